@@ -24,6 +24,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/internal/client"
 	"github.com/cockroachdb/cockroach/pkg/jobs"
 	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobsprotectedts"
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/settings"
@@ -40,6 +41,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/storage"
 	"github.com/cockroachdb/cockroach/pkg/storage/cloud"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine"
+	"github.com/cockroachdb/cockroach/pkg/storage/protectedts"
 	"github.com/cockroachdb/cockroach/pkg/util/ctxgroup"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/interval"
@@ -84,6 +86,8 @@ var useTBI = settings.RegisterBoolSetting(
 
 var backupOptionExpectValues = map[string]sql.KVStringOptValidate{
 	backupOptRevisionHistory: sql.KVStringOptRequireNoValue,
+	backupOptEncPassphrase:   sql.KVStringOptRequireValue,
+	backupOptEncKeyFile:      sql.KVStringOptRequireValue,
 }
 
 // BackupCheckpointInterval is the interval at which backup progress is saved
@@ -92,9 +96,12 @@ var BackupCheckpointInterval = time.Minute
 
 // ReadBackupDescriptorFromURI creates an export store from the given URI, then
 // reads and unmarshals a BackupDescriptor at the standard location in the
-// export storage.
+// export storage. The encryption must be set if the backup is encrypted.
 func ReadBackupDescriptorFromURI(
-	ctx context.Context, uri string, makeExternalStorageFromURI cloud.ExternalStorageFromURIFactory,
+	ctx context.Context,
+	uri string,
+	makeExternalStorageFromURI cloud.ExternalStorageFromURIFactory,
+	encryption *roachpb.FileEncryptionOptions,
 ) (BackupDescriptor, error) {
 	exportStore, err := makeExternalStorageFromURI(ctx, uri)
 
@@ -102,9 +109,9 @@ func ReadBackupDescriptorFromURI(
 		return BackupDescriptor{}, err
 	}
 	defer exportStore.Close()
-	backupDesc, err := readBackupDescriptor(ctx, exportStore, BackupDescriptorName)
+	backupDesc, err := readBackupDescriptor(ctx, exportStore, BackupDescriptorName, encryption)
 	if err != nil {
-		backupManifest, manifestErr := readBackupDescriptor(ctx, exportStore, BackupManifestName)
+		backupManifest, manifestErr := readBackupDescriptor(ctx, exportStore, BackupManifestName, encryption)
 		if manifestErr != nil {
 			return BackupDescriptor{}, err
 		}
@@ -117,9 +124,12 @@ func ReadBackupDescriptorFromURI(
 }
 
 // readBackupDescriptor reads and unmarshals a BackupDescriptor from filename in
-// the provided export store.
+// the provided export store, decrypting it if an encryption is given.
 func readBackupDescriptor(
-	ctx context.Context, exportStore cloud.ExternalStorage, filename string,
+	ctx context.Context,
+	exportStore cloud.ExternalStorage,
+	filename string,
+	encryption *roachpb.FileEncryptionOptions,
 ) (BackupDescriptor, error) {
 	r, err := exportStore.ReadFile(ctx, filename)
 	if err != nil {
@@ -130,6 +140,10 @@ func readBackupDescriptor(
 	if err != nil {
 		return BackupDescriptor{}, err
 	}
+	descBytes, err = maybeDecrypt(descBytes, encryption)
+	if err != nil {
+		return BackupDescriptor{}, err
+	}
 	var backupDesc BackupDescriptor
 	if err := protoutil.Unmarshal(descBytes, &backupDesc); err != nil {
 		return BackupDescriptor{}, err
@@ -155,7 +169,10 @@ func readBackupDescriptor(
 }
 
 func readBackupPartitionDescriptor(
-	ctx context.Context, exportStore cloud.ExternalStorage, filename string,
+	ctx context.Context,
+	exportStore cloud.ExternalStorage,
+	filename string,
+	encryption *roachpb.FileEncryptionOptions,
 ) (BackupPartitionDescriptor, error) {
 	r, err := exportStore.ReadFile(ctx, filename)
 	if err != nil {
@@ -166,6 +183,10 @@ func readBackupPartitionDescriptor(
 	if err != nil {
 		return BackupPartitionDescriptor{}, err
 	}
+	descBytes, err = maybeDecrypt(descBytes, encryption)
+	if err != nil {
+		return BackupPartitionDescriptor{}, err
+	}
 	var backupDesc BackupPartitionDescriptor
 	if err := protoutil.Unmarshal(descBytes, &backupDesc); err != nil {
 		return BackupPartitionDescriptor{}, err
@@ -528,6 +549,10 @@ func backupJobDescription(
 	incrementalFrom []string,
 	opts map[string]string,
 ) (string, error) {
+	opts, err := redactEncryptionOpts(opts)
+	if err != nil {
+		return "", err
+	}
 	b := &tree.Backup{
 		AsOf:    backup.AsOf,
 		Options: optsToKVOptions(opts),
@@ -582,6 +607,7 @@ func writeBackupDescriptor(
 	exportStore cloud.ExternalStorage,
 	filename string,
 	desc *BackupDescriptor,
+	encryption *roachpb.FileEncryptionOptions,
 ) error {
 	sort.Sort(BackupFileDescriptors(desc.Files))
 
@@ -589,6 +615,9 @@ func writeBackupDescriptor(
 	if err != nil {
 		return err
 	}
+	if descBuf, err = maybeEncrypt(descBuf, encryption); err != nil {
+		return err
+	}
 	return exportStore.WriteFile(ctx, filename, bytes.NewReader(descBuf))
 }
 
@@ -600,11 +629,15 @@ func writeBackupPartitionDescriptor(
 	exportStore cloud.ExternalStorage,
 	filename string,
 	desc *BackupPartitionDescriptor,
+	encryption *roachpb.FileEncryptionOptions,
 ) error {
 	descBuf, err := protoutil.Marshal(desc)
 	if err != nil {
 		return err
 	}
+	if descBuf, err = maybeEncrypt(descBuf, encryption); err != nil {
+		return err
+	}
 
 	return exportStore.WriteFile(ctx, filename, bytes.NewReader(descBuf))
 }
@@ -672,6 +705,7 @@ func backup(
 	checkpointDesc *BackupDescriptor,
 	resultsCh chan<- tree.Datums,
 	makeExternalStorage cloud.ExternalStorageFactory,
+	encryption *roachpb.FileEncryptionOptions,
 ) (roachpb.BulkOpSummary, error) {
 	// TODO(dan): Figure out how permissions should work. #6713 is tracking this
 	// for grpc.
@@ -797,6 +831,7 @@ func backup(
 					StartTime:                           span.start,
 					EnableTimeBoundIteratorOptimization: useTBI.Get(&settings.SV),
 					MVCCFilter:                          roachpb.MVCCFilter(backupDesc.MVCCFilter),
+					Encryption:                          encryption,
 				}
 				rawRes, pErr := client.SendWrappedWith(ctx, db.NonTransactionalSender(), header, req)
 				if pErr != nil {
@@ -838,7 +873,7 @@ func backup(
 					checkpointMu.Lock()
 					backupDesc.Files = checkpointFiles
 					err := writeBackupDescriptor(
-						ctx, settings, defaultStore, BackupDescriptorCheckpointName, backupDesc,
+						ctx, settings, defaultStore, BackupDescriptorCheckpointName, backupDesc, encryption,
 					)
 					checkpointMu.Unlock()
 					if err != nil {
@@ -892,14 +927,16 @@ func backup(
 					return err
 				}
 				defer store.Close()
-				return writeBackupPartitionDescriptor(ctx, store, filename, &desc)
+				return writeBackupPartitionDescriptor(ctx, store, filename, &desc, encryption)
 			}(); err != nil {
 				return mu.exported, err
 			}
 		}
 	}
 
-	if err := writeBackupDescriptor(ctx, settings, defaultStore, BackupDescriptorName, backupDesc); err != nil {
+	if err := writeBackupDescriptor(
+		ctx, settings, defaultStore, BackupDescriptorName, backupDesc, encryption,
+	); err != nil {
 		return mu.exported, err
 	}
 
@@ -957,7 +994,7 @@ func VerifyUsableExportTarget(
 			readable, BackupDescriptorCheckpointName)
 	}
 	if err := writeBackupDescriptor(
-		ctx, settings, exportStore, BackupDescriptorCheckpointName, &BackupDescriptor{},
+		ctx, settings, exportStore, BackupDescriptorCheckpointName, &BackupDescriptor{}, nil /* encryption */,
 	); err != nil {
 		return errors.Wrapf(err, "cannot write to %s", readable)
 	}
@@ -973,15 +1010,7 @@ func backupPlanHook(
 		return nil, nil, nil, false, nil
 	}
 
-	toFn, err := p.TypeAsStringArray(tree.Exprs(backupStmt.To), "BACKUP")
-	if err != nil {
-		return nil, nil, nil, false, err
-	}
-	incrementalFromFn, err := p.TypeAsStringArray(backupStmt.IncrementalFrom, "BACKUP")
-	if err != nil {
-		return nil, nil, nil, false, err
-	}
-	optsFn, err := p.TypeAsStringOpts(backupStmt.Options, backupOptionExpectValues)
+	recordFn, err := makeBackupJobRecordFn(backupStmt, p)
 	if err != nil {
 		return nil, nil, nil, false, err
 	}
@@ -1001,55 +1030,99 @@ func backupPlanHook(
 		ctx, span := tracing.ChildSpan(ctx, stmt.StatementTag())
 		defer tracing.FinishSpan(span)
 
+		if !p.ExtendedEvalContext().TxnImplicit {
+			return errors.Errorf("BACKUP cannot be used inside a transaction")
+		}
+
+		record, err := recordFn(ctx)
+		if err != nil {
+			return err
+		}
+		_, errCh, err := p.ExecCfg().JobRegistry.CreateAndStartJob(ctx, resultsCh, record)
+		if err != nil {
+			return err
+		}
+		return <-errCh
+	}
+	return fn, header, nil, false, nil
+}
+
+// makeBackupJobRecordFn type checks the given BACKUP statement, and returns a
+// function which plans the backup and returns the record of its job.
+func makeBackupJobRecordFn(
+	backupStmt *tree.Backup, p sql.PlanHookState,
+) (func(context.Context) (jobs.Record, error), error) {
+	toFn, err := p.TypeAsStringArray(tree.Exprs(backupStmt.To), "BACKUP")
+	if err != nil {
+		return nil, err
+	}
+	incrementalFromFn, err := p.TypeAsStringArray(backupStmt.IncrementalFrom, "BACKUP")
+	if err != nil {
+		return nil, err
+	}
+	optsFn, err := p.TypeAsStringOpts(backupStmt.Options, backupOptionExpectValues)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(ctx context.Context) (jobs.Record, error) {
 		if err := utilccl.CheckEnterpriseEnabled(
 			p.ExecCfg().Settings, p.ExecCfg().ClusterID(), p.ExecCfg().Organization(), "BACKUP",
 		); err != nil {
-			return err
+			return jobs.Record{}, err
 		}
 
 		if err := p.RequireAdminRole(ctx, "BACKUP"); err != nil {
-			return err
-		}
-
-		if !p.ExtendedEvalContext().TxnImplicit {
-			return errors.Errorf("BACKUP cannot be used inside a transaction")
+			return jobs.Record{}, err
 		}
 
 		to, err := toFn()
 		if err != nil {
-			return err
+			return jobs.Record{}, err
 		}
 		if len(to) > 1 &&
 			!cluster.Version.IsActive(ctx, p.ExecCfg().Settings, cluster.VersionPartitionedBackup) {
-			return errors.Errorf("partitioned backups can only be made on a cluster that has been fully upgraded to version 19.2")
+			return jobs.Record{}, errors.Errorf("partitioned backups can only be made on a cluster that has been fully upgraded to version 19.2")
 		}
 
 		incrementalFrom, err := incrementalFromFn()
 		if err != nil {
-			return err
+			return jobs.Record{}, err
 		}
 
 		endTime := p.ExecCfg().Clock.Now()
 		if backupStmt.AsOf.Expr != nil {
 			var err error
 			if endTime, err = p.EvalAsOfTimestamp(backupStmt.AsOf); err != nil {
-				return err
+				return jobs.Record{}, err
+			}
+		}
+
+		makeExternalStorageFromURI := p.ExecCfg().DistSQLSrv.ExternalStorageFromURI
+		if backupStmt.Nested {
+			if to, incrementalFrom, err = resolveBackupCollection(
+				ctx, to, backupStmt.AppendToLatest, endTime, makeExternalStorageFromURI,
+			); err != nil {
+				return jobs.Record{}, err
 			}
 		}
 
 		defaultURI, urisByLocalityKV, err := getURIsByLocalityKV(to)
 		if err != nil {
-			return nil
+			return jobs.Record{}, err
 		}
 		defaultStore, err := p.ExecCfg().DistSQLSrv.ExternalStorageFromURI(ctx, defaultURI)
 		if err != nil {
-			return err
+			return jobs.Record{}, err
 		}
 		defer defaultStore.Close()
 
 		opts, err := optsFn()
 		if err != nil {
-			return err
+			return jobs.Record{}, err
+		}
+		if err := checkEncryptionVersion(ctx, p.ExecCfg().Settings, opts); err != nil {
+			return jobs.Record{}, err
 		}
 
 		mvccFilter := MVCCFilter_Latest
@@ -1059,7 +1132,7 @@ func backupPlanHook(
 
 		targetDescs, completeDBs, err := ResolveTargetsToDescriptors(ctx, p, endTime, backupStmt.Targets)
 		if err != nil {
-			return err
+			return jobs.Record{}, err
 		}
 
 		statsCache := p.ExecCfg().TableStatsCache
@@ -1068,19 +1141,19 @@ func backupPlanHook(
 		for _, desc := range targetDescs {
 			if dbDesc := desc.GetDatabase(); dbDesc != nil {
 				if err := p.CheckPrivilege(ctx, dbDesc, privilege.SELECT); err != nil {
-					return err
+					return jobs.Record{}, err
 				}
 			}
 			if tableDesc := desc.Table(hlc.Timestamp{}); tableDesc != nil {
 				if err := p.CheckPrivilege(ctx, tableDesc, privilege.SELECT); err != nil {
-					return err
+					return jobs.Record{}, err
 				}
 				tables = append(tables, tableDesc)
 
 				// Collect all the table stats for this table.
 				tableStatisticsAcc, err := statsCache.GetTableStats(ctx, tableDesc.GetID())
 				if err != nil {
-					return err
+					return jobs.Record{}, err
 				}
 				for i := range tableStatisticsAcc {
 					tableStatistics = append(tableStatistics, &tableStatisticsAcc[i].TableStatisticProto)
@@ -1089,7 +1162,22 @@ func backupPlanHook(
 		}
 
 		if err := ensureInterleavesIncluded(tables); err != nil {
-			return err
+			return jobs.Record{}, err
+		}
+
+		var encryption *roachpb.FileEncryptionOptions
+		var encryptionInfo *EncryptionInfo
+		if len(incrementalFrom) > 0 {
+			// Incremental backups are encrypted with the key of the backups they
+			// build on, so that they can all be restored with the same options.
+			encryption, encryptionInfo, err = getEncryptionFromBase(
+				ctx, incrementalFrom[0], opts, makeExternalStorageFromURI)
+		} else {
+			encryption, encryptionInfo, err = makeEncryption(
+				ctx, opts, nil /* baseInfo */, makeExternalStorageFromURI)
+		}
+		if err != nil {
+			return jobs.Record{}, err
 		}
 
 		var prevBackups []BackupDescriptor
@@ -1103,15 +1191,15 @@ func backupPlanHook(
 				// since all we need to do is get the past backups' table/index spans,
 				// but it will be safer for future code to avoid having older-style
 				// descriptors around.
-				desc, err := ReadBackupDescriptorFromURI(ctx, uri, p.ExecCfg().DistSQLSrv.ExternalStorageFromURI)
+				desc, err := ReadBackupDescriptorFromURI(ctx, uri, makeExternalStorageFromURI, encryption)
 				if err != nil {
-					return errors.Wrapf(err, "failed to read backup from %q", uri)
+					return jobs.Record{}, errors.Wrapf(err, "failed to read backup from %q", uri)
 				}
 				// IDs are how we identify tables, and those are only meaningful in the
 				// context of their own cluster, so we need to ensure we only allow
 				// incremental previous backups that we created.
 				if !desc.ClusterID.Equal(clusterID) {
-					return errors.Newf("previous BACKUP %q belongs to cluster %s", uri, desc.ClusterID.String())
+					return jobs.Record{}, errors.Newf("previous BACKUP %q belongs to cluster %s", uri, desc.ClusterID.String())
 				}
 				prevBackups[i] = desc
 			}
@@ -1130,7 +1218,7 @@ func backupPlanHook(
 			priorIDs = make(map[sqlbase.ID]sqlbase.ID)
 			revs, err = getRelevantDescChanges(ctx, p.ExecCfg().DB, startTime, endTime, targetDescs, completeDBs, priorIDs)
 			if err != nil {
-				return err
+				return jobs.Record{}, err
 			}
 		}
 
@@ -1170,7 +1258,7 @@ func backupPlanHook(
 							priorIDs = make(map[sqlbase.ID]sqlbase.ID)
 							_, err := getAllDescChanges(ctx, p.ExecCfg().DB, startTime, endTime, priorIDs)
 							if err != nil {
-								return err
+								return jobs.Record{}, err
 							}
 						}
 						found := false
@@ -1181,7 +1269,7 @@ func backupPlanHook(
 							continue
 						}
 					}
-					return errors.Errorf("previous backup does not contain table %q", t.Name)
+					return jobs.Record{}, errors.Errorf("previous backup does not contain table %q", t.Name)
 				}
 			}
 
@@ -1200,10 +1288,10 @@ func backupPlanHook(
 				},
 			)
 			if err != nil {
-				return errors.Wrapf(err, "invalid previous backups (a new full backup may be required if a table has been created, dropped or truncated)")
+				return jobs.Record{}, errors.Wrapf(err, "invalid previous backups (a new full backup may be required if a table has been created, dropped or truncated)")
 			}
 			if coveredTime != startTime {
-				return errors.Wrapf(err, "expected previous backups to cover until time %v, got %v", startTime, coveredTime)
+				return jobs.Record{}, errors.Wrapf(err, "expected previous backups to cover until time %v, got %v", startTime, coveredTime)
 			}
 		}
 
@@ -1240,28 +1328,34 @@ func backupPlanHook(
 			keys.MinKey,
 			errOnMissingRange,
 		); err != nil {
-			return err
+			return jobs.Record{}, err
 		} else if coveredEnd != endTime {
-			return errors.Errorf("expected backup (along with any previous backups) to cover to %v, not %v", endTime, coveredEnd)
+			return jobs.Record{}, errors.Errorf("expected backup (along with any previous backups) to cover to %v, not %v", endTime, coveredEnd)
 		}
 
 		descBytes, err := protoutil.Marshal(&backupDesc)
 		if err != nil {
-			return err
+			return jobs.Record{}, err
 		}
 
 		description, err := backupJobDescription(p, backupStmt, to, incrementalFrom, opts)
 		if err != nil {
-			return err
+			return jobs.Record{}, err
 		}
 
 		// TODO (lucy): For partitioned backups, also add verification for other
 		// stores we are writing to in addition to the default.
 		if err := VerifyUsableExportTarget(ctx, p.ExecCfg().Settings, defaultStore, defaultURI); err != nil {
-			return err
+			return jobs.Record{}, err
+		}
+		if encryptionInfo != nil {
+			if err := writeEncryptionInfo(ctx, defaultStore, encryptionInfo); err != nil {
+				return jobs.Record{}, err
+			}
 		}
 
-		_, errCh, err := p.ExecCfg().JobRegistry.CreateAndStartJob(ctx, resultsCh, jobs.Record{
+		jobEncryption := makeJobEncryption(opts, encryption)
+		return jobs.Record{
 			Description: description,
 			Username:    p.User(),
 			DescriptorIDs: func() (sqlDescIDs []sqlbase.ID) {
@@ -1276,15 +1370,11 @@ func backupPlanHook(
 				URI:              defaultURI,
 				URIsByLocalityKV: urisByLocalityKV,
 				BackupDescriptor: descBytes,
+				Encryption:       jobEncryption,
 			},
 			Progress: jobspb.BackupProgress{},
-		})
-		if err != nil {
-			return err
-		}
-		return <-errCh
-	}
-	return fn, header, nil, false, nil
+		}, nil
+	}, nil
 }
 
 type backupResumer struct {
@@ -1292,6 +1382,7 @@ type backupResumer struct {
 	settings            *cluster.Settings
 	res                 roachpb.BulkOpSummary
 	makeExternalStorage cloud.ExternalStorageFactory
+	execCfg             *sql.ExecutorConfig
 }
 
 // Resume is part of the jobs.Resumer interface.
@@ -1301,6 +1392,7 @@ func (b *backupResumer) Resume(
 	details := b.job.Details().(jobspb.BackupDetails)
 	p := phs.(sql.PlanHookState)
 	b.makeExternalStorage = p.ExecCfg().DistSQLSrv.ExternalStorage
+	b.execCfg = p.ExecCfg()
 
 	if len(details.BackupDescriptor) == 0 {
 		return errors.Newf("missing backup descriptor; cannot resume a backup from an older version")
@@ -1311,6 +1403,9 @@ func (b *backupResumer) Resume(
 		return pgerror.Wrapf(err, pgcode.DataCorrupted,
 			"unmarshal backup descriptor")
 	}
+	if err := b.maybeProtectTimestamp(ctx, &details, backupDesc.Spans); err != nil {
+		return err
+	}
 	// For all backups, partitioned or not, the main BACKUP manifest is stored at
 	// details.URI.
 	defaultConf, err := cloud.ExternalStorageConfFromURI(details.URI)
@@ -1329,12 +1424,19 @@ func (b *backupResumer) Resume(
 		}
 		storageByLocalityKV[kv] = &conf
 	}
+	encryption, err := getJobEncryption(
+		ctx, details.Encryption, details.URI, p.ExecCfg().DistSQLSrv.ExternalStorageFromURI)
+	if err != nil {
+		return err
+	}
 	var checkpointDesc *BackupDescriptor
 	// We don't read the table descriptors from the backup descriptor, but
 	// they could be using either the new or the old foreign key
 	// representations. We should just preserve whatever representation the
 	// table descriptors were using and leave them alone.
-	if desc, err := readBackupDescriptor(ctx, defaultStore, BackupDescriptorCheckpointName); err == nil {
+	if desc, err := readBackupDescriptor(
+		ctx, defaultStore, BackupDescriptorCheckpointName, encryption,
+	); err == nil {
 		// If the checkpoint is from a different cluster, it's meaningless to us.
 		// More likely though are dummy/lock-out checkpoints with no ClusterID.
 		if desc.ClusterID.Equal(p.ExecCfg().ClusterID()) {
@@ -1360,18 +1462,81 @@ func (b *backupResumer) Resume(
 		checkpointDesc,
 		resultsCh,
 		b.makeExternalStorage,
+		encryption,
 	)
 	b.res = res
 	return err
 }
 
+// maybeProtectTimestamp prevents the data read by the backup from being
+// garbage collected while the backup runs. Incremental backups need the
+// history since their start time; full backups only need the data as of
+// their end time. The record is created once, when the job first runs, and
+// its ID is stored in the job details so that the resumed job doesn't create
+// another one.
+func (b *backupResumer) maybeProtectTimestamp(
+	ctx context.Context, details *jobspb.BackupDetails, spans []roachpb.Span,
+) error {
+	pts := b.execCfg.ProtectedTimestampProvider
+	if pts == nil || len(spans) == 0 ||
+		!cluster.Version.IsActive(ctx, b.settings, cluster.VersionProtectedTimestamps) {
+		return nil
+	}
+	if details.ProtectedTimestampRecord == nil {
+		tsToProtect := details.EndTime
+		if details.StartTime != (hlc.Timestamp{}) {
+			tsToProtect = details.StartTime
+		}
+		id := uuid.MakeV4()
+		rec := jobsprotectedts.MakeRecord(id, *b.job.ID(), tsToProtect, spans)
+		if err := b.execCfg.DB.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+			if err := pts.Protect(ctx, txn, rec); err != nil {
+				return err
+			}
+			details.ProtectedTimestampRecord = &id
+			return b.job.WithTxn(txn).SetDetails(ctx, *details)
+		}); err != nil {
+			details.ProtectedTimestampRecord = nil
+			return errors.Wrap(err, "failed to protect the timestamp of the backup")
+		}
+	}
+	// Fail early if the data was collected before the record was created, for
+	// example because the previous backup of an incremental chain is older
+	// than the GC TTL.
+	return errors.Wrap(pts.Verify(ctx, *details.ProtectedTimestampRecord),
+		"failed to verify the protection of the backup's data")
+}
+
+// releaseProtectedTimestamp releases the protected timestamp record of the
+// backup, if any, in the transaction which moves the job to a terminal state.
+// Jobs canceled on a node which did not run them leave their record to the
+// protected timestamp reconciliation loop.
+func (b *backupResumer) releaseProtectedTimestamp(ctx context.Context, txn *client.Txn) error {
+	details := b.job.Details().(jobspb.BackupDetails)
+	if details.ProtectedTimestampRecord == nil || b.execCfg == nil ||
+		b.execCfg.ProtectedTimestampProvider == nil {
+		return nil
+	}
+	pts := b.execCfg.ProtectedTimestampProvider
+	err := pts.Release(ctx, txn, *details.ProtectedTimestampRecord)
+	if err == protectedts.ErrNotExists {
+		// The record was already removed by the reconciliation loop.
+		log.Warningf(ctx, "protected timestamp record %s of job %d does not exist",
+			details.ProtectedTimestampRecord, *b.job.ID())
+		return nil
+	}
+	return err
+}
+
 // OnFailOrCancel is part of the jobs.Resumer interface.
-func (b *backupResumer) OnFailOrCancel(context.Context, *client.Txn) error {
-	return nil
+func (b *backupResumer) OnFailOrCancel(ctx context.Context, txn *client.Txn) error {
+	return b.releaseProtectedTimestamp(ctx, txn)
 }
 
 // OnSuccess is part of the jobs.Resumer interface.
-func (b *backupResumer) OnSuccess(context.Context, *client.Txn) error { return nil }
+func (b *backupResumer) OnSuccess(ctx context.Context, txn *client.Txn) error {
+	return b.releaseProtectedTimestamp(ctx, txn)
+}
 
 // OnTerminal is part of the jobs.Resumer interface.
 func (b *backupResumer) OnTerminal(
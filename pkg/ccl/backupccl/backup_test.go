@@ -12,6 +12,7 @@ import (
 	"bytes"
 	"context"
 	gosql "database/sql"
+	"encoding/hex"
 	"fmt"
 	"hash/crc32"
 	"io"
@@ -32,6 +33,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/base"
 	"github.com/cockroachdb/cockroach/pkg/ccl/backupccl"
 	_ "github.com/cockroachdb/cockroach/pkg/ccl/partitionccl"
+	"github.com/cockroachdb/cockroach/pkg/ccl/storageccl"
 	"github.com/cockroachdb/cockroach/pkg/ccl/utilccl/sampledataccl"
 	"github.com/cockroachdb/cockroach/pkg/config"
 	"github.com/cockroachdb/cockroach/pkg/internal/client"
@@ -45,6 +47,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/pkg/storage"
+	"github.com/cockroachdb/cockroach/pkg/storage/protectedts"
 	"github.com/cockroachdb/cockroach/pkg/testutils"
 	"github.com/cockroachdb/cockroach/pkg/testutils/jobutils"
 	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
@@ -55,6 +58,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/retry"
 	"github.com/cockroachdb/cockroach/pkg/util/stop"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
 	"github.com/cockroachdb/cockroach/pkg/workload/bank"
 	"github.com/cockroachdb/cockroach/pkg/workload/workloadsql"
 	"github.com/gogo/protobuf/proto"
@@ -2310,6 +2314,95 @@ func TestRestoreAsOfSystemTime(t *testing.T) {
 	})
 }
 
+// TestBackupProtectedTimestamp tests that a backup protects the data it reads
+// from garbage collection while it runs, and releases the protection once it
+// finishes.
+func TestBackupProtectedTimestamp(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	var allowResponse chan struct{}
+	params := base.TestClusterArgs{}
+	params.ServerArgs.Knobs.Store = &storage.StoreTestingKnobs{
+		TestingResponseFilter: jobutils.BulkOpResponseFilter(&allowResponse),
+	}
+	const numAccounts = 10
+	ctx, tc, sqlDB, _, cleanupFn := backupRestoreTestSetupWithParams(
+		t, singleNode, numAccounts, initNone, params,
+	)
+	defer cleanupFn()
+	// Split the table so that the backup sends more than one export request,
+	// and can be blocked once it started.
+	sqlDB.Exec(t, `ALTER TABLE data.bank SPLIT AT VALUES (5)`)
+
+	execCfg := tc.Server(0).ExecutorConfig().(sql.ExecutorConfig)
+	pts := execCfg.ProtectedTimestampProvider
+	recordExists := func(id uuid.UUID) bool {
+		var err error
+		if txnErr := tc.Server(0).DB().Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+			_, err = pts.GetRecord(ctx, txn, id)
+			return nil
+		}); txnErr != nil {
+			t.Fatal(txnErr)
+		}
+		if err != nil && err != protectedts.ErrNotExists {
+			t.Fatal(err)
+		}
+		return err == nil
+	}
+
+	for _, testCase := range []struct {
+		name string
+		ops  []string
+		err  string
+	}{
+		{name: "success"},
+		{name: "cancel", ops: []string{"CANCEL"}, err: "job canceled"},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			allowResponse = make(chan struct{})
+			errCh := make(chan error)
+			go func() {
+				_, err := sqlDB.DB.ExecContext(ctx, `BACKUP data.bank TO $1`, localFoo+"/"+testCase.name)
+				errCh <- err
+			}()
+			// Let one export response through, after which the backup is running
+			// and blocked on the next response.
+			select {
+			case allowResponse <- struct{}{}:
+			case err := <-errCh:
+				t.Fatalf("backup returned before expected: %v", err)
+			}
+			var jobID int64
+			sqlDB.QueryRow(t, `SELECT id FROM system.jobs ORDER BY created DESC LIMIT 1`).Scan(&jobID)
+			job, err := execCfg.JobRegistry.LoadJob(ctx, jobID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			id := job.Details().(jobspb.BackupDetails).ProtectedTimestampRecord
+			if id == nil {
+				t.Fatal("expected the backup to have a protected timestamp record")
+			}
+			if !recordExists(*id) {
+				t.Fatalf("expected record %s to exist while the backup runs", id)
+			}
+
+			for _, op := range testCase.ops {
+				sqlDB.Exec(t, fmt.Sprintf("%s JOB %d", op, jobID))
+			}
+			close(allowResponse)
+			if err := <-errCh; !testutils.IsError(err, testCase.err) {
+				t.Fatalf("expected error %q, got %v", testCase.err, err)
+			}
+			testutils.SucceedsSoon(t, func() error {
+				if recordExists(*id) {
+					return errors.Errorf("record %s not released yet", id)
+				}
+				return nil
+			})
+		})
+	}
+}
+
 func TestRestoreAsOfSystemTimeGCBounds(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 
@@ -2425,6 +2518,217 @@ func TestBackupRestoreChecksum(t *testing.T) {
 	sqlDB.ExpectErr(t, "checksum mismatch", `RESTORE data.* FROM $1`, localFoo)
 }
 
+func TestBackupRestoreEncrypted(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	const numAccounts = 100
+	_, _, sqlDB, dir, cleanupFn := backupRestoreTestSetup(t, singleNode, numAccounts, initNone)
+	defer cleanupFn()
+	full, inc := localFoo+"/full", localFoo+"/inc"
+
+	sqlDB.Exec(t, `BACKUP DATABASE data TO $1 WITH encryption_passphrase = 'abcdefg'`, full)
+	sqlDB.Exec(t, `UPDATE data.bank SET balance = balance + 1`)
+	sqlDB.ExpectErr(t, "does not match the encryption",
+		`BACKUP DATABASE data TO $1 INCREMENTAL FROM $2 WITH encryption_passphrase = 'wrong'`,
+		inc, full)
+	sqlDB.Exec(t, `BACKUP DATABASE data TO $1 INCREMENTAL FROM $2 WITH encryption_passphrase = 'abcdefg'`,
+		inc, full)
+
+	// The files of the backup are not readable without the passphrase.
+	descBytes, err := ioutil.ReadFile(filepath.Join(dir, "foo", "full", backupccl.BackupDescriptorName))
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	if !storageccl.AppearsEncrypted(descBytes) {
+		t.Fatal("expected the backup descriptor to be encrypted")
+	}
+	sqlDB.ExpectErr(t, "the backup is encrypted", `SHOW BACKUP $1`, full)
+	if res := sqlDB.QueryStr(t,
+		`SELECT table_name FROM [SHOW BACKUP $1 WITH encryption_passphrase = 'abcdefg']`, full,
+	); !reflect.DeepEqual(res, [][]string{{"bank"}}) {
+		t.Fatalf("unexpected SHOW BACKUP output: %v", res)
+	}
+
+	var expected int
+	sqlDB.QueryRow(t, `SELECT sum(balance) FROM data.bank`).Scan(&expected)
+	sqlDB.Exec(t, `DROP DATABASE data CASCADE`)
+
+	sqlDB.ExpectErr(t, "the backup is encrypted", `RESTORE DATABASE data FROM $1, $2`, full, inc)
+	sqlDB.ExpectErr(t, "does not match the encryption",
+		`RESTORE DATABASE data FROM $1, $2 WITH encryption_passphrase = 'wrong'`, full, inc)
+	sqlDB.Exec(t, `RESTORE DATABASE data FROM $1, $2 WITH encryption_passphrase = 'abcdefg'`,
+		full, inc)
+
+	var actual int
+	sqlDB.QueryRow(t, `SELECT sum(balance) FROM data.bank`).Scan(&actual)
+	if expected != actual {
+		t.Fatalf("expected sum of balances %d, got %d", expected, actual)
+	}
+
+	// The passphrase is redacted from the job descriptions.
+	sqlDB.CheckQueryResults(t,
+		`SELECT count(*) FROM [SHOW JOBS] WHERE description LIKE '%abcdefg%'`, [][]string{{"0"}})
+	sqlDB.CheckQueryResults(t,
+		`SELECT count(*) FROM [SHOW JOBS] WHERE description LIKE '%encryption_passphrase = ''redacted''%'`,
+		[][]string{{"3"}})
+
+	// The jobs store the key derived from the passphrase, so they can resume
+	// on any node, but never the passphrase itself.
+	for _, row := range sqlDB.QueryStr(t,
+		`SELECT job_id FROM [SHOW JOBS] WHERE job_type IN ('BACKUP', 'RESTORE')`,
+	) {
+		var payloadBytes []byte
+		sqlDB.QueryRow(t, `SELECT payload FROM system.jobs WHERE id = $1`, row[0]).Scan(&payloadBytes)
+		if bytes.Contains(payloadBytes, []byte("abcdefg")) {
+			t.Fatalf("job %s persisted the encryption passphrase", row[0])
+		}
+		var payload jobspb.Payload
+		if err := protoutil.Unmarshal(payloadBytes, &payload); err != nil {
+			t.Fatal(err)
+		}
+		var encryption *jobspb.BackupEncryptionOptions
+		switch d := payload.UnwrapDetails().(type) {
+		case jobspb.BackupDetails:
+			encryption = d.Encryption
+		case jobspb.RestoreDetails:
+			encryption = d.Encryption
+		}
+		if encryption == nil || len(encryption.Key) != storageccl.EncryptionKeySize {
+			t.Fatalf("job %s: expected the encryption key in the details, got %v", row[0], encryption)
+		}
+	}
+}
+
+func TestBackupRestoreEncryptedKeyFile(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	const numAccounts = 10
+	_, _, sqlDB, dir, cleanupFn := backupRestoreTestSetup(t, singleNode, numAccounts, initNone)
+	defer cleanupFn()
+	full, inc := localFoo+"/full", localFoo+"/inc"
+
+	rng, _ := randutil.NewPseudoRand()
+	writeKeyFile := func(name string) []byte {
+		t.Helper()
+		key := make([]byte, storageccl.EncryptionKeySize)
+		if _, err := rng.Read(key); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(
+			filepath.Join(dir, "foo", name), []byte(hex.EncodeToString(key)+"\n"), 0644,
+		); err != nil {
+			t.Fatal(err)
+		}
+		return key
+	}
+	key := writeKeyFile("key")
+	writeKeyFile("other-key")
+	keyFile, otherKeyFile := localFoo+"/key", localFoo+"/other-key"
+
+	sqlDB.Exec(t, `BACKUP DATABASE data TO $1 WITH encryption_key_file = $2`, full, keyFile)
+	sqlDB.Exec(t, `UPDATE data.bank SET balance = balance + 1`)
+	sqlDB.ExpectErr(t, "does not match the encryption",
+		`BACKUP DATABASE data TO $1 INCREMENTAL FROM $2 WITH encryption_key_file = $3`,
+		inc, full, otherKeyFile)
+	sqlDB.Exec(t, `BACKUP DATABASE data TO $1 INCREMENTAL FROM $2 WITH encryption_key_file = $3`,
+		inc, full, keyFile)
+	sqlDB.ExpectErr(t, "cannot use both",
+		`SHOW BACKUP $1 WITH encryption_key_file = $2, encryption_passphrase = 'abcdefg'`,
+		full, keyFile)
+
+	var expected int
+	sqlDB.QueryRow(t, `SELECT sum(balance) FROM data.bank`).Scan(&expected)
+	sqlDB.Exec(t, `DROP DATABASE data CASCADE`)
+
+	sqlDB.ExpectErr(t, "the backup is encrypted", `RESTORE DATABASE data FROM $1, $2`, full, inc)
+	sqlDB.ExpectErr(t, "does not match the encryption",
+		`RESTORE DATABASE data FROM $1, $2 WITH encryption_key_file = $3`, full, inc, otherKeyFile)
+	sqlDB.Exec(t, `RESTORE DATABASE data FROM $1, $2 WITH encryption_key_file = $3`,
+		full, inc, keyFile)
+
+	var actual int
+	sqlDB.QueryRow(t, `SELECT sum(balance) FROM data.bank`).Scan(&actual)
+	if expected != actual {
+		t.Fatalf("expected sum of balances %d, got %d", expected, actual)
+	}
+
+	// The jobs only store the URI of the key file, never the key itself.
+	for _, row := range sqlDB.QueryStr(t,
+		`SELECT job_id FROM [SHOW JOBS] WHERE job_type IN ('BACKUP', 'RESTORE')`,
+	) {
+		var payloadBytes []byte
+		sqlDB.QueryRow(t, `SELECT payload FROM system.jobs WHERE id = $1`, row[0]).Scan(&payloadBytes)
+		if bytes.Contains(payloadBytes, key) {
+			t.Fatalf("job %s persisted the encryption key", row[0])
+		}
+		var payload jobspb.Payload
+		if err := protoutil.Unmarshal(payloadBytes, &payload); err != nil {
+			t.Fatal(err)
+		}
+		var encryption *jobspb.BackupEncryptionOptions
+		switch d := payload.UnwrapDetails().(type) {
+		case jobspb.BackupDetails:
+			encryption = d.Encryption
+		case jobspb.RestoreDetails:
+			encryption = d.Encryption
+		}
+		if encryption == nil || encryption.KeyFile != keyFile {
+			t.Fatalf("job %s: expected the key file %s, got %v", row[0], keyFile, encryption)
+		}
+	}
+}
+
+func TestBackupRestoreCollection(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	const numAccounts = 10
+	_, _, sqlDB, _, cleanupFn := backupRestoreTestSetup(t, singleNode, numAccounts, initNone)
+	defer cleanupFn()
+	const collection = localFoo + "/collection"
+
+	sqlDB.ExpectErr(t, "no full backup found in collection",
+		`BACKUP DATABASE data INTO LATEST IN $1`, collection)
+
+	sqlDB.Exec(t, `BACKUP DATABASE data INTO $1`, collection)
+	for i := 0; i < 2; i++ {
+		sqlDB.Exec(t, `UPDATE data.bank SET balance = balance + 1`)
+		sqlDB.Exec(t, `BACKUP DATABASE data INTO LATEST IN $1`, collection)
+	}
+	// A new full backup starts a new chain.
+	sqlDB.Exec(t, `UPDATE data.bank SET balance = balance + 1`)
+	sqlDB.Exec(t, `BACKUP DATABASE data INTO $1`, collection)
+	sqlDB.Exec(t, `UPDATE data.bank SET balance = balance + 1`)
+	sqlDB.Exec(t, `BACKUP DATABASE data INTO LATEST IN $1`, collection)
+
+	rows := sqlDB.QueryStr(t, `SHOW BACKUPS IN $1`, collection)
+	var types []string
+	var full string
+	for _, row := range rows {
+		types = append(types, row[1])
+		if row[1] == "full" {
+			full = row[0]
+		} else if !strings.HasPrefix(row[0], full+"/") {
+			t.Fatalf("expected incremental backup %s to be in full backup %s", row[0], full)
+		}
+	}
+	if expected := []string{
+		"full", "incremental", "incremental", "full", "incremental",
+	}; !reflect.DeepEqual(expected, types) {
+		t.Fatalf("expected backups %v, got %v", expected, types)
+	}
+
+	var expected int
+	sqlDB.QueryRow(t, `SELECT sum(balance) FROM data.bank`).Scan(&expected)
+	sqlDB.Exec(t, `DROP DATABASE data CASCADE`)
+	sqlDB.Exec(t, `RESTORE DATABASE data FROM LATEST IN $1`, collection)
+
+	var actual int
+	sqlDB.QueryRow(t, `SELECT sum(balance) FROM data.bank`).Scan(&actual)
+	if expected != actual {
+		t.Fatalf("expected sum of balances %d, got %d", expected, actual)
+	}
+}
+
 func TestTimestampMismatch(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	const numAccounts = 1
@@ -0,0 +1,208 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package backupccl
+
+import (
+	"context"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/storage/cloud"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/errors"
+)
+
+// A backup collection is a location holding a series of backups, written by
+// BACKUP ... INTO. Each full backup is written to a subdirectory of the
+// collection named after its end time, and each incremental backup appended
+// to it by BACKUP ... INTO LATEST IN is written to a subdirectory of the full
+// backup, also named after its end time:
+//
+//   <collection>/2020/03/21-143000.00/BACKUP
+//   <collection>/2020/03/21-143000.00/20200322-143000.00/BACKUP
+//   <collection>/2020/03/21-143000.00/20200323-143000.00/BACKUP
+//
+// The names sort chronologically, so the latest full backup of the collection
+// and the chain of incremental backups appended to it can be found by listing
+// the collection.
+const (
+	// backupCollectionFullFormat is the time layout of the subdirectories of a
+	// collection holding full backups.
+	backupCollectionFullFormat = "2006/01/02-150405.00"
+	// backupCollectionFullGlob matches the subdirectories of a collection
+	// holding full backups.
+	backupCollectionFullGlob = "*/*/*"
+	// backupCollectionIncFormat is the time layout of the subdirectories of a
+	// full backup holding the incremental backups appended to it.
+	backupCollectionIncFormat = "20060102-150405.00"
+	// backupCollectionIncGlob matches the subdirectories of a full backup
+	// holding the incremental backups appended to it.
+	backupCollectionIncGlob = "*"
+)
+
+// errNoFullBackupInCollection marks the error returned when appending to the
+// latest full backup of a collection which doesn't have any.
+var errNoFullBackupInCollection = errors.New("no full backup found in collection")
+
+// appendPaths returns the given URI with the given elements appended to its
+// path, preserving its parameters.
+func appendPaths(uri string, elems ...string) (string, error) {
+	parsed, err := url.Parse(uri)
+	if err != nil {
+		return "", err
+	}
+	parsed.Path = path.Join(append([]string{parsed.Path}, elems...)...)
+	return parsed.String(), nil
+}
+
+// listBackupSubdirs returns the subdirectories of the given URI matching the
+// given glob pattern which hold a backup, relative to the URI, in sorted
+// order.
+func listBackupSubdirs(
+	ctx context.Context,
+	uri string,
+	pattern string,
+	makeExternalStorageFromURI cloud.ExternalStorageFromURIFactory,
+) ([]string, error) {
+	base, err := url.Parse(uri)
+	if err != nil {
+		return nil, err
+	}
+	glob, err := appendPaths(uri, pattern, BackupDescriptorName)
+	if err != nil {
+		return nil, err
+	}
+	store, err := makeExternalStorageFromURI(ctx, glob)
+	if err != nil {
+		return nil, err
+	}
+	defer store.Close()
+	files, err := store.ListFiles(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	basePath := strings.TrimSuffix(base.Path, "/")
+	subdirs := make([]string, 0, len(files))
+	for _, file := range files {
+		parsed, err := url.Parse(file)
+		if err != nil {
+			return nil, err
+		}
+		// ListFiles returns the full URIs of the files, which don't keep the
+		// parameters of the listed URI, so the subdirectory is extracted from
+		// their paths.
+		subdir := strings.TrimPrefix(path.Dir(parsed.Path), basePath)
+		subdirs = append(subdirs, strings.TrimPrefix(subdir, "/"))
+	}
+	sort.Strings(subdirs)
+	return subdirs, nil
+}
+
+// findLatestBackupInCollection returns the subdirectories of the latest full
+// backup of the collection at the given URI and of the incremental backups
+// appended to it, in chronological order.
+func findLatestBackupInCollection(
+	ctx context.Context,
+	collectionURI string,
+	makeExternalStorageFromURI cloud.ExternalStorageFromURIFactory,
+) ([]string, error) {
+	fulls, err := listBackupSubdirs(
+		ctx, collectionURI, backupCollectionFullGlob, makeExternalStorageFromURI)
+	if err != nil {
+		return nil, err
+	}
+	if len(fulls) == 0 {
+		redacted, err := cloud.SanitizeExternalStorageURI(collectionURI)
+		if err != nil {
+			return nil, err
+		}
+		return nil, errors.Mark(
+			errors.Errorf("no full backup found in collection %s", redacted), errNoFullBackupInCollection)
+	}
+	full := fulls[len(fulls)-1]
+
+	fullURI, err := appendPaths(collectionURI, full)
+	if err != nil {
+		return nil, err
+	}
+	incs, err := listBackupSubdirs(
+		ctx, fullURI, backupCollectionIncGlob, makeExternalStorageFromURI)
+	if err != nil {
+		return nil, err
+	}
+	chain := []string{full}
+	for _, inc := range incs {
+		chain = append(chain, path.Join(full, inc))
+	}
+	return chain, nil
+}
+
+// resolveBackupCollection returns the URIs a BACKUP ... INTO the collection
+// at the given URIs writes to, along with the URIs of the backups it builds on
+// when appendToLatest is set.
+func resolveBackupCollection(
+	ctx context.Context,
+	collection []string,
+	appendToLatest bool,
+	endTime hlc.Timestamp,
+	makeExternalStorageFromURI cloud.ExternalStorageFromURIFactory,
+) (to []string, incrementalFrom []string, err error) {
+	subdir := endTime.GoTime().Format(backupCollectionFullFormat)
+	if appendToLatest {
+		// The manifests of partitioned backups are all written to the default
+		// URI, so listing it is enough to find the chain.
+		chain, err := findLatestBackupInCollection(ctx, collection[0], makeExternalStorageFromURI)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, prev := range chain {
+			uri, err := appendPaths(collection[0], prev)
+			if err != nil {
+				return nil, nil, err
+			}
+			incrementalFrom = append(incrementalFrom, uri)
+		}
+		subdir = path.Join(chain[0], endTime.GoTime().Format(backupCollectionIncFormat))
+	}
+
+	to = make([]string, len(collection))
+	for i, uri := range collection {
+		if to[i], err = appendPaths(uri, subdir); err != nil {
+			return nil, nil, err
+		}
+	}
+	return to, incrementalFrom, nil
+}
+
+// resolveLatestInCollection returns the URIs of the latest full backup of the
+// collection at the given URIs and of the incremental backups appended to it,
+// for RESTORE ... FROM LATEST IN.
+func resolveLatestInCollection(
+	ctx context.Context,
+	collection []string,
+	makeExternalStorageFromURI cloud.ExternalStorageFromURIFactory,
+) ([][]string, error) {
+	chain, err := findLatestBackupInCollection(ctx, collection[0], makeExternalStorageFromURI)
+	if err != nil {
+		return nil, err
+	}
+	from := make([][]string, len(chain))
+	for i, subdir := range chain {
+		from[i] = make([]string, len(collection))
+		for j, uri := range collection {
+			if from[i][j], err = appendPaths(uri, subdir); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return from, nil
+}
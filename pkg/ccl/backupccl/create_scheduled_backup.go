@@ -0,0 +1,278 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package backupccl
+
+import (
+	"context"
+	"net/url"
+	"sort"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/utilccl"
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/storage/cloud"
+	"github.com/cockroachdb/cockroach/pkg/util/cron"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/cockroach/pkg/util/tracing"
+	"github.com/cockroachdb/errors"
+)
+
+// defaultFullBackupRecurrence is the recurrence of the full backups of a
+// schedule which doesn't specify one, when its incremental backups are taken
+// more often.
+const defaultFullBackupRecurrence = "@weekly"
+
+// createScheduledBackupHeader is the header of the CREATE SCHEDULE FOR
+// BACKUP results: a row per created schedule.
+var createScheduledBackupHeader = sqlbase.ResultColumns{
+	{Name: "schedule_id", Typ: types.Int},
+	{Name: "name", Typ: types.String},
+	{Name: "status", Typ: types.String},
+	{Name: "next_run", Typ: types.TimestampTZ},
+	{Name: "recurrence", Typ: types.String},
+	{Name: "backup_stmt", Typ: types.String},
+}
+
+// createScheduledBackupPlanHook implements PlanHookFn.
+func createScheduledBackupPlanHook(
+	_ context.Context, stmt tree.Statement, p sql.PlanHookState,
+) (sql.PlanHookRowFn, sqlbase.ResultColumns, []sql.PlanNode, bool, error) {
+	schedule, ok := stmt.(*tree.ScheduledBackup)
+	if !ok {
+		return nil, nil, nil, false, nil
+	}
+
+	const op = "CREATE SCHEDULE FOR BACKUP"
+	var nameFn func() (string, error)
+	if schedule.ScheduleName != nil {
+		var err error
+		if nameFn, err = p.TypeAsString(schedule.ScheduleName, op); err != nil {
+			return nil, nil, nil, false, err
+		}
+	}
+	toFn, err := p.TypeAsStringArray(tree.Exprs(schedule.To), op)
+	if err != nil {
+		return nil, nil, nil, false, err
+	}
+	optsFn, err := p.TypeAsStringOpts(schedule.BackupOptions, backupOptionExpectValues)
+	if err != nil {
+		return nil, nil, nil, false, err
+	}
+	recurrenceFn, err := p.TypeAsString(schedule.Recurrence, op)
+	if err != nil {
+		return nil, nil, nil, false, err
+	}
+	var fullRecurrenceFn func() (string, error)
+	if schedule.FullBackup != nil && !schedule.FullBackup.AlwaysFull {
+		if fullRecurrenceFn, err = p.TypeAsString(schedule.FullBackup.Recurrence, op); err != nil {
+			return nil, nil, nil, false, err
+		}
+	}
+
+	fn := func(ctx context.Context, _ []sql.PlanNode, resultsCh chan<- tree.Datums) error {
+		ctx, span := tracing.ChildSpan(ctx, stmt.StatementTag())
+		defer tracing.FinishSpan(span)
+
+		if err := utilccl.CheckEnterpriseEnabled(
+			p.ExecCfg().Settings, p.ExecCfg().ClusterID(), p.ExecCfg().Organization(), op,
+		); err != nil {
+			return err
+		}
+
+		if err := p.RequireAdminRole(ctx, op); err != nil {
+			return err
+		}
+
+		if !cluster.Version.IsActive(ctx, p.ExecCfg().Settings, cluster.VersionScheduledJobs) {
+			return errors.Errorf("scheduled backups can only be created on a cluster that has been fully upgraded")
+		}
+
+		name := "BACKUP " + tree.AsString(&schedule.Targets)
+		if nameFn != nil {
+			if name, err = nameFn(); err != nil {
+				return err
+			}
+		}
+		to, err := toFn()
+		if err != nil {
+			return err
+		}
+		opts, err := optsFn()
+		if err != nil {
+			return err
+		}
+		recurrence, err := recurrenceFn()
+		if err != nil {
+			return err
+		}
+
+		now := timeutil.Now()
+		var fullRecurrence string
+		switch {
+		case fullRecurrenceFn != nil:
+			if fullRecurrence, err = fullRecurrenceFn(); err != nil {
+				return err
+			}
+		case schedule.FullBackup == nil:
+			frequent, err := firesMoreOftenThanWeekly(recurrence, now)
+			if err != nil {
+				return err
+			}
+			if frequent {
+				fullRecurrence = defaultFullBackupRecurrence
+			}
+		}
+
+		// The BACKUP statement of the schedule is stored in system.scheduled_jobs
+		// and returned in the backup_stmt column, so it must not hold any
+		// secret. The schedule runs the statement as stored, so the secrets
+		// can't be redacted from it either: a statement which needs them can't
+		// be scheduled.
+		if err := checkScheduledBackupSecrets(to, opts); err != nil {
+			return err
+		}
+
+		backup := &tree.Backup{
+			Targets: schedule.Targets,
+			To:      make(tree.PartitionedBackup, len(to)),
+			Nested:  true,
+			Options: makeScheduledBackupOptions(opts),
+		}
+		for i := range to {
+			backup.To[i] = tree.NewDString(to[i])
+		}
+
+		// Without a separate recurrence for full backups, every backup taken by
+		// the schedule is a full one. Otherwise the schedule appends incremental
+		// backups to the latest full backup, taken by a second schedule.
+		var schedules []*jobs.ScheduledJob
+		if fullRecurrence != "" {
+			backup.AppendToLatest = true
+			inc, err := jobs.NewScheduledJob(name, p.User(), recurrence,
+				scheduledBackupExecutorName, []byte(tree.AsString(backup)), now)
+			if err != nil {
+				return err
+			}
+			schedules = append(schedules, inc)
+			backup.AppendToLatest = false
+			recurrence = fullRecurrence
+		}
+		full, err := jobs.NewScheduledJob(name, p.User(), recurrence,
+			scheduledBackupExecutorName, []byte(tree.AsString(backup)), now)
+		if err != nil {
+			return err
+		}
+		schedules = append(schedules, full)
+
+		for _, s := range schedules {
+			if err := s.Create(ctx, p.ExecCfg().InternalExecutor, p.ExtendedEvalContext().Txn); err != nil {
+				return err
+			}
+			resultsCh <- tree.Datums{
+				tree.NewDInt(tree.DInt(s.ID)),
+				tree.NewDString(s.Name),
+				tree.NewDString("ACTIVE"),
+				tree.MakeDTimestampTZ(s.NextRun, time.Microsecond),
+				tree.NewDString(s.ScheduleExpr),
+				tree.NewDString(string(s.ExecutionArgs)),
+			}
+		}
+		return nil
+	}
+	return fn, createScheduledBackupHeader, nil, false, nil
+}
+
+// firesMoreOftenThanWeekly returns whether the given cron expression fires
+// more than once in a week, judging by its next two firings after now.
+func firesMoreOftenThanWeekly(expr string, now time.Time) (bool, error) {
+	e, err := cron.Parse(expr)
+	if err != nil {
+		return false, err
+	}
+	first := e.Next(now)
+	if first.IsZero() {
+		return false, nil
+	}
+	second := e.Next(first)
+	return !second.IsZero() && second.Sub(first) < 7*24*time.Hour, nil
+}
+
+// scheduledBackupCredentialParams are the query parameters of external
+// storage URIs which hold credentials. The other parameters, such as AUTH or
+// the region of a bucket, may be stored in a schedule.
+var scheduledBackupCredentialParams = []string{
+	cloud.S3AccessKeyParam,
+	cloud.S3SecretParam,
+	cloud.S3TempTokenParam,
+	cloud.AzureAccountKeyParam,
+	cloud.CredentialsParam,
+}
+
+// checkScheduledBackupSecrets returns an error if the destination URIs or the
+// options of a scheduled BACKUP hold a secret: the passphrase, or the
+// credentials of a URI.
+func checkScheduledBackupSecrets(to []string, opts map[string]string) error {
+	if _, ok := opts[backupOptEncPassphrase]; ok {
+		return pgerror.Newf(pgcode.FeatureNotSupported,
+			"scheduled backups cannot store the %s option: use the %s option instead",
+			backupOptEncPassphrase, backupOptEncKeyFile)
+	}
+	uris := to
+	if keyFile, ok := opts[backupOptEncKeyFile]; ok {
+		uris = append(append([]string(nil), to...), keyFile)
+	}
+	for _, uri := range uris {
+		parsed, err := url.Parse(uri)
+		if err != nil {
+			return err
+		}
+		query := parsed.Query()
+		for _, param := range scheduledBackupCredentialParams {
+			if _, ok := query[param]; ok {
+				return pgerror.Newf(pgcode.FeatureNotSupported,
+					"scheduled backups cannot store the %s parameter of URIs: use %s=implicit instead",
+					param, cloud.AuthParam)
+			}
+		}
+	}
+	return nil
+}
+
+// makeScheduledBackupOptions turns the evaluated options of a CREATE SCHEDULE
+// FOR BACKUP statement back into the options of the BACKUP statements run by
+// the schedule, in a deterministic order.
+func makeScheduledBackupOptions(opts map[string]string) tree.KVOptions {
+	if len(opts) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(opts))
+	for k := range opts {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	res := make(tree.KVOptions, len(keys))
+	for i, k := range keys {
+		res[i].Key = tree.Name(k)
+		if v := opts[k]; v != "" {
+			res[i].Value = tree.NewDString(v)
+		}
+	}
+	return res
+}
+
+func init() {
+	sql.AddPlanHook(createScheduledBackupPlanHook)
+}
@@ -0,0 +1,240 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package backupccl
+
+import (
+	"context"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlutil"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/errors"
+)
+
+func TestCreateScheduledBackup(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	dir, cleanupDir := testutils.TempDir(t)
+	defer cleanupDir()
+
+	ctx := context.Background()
+	srv, db, kvDB := serverutils.StartServer(t, base.TestServerArgs{ExternalIODir: dir})
+	defer srv.Stopper().Stop(ctx)
+	sqlDB := sqlutils.MakeSQLRunner(db)
+	// Keep the scheduler out of the way: the test runs the schedules itself.
+	sqlDB.Exec(t, `SET CLUSTER SETTING jobs.scheduler.enabled = false`)
+	sqlDB.Exec(t, `CREATE DATABASE data`)
+	sqlDB.Exec(t, `CREATE TABLE data.t (a INT PRIMARY KEY)`)
+	sqlDB.Exec(t, `INSERT INTO data.t VALUES (1), (2)`)
+
+	// scheduleRecurrences returns the recurrences and the backup statements of
+	// the schedules created by the given statement.
+	scheduleRecurrences := func(stmt string) [][]string {
+		t.Helper()
+		return sqlDB.QueryStr(t, `SELECT recurrence, backup_stmt FROM [`+stmt+`]`)
+	}
+
+	t.Run("schedules", func(t *testing.T) {
+		for _, tc := range []struct {
+			stmt     string
+			expected [][]string
+		}{
+			{
+				`CREATE SCHEDULE 'nightly' FOR BACKUP DATABASE data INTO 'nodelocal:///a'
+					RECURRING '@daily' FULL BACKUP '@weekly'`,
+				[][]string{
+					{"@daily", "BACKUP DATABASE data INTO LATEST IN 'nodelocal:///a'"},
+					{"@weekly", "BACKUP DATABASE data INTO 'nodelocal:///a'"},
+				},
+			},
+			{
+				`CREATE SCHEDULE FOR BACKUP TABLE data.t INTO 'nodelocal:///b'
+					WITH revision_history RECURRING '@hourly'`,
+				[][]string{
+					{"@hourly", "BACKUP TABLE data.t INTO LATEST IN 'nodelocal:///b' WITH revision_history"},
+					{"@weekly", "BACKUP TABLE data.t INTO 'nodelocal:///b' WITH revision_history"},
+				},
+			},
+			{
+				`CREATE SCHEDULE FOR BACKUP TABLE data.t INTO 'nodelocal:///c' RECURRING '@monthly'`,
+				[][]string{
+					{"@monthly", "BACKUP TABLE data.t INTO 'nodelocal:///c'"},
+				},
+			},
+			{
+				`CREATE SCHEDULE FOR BACKUP TABLE data.t INTO 'nodelocal:///d'
+					RECURRING '0 */6 * * *' FULL BACKUP ALWAYS`,
+				[][]string{
+					{"0 */6 * * *", "BACKUP TABLE data.t INTO 'nodelocal:///d'"},
+				},
+			},
+		} {
+			if actual := scheduleRecurrences(tc.stmt); !reflect.DeepEqual(tc.expected, actual) {
+				t.Errorf("%s: expected %v, got %v", tc.stmt, tc.expected, actual)
+			}
+		}
+
+		sqlDB.ExpectErr(t, "invalid cron expression",
+			`CREATE SCHEDULE FOR BACKUP TABLE data.t INTO 'nodelocal:///e' RECURRING 'often'`)
+		sqlDB.ExpectErr(t, "invalid cron expression",
+			`CREATE SCHEDULE FOR BACKUP TABLE data.t INTO 'nodelocal:///e'
+				RECURRING '@daily' FULL BACKUP '* *'`)
+		sqlDB.ExpectErr(t, "does not take a value",
+			`CREATE SCHEDULE FOR BACKUP TABLE data.t INTO 'nodelocal:///e'
+				WITH revision_history = 'yes' RECURRING '@daily'`)
+	})
+
+	t.Run("secrets", func(t *testing.T) {
+		sqlDB.ExpectErr(t, "scheduled backups cannot store",
+			`CREATE SCHEDULE FOR BACKUP TABLE data.t INTO 'nodelocal:///secrets'
+				WITH encryption_passphrase = 'secret-passphrase' RECURRING '@daily'`)
+		sqlDB.ExpectErr(t, "scheduled backups cannot store",
+			`CREATE SCHEDULE FOR BACKUP TABLE data.t INTO 'nodelocal:///secrets?AWS_SECRET_ACCESS_KEY=secret-key'
+				RECURRING '@daily'`)
+		sqlDB.ExpectErr(t, "scheduled backups cannot store",
+			`CREATE SCHEDULE FOR BACKUP TABLE data.t INTO 'nodelocal:///secrets'
+				WITH encryption_key_file = 'nodelocal:///key?AWS_SECRET_ACCESS_KEY=secret-key'
+				RECURRING '@daily'`)
+		sqlDB.ExpectErr(t, "scheduled backups cannot store the CREDENTIALS parameter",
+			`CREATE SCHEDULE FOR BACKUP TABLE data.t INTO 'gs://bucket/secrets?AUTH=specified&CREDENTIALS=secret-key'
+				RECURRING '@daily'`)
+
+		// The parameters which aren't credentials are kept, so that backups to
+		// cloud storage can use implicit authentication.
+		expected := [][]string{{"@monthly",
+			"BACKUP TABLE data.t INTO 's3://bucket/implicit?AUTH=implicit&AWS_REGION=us-east-1'"}}
+		if actual := scheduleRecurrences(`CREATE SCHEDULE FOR BACKUP TABLE data.t
+			INTO 's3://bucket/implicit?AUTH=implicit&AWS_REGION=us-east-1' RECURRING '@monthly'`,
+		); !reflect.DeepEqual(expected, actual) {
+			t.Fatalf("expected %v, got %v", expected, actual)
+		}
+
+		expected = [][]string{{"@monthly",
+			"BACKUP TABLE data.t INTO 'nodelocal:///secrets' WITH encryption_key_file = 'nodelocal:///key'"}}
+		if actual := scheduleRecurrences(`CREATE SCHEDULE FOR BACKUP TABLE data.t INTO 'nodelocal:///secrets'
+			WITH encryption_key_file = 'nodelocal:///key' RECURRING '@monthly'`,
+		); !reflect.DeepEqual(expected, actual) {
+			t.Fatalf("expected %v, got %v", expected, actual)
+		}
+
+		// Neither the passphrase nor the credentials were stored.
+		sqlDB.CheckQueryResults(t, `SELECT count(*) FROM system.scheduled_jobs
+			WHERE convert_from(execution_args, 'UTF8') LIKE '%secret%'`, [][]string{{"0"}})
+	})
+
+	t.Run("control", func(t *testing.T) {
+		var id int64
+		sqlDB.QueryRow(t, `SELECT schedule_id FROM [CREATE SCHEDULE 'control' FOR BACKUP TABLE data.t
+			INTO 'nodelocal:///control' RECURRING '@monthly']`).Scan(&id)
+		status := func() string {
+			return sqlDB.QueryStr(t, `SELECT schedule_status FROM [SHOW SCHEDULE $1]`, id)[0][0]
+		}
+
+		if s := status(); s != "ACTIVE" {
+			t.Fatalf("expected ACTIVE, got %s", s)
+		}
+		sqlDB.Exec(t, `PAUSE SCHEDULE $1`, id)
+		if s := status(); s != "PAUSED" {
+			t.Fatalf("expected PAUSED, got %s", s)
+		}
+		sqlDB.Exec(t, `RESUME SCHEDULE $1`, id)
+		if s := status(); s != "ACTIVE" {
+			t.Fatalf("expected ACTIVE, got %s", s)
+		}
+		sqlDB.Exec(t, `DROP SCHEDULE $1`, id)
+		sqlDB.CheckQueryResults(t,
+			`SELECT count(*) FROM [SHOW SCHEDULES] WHERE id = `+strconv.FormatInt(id, 10),
+			[][]string{{"0"}})
+		sqlDB.ExpectErr(t, "does not exist", `PAUSE SCHEDULE $1`, id)
+	})
+
+	t.Run("execute", func(t *testing.T) {
+		var incID, fullID int64
+		rows := sqlDB.Query(t, `SELECT schedule_id FROM [CREATE SCHEDULE FOR BACKUP DATABASE data
+			INTO 'nodelocal:///execute' RECURRING '@daily' FULL BACKUP '@weekly']`)
+		for _, id := range []*int64{&incID, &fullID} {
+			if !rows.Next() {
+				t.Fatal("expected two schedules")
+			}
+			if err := rows.Scan(id); err != nil {
+				t.Fatal(err)
+			}
+		}
+		rows.Close()
+
+		// The backups are run as the owner of the schedules.
+		sqlDB.Exec(t, `CREATE USER backup_owner`)
+		sqlDB.Exec(t, `GRANT admin TO backup_owner`)
+		sqlDB.Exec(t, `UPDATE system.scheduled_jobs SET owner = 'backup_owner'
+			WHERE schedule_id IN ($1, $2)`, incID, fullID)
+
+		ex := srv.InternalExecutor().(sqlutil.InternalExecutor)
+		execCfg := srv.ExecutorConfig().(sql.ExecutorConfig)
+		env := jobs.ScheduledJobEnv{
+			DB:               kvDB,
+			InternalExecutor: ex,
+			Stopper:          srv.Stopper(),
+			Settings:         srv.ClusterSettings(),
+			PlanHookMaker: func(opName string, txn *client.Txn, user string) (interface{}, func()) {
+				return sql.NewInternalPlanner(opName, txn, user, &sql.MemoryMetrics{}, &execCfg)
+			},
+		}
+		execute := func(id int64) {
+			t.Helper()
+			schedule, err := jobs.LoadScheduledJob(ctx, ex, nil /* txn */, id)
+			if err != nil {
+				t.Fatal(err)
+			}
+			sqlDB.Exec(t, `UPDATE system.scheduled_jobs SET schedule_status = NULL WHERE schedule_id = $1`, id)
+			var jobID int64
+			if err := kvDB.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+				var err error
+				jobID, err = (&scheduledBackupExecutor{}).ExecuteJob(ctx, env, schedule, txn)
+				return err
+			}); err != nil {
+				t.Fatal(err)
+			}
+			testutils.SucceedsSoon(t, func() error {
+				s := sqlDB.QueryStr(t, `SELECT state FROM [SHOW SCHEDULE $1]`, id)[0][0]
+				if s != "succeeded" {
+					return errors.Errorf("schedule %d: %q", id, s)
+				}
+				return nil
+			})
+			sqlDB.CheckQueryResults(t,
+				`SELECT user_name, status FROM [SHOW JOBS] WHERE job_id = `+strconv.FormatInt(jobID, 10),
+				[][]string{{"backup_owner", "succeeded"}})
+		}
+
+		// The incremental schedule takes a full backup as long as the collection
+		// doesn't have any.
+		execute(incID)
+		execute(incID)
+		execute(fullID)
+		execute(incID)
+
+		var types []string
+		for _, row := range sqlDB.QueryStr(t, `SHOW BACKUPS IN 'nodelocal:///execute'`) {
+			types = append(types, row[1])
+		}
+		if expected := []string{
+			"full", "incremental", "full", "incremental",
+		}; !reflect.DeepEqual(expected, types) {
+			t.Fatalf("expected backups %v, got %v", expected, types)
+		}
+	})
+}
@@ -0,0 +1,287 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package backupccl
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"io/ioutil"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/storageccl"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/storage/cloud"
+	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
+	"github.com/cockroachdb/errors"
+)
+
+const (
+	// BackupEncryptionInfoName is the file name used for the serialized
+	// EncryptionInfo proto of an encrypted backup.
+	BackupEncryptionInfoName = "ENCRYPTION-INFO"
+
+	backupOptEncPassphrase = "encryption_passphrase"
+	backupOptEncKeyFile    = "encryption_key_file"
+)
+
+// encryptionKeyCheck is the value encrypted in EncryptionInfo.KeyCheck.
+var encryptionKeyCheck = []byte("cockroach backup encryption key check")
+
+// hasEncryptionOpts returns whether the options of a BACKUP, RESTORE or SHOW
+// BACKUP statement ask for encryption.
+func hasEncryptionOpts(opts map[string]string) (bool, error) {
+	_, passphrase := opts[backupOptEncPassphrase]
+	_, keyFile := opts[backupOptEncKeyFile]
+	if passphrase && keyFile {
+		return false, errors.Errorf("cannot use both %s and %s options",
+			backupOptEncPassphrase, backupOptEncKeyFile)
+	}
+	return passphrase || keyFile, nil
+}
+
+// checkEncryptionVersion returns an error if the options of a BACKUP or
+// RESTORE ask for encryption before all the nodes of the cluster know how to
+// encrypt and decrypt the files of Export and Import requests. A node running
+// an older version would ignore the encryption of the requests and write or
+// read plaintext files.
+func checkEncryptionVersion(
+	ctx context.Context, settings *cluster.Settings, opts map[string]string,
+) error {
+	if ok, err := hasEncryptionOpts(opts); !ok || err != nil {
+		return err
+	}
+	if !cluster.Version.IsActive(ctx, settings, cluster.VersionBackupEncryption) {
+		return errors.Errorf("the %s and %s options can only be used on a cluster that has been fully upgraded",
+			backupOptEncPassphrase, backupOptEncKeyFile)
+	}
+	return nil
+}
+
+// readEncryptionKeyFile reads a hex-encoded AES-256 key from the file at the
+// given URI.
+func readEncryptionKeyFile(
+	ctx context.Context, uri string, makeExternalStorageFromURI cloud.ExternalStorageFromURIFactory,
+) ([]byte, error) {
+	store, err := makeExternalStorageFromURI(ctx, uri)
+	if err != nil {
+		return nil, err
+	}
+	defer store.Close()
+	r, err := store.ReadFile(ctx, "")
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading %s", backupOptEncKeyFile)
+	}
+	defer r.Close()
+	contents, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading %s", backupOptEncKeyFile)
+	}
+	key, err := hex.DecodeString(string(bytes.TrimSpace(contents)))
+	if err != nil || len(key) != storageccl.EncryptionKeySize {
+		return nil, errors.Errorf("%s must contain a hex-encoded %d-byte key",
+			backupOptEncKeyFile, storageccl.EncryptionKeySize)
+	}
+	return key, nil
+}
+
+// encryptionKeyFromOpts returns the key given by the encryption options of a
+// statement: either the key derived from the passphrase and the given salt, or
+// the key read from the key file.
+func encryptionKeyFromOpts(
+	ctx context.Context,
+	opts map[string]string,
+	salt []byte,
+	makeExternalStorageFromURI cloud.ExternalStorageFromURIFactory,
+) ([]byte, error) {
+	if uri, ok := opts[backupOptEncKeyFile]; ok {
+		return readEncryptionKeyFile(ctx, uri, makeExternalStorageFromURI)
+	}
+	return storageccl.GenerateKey([]byte(opts[backupOptEncPassphrase]), salt), nil
+}
+
+// makeEncryption returns the encryption of a new backup, along with the
+// EncryptionInfo to write with it, or nil if the options of the BACKUP don't
+// ask for encryption. Incremental backups must be encrypted with the key of
+// the backups they build on, so the salt of the full backup is passed in
+// baseInfo for them.
+func makeEncryption(
+	ctx context.Context,
+	opts map[string]string,
+	baseInfo *EncryptionInfo,
+	makeExternalStorageFromURI cloud.ExternalStorageFromURIFactory,
+) (*roachpb.FileEncryptionOptions, *EncryptionInfo, error) {
+	if ok, err := hasEncryptionOpts(opts); !ok || err != nil {
+		return nil, nil, err
+	}
+	var salt []byte
+	if baseInfo != nil {
+		salt = baseInfo.Salt
+	} else if _, ok := opts[backupOptEncPassphrase]; ok {
+		var err error
+		if salt, err = storageccl.GenerateSalt(); err != nil {
+			return nil, nil, err
+		}
+	}
+	key, err := encryptionKeyFromOpts(ctx, opts, salt, makeExternalStorageFromURI)
+	if err != nil {
+		return nil, nil, err
+	}
+	keyCheck, err := storageccl.EncryptFile(encryptionKeyCheck, key)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &roachpb.FileEncryptionOptions{Key: key}, &EncryptionInfo{Salt: salt, KeyCheck: keyCheck}, nil
+}
+
+// getEncryptionFromBase returns the encryption of the existing backup at the
+// given URI, validating it against the encryption options of the statement
+// reading the backup, along with the backup's EncryptionInfo. It returns nil
+// if the options don't ask for encryption; reading the files of an encrypted
+// backup then fails.
+func getEncryptionFromBase(
+	ctx context.Context,
+	baseURI string,
+	opts map[string]string,
+	makeExternalStorageFromURI cloud.ExternalStorageFromURIFactory,
+) (*roachpb.FileEncryptionOptions, *EncryptionInfo, error) {
+	if ok, err := hasEncryptionOpts(opts); !ok || err != nil {
+		return nil, nil, err
+	}
+	store, err := makeExternalStorageFromURI(ctx, baseURI)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer store.Close()
+	info, err := readEncryptionInfo(ctx, store)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err,
+			"reading the encryption info of %s (is the backup encrypted?)", baseURI)
+	}
+	key, err := encryptionKeyFromOpts(ctx, opts, info.Salt, makeExternalStorageFromURI)
+	if err != nil {
+		return nil, nil, err
+	}
+	if check, err := storageccl.DecryptFile(info.KeyCheck, key); err != nil ||
+		!bytes.Equal(check, encryptionKeyCheck) {
+		return nil, nil, pgerror.Newf(pgcode.InvalidPassword,
+			"the %s or %s does not match the encryption of %s",
+			backupOptEncPassphrase, backupOptEncKeyFile, baseURI)
+	}
+	return &roachpb.FileEncryptionOptions{Key: key}, &info, nil
+}
+
+func readEncryptionInfo(ctx context.Context, store cloud.ExternalStorage) (EncryptionInfo, error) {
+	r, err := store.ReadFile(ctx, BackupEncryptionInfoName)
+	if err != nil {
+		return EncryptionInfo{}, err
+	}
+	defer r.Close()
+	infoBytes, err := ioutil.ReadAll(r)
+	if err != nil {
+		return EncryptionInfo{}, err
+	}
+	var info EncryptionInfo
+	if err := protoutil.Unmarshal(infoBytes, &info); err != nil {
+		return EncryptionInfo{}, err
+	}
+	return info, nil
+}
+
+func writeEncryptionInfo(
+	ctx context.Context, store cloud.ExternalStorage, info *EncryptionInfo,
+) error {
+	infoBytes, err := protoutil.Marshal(info)
+	if err != nil {
+		return err
+	}
+	return store.WriteFile(ctx, BackupEncryptionInfoName, bytes.NewReader(infoBytes))
+}
+
+// maybeEncrypt encrypts the contents of a file of a backup if the backup is
+// encrypted.
+func maybeEncrypt(contents []byte, encryption *roachpb.FileEncryptionOptions) ([]byte, error) {
+	if encryption == nil {
+		return contents, nil
+	}
+	return storageccl.EncryptFile(contents, encryption.Key)
+}
+
+// maybeDecrypt decrypts the contents of a file of a backup if the backup is
+// encrypted. It fails with a helpful error if an encrypted file is read
+// without a key.
+func maybeDecrypt(contents []byte, encryption *roachpb.FileEncryptionOptions) ([]byte, error) {
+	if encryption == nil {
+		if storageccl.AppearsEncrypted(contents) {
+			return nil, errors.Errorf("the backup is encrypted: use the %s or %s option",
+				backupOptEncPassphrase, backupOptEncKeyFile)
+		}
+		return contents, nil
+	}
+	return storageccl.DecryptFile(contents, encryption.Key)
+}
+
+// redactEncryptionOpts returns a copy of the options of a statement in which
+// the passphrase is redacted and the key file URI is sanitized, for the job
+// description.
+func redactEncryptionOpts(opts map[string]string) (map[string]string, error) {
+	redacted := make(map[string]string, len(opts))
+	for k, v := range opts {
+		redacted[k] = v
+	}
+	if _, ok := redacted[backupOptEncPassphrase]; ok {
+		redacted[backupOptEncPassphrase] = "redacted"
+	}
+	if uri, ok := redacted[backupOptEncKeyFile]; ok {
+		sanitized, err := cloud.SanitizeExternalStorageURI(uri)
+		if err != nil {
+			return nil, err
+		}
+		redacted[backupOptEncKeyFile] = sanitized
+	}
+	return redacted, nil
+}
+
+// makeJobEncryption returns the encryption options to store in the details of
+// the job of a BACKUP or RESTORE with the given options and encryption: the
+// URI of the key file, or the key derived from the passphrase.
+func makeJobEncryption(
+	opts map[string]string, encryption *roachpb.FileEncryptionOptions,
+) *jobspb.BackupEncryptionOptions {
+	if encryption == nil {
+		return nil
+	}
+	if uri, ok := opts[backupOptEncKeyFile]; ok {
+		return &jobspb.BackupEncryptionOptions{KeyFile: uri}
+	}
+	return &jobspb.BackupEncryptionOptions{Key: encryption.Key}
+}
+
+// getJobEncryption returns the encryption of a running BACKUP or RESTORE job,
+// given the encryption options in its details. The key of a key file is read
+// again and validated against the encryption of the backup at baseURI.
+func getJobEncryption(
+	ctx context.Context,
+	jobEncryption *jobspb.BackupEncryptionOptions,
+	baseURI string,
+	makeExternalStorageFromURI cloud.ExternalStorageFromURIFactory,
+) (*roachpb.FileEncryptionOptions, error) {
+	if jobEncryption == nil {
+		return nil, nil
+	}
+	if jobEncryption.KeyFile != "" {
+		opts := map[string]string{backupOptEncKeyFile: jobEncryption.KeyFile}
+		encryption, _, err := getEncryptionFromBase(ctx, baseURI, opts, makeExternalStorageFromURI)
+		return encryption, err
+	}
+	return &roachpb.FileEncryptionOptions{Key: jobEncryption.Key}, nil
+}
@@ -63,17 +63,20 @@ var restoreOptionExpectValues = map[string]sql.KVStringOptValidate{
 	restoreOptSkipMissingFKs:       sql.KVStringOptRequireNoValue,
 	restoreOptSkipMissingSequences: sql.KVStringOptRequireNoValue,
 	restoreOptSkipMissingViews:     sql.KVStringOptRequireNoValue,
+	backupOptEncPassphrase:         sql.KVStringOptRequireValue,
+	backupOptEncKeyFile:            sql.KVStringOptRequireValue,
 }
 
 func loadBackupDescs(
 	ctx context.Context,
 	uris []string,
 	makeExternalStorageFromURI cloud.ExternalStorageFromURIFactory,
+	encryption *roachpb.FileEncryptionOptions,
 ) ([]BackupDescriptor, error) {
 	backupDescs := make([]BackupDescriptor, len(uris))
 
 	for i, uri := range uris {
-		desc, err := ReadBackupDescriptorFromURI(ctx, uri, makeExternalStorageFromURI)
+		desc, err := ReadBackupDescriptorFromURI(ctx, uri, makeExternalStorageFromURI, encryption)
 		if err != nil {
 			return nil, errors.Wrapf(err, "failed to read backup descriptor")
 		}
@@ -91,7 +94,10 @@ func loadBackupDescs(
 // default) original backup locality values to URIs that currently contain
 // the backup files.
 func getBackupLocalityInfo(
-	ctx context.Context, uris []string, p sql.PlanHookState,
+	ctx context.Context,
+	uris []string,
+	p sql.PlanHookState,
+	encryption *roachpb.FileEncryptionOptions,
 ) (jobspb.RestoreDetails_BackupLocalityInfo, error) {
 	var info jobspb.RestoreDetails_BackupLocalityInfo
 	if len(uris) == 1 {
@@ -114,9 +120,9 @@ func getBackupLocalityInfo(
 	// First read the main backup descriptor, which is required to be at the first
 	// URI in the list. We don't read the table descriptors, so there's no need to
 	// upgrade them.
-	mainBackupDesc, err := readBackupDescriptor(ctx, stores[0], BackupDescriptorName)
+	mainBackupDesc, err := readBackupDescriptor(ctx, stores[0], BackupDescriptorName, encryption)
 	if err != nil {
-		manifest, manifestErr := readBackupDescriptor(ctx, stores[0], BackupManifestName)
+		manifest, manifestErr := readBackupDescriptor(ctx, stores[0], BackupManifestName, encryption)
 		if manifestErr != nil {
 			return info, err
 		}
@@ -129,7 +135,7 @@ func getBackupLocalityInfo(
 	for _, filename := range mainBackupDesc.PartitionDescriptorFilenames {
 		found := false
 		for i, store := range stores {
-			if desc, err := readBackupPartitionDescriptor(ctx, store, filename); err == nil {
+			if desc, err := readBackupPartitionDescriptor(ctx, store, filename, encryption); err == nil {
 				if desc.BackupID != mainBackupDesc.ID {
 					return info, errors.Errorf(
 						"expected backup part to have backup ID %s, found %s",
@@ -1083,6 +1089,10 @@ func WriteTableDescs(
 func restoreJobDescription(
 	p sql.PlanHookState, restore *tree.Restore, from [][]string, opts map[string]string,
 ) (string, error) {
+	opts, err := redactEncryptionOpts(opts)
+	if err != nil {
+		return "", err
+	}
 	r := &tree.Restore{
 		AsOf:    restore.AsOf,
 		Options: optsToKVOptions(opts),
@@ -1162,6 +1172,7 @@ func restore(
 	oldTableIDs []sqlbase.ID,
 	spans []roachpb.Span,
 	job *jobs.Job,
+	encryption *roachpb.FileEncryptionOptions,
 ) (roachpb.BulkOpSummary, error) {
 	// A note about contexts and spans in this method: the top-level context
 	// `restoreCtx` is used for orchestration logging. All operations that carry
@@ -1282,6 +1293,7 @@ func restore(
 				Files:         readyForImportSpan.files,
 				EndTime:       endTime,
 				Rekeys:        rekeys,
+				Encryption:    encryption,
 			}
 
 			log.VEventf(restoreCtx, 1, "importing %d of %d", idx, len(importSpans))
@@ -1398,6 +1410,13 @@ func restorePlanHook(
 				return err
 			}
 		}
+		if restoreStmt.FromLatest {
+			if from, err = resolveLatestInCollection(
+				ctx, from[0], p.ExecCfg().DistSQLSrv.ExternalStorageFromURI,
+			); err != nil {
+				return err
+			}
+		}
 		var endTime hlc.Timestamp
 		if restoreStmt.AsOf.Expr != nil {
 			var err error
@@ -1411,6 +1430,9 @@ func restorePlanHook(
 		if err != nil {
 			return err
 		}
+		if err := checkEncryptionVersion(ctx, p.ExecCfg().Settings, opts); err != nil {
+			return err
+		}
 		return doRestorePlan(ctx, restoreStmt, p, from, endTime, opts, resultsCh)
 	}
 	return fn, RestoreHeader, nil, false, nil
@@ -1425,18 +1447,28 @@ func doRestorePlan(
 	opts map[string]string,
 	resultsCh chan<- tree.Datums,
 ) error {
+	// All the backups are encrypted with the key of the first one, since
+	// incremental backups are encrypted with the key of the backups they build
+	// on.
+	encryption, _, err := getEncryptionFromBase(
+		ctx, from[0][0], opts, p.ExecCfg().DistSQLSrv.ExternalStorageFromURI)
+	if err != nil {
+		return err
+	}
+
 	defaultURIs := make([]string, len(from))
 	localityInfo := make([]jobspb.RestoreDetails_BackupLocalityInfo, len(from))
 	for i, uris := range from {
 		// The first URI in the list must contain the main BACKUP manifest.
 		defaultURIs[i] = uris[0]
-		info, err := getBackupLocalityInfo(ctx, uris, p)
+		info, err := getBackupLocalityInfo(ctx, uris, p, encryption)
 		if err != nil {
 			return err
 		}
 		localityInfo[i] = info
 	}
-	mainBackupDescs, err := loadBackupDescs(ctx, defaultURIs, p.ExecCfg().DistSQLSrv.ExternalStorageFromURI)
+	mainBackupDescs, err := loadBackupDescs(
+		ctx, defaultURIs, p.ExecCfg().DistSQLSrv.ExternalStorageFromURI, encryption)
 	if err != nil {
 		return err
 	}
@@ -1527,6 +1559,7 @@ func doRestorePlan(
 			BackupLocalityInfo: localityInfo,
 			TableDescs:         tables,
 			OverrideDB:         opts[restoreOptIntoDB],
+			Encryption:         makeJobEncryption(opts, encryption),
 		},
 		Progress: jobspb.RestoreProgress{},
 	})
@@ -1546,8 +1579,9 @@ func loadBackupSQLDescs(
 	ctx context.Context,
 	details jobspb.RestoreDetails,
 	makeExternalStorageFromURI cloud.ExternalStorageFromURIFactory,
+	encryption *roachpb.FileEncryptionOptions,
 ) ([]BackupDescriptor, BackupDescriptor, []sqlbase.Descriptor, error) {
-	backupDescs, err := loadBackupDescs(ctx, details.URIs, makeExternalStorageFromURI)
+	backupDescs, err := loadBackupDescs(ctx, details.URIs, makeExternalStorageFromURI, encryption)
 	if err != nil {
 		return nil, BackupDescriptor{}, nil, err
 	}
@@ -1723,8 +1757,14 @@ func (r *restoreResumer) Resume(
 	details := r.job.Details().(jobspb.RestoreDetails)
 	p := phs.(sql.PlanHookState)
 
+	// All the backups are encrypted with the key of the first one.
+	encryption, err := getJobEncryption(
+		ctx, details.Encryption, details.URIs[0], p.ExecCfg().DistSQLSrv.ExternalStorageFromURI)
+	if err != nil {
+		return err
+	}
 	backupDescs, latestBackupDesc, sqlDescs, err := loadBackupSQLDescs(
-		ctx, details, p.ExecCfg().DistSQLSrv.ExternalStorageFromURI,
+		ctx, details, p.ExecCfg().DistSQLSrv.ExternalStorageFromURI, encryption,
 	)
 	if err != nil {
 		return err
@@ -1759,6 +1799,7 @@ func (r *restoreResumer) Resume(
 		oldTableIDs,
 		spans,
 		r.job,
+		encryption,
 	)
 	r.res = res
 	return err
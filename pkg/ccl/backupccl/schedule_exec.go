@@ -0,0 +1,128 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package backupccl
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/errors"
+)
+
+// scheduledBackupExecutorName is the executor type of the schedules created
+// by CREATE SCHEDULE FOR BACKUP. The execution arguments of these schedules
+// are the BACKUP statement to run.
+const scheduledBackupExecutorName = "scheduled-backup-executor"
+
+// scheduledBackupExecutor runs the BACKUP statements of backup schedules.
+type scheduledBackupExecutor struct{}
+
+var _ jobs.ScheduledJobExecutor = &scheduledBackupExecutor{}
+
+// ExecuteJob implements the jobs.ScheduledJobExecutor interface. The BACKUP
+// statement of the schedule is planned as the owner of the schedule, and its
+// job is created in txn. The job is started once txn commits.
+func (e *scheduledBackupExecutor) ExecuteJob(
+	ctx context.Context, env jobs.ScheduledJobEnv, schedule *jobs.ScheduledJob, txn *client.Txn,
+) (int64, error) {
+	phs, cleanup := env.PlanHookMaker("scheduled-backup", txn, schedule.Owner)
+	defer cleanup()
+	p := phs.(sql.PlanHookState)
+
+	record, err := planScheduledBackup(ctx, p, string(schedule.ExecutionArgs))
+	if err != nil {
+		return 0, err
+	}
+	registry := p.ExecCfg().JobRegistry
+	job, err := registry.CreateJobWithTxn(ctx, record, txn)
+	if err != nil {
+		return 0, err
+	}
+
+	txn.AddCommitTrigger(func(ctx context.Context) {
+		taskName := fmt.Sprintf("scheduled-backup-%d", schedule.ID)
+		if err := env.Stopper.RunAsyncTask(ctx, taskName, func(ctx context.Context) {
+			status := "succeeded"
+			if err := runScheduledBackupJob(ctx, registry, job); err != nil {
+				log.Errorf(ctx, "scheduled backup %d failed: %s", schedule.ID, err)
+				status = fmt.Sprintf("failed: %s", err)
+			}
+			if err := schedule.SetStatus(ctx, env.InternalExecutor, nil /* txn */, status); err != nil {
+				log.Warningf(ctx, "failed to record the status of schedule %d: %s", schedule.ID, err)
+			}
+		}); err != nil {
+			log.Warningf(ctx, "failed to start the job of schedule %d: %s", schedule.ID, err)
+		}
+	})
+	return *job.ID(), nil
+}
+
+// planScheduledBackup plans the given BACKUP statement and returns the record
+// of its job. An incremental backup appended to the latest full backup of a
+// collection which has none yet, as happens when the incremental backups of a
+// schedule start before its full backups, is turned into a full backup.
+func planScheduledBackup(
+	ctx context.Context, p sql.PlanHookState, backupStmt string,
+) (jobs.Record, error) {
+	stmt, err := parser.ParseOne(backupStmt)
+	if err != nil {
+		return jobs.Record{}, err
+	}
+	backup, ok := stmt.AST.(*tree.Backup)
+	if !ok {
+		return jobs.Record{}, errors.AssertionFailedf("unexpected statement %T in backup schedule", stmt.AST)
+	}
+
+	recordFn, err := makeBackupJobRecordFn(backup, p)
+	if err != nil {
+		return jobs.Record{}, err
+	}
+	record, err := recordFn(ctx)
+	if !errors.Is(err, errNoFullBackupInCollection) {
+		return record, err
+	}
+
+	backup.AppendToLatest = false
+	log.Infof(ctx, "no full backup to append to, taking a full backup instead")
+	if recordFn, err = makeBackupJobRecordFn(backup, p); err != nil {
+		return jobs.Record{}, err
+	}
+	return recordFn(ctx)
+}
+
+// runScheduledBackupJob starts the given job, created by ExecuteJob, and
+// waits for it to finish.
+func runScheduledBackupJob(ctx context.Context, registry *jobs.Registry, job *jobs.Job) error {
+	resultsCh := make(chan tree.Datums)
+	defer close(resultsCh)
+	go func() {
+		// Drain and ignore results.
+		for range resultsCh {
+		}
+	}()
+	errCh, err := registry.StartJob(ctx, resultsCh, job)
+	if err != nil {
+		return err
+	}
+	return <-errCh
+}
+
+func init() {
+	jobs.RegisterScheduledJobExecutorFactory(
+		scheduledBackupExecutorName,
+		func() (jobs.ScheduledJobExecutor, error) {
+			return &scheduledBackupExecutor{}, nil
+		})
+}
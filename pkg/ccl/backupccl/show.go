@@ -10,6 +10,7 @@ package backupccl
 
 import (
 	"context"
+	"path"
 	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/ccl/utilccl"
@@ -48,6 +49,19 @@ func showBackupPlanHook(
 		return nil, nil, nil, false, err
 	}
 
+	if backup.InCollection {
+		return showBackupsInCollectionPlanHook(ctx, stmt, toFn, p)
+	}
+
+	expected := map[string]sql.KVStringOptValidate{
+		backupOptEncPassphrase: sql.KVStringOptRequireValue,
+		backupOptEncKeyFile:    sql.KVStringOptRequireValue,
+	}
+	optsFn, err := p.TypeAsStringOpts(backup.Options, expected)
+	if err != nil {
+		return nil, nil, nil, false, err
+	}
+
 	var shower backupShower
 	switch backup.Details {
 	case tree.BackupRangeDetails:
@@ -67,7 +81,16 @@ func showBackupPlanHook(
 		if err != nil {
 			return err
 		}
-		desc, err := ReadBackupDescriptorFromURI(ctx, str, p.ExecCfg().DistSQLSrv.ExternalStorageFromURI)
+		opts, err := optsFn()
+		if err != nil {
+			return err
+		}
+		makeExternalStorageFromURI := p.ExecCfg().DistSQLSrv.ExternalStorageFromURI
+		encryption, _, err := getEncryptionFromBase(ctx, str, opts, makeExternalStorageFromURI)
+		if err != nil {
+			return err
+		}
+		desc, err := ReadBackupDescriptorFromURI(ctx, str, makeExternalStorageFromURI, encryption)
 		if err != nil {
 			return err
 		}
@@ -92,6 +115,60 @@ func showBackupPlanHook(
 	return fn, shower.header, nil, false, nil
 }
 
+var showBackupsInCollectionHeader = sqlbase.ResultColumns{
+	{Name: "path", Typ: types.String},
+	{Name: "backup_type", Typ: types.String},
+}
+
+// showBackupsInCollectionPlanHook lists the backups of a collection written by
+// BACKUP ... INTO, each full backup followed by the incremental backups
+// appended to it.
+func showBackupsInCollectionPlanHook(
+	ctx context.Context, stmt tree.Statement, collectionFn func() (string, error), p sql.PlanHookState,
+) (sql.PlanHookRowFn, sqlbase.ResultColumns, []sql.PlanNode, bool, error) {
+	fn := func(ctx context.Context, _ []sql.PlanNode, resultsCh chan<- tree.Datums) error {
+		ctx, span := tracing.ChildSpan(ctx, stmt.StatementTag())
+		defer tracing.FinishSpan(span)
+
+		collection, err := collectionFn()
+		if err != nil {
+			return err
+		}
+		makeExternalStorageFromURI := p.ExecCfg().DistSQLSrv.ExternalStorageFromURI
+		fulls, err := listBackupSubdirs(
+			ctx, collection, backupCollectionFullGlob, makeExternalStorageFromURI)
+		if err != nil {
+			return err
+		}
+		for _, full := range fulls {
+			fullURI, err := appendPaths(collection, full)
+			if err != nil {
+				return err
+			}
+			incs, err := listBackupSubdirs(
+				ctx, fullURI, backupCollectionIncGlob, makeExternalStorageFromURI)
+			if err != nil {
+				return err
+			}
+			rows := []tree.Datums{{tree.NewDString(full), tree.NewDString("full")}}
+			for _, inc := range incs {
+				rows = append(rows, tree.Datums{
+					tree.NewDString(path.Join(full, inc)), tree.NewDString("incremental"),
+				})
+			}
+			for _, row := range rows {
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case resultsCh <- row:
+				}
+			}
+		}
+		return nil
+	}
+	return fn, showBackupsInCollectionHeader, nil, false, nil
+}
+
 type backupShower struct {
 	header sqlbase.ResultColumns
 	fn     func(BackupDescriptor) []tree.Datums
@@ -124,8 +124,8 @@ type avroMetadata map[string]interface{}
 
 // avroEnvelopeOpts controls which fields in avroEnvelopeRecord are set.
 type avroEnvelopeOpts struct {
-	beforeField, afterField     bool
-	updatedField, resolvedField bool
+	keyField, beforeField, afterField bool
+	updatedField, resolvedField       bool
 }
 
 // avroEnvelopeRecord is an `avroRecord` that wraps a changed SQL row and some
@@ -133,8 +133,8 @@ type avroEnvelopeOpts struct {
 type avroEnvelopeRecord struct {
 	avroRecord
 
-	opts          avroEnvelopeOpts
-	before, after *avroDataRecord
+	opts               avroEnvelopeOpts
+	key, before, after *avroDataRecord
 }
 
 // columnDescToAvroSchema converts a column descriptor into its corresponding
@@ -348,12 +348,18 @@ func columnDescToAvroSchema(colDesc *sqlbase.ColumnDescriptor) (*avroSchemaField
 
 // indexToAvroSchema converts a column descriptor into its corresponding avro
 // record schema. The fields are kept in the same order as columns in the index.
+// If a name suffix is provided (as opposed to avroSchemaNoSuffix), it will be
+// appended to the end of the avro record's name.
 func indexToAvroSchema(
-	tableDesc *sqlbase.TableDescriptor, indexDesc *sqlbase.IndexDescriptor,
+	tableDesc *sqlbase.TableDescriptor, indexDesc *sqlbase.IndexDescriptor, nameSuffix string,
 ) (*avroDataRecord, error) {
+	name := SQLNameToAvroName(tableDesc.Name)
+	if nameSuffix != avroSchemaNoSuffix {
+		name = name + `_` + nameSuffix
+	}
 	schema := &avroDataRecord{
 		avroRecord: avroRecord{
-			Name:       SQLNameToAvroName(tableDesc.Name),
+			Name:       name,
 			SchemaType: `record`,
 		},
 		fieldIdxByName:   make(map[string]int),
@@ -511,9 +517,10 @@ func (r *avroDataRecord) rowFromNative(native interface{}) (sqlbase.EncDatumRow,
 }
 
 // envelopeToAvroSchema creates an avro record schema for an envelope containing
-// before and after versions of a row change and metadata about that row change.
+// the key and the before and after versions of a row change and metadata about
+// that row change.
 func envelopeToAvroSchema(
-	topic string, opts avroEnvelopeOpts, before, after *avroDataRecord,
+	topic string, opts avroEnvelopeOpts, key, before, after *avroDataRecord,
 ) (*avroEnvelopeRecord, error) {
 	schema := &avroEnvelopeRecord{
 		avroRecord: avroRecord{
@@ -523,6 +530,15 @@ func envelopeToAvroSchema(
 		opts: opts,
 	}
 
+	if opts.keyField {
+		schema.key = key
+		keyField := &avroSchemaField{
+			Name:       `key`,
+			SchemaType: key,
+			Default:    nil,
+		}
+		schema.Fields = append(schema.Fields, keyField)
+	}
 	if opts.beforeField {
 		schema.before = before
 		beforeField := &avroSchemaField{
@@ -572,9 +588,16 @@ func envelopeToAvroSchema(
 // BinaryFromRow encodes the given metadata and row data into avro's defined
 // binary format.
 func (r *avroEnvelopeRecord) BinaryFromRow(
-	buf []byte, meta avroMetadata, beforeRow, afterRow sqlbase.EncDatumRow,
+	buf []byte, meta avroMetadata, keyRow, beforeRow, afterRow sqlbase.EncDatumRow,
 ) ([]byte, error) {
 	native := map[string]interface{}{}
+	if r.opts.keyField {
+		keyNative, err := r.key.nativeFromRow(keyRow)
+		if err != nil {
+			return nil, err
+		}
+		native[`key`] = keyNative
+	}
 	if r.opts.beforeField {
 		if beforeRow == nil {
 			native[`before`] = nil
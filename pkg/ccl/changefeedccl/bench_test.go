@@ -138,8 +138,9 @@ func (s *benchSink) EmitResolvedTimestamp(ctx context.Context, e Encoder, ts hlc
 	}
 	return s.emit(int64(len(p)))
 }
-func (s *benchSink) Flush(_ context.Context) error { return nil }
-func (s *benchSink) Close() error                  { return nil }
+func (s *benchSink) AddTarget(jobspb.ChangefeedTarget) {}
+func (s *benchSink) Flush(_ context.Context) error     { return nil }
+func (s *benchSink) Close() error                      { return nil }
 func (s *benchSink) emit(bytes int64) error {
 	s.Lock()
 	defer s.Unlock()
@@ -217,7 +218,7 @@ func createBenchmarkChangefeed(
 		targets:  details.Targets,
 		m:        th,
 	}
-	rowsFn := kvsToRows(s.LeaseManager().(*sql.LeaseManager), details, buf.Get)
+	rowsFn := kvsToRows(s.LeaseManager().(*sql.LeaseManager), details, nil /* sel */, buf.Get)
 	sf := makeSpanFrontier(spans...)
 	tickFn := emitEntries(
 		s.ClusterSettings(), details, sf, encoder, sink, rowsFn, TestingKnobs{}, metrics)
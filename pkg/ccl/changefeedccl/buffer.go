@@ -69,6 +69,41 @@ func (b *buffer) AddResolved(ctx context.Context, span roachpb.Span, ts hlc.Time
 	return b.addEntry(ctx, bufferEntry{resolved: &jobspb.ResolvedSpan{Span: span, Timestamp: ts}})
 }
 
+// AddResolvedBoundary inserts a resolved timestamp notification in the buffer
+// for the last timestamp before the changefeed stops, because of a schema
+// change or because its initial scan is done.
+func (b *buffer) AddResolvedBoundary(
+	ctx context.Context, span roachpb.Span, ts hlc.Timestamp,
+) error {
+	return b.addEntry(ctx, bufferEntry{resolved: &jobspb.ResolvedSpan{
+		Span: span, Timestamp: ts, BoundaryReached: true,
+	}})
+}
+
+// AddTableSpan inserts a notification in the buffer that the primary index
+// span of a table created in the watched database of a changefeed on a
+// database is now watched. The span is resolved at the given timestamp, which
+// is before the table was created.
+func (b *buffer) AddTableSpan(
+	ctx context.Context, tableID sqlbase.ID, name string, span roachpb.Span, ts hlc.Timestamp,
+) error {
+	return b.addEntry(ctx, bufferEntry{resolved: &jobspb.ResolvedSpan{
+		Span: span, Timestamp: ts, TableID: tableID, AddedTableName: name,
+	}})
+}
+
+// RemoveTableSpan inserts a notification in the buffer that the primary index
+// span of a table dropped from the watched database of a changefeed on a
+// database is no longer watched. The span is resolved at the given timestamp,
+// which is when the table was dropped.
+func (b *buffer) RemoveTableSpan(
+	ctx context.Context, tableID sqlbase.ID, span roachpb.Span, ts hlc.Timestamp,
+) error {
+	return b.addEntry(ctx, bufferEntry{resolved: &jobspb.ResolvedSpan{
+		Span: span, Timestamp: ts, TableID: tableID, Removed: true,
+	}})
+}
+
 func (b *buffer) addEntry(ctx context.Context, e bufferEntry) error {
 	select {
 	case <-ctx.Done():
@@ -52,10 +52,12 @@ type emitEntry struct {
 
 // kvsToRows gets changed kvs from a closure and converts them into sql rows. It
 // returns a closure that may be repeatedly called to advance the changefeed.
-// The returned closure is not threadsafe.
+// The returned closure is not threadsafe. If sel is non-nil, it is applied to
+// each row before the row is emitted.
 func kvsToRows(
 	leaseMgr *sql.LeaseManager,
 	details jobspb.ChangefeedDetails,
+	sel *changefeedSelect,
 	inputFn func(context.Context) (bufferEntry, error),
 ) func(context.Context) ([]emitEntry, error) {
 	_, withDiff := details.Opts[optDiff]
@@ -76,8 +78,11 @@ func kvsToRows(
 		if err != nil {
 			return nil, err
 		}
-		if _, ok := details.Targets[desc.ID]; !ok {
-			// This kv is for an interleaved table that we're not watching.
+		if _, ok := details.Targets[desc.ID]; !ok &&
+			!(details.DatabaseID != 0 && isDatabaseTable(details.DatabaseID, desc)) {
+			// This kv is for an interleaved table that we're not watching. Tables
+			// created in a watched database are not in the targets of the spec,
+			// but their spans are only watched once they're part of the feed.
 			if log.V(3) {
 				log.Infof(ctx, `skipping key from unwatched table %s: %s`, desc.Name, kv.Key)
 			}
@@ -172,6 +177,12 @@ func kvsToRows(
 			}
 		}
 
+		if sel != nil {
+			if ok, err := sel.apply(&r.row); err != nil || !ok {
+				return output, err
+			}
+		}
+
 		output = append(output, r)
 		return output, nil
 	}
@@ -277,6 +288,10 @@ func emitEntries(
 	var lastFlush time.Time
 	// TODO(dan): We could keep these in `sf` to eliminate dups.
 	var resolvedSpans []jobspb.ResolvedSpan
+	// boundaryReached is set when resolvedSpans has a span resolved up to a
+	// schema change which stops the changefeed, after which no more resolved
+	// timestamps are coming to trigger a flush.
+	var boundaryReached bool
 
 	return func(ctx context.Context) ([]jobspb.ResolvedSpan, error) {
 		inputs, err := inputFn(ctx)
@@ -301,8 +316,22 @@ func emitEntries(
 				}
 			}
 			if input.resolved != nil {
-				_ = sf.Forward(input.resolved.Span, input.resolved.Timestamp)
+				r := input.resolved
+				switch {
+				case len(r.Span.Key) == 0:
+					// The boundary of a changefeed which watches no spans.
+				case r.Removed:
+					_ = sf.RemoveSpans(r.Span)
+				case r.TableID != 0:
+					// A table created in a watched database. Its rows are emitted
+					// under the name it was created with.
+					sink.AddTarget(jobspb.ChangefeedTarget{StatementTimeName: r.AddedTableName})
+					sf.AddSpansAt(r.Timestamp, r.Span)
+				default:
+					_ = sf.Forward(r.Span, r.Timestamp)
+				}
 				resolvedSpans = append(resolvedSpans, *input.resolved)
+				boundaryReached = boundaryReached || input.resolved.BoundaryReached
 			}
 		}
 
@@ -331,7 +360,8 @@ func emitEntries(
 		} else {
 			timeBetweenFlushes = changefeedPollInterval.Get(&settings.SV) / 5
 		}
-		if len(resolvedSpans) == 0 || timeutil.Since(lastFlush) < timeBetweenFlushes {
+		if len(resolvedSpans) == 0 ||
+			(timeutil.Since(lastFlush) < timeBetweenFlushes && !boundaryReached) {
 			return nil, nil
 		}
 
@@ -350,6 +380,7 @@ func emitEntries(
 		}
 		ret := append([]jobspb.ResolvedSpan(nil), resolvedSpans...)
 		resolvedSpans = resolvedSpans[:0]
+		boundaryReached = false
 		return ret, nil
 	}
 }
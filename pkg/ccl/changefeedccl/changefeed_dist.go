@@ -79,6 +79,11 @@ func distChangefeedFlow(
 		// ones at the statement time may have been garbage collected by now.
 		spansTS = initialHighWater
 	}
+	if details.DatabaseID != 0 {
+		// The targets of a changefeed on a database were listed when it was
+		// last planned, and include tables created after the high-water.
+		spansTS = phs.ExecCfg().Clock.Now()
+	}
 
 	execCfg := phs.ExecCfg()
 	trackedSpans, err := fetchSpansForTargets(ctx, execCfg.DB, details.Targets, spansTS)
@@ -94,8 +99,10 @@ func distChangefeedFlow(
 	planCtx := dsp.NewPlanningCtx(ctx, evalCtx, noTxn)
 
 	var spanPartitions []sql.SpanPartition
-	if details.SinkURI == `` {
-		// Sinkless feeds get one ChangeAggregator on the gateway.
+	if details.SinkURI == `` || details.DatabaseID != 0 {
+		// Sinkless feeds get one ChangeAggregator on the gateway. So do feeds on
+		// a database, whose single ChangeAggregator watches the tables as
+		// they're created, even if there are none yet.
 		spanPartitions = []sql.SpanPartition{{Node: gatewayNodeID, Spans: trackedSpans}}
 	} else {
 		// All other feeds get a ChangeAggregator local on the leaseholder.
@@ -130,10 +137,10 @@ func distChangefeedFlow(
 			},
 		})
 	}
-	// NB: This SpanFrontier processor depends on the set of tracked spans being
-	// static. Currently there is no way for them to change after the changefeed
-	// is created, even if it is paused and unpaused, but #28982 describes some
-	// ways that this might happen in the future.
+	// NB: This SpanFrontier processor starts with the tracked spans of the
+	// targets. Only the spans of a changefeed on a database change afterwards,
+	// as its ChangeAggregator adds and removes the spans of the tables created
+	// and dropped in the database.
 	changeFrontierSpec := execinfrapb.ChangeFrontierSpec{
 		TrackedSpans: trackedSpans,
 		Feed:         details,
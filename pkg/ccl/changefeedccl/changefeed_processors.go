@@ -14,9 +14,11 @@ import (
 	"math"
 	"time"
 
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
 	"github.com/cockroachdb/cockroach/pkg/jobs"
 	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql"
 	"github.com/cockroachdb/cockroach/pkg/sql/execinfra"
 	"github.com/cockroachdb/cockroach/pkg/sql/execinfrapb"
@@ -187,6 +189,11 @@ func (ca *changeAggregator) Start(ctx context.Context) context.Context {
 	if b, ok := ca.sink.(*bufferSink); ok {
 		ca.changedRowBuf = &b.buf
 	}
+	if c, ok := ca.sink.(*cloudStorageSink); ok {
+		if e, ok := ca.encoder.(fileEncoder); ok {
+			c.fileEncoder = e
+		}
+	}
 
 	// The job registry has a set of metrics used to monitor the various jobs it
 	// runs. They're all stored as the `metric.Struct` interface because of
@@ -218,7 +225,8 @@ func (ca *changeAggregator) Start(ctx context.Context) context.Context {
 		ca.flowCtx.Cfg.Settings, ca.flowCtx.Cfg.DB, ca.flowCtx.Cfg.DB.Clock(), ca.flowCtx.Cfg.Gossip,
 		spans, ca.spec.Feed, initialHighWater, buf, leaseMgr, metrics, ca.pollerMemMon,
 	)
-	rowsFn := kvsToRows(leaseMgr, ca.spec.Feed, buf.Get)
+	sel := makeChangefeedSelect(ca.flowCtx.NewEvalCtx(), ca.spec.Feed.Select)
+	rowsFn := kvsToRows(leaseMgr, ca.spec.Feed, sel, buf.Get)
 
 	ca.tickFn = emitEntries(
 		ca.flowCtx.Cfg.Settings, ca.spec.Feed, sf, ca.encoder, ca.sink, rowsFn, knobs, metrics)
@@ -356,6 +364,10 @@ type changeFrontier struct {
 	// sf contains the current resolved timestamp high-water for the tracked
 	// span set.
 	sf *spanFrontier
+	// targets are the tables watched by the changefeed. They start out as the
+	// targets of the spec, and the tables of a database changefeed are added
+	// and removed as they're created and dropped.
+	targets jobspb.ChangefeedTargets
 	// encoder is the Encoder to use for resolved timestamp serialization.
 	encoder Encoder
 	// sink is the Sink to write resolved timestamps to. Rows are never written
@@ -372,6 +384,15 @@ type changeFrontier struct {
 	// jobProgressedFn, if non-nil, is called to checkpoint the changefeed's
 	// progress in the corresponding system job entry.
 	jobProgressedFn func(context.Context, jobs.HighWaterProgressedFn) error
+	// job, if non-nil, is the job of the changefeed. It is used to forward the
+	// protected timestamp record of the changefeed.
+	job *jobs.Job
+	// lastProtectedTimestampUpdate is the last time the protected timestamp
+	// record of the changefeed was forwarded.
+	lastProtectedTimestampUpdate time.Time
+	// lastScanProgressUpdate is the last time the fraction of the initial scan
+	// of an initial_scan_only changefeed was reported.
+	lastScanProgressUpdate time.Time
 	// highWaterAtStart is the greater of the job high-water and the timestamp the
 	// CHANGEFEED statement was run at. It's used in an assertion that we never
 	// regress the job high-water.
@@ -388,6 +409,12 @@ type changeFrontier struct {
 	// metricsID is used as the unique id of this changefeed in the
 	// metrics.MaxBehindNanos map.
 	metricsID int
+	// boundaryReached is set once every span was resolved up to a schema
+	// change which stops the changefeed, or up to the initial scan of an
+	// initial_scan_only changefeed. The changefeed then finishes with
+	// boundaryErr once the buffered rows have been returned.
+	boundaryReached bool
+	boundaryErr     error
 }
 
 var _ execinfra.Processor = &changeFrontier{}
@@ -408,6 +435,10 @@ func newChangeFrontierProcessor(
 		memAcc:  memMonitor.MakeBoundAccount(),
 		input:   input,
 		sf:      makeSpanFrontier(spec.TrackedSpans...),
+		targets: make(jobspb.ChangefeedTargets, len(spec.Feed.Targets)),
+	}
+	for id, target := range spec.Feed.Targets {
+		cf.targets[id] = target
 	}
 	if err := cf.Init(
 		cf, &execinfrapb.PostProcessSpec{},
@@ -492,6 +523,8 @@ func (cf *changeFrontier) Start(ctx context.Context) context.Context {
 			return ctx
 		}
 		cf.jobProgressedFn = job.HighWaterProgressed
+		cf.job = job
+		cf.lastProtectedTimestampUpdate = timeutil.Now()
 
 		p := job.Progress()
 		if ts := p.GetHighWater(); ts != nil {
@@ -551,6 +584,9 @@ func (cf *changeFrontier) Next() (sqlbase.EncDatumRow, *execinfrapb.ProducerMeta
 			return cf.passthroughBuf.Pop(), nil
 		} else if !cf.resolvedBuf.IsEmpty() {
 			return cf.resolvedBuf.Pop(), nil
+		} else if cf.boundaryReached {
+			cf.MoveToDraining(cf.boundaryErr)
+			break
 		}
 
 		row, meta := cf.input.Next()
@@ -610,7 +646,35 @@ func (cf *changeFrontier) noteResolvedSpan(d sqlbase.EncDatum) error {
 		return nil
 	}
 
-	frontierChanged := cf.sf.Forward(resolved.Span, resolved.Timestamp)
+	var frontierChanged bool
+	switch {
+	case len(resolved.Span.Key) == 0:
+		// The boundary of a changefeed which watches no spans, i.e. on a
+		// database without tables. There is no frontier to checkpoint, so
+		// checkpoint the boundary itself.
+		if cf.jobProgressedFn != nil {
+			if err := cf.jobProgressedFn(cf.Ctx, func(
+				context.Context, jobspb.ProgressDetails,
+			) hlc.Timestamp {
+				return resolved.Timestamp
+			}); err != nil {
+				return err
+			}
+		}
+	case resolved.Removed:
+		// The table was dropped from the watched database.
+		delete(cf.targets, resolved.TableID)
+		frontierChanged = cf.sf.RemoveSpans(resolved.Span)
+	case resolved.TableID != 0:
+		// The table was created in the watched database. The frontier is at most
+		// the timestamp of the new span, so adding it doesn't move it.
+		target := jobspb.ChangefeedTarget{StatementTimeName: resolved.AddedTableName}
+		cf.targets[resolved.TableID] = target
+		cf.sink.AddTarget(target)
+		cf.sf.AddSpansAt(resolved.Timestamp, resolved.Span)
+	default:
+		frontierChanged = cf.sf.Forward(resolved.Span, resolved.Timestamp)
+	}
 	if frontierChanged {
 		newResolved := cf.sf.Frontier()
 		cf.metrics.mu.Lock()
@@ -621,6 +685,9 @@ func (cf *changeFrontier) noteResolvedSpan(d sqlbase.EncDatum) error {
 		if err := checkpointResolvedTimestamp(cf.Ctx, cf.jobProgressedFn, cf.sf); err != nil {
 			return err
 		}
+		if err := cf.maybeForwardProtectedTimestamp(cf.Ctx, newResolved); err != nil {
+			return err
+		}
 		sinceEmitted := newResolved.GoTime().Sub(cf.lastEmitResolved)
 		if cf.freqEmitResolved != emitNoResolved && sinceEmitted >= cf.freqEmitResolved {
 			// Keeping this after the checkpointResolvedTimestamp call will avoid
@@ -632,6 +699,34 @@ func (cf *changeFrontier) noteResolvedSpan(d sqlbase.EncDatum) error {
 		}
 	}
 
+	if resolved.BoundaryReached &&
+		(len(resolved.Span.Key) == 0 || cf.sf.Frontier() == resolved.Timestamp) {
+		// Every change before the boundary has been emitted and checkpointed.
+		// Make sure the resolved timestamp is emitted too, so the consumer
+		// knows it has seen everything, then stop.
+		if cf.freqEmitResolved != emitNoResolved && cf.lastEmitResolved != resolved.Timestamp.GoTime() {
+			if err := emitResolvedTimestamp(cf.Ctx, cf.encoder, cf.sink, resolved.Timestamp); err != nil {
+				return err
+			}
+			cf.lastEmitResolved = resolved.Timestamp.GoTime()
+		}
+		cf.boundaryReached = true
+		if _, ok := cf.spec.Feed.Opts[optInitialScanOnly]; !ok {
+			cf.boundaryErr = errors.Errorf(`schema change occurred at %s`, resolved.Timestamp.Next())
+		}
+	} else if resolved.BoundaryReached && cf.job != nil {
+		if _, ok := cf.spec.Feed.Opts[optInitialScanOnly]; ok &&
+			timeutil.Since(cf.lastScanProgressUpdate) >= scanProgressUpdateInterval {
+			// Part of the initial scan was emitted. Report how much, until the
+			// high-water is checkpointed once all of it is.
+			fraction := scanFraction(cf.sf, resolved.Timestamp)
+			if err := cf.job.FractionProgressed(cf.Ctx, jobs.FractionUpdater(fraction)); err != nil {
+				return err
+			}
+			cf.lastScanProgressUpdate = timeutil.Now()
+		}
+	}
+
 	// Potentially log the most behind span in the frontier for debugging. These
 	// two cluster setting values represent the target responsiveness of poller
 	// and range feed. The cluster setting for switching between poller and
@@ -663,6 +758,54 @@ func (cf *changeFrontier) noteResolvedSpan(d sqlbase.EncDatum) error {
 	return nil
 }
 
+// scanProgressUpdateInterval is the minimum time between the reports of the
+// fraction of the initial scan of an initial_scan_only changefeed.
+const scanProgressUpdateInterval = time.Second
+
+// scanFraction returns the fraction of the spans tracked by sf which are
+// resolved up to the scan at ts.
+func scanFraction(sf *spanFrontier, ts hlc.Timestamp) float32 {
+	var scanned, total int
+	sf.Entries(func(_ roachpb.Span, spanTS hlc.Timestamp) {
+		total++
+		if !spanTS.Less(ts) {
+			scanned++
+		}
+	})
+	if total == 0 {
+		return 0
+	}
+	return float32(scanned) / float32(total)
+}
+
+// maybeForwardProtectedTimestamp replaces the protected timestamp record of
+// the changefeed with one at the new high-water, so that the record doesn't
+// prevent the garbage collection of the data the changefeed already emitted.
+// This is done at most once per changefeed.protect_timestamp_interval.
+func (cf *changeFrontier) maybeForwardProtectedTimestamp(
+	ctx context.Context, resolved hlc.Timestamp,
+) error {
+	pts := cf.flowCtx.Cfg.ProtectedTimestampProvider
+	if cf.job == nil || pts == nil {
+		return nil
+	}
+	interval := changefeedProtectTimestampInterval.Get(&cf.flowCtx.Cfg.Settings.SV)
+	if timeutil.Since(cf.lastProtectedTimestampUpdate) < interval ||
+		!cluster.Version.IsActive(ctx, cf.flowCtx.Cfg.Settings, cluster.VersionProtectedTimestamps) {
+		return nil
+	}
+	cf.lastProtectedTimestampUpdate = timeutil.Now()
+	progress := cf.job.Progress()
+	cfProgress := progress.GetChangefeed()
+	if cfProgress == nil {
+		return nil
+	}
+	return cf.flowCtx.Cfg.DB.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		_, err := protectTimestamp(ctx, txn, pts, cf.job, cf.targets, resolved, *cfProgress)
+		return err
+	})
+}
+
 // ConsumerDone is part of the RowSource interface.
 func (cf *changeFrontier) ConsumerDone() {
 	cf.MoveToDraining(nil /* err */)
@@ -13,6 +13,7 @@ import (
 	"encoding/hex"
 	"math/rand"
 	"net/url"
+	"reflect"
 	"sort"
 	"time"
 
@@ -29,10 +30,12 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
 	"github.com/cockroachdb/cockroach/pkg/storage/cloud"
+	"github.com/cockroachdb/cockroach/pkg/storage/protectedts"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/retry"
 	"github.com/cockroachdb/cockroach/pkg/util/tracing"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
 	"github.com/pkg/errors"
 )
 
@@ -48,6 +51,8 @@ func init() {
 
 type envelopeType string
 type formatType string
+type schemaChangeEventClass string
+type schemaChangePolicy string
 
 const (
 	optConfluentSchemaRegistry = `confluent_schema_registry`
@@ -58,25 +63,54 @@ const (
 	optResolvedTimestamps      = `resolved`
 	optUpdatedTimestamps       = `updated`
 	optDiff                    = `diff`
+	optInitialScan             = `initial_scan`
+	optNoInitialScan           = `no_initial_scan`
+	optInitialScanOnly         = `initial_scan_only`
+	optSchemaChangeEvents      = `schema_change_events`
+	optSchemaChangePolicy      = `schema_change_policy`
+	optNullAs                  = `nullas`
 
 	optEnvelopeKeyOnly       envelopeType = `key_only`
 	optEnvelopeRow           envelopeType = `row`
 	optEnvelopeDeprecatedRow envelopeType = `deprecated_row`
 	optEnvelopeWrapped       envelopeType = `wrapped`
 
-	optFormatJSON formatType = `json`
-	optFormatAvro formatType = `experimental_avro`
+	optFormatJSON     formatType = `json`
+	optFormatAvro     formatType = `experimental_avro`
+	optFormatAvroOCF  formatType = `avro_ocf`
+	optFormatCSV      formatType = `csv`
+	optFormatProtobuf formatType = `protobuf`
+
+	// optSchemaChangeEventClassDefault makes the schema changes which backfill
+	// a column the events of a changefeed. optSchemaChangeEventClassColumnChange
+	// also makes any other change to the columns of a table an event.
+	optSchemaChangeEventClassDefault      schemaChangeEventClass = `default`
+	optSchemaChangeEventClassColumnChange schemaChangeEventClass = `column_changes`
+
+	// optSchemaChangePolicyBackfill re-emits every row of a table at the
+	// timestamp of each schema change event, optSchemaChangePolicyNoBackfill
+	// doesn't, and optSchemaChangePolicyStop stops the changefeed with an error
+	// once every change before the event has been emitted.
+	optSchemaChangePolicyBackfill   schemaChangePolicy = `backfill`
+	optSchemaChangePolicyNoBackfill schemaChangePolicy = `nobackfill`
+	optSchemaChangePolicyStop       schemaChangePolicy = `stop`
 
 	sinkParamCACert           = `ca_cert`
 	sinkParamClientCert       = `client_cert`
 	sinkParamClientKey        = `client_key`
 	sinkParamFileSize         = `file_size`
+	sinkParamFlushInterval    = `flush_interval`
+	sinkParamMaxBatchSize     = `max_batch_size`
+	sinkParamMaxRetries       = `max_retries`
+	sinkParamRetryBackoff     = `retry_backoff`
+	sinkParamRetryMaxBackoff  = `retry_max_backoff`
 	sinkParamSchemaTopic      = `schema_topic`
 	sinkParamTLSEnabled       = `tls_enabled`
 	sinkParamTopicPrefix      = `topic_prefix`
 	sinkSchemeBuffer          = ``
 	sinkSchemeExperimentalSQL = `experimental-sql`
 	sinkSchemeKafka           = `kafka`
+	sinkSchemeWebhookHTTPS    = `webhook-https`
 	sinkParamSASLEnabled      = `sasl_enabled`
 	sinkParamSASLHandshake    = `sasl_handshake`
 	sinkParamSASLUser         = `sasl_user`
@@ -92,6 +126,12 @@ var changefeedOptionExpectValues = map[string]sql.KVStringOptValidate{
 	optResolvedTimestamps:      sql.KVStringOptAny,
 	optUpdatedTimestamps:       sql.KVStringOptRequireNoValue,
 	optDiff:                    sql.KVStringOptRequireNoValue,
+	optInitialScan:             sql.KVStringOptRequireNoValue,
+	optNoInitialScan:           sql.KVStringOptRequireNoValue,
+	optInitialScanOnly:         sql.KVStringOptRequireNoValue,
+	optSchemaChangeEvents:      sql.KVStringOptRequireValue,
+	optSchemaChangePolicy:      sql.KVStringOptRequireValue,
+	optNullAs:                  sql.KVStringOptRequireValue,
 }
 
 // changefeedPlanHook implements sql.PlanHookFn.
@@ -169,24 +209,63 @@ func changefeedPlanHook(
 		statementTime := hlc.Timestamp{
 			WallTime: p.ExtendedEvalContext().GetStmtTimestamp().UnixNano(),
 		}
-		var initialHighWater hlc.Timestamp
 		if cursor, ok := opts[optCursor]; ok {
 			asOf := tree.AsOfClause{Expr: tree.NewStrVal(cursor)}
 			var err error
-			if initialHighWater, err = p.EvalAsOfTimestamp(asOf); err != nil {
+			if statementTime, err = p.EvalAsOfTimestamp(asOf); err != nil {
 				return err
 			}
-			statementTime = initialHighWater
+		}
+		// An empty initial high-water makes the changefeed start with a scan of
+		// its targets at the statement time.
+		var initialHighWater hlc.Timestamp
+		if !initialScanFromOptions(opts) {
+			initialHighWater = statementTime
 		}
 
-		// For now, disallow targeting a database or wildcard table selection.
-		// Getting it right as tables enter and leave the set over time is
-		// tricky.
-		if len(changefeedStmt.Targets.Databases) > 0 {
-			return errors.Errorf(`CHANGEFEED cannot target %s`,
-				tree.AsString(&changefeedStmt.Targets))
+		// A changefeed created with AS SELECT watches the one table that is
+		// selected from.
+		targetList := changefeedStmt.Targets
+		if changefeedStmt.Select != nil {
+			if !cluster.Version.IsActive(ctx, p.ExecCfg().Settings, cluster.VersionChangefeedSelect) {
+				return errors.Errorf(`CHANGEFEED ... AS SELECT requires all nodes to be upgraded to %s`,
+					cluster.VersionByKey(cluster.VersionChangefeedSelect))
+			}
+			tn, err := changefeedSelectTarget(changefeedStmt.Select)
+			if err != nil {
+				return err
+			}
+			// Rows which stop matching the WHERE clause are emitted as
+			// deletions, which requires their previous values.
+			if _, ok := opts[optDiff]; changefeedStmt.Select.Where != nil && !ok {
+				return errors.Errorf(`CHANGEFEED ... AS SELECT with a WHERE clause requires the %s option`,
+					optDiff)
+			}
+			targetList = tree.TargetList{Tables: tree.TablePatterns{tn}}
+		}
+
+		// A single database may be targeted, in which case the set of watched
+		// tables follows the tables of the database as they are created and
+		// dropped. For now, disallow targeting several databases or a wildcard
+		// table selection.
+		watchDatabase := len(targetList.Databases) > 0
+		if watchDatabase {
+			if len(targetList.Databases) > 1 || len(targetList.Tables) > 0 {
+				return errors.Errorf(`CHANGEFEED cannot target %s`, tree.AsString(&targetList))
+			}
+			if !cluster.Version.IsActive(
+				ctx, p.ExecCfg().Settings, cluster.VersionChangefeedDatabaseTargets,
+			) {
+				return errors.Errorf(`CHANGEFEED FOR DATABASE requires all nodes to be upgraded to %s`,
+					cluster.VersionByKey(cluster.VersionChangefeedDatabaseTargets))
+			}
+			// The set of watched tables is updated by replanning the changefeed,
+			// which only jobs do.
+			if unspecifiedSink {
+				return errors.New(`CHANGEFEED FOR DATABASE requires a sink`)
+			}
 		}
-		for _, t := range changefeedStmt.Targets.Tables {
+		for _, t := range targetList.Tables {
 			p, err := t.NormalizeTablePattern()
 			if err != nil {
 				return err
@@ -198,18 +277,51 @@ func changefeedPlanHook(
 
 		// This grabs table descriptors once to get their ids.
 		targetDescs, _, err := backupccl.ResolveTargetsToDescriptors(
-			ctx, p, statementTime, changefeedStmt.Targets)
+			ctx, p, statementTime, targetList)
 		if err != nil {
 			return err
 		}
-		targets := make(jobspb.ChangefeedTargets, len(targetDescs))
-		for _, desc := range targetDescs {
-			if tableDesc := desc.Table(hlc.Timestamp{}); tableDesc != nil {
-				targets[tableDesc.ID] = jobspb.ChangefeedTarget{
-					StatementTimeName: tableDesc.Name,
+		var databaseID sqlbase.ID
+		var targets jobspb.ChangefeedTargets
+		if watchDatabase {
+			for _, desc := range targetDescs {
+				if dbDesc := desc.GetDatabase(); dbDesc != nil {
+					databaseID = dbDesc.ID
 				}
-				if err := validateChangefeedTable(targets, tableDesc); err != nil {
-					return err
+			}
+			// The tables of the database are not taken from the resolved
+			// descriptors, which skip the ones that are not public, so that the
+			// same tables are watched as after the changefeed is replanned.
+			targets, err = fetchDatabaseTargets(ctx, p.ExecCfg().DB, databaseID, statementTime)
+			if err != nil {
+				return err
+			}
+		} else {
+			targets = make(jobspb.ChangefeedTargets, len(targetDescs))
+			for _, desc := range targetDescs {
+				if tableDesc := desc.Table(hlc.Timestamp{}); tableDesc != nil {
+					targets[tableDesc.ID] = jobspb.ChangefeedTarget{
+						StatementTimeName: tableDesc.Name,
+					}
+					if err := validateChangefeedTable(targets, 0 /* databaseID */, tableDesc); err != nil {
+						return err
+					}
+				}
+			}
+		}
+
+		var selectClause string
+		if changefeedStmt.Select != nil {
+			selectClause = tree.AsStringWithFlags(changefeedStmt.Select, tree.FmtParsable)
+			// Bind the SELECT clause to the table now, so that errors in it are
+			// returned before the changefeed is started.
+			for _, desc := range targetDescs {
+				if tableDesc := desc.Table(hlc.Timestamp{}); tableDesc != nil {
+					_, err := bindChangefeedSelect(
+						&p.ExtendedEvalContext().EvalContext, selectClause, tableDesc)
+					if err != nil {
+						return err
+					}
 				}
 			}
 		}
@@ -219,6 +331,8 @@ func changefeedPlanHook(
 			Opts:          opts,
 			SinkURI:       sinkURI,
 			StatementTime: statementTime,
+			DatabaseID:    databaseID,
+			Select:        selectClause,
 		}
 		progress := jobspb.Progress{
 			Progress: &jobspb.Progress_HighWater{HighWater: &initialHighWater},
@@ -263,6 +377,14 @@ func changefeedPlanHook(
 		if _, err := getEncoder(details.Opts); err != nil {
 			return err
 		}
+		switch formatType(details.Opts[optFormat]) {
+		case optFormatAvroOCF, optFormatCSV, optFormatProtobuf:
+			// These formats are only readable as whole files.
+			if !isCloudStorageSink(parsedSink) {
+				return errors.Errorf(`%s=%s is only usable with cloud storage sinks`,
+					optFormat, details.Opts[optFormat])
+			}
+		}
 		if isCloudStorageSink(parsedSink) {
 			details.Opts[optKeyInValue] = ``
 		}
@@ -275,6 +397,9 @@ func changefeedPlanHook(
 		telemetry.Count(`changefeed.create.sink.` + telemetrySink)
 		telemetry.Count(`changefeed.create.format.` + details.Opts[optFormat])
 		telemetry.CountBucketed(`changefeed.create.num_tables`, int64(len(targets)))
+		if changefeedStmt.Select != nil {
+			telemetry.Count(`changefeed.create.select`)
+		}
 
 		if details.SinkURI == `` {
 			err := distChangefeedFlow(ctx, p, 0 /* jobID */, details, progress, resultsCh)
@@ -354,6 +479,7 @@ func changefeedJobDescription(
 	c := &tree.CreateChangefeed{
 		Targets: changefeed.Targets,
 		SinkURI: tree.NewDString(cleanedSinkURI),
+		Select:  changefeed.Select,
 	}
 	for k, v := range opts {
 		opt := tree.KVOption{Key: tree.Name(k)}
@@ -400,18 +526,72 @@ func validateDetails(details jobspb.ChangefeedDetails) (jobspb.ChangefeedDetails
 	switch formatType(details.Opts[optFormat]) {
 	case ``, optFormatJSON:
 		details.Opts[optFormat] = string(optFormatJSON)
-	case optFormatAvro:
+	case optFormatAvro, optFormatAvroOCF, optFormatCSV, optFormatProtobuf:
 		// No-op.
 	default:
 		return jobspb.ChangefeedDetails{}, errors.Errorf(
 			`unknown %s: %s`, optFormat, details.Opts[optFormat])
 	}
+	if _, ok := details.Opts[optNullAs]; ok && formatType(details.Opts[optFormat]) != optFormatCSV {
+		return jobspb.ChangefeedDetails{}, errors.Errorf(
+			`%s is only usable with %s=%s`, optNullAs, optFormat, optFormatCSV)
+	}
+
+	var initialScanOpts []string
+	for _, opt := range []string{optInitialScan, optNoInitialScan, optInitialScanOnly} {
+		if _, ok := details.Opts[opt]; ok {
+			initialScanOpts = append(initialScanOpts, opt)
+		}
+	}
+	if len(initialScanOpts) > 1 {
+		return jobspb.ChangefeedDetails{}, errors.Errorf(
+			`cannot specify both %s and %s`, initialScanOpts[0], initialScanOpts[1])
+	}
+
+	switch schemaChangeEventClass(details.Opts[optSchemaChangeEvents]) {
+	case ``, optSchemaChangeEventClassDefault:
+		details.Opts[optSchemaChangeEvents] = string(optSchemaChangeEventClassDefault)
+	case optSchemaChangeEventClassColumnChange:
+		// No-op.
+	default:
+		return jobspb.ChangefeedDetails{}, errors.Errorf(
+			`unknown %s: %s`, optSchemaChangeEvents, details.Opts[optSchemaChangeEvents])
+	}
+
+	switch schemaChangePolicy(details.Opts[optSchemaChangePolicy]) {
+	case ``, optSchemaChangePolicyBackfill:
+		details.Opts[optSchemaChangePolicy] = string(optSchemaChangePolicyBackfill)
+	case optSchemaChangePolicyNoBackfill, optSchemaChangePolicyStop:
+		// No-op.
+	default:
+		return jobspb.ChangefeedDetails{}, errors.Errorf(
+			`unknown %s: %s`, optSchemaChangePolicy, details.Opts[optSchemaChangePolicy])
+	}
 
 	return details, nil
 }
 
+// initialScanFromOptions returns whether a changefeed with the given options
+// starts with a scan of its targets. By default, only changefeeds without a
+// cursor do.
+func initialScanFromOptions(opts map[string]string) bool {
+	_, initialScan := opts[optInitialScan]
+	_, initialScanOnly := opts[optInitialScanOnly]
+	if initialScan || initialScanOnly {
+		return true
+	}
+	if _, noInitialScan := opts[optNoInitialScan]; noInitialScan {
+		return false
+	}
+	_, cursor := opts[optCursor]
+	return !cursor
+}
+
+// validateChangefeedTable returns an error if the given version of a table
+// cannot be watched by a changefeed with the given targets. For a changefeed
+// on a database, databaseID is the id of the database.
 func validateChangefeedTable(
-	targets jobspb.ChangefeedTargets, tableDesc *sqlbase.TableDescriptor,
+	targets jobspb.ChangefeedTargets, databaseID sqlbase.ID, tableDesc *sqlbase.TableDescriptor,
 ) error {
 	t, ok := targets[tableDesc.ID]
 	if !ok {
@@ -441,9 +621,13 @@ func validateChangefeedTable(
 			tableDesc.Name, len(tableDesc.Families))
 	}
 
-	if tableDesc.State == sqlbase.TableDescriptor_DROP {
+	// A table dropped from a watched database stops being watched.
+	if tableDesc.State == sqlbase.TableDescriptor_DROP && databaseID == 0 {
 		return errors.Errorf(`"%s" was dropped or truncated`, t.StatementTimeName)
 	}
+	if databaseID != 0 && tableDesc.ParentID != databaseID {
+		return errors.Errorf(`"%s" was moved out of the watched database`, t.StatementTimeName)
+	}
 	if tableDesc.Name != t.StatementTimeName {
 		return errors.Errorf(`"%s" was renamed to "%s"`, t.StatementTimeName, tableDesc.Name)
 	}
@@ -456,8 +640,54 @@ func validateChangefeedTable(
 	return nil
 }
 
+// isDatabaseTable returns whether the given table is watched by a changefeed
+// on the database with the given id. Tables which are not public are included,
+// so that the watched tables don't depend on the timestamp at which they are
+// listed once they were created.
+func isDatabaseTable(databaseID sqlbase.ID, tableDesc *sqlbase.TableDescriptor) bool {
+	return tableDesc.ParentID == databaseID &&
+		!tableDesc.IsView() && !tableDesc.IsSequence() && !tableDesc.IsVirtualTable()
+}
+
+// fetchDatabaseTargets returns the targets of a changefeed on the database with
+// the given id, as of the given timestamp.
+func fetchDatabaseTargets(
+	ctx context.Context, db *client.DB, databaseID sqlbase.ID, ts hlc.Timestamp,
+) (jobspb.ChangefeedTargets, error) {
+	var targets jobspb.ChangefeedTargets
+	err := db.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		targets = make(jobspb.ChangefeedTargets)
+		txn.SetFixedTimestamp(ctx, ts)
+		descs, err := sql.GetAllDescriptors(ctx, txn)
+		if err != nil {
+			return err
+		}
+		foundDatabase := false
+		for _, desc := range descs {
+			switch desc := desc.(type) {
+			case *sqlbase.DatabaseDescriptor:
+				foundDatabase = foundDatabase || desc.ID == databaseID
+			case *sqlbase.TableDescriptor:
+				if !isDatabaseTable(databaseID, desc) {
+					continue
+				}
+				targets[desc.ID] = jobspb.ChangefeedTarget{StatementTimeName: desc.Name}
+				if err := validateChangefeedTable(targets, databaseID, desc); err != nil {
+					return err
+				}
+			}
+		}
+		if !foundDatabase {
+			return errors.Errorf(`database %d was dropped`, databaseID)
+		}
+		return nil
+	})
+	return targets, err
+}
+
 type changefeedResumer struct {
-	job *jobs.Job
+	job     *jobs.Job
+	execCfg *sql.ExecutorConfig
 }
 
 // generateChangefeedSessionID generates a unique string that is used to
@@ -494,6 +724,7 @@ func (b *changefeedResumer) Resume(
 ) error {
 	phs := planHookState.(sql.PlanHookState)
 	execCfg := phs.ExecCfg()
+	b.execCfg = execCfg
 	jobID := *b.job.ID()
 	details := b.job.Details().(jobspb.ChangefeedDetails)
 	progress := b.job.Progress()
@@ -502,12 +733,16 @@ func (b *changefeedResumer) Resume(
 	// progress high-water when creating a job (currently only the progress
 	// details can be set). I didn't want to pick off the refactor to get this
 	// fix in, but it'd be nice to remove this hack.
-	if _, ok := details.Opts[optCursor]; ok {
+	if !initialScanFromOptions(details.Opts) {
 		if h := progress.GetHighWater(); h == nil || *h == (hlc.Timestamp{}) {
 			progress.Progress = &jobspb.Progress_HighWater{HighWater: &details.StatementTime}
 		}
 	}
 
+	if err := b.maybeProtectTimestamp(ctx, details, &progress); err != nil {
+		return err
+	}
+
 	// We'd like to avoid failing a changefeed unnecessarily, so when an error
 	// bubbles up to this level, we'd like to "retry" the flow if possible. This
 	// could be because the sink is down or because a cockroach node has crashed
@@ -519,7 +754,35 @@ func (b *changefeedResumer) Resume(
 	}
 	var err error
 	for r := retry.StartWithCtx(ctx, opts); r.Next(); {
-		if err = distChangefeedFlow(ctx, phs, jobID, details, progress, startedCh); err == nil {
+		if err != nil {
+			// Re-load the job in order to update our progress object, which may
+			// have been updated by the changeFrontier processor of the failed
+			// attempt.
+			reloadedJob, reloadErr := execCfg.JobRegistry.LoadJob(ctx, jobID)
+			if reloadErr != nil {
+				log.Warningf(ctx, `CHANGEFEED job %d could not reload job progress; `+
+					`continuing from last known high-water of %s: %v`,
+					jobID, progress.GetHighWater(), reloadErr)
+			} else {
+				progress = reloadedJob.Progress()
+			}
+		}
+		if _, ok := details.Opts[optInitialScanOnly]; ok {
+			if h := progress.GetHighWater(); h != nil && *h != (hlc.Timestamp{}) {
+				// The initial scan was emitted and resolved before the job was
+				// last stopped, so there is nothing left to do.
+				return nil
+			}
+		}
+		if details.DatabaseID != 0 {
+			// Tables may have been created in or dropped from the database while
+			// the changefeed wasn't running.
+			details, err = b.refreshDatabaseTargets(ctx, details)
+		}
+		if err == nil {
+			err = distChangefeedFlow(ctx, phs, jobID, details, progress, startedCh)
+		}
+		if err == nil {
 			return nil
 		}
 		if !IsRetryableError(err) {
@@ -531,17 +794,6 @@ func (b *changefeedResumer) Resume(
 		if metrics, ok := execCfg.JobRegistry.MetricsStruct().Changefeed.(*Metrics); ok {
 			metrics.ErrorRetries.Inc(1)
 		}
-		// Re-load the job in order to update our progress object, which may have
-		// been updated by the changeFrontier processor since the flow started.
-		reloadedJob, reloadErr := execCfg.JobRegistry.LoadJob(ctx, jobID)
-		if reloadErr != nil {
-			log.Warningf(ctx, `CHANGEFEED job %d could not reload job progress; `+
-				`continuing from last known high-water of %s: %v`,
-				jobID, progress.GetHighWater(), reloadErr)
-		} else {
-			progress = reloadedJob.Progress()
-		}
-
 		// startedCh is normally used to signal back to the creator of the job that
 		// the job has started; however, in this case nothing will ever receive
 		// on the channel, causing the changefeed flow to block. Replace it with
@@ -553,11 +805,92 @@ func (b *changefeedResumer) Resume(
 	return errors.Wrap(err, `ran out of retries`)
 }
 
+// refreshDatabaseTargets updates the targets of a changefeed on a database to
+// the current tables of the database. The targets are persisted in the job, so
+// that they are known to the processors and sinks of the replanned changefeed.
+// Tables created and dropped while the changefeed runs are added and removed by
+// its poller instead.
+func (b *changefeedResumer) refreshDatabaseTargets(
+	ctx context.Context, details jobspb.ChangefeedDetails,
+) (jobspb.ChangefeedDetails, error) {
+	targets, err := fetchDatabaseTargets(
+		ctx, b.execCfg.DB, details.DatabaseID, b.execCfg.Clock.Now())
+	if err != nil {
+		return details, err
+	}
+	if reflect.DeepEqual(targets, details.Targets) {
+		return details, nil
+	}
+	log.Infof(ctx, `CHANGEFEED job %d now watching %d tables`, *b.job.ID(), len(targets))
+	details.Targets = targets
+	if err := b.job.SetDetails(ctx, details); err != nil {
+		return details, err
+	}
+	return details, nil
+}
+
+// maybeProtectTimestamp creates the protected timestamp record of the
+// changefeed when the job first runs. The record is forwarded by the
+// changeFrontier as the high-water advances.
+func (b *changefeedResumer) maybeProtectTimestamp(
+	ctx context.Context, details jobspb.ChangefeedDetails, progress *jobspb.Progress,
+) error {
+	pts := b.execCfg.ProtectedTimestampProvider
+	cfProgress := progress.GetChangefeed()
+	if pts == nil || cfProgress == nil || cfProgress.ProtectedTimestampRecord != uuid.Nil ||
+		!cluster.Version.IsActive(ctx, b.execCfg.Settings, cluster.VersionProtectedTimestamps) {
+		return nil
+	}
+	ts := details.StatementTime
+	if h := progress.GetHighWater(); h != nil {
+		ts.Forward(*h)
+	}
+	var newProgress jobspb.ChangefeedProgress
+	if err := b.execCfg.DB.Txn(ctx, func(ctx context.Context, txn *client.Txn) (err error) {
+		newProgress, err = protectTimestamp(ctx, txn, pts, b.job, details.Targets, ts, *cfProgress)
+		return err
+	}); err != nil {
+		return errors.Wrap(err, "failed to protect the timestamp of the changefeed")
+	}
+	*cfProgress = newProgress
+	return nil
+}
+
+// releaseProtectedTimestamp releases the protected timestamp record of the
+// changefeed, if any, in the transaction which moves the job to a terminal
+// state. Jobs canceled on a node which did not run them leave their record to
+// the protected timestamp reconciliation loop.
+func (b *changefeedResumer) releaseProtectedTimestamp(ctx context.Context, txn *client.Txn) error {
+	if b.execCfg == nil || b.execCfg.ProtectedTimestampProvider == nil {
+		return nil
+	}
+	// The record is forwarded by the changeFrontier, so the progress of
+	// b.job may be stale.
+	job, err := b.execCfg.JobRegistry.LoadJobWithTxn(ctx, *b.job.ID(), txn)
+	if err != nil {
+		return err
+	}
+	progress := job.Progress()
+	cfProgress := progress.GetChangefeed()
+	if cfProgress == nil || cfProgress.ProtectedTimestampRecord == uuid.Nil {
+		return nil
+	}
+	err = b.execCfg.ProtectedTimestampProvider.Release(ctx, txn, cfProgress.ProtectedTimestampRecord)
+	if err == protectedts.ErrNotExists {
+		return nil
+	}
+	return err
+}
+
 // OnFailOrCancel is part of the jobs.Resumer interface.
-func (b *changefeedResumer) OnFailOrCancel(context.Context, *client.Txn) error { return nil }
+func (b *changefeedResumer) OnFailOrCancel(ctx context.Context, txn *client.Txn) error {
+	return b.releaseProtectedTimestamp(ctx, txn)
+}
 
 // OnSuccess is part of the jobs.Resumer interface.
-func (b *changefeedResumer) OnSuccess(context.Context, *client.Txn) error { return nil }
+func (b *changefeedResumer) OnSuccess(ctx context.Context, txn *client.Txn) error {
+	return b.releaseProtectedTimestamp(ctx, txn)
+}
 
 // OnTerminal is part of the jobs.Resumer interface.
 func (b *changefeedResumer) OnTerminal(context.Context, jobs.Status, chan<- tree.Datums) {}
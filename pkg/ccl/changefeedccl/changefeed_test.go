@@ -204,6 +204,117 @@ func TestChangefeedMultiTable(t *testing.T) {
 	t.Run(`enterprise`, enterpriseTest(testFn))
 }
 
+func TestChangefeedDatabase(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	testFn := func(t *testing.T, db *gosql.DB, f cdctest.TestFeedFactory) {
+		registry := f.Server().JobRegistry().(*jobs.Registry)
+		retryCounter := registry.MetricsStruct().Changefeed.(*Metrics).ErrorRetries
+		sqlDB := sqlutils.MakeSQLRunner(db)
+		sqlDB.Exec(t, `CREATE TABLE foo (a INT PRIMARY KEY)`)
+		sqlDB.Exec(t, `CREATE VIEW vw AS SELECT a FROM foo`)
+		sqlDB.Exec(t, `INSERT INTO foo VALUES (1)`)
+
+		d := feed(t, f, `CREATE CHANGEFEED FOR DATABASE d`)
+		defer closeFeed(t, d)
+		assertPayloads(t, d, []string{
+			`foo: [1]->{"after": {"a": 1}}`,
+		})
+
+		// A table created in the database is watched from its creation.
+		sqlDB.Exec(t, `CREATE TABLE bar (b INT PRIMARY KEY)`)
+		sqlDB.Exec(t, `INSERT INTO bar VALUES (2)`)
+		sqlDB.Exec(t, `INSERT INTO foo VALUES (3)`)
+		assertPayloads(t, d, []string{
+			`bar: [2]->{"after": {"b": 2}}`,
+			`foo: [3]->{"after": {"a": 3}}`,
+		})
+
+		// A table dropped from the database doesn't stop the changefeed.
+		sqlDB.Exec(t, `DROP TABLE bar`)
+		sqlDB.Exec(t, `INSERT INTO foo VALUES (4)`)
+		assertPayloads(t, d, []string{
+			`foo: [4]->{"after": {"a": 4}}`,
+		})
+
+		// Tables of other databases are not watched.
+		sqlDB.Exec(t, `CREATE DATABASE other`)
+		sqlDB.Exec(t, `CREATE TABLE other.baz (c INT PRIMARY KEY)`)
+		sqlDB.Exec(t, `INSERT INTO other.baz VALUES (5)`)
+		sqlDB.Exec(t, `INSERT INTO foo VALUES (6)`)
+		assertPayloads(t, d, []string{
+			`foo: [6]->{"after": {"a": 6}}`,
+		})
+
+		// The changefeed watches the tables as they're created and dropped,
+		// without being restarted.
+		if retries := retryCounter.Count(); retries != 0 {
+			t.Fatalf(`expected no retries, got %d`, retries)
+		}
+
+		// A changefeed on an empty database watches the tables created in it.
+		sqlDB.Exec(t, `CREATE DATABASE empty`)
+		e := feed(t, f, `CREATE CHANGEFEED FOR DATABASE empty`)
+		defer closeFeed(t, e)
+		sqlDB.Exec(t, `CREATE TABLE empty.qux (d INT PRIMARY KEY)`)
+		sqlDB.Exec(t, `INSERT INTO empty.qux VALUES (7)`)
+		assertPayloads(t, e, []string{
+			`qux: [7]->{"after": {"d": 7}}`,
+		})
+	}
+
+	// Changefeeds on a database are only supported by the enterprise version.
+	t.Run(`enterprise`, enterpriseTest(testFn))
+}
+
+func TestChangefeedSelect(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	testFn := func(t *testing.T, db *gosql.DB, f cdctest.TestFeedFactory) {
+		sqlDB := sqlutils.MakeSQLRunner(db)
+		sqlDB.Exec(t, `CREATE TABLE foo (a INT PRIMARY KEY, b STRING, c INT)`)
+		sqlDB.Exec(t, `INSERT INTO foo VALUES (1, 'one', 10), (2, 'two', 20)`)
+
+		s := feed(t, f, `CREATE CHANGEFEED AS SELECT a, upper(b) AS b, c + 1 FROM foo WHERE c > 10
+			WITH diff`)
+		defer closeFeed(t, s)
+		assertPayloads(t, s, []string{
+			`foo: [2]->{"after": {"?column?": 21, "a": 2, "b": "TWO"}, "before": null}`,
+		})
+
+		// The key is always the primary key of the table. Deletes of rows which
+		// didn't match are filtered out.
+		sqlDB.Exec(t, `INSERT INTO foo VALUES (3, 'three', 5)`)
+		sqlDB.Exec(t, `UPDATE foo SET c = 30 WHERE a = 3`)
+		sqlDB.Exec(t, `DELETE FROM foo WHERE a = 1`)
+		sqlDB.Exec(t, `DELETE FROM foo WHERE a = 3`)
+		assertPayloads(t, s, []string{
+			`foo: [3]->{"after": {"?column?": 31, "a": 3, "b": "THREE"}, "before": null}`,
+			`foo: [3]->{"after": null, "before": {"?column?": 31, "a": 3, "b": "THREE"}}`,
+		})
+
+		// A row which stops matching is emitted as a deletion, and one which
+		// starts matching again has no previous value.
+		sqlDB.Exec(t, `UPDATE foo SET c = 0 WHERE a = 2`)
+		sqlDB.Exec(t, `UPDATE foo SET b = 'deux' WHERE a = 2`)
+		sqlDB.Exec(t, `UPDATE foo SET c = 22 WHERE a = 2`)
+		assertPayloads(t, s, []string{
+			`foo: [2]->{"after": null, "before": {"?column?": 21, "a": 2, "b": "TWO"}}`,
+			`foo: [2]->{"after": {"?column?": 23, "a": 2, "b": "DEUX"}, "before": null}`,
+		})
+
+		// Columns which are not selected can be added.
+		sqlDB.Exec(t, `ALTER TABLE foo ADD COLUMN d INT`)
+		sqlDB.Exec(t, `INSERT INTO foo VALUES (4, 'four', 40, 400)`)
+		assertPayloads(t, s, []string{
+			`foo: [4]->{"after": {"?column?": 41, "a": 4, "b": "FOUR"}, "before": null}`,
+		})
+	}
+
+	t.Run(`sinkless`, sinklessTest(testFn))
+	t.Run(`enterprise`, enterpriseTest(testFn))
+}
+
 func TestChangefeedCursor(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 
@@ -262,6 +373,106 @@ func TestChangefeedCursor(t *testing.T) {
 	t.Run(`enterprise`, enterpriseTest(testFn))
 }
 
+func TestChangefeedInitialScan(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	testFn := func(t *testing.T, db *gosql.DB, f cdctest.TestFeedFactory) {
+		sqlDB := sqlutils.MakeSQLRunner(db)
+		sqlDB.Exec(t, `CREATE TABLE foo (a INT PRIMARY KEY)`)
+		sqlDB.Exec(t, `INSERT INTO foo VALUES (1)`)
+		var cursor string
+		sqlDB.QueryRow(t, `SELECT cluster_logical_timestamp()`).Scan(&cursor)
+		sqlDB.Exec(t, `INSERT INTO foo VALUES (2)`)
+
+		noInitialScan := feed(t, f, `CREATE CHANGEFEED FOR foo WITH no_initial_scan`)
+		defer closeFeed(t, noInitialScan)
+		sqlDB.Exec(t, `INSERT INTO foo VALUES (3)`)
+		assertPayloads(t, noInitialScan, []string{
+			`foo: [3]->{"after": {"a": 3}}`,
+		})
+
+		initialScan := feed(t, f, `CREATE CHANGEFEED FOR foo WITH initial_scan, cursor=$1`, cursor)
+		defer closeFeed(t, initialScan)
+		assertPayloads(t, initialScan, []string{
+			`foo: [1]->{"after": {"a": 1}}`,
+			`foo: [2]->{"after": {"a": 2}}`,
+			`foo: [3]->{"after": {"a": 3}}`,
+		})
+	}
+
+	t.Run(`sinkless`, sinklessTest(testFn))
+	t.Run(`enterprise`, enterpriseTest(testFn))
+}
+
+func TestChangefeedInitialScanOnly(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	testFn := func(t *testing.T, db *gosql.DB, f cdctest.TestFeedFactory) {
+		sqlDB := sqlutils.MakeSQLRunner(db)
+		sqlDB.Exec(t, `CREATE TABLE foo (a INT PRIMARY KEY)`)
+		sqlDB.Exec(t, `INSERT INTO foo VALUES (1)`)
+		var cursor string
+		sqlDB.QueryRow(t, `SELECT cluster_logical_timestamp()`).Scan(&cursor)
+		sqlDB.Exec(t, `INSERT INTO foo VALUES (2)`)
+
+		scanOnly := feed(t, f, `CREATE CHANGEFEED FOR foo WITH initial_scan_only, cursor=$1`, cursor)
+		defer closeFeed(t, scanOnly)
+		assertPayloads(t, scanOnly, []string{
+			`foo: [1]->{"after": {"a": 1}}`,
+		})
+
+		if strings.Contains(t.Name(), `sinkless`) {
+			// Sinkless changefeeds don't have a job to complete, their query
+			// returns once the scan is emitted instead.
+			assertEnded := func(f cdctest.TestFeed) {
+				t.Helper()
+				for {
+					m, err := f.Next()
+					if err != nil {
+						t.Fatal(err)
+					}
+					if m == nil {
+						return
+					}
+					if len(m.Key) > 0 {
+						t.Fatalf(`unexpected row after the initial scan: %s`, m)
+					}
+				}
+			}
+			assertEnded(scanOnly)
+
+			sqlDB.Exec(t, `CREATE DATABASE empty`)
+			empty := feed(t, f, `CREATE CHANGEFEED FOR DATABASE empty WITH initial_scan_only`)
+			defer closeFeed(t, empty)
+			assertEnded(empty)
+			return
+		}
+
+		// The job completes once the scan is emitted, with its high-water at
+		// the scan timestamp.
+		e := scanOnly.(*cdctest.TableFeed)
+		sqlDB.CheckQueryResultsRetry(t,
+			fmt.Sprintf(`SELECT status, high_water_timestamp = %s FROM crdb_internal.jobs WHERE job_id = %d`,
+				cursor, e.JobID),
+			[][]string{{`succeeded`, `true`}},
+		)
+
+		// A changefeed on a database without tables has nothing to scan, and
+		// completes too.
+		sqlDB.Exec(t, `CREATE DATABASE empty`)
+		empty := feed(t, f, `CREATE CHANGEFEED FOR DATABASE empty WITH initial_scan_only`)
+		defer closeFeed(t, empty)
+		sqlDB.CheckQueryResultsRetry(t,
+			fmt.Sprintf(`SELECT status FROM crdb_internal.jobs WHERE job_id = %d`,
+				empty.(*cdctest.TableFeed).JobID),
+			[][]string{{`succeeded`}},
+		)
+	}
+
+	t.Run(`sinkless`, sinklessTest(testFn))
+	t.Run(`enterprise`, enterpriseTest(testFn))
+}
+
 func TestChangefeedTimestamps(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 
@@ -794,6 +1005,73 @@ func TestChangefeedSchemaChangeAllowBackfill(t *testing.T) {
 	}
 }
 
+func TestChangefeedSchemaChangePolicy(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	testFn := func(t *testing.T, db *gosql.DB, f cdctest.TestFeedFactory) {
+		sqlDB := sqlutils.MakeSQLRunner(db)
+
+		t.Run(`nobackfill`, func(t *testing.T) {
+			sqlDB.Exec(t, `CREATE TABLE nobackfill (a INT PRIMARY KEY)`)
+			sqlDB.Exec(t, `INSERT INTO nobackfill VALUES (1)`)
+			nobackfill := feed(t, f, `CREATE CHANGEFEED FOR nobackfill `+
+				`WITH schema_change_policy='nobackfill'`)
+			defer closeFeed(t, nobackfill)
+			assertPayloads(t, nobackfill, []string{
+				`nobackfill: [1]->{"after": {"a": 1}}`,
+			})
+			sqlDB.Exec(t, `ALTER TABLE nobackfill ADD COLUMN b STRING DEFAULT 'd'`)
+			// Schema change backfill, but no changefeed level backfill.
+			assertPayloads(t, nobackfill, []string{
+				`nobackfill: [1]->{"after": {"a": 1}}`,
+			})
+			sqlDB.Exec(t, `INSERT INTO nobackfill VALUES (2)`)
+			assertPayloads(t, nobackfill, []string{
+				`nobackfill: [2]->{"after": {"a": 2, "b": "d"}}`,
+			})
+		})
+
+		t.Run(`column_changes`, func(t *testing.T) {
+			sqlDB.Exec(t, `CREATE TABLE column_changes (a INT PRIMARY KEY)`)
+			sqlDB.Exec(t, `INSERT INTO column_changes VALUES (1)`)
+			columnChanges := feed(t, f, `CREATE CHANGEFEED FOR column_changes `+
+				`WITH schema_change_events='column_changes'`)
+			defer closeFeed(t, columnChanges)
+			assertPayloads(t, columnChanges, []string{
+				`column_changes: [1]->{"after": {"a": 1}}`,
+			})
+			// Adding a nullable column doesn't need a backfill, but it is still
+			// an event.
+			sqlDB.Exec(t, `ALTER TABLE column_changes ADD COLUMN b STRING`)
+			assertPayloads(t, columnChanges, []string{
+				`column_changes: [1]->{"after": {"a": 1, "b": null}}`,
+			})
+		})
+
+		t.Run(`stop`, func(t *testing.T) {
+			sqlDB.Exec(t, `CREATE TABLE stop (a INT PRIMARY KEY)`)
+			sqlDB.Exec(t, `INSERT INTO stop VALUES (1)`)
+			stop := feed(t, f, `CREATE CHANGEFEED FOR stop WITH schema_change_policy='stop'`)
+			defer closeFeed(t, stop)
+			assertPayloads(t, stop, []string{
+				`stop: [1]->{"after": {"a": 1}}`,
+			})
+			sqlDB.Exec(t, `ALTER TABLE stop ADD COLUMN b STRING DEFAULT 'd'`)
+			// Schema change backfill, which happens before the schema change is
+			// complete.
+			assertPayloads(t, stop, []string{
+				`stop: [1]->{"after": {"a": 1}}`,
+			})
+			if _, err := stop.Next(); !testutils.IsError(err, `schema change occurred at`) {
+				t.Errorf(`expected "schema change occurred at" error got: %+v`, err)
+			}
+		})
+	}
+
+	t.Run(`sinkless`, sinklessTest(testFn))
+	t.Run(`enterprise`, enterpriseTest(testFn))
+}
+
 // fetchDescVersionModificationTime fetches the `ModificationTime` of the specified
 // `version` of `tableName`'s table descriptor.
 func fetchDescVersionModificationTime(
@@ -1536,6 +1814,46 @@ func TestChangefeedErrors(t *testing.T) {
 		t, `CHANGEFEED cannot target views: vw`,
 		`EXPERIMENTAL CHANGEFEED FOR vw`,
 	)
+	sqlDB.ExpectErr(
+		t, `CHANGEFEED FOR DATABASE requires a sink`,
+		`EXPERIMENTAL CHANGEFEED FOR DATABASE d`,
+	)
+	sqlDB.ExpectErr(
+		t, `CHANGEFEED cannot target DATABASE d, defaultdb`,
+		`EXPERIMENTAL CHANGEFEED FOR DATABASE d, defaultdb`,
+	)
+	sqlDB.ExpectErr(
+		t, `cannot specify both initial_scan and no_initial_scan`,
+		`EXPERIMENTAL CHANGEFEED FOR foo WITH initial_scan, no_initial_scan`,
+	)
+	sqlDB.ExpectErr(
+		t, `unknown schema_change_policy: foo`,
+		`EXPERIMENTAL CHANGEFEED FOR foo WITH schema_change_policy='foo'`,
+	)
+	sqlDB.ExpectErr(
+		t, `unknown schema_change_events: foo`,
+		`EXPERIMENTAL CHANGEFEED FOR foo WITH schema_change_events='foo'`,
+	)
+	sqlDB.ExpectErr(
+		t, `CHANGEFEED ... AS SELECT does not support GROUP BY`,
+		`EXPERIMENTAL CHANGEFEED AS SELECT a FROM foo GROUP BY a`,
+	)
+	sqlDB.ExpectErr(
+		t, `CHANGEFEED ... AS SELECT requires exactly one table in FROM`,
+		`EXPERIMENTAL CHANGEFEED AS SELECT foo.a FROM foo, vw`,
+	)
+	sqlDB.ExpectErr(
+		t, `impure functions are not allowed in CHANGEFEED`,
+		`EXPERIMENTAL CHANGEFEED AS SELECT a, now() FROM foo`,
+	)
+	sqlDB.ExpectErr(
+		t, `column "c" does not exist`,
+		`EXPERIMENTAL CHANGEFEED AS SELECT c FROM foo`,
+	)
+	sqlDB.ExpectErr(
+		t, `CHANGEFEED ... AS SELECT with a WHERE clause requires the diff option`,
+		`EXPERIMENTAL CHANGEFEED AS SELECT a FROM foo WHERE a > 1`,
+	)
 	// Backup has the same bad error message #28170.
 	sqlDB.ExpectErr(
 		t, `"information_schema.tables" does not exist`,
@@ -1806,6 +2124,67 @@ func TestChangefeedPauseUnpause(t *testing.T) {
 	t.Run(`enterprise`, enterpriseTest(testFn))
 }
 
+func TestChangefeedProtectedTimestamps(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	defer func(i time.Duration) { jobs.DefaultAdoptInterval = i }(jobs.DefaultAdoptInterval)
+	jobs.DefaultAdoptInterval = 10 * time.Millisecond
+
+	testFn := func(t *testing.T, db *gosql.DB, f cdctest.TestFeedFactory) {
+		sqlDB := sqlutils.MakeSQLRunner(db)
+		sqlDB.Exec(t, `SET CLUSTER SETTING changefeed.protect_timestamp_interval = '10ms'`)
+		sqlDB.Exec(t, `CREATE TABLE foo (a INT PRIMARY KEY)`)
+		sqlDB.Exec(t, `INSERT INTO foo VALUES (1)`)
+
+		foo := feed(t, f, `CREATE CHANGEFEED FOR foo WITH resolved = '10ms'`).(*cdctest.TableFeed)
+		defer closeFeed(t, foo)
+		assertPayloads(t, foo, []string{`foo: [1]->{"after": {"a": 1}}`})
+
+		// The records of the changefeed, as (id, ts) pairs.
+		meta := []byte(strconv.FormatInt(foo.JobID, 10))
+		records := func() [][]string {
+			return sqlDB.QueryStr(t, `SELECT id::STRING, ts::STRING FROM system.protected_ts_records
+				WHERE meta_type = 'jobs' AND meta = $1`, meta)
+		}
+
+		// The changefeed creates a record when it starts.
+		recs := records()
+		require.Len(t, recs, 1)
+
+		// The record is replaced by one at a later timestamp as the high-water
+		// advances, so only one record is ever left.
+		testutils.SucceedsSoon(t, func() error {
+			cur := records()
+			if len(cur) != 1 {
+				return errors.Errorf(`expected one record, got %v`, cur)
+			}
+			if cur[0][0] == recs[0][0] {
+				return errors.New(`record not forwarded yet`)
+			}
+			prevTS, err := strconv.ParseFloat(recs[0][1], 64)
+			require.NoError(t, err)
+			curTS, err := strconv.ParseFloat(cur[0][1], 64)
+			require.NoError(t, err)
+			if curTS <= prevTS {
+				return errors.Errorf(`expected record at %s to be above %s`, cur[0][1], recs[0][1])
+			}
+			return nil
+		})
+
+		// The record is released when the job is canceled.
+		sqlDB.Exec(t, `CANCEL JOB $1`, foo.JobID)
+		testutils.SucceedsSoon(t, func() error {
+			if cur := records(); len(cur) != 0 {
+				return errors.Errorf(`expected the record to be released, got %v`, cur)
+			}
+			return nil
+		})
+	}
+
+	// Only the enterprise version uses jobs.
+	t.Run(`enterprise`, enterpriseTest(testFn))
+}
+
 func TestManyChangefeedsOneTable(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 
@@ -55,6 +55,21 @@ type encodeRow struct {
 	// prevTableDesc is a TableDescriptor for the table containing `prevDatums`.
 	// It's valid for interpreting the row at `updated.Prev()`.
 	prevTableDesc *sqlbase.TableDescriptor
+	// keyDatums and keyTableDesc, if set, are the table row and descriptor
+	// which the primary key is encoded from. They are set when `datums` and
+	// `tableDesc` are the projection of a changefeed created with CREATE
+	// CHANGEFEED ... AS SELECT, which may not include the primary key columns.
+	keyDatums    sqlbase.EncDatumRow
+	keyTableDesc *sqlbase.TableDescriptor
+}
+
+// keyRow returns the row and table descriptor which the primary key of the
+// row is encoded from.
+func (r encodeRow) keyRow() (sqlbase.EncDatumRow, *sqlbase.TableDescriptor) {
+	if r.keyTableDesc != nil {
+		return r.keyDatums, r.keyTableDesc
+	}
+	return r.datums, r.tableDesc
 }
 
 // Encoder turns a row into a serialized changefeed key, value, or resolved
@@ -82,6 +97,12 @@ func getEncoder(opts map[string]string) (Encoder, error) {
 		return makeJSONEncoder(opts)
 	case optFormatAvro:
 		return newConfluentAvroEncoder(opts)
+	case optFormatAvroOCF:
+		return newAvroOCFEncoder(opts)
+	case optFormatCSV:
+		return newCSVEncoder(opts)
+	case optFormatProtobuf:
+		return newProtobufEncoder(opts)
 	default:
 		return nil, errors.Errorf(`unknown %s: %s`, optFormat, opts[optFormat])
 	}
@@ -135,14 +156,15 @@ func (e *jsonEncoder) EncodeKey(_ context.Context, row encodeRow) ([]byte, error
 }
 
 func (e *jsonEncoder) encodeKeyRaw(row encodeRow) ([]interface{}, error) {
-	colIdxByID := row.tableDesc.ColumnIdxMap()
-	jsonEntries := make([]interface{}, len(row.tableDesc.PrimaryIndex.ColumnIDs))
-	for i, colID := range row.tableDesc.PrimaryIndex.ColumnIDs {
+	datums, tableDesc := row.keyRow()
+	colIdxByID := tableDesc.ColumnIdxMap()
+	jsonEntries := make([]interface{}, len(tableDesc.PrimaryIndex.ColumnIDs))
+	for i, colID := range tableDesc.PrimaryIndex.ColumnIDs {
 		idx, ok := colIdxByID[colID]
 		if !ok {
 			return nil, errors.Errorf(`unknown column id: %d`, colID)
 		}
-		datum, col := row.datums[idx], &row.tableDesc.Columns[idx]
+		datum, col := datums[idx], &tableDesc.Columns[idx]
 		if err := datum.EnsureDecoded(&col.Type, &e.alloc); err != nil {
 			return nil, err
 		}
@@ -331,18 +353,19 @@ func newConfluentAvroEncoder(opts map[string]string) (*confluentAvroEncoder, err
 
 // EncodeKey implements the Encoder interface.
 func (e *confluentAvroEncoder) EncodeKey(ctx context.Context, row encodeRow) ([]byte, error) {
-	cacheKey := makeTableIDAndVersion(row.tableDesc.ID, row.tableDesc.Version)
+	datums, tableDesc := row.keyRow()
+	cacheKey := makeTableIDAndVersion(tableDesc.ID, tableDesc.Version)
 	registered, ok := e.keyCache[cacheKey]
 	if !ok {
 		var err error
-		registered.schema, err = indexToAvroSchema(row.tableDesc, &row.tableDesc.PrimaryIndex)
+		registered.schema, err = indexToAvroSchema(tableDesc, &tableDesc.PrimaryIndex, avroSchemaNoSuffix)
 		if err != nil {
 			return nil, err
 		}
 
 		// NB: This uses the kafka name escaper because it has to match the name
 		// of the kafka topic.
-		subject := SQLNameToKafkaName(row.tableDesc.Name) + confluentSubjectSuffixKey
+		subject := SQLNameToKafkaName(tableDesc.Name) + confluentSubjectSuffixKey
 		registered.registryID, err = e.register(ctx, &registered.schema.avroRecord, subject)
 		if err != nil {
 			return nil, err
@@ -357,7 +380,7 @@ func (e *confluentAvroEncoder) EncodeKey(ctx context.Context, row encodeRow) ([]
 		0, 0, 0, 0, // Placeholder for the ID.
 	}
 	binary.BigEndian.PutUint32(header[1:5], uint32(registered.registryID))
-	return registered.schema.BinaryFromRow(header, row.datums)
+	return registered.schema.BinaryFromRow(header, datums)
 }
 
 // EncodeValue implements the Encoder interface.
@@ -388,7 +411,8 @@ func (e *confluentAvroEncoder) EncodeValue(ctx context.Context, row encodeRow) (
 		}
 
 		opts := avroEnvelopeOpts{afterField: true, beforeField: e.beforeField, updatedField: e.updatedField}
-		registered.schema, err = envelopeToAvroSchema(row.tableDesc.Name, opts, beforeDataSchema, afterDataSchema)
+		registered.schema, err = envelopeToAvroSchema(
+			row.tableDesc.Name, opts, nil /* key */, beforeDataSchema, afterDataSchema)
 		if err != nil {
 			return nil, err
 		}
@@ -422,7 +446,7 @@ func (e *confluentAvroEncoder) EncodeValue(ctx context.Context, row encodeRow) (
 		0, 0, 0, 0, // Placeholder for the ID.
 	}
 	binary.BigEndian.PutUint32(header[1:5], uint32(registered.registryID))
-	return registered.schema.BinaryFromRow(header, meta, beforeDatums, afterDatums)
+	return registered.schema.BinaryFromRow(header, meta, nil /* keyRow */, beforeDatums, afterDatums)
 }
 
 // EncodeResolvedTimestamp implements the Encoder interface.
@@ -433,7 +457,8 @@ func (e *confluentAvroEncoder) EncodeResolvedTimestamp(
 	if !ok {
 		opts := avroEnvelopeOpts{resolvedField: true}
 		var err error
-		registered.schema, err = envelopeToAvroSchema(topic, opts, nil /* before */, nil /* after */)
+		registered.schema, err = envelopeToAvroSchema(
+			topic, opts, nil /* key */, nil /* before */, nil /* after */)
 		if err != nil {
 			return nil, err
 		}
@@ -460,7 +485,8 @@ func (e *confluentAvroEncoder) EncodeResolvedTimestamp(
 		0, 0, 0, 0, // Placeholder for the ID.
 	}
 	binary.BigEndian.PutUint32(header[1:5], uint32(registered.registryID))
-	return registered.schema.BinaryFromRow(header, meta, nil /* beforeRow */, nil /* afterRow */)
+	return registered.schema.BinaryFromRow(
+		header, meta, nil /* keyRow */, nil /* beforeRow */, nil /* afterRow */)
 }
 
 func (e *confluentAvroEncoder) register(
@@ -0,0 +1,391 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package changefeedccl
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
+	"github.com/cockroachdb/errors"
+)
+
+// fileEncoder is implemented by the Encoders of file formats in which a file
+// starts with a header, or frames the values in it. The cloud storage sink uses
+// it to write the files of these formats.
+type fileEncoder interface {
+	// FileHeader returns the header of a file of values of the given version of
+	// a table. It's only called once a value of that version was encoded.
+	FileHeader(tableDesc *sqlbase.TableDescriptor) ([]byte, error)
+	// WriteFileBlock writes the given concatenated values to a file, after its
+	// header.
+	WriteFileBlock(w io.Writer, numValues int, values []byte) error
+}
+
+// avroOCFMagic starts every Avro object container file.
+var avroOCFMagic = []byte{'O', 'b', 'j', 1}
+
+// avroOCFEncoder encodes changefeed entries in Avro's binary format, to be
+// written to Avro object container files, which embed the schema of their
+// values in their header. Unlike confluentAvroEncoder, it doesn't need a schema
+// registry. Values are all columns in a record, along with the primary key if
+// key_in_value is set. Resolved timestamps are encoded as JSON, since they are
+// written to their own files.
+type avroOCFEncoder struct {
+	updatedField, keyInValue bool
+
+	// sync is the marker which ends the header and every block of the files
+	// written with this encoder.
+	sync [16]byte
+
+	keyCache   map[tableIDAndVersion]*avroDataRecord
+	valueCache map[tableIDAndVersion]*avroEnvelopeRecord
+	resolved   *jsonEncoder
+}
+
+var _ Encoder = &avroOCFEncoder{}
+var _ fileEncoder = &avroOCFEncoder{}
+
+func newAvroOCFEncoder(opts map[string]string) (*avroOCFEncoder, error) {
+	e := &avroOCFEncoder{
+		sync:       uuid.MakeV4(),
+		keyCache:   make(map[tableIDAndVersion]*avroDataRecord),
+		valueCache: make(map[tableIDAndVersion]*avroEnvelopeRecord),
+	}
+	if envelopeType(opts[optEnvelope]) != optEnvelopeWrapped {
+		return nil, errors.Errorf(`%s=%s is not supported with %s=%s`,
+			optEnvelope, opts[optEnvelope], optFormat, optFormatAvroOCF)
+	}
+	// The schema of a file would depend on the version of the table the
+	// previous value of each row was written with.
+	if _, ok := opts[optDiff]; ok {
+		return nil, errors.Errorf(`%s is not supported with %s=%s`,
+			optDiff, optFormat, optFormatAvroOCF)
+	}
+	_, e.updatedField = opts[optUpdatedTimestamps]
+	_, e.keyInValue = opts[optKeyInValue]
+
+	var err error
+	if e.resolved, err = makeJSONEncoder(map[string]string{
+		optEnvelope: string(optEnvelopeWrapped),
+	}); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func (e *avroOCFEncoder) keySchema(
+	tableDesc *sqlbase.TableDescriptor, nameSuffix string,
+) (*avroDataRecord, error) {
+	cacheKey := makeTableIDAndVersion(tableDesc.ID, tableDesc.Version)
+	if schema, ok := e.keyCache[cacheKey]; ok {
+		return schema, nil
+	}
+	schema, err := indexToAvroSchema(tableDesc, &tableDesc.PrimaryIndex, nameSuffix)
+	if err != nil {
+		return nil, err
+	}
+	// TODO(dan): Bound the size of this cache.
+	e.keyCache[cacheKey] = schema
+	return schema, nil
+}
+
+// EncodeKey implements the Encoder interface.
+func (e *avroOCFEncoder) EncodeKey(_ context.Context, row encodeRow) ([]byte, error) {
+	datums, tableDesc := row.keyRow()
+	schema, err := e.keySchema(tableDesc, `key`)
+	if err != nil {
+		return nil, err
+	}
+	return schema.BinaryFromRow(nil /* buf */, datums)
+}
+
+// EncodeValue implements the Encoder interface.
+func (e *avroOCFEncoder) EncodeValue(_ context.Context, row encodeRow) ([]byte, error) {
+	keyDatums, keyTableDesc := row.keyRow()
+	cacheKey := makeTableIDAndVersion(row.tableDesc.ID, row.tableDesc.Version)
+	schema, ok := e.valueCache[cacheKey]
+	if !ok {
+		var keySchema *avroDataRecord
+		if e.keyInValue {
+			var err error
+			if keySchema, err = e.keySchema(keyTableDesc, `key`); err != nil {
+				return nil, err
+			}
+		}
+		afterSchema, err := tableToAvroSchema(row.tableDesc, avroSchemaNoSuffix)
+		if err != nil {
+			return nil, err
+		}
+		opts := avroEnvelopeOpts{keyField: e.keyInValue, afterField: true, updatedField: e.updatedField}
+		schema, err = envelopeToAvroSchema(
+			row.tableDesc.Name, opts, keySchema, nil /* before */, afterSchema)
+		if err != nil {
+			return nil, err
+		}
+		// TODO(dan): Bound the size of this cache.
+		e.valueCache[cacheKey] = schema
+	}
+	var meta avroMetadata
+	if schema.opts.updatedField {
+		meta = map[string]interface{}{
+			`updated`: row.updated,
+		}
+	}
+	var afterDatums sqlbase.EncDatumRow
+	if !row.deleted {
+		afterDatums = row.datums
+	}
+	return schema.BinaryFromRow(nil /* buf */, meta, keyDatums, nil /* beforeRow */, afterDatums)
+}
+
+// EncodeResolvedTimestamp implements the Encoder interface.
+func (e *avroOCFEncoder) EncodeResolvedTimestamp(
+	ctx context.Context, topic string, resolved hlc.Timestamp,
+) ([]byte, error) {
+	return e.resolved.EncodeResolvedTimestamp(ctx, topic, resolved)
+}
+
+// FileHeader implements the fileEncoder interface.
+//
+// https://avro.apache.org/docs/1.8.2/spec.html#Object+Container+Files
+func (e *avroOCFEncoder) FileHeader(tableDesc *sqlbase.TableDescriptor) ([]byte, error) {
+	schema, ok := e.valueCache[makeTableIDAndVersion(tableDesc.ID, tableDesc.Version)]
+	if !ok {
+		return nil, errors.AssertionFailedf(
+			`no schema for version %d of table %s`, tableDesc.Version, tableDesc.Name)
+	}
+	schemaJSON, err := json.Marshal(schema)
+	if err != nil {
+		return nil, err
+	}
+	header := append([]byte(nil), avroOCFMagic...)
+	// The file metadata is a map from string to bytes, in a single block.
+	header = appendAvroLong(header, 2)
+	header = appendAvroBytes(header, []byte(`avro.schema`))
+	header = appendAvroBytes(header, schemaJSON)
+	header = appendAvroBytes(header, []byte(`avro.codec`))
+	header = appendAvroBytes(header, []byte(`null`))
+	header = appendAvroLong(header, 0)
+	return append(header, e.sync[:]...), nil
+}
+
+// WriteFileBlock implements the fileEncoder interface.
+func (e *avroOCFEncoder) WriteFileBlock(w io.Writer, numValues int, values []byte) error {
+	var scratch [2 * binary.MaxVarintLen64]byte
+	blockHeader := appendAvroLong(scratch[:0], int64(numValues))
+	blockHeader = appendAvroLong(blockHeader, int64(len(values)))
+	if _, err := w.Write(blockHeader); err != nil {
+		return err
+	}
+	if _, err := w.Write(values); err != nil {
+		return err
+	}
+	_, err := w.Write(e.sync[:])
+	return err
+}
+
+// appendAvroLong appends the Avro binary encoding of a long, which is the same
+// zig-zag varint encoding as binary.PutVarint.
+func appendAvroLong(buf []byte, x int64) []byte {
+	var scratch [binary.MaxVarintLen64]byte
+	return append(buf, scratch[:binary.PutVarint(scratch[:], x)]...)
+}
+
+// appendAvroBytes appends the Avro binary encoding of bytes or a string.
+func appendAvroBytes(buf []byte, b []byte) []byte {
+	return append(appendAvroLong(buf, int64(len(b))), b...)
+}
+
+// csvDeletedColumn is the name of the last column of the CSV records of a
+// changefeed which may emit deleted rows.
+const csvDeletedColumn = `__crdb__deleted`
+
+// csvEncoder encodes changefeed entries as CSV records. Keys are the primary
+// key columns, values are all columns, and every file starts with a header
+// record of the column names. NULLs are encoded as empty fields, or as the value
+// of the nullas option. Unless the changefeed is initial_scan_only, a last
+// __crdb__deleted column is true for the records of deleted rows, in which only
+// the primary key columns are set. Resolved timestamps are encoded as JSON,
+// since they are written to their own files.
+type csvEncoder struct {
+	deletedColumn bool
+	nullAs        string
+
+	alloc    sqlbase.DatumAlloc
+	buf      bytes.Buffer
+	w        *csv.Writer
+	record   []string
+	resolved *jsonEncoder
+}
+
+var _ Encoder = &csvEncoder{}
+var _ fileEncoder = &csvEncoder{}
+
+func newCSVEncoder(opts map[string]string) (*csvEncoder, error) {
+	if envelopeType(opts[optEnvelope]) != optEnvelopeWrapped {
+		return nil, errors.Errorf(`%s=%s is not supported with %s=%s`,
+			optEnvelope, opts[optEnvelope], optFormat, optFormatCSV)
+	}
+	for _, opt := range []string{optUpdatedTimestamps, optDiff} {
+		if _, ok := opts[opt]; ok {
+			return nil, errors.Errorf(`%s is not supported with %s=%s`, opt, optFormat, optFormatCSV)
+		}
+	}
+	e := &csvEncoder{nullAs: opts[optNullAs]}
+	_, scanOnly := opts[optInitialScanOnly]
+	e.deletedColumn = !scanOnly
+	e.w = csv.NewWriter(&e.buf)
+	var err error
+	if e.resolved, err = makeJSONEncoder(map[string]string{
+		optEnvelope: string(optEnvelopeWrapped),
+	}); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// appendField appends the CSV field of the given column of the row to the
+// record.
+func (e *csvEncoder) appendField(
+	datums sqlbase.EncDatumRow, tableDesc *sqlbase.TableDescriptor, colIdx int,
+) error {
+	col := &tableDesc.Columns[colIdx]
+	if err := datums[colIdx].EnsureDecoded(&col.Type, &e.alloc); err != nil {
+		return err
+	}
+	field := e.nullAs
+	if d := datums[colIdx].Datum; d != tree.DNull {
+		field = tree.AsStringWithFlags(d, tree.FmtBareStrings)
+	}
+	e.record = append(e.record, field)
+	return nil
+}
+
+func (e *csvEncoder) writeRecord(record []string) ([]byte, error) {
+	e.buf.Reset()
+	if err := e.w.Write(record); err != nil {
+		return nil, err
+	}
+	e.w.Flush()
+	if err := e.w.Error(); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(e.buf.Bytes(), []byte{'\n'}), nil
+}
+
+// primaryKeyColIdxs returns the indexes in the columns of the table of its
+// primary key columns.
+func primaryKeyColIdxs(tableDesc *sqlbase.TableDescriptor) ([]int, error) {
+	colIdxByID := tableDesc.ColumnIdxMap()
+	colIdxs := make([]int, len(tableDesc.PrimaryIndex.ColumnIDs))
+	for i, colID := range tableDesc.PrimaryIndex.ColumnIDs {
+		idx, ok := colIdxByID[colID]
+		if !ok {
+			return nil, errors.Errorf(`unknown column id: %d`, colID)
+		}
+		colIdxs[i] = idx
+	}
+	return colIdxs, nil
+}
+
+// EncodeKey implements the Encoder interface.
+func (e *csvEncoder) EncodeKey(_ context.Context, row encodeRow) ([]byte, error) {
+	datums, tableDesc := row.keyRow()
+	colIdxs, err := primaryKeyColIdxs(tableDesc)
+	if err != nil {
+		return nil, err
+	}
+	e.record = e.record[:0]
+	for _, colIdx := range colIdxs {
+		if err := e.appendField(datums, tableDesc, colIdx); err != nil {
+			return nil, err
+		}
+	}
+	return e.writeRecord(e.record)
+}
+
+// EncodeValue implements the Encoder interface.
+func (e *csvEncoder) EncodeValue(_ context.Context, row encodeRow) ([]byte, error) {
+	if row.deleted && !e.deletedColumn {
+		return nil, errors.AssertionFailedf(`%s=%s cannot encode a deleted row with %s`,
+			optFormat, optFormatCSV, optInitialScanOnly)
+	}
+	e.record = e.record[:0]
+	if !row.deleted {
+		for colIdx := range row.tableDesc.Columns {
+			if err := e.appendField(row.datums, row.tableDesc, colIdx); err != nil {
+				return nil, err
+			}
+		}
+	} else {
+		// Only the primary key columns of a deleted row are set.
+		keyDatums, keyTableDesc := row.keyRow()
+		keyColIdxs, err := primaryKeyColIdxs(keyTableDesc)
+		if err != nil {
+			return nil, err
+		}
+		keyFields := make(map[sqlbase.ColumnID]string, len(keyColIdxs))
+		for _, colIdx := range keyColIdxs {
+			if err := e.appendField(keyDatums, keyTableDesc, colIdx); err != nil {
+				return nil, err
+			}
+			keyFields[keyTableDesc.Columns[colIdx].ID] = e.record[len(e.record)-1]
+		}
+		e.record = e.record[:0]
+		for i := range row.tableDesc.Columns {
+			field, ok := keyFields[row.tableDesc.Columns[i].ID]
+			if !ok {
+				field = e.nullAs
+			}
+			e.record = append(e.record, field)
+		}
+	}
+	if e.deletedColumn {
+		e.record = append(e.record, strconv.FormatBool(row.deleted))
+	}
+	return e.writeRecord(e.record)
+}
+
+// EncodeResolvedTimestamp implements the Encoder interface.
+func (e *csvEncoder) EncodeResolvedTimestamp(
+	ctx context.Context, topic string, resolved hlc.Timestamp,
+) ([]byte, error) {
+	return e.resolved.EncodeResolvedTimestamp(ctx, topic, resolved)
+}
+
+// FileHeader implements the fileEncoder interface.
+func (e *csvEncoder) FileHeader(tableDesc *sqlbase.TableDescriptor) ([]byte, error) {
+	names := make([]string, 0, len(tableDesc.Columns)+1)
+	for i := range tableDesc.Columns {
+		names = append(names, tableDesc.Columns[i].Name)
+	}
+	if e.deletedColumn {
+		names = append(names, csvDeletedColumn)
+	}
+	header, err := e.writeRecord(names)
+	if err != nil {
+		return nil, err
+	}
+	return append(append([]byte(nil), header...), '\n'), nil
+}
+
+// WriteFileBlock implements the fileEncoder interface.
+func (e *csvEncoder) WriteFileBlock(w io.Writer, _ int, values []byte) error {
+	_, err := w.Write(values)
+	return err
+}
@@ -0,0 +1,289 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package changefeedccl
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"math"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/errors"
+	"github.com/gogo/protobuf/proto"
+	"github.com/gogo/protobuf/protoc-gen-gogo/descriptor"
+)
+
+// The wire types of the protobuf encoding.
+//
+// https://developers.google.com/protocol-buffers/docs/encoding#structure
+const (
+	protoWireVarint          = 0
+	protoWireFixed64         = 1
+	protoWireLengthDelimited = 2
+)
+
+// The field numbers of the envelope message of a table. The field numbers of
+// the messages of the rows of a table are the IDs of their columns.
+const (
+	protoEnvelopeKeyField     = 1
+	protoEnvelopeAfterField   = 2
+	protoEnvelopeUpdatedField = 3
+)
+
+// protoPackage is the package of the messages of the files written by the
+// protobufEncoder.
+const protoPackage = `changefeed`
+
+// protobufEncoder encodes changefeed entries as protobuf messages. A file of
+// values is a sequence of length-delimited messages (each prefixed by its size
+// as a varint), the first of which is a google.protobuf.FileDescriptorSet
+// describing the messages of the rest. These are envelopes of a `key` message
+// of the primary key columns, an `after` message of all columns, which is
+// unset for deleted rows, and the `updated` timestamp if the option is set.
+//
+// The field numbers of the columns are their IDs, so they stay the same across
+// schema changes. NULLs are unset fields. INT, FLOAT, BOOL, STRING and BYTES
+// columns are encoded as sint64, double, bool, string and bytes fields, and the
+// other columns as strings of their SQL text. Resolved timestamps are encoded
+// as JSON, since they are written to their own files.
+type protobufEncoder struct {
+	updatedField bool
+
+	alloc    sqlbase.DatumAlloc
+	buf      []byte
+	resolved *jsonEncoder
+}
+
+var _ Encoder = &protobufEncoder{}
+var _ fileEncoder = &protobufEncoder{}
+
+func newProtobufEncoder(opts map[string]string) (*protobufEncoder, error) {
+	if envelopeType(opts[optEnvelope]) != optEnvelopeWrapped {
+		return nil, errors.Errorf(`%s=%s is not supported with %s=%s`,
+			optEnvelope, opts[optEnvelope], optFormat, optFormatProtobuf)
+	}
+	// The schema of a file would depend on the version of the table the
+	// previous value of each row was written with.
+	if _, ok := opts[optDiff]; ok {
+		return nil, errors.Errorf(`%s is not supported with %s=%s`,
+			optDiff, optFormat, optFormatProtobuf)
+	}
+	e := &protobufEncoder{}
+	_, e.updatedField = opts[optUpdatedTimestamps]
+	var err error
+	if e.resolved, err = makeJSONEncoder(map[string]string{
+		optEnvelope: string(optEnvelopeWrapped),
+	}); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// appendColumns appends the fields of the given columns of the row to buf.
+func (e *protobufEncoder) appendColumns(
+	buf []byte, datums sqlbase.EncDatumRow, tableDesc *sqlbase.TableDescriptor, colIdxs []int,
+) ([]byte, error) {
+	for _, colIdx := range colIdxs {
+		col := &tableDesc.Columns[colIdx]
+		if err := datums[colIdx].EnsureDecoded(&col.Type, &e.alloc); err != nil {
+			return nil, err
+		}
+		if datums[colIdx].Datum == tree.DNull {
+			continue
+		}
+		fieldNum := int32(col.ID)
+		switch d := datums[colIdx].Datum.(type) {
+		case *tree.DInt:
+			// sint64 fields are zig-zag encoded, like Avro longs.
+			buf = appendProtoTag(buf, fieldNum, protoWireVarint)
+			buf = appendAvroLong(buf, int64(*d))
+		case *tree.DFloat:
+			buf = appendProtoTag(buf, fieldNum, protoWireFixed64)
+			var scratch [8]byte
+			binary.LittleEndian.PutUint64(scratch[:], math.Float64bits(float64(*d)))
+			buf = append(buf, scratch[:]...)
+		case *tree.DBool:
+			buf = appendProtoTag(buf, fieldNum, protoWireVarint)
+			if *d {
+				buf = appendProtoVarint(buf, 1)
+			} else {
+				buf = appendProtoVarint(buf, 0)
+			}
+		case *tree.DString:
+			buf = appendProtoTag(buf, fieldNum, protoWireLengthDelimited)
+			buf = appendProtoBytes(buf, []byte(*d))
+		case *tree.DBytes:
+			buf = appendProtoTag(buf, fieldNum, protoWireLengthDelimited)
+			buf = appendProtoBytes(buf, []byte(*d))
+		default:
+			buf = appendProtoTag(buf, fieldNum, protoWireLengthDelimited)
+			buf = appendProtoBytes(buf, []byte(tree.AsStringWithFlags(d, tree.FmtBareStrings)))
+		}
+	}
+	return buf, nil
+}
+
+// EncodeKey implements the Encoder interface.
+func (e *protobufEncoder) EncodeKey(_ context.Context, row encodeRow) ([]byte, error) {
+	datums, tableDesc := row.keyRow()
+	colIdxs, err := primaryKeyColIdxs(tableDesc)
+	if err != nil {
+		return nil, err
+	}
+	e.buf, err = e.appendColumns(e.buf[:0], datums, tableDesc, colIdxs)
+	return e.buf, err
+}
+
+// EncodeValue implements the Encoder interface.
+func (e *protobufEncoder) EncodeValue(_ context.Context, row encodeRow) ([]byte, error) {
+	keyDatums, keyTableDesc := row.keyRow()
+	keyColIdxs, err := primaryKeyColIdxs(keyTableDesc)
+	if err != nil {
+		return nil, err
+	}
+	key, err := e.appendColumns(nil /* buf */, keyDatums, keyTableDesc, keyColIdxs)
+	if err != nil {
+		return nil, err
+	}
+	envelope := appendProtoTag(nil /* buf */, protoEnvelopeKeyField, protoWireLengthDelimited)
+	envelope = appendProtoBytes(envelope, key)
+	if !row.deleted {
+		colIdxs := make([]int, len(row.tableDesc.Columns))
+		for i := range colIdxs {
+			colIdxs[i] = i
+		}
+		after, err := e.appendColumns(nil /* buf */, row.datums, row.tableDesc, colIdxs)
+		if err != nil {
+			return nil, err
+		}
+		envelope = appendProtoTag(envelope, protoEnvelopeAfterField, protoWireLengthDelimited)
+		envelope = appendProtoBytes(envelope, after)
+	}
+	if e.updatedField {
+		envelope = appendProtoTag(envelope, protoEnvelopeUpdatedField, protoWireLengthDelimited)
+		envelope = appendProtoBytes(envelope, []byte(row.updated.AsOfSystemTime()))
+	}
+	e.buf = appendProtoBytes(e.buf[:0], envelope)
+	return e.buf, nil
+}
+
+// EncodeResolvedTimestamp implements the Encoder interface.
+func (e *protobufEncoder) EncodeResolvedTimestamp(
+	ctx context.Context, topic string, resolved hlc.Timestamp,
+) ([]byte, error) {
+	return e.resolved.EncodeResolvedTimestamp(ctx, topic, resolved)
+}
+
+// FileHeader implements the fileEncoder interface. It is the length-delimited
+// FileDescriptorSet of the messages of the given version of the table.
+func (e *protobufEncoder) FileHeader(tableDesc *sqlbase.TableDescriptor) ([]byte, error) {
+	name := SQLNameToAvroName(tableDesc.Name)
+	keyColIdxs, err := primaryKeyColIdxs(tableDesc)
+	if err != nil {
+		return nil, err
+	}
+	colIdxs := make([]int, len(tableDesc.Columns))
+	for i := range colIdxs {
+		colIdxs[i] = i
+	}
+	messageField := func(name string, num int32, typeName string) *descriptor.FieldDescriptorProto {
+		return &descriptor.FieldDescriptorProto{
+			Name:     proto.String(name),
+			Number:   proto.Int32(num),
+			Label:    descriptor.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+			Type:     descriptor.FieldDescriptorProto_TYPE_MESSAGE.Enum(),
+			TypeName: proto.String(`.` + protoPackage + `.` + typeName),
+		}
+	}
+	envelope := &descriptor.DescriptorProto{
+		Name: proto.String(name + `_envelope`),
+		Field: []*descriptor.FieldDescriptorProto{
+			messageField(`key`, protoEnvelopeKeyField, name+`_key`),
+			messageField(`after`, protoEnvelopeAfterField, name),
+		},
+	}
+	if e.updatedField {
+		envelope.Field = append(envelope.Field, &descriptor.FieldDescriptorProto{
+			Name:   proto.String(`updated`),
+			Number: proto.Int32(protoEnvelopeUpdatedField),
+			Label:  descriptor.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+			Type:   descriptor.FieldDescriptorProto_TYPE_STRING.Enum(),
+		})
+	}
+	set := &descriptor.FileDescriptorSet{File: []*descriptor.FileDescriptorProto{{
+		Name:    proto.String(name + `.proto`),
+		Package: proto.String(protoPackage),
+		Syntax:  proto.String(`proto2`),
+		MessageType: []*descriptor.DescriptorProto{
+			envelope,
+			columnsToProtoMessage(name+`_key`, tableDesc, keyColIdxs),
+			columnsToProtoMessage(name, tableDesc, colIdxs),
+		},
+	}}}
+	header, err := proto.Marshal(set)
+	if err != nil {
+		return nil, err
+	}
+	return appendProtoBytes(nil /* buf */, header), nil
+}
+
+// WriteFileBlock implements the fileEncoder interface.
+func (e *protobufEncoder) WriteFileBlock(w io.Writer, _ int, values []byte) error {
+	_, err := w.Write(values)
+	return err
+}
+
+// columnsToProtoMessage returns the descriptor of the message of the given
+// columns of the table.
+func columnsToProtoMessage(
+	name string, tableDesc *sqlbase.TableDescriptor, colIdxs []int,
+) *descriptor.DescriptorProto {
+	msg := &descriptor.DescriptorProto{Name: proto.String(name)}
+	for _, colIdx := range colIdxs {
+		col := &tableDesc.Columns[colIdx]
+		typ := descriptor.FieldDescriptorProto_TYPE_STRING
+		switch col.Type.Family() {
+		case types.IntFamily:
+			typ = descriptor.FieldDescriptorProto_TYPE_SINT64
+		case types.FloatFamily:
+			typ = descriptor.FieldDescriptorProto_TYPE_DOUBLE
+		case types.BoolFamily:
+			typ = descriptor.FieldDescriptorProto_TYPE_BOOL
+		case types.BytesFamily:
+			typ = descriptor.FieldDescriptorProto_TYPE_BYTES
+		}
+		msg.Field = append(msg.Field, &descriptor.FieldDescriptorProto{
+			Name:   proto.String(SQLNameToAvroName(col.Name)),
+			Number: proto.Int32(int32(col.ID)),
+			Label:  descriptor.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+			Type:   typ.Enum(),
+		})
+	}
+	return msg
+}
+
+// appendProtoVarint appends the protobuf encoding of a varint.
+func appendProtoVarint(buf []byte, x uint64) []byte {
+	var scratch [binary.MaxVarintLen64]byte
+	return append(buf, scratch[:binary.PutUvarint(scratch[:], x)]...)
+}
+
+// appendProtoTag appends the key of a field of a protobuf message.
+func appendProtoTag(buf []byte, fieldNum int32, wireType uint64) []byte {
+	return appendProtoVarint(buf, uint64(fieldNum)<<3|wireType)
+}
+
+// appendProtoBytes appends the protobuf encoding of a length-delimited value.
+func appendProtoBytes(buf []byte, b []byte) []byte {
+	return append(appendProtoVarint(buf, uint64(len(b))), b...)
+}
@@ -9,6 +9,7 @@
 package changefeedccl
 
 import (
+	"bytes"
 	"context"
 	gosql "database/sql"
 	"encoding/binary"
@@ -451,3 +452,151 @@ func TestAvroLedger(t *testing.T) {
 	t.Run(`sinkless`, sinklessTest(testFn))
 	t.Run(`enterprise`, enterpriseTest(testFn))
 }
+
+func TestFileEncoders(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	tableDesc, err := parseTableDesc(`CREATE TABLE foo (a INT PRIMARY KEY, b STRING)`)
+	require.NoError(t, err)
+	rows := []encodeRow{
+		{
+			datums: sqlbase.EncDatumRow{
+				sqlbase.EncDatum{Datum: tree.NewDInt(1)},
+				sqlbase.EncDatum{Datum: tree.NewDString(`bar`)},
+			},
+			tableDesc: tableDesc,
+		},
+		{
+			datums: sqlbase.EncDatumRow{
+				sqlbase.EncDatum{Datum: tree.NewDInt(2)},
+				sqlbase.EncDatum{Datum: tree.DNull},
+			},
+			tableDesc: tableDesc,
+		},
+	}
+
+	encodeFile := func(t *testing.T, e Encoder, delim []byte) []byte {
+		var values []byte
+		for _, row := range rows {
+			value, err := e.EncodeValue(context.TODO(), row)
+			require.NoError(t, err)
+			values = append(append(values, value...), delim...)
+		}
+		fe := e.(fileEncoder)
+		header, err := fe.FileHeader(tableDesc)
+		require.NoError(t, err)
+		file := bytes.NewBuffer(append([]byte(nil), header...))
+		require.NoError(t, fe.WriteFileBlock(file, len(rows), values))
+		return file.Bytes()
+	}
+
+	t.Run(`avro_ocf`, func(t *testing.T) {
+		e, err := getEncoder(map[string]string{
+			optFormat:     string(optFormatAvroOCF),
+			optEnvelope:   string(optEnvelopeWrapped),
+			optKeyInValue: ``,
+		})
+		require.NoError(t, err)
+		file := encodeFile(t, e, nil /* delim */)
+
+		r, err := goavro.NewOCFReader(bytes.NewReader(file))
+		require.NoError(t, err)
+		var actual []string
+		for r.Scan() {
+			native, err := r.Read()
+			require.NoError(t, err)
+			textual, err := r.Codec().TextualFromNative(nil, native)
+			require.NoError(t, err)
+			actual = append(actual, string(textual))
+		}
+		require.NoError(t, r.Err())
+		require.Equal(t, []string{
+			`{"key":{"a":{"long":1}},"after":{"foo":{"a":{"long":1},"b":{"string":"bar"}}}}`,
+			`{"key":{"a":{"long":2}},"after":{"foo":{"a":{"long":2},"b":null}}}`,
+		}, actual)
+	})
+
+	t.Run(`csv`, func(t *testing.T) {
+		e, err := getEncoder(map[string]string{
+			optFormat:          string(optFormatCSV),
+			optEnvelope:        string(optEnvelopeWrapped),
+			optInitialScanOnly: ``,
+		})
+		require.NoError(t, err)
+		key, err := e.EncodeKey(context.TODO(), rows[0])
+		require.NoError(t, err)
+		require.Equal(t, `1`, string(key))
+		file := encodeFile(t, e, []byte{'\n'})
+		require.Equal(t, "a,b\n1,bar\n2,\n", string(file))
+
+		// NULLs can be told apart from empty strings with nullas.
+		e, err = getEncoder(map[string]string{
+			optFormat:          string(optFormatCSV),
+			optEnvelope:        string(optEnvelopeWrapped),
+			optInitialScanOnly: ``,
+			optNullAs:          `NULL`,
+		})
+		require.NoError(t, err)
+		file = encodeFile(t, e, []byte{'\n'})
+		require.Equal(t, "a,b\n1,bar\n2,NULL\n", string(file))
+		value, err := e.EncodeValue(context.TODO(), encodeRow{
+			datums: sqlbase.EncDatumRow{
+				sqlbase.EncDatum{Datum: tree.NewDInt(3)},
+				sqlbase.EncDatum{Datum: tree.NewDString(``)},
+			},
+			tableDesc: tableDesc,
+		})
+		require.NoError(t, err)
+		require.Equal(t, `3,`, string(value))
+
+		// Unless the changefeed is initial_scan_only, the records say whether
+		// the row was deleted.
+		e, err = getEncoder(map[string]string{
+			optFormat:   string(optFormatCSV),
+			optEnvelope: string(optEnvelopeWrapped),
+		})
+		require.NoError(t, err)
+		file = encodeFile(t, e, []byte{'\n'})
+		require.Equal(t, "a,b,__crdb__deleted\n1,bar,false\n2,,false\n", string(file))
+		value, err = e.EncodeValue(context.TODO(), encodeRow{
+			datums:    rows[0].datums,
+			tableDesc: tableDesc,
+			deleted:   true,
+		})
+		require.NoError(t, err)
+		require.Equal(t, `1,,true`, string(value))
+	})
+
+	t.Run(`protobuf`, func(t *testing.T) {
+		e, err := getEncoder(map[string]string{
+			optFormat:     string(optFormatProtobuf),
+			optEnvelope:   string(optEnvelopeWrapped),
+			optKeyInValue: ``,
+		})
+		require.NoError(t, err)
+		// Field 1 (a) is the zig-zag varint 1.
+		key, err := e.EncodeKey(context.TODO(), rows[0])
+		require.NoError(t, err)
+		require.Equal(t, []byte{0x08, 0x02}, key)
+
+		// The envelope of the key (field 1) and the row (field 2), with its
+		// length. The NULL column b is unset.
+		value, err := e.EncodeValue(context.TODO(), rows[1])
+		require.NoError(t, err)
+		require.Equal(t, []byte{0x08, 0x0a, 0x02, 0x08, 0x04, 0x12, 0x02, 0x08, 0x04}, value)
+		value, err = e.EncodeValue(context.TODO(), rows[0])
+		require.NoError(t, err)
+		require.Equal(t, []byte{
+			0x0d, 0x0a, 0x02, 0x08, 0x02, 0x12, 0x07, 0x08, 0x02, 0x12, 0x03, 'b', 'a', 'r',
+		}, value)
+
+		// The row of a deleted row is unset.
+		value, err = e.EncodeValue(context.TODO(), encodeRow{
+			datums:    rows[1].datums,
+			tableDesc: tableDesc,
+			deleted:   true,
+		})
+		require.NoError(t, err)
+		require.Equal(t, []byte{0x04, 0x0a, 0x02, 0x08, 0x04}, value)
+	})
+}
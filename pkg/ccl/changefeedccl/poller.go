@@ -42,10 +42,13 @@ import (
 // Each poll (ie set of ExportRequests) are rate limited to be no more often
 // than the `changefeed.experimental_poll_interval` setting.
 type poller struct {
-	settings  *cluster.Settings
-	db        *client.DB
-	clock     *hlc.Clock
-	gossip    *gossip.Gossip
+	settings *cluster.Settings
+	db       *client.DB
+	clock    *hlc.Clock
+	gossip   *gossip.Gossip
+	// spans are the watched spans. For a changefeed on a database, they change
+	// at the scan boundaries as tables are created in and dropped from the
+	// database. They are only used by the rangefeed goroutine.
 	spans     []roachpb.Span
 	details   jobspb.ChangefeedDetails
 	buf       *buffer
@@ -59,18 +62,41 @@ type poller struct {
 		// highWater timestamp for exports processed by this poller so far.
 		highWater hlc.Timestamp
 		// scanBoundaries represent timestamps where the changefeed output process
-		// should pause and output a scan of *all keys* of the watched spans at the
-		// given timestamp. There are currently two situations where this occurs:
-		// the initial scan of the table when starting a new Changefeed, and when
-		// a backfilling schema change is marked as completed. This collection must
-		// be kept in sorted order (by timestamp ascending).
-		scanBoundaries []hlc.Timestamp
+		// should pause and, for the boundaries with scan set, output a scan of
+		// *all keys* of the watched spans at the given timestamp. There are
+		// currently two situations where this occurs: the initial scan of the
+		// table when starting a new Changefeed, and when a backfilling schema
+		// change is marked as completed. The watched spans of a changefeed on a
+		// database also change at a boundary, without a scan. This collection
+		// must be kept in sorted order (by timestamp ascending).
+		scanBoundaries []scanBoundary
 		// previousTableVersion is a map from tableID to the most recent version
 		// of the table descriptor seen by the poller. This is needed to determine
 		// when a backilling mutation has successfully completed - this can only
 		// be determining by comparing a version to the previous version.
 		previousTableVersion map[sqlbase.ID]*sqlbase.TableDescriptor
 	}
+
+	// targets are the targets of the changefeed, which grow as tables are
+	// created in the watched database of a changefeed on a database. The ids of
+	// the tables dropped from it are in droppedTables. Both are only used by
+	// validateTable, which runs on the table history goroutine.
+	targets       jobspb.ChangefeedTargets
+	droppedTables map[sqlbase.ID]struct{}
+}
+
+// scanBoundary is a timestamp where the changefeed output process pauses, see
+// poller.mu.scanBoundaries.
+type scanBoundary struct {
+	ts hlc.Timestamp
+	// scan is set when all the watched spans are scanned at the boundary.
+	scan bool
+	// addedTables and droppedTables are the tables whose primary index spans
+	// start or stop being watched at the boundary, in a changefeed on a
+	// database. The spans of the added tables are watched from before they were
+	// created, which is the boundary.
+	addedTables   []*sqlbase.TableDescriptor
+	droppedTables []*sqlbase.TableDescriptor
 }
 
 func makePoller(
@@ -100,12 +126,19 @@ func makePoller(
 		mm:       mm,
 	}
 	p.mu.previousTableVersion = make(map[sqlbase.ID]*sqlbase.TableDescriptor)
+	p.targets = make(jobspb.ChangefeedTargets, len(details.Targets))
+	for id, t := range details.Targets {
+		p.targets[id] = t
+	}
+	p.droppedTables = make(map[sqlbase.ID]struct{})
 	// If no highWater is specified, set the highwater to the statement time
 	// and add a scanBoundary at the statement time to trigger an immediate output
 	// of the full table.
 	if highWater == (hlc.Timestamp{}) {
 		p.mu.highWater = details.StatementTime
-		p.mu.scanBoundaries = append(p.mu.scanBoundaries, details.StatementTime)
+		p.mu.scanBoundaries = append(p.mu.scanBoundaries, scanBoundary{
+			ts: details.StatementTime, scan: true,
+		})
 	} else {
 		p.mu.highWater = highWater
 	}
@@ -146,7 +179,11 @@ func (p *poller) primeInitialTableDescs(ctx context.Context) error {
 		// Note that all targets are currently guaranteed to be tables.
 		for tableID := range p.details.Targets {
 			tableDesc, err := sqlbase.GetTableDescFromID(ctx, txn, tableID)
-			if err != nil {
+			if err == sqlbase.ErrDescriptorNotFound && p.details.DatabaseID != 0 {
+				// The table was created in the watched database after the
+				// high-water, its descriptor is picked up by the table history.
+				continue
+			} else if err != nil {
 				return err
 			}
 			initialDescs = append(initialDescs, tableDesc)
@@ -181,6 +218,21 @@ func (p *poller) rangefeedImplIter(ctx context.Context, i int) error {
 		return err
 	}
 
+	// Consume the boundary at the high-water, if any.
+	var boundary scanBoundary
+	p.mu.Lock()
+	if len(p.mu.scanBoundaries) > 0 && p.mu.scanBoundaries[0].ts.Equal(p.mu.highWater) {
+		boundary = p.mu.scanBoundaries[0]
+		p.mu.scanBoundaries = p.mu.scanBoundaries[1:]
+	}
+	p.mu.Unlock()
+	// The spans of the tables created in the watched database are watched from
+	// before their creation.
+	addedSpans, err := p.updateWatchedSpans(ctx, boundary)
+	if err != nil {
+		return err
+	}
+
 	spans, err := getSpansToProcess(ctx, p.db, p.spans)
 	if err != nil {
 		return err
@@ -191,20 +243,50 @@ func (p *poller) rangefeedImplIter(ctx context.Context, i int) error {
 	initialScan := i == 0
 	backfillWithDiff := !initialScan && withDiff
 	var scanTime hlc.Timestamp
-	p.mu.Lock()
-	if len(p.mu.scanBoundaries) > 0 && p.mu.scanBoundaries[0].Equal(p.mu.highWater) {
+	if boundary.scan {
 		// Perform a full scan of the latest value of all keys as of the
-		// boundary timestamp and consume the boundary.
-		scanTime = p.mu.scanBoundaries[0]
-		p.mu.scanBoundaries = p.mu.scanBoundaries[1:]
+		// boundary timestamp.
+		scanTime = boundary.ts
 	}
-	p.mu.Unlock()
 	if scanTime != (hlc.Timestamp{}) {
+		_, scanOnly := p.details.Opts[optInitialScanOnly]
+		scanOnly = scanOnly && initialScan
+		// The changefeed is done once the initial scan is resolved. Each span
+		// is resolved as soon as it is exported, so that the progress of the
+		// scan is reported.
+		var exported func(context.Context, roachpb.Span) error
+		if scanOnly {
+			exported = func(ctx context.Context, span roachpb.Span) error {
+				return p.buf.AddResolvedBoundary(ctx, span, scanTime)
+			}
+		}
 		// TODO(dan): Now that we no longer have the poller, we should stop using
 		// ExportRequest and start using normal Scans.
-		if err := p.exportSpansParallel(ctx, spans, scanTime, backfillWithDiff); err != nil {
+		if err := p.exportSpansParallel(ctx, spans, scanTime, backfillWithDiff, exported); err != nil {
+			return err
+		}
+		if scanOnly {
+			// A changefeed on a database without tables has no span to resolve,
+			// so it resolves the empty span instead.
+			if len(spans) == 0 {
+				if err := p.buf.AddResolvedBoundary(ctx, roachpb.Span{}, scanTime); err != nil {
+					return err
+				}
+			}
+			// Don't start the rangefeeds, just wait to be shut down.
+			<-ctx.Done()
+			return ctx.Err()
+		}
+	}
+
+	if len(p.spans) == 0 {
+		// Nothing is watched, so there is nothing to resolve either. This only
+		// happens in a changefeed on a database without tables, which waits for
+		// one to be created.
+		if err := p.waitForScanBoundary(ctx); err != nil {
 			return err
 		}
+		return p.finishScanBoundary(ctx)
 	}
 
 	// Start rangefeeds, exit polling if we hit a resolved timestamp beyond
@@ -251,9 +333,13 @@ func (p *poller) rangefeedImplIter(ctx context.Context, i int) error {
 	rangeFeedStartTS := lastHighwater
 	for _, span := range p.spans {
 		span := span
-		frontier.Forward(span, rangeFeedStartTS)
+		startTS := rangeFeedStartTS
+		if _, ok := addedSpans[span.Key.String()]; ok {
+			startTS = boundary.ts.Prev()
+		}
+		frontier.Forward(span, startTS)
 		g.GoCtx(func(ctx context.Context) error {
-			return ds.RangeFeed(ctx, span, rangeFeedStartTS, withDiff, eventC)
+			return ds.RangeFeed(ctx, span, startTS, withDiff, eventC)
 		})
 	}
 	g.GoCtx(func(ctx context.Context) error {
@@ -300,7 +386,7 @@ func (p *poller) rangefeedImplIter(ctx context.Context, i int) error {
 				}
 				pastBoundary := false
 				p.mu.Lock()
-				if len(p.mu.scanBoundaries) > 0 && p.mu.scanBoundaries[0].Less(e.kv.Value.Timestamp) {
+				if len(p.mu.scanBoundaries) > 0 && p.mu.scanBoundaries[0].ts.Less(e.kv.Value.Timestamp) {
 					// Ignore feed results beyond the next boundary; they will be retrieved when
 					// the feeds are restarted after the scan.
 					pastBoundary = true
@@ -321,9 +407,9 @@ func (p *poller) rangefeedImplIter(ctx context.Context, i int) error {
 					return err
 				}
 				p.mu.Lock()
-				if len(p.mu.scanBoundaries) > 0 && !resolvedTS.Less(p.mu.scanBoundaries[0]) {
+				if len(p.mu.scanBoundaries) > 0 && !resolvedTS.Less(p.mu.scanBoundaries[0].ts) {
 					boundaryBreak = true
-					resolvedTS = p.mu.scanBoundaries[0]
+					resolvedTS = p.mu.scanBoundaries[0].ts
 				}
 				p.mu.Unlock()
 				if boundaryBreak {
@@ -354,13 +440,82 @@ func (p *poller) rangefeedImplIter(ctx context.Context, i int) error {
 	if err := g.Wait(); err != nil && err != errBoundaryReached {
 		return err
 	}
+	return p.finishScanBoundary(ctx)
+}
 
+// finishScanBoundary moves the high-water to the next scan boundary, once
+// everything before it has been added to the buffer. The boundary is consumed
+// by the next iteration of the rangefeeds.
+func (p *poller) finishScanBoundary(ctx context.Context) error {
 	p.mu.Lock()
-	p.mu.highWater = p.mu.scanBoundaries[0]
+	boundary := p.mu.scanBoundaries[0]
+	p.mu.highWater = boundary.ts
 	p.mu.Unlock()
+
+	if boundary.scan &&
+		schemaChangePolicy(p.details.Opts[optSchemaChangePolicy]) == optSchemaChangePolicyStop {
+		// Everything before the schema change has been added to the buffer.
+		// Resolve it, so the changefeed stops once it has been emitted, and
+		// then wait to be shut down.
+		for _, span := range p.spans {
+			if err := p.buf.AddResolvedBoundary(ctx, span, boundary.ts.Prev()); err != nil {
+				return err
+			}
+		}
+		<-ctx.Done()
+		return ctx.Err()
+	}
 	return nil
 }
 
+// waitForScanBoundary blocks until there is a scan boundary.
+func (p *poller) waitForScanBoundary(ctx context.Context) error {
+	for {
+		p.mu.Lock()
+		found := len(p.mu.scanBoundaries) > 0
+		p.mu.Unlock()
+		if found {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(changefeedPollInterval.Get(&p.settings.SV)):
+		}
+	}
+}
+
+// updateWatchedSpans updates the watched spans with the tables created in and
+// dropped from the watched database at the given boundary, and tells the rest
+// of the changefeed about it through the buffer. The keys of the added spans
+// are returned.
+func (p *poller) updateWatchedSpans(
+	ctx context.Context, boundary scanBoundary,
+) (map[string]struct{}, error) {
+	for _, desc := range boundary.droppedTables {
+		span := desc.PrimaryIndexSpan()
+		for i := range p.spans {
+			if p.spans[i].Equal(span) {
+				p.spans = append(p.spans[:i], p.spans[i+1:]...)
+				break
+			}
+		}
+		if err := p.buf.RemoveTableSpan(ctx, desc.ID, span, boundary.ts); err != nil {
+			return nil, err
+		}
+	}
+	added := make(map[string]struct{}, len(boundary.addedTables))
+	for _, desc := range boundary.addedTables {
+		span := desc.PrimaryIndexSpan()
+		p.spans = append(p.spans, span)
+		added[span.Key.String()] = struct{}{}
+		if err := p.buf.AddTableSpan(ctx, desc.ID, desc.Name, span, boundary.ts.Prev()); err != nil {
+			return nil, err
+		}
+	}
+	return added, nil
+}
+
 func getSpansToProcess(
 	ctx context.Context, db *client.DB, targetSpans []roachpb.Span,
 ) ([]roachpb.Span, error) {
@@ -408,8 +563,14 @@ func getSpansToProcess(
 	return requests, nil
 }
 
+// exportSpansParallel exports the spans at ts. If exported is non-nil, it is
+// called with each span once its rows were added to the buffer.
 func (p *poller) exportSpansParallel(
-	ctx context.Context, spans []roachpb.Span, ts hlc.Timestamp, withDiff bool,
+	ctx context.Context,
+	spans []roachpb.Span,
+	ts hlc.Timestamp,
+	withDiff bool,
+	exported func(context.Context, roachpb.Span) error,
 ) error {
 	// Export requests for the various watched spans are executed in parallel,
 	// with a semaphore-enforced limit based on a cluster setting.
@@ -442,6 +603,9 @@ func (p *poller) exportSpansParallel(
 			if err != nil {
 				return err
 			}
+			if exported != nil {
+				return exported(ctx, span)
+			}
 			return nil
 		})
 	}
@@ -507,7 +671,8 @@ func (p *poller) updateTableHistory(ctx context.Context, endTS hlc.Timestamp) er
 	if !startTS.Less(endTS) {
 		return nil
 	}
-	descs, err := fetchTableDescriptorVersions(ctx, p.db, startTS, endTS, p.details.Targets)
+	descs, err := fetchTableDescriptorVersions(
+		ctx, p.db, startTS, endTS, p.details.Targets, p.details.DatabaseID)
 	if err != nil {
 		return err
 	}
@@ -629,7 +794,16 @@ func clusterNodeCount(g *gossip.Gossip) int {
 }
 
 func (p *poller) validateTable(ctx context.Context, desc *sqlbase.TableDescriptor) error {
-	if err := validateChangefeedTable(p.details.Targets, desc); err != nil {
+	if p.details.DatabaseID != 0 {
+		if err := p.updateDatabaseTargets(desc); err != nil {
+			return err
+		}
+		if _, ok := p.droppedTables[desc.ID]; ok {
+			// The table is no longer watched.
+			return nil
+		}
+	}
+	if err := validateChangefeedTable(p.targets, p.details.DatabaseID, desc); err != nil {
 		return err
 	}
 	p.mu.Lock()
@@ -638,7 +812,7 @@ func (p *poller) validateTable(ctx context.Context, desc *sqlbase.TableDescripto
 		if desc.ModificationTime.Less(lastVersion.ModificationTime) {
 			return nil
 		}
-		if shouldAddScanBoundary(lastVersion, desc) {
+		if shouldAddScanBoundary(p.details, lastVersion, desc) {
 			boundaryTime := desc.GetModificationTime()
 			// Only mutations that happened after the changefeed started are
 			// interesting here.
@@ -652,10 +826,7 @@ func (p *poller) validateTable(ctx context.Context, desc *sqlbase.TableDescripto
 						errors.Safe(p.mu.highWater),
 					)
 				}
-				p.mu.scanBoundaries = append(p.mu.scanBoundaries, boundaryTime)
-				sort.Slice(p.mu.scanBoundaries, func(i, j int) bool {
-					return p.mu.scanBoundaries[i].Less(p.mu.scanBoundaries[j])
-				})
+				p.addScanBoundaryLocked(scanBoundary{ts: boundaryTime, scan: true})
 				// To avoid race conditions with the lease manager, at this point we force
 				// the manager to acquire the freshest descriptor of this table from the
 				// store. In normal operation, the lease manager returns the newest
@@ -673,11 +844,102 @@ func (p *poller) validateTable(ctx context.Context, desc *sqlbase.TableDescripto
 	return nil
 }
 
+// updateDatabaseTargets adds the given table to the targets of a changefeed on
+// a database when it was created in the database, and removes it once it was
+// dropped. The watched spans change accordingly at a scan boundary.
+func (p *poller) updateDatabaseTargets(desc *sqlbase.TableDescriptor) error {
+	if _, ok := p.droppedTables[desc.ID]; ok {
+		return nil
+	}
+	_, watched := p.targets[desc.ID]
+	dropped := desc.State == sqlbase.TableDescriptor_DROP
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	switch {
+	case !watched && dropped:
+		// The table was dropped before it was ever watched.
+		p.droppedTables[desc.ID] = struct{}{}
+	case !watched && isDatabaseTable(p.details.DatabaseID, desc):
+		// Only tables created after the high-water are missing from the
+		// targets, so the boundary is after it.
+		boundaryTime := desc.GetModificationTime()
+		if !p.mu.highWater.Less(boundaryTime) {
+			return errors.AssertionFailedf(
+				"error: detected table ID %d created at %s "+
+					"not later than highwater timestamp %s",
+				errors.Safe(desc.ID),
+				errors.Safe(boundaryTime),
+				errors.Safe(p.mu.highWater),
+			)
+		}
+		p.targets[desc.ID] = jobspb.ChangefeedTarget{StatementTimeName: desc.Name}
+		p.addScanBoundaryLocked(scanBoundary{
+			ts: boundaryTime, addedTables: []*sqlbase.TableDescriptor{desc},
+		})
+	case watched && dropped:
+		// A table may have been dropped before the high-water the changefeed
+		// was resumed at, in which case it stops being watched right away.
+		boundaryTime := desc.GetModificationTime()
+		boundaryTime.Forward(p.mu.highWater)
+		delete(p.targets, desc.ID)
+		p.droppedTables[desc.ID] = struct{}{}
+		p.addScanBoundaryLocked(scanBoundary{
+			ts: boundaryTime, droppedTables: []*sqlbase.TableDescriptor{desc},
+		})
+	}
+	return nil
+}
+
+// addScanBoundaryLocked adds the given boundary to the scan boundaries, merging
+// it with an existing boundary at the same timestamp. p.mu must be held.
+func (p *poller) addScanBoundaryLocked(b scanBoundary) {
+	i := sort.Search(len(p.mu.scanBoundaries), func(i int) bool {
+		return !p.mu.scanBoundaries[i].ts.Less(b.ts)
+	})
+	if i < len(p.mu.scanBoundaries) && p.mu.scanBoundaries[i].ts.Equal(b.ts) {
+		existing := &p.mu.scanBoundaries[i]
+		existing.scan = existing.scan || b.scan
+		existing.addedTables = append(existing.addedTables, b.addedTables...)
+		existing.droppedTables = append(existing.droppedTables, b.droppedTables...)
+		return
+	}
+	p.mu.scanBoundaries = append(p.mu.scanBoundaries, scanBoundary{})
+	copy(p.mu.scanBoundaries[i+1:], p.mu.scanBoundaries[i:])
+	p.mu.scanBoundaries[i] = b
+}
+
+// shouldAddScanBoundary returns whether the change from lastVersion to desc is
+// a schema change event of the changefeed, in which case the changefeed has to
+// either backfill the table or stop at the timestamp of desc.
 func shouldAddScanBoundary(
-	lastVersion *sqlbase.TableDescriptor, desc *sqlbase.TableDescriptor,
+	details jobspb.ChangefeedDetails,
+	lastVersion *sqlbase.TableDescriptor,
+	desc *sqlbase.TableDescriptor,
 ) (res bool) {
-	return newColumnBackfillComplete(lastVersion, desc) ||
-		hasNewColumnDropBackfillMutation(lastVersion, desc)
+	if schemaChangePolicy(details.Opts[optSchemaChangePolicy]) == optSchemaChangePolicyNoBackfill {
+		return false
+	}
+	if newColumnBackfillComplete(lastVersion, desc) ||
+		hasNewColumnDropBackfillMutation(lastVersion, desc) {
+		return true
+	}
+	events := schemaChangeEventClass(details.Opts[optSchemaChangeEvents])
+	return events == optSchemaChangeEventClassColumnChange && publicColumnsChanged(lastVersion, desc)
+}
+
+// publicColumnsChanged returns whether a column was added to or removed from
+// the public columns of a table, including the ones that don't need a
+// backfill.
+func publicColumnsChanged(oldDesc, newDesc *sqlbase.TableDescriptor) bool {
+	if len(oldDesc.Columns) != len(newDesc.Columns) {
+		return true
+	}
+	for i := range oldDesc.Columns {
+		if oldDesc.Columns[i].ID != newDesc.Columns[i].ID {
+			return true
+		}
+	}
+	return false
 }
 
 func hasNewColumnDropBackfillMutation(oldDesc, newDesc *sqlbase.TableDescriptor) (res bool) {
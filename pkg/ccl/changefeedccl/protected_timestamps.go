@@ -0,0 +1,78 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package changefeedccl
+
+import (
+	"context"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobsprotectedts"
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/storage/protectedts"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
+)
+
+// changefeedProtectTimestampInterval controls how often a changefeed moves
+// its protected timestamp record up to its high-water. Data above the
+// protected timestamp is not garbage collected, which lets a paused
+// changefeed be resumed after more than the GC TTL.
+var changefeedProtectTimestampInterval = settings.RegisterNonNegativeDurationSetting(
+	"changefeed.protect_timestamp_interval",
+	"the interval at which changefeeds forward their protected timestamp to their high-water",
+	10*time.Minute,
+)
+
+// spansToProtect returns the spans which are read by a changefeed: the spans
+// of the targeted tables and the span of the descriptor table, which is used
+// to track the schema changes of the targets.
+func spansToProtect(targets jobspb.ChangefeedTargets) []roachpb.Span {
+	spans := make([]roachpb.Span, 0, len(targets)+1)
+	addTable := func(tableID uint32) {
+		prefix := roachpb.Key(keys.MakeTablePrefix(tableID))
+		spans = append(spans, roachpb.Span{Key: prefix, EndKey: prefix.PrefixEnd()})
+	}
+	for tableID := range targets {
+		addTable(uint32(tableID))
+	}
+	addTable(keys.DescriptorTableID)
+	return spans
+}
+
+// protectTimestamp creates a protected timestamp record for the changefeed at
+// the given timestamp, releases the record previously created by the
+// changefeed, if any, and stores the ID of the new record in the progress of
+// the job. It is run in the given transaction.
+func protectTimestamp(
+	ctx context.Context,
+	txn *client.Txn,
+	pts protectedts.Storage,
+	job *jobs.Job,
+	targets jobspb.ChangefeedTargets,
+	ts hlc.Timestamp,
+	progress jobspb.ChangefeedProgress,
+) (jobspb.ChangefeedProgress, error) {
+	if prev := progress.ProtectedTimestampRecord; prev != uuid.Nil {
+		if err := pts.Release(ctx, txn, prev); err != nil && err != protectedts.ErrNotExists {
+			return progress, err
+		}
+	}
+	id := uuid.MakeV4()
+	rec := jobsprotectedts.MakeRecord(id, *job.ID(), ts, spansToProtect(targets))
+	if err := pts.Protect(ctx, txn, rec); err != nil {
+		return progress, err
+	}
+	progress.ProtectedTimestampRecord = id
+	return progress, job.WithTxn(txn).SetProgress(ctx, progress)
+}
@@ -0,0 +1,334 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package changefeedccl
+
+import (
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/pkg/errors"
+)
+
+// changefeedSelectRejectFlags are the expressions which can't be used in the
+// SELECT clause of a changefeed. Only expressions which are a function of the
+// changed row are allowed, so that the output of the changefeed doesn't depend
+// on when or where a row is processed.
+const changefeedSelectRejectFlags = tree.RejectAggregates | tree.RejectWindowApplications |
+	tree.RejectGenerators | tree.RejectImpureFunctions | tree.RejectSubqueries
+
+// changefeedSelectTarget validates the shape of the SELECT clause of a CREATE
+// CHANGEFEED ... AS SELECT, and returns the table it selects from.
+func changefeedSelectTarget(sc *tree.SelectClause) (*tree.TableName, error) {
+	unsupported := func(clause string) error {
+		return errors.Errorf(`CHANGEFEED ... AS SELECT does not support %s`, clause)
+	}
+	switch {
+	case sc.Distinct || sc.DistinctOn != nil:
+		return nil, unsupported(`DISTINCT`)
+	case len(sc.GroupBy) > 0:
+		return nil, unsupported(`GROUP BY`)
+	case sc.Having != nil:
+		return nil, unsupported(`HAVING`)
+	case len(sc.Window) > 0:
+		return nil, unsupported(`WINDOW`)
+	case sc.From.AsOf.Expr != nil:
+		return nil, unsupported(`AS OF SYSTEM TIME`)
+	case len(sc.From.Tables) != 1:
+		return nil, errors.New(`CHANGEFEED ... AS SELECT requires exactly one table in FROM`)
+	}
+	ate, ok := sc.From.Tables[0].(*tree.AliasedTableExpr)
+	if !ok || ate.Ordinality || ate.IndexFlags != nil || len(ate.As.Cols) > 0 {
+		return nil, errors.Errorf(`CHANGEFEED cannot select from %s`, tree.AsString(sc.From.Tables[0]))
+	}
+	tn, ok := ate.Expr.(*tree.TableName)
+	if !ok {
+		return nil, errors.Errorf(`CHANGEFEED cannot select from %s`, tree.AsString(ate.Expr))
+	}
+	return tn, nil
+}
+
+// changefeedSelect evaluates the projection and filter of a changefeed created
+// with CREATE CHANGEFEED ... AS SELECT on the rows decoded by kvsToRows. The
+// expressions are bound to each version of the watched table when a row of
+// that version is first seen, so they survive schema changes which keep the
+// columns they reference.
+//
+// It is not concurrency-safe.
+type changefeedSelect struct {
+	evalCtx *tree.EvalContext
+	clause  string
+	alloc   sqlbase.DatumAlloc
+	bound   map[tableIDAndVersion]*boundSelect
+}
+
+// makeChangefeedSelect returns the changefeedSelect for the SELECT clause of
+// the changefeed, or nil if the changefeed has none.
+func makeChangefeedSelect(evalCtx *tree.EvalContext, clause string) *changefeedSelect {
+	if clause == `` {
+		return nil
+	}
+	return &changefeedSelect{
+		evalCtx: evalCtx,
+		clause:  clause,
+		bound:   make(map[tableIDAndVersion]*boundSelect),
+	}
+}
+
+// apply replaces the row with its projection, and returns false if the row is
+// filtered out instead.
+//
+// A WHERE clause requires the previous value of the row, given by the diff
+// option. A row which doesn't match the WHERE clause, whether it was updated
+// or deleted, is emitted as a deletion if its previous value matched, so that
+// downstream consumers learn that the row left the filtered set, and is
+// filtered out otherwise. The previous value of the row, if any, is projected,
+// and is treated as deleted if it didn't match.
+func (s *changefeedSelect) apply(row *encodeRow) (bool, error) {
+	b, err := s.bind(row.tableDesc)
+	if err != nil {
+		return false, err
+	}
+	var prev *boundSelect
+	if row.prevDatums != nil {
+		if prev, err = s.bind(row.prevTableDesc); err != nil {
+			return false, err
+		}
+	}
+
+	matches := !row.deleted
+	if matches {
+		if matches, err = b.filter(s.evalCtx, &s.alloc, row.datums); err != nil {
+			return false, err
+		}
+	}
+	if prev != nil && !row.prevDeleted {
+		var prevMatches bool
+		if prevMatches, err = prev.filter(s.evalCtx, &s.alloc, row.prevDatums); err != nil {
+			return false, err
+		}
+		row.prevDeleted = !prevMatches
+	}
+	if !matches && b.where != nil {
+		if prev == nil || row.prevDeleted {
+			return false, nil
+		}
+		row.deleted = true
+	}
+
+	row.keyDatums, row.keyTableDesc = row.datums, row.tableDesc
+	if row.deleted {
+		row.datums = make(sqlbase.EncDatumRow, len(b.desc.Columns))
+	} else if row.datums, err = b.project(s.evalCtx, &s.alloc, row.datums); err != nil {
+		return false, err
+	}
+	row.tableDesc = b.desc
+
+	if prev != nil {
+		if row.prevDeleted {
+			row.prevDatums = make(sqlbase.EncDatumRow, len(prev.desc.Columns))
+		} else if row.prevDatums, err = prev.project(s.evalCtx, &s.alloc, row.prevDatums); err != nil {
+			return false, err
+		}
+		row.prevTableDesc = prev.desc
+	}
+	return true, nil
+}
+
+func (s *changefeedSelect) bind(tableDesc *sqlbase.TableDescriptor) (*boundSelect, error) {
+	cacheKey := makeTableIDAndVersion(tableDesc.ID, tableDesc.Version)
+	if b, ok := s.bound[cacheKey]; ok {
+		return b, nil
+	}
+	b, err := bindChangefeedSelect(s.evalCtx, s.clause, tableDesc)
+	if err != nil {
+		return nil, err
+	}
+	// TODO(dan): Bound the size of this cache.
+	s.bound[cacheKey] = b
+	return b, nil
+}
+
+// boundSelect is the SELECT clause of a changefeed, bound to the columns of a
+// version of the watched table.
+type boundSelect struct {
+	// desc describes the projected rows. It has the id, name and version of
+	// the table, but its columns are the projected ones.
+	desc   *sqlbase.TableDescriptor
+	exprs  []tree.TypedExpr
+	where  tree.TypedExpr
+	source selectRowContainer
+}
+
+// bindChangefeedSelect parses the given SELECT clause, then resolves and type
+// checks its expressions against the columns of the given table descriptor.
+func bindChangefeedSelect(
+	evalCtx *tree.EvalContext, clause string, tableDesc *sqlbase.TableDescriptor,
+) (*boundSelect, error) {
+	// The clause is parsed again for every binding, since name resolution
+	// modifies the expressions.
+	stmt, err := parser.ParseOne(clause)
+	if err != nil {
+		return nil, err
+	}
+	sel, ok := stmt.AST.(*tree.Select)
+	if !ok {
+		return nil, errors.Errorf(`expected a SELECT clause: %s`, clause)
+	}
+	sc, ok := sel.Select.(*tree.SelectClause)
+	if !ok {
+		return nil, errors.Errorf(`expected a SELECT clause: %s`, clause)
+	}
+	tn, err := changefeedSelectTarget(sc)
+	if err != nil {
+		return nil, err
+	}
+	if alias := sc.From.Tables[0].(*tree.AliasedTableExpr).As.Alias; alias != `` {
+		aliased := tree.MakeUnqualifiedTableName(alias)
+		tn = &aliased
+	}
+
+	b := &boundSelect{source: selectRowContainer{cols: tableDesc.Columns}}
+	ivarHelper := tree.MakeIndexedVarHelper(&b.source, len(tableDesc.Columns))
+	source := sqlbase.NewSourceInfoForSingleTable(
+		*tn, sqlbase.ResultColumnsFromColDescs(tableDesc.Columns))
+	semaCtx := tree.MakeSemaContext()
+	semaCtx.IVarContainer = &b.source
+	typeCheck := func(expr tree.Expr, context string, desired *types.T) (tree.TypedExpr, error) {
+		expr, _, err := sqlbase.ResolveNames(expr, source, ivarHelper, evalCtx.SessionData.SearchPath)
+		if err != nil {
+			return nil, err
+		}
+		semaCtx.Properties.Require(context, changefeedSelectRejectFlags)
+		return tree.TypeCheck(expr, &semaCtx, desired)
+	}
+
+	desc := *tableDesc
+	desc.Columns = nil
+	addColumn := func(name string, typ *types.T, expr tree.TypedExpr) {
+		desc.Columns = append(desc.Columns, sqlbase.ColumnDescriptor{
+			Name:     name,
+			ID:       sqlbase.ColumnID(len(desc.Columns) + 1),
+			Type:     *typ,
+			Nullable: true,
+		})
+		b.exprs = append(b.exprs, expr)
+	}
+	for _, target := range sc.Exprs {
+		if vn, ok := target.Expr.(tree.VarName); ok {
+			v, err := vn.NormalizeVarName()
+			if err != nil {
+				return nil, err
+			}
+			switch v.(type) {
+			case tree.UnqualifiedStar, *tree.AllColumnsSelector:
+				for i := range tableDesc.Columns {
+					col := &tableDesc.Columns[i]
+					addColumn(col.Name, &col.Type, ivarHelper.IndexedVarWithType(i, &col.Type))
+				}
+				continue
+			}
+		}
+		name, err := tree.GetRenderColName(evalCtx.SessionData.SearchPath, target)
+		if err != nil {
+			return nil, err
+		}
+		expr, err := typeCheck(target.Expr, `CHANGEFEED`, types.Any)
+		if err != nil {
+			return nil, err
+		}
+		addColumn(name, expr.ResolvedType(), expr)
+	}
+	if sc.Where != nil {
+		if b.where, err = typeCheck(sc.Where.Expr, `WHERE`, types.Bool); err != nil {
+			return nil, err
+		}
+		if typ := b.where.ResolvedType(); typ.Family() != types.BoolFamily &&
+			typ.Family() != types.UnknownFamily {
+			return nil, errors.Errorf(`argument of WHERE must be type bool, not type %s`, typ)
+		}
+	}
+	b.desc = &desc
+	return b, nil
+}
+
+// filter returns whether the given row of the table passes the WHERE clause.
+func (b *boundSelect) filter(
+	evalCtx *tree.EvalContext, alloc *sqlbase.DatumAlloc, datums sqlbase.EncDatumRow,
+) (bool, error) {
+	if b.where == nil {
+		return true, nil
+	}
+	if err := b.source.setRow(alloc, datums); err != nil {
+		return false, err
+	}
+	evalCtx.PushIVarContainer(&b.source)
+	defer evalCtx.PopIVarContainer()
+	d, err := b.where.Eval(evalCtx)
+	if err != nil {
+		return false, err
+	}
+	return d == tree.DBoolTrue, nil
+}
+
+// project returns the projection of the given row of the table.
+func (b *boundSelect) project(
+	evalCtx *tree.EvalContext, alloc *sqlbase.DatumAlloc, datums sqlbase.EncDatumRow,
+) (sqlbase.EncDatumRow, error) {
+	if err := b.source.setRow(alloc, datums); err != nil {
+		return nil, err
+	}
+	evalCtx.PushIVarContainer(&b.source)
+	defer evalCtx.PopIVarContainer()
+	projected := make(sqlbase.EncDatumRow, len(b.exprs))
+	for i, expr := range b.exprs {
+		d, err := expr.Eval(evalCtx)
+		if err != nil {
+			return nil, err
+		}
+		projected[i] = sqlbase.DatumToEncDatum(&b.desc.Columns[i].Type, d)
+	}
+	return projected, nil
+}
+
+// selectRowContainer is the tree.IndexedVarContainer for the columns of a row
+// of the watched table.
+type selectRowContainer struct {
+	cols []sqlbase.ColumnDescriptor
+	row  tree.Datums
+}
+
+var _ tree.IndexedVarContainer = &selectRowContainer{}
+
+func (c *selectRowContainer) setRow(alloc *sqlbase.DatumAlloc, datums sqlbase.EncDatumRow) error {
+	c.row = c.row[:0]
+	for i := range datums {
+		if err := datums[i].EnsureDecoded(&c.cols[i].Type, alloc); err != nil {
+			return err
+		}
+		c.row = append(c.row, datums[i].Datum)
+	}
+	return nil
+}
+
+// IndexedVarEval implements the tree.IndexedVarContainer interface.
+func (c *selectRowContainer) IndexedVarEval(idx int, _ *tree.EvalContext) (tree.Datum, error) {
+	return c.row[idx], nil
+}
+
+// IndexedVarResolvedType implements the tree.IndexedVarContainer interface.
+func (c *selectRowContainer) IndexedVarResolvedType(idx int) *types.T {
+	return &c.cols[idx].Type
+}
+
+// IndexedVarNodeFormatter implements the tree.IndexedVarContainer interface.
+func (c *selectRowContainer) IndexedVarNodeFormatter(idx int) tree.NodeFormatter {
+	n := tree.Name(c.cols[idx].Name)
+	return &n
+}
@@ -37,6 +37,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/humanizeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/retry"
 	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/cockroachdb/logtags"
@@ -57,6 +58,10 @@ type Sink interface {
 	// asynchronous delivery on every topic that has been seen by EmitRow. An
 	// error may be returned if a previously enqueued message has failed.
 	EmitResolvedTimestamp(ctx context.Context, encoder Encoder, resolved hlc.Timestamp) error
+	// AddTarget adds a table to the targets whose rows are emitted to the sink.
+	// It is used by changefeeds on a database, as tables are created in the
+	// database.
+	AddTarget(target jobspb.ChangefeedTarget)
 	// Flush blocks until every message enqueued by EmitRow and
 	// EmitResolvedTimestamp has been acknowledged by the sink. If an error is
 	// returned, no guarantees are given about which messages have been
@@ -192,6 +197,74 @@ func getSink(
 				opts, timestampOracle, makeExternalStorageFromURI,
 			)
 		}
+	case isWebhookSink(u):
+		if formatType(opts[optFormat]) != optFormatJSON {
+			return nil, errors.Errorf(`this sink is incompatible with %s=%s`,
+				optFormat, opts[optFormat])
+		}
+		cfg := webhookSinkConfig{
+			maxBatchSize:  defaultWebhookMaxBatchSize,
+			flushInterval: defaultWebhookFlushInterval,
+			retryOpts: retry.Options{
+				InitialBackoff: defaultWebhookRetryBackoff,
+				MaxBackoff:     defaultWebhookRetryMaxBackoff,
+				MaxRetries:     defaultWebhookMaxRetries,
+			},
+		}
+		if maxBatchSize := q.Get(sinkParamMaxBatchSize); maxBatchSize != `` {
+			if cfg.maxBatchSize, err = humanizeutil.ParseBytes(maxBatchSize); err != nil {
+				return nil, pgerror.Wrapf(err, pgcode.Syntax, `parsing %s`, maxBatchSize)
+			}
+		}
+		q.Del(sinkParamMaxBatchSize)
+		if flushInterval := q.Get(sinkParamFlushInterval); flushInterval != `` {
+			if cfg.flushInterval, err = time.ParseDuration(flushInterval); err != nil {
+				return nil, pgerror.Wrapf(err, pgcode.Syntax, `parsing %s`, flushInterval)
+			}
+		}
+		q.Del(sinkParamFlushInterval)
+		if retryBackoff := q.Get(sinkParamRetryBackoff); retryBackoff != `` {
+			if cfg.retryOpts.InitialBackoff, err = time.ParseDuration(retryBackoff); err != nil {
+				return nil, pgerror.Wrapf(err, pgcode.Syntax, `parsing %s`, retryBackoff)
+			}
+		}
+		q.Del(sinkParamRetryBackoff)
+		if retryMaxBackoff := q.Get(sinkParamRetryMaxBackoff); retryMaxBackoff != `` {
+			if cfg.retryOpts.MaxBackoff, err = time.ParseDuration(retryMaxBackoff); err != nil {
+				return nil, pgerror.Wrapf(err, pgcode.Syntax, `parsing %s`, retryMaxBackoff)
+			}
+		}
+		q.Del(sinkParamRetryMaxBackoff)
+		if maxRetries := q.Get(sinkParamMaxRetries); maxRetries != `` {
+			if cfg.retryOpts.MaxRetries, err = strconv.Atoi(maxRetries); err != nil {
+				return nil, pgerror.Wrapf(err, pgcode.Syntax, `parsing %s`, maxRetries)
+			}
+		}
+		q.Del(sinkParamMaxRetries)
+		if caCertHex := q.Get(sinkParamCACert); caCertHex != `` {
+			if cfg.caCert, err = base64.StdEncoding.DecodeString(caCertHex); err != nil {
+				return nil, errors.Errorf(`param %s must be base 64 encoded: %s`, sinkParamCACert, err)
+			}
+		}
+		q.Del(sinkParamCACert)
+		if clientCertHex := q.Get(sinkParamClientCert); clientCertHex != `` {
+			if cfg.clientCert, err = base64.StdEncoding.DecodeString(clientCertHex); err != nil {
+				return nil, errors.Errorf(`param %s must be base 64 encoded: %s`, sinkParamClientCert, err)
+			}
+		}
+		q.Del(sinkParamClientCert)
+		if clientKeyHex := q.Get(sinkParamClientKey); clientKeyHex != `` {
+			if cfg.clientKey, err = base64.StdEncoding.DecodeString(clientKeyHex); err != nil {
+				return nil, errors.Errorf(`param %s must be base 64 encoded: %s`, sinkParamClientKey, err)
+			}
+		}
+		q.Del(sinkParamClientKey)
+		// The endpoint is addressed without the sink's query parameters.
+		endpoint := *u
+		endpoint.RawQuery = ``
+		makeSink = func() (Sink, error) {
+			return makeWebhookSink(cfg, &endpoint, targets)
+		}
 	case u.Scheme == sinkSchemeExperimentalSQL:
 		// Swap the changefeed prefix for the sql connection one that sqlSink
 		// expects.
@@ -248,6 +321,10 @@ func (s errorWrapperSink) EmitResolvedTimestamp(
 	return nil
 }
 
+func (s errorWrapperSink) AddTarget(target jobspb.ChangefeedTarget) {
+	s.wrapped.AddTarget(target)
+}
+
 func (s errorWrapperSink) Flush(ctx context.Context) error {
 	if err := s.wrapped.Flush(ctx); err != nil {
 		return MarkRetryableError(err)
@@ -519,6 +596,11 @@ func (s *kafkaSink) EmitResolvedTimestamp(
 	return nil
 }
 
+// AddTarget implements the Sink interface.
+func (s *kafkaSink) AddTarget(target jobspb.ChangefeedTarget) {
+	s.topics[s.cfg.kafkaTopicPrefix+SQLNameToKafkaName(target.StatementTimeName)] = struct{}{}
+}
+
 // Flush implements the Sink interface.
 func (s *kafkaSink) Flush(ctx context.Context) error {
 	flushCh := make(chan struct{}, 1)
@@ -740,6 +822,11 @@ func (s *sqlSink) emit(
 	return nil
 }
 
+// AddTarget implements the Sink interface.
+func (s *sqlSink) AddTarget(target jobspb.ChangefeedTarget) {
+	s.topics[target.StatementTimeName] = struct{}{}
+}
+
 // Flush implements the Sink interface.
 func (s *sqlSink) Flush(ctx context.Context) error {
 	if len(s.rowBuf) == 0 {
@@ -836,6 +923,9 @@ func (s *bufferSink) EmitResolvedTimestamp(
 	return nil
 }
 
+// AddTarget implements the Sink interface.
+func (s *bufferSink) AddTarget(jobspb.ChangefeedTarget) {}
+
 // Flush implements the Sink interface.
 func (s *bufferSink) Flush(_ context.Context) error {
 	return nil
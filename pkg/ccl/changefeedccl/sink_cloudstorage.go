@@ -17,6 +17,7 @@ import (
 	"path/filepath"
 	"sync/atomic"
 
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
@@ -49,7 +50,10 @@ func cloudStorageFormatTime(ts hlc.Timestamp) string {
 
 type cloudStorageSinkFile struct {
 	cloudStorageSinkKey
-	buf bytes.Buffer
+	// header, if non-nil, is written at the start of the file.
+	header  []byte
+	numRows int
+	buf     bytes.Buffer
 }
 
 // cloudStorageSink writes changefeed output to files in a cloud storage bucket
@@ -145,9 +149,12 @@ type cloudStorageSinkFile struct {
 // by a given `<sink_id>` and <session_id> is a unique identifying string for the job
 // session running the `changeAggregator` that owns this sink.
 //
-// `<ext>` implies the format of the file: currently the only option is
-// `ndjson`, which means a text file conforming to the "Newline Delimited JSON"
-// spec.
+// `<ext>` implies the format of the file: `ndjson`, which means a text file
+// conforming to the "Newline Delimited JSON" spec, `avro`, which means an Avro
+// object container file with the schema of its records in its header, `csv`,
+// which means a CSV file with a header record of the column names, or `pb`,
+// which means length-delimited protobuf messages, the first of which describes
+// the rest.
 //
 // This naming convention of data files is carefully chosen in order to preserve
 // the external ordering guarantees of CDC. Naming output files in this fashion
@@ -257,6 +264,9 @@ type cloudStorageSink struct {
 
 	ext           string
 	recordDelimFn func(io.Writer) error
+	// fileEncoder, if non-nil, writes the header and frames the records of
+	// each file, for the formats which need it.
+	fileEncoder fileEncoder
 
 	es cloud.ExternalStorage
 
@@ -316,6 +326,18 @@ func makeCloudStorageSink(
 			_, err := w.Write([]byte{'\n'})
 			return err
 		}
+	case optFormatAvroOCF:
+		s.ext = `.avro`
+		s.recordDelimFn = func(io.Writer) error { return nil }
+	case optFormatCSV:
+		s.ext = `.csv`
+		s.recordDelimFn = func(w io.Writer) error {
+			_, err := w.Write([]byte{'\n'})
+			return err
+		}
+	case optFormatProtobuf:
+		s.ext = `.pb`
+		s.recordDelimFn = func(io.Writer) error { return nil }
 	default:
 		return nil, errors.Errorf(`this sink is incompatible with %s=%s`,
 			optFormat, opts[optFormat])
@@ -364,6 +386,13 @@ func (s *cloudStorageSink) EmitRow(
 	}
 
 	file := s.getOrCreateFile(table.Name, table.Version)
+	if s.fileEncoder != nil && file.header == nil {
+		var err error
+		if file.header, err = s.fileEncoder.FileHeader(table); err != nil {
+			return err
+		}
+	}
+	file.numRows++
 
 	// TODO(dan): Memory monitoring for this
 	if _, err := file.buf.Write(value); err != nil {
@@ -440,6 +469,10 @@ func (s *cloudStorageSink) flushTopicVersions(
 	return err
 }
 
+// AddTarget implements the Sink interface. Files are written for any table
+// whose rows are emitted.
+func (s *cloudStorageSink) AddTarget(jobspb.ChangefeedTarget) {}
+
 // Flush implements the Sink interface.
 func (s *cloudStorageSink) Flush(ctx context.Context) error {
 	if s.files == nil {
@@ -487,7 +520,16 @@ func (s *cloudStorageSink) flushFile(ctx context.Context, file *cloudStorageSink
 			"precedes a file emitted before: %s", filename, s.prevFilename)
 	}
 	s.prevFilename = filename
-	return s.es.WriteFile(ctx, filepath.Join(s.dataFilePartition, filename), bytes.NewReader(file.buf.Bytes()))
+	contents := file.buf.Bytes()
+	if s.fileEncoder != nil {
+		var buf bytes.Buffer
+		buf.Write(file.header)
+		if err := s.fileEncoder.WriteFileBlock(&buf, file.numRows, contents); err != nil {
+			return err
+		}
+		contents = buf.Bytes()
+	}
+	return s.es.WriteFile(ctx, filepath.Join(s.dataFilePartition, filename), bytes.NewReader(contents))
 }
 
 // Close implements the Sink interface.
@@ -9,6 +9,7 @@
 package changefeedccl
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"io/ioutil"
@@ -21,11 +22,15 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/blobs"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/pkg/storage/cloud"
 	"github.com/cockroachdb/cockroach/pkg/testutils"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/gogo/protobuf/proto"
+	"github.com/gogo/protobuf/protoc-gen-gogo/descriptor"
+	"github.com/linkedin/goavro"
 	"github.com/stretchr/testify/require"
 )
 
@@ -493,4 +498,95 @@ func TestCloudStorageSink(t *testing.T) {
 			"w1\n",
 		}, slurpDir(t, dir))
 	})
+
+	t.Run(`file-formats`, func(t *testing.T) {
+		tableDesc, err := parseTableDesc(`CREATE TABLE foo (a INT PRIMARY KEY, b STRING)`)
+		require.NoError(t, err)
+		row := encodeRow{
+			datums: sqlbase.EncDatumRow{
+				sqlbase.EncDatum{Datum: tree.NewDInt(1)},
+				sqlbase.EncDatum{Datum: tree.NewDString(`bar`)},
+			},
+			tableDesc: tableDesc,
+		}
+
+		// writeFile writes the row with the given format, and returns the name
+		// and contents of the file written.
+		writeFile := func(t *testing.T, format formatType) (string, []byte) {
+			formatOpts := map[string]string{
+				optFormat:     string(format),
+				optEnvelope:   string(optEnvelopeWrapped),
+				optKeyInValue: ``,
+			}
+			e, err := getEncoder(formatOpts)
+			require.NoError(t, err)
+			sinkDir := `file-formats-` + string(format)
+			sf := makeSpanFrontier(roachpb.Span{Key: []byte("a"), EndKey: []byte("b")})
+			s, err := makeCloudStorageSink(
+				`nodelocal:///`+sinkDir, 1, unlimitedFileSize, settings, formatOpts,
+				&changeAggregatorLowerBoundOracle{sf: sf}, externalStorageFromURI,
+			)
+			require.NoError(t, err)
+			s.(*cloudStorageSink).fileEncoder = e.(fileEncoder)
+
+			value, err := e.EncodeValue(ctx, row)
+			require.NoError(t, err)
+			require.NoError(t, s.EmitRow(ctx, tableDesc, noKey, value, ts(1)))
+			require.NoError(t, s.Flush(ctx))
+
+			var names []string
+			require.NoError(t, filepath.Walk(filepath.Join(dir, sinkDir),
+				func(path string, info os.FileInfo, err error) error {
+					if err == nil && !info.IsDir() {
+						names = append(names, path)
+					}
+					return err
+				}))
+			require.Len(t, names, 1)
+			contents, err := ioutil.ReadFile(names[0])
+			require.NoError(t, err)
+			return filepath.Base(names[0]), contents
+		}
+
+		t.Run(`csv`, func(t *testing.T) {
+			name, contents := writeFile(t, optFormatCSV)
+			require.Equal(t, `.csv`, filepath.Ext(name))
+			require.Equal(t, "a,b,__crdb__deleted\n1,bar,false\n", string(contents))
+		})
+
+		t.Run(`avro_ocf`, func(t *testing.T) {
+			name, contents := writeFile(t, optFormatAvroOCF)
+			require.Equal(t, `.avro`, filepath.Ext(name))
+			require.True(t, bytes.HasPrefix(contents, avroOCFMagic))
+			r, err := goavro.NewOCFReader(bytes.NewReader(contents))
+			require.NoError(t, err)
+			var numRecords int
+			for r.Scan() {
+				_, err := r.Read()
+				require.NoError(t, err)
+				numRecords++
+			}
+			require.NoError(t, r.Err())
+			require.Equal(t, 1, numRecords)
+		})
+
+		t.Run(`protobuf`, func(t *testing.T) {
+			name, contents := writeFile(t, optFormatProtobuf)
+			require.Equal(t, `.pb`, filepath.Ext(name))
+			b := proto.NewBuffer(contents)
+			header, err := b.DecodeRawBytes(false /* alloc */)
+			require.NoError(t, err)
+			var set descriptor.FileDescriptorSet
+			require.NoError(t, proto.Unmarshal(header, &set))
+			require.Len(t, set.File, 1)
+			var messages []string
+			for _, msg := range set.File[0].MessageType {
+				messages = append(messages, msg.GetName())
+			}
+			require.Equal(t, []string{`foo_envelope`, `foo_key`, `foo`}, messages)
+			value, err := b.DecodeRawBytes(false /* alloc */)
+			require.NoError(t, err)
+			require.NotEmpty(t, value)
+		})
+	})
 }
@@ -0,0 +1,369 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package changefeedccl
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/httputil"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/retry"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/pkg/errors"
+)
+
+func isWebhookSink(u *url.URL) bool {
+	return u.Scheme == sinkSchemeWebhookHTTPS
+}
+
+const (
+	defaultWebhookMaxBatchSize    = 1 << 20 // 1MiB
+	defaultWebhookFlushInterval   = time.Second
+	defaultWebhookRequestTimeout  = 30 * time.Second
+	defaultWebhookRetryBackoff    = 500 * time.Millisecond
+	defaultWebhookRetryMaxBackoff = 30 * time.Second
+	defaultWebhookMaxRetries      = 8
+)
+
+type webhookSinkConfig struct {
+	// maxBatchSize is the size in bytes of the buffered messages which triggers
+	// a request to the endpoint.
+	maxBatchSize int64
+	// flushInterval is the longest a message is buffered before a request to
+	// the endpoint is triggered. Zero disables time-based requests.
+	flushInterval time.Duration
+	caCert        []byte
+	clientCert    []byte
+	clientKey     []byte
+	// retryOpts is the backoff between the attempts of a request, and the
+	// number of retries after which the changefeed fails.
+	retryOpts retry.Options
+}
+
+// webhookMessage is the JSON representation of a row emitted to a webhook
+// endpoint. The key and value are the JSON encoded by the changefeed's encoder.
+type webhookMessage struct {
+	Topic string          `json:"topic"`
+	Key   json.RawMessage `json:"key"`
+	Value json.RawMessage `json:"value"`
+}
+
+// webhookSink emits to an HTTPS endpoint, by POSTing batches of rows as JSON
+// bodies of the form:
+//
+//	{"payload": [{"topic": ..., "key": ..., "value": ...}, ...], "length": n}
+//
+// and resolved timestamps as the bodies produced by the encoder, e.g.
+// `{"resolved": "1234.0000000000"}`. Any 2xx status acknowledges a request.
+// Other statuses and connection errors are retried with exponential backoff,
+// up to a limit after which the changefeed restarts from its last checkpoint.
+// The backoff and the limit are set by the retry_backoff, retry_max_backoff
+// and max_retries sink parameters.
+//
+// The semantics are those of the other sinks: delivery is at-least-once, as a
+// request which seemed to fail may still have been processed, and rows may be
+// sent again after a restart. Requests are sent one at a time, in the order in
+// which the rows were emitted, so that rows for a given key are delivered in
+// order (but may be followed by duplicates of earlier rows). A resolved
+// timestamp is only sent once every row emitted before it was acknowledged.
+//
+// Buffered rows are sent once flushInterval has passed even if nothing else is
+// emitted, by a worker goroutine. A failure of such a request is returned by
+// the next call to the sink. Rows emitted while a request is retried are
+// buffered; EmitRow only waits for it once the buffer is full.
+//
+// It is not concurrency-safe; all calls to Emit and Flush should be from the
+// same goroutine.
+type webhookSink struct {
+	cfg    webhookSinkConfig
+	url    string
+	client *httputil.Client
+	topics map[string]struct{}
+
+	stopWorker context.CancelFunc
+	worker     sync.WaitGroup
+
+	// Only synchronized between the client goroutine and the worker goroutine.
+	// It is released while a request is sent, so that rows can be buffered
+	// while the request is retried.
+	mu struct {
+		syncutil.Mutex
+		// buf holds the messages waiting to be sent, since firstBuffered.
+		buf           []webhookMessage
+		bufSize       int64
+		firstBuffered time.Time
+		// flushErr is the error of the last request sent by the worker.
+		flushErr error
+		// sending is set while a request is in flight. Only one request is
+		// sent at a time, so that they are sent in order; sendDone is
+		// signaled when it is cleared.
+		sending  bool
+		sendDone *sync.Cond
+	}
+}
+
+func makeWebhookSink(
+	cfg webhookSinkConfig, u *url.URL, targets jobspb.ChangefeedTargets,
+) (Sink, error) {
+	sink := &webhookSink{cfg: cfg}
+	sink.mu.sendDone = sync.NewCond(&sink.mu.Mutex)
+	sink.topics = make(map[string]struct{})
+	for _, t := range targets {
+		sink.topics[t.StatementTimeName] = struct{}{}
+	}
+
+	endpoint := *u
+	endpoint.Scheme = strings.TrimPrefix(endpoint.Scheme, `webhook-`)
+	sink.url = endpoint.String()
+
+	tlsConfig := &tls.Config{}
+	if cfg.caCert != nil {
+		caCertPool := x509.NewCertPool()
+		if !caCertPool.AppendCertsFromPEM(cfg.caCert) {
+			return nil, errors.Errorf(`invalid %s: no PEM certificate found`, sinkParamCACert)
+		}
+		tlsConfig.RootCAs = caCertPool
+	}
+	if cfg.clientCert != nil {
+		if cfg.clientKey == nil {
+			return nil, errors.Errorf(`%s requires %s to be set`, sinkParamClientCert, sinkParamClientKey)
+		}
+		cert, err := tls.X509KeyPair(cfg.clientCert, cfg.clientKey)
+		if err != nil {
+			return nil, errors.Errorf(`invalid client certificate data provided: %s`, err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	} else if cfg.clientKey != nil {
+		return nil, errors.Errorf(`%s requires %s to be set`, sinkParamClientKey, sinkParamClientCert)
+	}
+	sink.client = httputil.NewClientWithTimeout(defaultWebhookRequestTimeout)
+	sink.client.Transport.(*http.Transport).TLSClientConfig = tlsConfig
+	sink.start()
+	return sink, nil
+}
+
+func (s *webhookSink) start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.stopWorker = cancel
+	if s.cfg.flushInterval > 0 {
+		s.worker.Add(1)
+		go s.workerLoop(ctx)
+	}
+}
+
+// workerLoop sends the buffered messages once they have been buffered for
+// flushInterval.
+func (s *webhookSink) workerLoop(ctx context.Context) {
+	defer s.worker.Done()
+
+	timer := timeutil.NewTimer()
+	defer timer.Stop()
+	timer.Reset(s.cfg.flushInterval)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+			timer.Read = true
+		}
+
+		next := s.cfg.flushInterval
+		s.mu.Lock()
+		if len(s.mu.buf) > 0 && s.mu.flushErr == nil {
+			if buffered := timeutil.Since(s.mu.firstBuffered); buffered < s.cfg.flushInterval {
+				next = s.cfg.flushInterval - buffered
+			} else {
+				s.mu.flushErr = s.flushLocked(ctx)
+			}
+		}
+		s.mu.Unlock()
+		timer.Reset(next)
+	}
+}
+
+// takeFlushErrLocked returns and clears the error of the last request sent by
+// the worker.
+func (s *webhookSink) takeFlushErrLocked() error {
+	err := s.mu.flushErr
+	s.mu.flushErr = nil
+	return err
+}
+
+// EmitRow implements the Sink interface.
+func (s *webhookSink) EmitRow(
+	ctx context.Context, table *sqlbase.TableDescriptor, key, value []byte, _ hlc.Timestamp,
+) error {
+	topic := table.Name
+	if _, ok := s.topics[topic]; !ok {
+		return errors.Errorf(`cannot emit to undeclared topic: %s`, topic)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.takeFlushErrLocked(); err != nil {
+		return err
+	}
+	if len(s.mu.buf) == 0 {
+		s.mu.firstBuffered = timeutil.Now()
+	}
+	// The encoder may reuse the key and value buffers.
+	msg := webhookMessage{
+		Topic: topic,
+		Key:   append(json.RawMessage(nil), key...),
+		Value: append(json.RawMessage(nil), value...),
+	}
+	s.mu.buf = append(s.mu.buf, msg)
+	s.mu.bufSize += int64(len(key) + len(value))
+
+	if s.mu.bufSize >= s.cfg.maxBatchSize ||
+		(s.cfg.flushInterval > 0 && timeutil.Since(s.mu.firstBuffered) >= s.cfg.flushInterval) {
+		return s.flushLocked(ctx)
+	}
+	return nil
+}
+
+// EmitResolvedTimestamp implements the Sink interface.
+func (s *webhookSink) EmitResolvedTimestamp(
+	ctx context.Context, encoder Encoder, resolved hlc.Timestamp,
+) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// Wait for a request of the worker to finish, so that its error is seen.
+	s.waitForSendLocked()
+	if err := s.takeFlushErrLocked(); err != nil {
+		return err
+	}
+	// The resolved timestamp promises that every earlier row was delivered.
+	if err := s.flushLocked(ctx); err != nil {
+		return err
+	}
+	const noTopic = ``
+	payload, err := encoder.EncodeResolvedTimestamp(ctx, noTopic, resolved)
+	if err != nil {
+		return err
+	}
+	return s.sendLocked(ctx, payload)
+}
+
+// AddTarget implements the Sink interface.
+func (s *webhookSink) AddTarget(target jobspb.ChangefeedTarget) {
+	s.topics[target.StatementTimeName] = struct{}{}
+}
+
+// Flush implements the Sink interface.
+func (s *webhookSink) Flush(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.waitForSendLocked()
+	if err := s.takeFlushErrLocked(); err != nil {
+		return err
+	}
+	return s.flushLocked(ctx)
+}
+
+// flushLocked sends the buffered messages. s.mu is released while the request
+// is sent, so rows emitted meanwhile are buffered for the next request. The
+// messages are put back in front of the buffer if the request fails.
+func (s *webhookSink) flushLocked(ctx context.Context) error {
+	// Take the messages only once the request in flight is done, so that
+	// requests are sent in the order the messages were buffered.
+	s.waitForSendLocked()
+	if len(s.mu.buf) == 0 {
+		return nil
+	}
+	buf, bufSize, firstBuffered := s.mu.buf, s.mu.bufSize, s.mu.firstBuffered
+	body, err := json.Marshal(struct {
+		Payload []webhookMessage `json:"payload"`
+		Length  int              `json:"length"`
+	}{Payload: buf, Length: len(buf)})
+	if err != nil {
+		return err
+	}
+	s.mu.buf, s.mu.bufSize = nil, 0
+	if err := s.sendLocked(ctx, body); err != nil {
+		s.mu.buf = append(buf, s.mu.buf...)
+		s.mu.bufSize += bufSize
+		s.mu.firstBuffered = firstBuffered
+		return err
+	}
+	return nil
+}
+
+// waitForSendLocked waits until no request is in flight.
+func (s *webhookSink) waitForSendLocked() {
+	for s.mu.sending {
+		s.mu.sendDone.Wait()
+	}
+}
+
+// sendLocked sends the body once the request in flight, if any, is done. s.mu
+// is released while the request is sent and retried.
+func (s *webhookSink) sendLocked(ctx context.Context, body []byte) error {
+	s.waitForSendLocked()
+	s.mu.sending = true
+	s.mu.Unlock()
+	err := s.send(ctx, body)
+	s.mu.Lock()
+	s.mu.sending = false
+	s.mu.sendDone.Broadcast()
+	return err
+}
+
+// send POSTs the body to the endpoint, retrying until it is acknowledged or
+// the retries are exhausted.
+func (s *webhookSink) send(ctx context.Context, body []byte) error {
+	var err error
+	for r := retry.StartWithCtx(ctx, s.cfg.retryOpts); r.Next(); {
+		if err = s.sendOnce(ctx, body); err == nil {
+			return nil
+		}
+		log.VEventf(ctx, 2, "webhook request failed: %s", err)
+	}
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return ctxErr
+	}
+	return errors.Wrapf(err, `sending to webhook sink`)
+}
+
+func (s *webhookSink) sendOnce(ctx context.Context, body []byte) error {
+	resp, err := s.client.Post(ctx, s.url, `application/json`, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1<<10))
+		return errors.Errorf(`%s: %s`, resp.Status, msg)
+	}
+	_, err = io.Copy(ioutil.Discard, resp.Body)
+	return err
+}
+
+// Close implements the Sink interface.
+func (s *webhookSink) Close() error {
+	s.stopWorker()
+	s.worker.Wait()
+	s.client.CloseIdleConnections()
+	return nil
+}
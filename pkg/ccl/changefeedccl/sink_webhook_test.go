@@ -0,0 +1,241 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package changefeedccl
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookSink(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	ctx := context.Background()
+
+	var mu struct {
+		syncutil.Mutex
+		bodies   []string
+		failures int
+		// block, if set, delays the responses until it is closed.
+		block chan struct{}
+	}
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		block := mu.block
+		mu.Unlock()
+		if block != nil {
+			<-block
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		if mu.failures > 0 {
+			mu.failures--
+			http.Error(w, `unavailable`, http.StatusServiceUnavailable)
+			return
+		}
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Error(err)
+		}
+		if r.URL.Path != `/feed` || r.Header.Get(`Content-Type`) != `application/json` {
+			t.Errorf(`unexpected request: %s %s`, r.URL, r.Header)
+		}
+		mu.bodies = append(mu.bodies, string(body))
+	}))
+	defer srv.Close()
+	bodies := func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		ret := mu.bodies
+		mu.bodies = nil
+		return ret
+	}
+
+	caCert := pem.EncodeToMemory(&pem.Block{Type: `CERTIFICATE`, Bytes: srv.Certificate().Raw})
+	srvURL, err := url.Parse(srv.URL)
+	require.NoError(t, err)
+	sinkURI := func(params string) string {
+		return fmt.Sprintf(`webhook-https://%s/feed?%s=%s&%s`, srvURL.Host,
+			sinkParamCACert, url.QueryEscape(base64.StdEncoding.EncodeToString(caCert)), params)
+	}
+
+	opts := map[string]string{
+		optFormat:   string(optFormatJSON),
+		optEnvelope: string(optEnvelopeWrapped),
+	}
+	targets := jobspb.ChangefeedTargets{0: {StatementTimeName: `t1`}}
+	st := cluster.MakeTestingClusterSettings()
+	makeSink := func(params string) *webhookSink {
+		s, err := getSink(sinkURI(sinkParamRetryBackoff+`=1ms&`+params), 1 /* nodeID */, opts, targets, st,
+			nil /* timestampOracle */, nil /* makeExternalStorageFromURI */)
+		require.NoError(t, err)
+		return s.(*webhookSink)
+	}
+	t1 := &sqlbase.TableDescriptor{Name: `t1`}
+	e, err := makeJSONEncoder(opts)
+	require.NoError(t, err)
+
+	t.Run(`batching`, func(t *testing.T) {
+		s := makeSink(sinkParamMaxBatchSize + `=25B&` + sinkParamFlushInterval + `=0s`)
+		defer func() { require.NoError(t, s.Close()) }()
+
+		require.NoError(t, s.EmitRow(ctx, t1, []byte(`[1]`), []byte(`{"a":1}`), hlc.Timestamp{}))
+		require.Empty(t, bodies())
+		require.NoError(t, s.EmitRow(ctx, t1, []byte(`[2]`), []byte(`{"a":2}`), hlc.Timestamp{}))
+		require.NoError(t, s.EmitRow(ctx, t1, []byte(`[3]`), []byte(`{"a":3}`), hlc.Timestamp{}))
+		require.Equal(t, []string{
+			`{"payload":[{"topic":"t1","key":[1],"value":{"a":1}},` +
+				`{"topic":"t1","key":[2],"value":{"a":2}},` +
+				`{"topic":"t1","key":[3],"value":{"a":3}}],"length":3}`,
+		}, bodies())
+
+		// Resolved timestamps are only sent after the rows emitted before them.
+		require.NoError(t, s.EmitRow(ctx, t1, []byte(`[4]`), nil, hlc.Timestamp{}))
+		require.NoError(t, s.EmitResolvedTimestamp(ctx, e, hlc.Timestamp{WallTime: 5}))
+		require.Equal(t, []string{
+			`{"payload":[{"topic":"t1","key":[4],"value":null}],"length":1}`,
+			`{"resolved":"5.0000000000"}`,
+		}, bodies())
+
+		require.NoError(t, s.Flush(ctx))
+		require.Empty(t, bodies())
+		require.EqualError(t, s.EmitRow(ctx, &sqlbase.TableDescriptor{Name: `t2`}, nil, nil, hlc.Timestamp{}),
+			`cannot emit to undeclared topic: t2`)
+	})
+
+	t.Run(`interval`, func(t *testing.T) {
+		s := makeSink(sinkParamFlushInterval + `=10ms&` + sinkParamMaxRetries + `=1`)
+		defer func() { require.NoError(t, s.Close()) }()
+
+		// A single row is sent once the flush interval passes, even though
+		// nothing else is emitted.
+		require.NoError(t, s.EmitRow(ctx, t1, []byte(`[1]`), []byte(`{"a":1}`), hlc.Timestamp{}))
+		var sent []string
+		testutils.SucceedsSoon(t, func() error {
+			if sent = append(sent, bodies()...); len(sent) == 0 {
+				return errors.New(`row not sent yet`)
+			}
+			return nil
+		})
+		require.Equal(t, []string{
+			`{"payload":[{"topic":"t1","key":[1],"value":{"a":1}}],"length":1}`,
+		}, sent)
+
+		// A failure of the worker's request is returned by the next call, and
+		// the row is sent again by the next flush.
+		mu.Lock()
+		mu.failures = 2
+		mu.Unlock()
+		require.NoError(t, s.EmitRow(ctx, t1, []byte(`[2]`), []byte(`{"a":2}`), hlc.Timestamp{}))
+		testutils.SucceedsSoon(t, func() error {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			if s.mu.flushErr == nil {
+				return errors.New(`request not failed yet`)
+			}
+			return nil
+		})
+		require.Error(t, s.Flush(ctx))
+		require.NoError(t, s.Flush(ctx))
+		require.Equal(t, []string{
+			`{"payload":[{"topic":"t1","key":[2],"value":{"a":2}}],"length":1}`,
+		}, bodies())
+	})
+
+	t.Run(`retries`, func(t *testing.T) {
+		s := makeSink(sinkParamFlushInterval + `=0s`)
+		defer func() { require.NoError(t, s.Close()) }()
+
+		mu.Lock()
+		mu.failures = 2
+		mu.Unlock()
+		require.NoError(t, s.EmitRow(ctx, t1, []byte(`[1]`), []byte(`{"a":1}`), hlc.Timestamp{}))
+		require.NoError(t, s.Flush(ctx))
+		require.Equal(t, []string{
+			`{"payload":[{"topic":"t1","key":[1],"value":{"a":1}}],"length":1}`,
+		}, bodies())
+
+		// Once the retries are exhausted, the rows are kept for the next flush.
+		s2 := makeSink(sinkParamFlushInterval + `=0s&` + sinkParamMaxRetries + `=1`)
+		defer func() { require.NoError(t, s2.Close()) }()
+		mu.Lock()
+		mu.failures = 2
+		mu.Unlock()
+		require.NoError(t, s2.EmitRow(ctx, t1, []byte(`[2]`), []byte(`{"a":2}`), hlc.Timestamp{}))
+		require.Error(t, s2.Flush(ctx))
+		require.NoError(t, s2.Flush(ctx))
+		require.Equal(t, []string{
+			`{"payload":[{"topic":"t1","key":[2],"value":{"a":2}}],"length":1}`,
+		}, bodies())
+	})
+
+	t.Run(`in flight`, func(t *testing.T) {
+		s := makeSink(sinkParamFlushInterval + `=10ms`)
+		defer func() { require.NoError(t, s.Close()) }()
+
+		block := make(chan struct{})
+		mu.Lock()
+		mu.block = block
+		mu.Unlock()
+		require.NoError(t, s.EmitRow(ctx, t1, []byte(`[1]`), []byte(`{"a":1}`), hlc.Timestamp{}))
+		testutils.SucceedsSoon(t, func() error {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			if !s.mu.sending {
+				return errors.New(`request not sent yet`)
+			}
+			return nil
+		})
+
+		// Rows are buffered while the worker's request is in flight, and sent
+		// after it.
+		require.NoError(t, s.EmitRow(ctx, t1, []byte(`[2]`), []byte(`{"a":2}`), hlc.Timestamp{}))
+		mu.Lock()
+		mu.block = nil
+		mu.Unlock()
+		close(block)
+		require.NoError(t, s.Flush(ctx))
+		require.Equal(t, []string{
+			`{"payload":[{"topic":"t1","key":[1],"value":{"a":1}}],"length":1}`,
+			`{"payload":[{"topic":"t1","key":[2],"value":{"a":2}}],"length":1}`,
+		}, bodies())
+	})
+
+	t.Run(`params`, func(t *testing.T) {
+		_, err := getSink(sinkURI(`foo=bar`), 1, opts, targets, st, nil, nil)
+		require.EqualError(t, err, `unknown sink query parameter: foo`)
+		_, err = getSink(sinkURI(sinkParamMaxBatchSize+`=foo`), 1, opts, targets, st, nil, nil)
+		require.Error(t, err)
+		_, err = getSink(sinkURI(sinkParamMaxRetries+`=foo`), 1, opts, targets, st, nil, nil)
+		require.Error(t, err)
+		_, err = getSink(sinkURI(sinkParamRetryBackoff+`=foo`), 1, opts, targets, st, nil, nil)
+		require.Error(t, err)
+		_, err = getSink(sinkURI(sinkParamClientKey+`=Zm9v`), 1, opts, targets, st, nil, nil)
+		require.EqualError(t, err, `client_key requires client_cert to be set`)
+		avroOpts := map[string]string{optFormat: string(optFormatAvro)}
+		_, err = getSink(sinkURI(``), 1, avroOpts, targets, st, nil, nil)
+		require.EqualError(t, err, `this sink is incompatible with format=experimental_avro`)
+	})
+}
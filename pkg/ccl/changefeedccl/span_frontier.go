@@ -122,6 +122,52 @@ func makeSpanFrontier(spans ...roachpb.Span) *spanFrontier {
 	return s
 }
 
+// AddSpansAt adds the given spans to the tracked span set, at the given
+// timestamp. The spans must not overlap the tracked span set.
+func (s *spanFrontier) AddSpansAt(ts hlc.Timestamp, spans ...roachpb.Span) {
+	for _, span := range spans {
+		e := &spanFrontierEntry{
+			id:   s.idAlloc,
+			keys: span.AsRange(),
+			span: span,
+			ts:   ts,
+		}
+		s.idAlloc++
+		if err := s.tree.Insert(e, true /* fast */); err != nil {
+			panic(err)
+		}
+		heap.Push(&s.minHeap, e)
+	}
+	s.tree.AdjustRanges()
+}
+
+// RemoveSpans removes the given spans from the tracked span set. Any part of
+// the tracked span set that doesn't overlap the spans is left alone. True is
+// returned if the frontier advanced as a result.
+func (s *spanFrontier) RemoveSpans(spans ...roachpb.Span) bool {
+	prevFrontier := s.Frontier()
+	for _, span := range spans {
+		for _, o := range s.tree.Get(span.AsRange()) {
+			spe := o.(*spanFrontierEntry)
+			if err := s.tree.Delete(spe, true /* fast */); err != nil {
+				panic(err)
+			}
+			heap.Remove(&s.minHeap, spe.index)
+			// Keep the parts of the entry outside of the removed span.
+			var remaining []roachpb.Span
+			if spe.span.Key.Compare(span.Key) < 0 {
+				remaining = append(remaining, roachpb.Span{Key: spe.span.Key, EndKey: span.Key})
+			}
+			if span.EndKey.Compare(spe.span.EndKey) < 0 {
+				remaining = append(remaining, roachpb.Span{Key: span.EndKey, EndKey: spe.span.EndKey})
+			}
+			s.AddSpansAt(spe.ts, remaining...)
+		}
+	}
+	s.tree.AdjustRanges()
+	return prevFrontier.Less(s.Frontier())
+}
+
 // Frontier returns the minimum timestamp being tracked.
 func (s *spanFrontier) Frontier() hlc.Timestamp {
 	if s.minHeap.Len() == 0 {
@@ -183,6 +183,46 @@ func TestSpanFrontierDisjointSpans(t *testing.T) {
 	require.Equal(t, `{a-b}@3 {c-d}@3 {d-e}@2`, f.entriesStr())
 }
 
+func TestSpanFrontierAddRemoveSpans(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	keyA, keyB, keyC := roachpb.Key("a"), roachpb.Key("b"), roachpb.Key("c")
+	keyD, keyE, keyF := roachpb.Key("d"), roachpb.Key("e"), roachpb.Key("f")
+	spAB := roachpb.Span{Key: keyA, EndKey: keyB}
+	spBD := roachpb.Span{Key: keyB, EndKey: keyD}
+	spCE := roachpb.Span{Key: keyC, EndKey: keyE}
+	spEF := roachpb.Span{Key: keyE, EndKey: keyF}
+
+	f := makeSpanFrontier(spAB)
+	f.Forward(spAB, hlc.Timestamp{WallTime: 2})
+	require.Equal(t, hlc.Timestamp{WallTime: 2}, f.Frontier())
+
+	// Added spans start at the given timestamp.
+	f.AddSpansAt(hlc.Timestamp{WallTime: 3}, spCE, spEF)
+	require.Equal(t, hlc.Timestamp{WallTime: 2}, f.Frontier())
+	require.Equal(t, `{a-b}@2 {c-e}@3 {e-f}@3`, f.entriesStr())
+	adv := f.Forward(spCE, hlc.Timestamp{WallTime: 4})
+	require.Equal(t, false, adv)
+	require.Equal(t, `{a-b}@2 {c-e}@4 {e-f}@3`, f.entriesStr())
+
+	// Removing the span at the frontier advances it.
+	adv = f.RemoveSpans(spAB)
+	require.Equal(t, true, adv)
+	require.Equal(t, hlc.Timestamp{WallTime: 3}, f.Frontier())
+	require.Equal(t, `{c-e}@4 {e-f}@3`, f.entriesStr())
+
+	// Untracked parts of the removed spans are ignored, and the tracked parts
+	// outside of them are kept.
+	adv = f.RemoveSpans(spBD)
+	require.Equal(t, false, adv)
+	require.Equal(t, hlc.Timestamp{WallTime: 3}, f.Frontier())
+	require.Equal(t, `{d-e}@4 {e-f}@3`, f.entriesStr())
+
+	adv = f.RemoveSpans(spEF)
+	require.Equal(t, true, adv)
+	require.Equal(t, hlc.Timestamp{WallTime: 4}, f.Frontier())
+	require.Equal(t, `{d-e}@4`, f.entriesStr())
+}
+
 func TestSpanFrontierHeap(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 
@@ -224,3 +264,25 @@ func TestSpanFrontierHeap(t *testing.T) {
 	require.Equal(t, eBC1, heap.Pop(&sfh))
 	require.Equal(t, eAB2, heap.Pop(&sfh))
 }
+
+func TestScanFraction(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	keyA, keyB := roachpb.Key("a"), roachpb.Key("b")
+	keyC, keyD := roachpb.Key("c"), roachpb.Key("d")
+	scanTS := hlc.Timestamp{WallTime: 5}
+
+	f := makeSpanFrontier(roachpb.Span{Key: keyA, EndKey: keyD})
+	require.Equal(t, float32(0), scanFraction(f, scanTS))
+
+	f.Forward(roachpb.Span{Key: keyA, EndKey: keyB}, scanTS)
+	require.Equal(t, float32(0.5), scanFraction(f, scanTS))
+
+	f.Forward(roachpb.Span{Key: keyC, EndKey: keyD}, hlc.Timestamp{WallTime: 4})
+	require.Equal(t, float32(1)/3, scanFraction(f, scanTS))
+
+	f.Forward(roachpb.Span{Key: keyB, EndKey: keyD}, scanTS)
+	require.Equal(t, float32(1), scanFraction(f, scanTS))
+
+	require.Equal(t, float32(0), scanFraction(makeSpanFrontier(), scanTS))
+}
@@ -207,7 +207,8 @@ func (u *tableHistoryUpdater) PollTableDescs(ctx context.Context) error {
 		if !startTS.Less(endTS) {
 			continue
 		}
-		descs, err := fetchTableDescriptorVersions(ctx, u.db, startTS, endTS, u.targets)
+		descs, err := fetchTableDescriptorVersions(
+			ctx, u.db, startTS, endTS, u.targets, 0 /* databaseID */)
 		if err != nil {
 			return err
 		}
@@ -222,6 +223,7 @@ func fetchTableDescriptorVersions(
 	db *client.DB,
 	startTS, endTS hlc.Timestamp,
 	targets jobspb.ChangefeedTargets,
+	databaseID sqlbase.ID,
 ) ([]*sqlbase.TableDescriptor, error) {
 	if log.V(2) {
 		log.Infof(ctx, `fetching table descs (%s,%s]`, startTS, endTS)
@@ -270,12 +272,17 @@ func fetchTableDescriptorVersions(
 					return err
 				}
 				origName, ok := targets[sqlbase.ID(tableID)]
-				if !ok {
+				if !ok && databaseID == 0 {
 					// Uninteresting table.
 					continue
 				}
 				unsafeValue := it.UnsafeValue()
 				if unsafeValue == nil {
+					if databaseID != 0 {
+						// The descriptor of a table dropped from the watched
+						// database was removed.
+						continue
+					}
 					return errors.Errorf(`"%v" was dropped or truncated`, origName)
 				}
 				value := roachpb.Value{RawBytes: unsafeValue}
@@ -283,7 +290,13 @@ func fetchTableDescriptorVersions(
 				if err := value.GetProto(&desc); err != nil {
 					return err
 				}
-				if tableDesc := desc.Table(k.Timestamp); tableDesc != nil {
+				tableDesc := desc.Table(k.Timestamp)
+				if tableDesc == nil {
+					continue
+				}
+				// For a changefeed on a database, tables created in the database
+				// are interesting too.
+				if ok || isDatabaseTable(databaseID, tableDesc) {
 					tableDescs = append(tableDescs, tableDesc)
 				}
 			}
@@ -64,7 +64,7 @@ func runLoadShow(cmd *cobra.Command, args []string) error {
 	// upgraded from the old FK representation, or even older formats). If more
 	// fields are added to the output, the table descriptors may need to be
 	// upgraded.
-	desc, err := backupccl.ReadBackupDescriptorFromURI(ctx, basepath, externalStorageFromURI)
+	desc, err := backupccl.ReadBackupDescriptorFromURI(ctx, basepath, externalStorageFromURI, nil /* encryption */)
 	if err != nil {
 		return err
 	}
@@ -85,14 +85,21 @@ func txnCanPerformFollowerRead(txn *roachpb.Transaction) bool {
 	return txn != nil && !txn.IsWriting()
 }
 
-// canUseFollowerRead determines if a query can be sent to a follower.
-func canUseFollowerRead(clusterID uuid.UUID, st *cluster.Settings, ts hlc.Timestamp) bool {
+// canUseFollowerRead determines if a query can be sent to a follower. Reads
+// of bounded staleness transactions are at or below the closed timestamp
+// negotiated with the ranges they read, so they can be sent to a follower
+// regardless of how recent their timestamp is.
+func canUseFollowerRead(
+	clusterID uuid.UUID, st *cluster.Settings, ts hlc.Timestamp, boundedStaleness bool,
+) bool {
 	if !storage.FollowerReadsEnabled.Get(&st.SV) {
 		return false
 	}
-	threshold := (-1 * getFollowerReadDuration(st)) - 1*base.DefaultMaxClockOffset
-	if timeutil.Since(ts.GoTime()) < threshold {
-		return false
+	if !boundedStaleness {
+		threshold := (-1 * getFollowerReadDuration(st)) - 1*base.DefaultMaxClockOffset
+		if timeutil.Since(ts.GoTime()) < threshold {
+			return false
+		}
 	}
 	return checkEnterpriseEnabled(clusterID, st) == nil
 }
@@ -102,7 +109,8 @@ func canUseFollowerRead(clusterID uuid.UUID, st *cluster.Settings, ts hlc.Timest
 func canSendToFollower(clusterID uuid.UUID, st *cluster.Settings, ba roachpb.BatchRequest) bool {
 	return batchCanBeEvaluatedOnFollower(ba) &&
 		txnCanPerformFollowerRead(ba.Txn) &&
-		canUseFollowerRead(clusterID, st, forward(ba.Txn.ReadTimestamp, ba.Txn.MaxTimestamp))
+		canUseFollowerRead(clusterID, st,
+			forward(ba.Txn.ReadTimestamp, ba.Txn.MaxTimestamp), ba.Txn.BoundedStaleness)
 }
 
 func forward(ts hlc.Timestamp, to hlc.Timestamp) hlc.Timestamp {
@@ -128,7 +136,9 @@ func newOracleFactory(cfg replicaoracle.Config) replicaoracle.OracleFactory {
 }
 
 func (f oracleFactory) Oracle(txn *client.Txn) replicaoracle.Oracle {
-	if txn != nil && canUseFollowerRead(f.clusterID.Get(), f.st, txn.ReadTimestamp()) {
+	if txn != nil && canUseFollowerRead(
+		f.clusterID.Get(), f.st, txn.ReadTimestamp(), txn.BoundedStaleness(),
+	) {
 		return f.closest.Oracle(txn)
 	}
 	return f.binPacking.Oracle(txn)
@@ -10,10 +10,12 @@ package followerreadsccl
 
 import (
 	"context"
+	"fmt"
 	"reflect"
 	"testing"
 	"time"
 
+	"github.com/cockroachdb/cockroach/pkg/base"
 	"github.com/cockroachdb/cockroach/pkg/ccl/utilccl"
 	"github.com/cockroachdb/cockroach/pkg/internal/client"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
@@ -23,6 +25,8 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/storage"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
 	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/testcluster"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
@@ -30,6 +34,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/tracing"
 	"github.com/cockroachdb/cockroach/pkg/util/uuid"
+	"github.com/pkg/errors"
 )
 
 const (
@@ -123,10 +128,28 @@ func TestCanSendToFollower(t *testing.T) {
 	if canSendToFollower(uuid.MakeV4(), st, roNew) {
 		t.Fatalf("should not be able to send a ro batch with new MaxTimestamp to a follower")
 	}
+	roNewBoundedStaleness := roachpb.BatchRequest{Header: roachpb.Header{
+		Txn: &roachpb.Transaction{
+			ReadTimestamp:    hlc.Timestamp{WallTime: timeutil.Now().UnixNano()},
+			BoundedStaleness: true,
+		},
+	}}
+	roNewBoundedStaleness.Add(&roachpb.GetRequest{})
+	if !canSendToFollower(uuid.MakeV4(), st, roNewBoundedStaleness) {
+		t.Fatalf("should be able to send a new ro batch of a bounded staleness txn to a follower")
+	}
+	storage.FollowerReadsEnabled.Override(&st.SV, false)
+	if canSendToFollower(uuid.MakeV4(), st, roNewBoundedStaleness) {
+		t.Fatalf("should not be able to send a bounded staleness ro batch to a follower when follower reads are disabled")
+	}
+	storage.FollowerReadsEnabled.Override(&st.SV, true)
 	disableEnterprise()
 	if canSendToFollower(uuid.MakeV4(), st, roOld) {
 		t.Fatalf("should not be able to send an old ro batch to a follower without enterprise enabled")
 	}
+	if canSendToFollower(uuid.MakeV4(), st, roNewBoundedStaleness) {
+		t.Fatalf("should not be able to send a bounded staleness ro batch to a follower without enterprise enabled")
+	}
 }
 
 func TestFollowerReadMultipleValidation(t *testing.T) {
@@ -173,6 +196,13 @@ func TestOracleFactory(t *testing.T) {
 		t.Fatalf("expected types of %T and %T to differ", followerReadOracle,
 			noFollowerReadOracle)
 	}
+	boundedStalenessTxn := client.NewTxn(context.TODO(), c, 0, client.RootTxn)
+	boundedStalenessTxn.SetBoundedStalenessTimestamp(context.TODO(), clock.Now())
+	boundedStalenessOracle := of.Oracle(boundedStalenessTxn)
+	if reflect.TypeOf(boundedStalenessOracle) != reflect.TypeOf(followerReadOracle) {
+		t.Fatalf("expected types of %T and %T not to differ", boundedStalenessOracle,
+			followerReadOracle)
+	}
 	disableEnterprise()
 	disabledFollowerReadOracle := of.Oracle(txn)
 	if reflect.TypeOf(disabledFollowerReadOracle) != reflect.TypeOf(noFollowerReadOracle) {
@@ -180,3 +210,48 @@ func TestOracleFactory(t *testing.T) {
 			noFollowerReadOracle)
 	}
 }
+
+// TestBoundedStalenessReadServedByFollower checks that a bounded staleness
+// read whose timestamp is more recent than the follower read threshold is
+// served by the replica of the gateway node, which does not hold the lease.
+func TestBoundedStalenessReadServedByFollower(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer utilccl.TestingEnableEnterprise()()
+
+	ctx := context.Background()
+	tc := testcluster.StartTestCluster(t, 3, base.TestClusterArgs{})
+	defer tc.Stopper().Stop(ctx)
+	db := sqlutils.MakeSQLRunner(tc.ServerConn(0))
+	db.Exec(t, `SET CLUSTER SETTING kv.closed_timestamp.target_duration = '100ms'`)
+	db.Exec(t, `SET CLUSTER SETTING kv.closed_timestamp.follower_reads_enabled = true`)
+	db.Exec(t, `CREATE DATABASE test`)
+	db.Exec(t, `CREATE TABLE test.t (k INT PRIMARY KEY)`)
+	db.Exec(t, `INSERT INTO test.t VALUES (1), (2), (3)`)
+	var minTS time.Time
+	db.QueryRow(t, `SELECT now()`).Scan(&minTS)
+	if err := tc.WaitForFullReplication(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The gateway node of the read doesn't hold the lease, so a read served by
+	// its own store is a follower read.
+	const gatewayIdx = 2
+	gateway := tc.Server(gatewayIdx)
+	store, err := gateway.GetStores().(*storage.Stores).GetStore(gateway.GetFirstStoreID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	gatewayDB := sqlutils.MakeSQLRunner(tc.ServerConn(gatewayIdx))
+	testutils.SucceedsSoon(t, func() error {
+		db.Exec(t, `ALTER TABLE test.t EXPERIMENTAL_RELOCATE LEASE VALUES (1, 1)`)
+		before := store.Metrics().FollowerReadsCount.Count()
+		gatewayDB.CheckQueryResults(t, fmt.Sprintf(
+			`SELECT k FROM test.t AS OF SYSTEM TIME with_min_timestamp('%s')`,
+			minTS.Format(time.RFC3339Nano),
+		), [][]string{{"1"}, {"2"}, {"3"}})
+		if after := store.Metrics().FollowerReadsCount.Count(); after <= before {
+			return errors.Errorf("expected the read to be served by a follower")
+		}
+		return nil
+	})
+}
@@ -144,6 +144,12 @@ func makeInputConverter(
 		return newPgCopyReader(kvCh, spec.Format.PgCopy, singleTable, evalCtx)
 	case roachpb.IOFileFormat_PgDump:
 		return newPgDumpReader(kvCh, spec.Format.PgDump, spec.Tables, evalCtx)
+	case roachpb.IOFileFormat_Avro:
+		return newAvroInputReader(
+			kvCh, spec.WalltimeNanos, singleTable, singleTableTargetCols, evalCtx)
+	case roachpb.IOFileFormat_JSONL:
+		return newJSONLInputReader(
+			kvCh, spec.WalltimeNanos, singleTable, singleTableTargetCols, evalCtx)
 	default:
 		return nil, errors.Errorf("Requested IMPORT format (%d) not supported by this node", spec.Format.Format)
 	}
@@ -377,6 +377,18 @@ func importPlanHook(
 				maxRowSize = int32(sz)
 			}
 			format.PgDump.MaxRowSize = maxRowSize
+		case "AVRO":
+			telemetry.Count("import.format.avro")
+			format.Format = roachpb.IOFileFormat_Avro
+			if _, ok := opts[importOptionSaveRejected]; ok {
+				format.SaveRejected = true
+			}
+		case "JSONL":
+			telemetry.Count("import.format.jsonl")
+			format.Format = roachpb.IOFileFormat_JSONL
+			if _, ok := opts[importOptionSaveRejected]; ok {
+				format.SaveRejected = true
+			}
 		default:
 			return unimplemented.Newf("import.format", "unsupported import format: %q", importStmt.FileFormat)
 		}
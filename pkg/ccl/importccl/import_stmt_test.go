@@ -13,6 +13,7 @@ import (
 	"context"
 	"fmt"
 	"io/ioutil"
+	"math/big"
 	"net/http"
 	"net/http/httptest"
 	"path/filepath"
@@ -47,6 +48,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
 	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
 	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/linkedin/goavro"
 	"github.com/pkg/errors"
 	"golang.org/x/sync/errgroup"
 )
@@ -3123,6 +3125,214 @@ func TestImportPgCopy(t *testing.T) {
 	}
 }
 
+func TestImportAvro(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ctx := context.Background()
+	dir, cleanup := testutils.TempDir(t)
+	defer cleanup()
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{ExternalIODir: dir})
+	defer s.Stopper().Stop(ctx)
+	sqlDB := sqlutils.MakeSQLRunner(db)
+	sqlDB.Exec(t, `CREATE DATABASE foo; SET DATABASE = foo`)
+
+	// writeOCF writes the given records to an Avro object container file.
+	writeOCF := func(name, schema string, records ...map[string]interface{}) {
+		t.Helper()
+		var buf bytes.Buffer
+		w, err := goavro.NewOCFWriter(goavro.OCFConfig{W: &buf, Schema: schema})
+		if err != nil {
+			t.Fatal(err)
+		}
+		values := make([]interface{}, len(records))
+		for i := range records {
+			values[i] = records[i]
+		}
+		if err := w.Append(values); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(dir, name), buf.Bytes(), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	const allTypesSchema = `{"type": "record", "name": "all", "fields": [
+		{"name": "id", "type": "long"},
+		{"name": "name", "type": ["null", "string"]},
+		{"name": "score", "type": "double"},
+		{"name": "created", "type": {"type": "long", "logicalType": "timestamp-micros"}},
+		{"name": "amount", "type": {"type": "bytes", "logicalType": "decimal", "precision": 10, "scale": 2}},
+		{"name": "tags", "type": {"type": "array", "items": "string"}},
+		{"name": "attrs", "type": {"type": "map", "values": "long"}}
+	]}`
+	created := time.Date(2020, 3, 18, 10, 30, 0, 0, time.UTC)
+	writeOCF("all.avro", allTypesSchema,
+		map[string]interface{}{
+			"id":      int64(1),
+			"name":    goavro.Union("string", "one"),
+			"score":   1.5,
+			"created": created,
+			"amount":  big.NewRat(1234, 100),
+			"tags":    []interface{}{"a", "b"},
+			"attrs":   map[string]interface{}{"x": int64(1)},
+		},
+		map[string]interface{}{
+			"id":      int64(2),
+			"name":    nil,
+			"score":   -2.0,
+			"created": created.Add(time.Hour),
+			"amount":  big.NewRat(-5, 1),
+			"tags":    []interface{}{},
+			"attrs":   map[string]interface{}{},
+		},
+	)
+
+	t.Run("all types", func(t *testing.T) {
+		sqlDB.Exec(t, `IMPORT TABLE all_types (
+			id INT8 PRIMARY KEY, name STRING, score FLOAT8, created TIMESTAMPTZ,
+			amount DECIMAL(10,2), tags STRING[], attrs JSONB
+		) AVRO DATA ('nodelocal:///all.avro')`)
+		sqlDB.CheckQueryResults(t, `SELECT * FROM all_types ORDER BY id`, [][]string{
+			{"1", "one", "1.5", "2020-03-18 10:30:00+00:00", "12.34", "{a,b}", `{"x": 1}`},
+			{"2", "NULL", "-2", "2020-03-18 11:30:00+00:00", "-5.00", "{}", `{}`},
+		})
+	})
+
+	t.Run("into", func(t *testing.T) {
+		sqlDB.Exec(t, `CREATE TABLE into_table (
+			id INT8 PRIMARY KEY, name STRING, score FLOAT8, created TIMESTAMPTZ,
+			amount DECIMAL(10,2), tags STRING[], attrs JSONB, extra STRING
+		)`)
+		sqlDB.Exec(t, `IMPORT INTO into_table AVRO DATA ('nodelocal:///all.avro')`)
+		sqlDB.CheckQueryResults(t, `SELECT id, name, extra FROM into_table ORDER BY id`, [][]string{
+			{"1", "one", "NULL"}, {"2", "NULL", "NULL"},
+		})
+	})
+
+	t.Run("unknown field", func(t *testing.T) {
+		sqlDB.ExpectErr(t, `Avro field "attrs" does not match any column`,
+			`IMPORT TABLE missing (
+				id INT8 PRIMARY KEY, name STRING, score FLOAT8, created TIMESTAMPTZ,
+				amount DECIMAL(10,2), tags STRING[]
+			) AVRO DATA ('nodelocal:///all.avro')`)
+	})
+
+	t.Run("rejected", func(t *testing.T) {
+		writeOCF("ints.avro", `{"type": "record", "name": "ints", "fields": [
+				{"name": "id", "type": "long"},
+				{"name": "v", "type": "string"}
+			]}`,
+			map[string]interface{}{"id": int64(1), "v": "1"},
+			map[string]interface{}{"id": int64(2), "v": "two"},
+			map[string]interface{}{"id": int64(3), "v": "3"},
+		)
+		const create = `IMPORT TABLE %s (id INT8 PRIMARY KEY, v INT8) AVRO DATA ('nodelocal:///ints.avro') %s`
+		sqlDB.ExpectErr(t, `row 2: parse "v" as INT8`, fmt.Sprintf(create, "ints", ""))
+		sqlDB.Exec(t, fmt.Sprintf(create, "ints", "WITH experimental_save_rejected"))
+		sqlDB.CheckQueryResults(t, `SELECT * FROM ints ORDER BY id`, [][]string{{"1", "1"}, {"3", "3"}})
+		rejected, err := ioutil.ReadFile(filepath.Join(dir, "ints.avro.rejected"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		// The rejected records are saved as an object container file.
+		ocf, err := goavro.NewOCFReader(bytes.NewReader(rejected))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var records []string
+		for ocf.Scan() {
+			native, err := ocf.Read()
+			if err != nil {
+				t.Fatal(err)
+			}
+			textual, err := ocf.Codec().TextualFromNative(nil, native)
+			if err != nil {
+				t.Fatal(err)
+			}
+			records = append(records, string(textual))
+		}
+		if expected := `{"id":2,"v":"two"}`; strings.Join(records, "\n") != expected {
+			t.Fatalf("expected rejected records %q, got %q", expected, records)
+		}
+
+		// They can be imported again.
+		sqlDB.Exec(t, `IMPORT TABLE ints_rejected (id INT8 PRIMARY KEY, v STRING)
+			AVRO DATA ('nodelocal:///ints.avro.rejected')`)
+		sqlDB.CheckQueryResults(t, `SELECT * FROM ints_rejected`, [][]string{{"2", "two"}})
+	})
+}
+
+func TestImportJSONL(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ctx := context.Background()
+	dir, cleanup := testutils.TempDir(t)
+	defer cleanup()
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{ExternalIODir: dir})
+	defer s.Stopper().Stop(ctx)
+	sqlDB := sqlutils.MakeSQLRunner(db)
+	sqlDB.Exec(t, `CREATE DATABASE foo; SET DATABASE = foo`)
+
+	writeFile := func(name string, lines ...string) {
+		t.Helper()
+		data := strings.Join(lines, "\n") + "\n"
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	writeFile("all.jsonl",
+		`{"id": 1, "name": "one", "score": 1.5, "created": "2020-03-18T10:30:00Z", "ok": true, "tags": ["a", "b"], "attrs": {"x": 1}}`,
+		``,
+		`{"id": 2, "name": null, "score": -2, "ok": false, "tags": [], "attrs": [1, null]}`,
+		`{"ID": 3}`,
+	)
+
+	t.Run("all types", func(t *testing.T) {
+		sqlDB.Exec(t, `IMPORT TABLE all_types (
+			id INT8 PRIMARY KEY, name STRING, score DECIMAL, created TIMESTAMPTZ, ok BOOL,
+			tags STRING[], attrs JSONB
+		) JSONL DATA ('nodelocal:///all.jsonl')`)
+		sqlDB.CheckQueryResults(t, `SELECT * FROM all_types ORDER BY id`, [][]string{
+			{"1", "one", "1.5", "2020-03-18 10:30:00+00:00", "true", "{a,b}", `{"x": 1}`},
+			{"2", "NULL", "-2", "NULL", "false", "{}", `[1, null]`},
+			{"3", "NULL", "NULL", "NULL", "NULL", "NULL", "NULL"},
+		})
+	})
+
+	t.Run("into", func(t *testing.T) {
+		writeFile("into.jsonl", `{"id": 1, "name": "one"}`, `{"id": 2}`)
+		sqlDB.Exec(t, `CREATE TABLE into_table (id INT8 PRIMARY KEY, name STRING, extra STRING)`)
+		sqlDB.Exec(t, `IMPORT INTO into_table (id, name) JSONL DATA ('nodelocal:///into.jsonl')`)
+		sqlDB.CheckQueryResults(t, `SELECT * FROM into_table ORDER BY id`, [][]string{
+			{"1", "one", "NULL"}, {"2", "NULL", "NULL"},
+		})
+		sqlDB.ExpectErr(t, `row 1: key "name" does not match any column`,
+			`IMPORT INTO into_table (id) JSONL DATA ('nodelocal:///into.jsonl')`)
+	})
+
+	t.Run("rejected", func(t *testing.T) {
+		writeFile("ints.jsonl",
+			`{"id": 1, "v": 1}`,
+			`{"id": 2, "v": "two"}`,
+			`[3]`,
+			`{"id": 4`,
+			`{"id": 5, "v": 5}`,
+		)
+		const create = `IMPORT TABLE %s (id INT8 PRIMARY KEY, v INT8) JSONL DATA ('nodelocal:///ints.jsonl') %s`
+		sqlDB.ExpectErr(t, `row 2: parse "v" as INT8`, fmt.Sprintf(create, "ints", ""))
+		sqlDB.Exec(t, fmt.Sprintf(create, "ints", "WITH experimental_save_rejected"))
+		sqlDB.CheckQueryResults(t, `SELECT * FROM ints ORDER BY id`, [][]string{{"1", "1"}, {"5", "5"}})
+		rejected, err := ioutil.ReadFile(filepath.Join(dir, "ints.jsonl.rejected"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected := `{"id": 2, "v": "two"}` + "\n" + `[3]` + "\n" + `{"id": 4` + "\n"; string(rejected) != expected {
+			t.Fatalf("expected rejected lines %q, got %q", expected, rejected)
+		}
+	})
+}
+
 func TestImportPgDump(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 
@@ -0,0 +1,342 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package importccl
+
+import (
+	"bytes"
+	"context"
+	gojson "encoding/json"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/row"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/storage/cloud"
+	"github.com/cockroachdb/cockroach/pkg/util/ctxgroup"
+	"github.com/cockroachdb/cockroach/pkg/util/duration"
+	"github.com/cockroachdb/cockroach/pkg/util/json"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/timeofday"
+	"github.com/cockroachdb/errors"
+	"github.com/linkedin/goavro"
+)
+
+// avroInputReader reads Avro object container files, whose records are
+// mapped to columns by field name.
+type avroInputReader struct {
+	conv           *row.DatumRowConverter
+	cols           recordColumns
+	rowIndexOffset int64
+}
+
+var _ inputConverter = &avroInputReader{}
+
+func newAvroInputReader(
+	kvCh chan row.KVBatch,
+	walltime int64,
+	tableDesc *sqlbase.TableDescriptor,
+	targetCols tree.NameList,
+	evalCtx *tree.EvalContext,
+) (*avroInputReader, error) {
+	conv, err := row.NewDatumRowConverter(tableDesc, targetCols, evalCtx, kvCh)
+	if err != nil {
+		return nil, err
+	}
+	return &avroInputReader{
+		conv:           conv,
+		cols:           makeRecordColumns(conv, targetCols),
+		rowIndexOffset: importRowIndexOffset(walltime),
+	}, nil
+}
+
+func (r *avroInputReader) start(group ctxgroup.Group) {
+}
+
+func (r *avroInputReader) readFiles(
+	ctx context.Context,
+	dataFiles map[int32]string,
+	resumePos map[int32]int64,
+	format roachpb.IOFileFormat,
+	makeExternalStorage cloud.ExternalStorageFactory,
+) error {
+	return readInputFiles(ctx, dataFiles, resumePos, format, r.readFile, makeExternalStorage)
+}
+
+// avroField is a field of the records of an Avro file.
+type avroField struct {
+	name string
+	// union is set for fields whose values may be of several types, which
+	// goavro decodes as a map from the name of the type to the value.
+	union bool
+	// idx and typ are the position in the datums of the converter and the
+	// type of the column the field is imported into.
+	idx int
+	typ *types.T
+}
+
+// avroRecordFields maps the fields of the records described by the given
+// Avro schema to columns.
+func (r *avroInputReader) avroRecordFields(schema string) (map[string]avroField, error) {
+	var record struct {
+		Type   interface{} `json:"type"`
+		Fields []struct {
+			Name string            `json:"name"`
+			Type gojson.RawMessage `json:"type"`
+		} `json:"fields"`
+	}
+	if err := gojson.Unmarshal([]byte(schema), &record); err != nil {
+		return nil, errors.Wrap(err, "parsing Avro schema")
+	}
+	if record.Type != "record" {
+		return nil, pgerror.Newf(pgcode.InvalidParameterValue,
+			"expected Avro records, got %s", schema)
+	}
+	fields := make(map[string]avroField, len(record.Fields))
+	for _, f := range record.Fields {
+		idx, typ, ok := r.cols.lookup(f.Name)
+		if !ok {
+			return nil, pgerror.Newf(pgcode.UndefinedColumn,
+				"Avro field %q does not match any column", f.Name)
+		}
+		fields[f.Name] = avroField{
+			name:  f.Name,
+			union: len(f.Type) > 0 && f.Type[0] == '[',
+			idx:   idx,
+			typ:   typ,
+		}
+	}
+	return fields, nil
+}
+
+func (r *avroInputReader) readFile(
+	ctx context.Context,
+	input *fileReader,
+	inputIdx int32,
+	inputName string,
+	resumePos int64,
+	rejected chan string,
+) error {
+	r.conv.KvBatch.Source = inputIdx
+	r.conv.FractionFn = input.ReadFraction
+	var count int64
+	r.conv.CompletedRowFn = func() int64 {
+		return count
+	}
+
+	ocf, err := goavro.NewOCFReader(input)
+	if err != nil {
+		return errors.Wrap(err, "reading Avro object container file")
+	}
+	fields, err := r.avroRecordFields(ocf.Codec().Schema())
+	if err != nil {
+		return err
+	}
+
+	// Rejected records are saved, once the whole file is read, as an object
+	// container file with the same schema, so that they can be imported again
+	// once fixed.
+	var rejectedRecords []interface{}
+	for ocf.Scan() {
+		native, err := ocf.Read()
+		if err != nil {
+			return errors.Wrap(err, "reading Avro record")
+		}
+		count++
+		if count <= resumePos {
+			continue
+		}
+		if err := r.convertRecord(ctx, inputIdx, inputName, count, fields, native); err != nil {
+			if rejected == nil {
+				return err
+			}
+			log.Error(ctx, err)
+			rejectedRecords = append(rejectedRecords, native)
+		}
+	}
+	if err := ocf.Err(); err != nil {
+		return errors.Wrap(err, "reading Avro record")
+	}
+	if len(rejectedRecords) > 0 {
+		var buf bytes.Buffer
+		w, err := goavro.NewOCFWriter(goavro.OCFConfig{W: &buf, Codec: ocf.Codec()})
+		if err != nil {
+			return errors.Wrap(err, "writing rejected Avro records")
+		}
+		if err := w.Append(rejectedRecords); err != nil {
+			return errors.Wrap(err, "writing rejected Avro records")
+		}
+		rejected <- buf.String()
+	}
+	return r.conv.SendBatch(ctx)
+}
+
+// convertRecord converts a record decoded by goavro into a row.
+func (r *avroInputReader) convertRecord(
+	ctx context.Context,
+	inputIdx int32,
+	inputName string,
+	count int64,
+	fields map[string]avroField,
+	native interface{},
+) error {
+	record, ok := native.(map[string]interface{})
+	if !ok {
+		return makeRowErr(inputName, count, pgcode.Syntax, "unexpected Avro value %T", native)
+	}
+
+	r.cols.reset()
+	for name, v := range record {
+		f := fields[name]
+		if f.union && v != nil {
+			v = avroUnionValue(v)
+		}
+		d, err := avroToDatum(v, f.typ, r.conv.EvalCtx)
+		if err != nil {
+			return wrapRowErr(err, inputName, count, pgcode.Syntax,
+				"parse %q as %s", name, f.typ.SQLString())
+		}
+		r.conv.Datums[f.idx] = d
+	}
+	if err := r.conv.Row(ctx, inputIdx, r.rowIndexOffset+count); err != nil {
+		return wrapRowErr(err, inputName, count, pgcode.Uncategorized, "")
+	}
+	return nil
+}
+
+// avroUnionValue returns the value of a non-null union, which goavro decodes
+// as a map from the name of the type of the value to the value.
+func avroUnionValue(v interface{}) interface{} {
+	if m, ok := v.(map[string]interface{}); ok && len(m) == 1 {
+		for _, value := range m {
+			return value
+		}
+	}
+	return v
+}
+
+// avroToDatum converts a value decoded by goavro into a datum of the given
+// type. Values which don't map directly to a datum of the type, such as
+// integers imported into decimal columns, are converted through their text
+// form.
+func avroToDatum(v interface{}, typ *types.T, evalCtx *tree.EvalContext) (tree.Datum, error) {
+	if v == nil {
+		return tree.DNull, nil
+	}
+	switch typ.Family() {
+	case types.JsonFamily:
+		// Records, maps and arrays are imported into JSON columns, along with
+		// the unions they contain.
+		raw, err := gojson.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		j, err := json.ParseJSON(string(raw))
+		if err != nil {
+			return nil, err
+		}
+		return tree.NewDJSON(j), nil
+	case types.ArrayFamily:
+		if elems, ok := v.([]interface{}); ok {
+			arr := tree.NewDArray(typ.ArrayContents())
+			for _, elem := range elems {
+				d, err := avroToDatum(avroUnionValue(elem), typ.ArrayContents(), evalCtx)
+				if err != nil {
+					return nil, err
+				}
+				if err := arr.Append(d); err != nil {
+					return nil, err
+				}
+			}
+			return arr, nil
+		}
+	}
+
+	switch x := v.(type) {
+	case bool:
+		if typ.Family() == types.BoolFamily {
+			return tree.MakeDBool(tree.DBool(x)), nil
+		}
+	case int32:
+		if typ.Family() == types.IntFamily {
+			return tree.NewDInt(tree.DInt(x)), nil
+		}
+	case int64:
+		if typ.Family() == types.IntFamily {
+			return tree.NewDInt(tree.DInt(x)), nil
+		}
+	case float32:
+		if typ.Family() == types.FloatFamily {
+			return tree.NewDFloat(tree.DFloat(x)), nil
+		}
+	case float64:
+		if typ.Family() == types.FloatFamily {
+			return tree.NewDFloat(tree.DFloat(x)), nil
+		}
+	case string:
+		return tree.ParseDatumStringAs(typ, x, evalCtx)
+	case []byte:
+		if typ.Family() == types.BytesFamily {
+			return tree.NewDBytes(tree.DBytes(x)), nil
+		}
+		return tree.ParseDatumStringAs(typ, string(x), evalCtx)
+	case time.Time:
+		// The timestamp-millis, timestamp-micros and date logical types.
+		switch typ.Family() {
+		case types.TimestampFamily:
+			return tree.MakeDTimestamp(x, time.Microsecond), nil
+		case types.TimestampTZFamily:
+			return tree.MakeDTimestampTZ(x, time.Microsecond), nil
+		case types.DateFamily:
+			return tree.NewDDateFromTime(x)
+		}
+		return tree.ParseDatumStringAs(typ, x.Format(time.RFC3339Nano), evalCtx)
+	case time.Duration:
+		// The time-millis and time-micros logical types.
+		switch typ.Family() {
+		case types.TimeFamily:
+			return tree.MakeDTime(timeofday.FromInt(int64(x / time.Microsecond))), nil
+		case types.IntervalFamily:
+			return &tree.DInterval{Duration: duration.MakeDuration(x.Nanoseconds(), 0, 0)}, nil
+		}
+	case *big.Rat:
+		// The decimal logical type.
+		return tree.ParseDatumStringAs(typ, ratToDecimalString(x), evalCtx)
+	case map[string]interface{}, []interface{}:
+		return nil, errors.Errorf("unexpected Avro value %v", v)
+	}
+	return tree.ParseDatumStringAs(typ, fmt.Sprint(v), evalCtx)
+}
+
+// ratToDecimalString formats a rational decoded from an Avro decimal, whose
+// denominator is a power of ten, as an exact decimal.
+func ratToDecimalString(r *big.Rat) string {
+	denom := new(big.Int).Set(r.Denom())
+	var digits [2]int
+	var rem big.Int
+	for i, factor := range []*big.Int{big.NewInt(2), big.NewInt(5)} {
+		for {
+			var q big.Int
+			if q.QuoRem(denom, factor, &rem); rem.Sign() != 0 {
+				break
+			}
+			denom.Set(&q)
+			digits[i]++
+		}
+	}
+	if digits[1] > digits[0] {
+		digits[0] = digits[1]
+	}
+	return r.FloatString(digits[0])
+}
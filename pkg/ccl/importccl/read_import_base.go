@@ -19,6 +19,7 @@ import (
 	"math"
 	"net/url"
 	"strings"
+	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/execinfra"
@@ -26,6 +27,8 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/row"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
 	"github.com/cockroachdb/cockroach/pkg/storage/cloud"
 	"github.com/cockroachdb/cockroach/pkg/util/ctxgroup"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
@@ -178,9 +181,12 @@ func readInputFiles(
 			src.Reader = decompressed
 
 			var rejected chan string
-			if (format.Format == roachpb.IOFileFormat_CSV && format.SaveRejected) ||
-				(format.Format == roachpb.IOFileFormat_MysqlOutfile && format.SaveRejected) {
-				rejected = make(chan string)
+			if format.SaveRejected {
+				switch format.Format {
+				case roachpb.IOFileFormat_CSV, roachpb.IOFileFormat_MysqlOutfile,
+					roachpb.IOFileFormat_Avro, roachpb.IOFileFormat_JSONL:
+					rejected = make(chan string)
+				}
 			}
 			if rejected != nil {
 				grp := ctxgroup.WithContext(ctx)
@@ -348,3 +354,67 @@ func wrapRowErr(err error, file string, row int64, code, format string, args ...
 	}
 	return err
 }
+
+// recordColumns maps the named fields of the records of self-describing
+// formats, such as Avro and JSON, to the datums of a row converter.
+type recordColumns struct {
+	conv *row.DatumRowConverter
+	// idx maps the name of each target column to its position in the datums
+	// of the converter.
+	idx   map[string]int
+	types []*types.T
+}
+
+func makeRecordColumns(conv *row.DatumRowConverter, targetCols tree.NameList) recordColumns {
+	colTypes := make(map[string]*types.T, len(conv.VisibleCols))
+	for i := range conv.VisibleCols {
+		colTypes[conv.VisibleCols[i].Name] = conv.VisibleColTypes[i]
+	}
+	names := targetCols
+	if len(names) == 0 {
+		for i := range conv.VisibleCols {
+			names = append(names, tree.Name(conv.VisibleCols[i].Name))
+		}
+	}
+	c := recordColumns{
+		conv:  conv,
+		idx:   make(map[string]int, len(names)),
+		types: make([]*types.T, len(names)),
+	}
+	for i, name := range names {
+		c.idx[string(name)] = i
+		c.types[i] = colTypes[string(name)]
+	}
+	return c
+}
+
+// lookup returns the position in the datums of the converter and the type of
+// the column a field is imported into. Field names are matched exactly, or
+// else to the column of the same name in lower case.
+func (c *recordColumns) lookup(field string) (int, *types.T, bool) {
+	i, ok := c.idx[field]
+	if !ok {
+		i, ok = c.idx[strings.ToLower(field)]
+	}
+	if !ok {
+		return 0, nil, false
+	}
+	return i, c.types[i], true
+}
+
+// reset sets the datums of all target columns to NULL, so that the columns
+// missing from a record are imported as NULL.
+func (c *recordColumns) reset() {
+	for i := range c.types {
+		c.conv.Datums[i] = tree.DNull
+	}
+}
+
+// importRowIndexOffset returns the offset of the row indexes of an import
+// started at the given time, so that the rows added to a table by subsequent
+// imports aren't given the same hidden row IDs. See DatumRowConverter.Row.
+func importRowIndexOffset(walltime int64) int64 {
+	epoch := time.Date(2015, time.January, 1, 0, 0, 0, 0, time.UTC).UnixNano()
+	const precision = int64(10 * time.Microsecond)
+	return (walltime - epoch) / precision
+}
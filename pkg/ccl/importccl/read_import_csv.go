@@ -14,7 +14,6 @@ import (
 	"runtime"
 	"strings"
 	"sync/atomic"
-	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
@@ -229,9 +228,7 @@ func (c *csvInputReader) convertRecordWorker(ctx context.Context, workerID int)
 		return m
 	}
 
-	epoch := time.Date(2015, time.January, 1, 0, 0, 0, 0, time.UTC).UnixNano()
-	const precision = uint64(10 * time.Microsecond)
-	timestamp := uint64(c.walltime-epoch) / precision
+	rowIndexOffset := importRowIndexOffset(c.walltime)
 
 	for batch := range c.recordCh {
 		minEmitted = batch.minEmitted
@@ -272,7 +269,7 @@ func (c *csvInputReader) convertRecordWorker(ctx context.Context, workerID int)
 				datumIdx++
 			}
 
-			rowIndex := int64(timestamp) + rowNum
+			rowIndex := rowIndexOffset + rowNum
 			if err := conv.Row(ctx, batch.fileIndex, rowIndex); err != nil {
 				return wrapRowErr(err, batch.file, rowNum, pgcode.Uncategorized, "")
 			}
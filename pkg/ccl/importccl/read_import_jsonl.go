@@ -0,0 +1,192 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package importccl
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/row"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/storage/cloud"
+	"github.com/cockroachdb/cockroach/pkg/util/ctxgroup"
+	"github.com/cockroachdb/cockroach/pkg/util/json"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/errors"
+)
+
+// jsonlInputReader reads newline-delimited JSON: every non-empty line of its
+// input is a JSON object, whose keys are mapped to columns by name.
+type jsonlInputReader struct {
+	conv           *row.DatumRowConverter
+	cols           recordColumns
+	rowIndexOffset int64
+}
+
+var _ inputConverter = &jsonlInputReader{}
+
+func newJSONLInputReader(
+	kvCh chan row.KVBatch,
+	walltime int64,
+	tableDesc *sqlbase.TableDescriptor,
+	targetCols tree.NameList,
+	evalCtx *tree.EvalContext,
+) (*jsonlInputReader, error) {
+	conv, err := row.NewDatumRowConverter(tableDesc, targetCols, evalCtx, kvCh)
+	if err != nil {
+		return nil, err
+	}
+	return &jsonlInputReader{
+		conv:           conv,
+		cols:           makeRecordColumns(conv, targetCols),
+		rowIndexOffset: importRowIndexOffset(walltime),
+	}, nil
+}
+
+func (r *jsonlInputReader) start(group ctxgroup.Group) {
+}
+
+func (r *jsonlInputReader) readFiles(
+	ctx context.Context,
+	dataFiles map[int32]string,
+	resumePos map[int32]int64,
+	format roachpb.IOFileFormat,
+	makeExternalStorage cloud.ExternalStorageFactory,
+) error {
+	return readInputFiles(ctx, dataFiles, resumePos, format, r.readFile, makeExternalStorage)
+}
+
+func (r *jsonlInputReader) readFile(
+	ctx context.Context,
+	input *fileReader,
+	inputIdx int32,
+	inputName string,
+	resumePos int64,
+	rejected chan string,
+) error {
+	r.conv.KvBatch.Source = inputIdx
+	r.conv.FractionFn = input.ReadFraction
+	var count int64
+	r.conv.CompletedRowFn = func() int64 {
+		return count
+	}
+
+	reader := bufio.NewReaderSize(input, 64*1024)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return err
+		}
+		finished := err == io.EOF
+		// Rows are numbered by line, so that resuming skips the same lines
+		// regardless of empty ones.
+		count++
+		if count > resumePos && strings.TrimSpace(line) != "" {
+			if err := r.convertLine(ctx, inputIdx, inputName, count, line); err != nil {
+				if rejected == nil {
+					return err
+				}
+				log.Error(ctx, err)
+				if !strings.HasSuffix(line, "\n") {
+					line += "\n"
+				}
+				rejected <- line
+			}
+		}
+		if finished {
+			break
+		}
+	}
+	return r.conv.SendBatch(ctx)
+}
+
+// convertLine converts the JSON object on a line of input into a row.
+func (r *jsonlInputReader) convertLine(
+	ctx context.Context, inputIdx int32, inputName string, count int64, line string,
+) error {
+	obj, err := json.ParseJSON(line)
+	if err != nil {
+		return wrapRowErr(err, inputName, count, pgcode.Syntax, "parse JSON")
+	}
+	if obj.Type() != json.ObjectJSONType {
+		return makeRowErr(inputName, count, pgcode.Syntax, "expected a JSON object, got %s", obj)
+	}
+	it, err := obj.ObjectIter()
+	if err != nil {
+		return wrapRowErr(err, inputName, count, pgcode.Syntax, "")
+	}
+
+	r.cols.reset()
+	for it.Next() {
+		idx, typ, ok := r.cols.lookup(it.Key())
+		if !ok {
+			return makeRowErr(inputName, count, pgcode.UndefinedColumn,
+				"key %q does not match any column", it.Key())
+		}
+		if r.conv.Datums[idx], err = jsonToDatum(it.Value(), typ, r.conv.EvalCtx); err != nil {
+			return wrapRowErr(err, inputName, count, pgcode.Syntax,
+				"parse %q as %s", it.Key(), typ.SQLString())
+		}
+	}
+	if err := r.conv.Row(ctx, inputIdx, r.rowIndexOffset+count); err != nil {
+		return wrapRowErr(err, inputName, count, pgcode.Uncategorized, "")
+	}
+	return nil
+}
+
+// jsonToDatum converts a JSON value into a datum of the given type. JSON
+// columns take any value as is; other columns take JSON strings, numbers and
+// booleans in their text form, and array columns take JSON arrays.
+func jsonToDatum(j json.JSON, typ *types.T, evalCtx *tree.EvalContext) (tree.Datum, error) {
+	if j.Type() == json.NullJSONType {
+		return tree.DNull, nil
+	}
+	switch typ.Family() {
+	case types.JsonFamily:
+		return tree.NewDJSON(j), nil
+	case types.ArrayFamily:
+		if j.Type() == json.ArrayJSONType {
+			arr := tree.NewDArray(typ.ArrayContents())
+			for i := 0; i < j.Len(); i++ {
+				elem, err := j.FetchValIdx(i)
+				if err != nil {
+					return nil, err
+				}
+				d, err := jsonToDatum(elem, typ.ArrayContents(), evalCtx)
+				if err != nil {
+					return nil, err
+				}
+				if err := arr.Append(d); err != nil {
+					return nil, err
+				}
+			}
+			return arr, nil
+		}
+	}
+
+	switch j.Type() {
+	case json.StringJSONType:
+		s, err := j.AsText()
+		if err != nil {
+			return nil, err
+		}
+		return tree.ParseDatumStringAs(typ, *s, evalCtx)
+	case json.ArrayJSONType, json.ObjectJSONType:
+		return nil, errors.Errorf("unexpected JSON value %s", j)
+	default:
+		// Numbers and booleans.
+		return tree.ParseDatumStringAs(typ, j.String(), evalCtx)
+	}
+}
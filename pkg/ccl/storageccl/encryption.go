@@ -0,0 +1,117 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package storageccl
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// The following helpers encrypt files written to export storage, such as the
+// SSTs and manifests of a BACKUP, with AES-GCM. An encrypted file is laid out
+// as:
+//
+//   encryptionPreamble | encryptionVersion | nonce | ciphertext and GCM tag
+//
+// The whole file is sealed at once, which is fine since the files written by
+// BACKUP are already buffered in memory.
+
+// encryptionPreamble is a constant string prepended to encrypted files, which
+// allows them to be told apart from plaintext ones.
+var encryptionPreamble = []byte("encrypt")
+
+const (
+	// encryptionVersion is the version of the layout of encrypted files.
+	encryptionVersion byte = 1
+	// nonceSize is the size of the random nonce of each file.
+	nonceSize = 12
+	// encryptionSaltSize is the size of the salts returned by GenerateSalt.
+	encryptionSaltSize = 16
+	// EncryptionKeySize is the size of the keys used to encrypt files, for
+	// AES-256.
+	EncryptionKeySize = 32
+	// pbkdf2Iterations is the number of iterations used to derive a key from a
+	// passphrase.
+	pbkdf2Iterations = 64000
+)
+
+// GenerateSalt returns a new random salt for GenerateKey.
+func GenerateSalt() ([]byte, error) {
+	salt := make([]byte, encryptionSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	return salt, nil
+}
+
+// GenerateKey derives the encryption key of the given passphrase and salt.
+func GenerateKey(passphrase, salt []byte) []byte {
+	return pbkdf2.Key(passphrase, salt, pbkdf2Iterations, EncryptionKeySize, sha256.New)
+}
+
+// AppearsEncrypted returns true if the given file starts with the preamble of
+// encrypted files.
+func AppearsEncrypted(text []byte) bool {
+	return bytes.HasPrefix(text, encryptionPreamble)
+}
+
+func aesgcm(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// EncryptFile encrypts a file with the given key.
+func EncryptFile(plaintext, key []byte) ([]byte, error) {
+	gcm, err := aesgcm(key)
+	if err != nil {
+		return nil, err
+	}
+	headerSize := len(encryptionPreamble) + 1 + nonceSize
+	ciphertext := make([]byte, headerSize, headerSize+len(plaintext)+gcm.Overhead())
+	copy(ciphertext, encryptionPreamble)
+	ciphertext[len(encryptionPreamble)] = encryptionVersion
+	nonce := ciphertext[len(encryptionPreamble)+1:]
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(ciphertext, nonce, plaintext, nil), nil
+}
+
+// DecryptFile decrypts a file encrypted with EncryptFile, using the given key.
+func DecryptFile(ciphertext, key []byte) ([]byte, error) {
+	if !AppearsEncrypted(ciphertext) {
+		return nil, errors.New("file does not appear to be encrypted")
+	}
+	ciphertext = ciphertext[len(encryptionPreamble):]
+	if len(ciphertext) < 1+nonceSize {
+		return nil, errors.New("invalid encryption header")
+	}
+	if version := ciphertext[0]; version != encryptionVersion {
+		return nil, errors.Errorf("unexpected encryption scheme/config version %d", version)
+	}
+	gcm, err := aesgcm(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := ciphertext[1 : 1+nonceSize]
+	plaintext, err := gcm.Open(nil, nonce, ciphertext[1+nonceSize:], nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decrypt (wrong key?)")
+	}
+	return plaintext, nil
+}
@@ -0,0 +1,61 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package storageccl
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncryptDecrypt(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	salt, err := GenerateSalt()
+	require.NoError(t, err)
+	key := GenerateKey([]byte("passphrase"), salt)
+	require.Len(t, key, EncryptionKeySize)
+
+	for _, plaintext := range [][]byte{
+		nil,
+		[]byte("a"),
+		bytes.Repeat([]byte("0123456789"), 1000),
+	} {
+		ciphertext, err := EncryptFile(plaintext, key)
+		require.NoError(t, err)
+		require.True(t, AppearsEncrypted(ciphertext))
+		require.False(t, len(plaintext) > 0 && bytes.Contains(ciphertext, plaintext))
+
+		decrypted, err := DecryptFile(ciphertext, key)
+		require.NoError(t, err)
+		require.Equal(t, string(plaintext), string(decrypted))
+
+		// Another passphrase, or the same passphrase with another salt, derives
+		// another key which can't decrypt the file.
+		otherSalt, err := GenerateSalt()
+		require.NoError(t, err)
+		for _, otherKey := range [][]byte{
+			GenerateKey([]byte("other"), salt),
+			GenerateKey([]byte("passphrase"), otherSalt),
+		} {
+			_, err := DecryptFile(ciphertext, otherKey)
+			require.Error(t, err)
+		}
+
+		// Tampering with the ciphertext is detected.
+		ciphertext[len(ciphertext)-1] ^= 1
+		_, err = DecryptFile(ciphertext, key)
+		require.Error(t, err)
+	}
+
+	_, err = DecryptFile([]byte("plaintext"), key)
+	require.EqualError(t, err, "file does not appear to be encrypted")
+}
@@ -72,6 +72,9 @@ type encryptedFile struct {
 		syncutil.Mutex
 		rOffset int64
 		wOffset int64
+		// writeBuf holds the encrypted copy of the data being written, since
+		// io.Writer implementations must not modify the slice they are passed.
+		writeBuf []byte
 	}
 	stream FileStream
 }
@@ -80,8 +83,13 @@ type encryptedFile struct {
 func (f *encryptedFile) Write(p []byte) (n int, err error) {
 	f.mu.Lock()
 	defer f.mu.Unlock()
-	f.stream.Encrypt(f.mu.wOffset, p)
-	n, err = f.File.Write(p)
+	if cap(f.mu.writeBuf) < len(p) {
+		f.mu.writeBuf = make([]byte, len(p))
+	}
+	buf := f.mu.writeBuf[:len(p)]
+	copy(buf, p)
+	f.stream.Encrypt(f.mu.wOffset, buf)
+	n, err = f.File.Write(buf)
 	f.mu.wOffset += int64(n)
 	return n, err
 }
@@ -219,12 +227,12 @@ func (e *encryptionStatsHandler) GetEncryptionStatus() ([]byte, error) {
 	if k != nil {
 		s.ActiveDataKey = k.Info
 	}
-	return []byte(s.String()), nil
+	return protoutil.Marshal(&s)
 }
 
 func (e *encryptionStatsHandler) GetDataKeysRegistry() ([]byte, error) {
 	r := e.dataKM.getScrubbedRegistry()
-	return []byte(r.String()), nil
+	return protoutil.Marshal(r)
 }
 
 func (e *encryptionStatsHandler) GetActiveDataKeyID() (string, error) {
@@ -11,21 +11,28 @@ package engineccl
 import (
 	"bytes"
 	"context"
+	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"testing"
 
 	"github.com/cockroachdb/cockroach/pkg/base"
 	"github.com/cockroachdb/cockroach/pkg/ccl/baseccl"
+	"github.com/cockroachdb/cockroach/pkg/ccl/storageccl/engineccl/enginepbccl"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
 	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
+	"github.com/cockroachdb/cockroach/pkg/util/randutil"
 	"github.com/cockroachdb/pebble"
 	"github.com/cockroachdb/pebble/vfs"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/require"
 )
 
@@ -130,7 +137,11 @@ func TestEncryptedFS(t *testing.T) {
 		case "reuseForWrite":
 			g, err = fs.ReuseForWrite(s[1], s[2])
 		case "f.write":
-			_, err = f.Write([]byte(s[1]))
+			buf := []byte(s[1])
+			_, err = f.Write(buf)
+			if err == nil && string(buf) != s[1] {
+				t.Fatalf("%q: write modified its input into %q", tc, buf)
+			}
 		case "f.read":
 			n, _ := strconv.Atoi(s[1])
 			buf := make([]byte, n)
@@ -216,16 +227,20 @@ func TestPebbleEncryption(t *testing.T) {
 			Opts: opts,
 		})
 	require.NoError(t, err)
-	// Only exercising the stats code paths and not checking the returned values.
-	// TODO(sbhola): Make this better once stats are complete. Also ensure that we are
-	// not returning the secret data keys by mistake.
+	// The registries are serialized protos, as consumed by the debug
+	// encryption-status command, and don't contain the secret data keys.
 	r, err := db.GetEncryptionRegistries()
 	require.NoError(t, err)
-	t.Logf("FileRegistry:\n%s\n\n", r.FileRegistry)
-	t.Logf("KeyRegistry:\n%s\n\n", r.KeyRegistry)
-	stats, err := db.GetEnvStats()
-	require.NoError(t, err)
-	t.Logf("EnvStats:\n%+v\n\n", *stats)
+	var fileRegistry enginepb.FileRegistry
+	require.NoError(t, protoutil.Unmarshal(r.FileRegistry, &fileRegistry))
+	require.Contains(t, fileRegistry.Files, keyRegistryFilename)
+	var keyRegistry enginepbccl.DataKeysRegistry
+	require.NoError(t, protoutil.Unmarshal(r.KeyRegistry, &keyRegistry))
+	require.NotEmpty(t, keyRegistry.ActiveStoreKeyId)
+	require.Len(t, keyRegistry.DataKeys, 1)
+	for _, key := range keyRegistry.DataKeys {
+		require.Nil(t, key.Key)
+	}
 
 	batch := db.NewWriteOnlyBatch()
 	require.NoError(t, batch.Put(engine.MVCCKey{Key: roachpb.Key("a")}, []byte("a")))
@@ -234,6 +249,18 @@ func TestPebbleEncryption(t *testing.T) {
 	val, err := db.Get(engine.MVCCKey{Key: roachpb.Key("a")})
 	require.NoError(t, err)
 	require.Equal(t, "a", string(val))
+
+	stats, err := db.GetEnvStats()
+	require.NoError(t, err)
+	require.Equal(t, int32(enginepbccl.EncryptionType_AES128_CTR), stats.EncryptionType)
+	require.NotZero(t, stats.ActiveKeyFiles)
+	require.NotZero(t, stats.ActiveKeyBytes)
+	require.True(t, stats.ActiveKeyFiles < stats.TotalFiles)
+	require.True(t, stats.ActiveKeyBytes <= stats.TotalBytes)
+	var status enginepbccl.EncryptionStatus
+	require.NoError(t, protoutil.Unmarshal(stats.EncryptionStatus, &status))
+	require.Equal(t, keyRegistry.ActiveStoreKeyId, status.ActiveStoreKey.KeyId)
+	require.Equal(t, keyRegistry.ActiveDataKeyId, status.ActiveDataKey.KeyId)
 	db.Close()
 
 	opts2 := engine.DefaultPebbleOptions()
@@ -256,3 +283,149 @@ func TestPebbleEncryption(t *testing.T) {
 	require.Equal(t, "a", string(val))
 	db.Close()
 }
+
+// TestPebbleEncryptionStoreKeyRotation verifies that rotating the store key of
+// an encrypted Pebble rotates the data key, and that the files written with
+// the previous keys remain readable.
+func TestPebbleEncryptionStoreKeyRotation(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	memFS := vfs.NewMem()
+	writeToFile(t, memFS, "16.key", []byte(keyFile128))
+	writeToFile(t, memFS, "24.key", []byte(keyFile192))
+	openEngine := func(currentKey, oldKey string) *engine.Pebble {
+		encOptions := baseccl.EncryptionOptions{
+			KeySource:             baseccl.EncryptionKeySource_KeyFiles,
+			KeyFiles:              &baseccl.EncryptionKeyFiles{CurrentKey: currentKey, OldKey: oldKey},
+			DataKeyRotationPeriod: 1000,
+		}
+		encOptionsBytes, err := protoutil.Marshal(&encOptions)
+		require.NoError(t, err)
+		opts := engine.DefaultPebbleOptions()
+		opts.Cache = pebble.NewCache(1 << 20)
+		opts.FS = memFS
+		db, err := engine.NewPebble(context.Background(), engine.PebbleConfig{
+			StorageConfig: base.StorageConfig{
+				MaxSize:         512 << 20,
+				UseFileRegistry: true,
+				ExtraOptions:    encOptionsBytes,
+			},
+			Opts: opts,
+		})
+		require.NoError(t, err)
+		return db
+	}
+	keyRegistry := func(db *engine.Pebble) *enginepbccl.DataKeysRegistry {
+		r, err := db.GetEncryptionRegistries()
+		require.NoError(t, err)
+		var keyRegistry enginepbccl.DataKeysRegistry
+		require.NoError(t, protoutil.Unmarshal(r.KeyRegistry, &keyRegistry))
+		return &keyRegistry
+	}
+
+	db := openEngine("16.key", "plain")
+	require.NoError(t, db.Put(engine.MVCCKey{Key: roachpb.Key("a")}, []byte("a")))
+	require.NoError(t, db.Flush())
+	before := keyRegistry(db)
+	db.Close()
+
+	db = openEngine("24.key", "16.key")
+	defer db.Close()
+	after := keyRegistry(db)
+	require.NotEqual(t, before.ActiveStoreKeyId, after.ActiveStoreKeyId)
+	require.NotEqual(t, before.ActiveDataKeyId, after.ActiveDataKeyId)
+	require.Len(t, after.StoreKeys, 2)
+	require.Len(t, after.DataKeys, 2)
+	require.Equal(t, enginepbccl.EncryptionType_AES192_CTR,
+		after.StoreKeys[after.ActiveStoreKeyId].EncryptionType)
+	require.Equal(t, after.ActiveStoreKeyId, after.DataKeys[after.ActiveDataKeyId].Info.ParentKeyId)
+
+	val, err := db.Get(engine.MVCCKey{Key: roachpb.Key("a")})
+	require.NoError(t, err)
+	require.Equal(t, "a", string(val))
+}
+
+// TestPebbleTempEngineEncryption verifies that the Pebble temp engine of a
+// store with encryption at rest enabled encrypts the files it writes.
+func TestPebbleTempEngineEncryption(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	dir, cleanup := testutils.TempDir(t)
+	defer cleanup()
+
+	keyPath := filepath.Join(dir, "16.key")
+	writeToFile(t, vfs.Default, keyPath, []byte(keyFile128))
+	var encOptions baseccl.EncryptionOptions
+	encOptions.KeySource = baseccl.EncryptionKeySource_KeyFiles
+	encOptions.KeyFiles = &baseccl.EncryptionKeyFiles{
+		CurrentKey: keyPath,
+		OldKey:     "plain",
+	}
+	encOptions.DataKeyRotationPeriod = 1000 // arbitrary seconds
+	encOptionsBytes, err := protoutil.Marshal(&encOptions)
+	require.NoError(t, err)
+
+	tempDir := filepath.Join(dir, "temp")
+	tempEngine, err := engine.NewPebbleTempEngine(
+		base.TempStorageConfig{Path: tempDir},
+		base.StoreSpec{UseFileRegistry: true, ExtraOptions: encOptionsBytes},
+	)
+	require.NoError(t, err)
+	defer tempEngine.Close()
+
+	// Write more than a memtable's worth of data, so that it gets flushed to
+	// sstables.
+	const keyPrefix = "plaintext-key-"
+	const numKeys = 1100
+	value := make([]byte, 64<<10)
+	rng, _ := randutil.NewPseudoRand()
+	diskMap := tempEngine.NewSortedDiskMap()
+	defer diskMap.Close(context.Background())
+	batch := diskMap.NewBatchWriter()
+	for i := 0; i < numKeys; i++ {
+		_, _ = rng.Read(value)
+		require.NoError(t, batch.Put([]byte(fmt.Sprintf("%s%06d", keyPrefix, i)), value))
+	}
+	require.NoError(t, batch.Close(context.Background()))
+
+	testutils.SucceedsSoon(t, func() error {
+		matches, err := filepath.Glob(filepath.Join(tempDir, "*.sst"))
+		if err != nil {
+			return err
+		}
+		if len(matches) == 0 {
+			return errors.New("no sstables written yet")
+		}
+		return nil
+	})
+
+	// Neither the keys written to the temp engine nor the comparer name recorded
+	// in its MANIFEST appear in plaintext on disk.
+	files, err := ioutil.ReadDir(tempDir)
+	require.NoError(t, err)
+	for _, f := range files {
+		if f.IsDir() {
+			continue
+		}
+		contents, err := ioutil.ReadFile(filepath.Join(tempDir, f.Name()))
+		require.NoError(t, err)
+		require.False(t, bytes.Contains(contents, []byte(keyPrefix)),
+			"found plaintext key in %s", f.Name())
+		require.False(t, bytes.Contains(contents, []byte(pebble.DefaultComparer.Name)),
+			"found plaintext comparer name in %s", f.Name())
+	}
+
+	// The data can still be read back through the temp engine.
+	it := diskMap.NewIterator()
+	defer it.Close()
+	var n int
+	for it.Rewind(); ; it.Next() {
+		ok, err := it.Valid()
+		require.NoError(t, err)
+		if !ok {
+			break
+		}
+		n++
+	}
+	require.Equal(t, numKeys, n)
+}
@@ -160,7 +160,16 @@ func evalExport(
 
 	if exportStore != nil {
 		exported.Path = fmt.Sprintf("%d.sst", builtins.GenerateUniqueInt(cArgs.EvalCtx.NodeID()))
-		if err := exportStore.WriteFile(ctx, exported.Path, bytes.NewReader(data)); err != nil {
+		// The checksum is of the plaintext, so that it's verified after the file
+		// is decrypted.
+		payload := data
+		if args.Encryption != nil {
+			payload, err = EncryptFile(data, args.Encryption.Key)
+			if err != nil {
+				return result.Result{}, err
+			}
+		}
+		if err := exportStore.WriteFile(ctx, exported.Path, bytes.NewReader(payload)); err != nil {
 			return result.Result{}, err
 		}
 	}
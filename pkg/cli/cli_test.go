@@ -2176,6 +2176,7 @@ writing ` + os.DevNull + `
   debug/liveness.json
   debug/settings.json
   debug/reports/problemranges.json
+  debug/crdb_internal.cluster_contention_events.txt
   debug/crdb_internal.cluster_queries.txt
   debug/crdb_internal.cluster_sessions.txt
   debug/crdb_internal.cluster_settings.txt
@@ -2188,6 +2189,7 @@ writing ` + os.DevNull + `
   debug/crdb_internal.schema_changes.txt
   debug/crdb_internal.partitions.txt
   debug/crdb_internal.zones.txt
+  debug/crdb_internal.protected_spans.txt
   debug/nodes/1/status.json
   debug/nodes/1/crdb_internal.feature_usage.txt
   debug/nodes/1/crdb_internal.gossip_alerts.txt
@@ -2201,6 +2203,7 @@ writing ` + os.DevNull + `
   debug/nodes/1/crdb_internal.node_runtime_info.txt
   debug/nodes/1/crdb_internal.node_sessions.txt
   debug/nodes/1/crdb_internal.node_statement_statistics.txt
+  debug/nodes/1/crdb_internal.node_transaction_statistics.txt
   debug/nodes/1/crdb_internal.node_txn_stats.txt
   debug/nodes/1/details.json
   debug/nodes/1/gossip.json
@@ -2235,6 +2238,12 @@ writing ` + os.DevNull + `
   debug/nodes/1/ranges/26.json
   debug/nodes/1/ranges/27.json
   debug/nodes/1/ranges/28.json
+  debug/nodes/1/ranges/29.json
+  debug/nodes/1/ranges/30.json
+  debug/nodes/1/ranges/31.json
+  debug/nodes/1/ranges/32.json
+  debug/nodes/1/ranges/33.json
+  debug/nodes/1/ranges/34.json
   debug/schema/defaultdb@details.json
   debug/schema/postgres@details.json
   debug/schema/system@details.json
@@ -2254,7 +2263,13 @@ writing ` + os.DevNull + `
   debug/schema/system/replication_stats.json
   debug/schema/system/reports_meta.json
   debug/schema/system/role_members.json
+  debug/schema/system/scheduled_jobs.json
   debug/schema/system/settings.json
+  debug/schema/system/statement_bundle_chunks.json
+  debug/schema/system/statement_diagnostics.json
+  debug/schema/system/statement_diagnostics_requests.json
+  debug/schema/system/statement_plan_pins.json
+  debug/schema/system/statement_statistics.json
   debug/schema/system/table_statistics.json
   debug/schema/system/ui.json
   debug/schema/system/users.json
@@ -108,6 +108,7 @@ func initCLIDefaults() {
 	serverCfg.JoinList = nil
 	serverCfg.DefaultZoneConfig = config.DefaultZoneConfig()
 	serverCfg.DefaultSystemZoneConfig = config.DefaultSystemZoneConfig()
+	serverCfg.StorageEngine = engine.DefaultStorageEngine
 	// Attempt to default serverCfg.SQLMemoryPoolSize to 25% if possible.
 	if bytes, _ := memoryPercentResolver(25); bytes != 0 {
 		serverCfg.SQLMemoryPoolSize = bytes
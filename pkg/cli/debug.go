@@ -17,6 +17,7 @@ import (
 	gohex "encoding/hex"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"math"
 	"os"
 	"path/filepath"
@@ -27,6 +28,7 @@ import (
 	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/cli/cliflags"
 	"github.com/cockroachdb/cockroach/pkg/cli/syncbench"
 	"github.com/cockroachdb/cockroach/pkg/config"
 	"github.com/cockroachdb/cockroach/pkg/gossip"
@@ -111,7 +113,7 @@ type OpenEngineOptions struct {
 	MustExist bool
 }
 
-// OpenExistingStore opens the rocksdb engine rooted at 'dir'.
+// OpenExistingStore opens the engine rooted at 'dir'.
 // If 'readOnly' is true, opens the store in read-only mode.
 func OpenExistingStore(dir string, stopper *stop.Stopper, readOnly bool) (engine.Engine, error) {
 	return OpenEngine(dir, stopper, OpenEngineOptions{ReadOnly: readOnly, MustExist: true})
@@ -138,7 +140,7 @@ func OpenEngine(dir string, stopper *stop.Stopper, opts OpenEngineOptions) (engi
 
 	var db engine.Engine
 
-	switch engine.DefaultStorageEngine {
+	switch serverCfg.StorageEngine {
 	case enginepb.EngineTypePebble:
 		cfg := engine.PebbleConfig{
 			StorageConfig: storageConfig,
@@ -161,6 +163,9 @@ func OpenEngine(dir string, stopper *stop.Stopper, opts OpenEngineOptions) (engi
 		}
 
 		db, err = engine.NewRocksDB(cfg, cache)
+
+	default:
+		return nil, errors.Errorf("unsupported storage engine %s for debug commands", serverCfg.StorageEngine.String())
 	}
 
 	if err != nil {
@@ -579,15 +584,28 @@ var debugRocksDBCmd = &cobra.Command{
 	Long: `
 Runs the RocksDB 'ldb' tool, which provides various subcommands for examining
 raw store data. 'cockroach debug rocksdb' accepts the same arguments and flags
-as 'ldb'.
+as 'ldb'. On stores using the Pebble storage engine, the scan, dump,
+approxsize, checkconsistency, list_live_files_metadata and manifest_dump
+subcommands run the equivalent 'cockroach debug pebble' commands.
 
 https://github.com/facebook/rocksdb/wiki/Administration-and-Data-Access-Tool#ldb-tool
 `,
 	// LDB does its own flag parsing.
 	// TODO(mberhault): support encrypted stores.
 	DisableFlagParsing: true,
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
+		args, isPebble, err := parseRocksDBToolArgs("--db", args)
+		if err != nil {
+			return err
+		}
+		if isPebble {
+			if args, err = ldbToPebbleArgs(args); err != nil {
+				return err
+			}
+			return runDebugPebble(args)
+		}
 		engine.RunLDB(args)
+		return nil
 	},
 }
 
@@ -603,16 +621,242 @@ var debugSSTDumpCmd = &cobra.Command{
 	Use:   "sst_dump",
 	Short: "run the RocksDB 'sst_dump' tool",
 	Long: `
-Runs the RocksDB 'sst_dump' tool
+Runs the RocksDB 'sst_dump' tool. On the sstables of stores using the Pebble
+storage engine, the scan, check, verify and raw commands and the
+--show_properties flag run the equivalent 'cockroach debug pebble sstable'
+commands.
 `,
 	// sst_dump does its own flag parsing.
 	// TODO(mberhault): support encrypted stores.
 	DisableFlagParsing: true,
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
+		args, isPebble, err := parseRocksDBToolArgs("--file", args)
+		if err != nil {
+			return err
+		}
+		if isPebble {
+			if args, err = sstDumpToPebbleArgs(args); err != nil {
+				return err
+			}
+			return runDebugPebble(args)
+		}
 		engine.RunSSTDump(args)
+		return nil
 	},
 }
 
+// parseRocksDBToolArgs returns the arguments of a RocksDB tool without the
+// --storage-engine flag, since the RocksDB tools do their own flag parsing,
+// and whether the tool is run on a Pebble store: either the flag asks for the
+// Pebble storage engine, or the store or sstable given by pathFlag was
+// written by Pebble.
+func parseRocksDBToolArgs(pathFlag string, args []string) ([]string, bool, error) {
+	storageEngine := enginepb.EngineTypeRocksDB
+	flagName := "--" + cliflags.StorageEngine.Name
+	var path string
+	toolArgs := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if strings.HasPrefix(arg, pathFlag+"=") {
+			path = strings.TrimPrefix(arg, pathFlag+"=")
+		}
+		switch {
+		case arg == flagName:
+			if i+1 == len(args) {
+				return nil, false, errors.Errorf("flag needs an argument: %s", flagName)
+			}
+			i++
+			if err := storageEngine.Set(args[i]); err != nil {
+				return nil, false, err
+			}
+		case strings.HasPrefix(arg, flagName+"="):
+			if err := storageEngine.Set(strings.TrimPrefix(arg, flagName+"=")); err != nil {
+				return nil, false, err
+			}
+		default:
+			toolArgs = append(toolArgs, arg)
+		}
+	}
+	if path != "" && isPebbleDir(path) {
+		storageEngine = enginepb.EngineTypePebble
+	}
+	return toolArgs, storageEngine == enginepb.EngineTypePebble, nil
+}
+
+// splitRocksDBToolArgs splits the arguments of a RocksDB tool into its
+// --name=value flags, keyed by name, and its other arguments.
+func splitRocksDBToolArgs(args []string) (map[string]string, []string) {
+	flags := make(map[string]string)
+	var positional []string
+	for _, arg := range args {
+		if !strings.HasPrefix(arg, "--") {
+			positional = append(positional, arg)
+			continue
+		}
+		name, value := strings.TrimPrefix(arg, "--"), ""
+		if i := strings.IndexByte(name, '='); i >= 0 {
+			name, value = name[:i], name[i+1:]
+		}
+		flags[name] = value
+	}
+	return flags, positional
+}
+
+// pebbleKeyRangeArgs translates the --from and --to flags of a RocksDB tool
+// into the --start and --end flags of a Pebble tool, and removes them from
+// flags along with the flags giving their encoding.
+func pebbleKeyRangeArgs(flags map[string]string) []string {
+	prefix := ""
+	for _, hexFlag := range []string{"hex", "key_hex"} {
+		if _, ok := flags[hexFlag]; ok {
+			prefix = "hex:"
+			delete(flags, hexFlag)
+		}
+	}
+	var args []string
+	if v, ok := flags["from"]; ok {
+		args = append(args, "--start="+prefix+v)
+		delete(flags, "from")
+	}
+	if v, ok := flags["to"]; ok {
+		args = append(args, "--end="+prefix+v)
+		delete(flags, "to")
+	}
+	return args
+}
+
+// unsupportedPebbleToolFlags returns an error naming the remaining flags of a
+// RocksDB tool, which have no equivalent in the Pebble tool.
+func unsupportedPebbleToolFlags(flags map[string]string, pebbleTool string) error {
+	if len(flags) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(flags))
+	for name := range flags {
+		names = append(names, "--"+name)
+	}
+	sort.Strings(names)
+	return errors.Errorf("%s not supported on the pebble storage engine; use 'cockroach debug %s' instead",
+		strings.Join(names, ", "), pebbleTool)
+}
+
+// ldbToPebbleArgs translates the arguments of the 'ldb' tool into those of
+// the equivalent 'cockroach debug pebble' command.
+func ldbToPebbleArgs(args []string) ([]string, error) {
+	flags, positional := splitRocksDBToolArgs(args)
+	dir := flags["db"]
+	delete(flags, "db")
+	if len(positional) != 1 {
+		return nil, errors.Errorf("expected a single ldb command, got %q", positional)
+	}
+	var pebbleArgs []string
+	switch command := positional[0]; command {
+	case "scan", "dump":
+		pebbleArgs = append([]string{"db", "scan", dir}, pebbleKeyRangeArgs(flags)...)
+	case "approxsize":
+		pebbleArgs = append([]string{"db", "space", dir}, pebbleKeyRangeArgs(flags)...)
+	case "checkconsistency":
+		pebbleArgs = []string{"db", "check", dir}
+	case "list_live_files_metadata":
+		pebbleArgs = []string{"db", "lsm", dir}
+	case "manifest_dump":
+		current, err := ioutil.ReadFile(filepath.Join(dir, "CURRENT"))
+		if err != nil {
+			return nil, err
+		}
+		manifest := filepath.Join(dir, strings.TrimSpace(string(current)))
+		pebbleArgs = []string{"manifest", "dump", manifest}
+	default:
+		return nil, errors.Errorf("ldb command %q is not supported on the pebble storage engine; "+
+			"use 'cockroach debug pebble db' instead", command)
+	}
+	if err := unsupportedPebbleToolFlags(flags, "pebble db"); err != nil {
+		return nil, err
+	}
+	return pebbleArgs, nil
+}
+
+// sstDumpToPebbleArgs translates the arguments of the 'sst_dump' tool into
+// those of the equivalent 'cockroach debug pebble sstable' command.
+func sstDumpToPebbleArgs(args []string) ([]string, error) {
+	flags, positional := splitRocksDBToolArgs(args)
+	if len(positional) != 0 {
+		return nil, errors.Errorf("unexpected arguments %q", positional)
+	}
+	file := flags["file"]
+	command := flags["command"]
+	delete(flags, "file")
+	delete(flags, "command")
+	if _, ok := flags["show_properties"]; ok {
+		delete(flags, "show_properties")
+		if command == "" || command == "none" {
+			command = "properties"
+		}
+	}
+	var pebbleArgs []string
+	switch command {
+	case "", "scan":
+		pebbleArgs = append([]string{"sstable", "scan", file}, pebbleKeyRangeArgs(flags)...)
+	case "check", "verify":
+		pebbleArgs = []string{"sstable", "check", file}
+	case "raw":
+		pebbleArgs = []string{"sstable", "layout", file}
+	case "properties":
+		pebbleArgs = []string{"sstable", "properties", file}
+	default:
+		return nil, errors.Errorf("sst_dump command %q is not supported on the pebble storage engine; "+
+			"use 'cockroach debug pebble sstable' instead", command)
+	}
+	if err := unsupportedPebbleToolFlags(flags, "pebble sstable"); err != nil {
+		return nil, err
+	}
+	return pebbleArgs, nil
+}
+
+// runDebugPebble runs the 'cockroach debug pebble' command given by args.
+func runDebugPebble(args []string) error {
+	cmd, rest, err := debugPebbleCmd.Find(args)
+	if err != nil {
+		return err
+	}
+	if err := cmd.ParseFlags(rest); err != nil {
+		return err
+	}
+	rest = cmd.Flags().Args()
+	if err := cmd.ValidateArgs(rest); err != nil {
+		return err
+	}
+	switch {
+	case cmd.RunE != nil:
+		return cmd.RunE(cmd, rest)
+	case cmd.Run != nil:
+		cmd.Run(cmd, rest)
+		return nil
+	default:
+		return errors.Errorf("unknown command %q", strings.Join(args, " "))
+	}
+}
+
+// isPebbleDir returns whether the store containing the path, which is either
+// the store directory or a file in it, was written by Pebble. Unlike RocksDB,
+// Pebble records its version in the store's OPTIONS files.
+func isPebbleDir(path string) bool {
+	dir := path
+	if info, err := os.Stat(path); err == nil && !info.IsDir() {
+		dir = filepath.Dir(path)
+	}
+	matches, err := filepath.Glob(filepath.Join(dir, "OPTIONS-*"))
+	if err != nil {
+		return false
+	}
+	for _, m := range matches {
+		if b, err := ioutil.ReadFile(m); err == nil && bytes.Contains(b, []byte("pebble_version")) {
+			return true
+		}
+	}
+	return false
+}
+
 var debugEnvCmd = &cobra.Command{
 	Use:   "env",
 	Short: "output environment settings",
@@ -959,7 +1203,12 @@ func runDebugUnsafeRemoveDeadReplicas(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 	defer batch.Close()
+	return promptAndCommit(batch)
+}
 
+// promptAndCommit asks the user for confirmation before committing the batch
+// of rewrites performed by an unsafe debug command.
+func promptAndCommit(batch engine.Batch) error {
 	fmt.Printf("Proceed with the above rewrites? [y/N] ")
 
 	reader := bufio.NewReader(os.Stdin)
@@ -1059,7 +1308,14 @@ func removeDeadReplicas(
 	if len(newDescs) == 0 {
 		return nil, nil
 	}
+	return rewriteRangeDescriptors(ctx, db, clock, newDescs)
+}
 
+// rewriteRangeDescriptors returns a batch overwriting the range-local copies
+// of the given range descriptors, which were stripped of their dead replicas.
+func rewriteRangeDescriptors(
+	ctx context.Context, db engine.Engine, clock *hlc.Clock, newDescs []roachpb.RangeDescriptor,
+) (engine.Batch, error) {
 	batch := db.NewBatch()
 	for _, desc := range newDescs {
 		// Write the rewritten descriptor to the range-local descriptor
@@ -1186,8 +1442,8 @@ func runDebugMergeLogs(cmd *cobra.Command, args []string) error {
 	return writeLogStream(s, cmd.OutOrStdout(), o.filter, o.prefix)
 }
 
-// DebugCmdsForRocksDB lists debug commands that access rocksdb through the engine
-// and need encryption flags (injected by CCL code).
+// DebugCmdsForRocksDB lists debug commands that access the store through the engine
+// and need encryption flags (injected by CCL code) and the storage engine flag.
 // Note: do NOT include commands that just call rocksdb code without setting up an engine.
 var DebugCmdsForRocksDB = []*cobra.Command{
 	debugCheckStoreCmd,
@@ -1212,6 +1468,7 @@ var debugCmds = append(DebugCmdsForRocksDB,
 	debugSyncBenchCmd,
 	debugSyncTestCmd,
 	debugUnsafeRemoveDeadReplicasCmd,
+	debugRecoverCmd,
 	debugEnvCmd,
 	debugZipCmd,
 	debugMergeLogsCommand,
@@ -1240,6 +1497,7 @@ func init() {
 	pebbleTool.RegisterComparer(engine.MVCCComparer)
 	debugPebbleCmd.AddCommand(pebbleTool.Commands...)
 	DebugCmd.AddCommand(debugPebbleCmd)
+	debugRecoverCmd.AddCommand(debugRecoverCmds...)
 
 	f := debugSyncBenchCmd.Flags()
 	f.IntVarP(&syncBenchOpts.Concurrency, "concurrency", "c", syncBenchOpts.Concurrency,
@@ -0,0 +1,561 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/storage"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine"
+	"github.com/cockroachdb/cockroach/pkg/storage/stateloader"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+var debugRecoverCmd = &cobra.Command{
+	Use:   "recover [command]",
+	Short: "commands to recover unavailable ranges after losing a quorum of replicas",
+	Long: `
+Commands to recover ranges which lost a majority of their replicas.
+
+These commands are UNSAFE and should only be used with the supervision of
+a Cockroach Labs engineer. They are a last-resort option to recover data
+after multiple node failures. The recovered data is not guaranteed to
+be consistent.
+
+Unlike unsafe-remove-dead-replicas, which operates on one store at a time,
+recovery is planned with a view of the whole cluster:
+
+1. Stop all the surviving nodes and run 'collect-info' on each of them,
+   for all of their stores.
+2. Gather the resulting files and run 'make-plan' on them. For each range
+   which can't make progress, the surviving replica with the most
+   up-to-date state is chosen to be the range's only replica. The plan
+   should be reviewed before proceeding.
+3. Run 'apply-plan' with the plan on each store of the surviving nodes, and
+   restart them.
+
+The dead stores must be lost and unrecoverable. If they were to rejoin the
+cluster after the plan was applied, data may be corrupted.
+`,
+	RunE: usageAndErr,
+}
+
+var debugRecoverCollectInfoCmd = &cobra.Command{
+	Use:   "collect-info [store path...]",
+	Short: "collect the replicas of the given stores for recovery planning",
+	Long: `
+Collects the descriptors and raft indexes of all the replicas found on the
+given stores, and prints them to standard output for use by 'make-plan'. The
+node must be stopped.
+`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: MaybeDecorateGRPCError(runDebugRecoverCollectInfo),
+}
+
+var debugRecoverMakePlanCmd = &cobra.Command{
+	Use:   "make-plan [info file...]",
+	Short: "plan the recovery of the ranges which lost quorum",
+	Long: `
+Reads the replicas collected from all the surviving stores by 'collect-info',
+and prints a plan to standard output which, for each range that lost a
+majority of its replicas, designates the surviving replica with the most
+up-to-date state as the range's only replica. Stores which weren't collected
+are considered dead.
+
+Surviving replicas are ranked by descriptor generation, then by raft applied
+index, with ties broken by the highest store ID, so that the same information
+always yields the same plan. The designated replica may be a learner or a
+non-voter, in which case it may be missing committed writes; the plan warns
+about it.
+
+If the survivors disagree on range boundaries, because splits or merges weren't
+applied on all of them, the descriptors with the highest generation are kept
+and the ranges overlapping them are skipped. The plan lists the skipped ranges
+and the spans of the keyspace left without any replica, whose data is lost.
+`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runDebugRecoverMakePlan,
+}
+
+var debugRecoverApplyPlanCmd = &cobra.Command{
+	Use:   "apply-plan <plan file> <store path>",
+	Short: "apply a recovery plan to a store",
+	Long: `
+Rewrites the descriptors of the ranges whose designated survivor lives on the
+given store, as instructed by the plan produced by 'make-plan'. The node must
+be stopped. This command will prompt for confirmation before committing its
+changes.
+
+The descriptors are checked against those seen when the plan was made, so that
+a plan made from outdated information, or already applied, doesn't resurrect a
+range twice.
+
+After this command is used, the node should not be restarted until at least 10
+seconds have passed since it was stopped.
+`,
+	Args: cobra.ExactArgs(2),
+	RunE: MaybeDecorateGRPCError(runDebugRecoverApplyPlan),
+}
+
+var debugRecoverCmds = []*cobra.Command{
+	debugRecoverCollectInfoCmd,
+	debugRecoverMakePlanCmd,
+	debugRecoverApplyPlanCmd,
+}
+
+// recoveryReplicaInfo describes a replica found on a surviving store.
+type recoveryReplicaInfo struct {
+	NodeID             roachpb.NodeID
+	StoreID            roachpb.StoreID
+	Desc               roachpb.RangeDescriptor
+	RaftAppliedIndex   uint64
+	RaftCommittedIndex uint64
+}
+
+// recoveryInfo is produced by collect-info for the stores of a node.
+type recoveryInfo struct {
+	Replicas []recoveryReplicaInfo
+}
+
+// recoveryUpdate instructs a store to rewrite the descriptor of a range, so
+// that its replica becomes the only member of the range.
+type recoveryUpdate struct {
+	RangeID  roachpb.RangeID
+	StartKey roachpb.RKey
+	NodeID   roachpb.NodeID
+	StoreID  roachpb.StoreID
+	// OldReplicaID is the ID of the surviving replica. It is replaced by
+	// NewReplicaID, so that the survivors which aren't in sync with the
+	// resurrected replica no longer recognize it.
+	OldReplicaID roachpb.ReplicaID
+	// OldReplicaType is the type of the surviving replica. If it isn't a voter,
+	// it may be missing writes which the range committed.
+	OldReplicaType roachpb.ReplicaType
+	NewReplicaID   roachpb.ReplicaID
+	// Generation is the generation of the surviving replica's descriptor.
+	Generation int64
+	// Desc is the surviving replica's descriptor, for review only.
+	Desc string
+}
+
+// promotesNonVoter returns whether the update makes a replica which wasn't a
+// voter the range's only voter.
+func (u recoveryUpdate) promotesNonVoter() bool {
+	return u.OldReplicaType == roachpb.LEARNER || u.OldReplicaType == roachpb.NON_VOTER
+}
+
+// recoverySkippedRange is a range which the plan doesn't recover because its
+// surviving descriptor overlaps the descriptor of another range.
+type recoverySkippedRange struct {
+	RangeID roachpb.RangeID
+	// Desc is the surviving descriptor of the range, for review only.
+	Desc   string
+	Reason string
+}
+
+// recoveryPlan is produced by make-plan and applied to each surviving store
+// by apply-plan.
+type recoveryPlan struct {
+	PlanID        uuid.UUID
+	DeadStoreIDs  []roachpb.StoreID
+	Updates       []recoveryUpdate
+	SkippedRanges []recoverySkippedRange
+	// LostSpans are the spans of the keyspace which no recovered or healthy
+	// range covers. Their data is lost.
+	LostSpans []roachpb.RSpan
+}
+
+func runDebugRecoverCollectInfo(cmd *cobra.Command, args []string) error {
+	stopper := stop.NewStopper()
+	defer stopper.Stop(context.Background())
+
+	var info recoveryInfo
+	for _, dir := range args {
+		db, err := OpenExistingStore(dir, stopper, true /* readOnly */)
+		if err != nil {
+			return errors.Wrapf(err, "opening store %s", dir)
+		}
+		replicas, err := collectRecoveryInfo(context.Background(), db)
+		if err != nil {
+			return errors.Wrapf(err, "collecting replicas of store %s", dir)
+		}
+		info.Replicas = append(info.Replicas, replicas...)
+	}
+	return writeRecoveryJSON(cmd.OutOrStdout(), info)
+}
+
+// collectRecoveryInfo returns the replicas found on the store.
+func collectRecoveryInfo(ctx context.Context, db engine.Engine) ([]recoveryReplicaInfo, error) {
+	storeIdent, err := storage.ReadStoreIdent(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	var replicas []recoveryReplicaInfo
+	err = storage.IterateRangeDescriptors(ctx, db, func(desc roachpb.RangeDescriptor) (bool, error) {
+		if _, ok := desc.GetReplicaDescriptor(storeIdent.StoreID); !ok {
+			// The replica was removed from the range and awaits GC.
+			return false, nil
+		}
+		sl := stateloader.Make(desc.RangeID)
+		appliedIndex, _, err := sl.LoadAppliedIndex(ctx, db)
+		if err != nil {
+			return false, errors.Wrapf(err, "loading applied index of r%d", desc.RangeID)
+		}
+		hs, err := sl.LoadHardState(ctx, db)
+		if err != nil {
+			return false, errors.Wrapf(err, "loading hard state of r%d", desc.RangeID)
+		}
+		replicas = append(replicas, recoveryReplicaInfo{
+			NodeID:             storeIdent.NodeID,
+			StoreID:            storeIdent.StoreID,
+			Desc:               desc,
+			RaftAppliedIndex:   appliedIndex,
+			RaftCommittedIndex: hs.Commit,
+		})
+		return false, nil
+	})
+	return replicas, err
+}
+
+func runDebugRecoverMakePlan(cmd *cobra.Command, args []string) error {
+	var replicas []recoveryReplicaInfo
+	for _, path := range args {
+		var info recoveryInfo
+		if err := readRecoveryJSON(path, &info); err != nil {
+			return err
+		}
+		replicas = append(replicas, info.Replicas...)
+	}
+	plan := makeRecoveryPlan(replicas)
+	fmt.Fprintf(os.Stderr, "Stores considered dead: %v\n", plan.DeadStoreIDs)
+	for _, u := range plan.Updates {
+		fmt.Fprintf(os.Stderr, "r%d: recovering from replica %d on s%d (n%d) as replica %d\n",
+			u.RangeID, u.OldReplicaID, u.StoreID, u.NodeID, u.NewReplicaID)
+		if u.promotesNonVoter() {
+			fmt.Fprintf(os.Stderr, "r%d: WARNING: replica %d is a %s, which is promoted to the "+
+				"only voter; it may be missing committed writes\n", u.RangeID, u.OldReplicaID, u.OldReplicaType)
+		}
+	}
+	for _, r := range plan.SkippedRanges {
+		fmt.Fprintf(os.Stderr, "r%d: WARNING: not recovered: %s\n", r.RangeID, r.Reason)
+	}
+	for _, span := range plan.LostSpans {
+		fmt.Fprintf(os.Stderr, "WARNING: no surviving replica covers %s; its data is lost\n", span)
+	}
+	if len(plan.Updates) == 0 {
+		fmt.Fprintf(os.Stderr, "Nothing to do\n")
+	}
+	return writeRecoveryJSON(cmd.OutOrStdout(), plan)
+}
+
+// makeRecoveryPlan plans the recovery of the ranges which can't make progress
+// with the surviving replicas, i.e. those collected. For each of them, the
+// surviving replica with the most up-to-date state is designated as the only
+// replica of the range.
+//
+// The survivors may disagree on the boundaries of the ranges, when splits or
+// merges weren't applied on all of them. The most recent descriptors, i.e.
+// those with the highest generation, are kept, and the ranges whose
+// descriptors overlap them are reported as skipped. The parts of the keyspace
+// that aren't covered by the kept descriptors are reported as lost.
+func makeRecoveryPlan(replicas []recoveryReplicaInfo) recoveryPlan {
+	live := map[roachpb.StoreID]struct{}{}
+	for _, r := range replicas {
+		live[r.StoreID] = struct{}{}
+	}
+
+	// Rank the replicas of each range, best first. The ranking is total so
+	// that the plan is deterministic.
+	byRange := map[roachpb.RangeID][]recoveryReplicaInfo{}
+	for _, r := range replicas {
+		byRange[r.Desc.RangeID] = append(byRange[r.Desc.RangeID], r)
+	}
+	best := make([]recoveryReplicaInfo, 0, len(byRange))
+	for _, rs := range byRange {
+		sort.Slice(rs, func(i, j int) bool {
+			if gi, gj := rs[i].Desc.GetGeneration(), rs[j].Desc.GetGeneration(); gi != gj {
+				return gi > gj
+			}
+			if rs[i].RaftAppliedIndex != rs[j].RaftAppliedIndex {
+				return rs[i].RaftAppliedIndex > rs[j].RaftAppliedIndex
+			}
+			// Of replicas that applied the same entries, prefer the one that
+			// knows more of the log to be committed: it holds committed
+			// entries the others may lack, which it applies once recovered.
+			if rs[i].RaftCommittedIndex != rs[j].RaftCommittedIndex {
+				return rs[i].RaftCommittedIndex > rs[j].RaftCommittedIndex
+			}
+			return rs[i].StoreID > rs[j].StoreID
+		})
+		best = append(best, rs[0])
+	}
+
+	plan := recoveryPlan{PlanID: uuid.MakeV4()}
+	dead := map[roachpb.StoreID]struct{}{}
+	for _, r := range best {
+		for _, rd := range r.Desc.Replicas().All() {
+			if _, ok := live[rd.StoreID]; !ok {
+				dead[rd.StoreID] = struct{}{}
+			}
+		}
+	}
+	for storeID := range dead {
+		plan.DeadStoreIDs = append(plan.DeadStoreIDs, storeID)
+	}
+	sort.Slice(plan.DeadStoreIDs, func(i, j int) bool {
+		return plan.DeadStoreIDs[i] < plan.DeadStoreIDs[j]
+	})
+
+	kept, skipped := resolveRecoveryOverlaps(best)
+	plan.SkippedRanges = skipped
+	plan.LostSpans = findLostSpans(kept)
+
+	for _, r := range kept {
+		desc := r.Desc
+		if desc.Replicas().CanMakeProgress(func(rd roachpb.ReplicaDescriptor) bool {
+			_, ok := live[rd.StoreID]
+			return ok
+		}) {
+			continue
+		}
+		rd, _ := desc.GetReplicaDescriptor(r.StoreID)
+		plan.Updates = append(plan.Updates, recoveryUpdate{
+			RangeID:        desc.RangeID,
+			StartKey:       desc.StartKey,
+			NodeID:         r.NodeID,
+			StoreID:        r.StoreID,
+			OldReplicaID:   rd.ReplicaID,
+			OldReplicaType: rd.GetType(),
+			NewReplicaID:   desc.NextReplicaID,
+			Generation:     desc.GetGeneration(),
+			Desc:           desc.String(),
+		})
+	}
+	sort.Slice(plan.Updates, func(i, j int) bool {
+		return plan.Updates[i].RangeID < plan.Updates[j].RangeID
+	})
+	return plan
+}
+
+// resolveRecoveryOverlaps returns the replicas whose descriptors are kept,
+// sorted by start key, and the ranges skipped because their descriptor
+// overlaps a kept one. Descriptors are kept by decreasing generation, since
+// splits and merges increase the generation of the descriptors they create.
+// Overlapping descriptors of the same generation can't be told apart, so none
+// of them is kept.
+func resolveRecoveryOverlaps(
+	best []recoveryReplicaInfo,
+) (kept []recoveryReplicaInfo, skipped []recoverySkippedRange) {
+	sort.Slice(best, func(i, j int) bool {
+		if gi, gj := best[i].Desc.GetGeneration(), best[j].Desc.GetGeneration(); gi != gj {
+			return gi > gj
+		}
+		return best[i].Desc.RangeID < best[j].Desc.RangeID
+	})
+	ambiguous := map[roachpb.RangeID]roachpb.RangeID{}
+	for _, r := range best {
+		desc := &r.Desc
+		// kept is sorted by start key and its descriptors don't overlap, so the
+		// descriptors overlapping desc are contiguous.
+		i := sort.Search(len(kept), func(i int) bool {
+			return desc.StartKey.Less(kept[i].Desc.EndKey)
+		})
+		j := i
+		for j < len(kept) && kept[j].Desc.StartKey.Less(desc.EndKey) {
+			j++
+		}
+		if i == j {
+			kept = append(kept, recoveryReplicaInfo{})
+			copy(kept[i+1:], kept[i:])
+			kept[i] = r
+			continue
+		}
+		for _, o := range kept[i:j] {
+			if o.Desc.GetGeneration() == desc.GetGeneration() {
+				ambiguous[o.Desc.RangeID] = desc.RangeID
+			}
+		}
+		o := &kept[i].Desc
+		reason := fmt.Sprintf("overlaps r%d whose descriptor is more recent (generation %d > %d)",
+			o.RangeID, o.GetGeneration(), desc.GetGeneration())
+		if o.GetGeneration() == desc.GetGeneration() {
+			reason = fmt.Sprintf("overlaps r%d whose descriptor has the same generation %d",
+				o.RangeID, o.GetGeneration())
+		}
+		skipped = append(skipped, recoverySkippedRange{
+			RangeID: desc.RangeID,
+			Desc:    desc.String(),
+			Reason:  reason,
+		})
+	}
+	if len(ambiguous) > 0 {
+		n := 0
+		for _, r := range kept {
+			if other, ok := ambiguous[r.Desc.RangeID]; ok {
+				skipped = append(skipped, recoverySkippedRange{
+					RangeID: r.Desc.RangeID,
+					Desc:    r.Desc.String(),
+					Reason: fmt.Sprintf("overlaps r%d whose descriptor has the same generation %d",
+						other, r.Desc.GetGeneration()),
+				})
+				continue
+			}
+			kept[n] = r
+			n++
+		}
+		kept = kept[:n]
+	}
+	sort.Slice(skipped, func(i, j int) bool { return skipped[i].RangeID < skipped[j].RangeID })
+	return kept, skipped
+}
+
+// findLostSpans returns the spans of the keyspace which none of the
+// descriptors, sorted by start key and not overlapping, cover.
+func findLostSpans(kept []recoveryReplicaInfo) []roachpb.RSpan {
+	var lost []roachpb.RSpan
+	prev := roachpb.RKeyMin
+	for _, r := range kept {
+		if prev.Less(r.Desc.StartKey) {
+			lost = append(lost, roachpb.RSpan{Key: prev, EndKey: r.Desc.StartKey})
+		}
+		prev = r.Desc.EndKey
+	}
+	if prev.Less(roachpb.RKeyMax) {
+		lost = append(lost, roachpb.RSpan{Key: prev, EndKey: roachpb.RKeyMax})
+	}
+	return lost
+}
+
+func runDebugRecoverApplyPlan(cmd *cobra.Command, args []string) error {
+	var plan recoveryPlan
+	if err := readRecoveryJSON(args[0], &plan); err != nil {
+		return err
+	}
+
+	stopper := stop.NewStopper()
+	defer stopper.Stop(context.Background())
+
+	db, err := OpenExistingStore(args[1], stopper, false /* readOnly */)
+	if err != nil {
+		return err
+	}
+	batch, err := applyRecoveryPlan(context.Background(), db, plan)
+	if err != nil {
+		return err
+	} else if batch == nil {
+		fmt.Printf("Nothing to do\n")
+		return nil
+	}
+	defer batch.Close()
+	return promptAndCommit(batch)
+}
+
+// applyRecoveryPlan returns a batch rewriting the descriptors of the ranges
+// whose designated survivor lives on the store, or nil if there are none left
+// to rewrite.
+func applyRecoveryPlan(
+	ctx context.Context, db engine.Engine, plan recoveryPlan,
+) (engine.Batch, error) {
+	storeIdent, err := storage.ReadStoreIdent(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	fmt.Printf("Applying recovery plan %s to store %s\n", plan.PlanID, storeIdent.String())
+	for _, storeID := range plan.DeadStoreIDs {
+		if storeID == storeIdent.StoreID {
+			return nil, errors.Errorf("this store's ID (%s) is marked as dead by the plan, aborting",
+				storeIdent.StoreID)
+		}
+	}
+
+	updates := map[roachpb.RangeID]recoveryUpdate{}
+	for _, u := range plan.Updates {
+		if u.StoreID == storeIdent.StoreID {
+			updates[u.RangeID] = u
+		}
+	}
+	var newDescs []roachpb.RangeDescriptor
+	err = storage.IterateRangeDescriptors(ctx, db, func(desc roachpb.RangeDescriptor) (bool, error) {
+		u, ok := updates[desc.RangeID]
+		if !ok {
+			return false, nil
+		}
+		delete(updates, desc.RangeID)
+
+		replicas := desc.Replicas().All()
+		if len(replicas) == 1 && replicas[0].StoreID == storeIdent.StoreID &&
+			replicas[0].ReplicaID == u.NewReplicaID {
+			fmt.Printf("r%d: already recovered\n", desc.RangeID)
+			return false, nil
+		}
+		rd, ok := desc.GetReplicaDescriptor(storeIdent.StoreID)
+		if !ok || rd.ReplicaID != u.OldReplicaID || desc.NextReplicaID != u.NewReplicaID ||
+			desc.GetGeneration() != u.Generation || !desc.StartKey.Equal(u.StartKey) {
+			return false, errors.Errorf(
+				"r%d: descriptor %s changed since the plan was made; collect the replicas again", desc.RangeID, &desc)
+		}
+
+		newDesc := desc
+		newDesc.SetReplicas(roachpb.MakeReplicaDescriptors([]roachpb.ReplicaDescriptor{{
+			NodeID:    storeIdent.NodeID,
+			StoreID:   storeIdent.StoreID,
+			ReplicaID: u.NewReplicaID,
+		}}))
+		newDesc.NextReplicaID = u.NewReplicaID + 1
+		fmt.Printf("Replica %s -> %s\n", &desc, &newDesc)
+		newDescs = append(newDescs, newDesc)
+		return false, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, u := range plan.Updates {
+		if _, ok := updates[u.RangeID]; ok {
+			return nil, errors.Errorf("r%d: replica designated by the plan not found on this store", u.RangeID)
+		}
+	}
+
+	if len(newDescs) == 0 {
+		return nil, nil
+	}
+	return rewriteRangeDescriptors(ctx, db, hlc.NewClock(hlc.UnixNano, 0), newDescs)
+}
+
+func writeRecoveryJSON(w io.Writer, v interface{}) error {
+	out, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(out, '\n'))
+	return err
+}
+
+func readRecoveryJSON(path string, v interface{}) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	return errors.Wrapf(dec.Decode(v), "reading %s", path)
+}
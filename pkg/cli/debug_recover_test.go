@@ -0,0 +1,206 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package cli
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/storage"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/stretchr/testify/require"
+)
+
+func makeRecoveryDesc(
+	rangeID roachpb.RangeID, start, end string, gen int64, storeIDs ...roachpb.StoreID,
+) roachpb.RangeDescriptor {
+	var replicas []roachpb.ReplicaDescriptor
+	for i, storeID := range storeIDs {
+		replicas = append(replicas, roachpb.ReplicaDescriptor{
+			NodeID:    roachpb.NodeID(storeID),
+			StoreID:   storeID,
+			ReplicaID: roachpb.ReplicaID(i + 1),
+		})
+	}
+	desc := roachpb.RangeDescriptor{
+		RangeID:       rangeID,
+		StartKey:      roachpb.RKey(start),
+		EndKey:        roachpb.RKey(end),
+		NextReplicaID: roachpb.ReplicaID(len(storeIDs) + 1),
+		Generation:    &gen,
+	}
+	desc.SetReplicas(roachpb.MakeReplicaDescriptors(replicas))
+	return desc
+}
+
+func makeRecoveryReplica(
+	storeID roachpb.StoreID, desc roachpb.RangeDescriptor, appliedIndex uint64,
+) recoveryReplicaInfo {
+	return recoveryReplicaInfo{
+		NodeID:           roachpb.NodeID(storeID),
+		StoreID:          storeID,
+		Desc:             desc,
+		RaftAppliedIndex: appliedIndex,
+	}
+}
+
+func TestMakeRecoveryPlan(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	// Stores 1 and 2 survived, stores 3 to 5 are dead.
+	healthy := makeRecoveryDesc(1, "a", "b", 0, 1, 2, 3)
+	lost := makeRecoveryDesc(2, "b", "c", 0, 1, 2, 3, 4, 5)
+	lostSplit := makeRecoveryDesc(3, "c", "d", 1, 1, 2, 3)
+	lostSplitStale := makeRecoveryDesc(3, "c", "e", 0, 1, 2, 3)
+	replicas := []recoveryReplicaInfo{
+		makeRecoveryReplica(1, healthy, 10),
+		makeRecoveryReplica(2, healthy, 10),
+		// The replica with the highest applied index wins.
+		makeRecoveryReplica(1, lost, 20),
+		makeRecoveryReplica(2, lost, 15),
+		// The replica with the highest generation wins.
+		makeRecoveryReplica(1, lostSplitStale, 30),
+		makeRecoveryReplica(2, lostSplit, 25),
+	}
+
+	plan := makeRecoveryPlan(replicas)
+	require.Equal(t, []roachpb.StoreID{3, 4, 5}, plan.DeadStoreIDs)
+	require.Len(t, plan.Updates, 2)
+	require.Equal(t, roachpb.RangeID(2), plan.Updates[0].RangeID)
+	require.Equal(t, roachpb.StoreID(1), plan.Updates[0].StoreID)
+	require.Equal(t, roachpb.ReplicaID(1), plan.Updates[0].OldReplicaID)
+	require.Equal(t, roachpb.ReplicaID(6), plan.Updates[0].NewReplicaID)
+	require.Equal(t, roachpb.RangeID(3), plan.Updates[1].RangeID)
+	require.Equal(t, roachpb.StoreID(2), plan.Updates[1].StoreID)
+	require.Equal(t, int64(1), plan.Updates[1].Generation)
+	require.False(t, plan.Updates[1].promotesNonVoter())
+	require.Empty(t, plan.SkippedRanges)
+	// Only [a,d) is covered by the surviving replicas.
+	require.Equal(t, []roachpb.RSpan{
+		{Key: roachpb.RKeyMin, EndKey: roachpb.RKey("a")},
+		{Key: roachpb.RKey("d"), EndKey: roachpb.RKeyMax},
+	}, plan.LostSpans)
+
+	// Ties are broken by store ID.
+	plan = makeRecoveryPlan([]recoveryReplicaInfo{
+		makeRecoveryReplica(1, lost, 20),
+		makeRecoveryReplica(2, lost, 20),
+	})
+	require.Len(t, plan.Updates, 1)
+	require.Equal(t, roachpb.StoreID(2), plan.Updates[0].StoreID)
+
+	// Of replicas with the same applied index, the one with the highest
+	// committed index wins.
+	committed := makeRecoveryReplica(1, lost, 20)
+	committed.RaftCommittedIndex = 25
+	uncommitted := makeRecoveryReplica(2, lost, 20)
+	uncommitted.RaftCommittedIndex = 20
+	plan = makeRecoveryPlan([]recoveryReplicaInfo{committed, uncommitted})
+	require.Len(t, plan.Updates, 1)
+	require.Equal(t, roachpb.StoreID(1), plan.Updates[0].StoreID)
+
+	// A learner or a non-voter may be designated, and the plan says so.
+	for _, typ := range []*roachpb.ReplicaType{roachpb.ReplicaTypeLearner(), roachpb.ReplicaTypeNonVoter()} {
+		nonVoter := makeRecoveryDesc(2, "b", "c", 0, 1, 2, 3)
+		nonVoter.InternalReplicas[0].Type = typ
+		plan = makeRecoveryPlan([]recoveryReplicaInfo{makeRecoveryReplica(1, nonVoter, 20)})
+		require.Len(t, plan.Updates, 1)
+		require.Equal(t, *typ, plan.Updates[0].OldReplicaType)
+		require.True(t, plan.Updates[0].promotesNonVoter())
+	}
+
+	// When the survivors disagree on range boundaries, the most recent
+	// descriptor is kept and the overlapping range is skipped.
+	plan = makeRecoveryPlan([]recoveryReplicaInfo{
+		makeRecoveryReplica(1, lostSplitStale, 30),
+		makeRecoveryReplica(2, makeRecoveryDesc(4, "d", "e", 1, 2, 3, 4), 30),
+	})
+	require.Len(t, plan.Updates, 1)
+	require.Equal(t, roachpb.RangeID(4), plan.Updates[0].RangeID)
+	require.Len(t, plan.SkippedRanges, 1)
+	require.Equal(t, roachpb.RangeID(3), plan.SkippedRanges[0].RangeID)
+	require.Contains(t, plan.SkippedRanges[0].Reason, "overlaps r4")
+	require.Equal(t, []roachpb.RSpan{
+		{Key: roachpb.RKeyMin, EndKey: roachpb.RKey("d")},
+		{Key: roachpb.RKey("e"), EndKey: roachpb.RKeyMax},
+	}, plan.LostSpans)
+
+	// Overlapping descriptors of the same generation can't be told apart, so
+	// neither range is recovered.
+	plan = makeRecoveryPlan([]recoveryReplicaInfo{
+		makeRecoveryReplica(1, lostSplitStale, 30),
+		makeRecoveryReplica(2, makeRecoveryDesc(4, "d", "e", 0, 2, 3, 4), 30),
+	})
+	require.Empty(t, plan.Updates)
+	require.Len(t, plan.SkippedRanges, 2)
+	require.Equal(t, roachpb.RangeID(3), plan.SkippedRanges[0].RangeID)
+	require.Equal(t, roachpb.RangeID(4), plan.SkippedRanges[1].RangeID)
+	require.Equal(t, []roachpb.RSpan{{Key: roachpb.RKeyMin, EndKey: roachpb.RKeyMax}}, plan.LostSpans)
+}
+
+func TestApplyRecoveryPlan(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ctx := context.Background()
+	db := engine.NewDefaultInMem()
+	defer db.Close()
+
+	ident := roachpb.StoreIdent{NodeID: 1, StoreID: 1}
+	require.NoError(t, engine.MVCCPutProto(
+		ctx, db, nil, keys.StoreIdentKey(), hlc.Timestamp{}, nil, &ident))
+	desc := makeRecoveryDesc(2, "b", "c", 0, 1, 2, 3)
+	require.NoError(t, engine.MVCCPutProto(
+		ctx, db, nil, keys.RangeDescriptorKey(desc.StartKey), hlc.Timestamp{WallTime: 1}, nil, &desc))
+
+	replicas, err := collectRecoveryInfo(ctx, db)
+	require.NoError(t, err)
+	require.Len(t, replicas, 1)
+	plan := makeRecoveryPlan(replicas)
+	require.Len(t, plan.Updates, 1)
+
+	batch, err := applyRecoveryPlan(ctx, db, plan)
+	require.NoError(t, err)
+	require.NotNil(t, batch)
+	require.NoError(t, batch.Commit(true))
+	batch.Close()
+
+	var newDesc roachpb.RangeDescriptor
+	require.NoError(t, storage.IterateRangeDescriptors(ctx, db,
+		func(d roachpb.RangeDescriptor) (bool, error) {
+			newDesc = d
+			return true, nil
+		}))
+	require.Equal(t, []roachpb.ReplicaDescriptor{{NodeID: 1, StoreID: 1, ReplicaID: 4}},
+		newDesc.Replicas().All())
+	require.Equal(t, roachpb.ReplicaID(5), newDesc.NextReplicaID)
+
+	// Applying the plan again is a no-op.
+	batch, err = applyRecoveryPlan(ctx, db, plan)
+	require.NoError(t, err)
+	require.Nil(t, batch)
+
+	// A plan made before the descriptor last changed is refused.
+	stale := plan
+	stale.Updates = []recoveryUpdate{plan.Updates[0]}
+	stale.Updates[0].NewReplicaID = 7
+	_, err = applyRecoveryPlan(ctx, db, stale)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "changed since the plan was made")
+
+	// A store marked as dead by the plan is refused.
+	plan.DeadStoreIDs = append(plan.DeadStoreIDs, ident.StoreID)
+	_, err = applyRecoveryPlan(ctx, db, plan)
+	require.Error(t, err)
+}
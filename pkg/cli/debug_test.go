@@ -15,6 +15,7 @@ import (
 	"errors"
 	"fmt"
 	"path/filepath"
+	"reflect"
 	"regexp"
 	"sort"
 	"strings"
@@ -93,6 +94,205 @@ func TestOpenExistingStore(t *testing.T) {
 	}
 }
 
+func TestDebugRocksDBToolsStorageEngine(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	baseDir, dirCleanupFn := testutils.TempDir(t)
+	defer dirCleanupFn()
+
+	rocksDBDir := filepath.Join(baseDir, "rocksdb")
+	createStore(t, rocksDBDir)
+	pebbleDir := filepath.Join(baseDir, "pebble")
+	db, err := engine.NewPebble(context.Background(), engine.PebbleConfig{
+		StorageConfig: base.StorageConfig{Dir: pebbleDir},
+		Opts:          engine.DefaultPebbleOptions(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.Close()
+
+	for _, test := range []struct {
+		tool      string
+		args      []string
+		expArgs   []string
+		expErr    string
+		expPebble bool
+	}{
+		{
+			tool:    "rocksdb",
+			args:    []string{"--db=" + rocksDBDir, "scan"},
+			expArgs: []string{"--db=" + rocksDBDir, "scan"},
+		},
+		{
+			tool:    "rocksdb",
+			args:    []string{"--storage-engine", "rocksdb", "--db=" + rocksDBDir, "scan"},
+			expArgs: []string{"--db=" + rocksDBDir, "scan"},
+		},
+		{
+			tool:      "rocksdb",
+			args:      []string{"--storage-engine=pebble", "--db=" + rocksDBDir, "scan"},
+			expArgs:   []string{"--db=" + rocksDBDir, "scan"},
+			expPebble: true,
+		},
+		{
+			tool:      "rocksdb",
+			args:      []string{"--db=" + pebbleDir, "scan"},
+			expArgs:   []string{"--db=" + pebbleDir, "scan"},
+			expPebble: true,
+		},
+		{
+			tool:   "rocksdb",
+			args:   []string{"--storage-engine"},
+			expErr: `flag needs an argument`,
+		},
+		{
+			tool:      "sst_dump",
+			args:      []string{"--file=" + pebbleDir, "--command=scan"},
+			expArgs:   []string{"--file=" + pebbleDir, "--command=scan"},
+			expPebble: true,
+		},
+		{
+			tool:    "sst_dump",
+			args:    []string{"--file=" + rocksDBDir, "--command=scan"},
+			expArgs: []string{"--file=" + rocksDBDir, "--command=scan"},
+		},
+	} {
+		t.Run(test.tool, func(t *testing.T) {
+			pathFlag := "--db"
+			if test.tool == "sst_dump" {
+				pathFlag = "--file"
+			}
+			args, isPebble, err := parseRocksDBToolArgs(pathFlag, test.args)
+			if !testutils.IsError(err, test.expErr) {
+				t.Fatalf("expected %q, got %v", test.expErr, err)
+			}
+			if err != nil {
+				return
+			}
+			if !reflect.DeepEqual(args, test.expArgs) {
+				t.Errorf("expected args %q, got %q", test.expArgs, args)
+			}
+			if isPebble != test.expPebble {
+				t.Errorf("expected pebble %t, got %t", test.expPebble, isPebble)
+			}
+		})
+	}
+
+	// The RocksDB tools run the equivalent Pebble tools on Pebble stores.
+	for _, args := range [][]string{
+		{"--db=" + pebbleDir, "scan"},
+		{"--db=" + pebbleDir, "checkconsistency"},
+		{"--db=" + pebbleDir, "manifest_dump"},
+	} {
+		pebbleArgs, err := ldbToPebbleArgs(args)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := runDebugPebble(pebbleArgs); err != nil {
+			t.Fatalf("%q: %v", args, err)
+		}
+	}
+}
+
+func TestDebugRocksDBToolsPebbleArgs(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	for _, test := range []struct {
+		tool    string
+		args    []string
+		expArgs []string
+		expErr  string
+	}{
+		{
+			tool:    "rocksdb",
+			args:    []string{"--db=/d", "scan"},
+			expArgs: []string{"db", "scan", "/d"},
+		},
+		{
+			tool:    "rocksdb",
+			args:    []string{"--db=/d", "--from=a", "--to=b", "dump"},
+			expArgs: []string{"db", "scan", "/d", "--start=a", "--end=b"},
+		},
+		{
+			tool:    "rocksdb",
+			args:    []string{"--db=/d", "--key_hex", "--from=0a", "approxsize"},
+			expArgs: []string{"db", "space", "/d", "--start=hex:0a"},
+		},
+		{
+			tool:    "rocksdb",
+			args:    []string{"--db=/d", "checkconsistency"},
+			expArgs: []string{"db", "check", "/d"},
+		},
+		{
+			tool:    "rocksdb",
+			args:    []string{"--db=/d", "list_live_files_metadata"},
+			expArgs: []string{"db", "lsm", "/d"},
+		},
+		{
+			tool:   "rocksdb",
+			args:   []string{"--db=/d", "put", "a", "b"},
+			expErr: `expected a single ldb command`,
+		},
+		{
+			tool:   "rocksdb",
+			args:   []string{"--db=/d", "compact"},
+			expErr: `ldb command "compact" is not supported on the pebble storage engine`,
+		},
+		{
+			tool:   "rocksdb",
+			args:   []string{"--db=/d", "--max_keys=10", "scan"},
+			expErr: `--max_keys not supported on the pebble storage engine`,
+		},
+		{
+			tool:    "sst_dump",
+			args:    []string{"--file=/f.sst"},
+			expArgs: []string{"sstable", "scan", "/f.sst"},
+		},
+		{
+			tool:    "sst_dump",
+			args:    []string{"--file=/f.sst", "--command=scan", "--from=a"},
+			expArgs: []string{"sstable", "scan", "/f.sst", "--start=a"},
+		},
+		{
+			tool:    "sst_dump",
+			args:    []string{"--file=/f.sst", "--command=verify"},
+			expArgs: []string{"sstable", "check", "/f.sst"},
+		},
+		{
+			tool:    "sst_dump",
+			args:    []string{"--file=/f.sst", "--command=raw"},
+			expArgs: []string{"sstable", "layout", "/f.sst"},
+		},
+		{
+			tool:    "sst_dump",
+			args:    []string{"--file=/f.sst", "--show_properties"},
+			expArgs: []string{"sstable", "properties", "/f.sst"},
+		},
+		{
+			tool:   "sst_dump",
+			args:   []string{"--file=/f.sst", "--command=recompress"},
+			expErr: `sst_dump command "recompress" is not supported on the pebble storage engine`,
+		},
+	} {
+		t.Run(strings.Join(test.args, " "), func(t *testing.T) {
+			var args []string
+			var err error
+			if test.tool == "sst_dump" {
+				args, err = sstDumpToPebbleArgs(test.args)
+			} else {
+				args, err = ldbToPebbleArgs(test.args)
+			}
+			if !testutils.IsError(err, test.expErr) {
+				t.Fatalf("expected %q, got %v", test.expErr, err)
+			}
+			if err == nil && !reflect.DeepEqual(args, test.expArgs) {
+				t.Errorf("expected args %q, got %q", test.expArgs, args)
+			}
+		})
+	}
+}
+
 func TestOpenReadOnlyStore(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	stopper := stop.NewStopper()
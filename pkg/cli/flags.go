@@ -617,6 +617,12 @@ func init() {
 	BoolFlag(fmtFlags, &sqlfmtCtx.align, cliflags.SQLFmtAlign, (cfg.Align != tree.PrettyNoAlign))
 
 	// Debug commands.
+	for _, cmd := range append(DebugCmdsForRocksDB,
+		debugUnsafeRemoveDeadReplicasCmd, debugRecoverCollectInfoCmd, debugRecoverApplyPlanCmd) {
+		// The debug commands open the store with the storage engine it was
+		// written with.
+		VarFlag(cmd.Flags(), &serverCfg.StorageEngine, cliflags.StorageEngine)
+	}
 	{
 		f := debugKeysCmd.Flags()
 		VarFlag(f, (*mvccKey)(&debugCtx.startKey), cliflags.From)
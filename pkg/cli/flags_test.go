@@ -26,6 +26,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/cli/cliflags"
 	"github.com/cockroachdb/cockroach/pkg/gossip/resolver"
 	"github.com/cockroachdb/cockroach/pkg/server/status"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
 	"github.com/cockroachdb/cockroach/pkg/testutils"
 	"github.com/cockroachdb/cockroach/pkg/testutils/buildutil"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
@@ -133,6 +134,41 @@ func TestClusterNameFlag(t *testing.T) {
 	}
 }
 
+func TestStorageEngineFlag(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	// Avoid leaking configuration changes after the test ends.
+	defer initCLIDefaults()
+
+	testCases := []struct {
+		value       string
+		expected    enginepb.EngineType
+		expectedErr string
+	}{
+		{"rocksdb", enginepb.EngineTypeRocksDB, ""},
+		{"pebble", enginepb.EngineTypePebble, ""},
+		{"pebble+rocksdb", enginepb.EngineTypeTeePebbleRocksDB, ""},
+		{"leveldb", 0, "invalid storage engine: leveldb"},
+	}
+
+	// The flag is accepted by the start command and by the debug commands
+	// opening a store.
+	for _, cmd := range []*cobra.Command{startCmd, debugKeysCmd, debugCheckStoreCmd} {
+		for _, c := range testCases {
+			t.Run(fmt.Sprintf("%s/%s", cmd.Name(), c.value), func(t *testing.T) {
+				initCLIDefaults()
+				err := cmd.Flags().Parse([]string{"--storage-engine", c.value})
+				if !testutils.IsError(err, c.expectedErr) {
+					t.Fatalf("expected %q, got %v", c.expectedErr, err)
+				}
+				if err == nil && serverCfg.StorageEngine != c.expected {
+					t.Errorf("expected %s, got %s", c.expected.String(), serverCfg.StorageEngine.String())
+				}
+			})
+		}
+	}
+}
+
 func TestSQLMemoryPoolFlagValue(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 
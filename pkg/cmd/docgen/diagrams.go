@@ -565,6 +565,9 @@ var specs = []stmtSpec{
 		unlink:  []string{"integer", "sequence_name"},
 		nosplit: true,
 	},
+	{
+		name: "create_schedule_for_backup_stmt",
+	},
 	{
 		name:    "create_stats_stmt",
 		replace: map[string]string{"name_list": "column_name"},
@@ -672,6 +675,11 @@ var specs = []stmtSpec{
 		name:    "drop_role_stmt",
 		replace: map[string]string{"string_or_placeholder_list": "name"},
 	},
+	{
+		name:    "drop_schedule_stmt",
+		replace: map[string]string{"a_expr": "schedule_id"},
+		unlink:  []string{"schedule_id"},
+	},
 	{
 		name:   "drop_sequence_stmt",
 		inline: []string{"table_name_list", "opt_drop_behavior"},
@@ -884,10 +892,16 @@ var specs = []stmtSpec{
 	},
 	{
 		name:    "pause_job",
-		stmt:    "pause_stmt",
+		stmt:    "pause_jobs_stmt",
 		replace: map[string]string{"a_expr": "job_id"},
 		unlink:  []string{"job_id"},
 	},
+	{
+		name:    "pause_schedule",
+		stmt:    "pause_schedule_stmt",
+		replace: map[string]string{"a_expr": "schedule_id"},
+		unlink:  []string{"schedule_id"},
+	},
 	{
 		name: "primary_key_column_level",
 		stmt: "stmt_block",
@@ -957,10 +971,16 @@ var specs = []stmtSpec{
 	},
 	{
 		name:    "resume_job",
-		stmt:    "resume_stmt",
+		stmt:    "resume_jobs_stmt",
 		replace: map[string]string{"a_expr": "job_id"},
 		unlink:  []string{"job_id"},
 	},
+	{
+		name:    "resume_schedule",
+		stmt:    "resume_schedule_stmt",
+		replace: map[string]string{"a_expr": "schedule_id"},
+		unlink:  []string{"schedule_id"},
+	},
 	{
 		name:   "revoke_privileges",
 		stmt:   "revoke_stmt",
@@ -1156,6 +1176,12 @@ var specs = []stmtSpec{
 		replace: map[string]string{"a_expr": "job_id"},
 		unlink:  []string{"job_id"},
 	},
+	{
+		name:    "show_schedules",
+		stmt:    "show_schedules_stmt",
+		replace: map[string]string{"a_expr": "schedule_id"},
+		unlink:  []string{"schedule_id"},
+	},
 	{
 		name:   "show_grants_stmt",
 		inline: []string{"name_list", "opt_on_targets_roles", "for_grantee_clause", "name_list"},
@@ -246,6 +246,26 @@ func (z *ZoneConfig) IsComplete() bool {
 		(!z.InheritedConstraints) && (!z.InheritedLeasePreferences))
 }
 
+// GetNumVoters returns the number of voting replicas for the zone, which is
+// NumVoters if it is set and NumReplicas otherwise.
+func (z *ZoneConfig) GetNumVoters() int32 {
+	if z.NumVoters != nil {
+		return *z.NumVoters
+	}
+	if z.NumReplicas != nil {
+		return *z.NumReplicas
+	}
+	return 0
+}
+
+// GetNumNonVoters returns the number of non-voting replicas for the zone.
+func (z *ZoneConfig) GetNumNonVoters() int32 {
+	if z.NumVoters == nil || z.NumReplicas == nil || *z.NumVoters >= *z.NumReplicas {
+		return 0
+	}
+	return *z.NumReplicas - *z.NumVoters
+}
+
 // ValidateTandemFields returns an error if the ZoneConfig to be written
 // specifies a configuration that could cause problems with the introduction
 // of cascading zone configs.
@@ -258,6 +278,12 @@ func (z *ZoneConfig) ValidateTandemFields() error {
 	if numConstrainedRepls > 0 && z.NumReplicas == nil {
 		return fmt.Errorf("when per-replica constraints are set, num_replicas must be set as well")
 	}
+	if z.NumVoters != nil && z.NumReplicas == nil {
+		return fmt.Errorf("when num_voters is set, num_replicas must be set as well")
+	}
+	if len(z.VoterConstraints) > 0 && z.NumVoters == nil {
+		return fmt.Errorf("when voter_constraints are set, num_voters must be set as well")
+	}
 	if (z.RangeMinBytes != nil || z.RangeMaxBytes != nil) &&
 		(z.RangeMinBytes == nil || z.RangeMaxBytes == nil) {
 		return fmt.Errorf("range_min_bytes and range_max_bytes must be set together")
@@ -293,6 +319,18 @@ func (z *ZoneConfig) Validate() error {
 		}
 	}
 
+	if z.NumVoters != nil {
+		switch {
+		case *z.NumVoters <= 0:
+			return fmt.Errorf("at least one voting replica is required")
+		case *z.NumVoters == 2:
+			return fmt.Errorf("at least 3 voting replicas are required for multi-replica configurations")
+		case z.NumReplicas != nil && *z.NumVoters > *z.NumReplicas:
+			return fmt.Errorf("num_voters (%d) cannot be greater than num_replicas (%d)",
+				*z.NumVoters, *z.NumReplicas)
+		}
+	}
+
 	if z.RangeMaxBytes != nil && *z.RangeMaxBytes < base.MinRangeMaxBytes {
 		return fmt.Errorf("RangeMaxBytes %d less than minimum allowed %d",
 			*z.RangeMaxBytes, base.MinRangeMaxBytes)
@@ -347,6 +385,32 @@ func (z *ZoneConfig) Validate() error {
 		}
 	}
 
+	// Voter constraints follow the same rules as constraints, but are bounded
+	// by the number of voters instead of the number of replicas.
+	for _, constraints := range z.VoterConstraints {
+		for _, constraint := range constraints.Constraints {
+			if constraint.Type == Constraint_DEPRECATED_POSITIVE {
+				return fmt.Errorf("voter constraints must either be required (prefixed with a '+') or " +
+					"prohibited (prefixed with a '-')")
+			}
+		}
+	}
+	if len(z.VoterConstraints) > 1 ||
+		(len(z.VoterConstraints) == 1 && z.VoterConstraints[0].NumReplicas != 0) {
+		var numConstrainedVoters int64
+		for _, constraints := range z.VoterConstraints {
+			if constraints.NumReplicas <= 0 {
+				return fmt.Errorf("voter constraints must apply to at least one replica")
+			}
+			numConstrainedVoters += int64(constraints.NumReplicas)
+		}
+		if z.NumVoters != nil && numConstrainedVoters > int64(*z.NumVoters) {
+			return fmt.Errorf("the number of replicas specified in voter constraints (%d) cannot be "+
+				"greater than the number of voters configured for the zone (%d)",
+				numConstrainedVoters, *z.NumVoters)
+		}
+	}
+
 	for _, leasePref := range z.LeasePreferences {
 		if len(leasePref.Constraints) == 0 {
 			return fmt.Errorf("every lease preference must include at least one constraint")
@@ -370,6 +434,12 @@ func (z *ZoneConfig) InheritFromParent(parent *ZoneConfig) {
 			z.NumReplicas = proto.Int32(*parent.NumReplicas)
 		}
 	}
+	if z.NumVoters == nil {
+		if parent.NumVoters != nil {
+			z.NumVoters = proto.Int32(*parent.NumVoters)
+			z.VoterConstraints = parent.VoterConstraints
+		}
+	}
 	if z.RangeMinBytes == nil {
 		if parent.RangeMinBytes != nil {
 			z.RangeMinBytes = proto.Int64(*parent.RangeMinBytes)
@@ -409,6 +479,15 @@ func (z *ZoneConfig) CopyFromZone(other ZoneConfig, fieldList []tree.Name) {
 				z.NumReplicas = proto.Int32(*other.NumReplicas)
 			}
 		}
+		if fieldName == "num_voters" {
+			z.NumVoters = nil
+			if other.NumVoters != nil {
+				z.NumVoters = proto.Int32(*other.NumVoters)
+			}
+		}
+		if fieldName == "voter_constraints" {
+			z.VoterConstraints = other.VoterConstraints
+		}
 		if fieldName == "range_min_bytes" {
 			z.RangeMinBytes = nil
 			if other.RangeMinBytes != nil {
@@ -232,6 +232,54 @@ func TestZoneConfigValidate(t *testing.T) {
 			},
 			"",
 		},
+		{
+			ZoneConfig{
+				NumReplicas:   proto.Int32(5),
+				NumVoters:     proto.Int32(2),
+				RangeMaxBytes: DefaultZoneConfig().RangeMaxBytes,
+				GC:            &GCPolicy{TTLSeconds: 1},
+			},
+			"at least 3 voting replicas are required for multi-replica configurations",
+		},
+		{
+			ZoneConfig{
+				NumReplicas:   proto.Int32(3),
+				NumVoters:     proto.Int32(5),
+				RangeMaxBytes: DefaultZoneConfig().RangeMaxBytes,
+				GC:            &GCPolicy{TTLSeconds: 1},
+			},
+			`num_voters \(5\) cannot be greater than num_replicas \(3\)`,
+		},
+		{
+			ZoneConfig{
+				NumReplicas:   proto.Int32(5),
+				NumVoters:     proto.Int32(3),
+				RangeMaxBytes: DefaultZoneConfig().RangeMaxBytes,
+				GC:            &GCPolicy{TTLSeconds: 1},
+				VoterConstraints: []Constraints{
+					{
+						Constraints: []Constraint{{Value: "a", Type: Constraint_REQUIRED}},
+						NumReplicas: 4,
+					},
+				},
+			},
+			"the number of replicas specified in voter constraints .+ cannot be greater than",
+		},
+		{
+			ZoneConfig{
+				NumReplicas:   proto.Int32(5),
+				NumVoters:     proto.Int32(3),
+				RangeMaxBytes: DefaultZoneConfig().RangeMaxBytes,
+				GC:            &GCPolicy{TTLSeconds: 1},
+				VoterConstraints: []Constraints{
+					{
+						Constraints: []Constraint{{Value: "a", Type: Constraint_REQUIRED}},
+						NumReplicas: 3,
+					},
+				},
+			},
+			"",
+		},
 	}
 
 	for i, c := range testCases {
@@ -284,6 +332,23 @@ func TestZoneConfigValidateTandemFields(t *testing.T) {
 			},
 			"lease preferences can not be set unless the constraints are explicitly set as well",
 		},
+		{
+			ZoneConfig{
+				NumVoters: proto.Int32(3),
+			},
+			"when num_voters is set, num_replicas must be set as well",
+		},
+		{
+			ZoneConfig{
+				NumReplicas: proto.Int32(5),
+				VoterConstraints: []Constraints{
+					{
+						Constraints: []Constraint{{Value: "a", Type: Constraint_REQUIRED}},
+					},
+				},
+			},
+			"when voter_constraints are set, num_voters must be set as well",
+		},
 	}
 
 	for i, c := range testCases {
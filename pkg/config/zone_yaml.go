@@ -203,7 +203,9 @@ type marshalableZoneConfig struct {
 	RangeMaxBytes                *int64            `json:"range_max_bytes" yaml:"range_max_bytes"`
 	GC                           *GCPolicy         `json:"gc"`
 	NumReplicas                  *int32            `json:"num_replicas" yaml:"num_replicas"`
+	NumVoters                    *int32            `json:"num_voters" yaml:"num_voters,omitempty"`
 	Constraints                  ConstraintsList   `json:"constraints" yaml:"constraints,flow"`
+	VoterConstraints             ConstraintsList   `json:"voter_constraints" yaml:"voter_constraints,flow,omitempty"`
 	LeasePreferences             []LeasePreference `json:"lease_preferences" yaml:"lease_preferences,flow"`
 	ExperimentalLeasePreferences []LeasePreference `json:"experimental_lease_preferences" yaml:"experimental_lease_preferences,flow,omitempty"`
 	Subzones                     []Subzone         `json:"subzones" yaml:"-"`
@@ -225,7 +227,11 @@ func zoneConfigToMarshalable(c ZoneConfig) marshalableZoneConfig {
 	if c.NumReplicas != nil && *c.NumReplicas != 0 {
 		m.NumReplicas = proto.Int32(*c.NumReplicas)
 	}
+	if c.NumVoters != nil {
+		m.NumVoters = proto.Int32(*c.NumVoters)
+	}
 	m.Constraints = ConstraintsList{c.Constraints, c.InheritedConstraints}
+	m.VoterConstraints = ConstraintsList{c.VoterConstraints, false}
 	if !c.InheritedLeasePreferences {
 		m.LeasePreferences = c.LeasePreferences
 	}
@@ -253,8 +259,12 @@ func zoneConfigFromMarshalable(m marshalableZoneConfig, c ZoneConfig) ZoneConfig
 	if m.NumReplicas != nil {
 		c.NumReplicas = proto.Int32(*m.NumReplicas)
 	}
+	if m.NumVoters != nil {
+		c.NumVoters = proto.Int32(*m.NumVoters)
+	}
 	c.Constraints = m.Constraints.Constraints
 	c.InheritedConstraints = m.Constraints.Inherited
+	c.VoterConstraints = m.VoterConstraints.Constraints
 	if m.LeasePreferences != nil {
 		c.LeasePreferences = m.LeasePreferences
 	}
@@ -109,6 +109,14 @@ func (m *MockTransactionalSender) SetFixedTimestamp(_ context.Context, ts hlc.Ti
 	m.txn.DeprecatedOrigTimestamp = ts
 }
 
+// SetBoundedStalenessTimestamp is part of the TxnSender interface.
+func (m *MockTransactionalSender) SetBoundedStalenessTimestamp(
+	ctx context.Context, ts hlc.Timestamp,
+) {
+	m.SetFixedTimestamp(ctx, ts)
+	m.txn.BoundedStaleness = true
+}
+
 // ManualRestart is part of the TxnSender interface.
 func (m *MockTransactionalSender) ManualRestart(
 	ctx context.Context, pri roachpb.UserPriority, ts hlc.Timestamp,
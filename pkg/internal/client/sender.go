@@ -134,6 +134,13 @@ type TxnSender interface {
 	// that retries should be rare for read-only queries with no clock uncertainty).
 	SetFixedTimestamp(ctx context.Context, ts hlc.Timestamp)
 
+	// SetBoundedStalenessTimestamp is like SetFixedTimestamp, for the read-only
+	// transactions of bounded staleness reads. The timestamp must be at or
+	// below the closed timestamp of all the ranges read by the transaction,
+	// which lets the reads be served by followers regardless of how recent the
+	// timestamp is.
+	SetBoundedStalenessTimestamp(ctx context.Context, ts hlc.Timestamp)
+
 	// ManualRestart bumps the transactions epoch, and can upgrade the timestamp
 	// and priority.
 	// An uninitialized timestamp can be passed to leave the timestamp alone.
@@ -72,6 +72,11 @@ type Txn struct {
 		// The txn has to be committed by this deadline. A nil value indicates no
 		// deadline.
 		deadline *hlc.Timestamp
+
+		// contentionTime is the total amount of time that the requests sent
+		// through this Txn spent waiting on other transactions, across all of
+		// its epochs.
+		contentionTime time.Duration
 	}
 }
 
@@ -226,6 +231,15 @@ func (txn *Txn) SetDebugName(name string) {
 	txn.mu.debugName = name
 }
 
+// ContentionTime returns the amount of time that the requests sent through
+// the transaction spent waiting on latches and locks held by other
+// transactions.
+func (txn *Txn) ContentionTime() time.Duration {
+	txn.mu.Lock()
+	defer txn.mu.Unlock()
+	return txn.mu.contentionTime
+}
+
 // DebugName returns the debug name associated with the transaction.
 func (txn *Txn) DebugName() string {
 	txn.mu.Lock()
@@ -251,6 +265,14 @@ func (txn *Txn) readTimestampLocked() hlc.Timestamp {
 	return txn.mu.sender.ReadTimestamp()
 }
 
+// BoundedStaleness returns whether the transaction's timestamp was set through
+// SetBoundedStalenessTimestamp.
+func (txn *Txn) BoundedStaleness() bool {
+	txn.mu.Lock()
+	defer txn.mu.Unlock()
+	return txn.mu.sender.SerializeTxn().BoundedStaleness
+}
+
 // CommitTimestamp returns the transaction's start timestamp.
 // The start timestamp can get pushed but the use of this
 // method will guarantee that if a timestamp push is needed
@@ -789,6 +811,11 @@ func (txn *Txn) Send(
 	txn.mu.Unlock()
 	br, pErr := txn.db.sendUsingSender(ctx, ba, sender)
 	if pErr == nil {
+		if br.ContentionTime != 0 {
+			txn.mu.Lock()
+			txn.mu.contentionTime += br.ContentionTime
+			txn.mu.Unlock()
+		}
 		return br, nil
 	}
 
@@ -939,6 +966,17 @@ func (txn *Txn) SetFixedTimestamp(ctx context.Context, ts hlc.Timestamp) {
 	txn.mu.sender.SetFixedTimestamp(ctx, ts)
 }
 
+// SetBoundedStalenessTimestamp is like SetFixedTimestamp, for the read-only
+// transactions of bounded staleness reads, whose timestamp is at or below the
+// closed timestamp of all the ranges they read. Their reads can then be served
+// by followers.
+func (txn *Txn) SetBoundedStalenessTimestamp(ctx context.Context, ts hlc.Timestamp) {
+	if ts.IsEmpty() {
+		log.Fatalf(ctx, "empty timestamp is invalid for SetBoundedStalenessTimestamp()")
+	}
+	txn.mu.sender.SetBoundedStalenessTimestamp(ctx, ts)
+}
+
 // GenerateForcedRetryableError returns a TransactionRetryWithProtoRefreshError that will
 // cause the txn to be retried.
 //
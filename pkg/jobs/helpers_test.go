@@ -62,3 +62,9 @@ func (d FakeResumer) OnTerminal(_ context.Context, _ Status, _ chan<- tree.Datum
 }
 
 var _ Resumer = FakeResumer{}
+
+// ExecuteScheduleForTesting adopts the given schedule if it is due, and
+// creates its job.
+func (s *JobScheduler) ExecuteScheduleForTesting(ctx context.Context, id int64) error {
+	return s.executeSchedule(ctx, id)
+}
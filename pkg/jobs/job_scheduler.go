@@ -0,0 +1,238 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlutil"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/pkg/errors"
+)
+
+var (
+	schedulerEnabledSetting = settings.RegisterBoolSetting(
+		"jobs.scheduler.enabled",
+		"enable the execution of scheduled jobs",
+		true)
+	schedulerPaceSetting = settings.RegisterValidatedDurationSetting(
+		"jobs.scheduler.pace",
+		"how often to scan system.scheduled_jobs for schedules due to run",
+		time.Minute,
+		func(v time.Duration) error {
+			if v <= 0 {
+				return errors.Errorf("cannot set jobs.scheduler.pace to a non-positive duration: %s", v)
+			}
+			return nil
+		})
+	schedulerMaxJobsPerIterationSetting = settings.RegisterPositiveIntSetting(
+		"jobs.scheduler.max_jobs_per_iteration",
+		"how many schedules to run at most per scan of system.scheduled_jobs",
+		10)
+)
+
+// ScheduledJobEnv is the environment in which ScheduledJobExecutors run the
+// jobs of schedules.
+type ScheduledJobEnv struct {
+	DB               *client.DB
+	InternalExecutor sqlutil.InternalExecutor
+	Stopper          *stop.Stopper
+	Settings         *cluster.Settings
+	// PlanHookMaker is a wrapper around sql.NewInternalPlanner, like the
+	// planHookMaker of the Registry, which plans in the given txn as the given
+	// user.
+	PlanHookMaker func(opName string, txn *client.Txn, user string) (interface{}, func())
+}
+
+// ScheduledJobExecutor runs the jobs of schedules of a certain executor type.
+type ScheduledJobExecutor interface {
+	// ExecuteJob creates the job of the given schedule in txn, the transaction
+	// which advances the next run of the schedule, and returns its ID. Each run
+	// of the schedule thus creates exactly one job across the cluster, even if
+	// several nodes notice it is due.
+	//
+	// The job must run as the owner of the schedule, and must only be started
+	// once txn commits, e.g. from a commit trigger. ExecuteJob shouldn't block
+	// the scheduler: long running work should be done asynchronously,
+	// recording its outcome with ScheduledJob.SetStatus.
+	ExecuteJob(
+		ctx context.Context, env ScheduledJobEnv, schedule *ScheduledJob, txn *client.Txn,
+	) (jobID int64, _ error)
+}
+
+// ScheduledJobExecutorFactory creates the executor of a certain executor type.
+type ScheduledJobExecutorFactory func() (ScheduledJobExecutor, error)
+
+var scheduledJobExecutorFactories = make(map[string]ScheduledJobExecutorFactory)
+
+// RegisterScheduledJobExecutorFactory registers the factory of the executor
+// running the schedules of the given executor type.
+func RegisterScheduledJobExecutorFactory(name string, factory ScheduledJobExecutorFactory) {
+	if _, ok := scheduledJobExecutorFactories[name]; ok {
+		panic("executor " + name + " already registered")
+	}
+	scheduledJobExecutorFactories[name] = factory
+}
+
+func newScheduledJobExecutor(name string) (ScheduledJobExecutor, error) {
+	factory := scheduledJobExecutorFactories[name]
+	if factory == nil {
+		return nil, errors.Errorf("no executor is available for %q", name)
+	}
+	return factory()
+}
+
+// JobScheduler runs the schedules of system.scheduled_jobs when they are due.
+//
+// Every node runs a JobScheduler, which periodically scans the table for the
+// schedules whose next run has passed. A schedule is adopted by advancing its
+// next run, according to its cron expression, in a transaction; only the
+// node whose transaction commits goes on to hand the schedule to its
+// executor, which creates the job of the schedule in the same transaction.
+type JobScheduler struct {
+	env ScheduledJobEnv
+}
+
+// NewJobScheduler creates a new JobScheduler. planFn is a wrapper around
+// sql.NewInternalPlanner, see ScheduledJobEnv.
+func NewJobScheduler(
+	db *client.DB,
+	ie sqlutil.InternalExecutor,
+	stopper *stop.Stopper,
+	st *cluster.Settings,
+	planFn func(opName string, txn *client.Txn, user string) (interface{}, func()),
+) *JobScheduler {
+	return &JobScheduler{
+		env: ScheduledJobEnv{
+			DB:               db,
+			InternalExecutor: ie,
+			Stopper:          stopper,
+			Settings:         st,
+			PlanHookMaker:    planFn,
+		},
+	}
+}
+
+// Start starts the loop running the due schedules.
+func (s *JobScheduler) Start(ctx context.Context, stopper *stop.Stopper) {
+	ctx, _ = stopper.WithCancelOnQuiesce(ctx)
+	stopper.RunWorker(ctx, func(ctx context.Context) {
+		for {
+			select {
+			case <-time.After(schedulerPaceSetting.Get(&s.env.Settings.SV)):
+				if !schedulerEnabledSetting.Get(&s.env.Settings.SV) {
+					continue
+				}
+				maxSchedules := schedulerMaxJobsPerIterationSetting.Get(&s.env.Settings.SV)
+				if err := s.executeSchedules(ctx, maxSchedules); err != nil {
+					log.Errorf(ctx, "error running scheduled jobs: %s", err)
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	})
+}
+
+// executeSchedules runs up to maxSchedules of the schedules which are due.
+func (s *JobScheduler) executeSchedules(ctx context.Context, maxSchedules int64) error {
+	if !cluster.Version.IsActive(ctx, s.env.Settings, cluster.VersionScheduledJobs) {
+		return nil
+	}
+	rows, err := s.env.InternalExecutor.Query(ctx, "find-due-schedules", nil, /* txn */
+		`SELECT schedule_id FROM system.scheduled_jobs WHERE next_run <= now()
+			ORDER BY next_run LIMIT $1`, maxSchedules)
+	if err != nil {
+		return err
+	}
+	for _, row := range rows {
+		id := int64(tree.MustBeDInt(row[0]))
+		if err := s.executeSchedule(ctx, id); err != nil {
+			log.Errorf(ctx, "error running schedule %d: %s", id, err)
+		}
+	}
+	return nil
+}
+
+// executeSchedule adopts the given schedule if it is still due and creates its
+// job.
+func (s *JobScheduler) executeSchedule(ctx context.Context, id int64) error {
+	// execErr is set when the job of the schedule couldn't be created.
+	var execErr error
+	if err := s.env.DB.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		execErr = nil
+		schedule, err := s.adoptDueSchedule(ctx, txn, id)
+		if err != nil || schedule == nil {
+			return err
+		}
+		executor, err := newScheduledJobExecutor(schedule.ExecutorType)
+		if err != nil {
+			execErr = err
+			return err
+		}
+		log.Infof(ctx, "running schedule %d (%s)", schedule.ID, schedule.Name)
+		jobID, err := executor.ExecuteJob(ctx, s.env, schedule, txn)
+		if err != nil {
+			execErr = err
+			return err
+		}
+		schedule.LastJobID = jobID
+		return schedule.Update(ctx, s.env.InternalExecutor, txn)
+	}); execErr == nil {
+		return err
+	}
+
+	// Skip the run, recording why it failed, rather than retrying it on every
+	// scan.
+	if err := s.env.DB.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		schedule, err := s.adoptDueSchedule(ctx, txn, id)
+		if err != nil || schedule == nil {
+			return err
+		}
+		schedule.Status = fmt.Sprintf("failed: %s", execErr)
+		return schedule.Update(ctx, s.env.InternalExecutor, txn)
+	}); err != nil {
+		log.Warningf(ctx, "failed to skip the run of schedule %d: %s", id, err)
+	}
+	return execErr
+}
+
+// adoptDueSchedule loads the given schedule if it is still due, and advances
+// its next run. The change isn't persisted until Update is called. A nil
+// schedule is returned if it isn't due anymore.
+func (s *JobScheduler) adoptDueSchedule(
+	ctx context.Context, txn *client.Txn, id int64,
+) (*ScheduledJob, error) {
+	// Another node may have adopted the schedule since it was found, in which
+	// case its next run is in the future.
+	row, err := s.env.InternalExecutor.QueryRow(ctx, "load-due-schedule", txn,
+		`SELECT `+scheduledJobColumns+` FROM system.scheduled_jobs
+			WHERE schedule_id = $1 AND next_run <= now()`, id)
+	if err != nil || row == nil {
+		return nil, err
+	}
+	schedule := scheduledJobFromRow(row)
+	if err := schedule.ScheduleNextRun(timeutil.Now()); err != nil {
+		// The schedule can't run again, so this run is its last one. Pause it
+		// so that it isn't picked up on every scan.
+		schedule.Pause()
+		schedule.Status = err.Error()
+	}
+	return schedule, nil
+}
@@ -0,0 +1,140 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package jobs_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlutil"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/pkg/errors"
+)
+
+// testScheduledJobExecutor is a ScheduledJobExecutor which "creates" jobs
+// by calling fn.
+type testScheduledJobExecutor struct {
+	fn func(schedule *jobs.ScheduledJob, txn *client.Txn) (int64, error)
+}
+
+func (e *testScheduledJobExecutor) ExecuteJob(
+	_ context.Context, _ jobs.ScheduledJobEnv, schedule *jobs.ScheduledJob, txn *client.Txn,
+) (int64, error) {
+	return e.fn(schedule, txn)
+}
+
+func TestJobSchedulerExecuteSchedule(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ctx := context.Background()
+	s, db, kvDB := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(ctx)
+	sqlDB := sqlutils.MakeSQLRunner(db)
+	sqlDB.Exec(t, `SET CLUSTER SETTING jobs.scheduler.enabled = false`)
+
+	executor := &testScheduledJobExecutor{}
+	jobs.RegisterScheduledJobExecutorFactory("test-executor",
+		func() (jobs.ScheduledJobExecutor, error) { return executor, nil })
+
+	ex := s.InternalExecutor().(sqlutil.InternalExecutor)
+	scheduler := jobs.NewJobScheduler(kvDB, ex, s.Stopper(), s.ClusterSettings(), nil /* planFn */)
+
+	// makeDueSchedule creates a schedule with the given cron expression, which
+	// is due.
+	makeDueSchedule := func(expr string) int64 {
+		t.Helper()
+		schedule, err := jobs.NewScheduledJob("test", "testuser", "@daily", "test-executor",
+			nil /* executionArgs */, timeutil.Now())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := schedule.Create(ctx, ex, nil /* txn */); err != nil {
+			t.Fatal(err)
+		}
+		sqlDB.Exec(t, `UPDATE system.scheduled_jobs
+			SET schedule_expr = $2, next_run = now() - '1h'::INTERVAL WHERE schedule_id = $1`,
+			schedule.ID, expr)
+		return schedule.ID
+	}
+	load := func(id int64) *jobs.ScheduledJob {
+		t.Helper()
+		schedule, err := jobs.LoadScheduledJob(ctx, ex, nil /* txn */, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return schedule
+	}
+
+	t.Run("execute", func(t *testing.T) {
+		id := makeDueSchedule("@daily")
+		var calls int
+		executor.fn = func(schedule *jobs.ScheduledJob, txn *client.Txn) (int64, error) {
+			calls++
+			if txn == nil {
+				return 0, errors.New("expected a txn")
+			}
+			if schedule.NextRun.Before(timeutil.Now()) {
+				return 0, errors.Errorf("expected the next run to be advanced, got %s", schedule.NextRun)
+			}
+			return 42, nil
+		}
+		if err := scheduler.ExecuteScheduleForTesting(ctx, id); err != nil {
+			t.Fatal(err)
+		}
+		schedule := load(id)
+		if calls != 1 || schedule.LastJobID != 42 || schedule.IsPaused() {
+			t.Fatalf("expected one run creating job 42, got %d runs, %+v", calls, schedule)
+		}
+
+		// The schedule isn't due anymore.
+		if err := scheduler.ExecuteScheduleForTesting(ctx, id); err != nil {
+			t.Fatal(err)
+		}
+		if calls != 1 {
+			t.Fatalf("expected the schedule not to run again, got %d runs", calls)
+		}
+	})
+
+	t.Run("last run", func(t *testing.T) {
+		// February 30th never comes.
+		id := makeDueSchedule("0 0 30 2 *")
+		executor.fn = func(*jobs.ScheduledJob, *client.Txn) (int64, error) { return 43, nil }
+		if err := scheduler.ExecuteScheduleForTesting(ctx, id); err != nil {
+			t.Fatal(err)
+		}
+		schedule := load(id)
+		if schedule.LastJobID != 43 || !schedule.IsPaused() {
+			t.Fatalf("expected a paused schedule having created job 43, got %+v", schedule)
+		}
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		id := makeDueSchedule("@daily")
+		executor.fn = func(*jobs.ScheduledJob, *client.Txn) (int64, error) {
+			return 0, errors.New("boom")
+		}
+		if err := scheduler.ExecuteScheduleForTesting(ctx, id); !testutils.IsError(err, "boom") {
+			t.Fatalf("expected boom, got %v", err)
+		}
+		schedule := load(id)
+		if schedule.LastJobID != 0 || schedule.IsPaused() ||
+			schedule.NextRun.Before(timeutil.Now()) || schedule.Status != "failed: boom" {
+			t.Fatalf("expected an advanced schedule recording the failure, got %+v", schedule)
+		}
+	})
+}
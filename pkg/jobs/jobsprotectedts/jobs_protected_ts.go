@@ -0,0 +1,82 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+// Package jobsprotectedts links protected timestamp records to the jobs which
+// created them.
+package jobsprotectedts
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlutil"
+	"github.com/cockroachdb/cockroach/pkg/storage/protectedts/ptpb"
+	"github.com/cockroachdb/cockroach/pkg/storage/protectedts/ptreconcile"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
+	"github.com/cockroachdb/errors"
+)
+
+// MetaType is the Meta type used for protected timestamp records associated
+// with jobs.
+const MetaType = "jobs"
+
+// MakeStatusFunc returns a function which determines whether the job implied
+// by the metadata of a record still needs the protection. Records of jobs
+// which no longer exist or which reached a terminal status are removed.
+func MakeStatusFunc(ie sqlutil.InternalExecutor) ptreconcile.StatusFunc {
+	return func(ctx context.Context, txn *client.Txn, meta []byte) (shouldRemove bool, _ error) {
+		jobID, err := decodeJobID(meta)
+		if err != nil {
+			return false, err
+		}
+		row, err := ie.QueryRow(ctx, "protectedts-job-status", txn,
+			"SELECT status FROM system.jobs WHERE id = $1", jobID)
+		if err != nil {
+			return false, err
+		}
+		if row == nil {
+			return true, nil
+		}
+		status := jobs.Status(tree.MustBeDString(row[0]))
+		return status.Terminal(), nil
+	}
+}
+
+// MakeRecord makes a protected timestamp record to protect a timestamp on
+// behalf of this job.
+func MakeRecord(
+	id uuid.UUID, jobID int64, tsToProtect hlc.Timestamp, spans []roachpb.Span,
+) *ptpb.Record {
+	return &ptpb.Record{
+		ID:        id,
+		Timestamp: tsToProtect,
+		Mode:      ptpb.PROTECT_AFTER,
+		MetaType:  MetaType,
+		Meta:      encodeJobID(jobID),
+		Spans:     spans,
+	}
+}
+
+func encodeJobID(jobID int64) []byte {
+	return []byte(strconv.FormatInt(jobID, 10))
+}
+
+func decodeJobID(meta []byte) (jobID int64, err error) {
+	jobID, err = strconv.ParseInt(string(meta), 10, 64)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to interpret meta %q as a job ID", meta)
+	}
+	return jobID, nil
+}
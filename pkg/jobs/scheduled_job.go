@@ -0,0 +1,225 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlutil"
+	"github.com/cockroachdb/cockroach/pkg/util/cron"
+	"github.com/pkg/errors"
+)
+
+// ScheduledJob is a job run periodically, according to a cron expression, by
+// the job scheduler. What running the job means is up to the executor named
+// by ExecutorType, which is handed the ExecutionArgs of the schedule.
+//
+// Schedules are stored in the `system.scheduled_jobs` table. A schedule whose
+// NextRun is the zero time is paused.
+type ScheduledJob struct {
+	// ID is assigned when the schedule is created.
+	ID            int64
+	Name          string
+	Created       time.Time
+	Owner         string
+	NextRun       time.Time
+	ScheduleExpr  string
+	ExecutorType  string
+	ExecutionArgs []byte
+	// Status is a free form description of the outcome of the last run of
+	// the schedule, set by its executor.
+	Status string
+	// LastJobID is the ID of the job created by the last run of the schedule,
+	// or zero if it hasn't run yet.
+	LastJobID int64
+}
+
+// NewScheduledJob returns a new schedule, which starts running at the next
+// time the given cron expression fires after now. The schedule isn't
+// persisted until Create is called.
+func NewScheduledJob(
+	name, owner, scheduleExpr, executorType string, executionArgs []byte, now time.Time,
+) (*ScheduledJob, error) {
+	j := &ScheduledJob{
+		Name:          name,
+		Owner:         owner,
+		ScheduleExpr:  scheduleExpr,
+		ExecutorType:  executorType,
+		ExecutionArgs: executionArgs,
+	}
+	if err := j.ScheduleNextRun(now); err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+// IsPaused returns whether the schedule is paused.
+func (j *ScheduledJob) IsPaused() bool {
+	return j.NextRun.IsZero()
+}
+
+// Pause pauses the schedule. The change isn't persisted until Update is
+// called.
+func (j *ScheduledJob) Pause() {
+	j.NextRun = time.Time{}
+}
+
+// ScheduleNextRun sets the next run of the schedule to the next time its
+// cron expression fires after now, resuming it if it was paused. The change
+// isn't persisted until Update is called.
+func (j *ScheduledJob) ScheduleNextRun(now time.Time) error {
+	expr, err := cron.Parse(j.ScheduleExpr)
+	if err != nil {
+		return err
+	}
+	next := expr.Next(now.UTC())
+	if next.IsZero() {
+		return errors.Errorf("schedule %q never runs", j.ScheduleExpr)
+	}
+	j.NextRun = next
+	return nil
+}
+
+// nextRunDatum returns the value of the next_run column of the schedule.
+func (j *ScheduledJob) nextRunDatum() tree.Datum {
+	if j.IsPaused() {
+		return tree.DNull
+	}
+	return tree.MakeDTimestampTZ(j.NextRun, time.Microsecond)
+}
+
+// Create persists a new schedule, and assigns its ID.
+func (j *ScheduledJob) Create(
+	ctx context.Context, ex sqlutil.InternalExecutor, txn *client.Txn,
+) error {
+	if j.ID != 0 {
+		return errors.Errorf("schedule %d already created", j.ID)
+	}
+	row, err := ex.QueryRow(ctx, "create-schedule", txn,
+		`INSERT INTO system.scheduled_jobs (schedule_name, created, owner, next_run, schedule_expr,
+			executor_type, execution_args) VALUES ($1, now(), $2, $3, $4, $5, $6)
+			RETURNING schedule_id, created`,
+		j.Name, j.Owner, j.nextRunDatum(), j.ScheduleExpr, j.ExecutorType, j.ExecutionArgs)
+	if err != nil {
+		return errors.Wrap(err, "failed to create schedule")
+	}
+	j.ID = int64(tree.MustBeDInt(row[0]))
+	j.Created = row[1].(*tree.DTimestampTZ).Time
+	return nil
+}
+
+// Update persists the next run, the status and the last job of the schedule.
+func (j *ScheduledJob) Update(
+	ctx context.Context, ex sqlutil.InternalExecutor, txn *client.Txn,
+) error {
+	var status, lastJobID interface{}
+	if j.Status != "" {
+		status = j.Status
+	}
+	if j.LastJobID != 0 {
+		lastJobID = j.LastJobID
+	}
+	n, err := ex.Exec(ctx, "update-schedule", txn,
+		`UPDATE system.scheduled_jobs SET next_run = $2, schedule_status = $3, last_job_id = $4
+			WHERE schedule_id = $1`,
+		j.ID, j.nextRunDatum(), status, lastJobID)
+	if err != nil {
+		return err
+	}
+	if n != 1 {
+		return errScheduleNotFound(j.ID)
+	}
+	return nil
+}
+
+// SetStatus records the outcome of a run of the schedule. Unlike Update, it
+// leaves the next run of the schedule alone, so executors may call it while
+// the scheduler advances the schedule.
+func (j *ScheduledJob) SetStatus(
+	ctx context.Context, ex sqlutil.InternalExecutor, txn *client.Txn, status string,
+) error {
+	n, err := ex.Exec(ctx, "set-schedule-status", txn,
+		`UPDATE system.scheduled_jobs SET schedule_status = $2 WHERE schedule_id = $1`,
+		j.ID, status)
+	if err != nil {
+		return err
+	}
+	if n != 1 {
+		return errScheduleNotFound(j.ID)
+	}
+	j.Status = status
+	return nil
+}
+
+// Delete deletes the schedule.
+func (j *ScheduledJob) Delete(
+	ctx context.Context, ex sqlutil.InternalExecutor, txn *client.Txn,
+) error {
+	n, err := ex.Exec(ctx, "delete-schedule", txn,
+		`DELETE FROM system.scheduled_jobs WHERE schedule_id = $1`, j.ID)
+	if err != nil {
+		return err
+	}
+	if n != 1 {
+		return errScheduleNotFound(j.ID)
+	}
+	return nil
+}
+
+const scheduledJobColumns = `schedule_id, schedule_name, created, owner, next_run, schedule_expr,
+	executor_type, execution_args, schedule_status, last_job_id`
+
+// LoadScheduledJob loads the schedule with the given ID.
+func LoadScheduledJob(
+	ctx context.Context, ex sqlutil.InternalExecutor, txn *client.Txn, id int64,
+) (*ScheduledJob, error) {
+	row, err := ex.QueryRow(ctx, "load-schedule", txn,
+		`SELECT `+scheduledJobColumns+` FROM system.scheduled_jobs WHERE schedule_id = $1`, id)
+	if err != nil {
+		return nil, err
+	}
+	if row == nil {
+		return nil, errScheduleNotFound(id)
+	}
+	return scheduledJobFromRow(row), nil
+}
+
+// scheduledJobFromRow builds a schedule from a row of system.scheduled_jobs
+// holding the scheduledJobColumns.
+func scheduledJobFromRow(row tree.Datums) *ScheduledJob {
+	j := &ScheduledJob{
+		ID:            int64(tree.MustBeDInt(row[0])),
+		Name:          string(tree.MustBeDString(row[1])),
+		Created:       row[2].(*tree.DTimestampTZ).Time,
+		Owner:         string(tree.MustBeDString(row[3])),
+		ScheduleExpr:  string(tree.MustBeDString(row[5])),
+		ExecutorType:  string(tree.MustBeDString(row[6])),
+		ExecutionArgs: []byte(tree.MustBeDBytes(row[7])),
+	}
+	if row[4] != tree.DNull {
+		j.NextRun = row[4].(*tree.DTimestampTZ).Time
+	}
+	if row[8] != tree.DNull {
+		j.Status = string(tree.MustBeDString(row[8]))
+	}
+	if row[9] != tree.DNull {
+		j.LastJobID = int64(tree.MustBeDInt(row[9]))
+	}
+	return j
+}
+
+func errScheduleNotFound(id int64) error {
+	return fmt.Errorf("schedule with ID %d does not exist", id)
+}
@@ -346,6 +346,16 @@ const (
 	ProtectedTimestampsMetaTableID    = 31
 	ProtectedTimestampsRecordsTableID = 32
 
+	StatementStatisticsTableID = 33
+
+	StatementBundleChunksTableID        = 34
+	StatementDiagnosticsRequestsTableID = 35
+	StatementDiagnosticsTableID         = 36
+
+	StatementPlanPinsTableID = 37
+
+	ScheduledJobsTableID = 38
+
 	// CommentType is type for system.comments
 	DatabaseCommentType = 0
 	TableCommentType    = 1
@@ -495,16 +495,23 @@ func (ds *DistSender) getDescriptor(
 func (ds *DistSender) sendSingleRange(
 	ctx context.Context, ba roachpb.BatchRequest, desc *roachpb.RangeDescriptor, withCommit bool,
 ) (*roachpb.BatchResponse, *roachpb.Error) {
+	canSendToFollower := ds.clusterID != nil &&
+		CanSendToFollower(ds.clusterID.Get(), ds.st, ba)
+
 	// Try to send the call. Learner replicas won't serve reads/writes, so send
-	// only to the `Voters` replicas. This is just an optimization to save a
-	// network hop, everything would still work if we had `All` here.
-	replicas := NewReplicaSlice(ds.gossip, desc.Replicas().Voters())
+	// only to the `Voters` replicas, plus the non-voting replicas if the request
+	// can be served as a follower read or doesn't need the lease at all. This is
+	// just an optimization to save a network hop, everything would still work if
+	// we had `All` here.
+	replicaDescs := desc.Replicas().Voters()
+	if canSendToFollower || !ba.RequiresLeaseHolder() {
+		replicaDescs = desc.Replicas().VotersAndNonVoters()
+	}
+	replicas := NewReplicaSlice(ds.gossip, replicaDescs)
 
 	// If this request needs to go to a lease holder and we know who that is, move
 	// it to the front.
 	var cachedLeaseHolder roachpb.ReplicaDescriptor
-	canSendToFollower := ds.clusterID != nil &&
-		CanSendToFollower(ds.clusterID.Get(), ds.st, ba)
 	if !canSendToFollower && ba.RequiresLeaseHolder() {
 		if storeID, ok := ds.leaseHolderCache.Lookup(ctx, desc.RangeID); ok {
 			if i := replicas.FindReplica(storeID); i >= 0 {
@@ -896,6 +896,14 @@ func (tc *TxnCoordSender) SetFixedTimestamp(ctx context.Context, ts hlc.Timestam
 	tc.mu.Unlock()
 }
 
+// SetBoundedStalenessTimestamp is part of the client.TxnSender interface.
+func (tc *TxnCoordSender) SetBoundedStalenessTimestamp(ctx context.Context, ts hlc.Timestamp) {
+	tc.SetFixedTimestamp(ctx, ts)
+	tc.mu.Lock()
+	tc.mu.txn.BoundedStaleness = true
+	tc.mu.Unlock()
+}
+
 // ManualRestart is part of the client.TxnSender interface.
 func (tc *TxnCoordSender) ManualRestart(
 	ctx context.Context, pri roachpb.UserPriority, ts hlc.Timestamp,
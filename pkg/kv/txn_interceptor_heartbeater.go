@@ -401,7 +401,10 @@ func firstWriteIndex(ba *roachpb.BatchRequest) (int, *roachpb.Error) {
 				return -1, roachpb.NewErrorf("%s sent as non-terminal call", args.Method())
 			}
 		}
-		if roachpb.IsTransactionWrite(args) {
+		// Locking reads count as writes: the transaction must heartbeat its
+		// record so that other transactions don't consider its locks
+		// abandoned.
+		if roachpb.IsTransactionWrite(args) || roachpb.IsLockingRead(args) {
 			return i, nil
 		}
 	}
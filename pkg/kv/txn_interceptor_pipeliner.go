@@ -267,6 +267,10 @@ func (tp *txnPipeliner) attachWritesToEndTxn(
 				w := roachpb.SequencedWrite{Key: h.Key, Sequence: h.Sequence}
 				et.InFlightWrites = append(et.InFlightWrites, w)
 			}
+		} else if roachpb.IsLockingRead(req) {
+			// Locking reads acquire unreplicated locks, which are released
+			// along with the transaction's intents.
+			et.IntentSpans = append(et.IntentSpans, h.Span())
 		}
 	}
 
@@ -505,6 +509,13 @@ func (tp *txnPipeliner) updateWriteTracking(
 					tp.footprint.insert(sp)
 				}
 			}
+		} else if roachpb.IsLockingRead(req) {
+			// Locking reads acquire unreplicated locks on the leaseholder,
+			// which are released when the transaction resolves its intents.
+			// Track them in the write footprint so that they are.
+			if sp, ok := roachpb.ActualSpan(req, resp); ok {
+				tp.footprint.insert(sp)
+			}
 		}
 	}
 }
@@ -127,6 +127,18 @@ func IsTransactionWrite(args Request) bool {
 	return (args.flags() & isTxnWrite) != 0
 }
 
+// IsLockingRead returns true if the request is a read which acquires
+// unreplicated locks on the keys it returns.
+func IsLockingRead(args Request) bool {
+	switch t := args.(type) {
+	case *ScanRequest:
+		return t.KeyLocking
+	case *ReverseScanRequest:
+		return t.KeyLocking
+	}
+	return false
+}
+
 // IsRange returns true if the command is range-based and must include
 // a start and an end key.
 func IsRange(args Request) bool {
@@ -356,6 +368,20 @@ func (r *AdminScatterResponse) combine(c combinable) error {
 
 var _ combinable = &AdminScatterResponse{}
 
+// Combine implements the combinable interface.
+func (r *QueryResolvedTimestampResponse) combine(c combinable) error {
+	if r != nil {
+		otherR := c.(*QueryResolvedTimestampResponse)
+		if err := r.ResponseHeader.combine(otherR.Header()); err != nil {
+			return err
+		}
+		r.ResolvedTS.Backward(otherR.ResolvedTS)
+	}
+	return nil
+}
+
+var _ combinable = &QueryResolvedTimestampResponse{}
+
 // Header implements the Request interface.
 func (rh RequestHeader) Header() RequestHeader {
 	return rh
@@ -400,6 +426,7 @@ func (h *BatchResponse_Header) combine(o BatchResponse_Header) error {
 	}
 	h.Now.Forward(o.Now)
 	h.CollectedSpans = append(h.CollectedSpans, o.CollectedSpans...)
+	h.ContentionTime += o.ContentionTime
 	return nil
 }
 
@@ -597,6 +624,9 @@ func (*SubsumeRequest) Method() Method { return Subsume }
 // Method implements the Request interface.
 func (*RangeStatsRequest) Method() Method { return RangeStats }
 
+// Method implements the Request interface.
+func (*QueryResolvedTimestampRequest) Method() Method { return QueryResolvedTimestamp }
+
 // ShallowCopy implements the Request interface.
 func (gr *GetRequest) ShallowCopy() Request {
 	shallowCopy := *gr
@@ -855,6 +885,12 @@ func (r *RangeStatsRequest) ShallowCopy() Request {
 	return &shallowCopy
 }
 
+// ShallowCopy implements the Request interface.
+func (r *QueryResolvedTimestampRequest) ShallowCopy() Request {
+	shallowCopy := *r
+	return &shallowCopy
+}
+
 // NewGet returns a Request initialized to get the value at key.
 func NewGet(key Key) Request {
 	return &GetRequest{
@@ -1135,8 +1171,9 @@ func (r *RefreshRangeRequest) flags() int {
 	return isRead | isTxn | isRange | updatesReadTSCache
 }
 
-func (*SubsumeRequest) flags() int    { return isRead | isAlone | updatesReadTSCache }
-func (*RangeStatsRequest) flags() int { return isRead }
+func (*SubsumeRequest) flags() int                { return isRead | isAlone | updatesReadTSCache }
+func (*RangeStatsRequest) flags() int             { return isRead }
+func (*QueryResolvedTimestampRequest) flags() int { return isRead | isRange }
 
 // IsParallelCommit returns whether the EndTransaction request is attempting to
 // perform a parallel commit. See txn_interceptor_committer.go for a discussion
@@ -1231,6 +1268,18 @@ func (rc ReplicationChanges) Removals() []ReplicationTarget {
 	return rc.byType(REMOVE_REPLICA)
 }
 
+// NonVoterAdditions returns a slice of all contained replication changes that
+// add non-voting replicas.
+func (rc ReplicationChanges) NonVoterAdditions() []ReplicationTarget {
+	return rc.byType(ADD_NON_VOTER)
+}
+
+// NonVoterRemovals returns a slice of all contained replication changes that
+// remove non-voting replicas.
+func (rc ReplicationChanges) NonVoterRemovals() []ReplicationTarget {
+	return rc.byType(REMOVE_NON_VOTER)
+}
+
 // Changes returns the changes requested by this AdminChangeReplicasRequest, taking
 // the deprecated method of doing so into account.
 func (acrr *AdminChangeReplicasRequest) Changes() []ReplicationChange {
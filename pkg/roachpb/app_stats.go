@@ -44,6 +44,31 @@ func AddNumericStats(a, b NumericStat, countA, countB int64) NumericStat {
 	}
 }
 
+// Add combines other into this StatementStatistics.
+func (s *StatementStatistics) Add(other *StatementStatistics) {
+	s.FirstAttemptCount += other.FirstAttemptCount
+	if other.MaxRetries > s.MaxRetries {
+		s.MaxRetries = other.MaxRetries
+	}
+	s.NumRows.Add(other.NumRows, s.Count, other.Count)
+	s.ParseLat.Add(other.ParseLat, s.Count, other.Count)
+	s.PlanLat.Add(other.PlanLat, s.Count, other.Count)
+	s.RunLat.Add(other.RunLat, s.Count, other.Count)
+	s.ServiceLat.Add(other.ServiceLat, s.Count, other.Count)
+	s.OverheadLat.Add(other.OverheadLat, s.Count, other.Count)
+	s.BytesRead.Add(other.BytesRead, s.Count, other.Count)
+	s.RowsRead.Add(other.RowsRead, s.Count, other.Count)
+
+	if other.SensitiveInfo.LastErr != "" {
+		s.SensitiveInfo.LastErr = other.SensitiveInfo.LastErr
+	}
+	if s.SensitiveInfo.MostRecentPlanTimestamp.Before(other.SensitiveInfo.MostRecentPlanTimestamp) {
+		s.SensitiveInfo = other.SensitiveInfo
+	}
+
+	s.Count += other.Count
+}
+
 // GetScrubbedCopy returns a copy of the given SensitiveInfo with its fields redacted
 // or omitted entirely. By default, fields are omitted: if a new field is
 // added to the SensitiveInfo proto, it must be added here to make it to the
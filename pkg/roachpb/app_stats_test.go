@@ -81,3 +81,51 @@ func TestAddNumericStats(t *testing.T) {
 		t.Fatalf("a.Add(b) should match add(a, b): %+v vs %+v", a, combined)
 	}
 }
+
+func TestAddStatementStatistics(t *testing.T) {
+	var a, b, ab StatementStatistics
+	record := func(s *StatementStatistics, rows, lat, bytesRead float64, retries int64) {
+		s.Count++
+		if retries == 0 {
+			s.FirstAttemptCount++
+		} else if retries > s.MaxRetries {
+			s.MaxRetries = retries
+		}
+		s.NumRows.Record(s.Count, rows)
+		s.ServiceLat.Record(s.Count, lat)
+		s.BytesRead.Record(s.Count, bytesRead)
+	}
+	record(&a, 1, 0.5, 100, 0)
+	record(&a, 3, 1.5, 101, 2)
+	record(&b, 2, 0.1, 400, 0)
+	record(&b, 5, 0.2, 401, 1)
+	record(&b, 4, 0.3, 403, 0)
+	record(&ab, 1, 0.5, 100, 0)
+	record(&ab, 3, 1.5, 101, 2)
+	record(&ab, 2, 0.1, 400, 0)
+	record(&ab, 5, 0.2, 401, 1)
+	record(&ab, 4, 0.3, 403, 0)
+
+	a.Add(&b)
+
+	const epsilon = 0.0000001
+	if a.Count != ab.Count || a.FirstAttemptCount != ab.FirstAttemptCount ||
+		a.MaxRetries != ab.MaxRetries {
+		t.Fatalf("expected counts %+v, got %+v", ab, a)
+	}
+	if e := math.Abs(a.NumRows.Mean - ab.NumRows.Mean); e > epsilon {
+		t.Fatalf("NumRows mean of combined %f does not match ab %f", a.NumRows.Mean, ab.NumRows.Mean)
+	}
+	if e := math.Abs(a.ServiceLat.SquaredDiffs - ab.ServiceLat.SquaredDiffs); e > epsilon {
+		t.Fatalf("ServiceLat SquaredDiffs of combined %f does not match ab %f",
+			a.ServiceLat.SquaredDiffs, ab.ServiceLat.SquaredDiffs)
+	}
+	// The mean of BytesRead isn't truncated to an integer.
+	if e := math.Abs(a.BytesRead.Mean - 281); e > epsilon {
+		t.Fatalf("expected BytesRead mean 281, got %f", a.BytesRead.Mean)
+	}
+	if e := math.Abs(a.BytesRead.SquaredDiffs - ab.BytesRead.SquaredDiffs); e > epsilon {
+		t.Fatalf("BytesRead SquaredDiffs of combined %f does not match ab %f",
+			a.BytesRead.SquaredDiffs, ab.BytesRead.SquaredDiffs)
+	}
+}
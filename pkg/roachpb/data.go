@@ -961,6 +961,7 @@ func (t *Transaction) Restart(
 	t.Sequence = 0
 	t.WriteTooOld = false
 	t.CommitTimestampFixed = false
+	t.BoundedStaleness = false
 	t.IntentSpans = nil
 	t.InFlightWrites = nil
 }
@@ -1009,6 +1010,7 @@ func (t *Transaction) Update(o *Transaction) {
 		t.Status = o.Status
 		t.WriteTooOld = o.WriteTooOld
 		t.CommitTimestampFixed = o.CommitTimestampFixed
+		t.BoundedStaleness = o.BoundedStaleness
 		t.Sequence = o.Sequence
 		t.IntentSpans = o.IntentSpans
 		t.InFlightWrites = o.InFlightWrites
@@ -1034,9 +1036,11 @@ func (t *Transaction) Update(o *Transaction) {
 		if t.ReadTimestamp.Less(o.ReadTimestamp) {
 			t.WriteTooOld = o.WriteTooOld
 			t.CommitTimestampFixed = o.CommitTimestampFixed
+			t.BoundedStaleness = o.BoundedStaleness
 		} else {
 			t.WriteTooOld = t.WriteTooOld || o.WriteTooOld
 			t.CommitTimestampFixed = t.CommitTimestampFixed || o.CommitTimestampFixed
+			t.BoundedStaleness = t.BoundedStaleness || o.BoundedStaleness
 		}
 
 		if t.Sequence < o.Sequence {
@@ -1431,6 +1435,12 @@ func confChangeImpl(
 			if err := checkNotExists(rDesc); err != nil {
 				return nil, err
 			}
+		case NON_VOTER:
+			// Non-voters are removed directly, without going through joint
+			// consensus.
+			if err := checkNotExists(rDesc); err != nil {
+				return nil, err
+			}
 		case VOTER_FULL:
 			// A voter can't be in the descriptor if it's being removed.
 			if err := checkNotExists(rDesc); err != nil {
@@ -1465,6 +1475,9 @@ func confChangeImpl(
 			// Demotions (i.e. transitioning from voter to learner) are not
 			// represented in `added`; they're handled in `removed` above.
 			changeType = raftpb.ConfChangeAddLearnerNode
+		case NON_VOTER:
+			// We're adding a non-voter, which raft treats as a learner.
+			changeType = raftpb.ConfChangeAddLearnerNode
 		default:
 			// A voter that is demoting was just removed and re-added in the
 			// `removals` handler. We should not see it again here.
@@ -490,6 +490,7 @@ var nonZeroTxn = Transaction{
 	IntentSpans:             []Span{{Key: []byte("a"), EndKey: []byte("b")}},
 	InFlightWrites:          []SequencedWrite{{Key: []byte("c"), Sequence: 1}},
 	CommitTimestampFixed:    true,
+	BoundedStaleness:        true,
 }
 
 func TestTransactionUpdate(t *testing.T) {
@@ -552,6 +553,7 @@ func TestTransactionUpdate(t *testing.T) {
 	expTxn5.InFlightWrites = nil
 	expTxn5.WriteTooOld = false
 	expTxn5.CommitTimestampFixed = false
+	expTxn5.BoundedStaleness = false
 	require.Equal(t, expTxn5, txn5)
 
 	// Updating a different transaction fatals.
@@ -664,6 +666,7 @@ func TestTransactionRestart(t *testing.T) {
 	expTxn.DeprecatedOrigTimestamp = expTxn.ReadTimestamp
 	expTxn.WriteTooOld = false
 	expTxn.CommitTimestampFixed = false
+	expTxn.BoundedStaleness = false
 	expTxn.IntentSpans = nil
 	expTxn.InFlightWrites = nil
 	require.Equal(t, expTxn, txn)
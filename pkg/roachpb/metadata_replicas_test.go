@@ -34,6 +34,7 @@ var vi = ReplicaTypeVoterIncoming()
 var vo = ReplicaTypeVoterOutgoing()
 var vd = ReplicaTypeVoterDemoting()
 var l = ReplicaTypeLearner()
+var nv = ReplicaTypeNonVoter()
 
 func TestVotersLearnersAll(t *testing.T) {
 
@@ -49,6 +50,8 @@ func TestVotersLearnersAll(t *testing.T) {
 		{rd(vi, 1)},
 		{rd(vo, 1)},
 		{rd(l, 1), rd(vo, 2), rd(vi, 3), rd(vi, 4)},
+		{rd(nv, 1)},
+		{rd(v, 1), rd(nv, 2), rd(l, 3)},
 	}
 	for _, test := range tests {
 		t.Run("", func(t *testing.T) {
@@ -67,10 +70,14 @@ func TestVotersLearnersAll(t *testing.T) {
 				seen[learner] = struct{}{}
 				assert.Equal(t, LEARNER, learner.GetType())
 			}
+			for _, nonVoter := range r.NonVoters() {
+				seen[nonVoter] = struct{}{}
+				assert.Equal(t, NON_VOTER, nonVoter.GetType())
+			}
 
 			all := r.All()
-			// Make sure that VOTER_OUTGOING is the only type that is skipped both
-			// by Learners() and Voters()
+			// Make sure that VOTER_OUTGOING is the only type that is skipped by
+			// Learners(), NonVoters() and Voters()
 			for _, rd := range all {
 				typ := rd.GetType()
 				if _, seen := seen[rd]; !seen {
@@ -155,6 +162,11 @@ func TestReplicaDescriptorsConfState(t *testing.T) {
 			[]ReplicaDescriptor{rd(l, 1), rd(vn, 2)},
 			"Voters:[2] VotersOutgoing:[] Learners:[1] LearnersNext:[] AutoLeave:false",
 		},
+		// Non-voters are raft learners.
+		{
+			[]ReplicaDescriptor{rd(v, 1), rd(nv, 2), rd(l, 3)},
+			"Voters:[1] VotersOutgoing:[] Learners:[2 3] LearnersNext:[] AutoLeave:false",
+		},
 		// First joint case. We're adding n3 (via atomic replication changes), so the outgoing
 		// config we have to get rid of consists only of n2 (even though n2 remains a voter).
 		// Note that we could simplify this config so that it's not joint, but raft expects
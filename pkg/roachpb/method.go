@@ -152,4 +152,7 @@ const (
 	Subsume
 	// RangeStats returns the MVCC statistics for a range.
 	RangeStats
+	// QueryResolvedTimestamp requests the resolved timestamp of the key span it
+	// is issued over.
+	QueryResolvedTimestamp
 )
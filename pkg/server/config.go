@@ -494,8 +494,8 @@ func (cfg *Config) CreateEngines(ctx context.Context) (Engines, error) {
 					spec.Size.Percent, spec.Path, humanizeutil.IBytes(sizeInBytes), humanizeutil.IBytes(base.MinimumStoreSize))
 			}
 
-			details = append(details, fmt.Sprintf("store %d: RocksDB, max size %s, max open file limit %d",
-				i, humanizeutil.IBytes(sizeInBytes), openFileLimitPerStore))
+			details = append(details, fmt.Sprintf("store %d: %s, max size %s, max open file limit %d",
+				i, cfg.StorageEngine.String(), humanizeutil.IBytes(sizeInBytes), openFileLimitPerStore))
 
 			var eng engine.Engine
 			var err error
@@ -0,0 +1,101 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package server
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ContentionEvents returns the contention events recorded by the requested
+// node, or by all the nodes in the cluster if no node is specified.
+func (s *statusServer) ContentionEvents(
+	ctx context.Context, req *serverpb.ContentionEventsRequest,
+) (*serverpb.ContentionEventsResponse, error) {
+	if _, err := s.admin.requireAdminUser(ctx); err != nil {
+		return nil, err
+	}
+
+	ctx = propagateGatewayMetadata(ctx)
+	ctx = s.AnnotateCtx(ctx)
+
+	localReq := &serverpb.ContentionEventsRequest{
+		NodeID: "local",
+	}
+
+	if len(req.NodeID) > 0 {
+		requestedNodeID, local, err := s.parseNodeID(req.NodeID)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, err.Error())
+		}
+		if local {
+			return s.contentionEventsLocal(), nil
+		}
+		status, err := s.dialNode(ctx, requestedNodeID)
+		if err != nil {
+			return nil, err
+		}
+		return status.ContentionEvents(ctx, localReq)
+	}
+
+	response := &serverpb.ContentionEventsResponse{
+		Events: []serverpb.ContentionEvent{},
+	}
+	dialFn := func(ctx context.Context, nodeID roachpb.NodeID) (interface{}, error) {
+		client, err := s.dialNode(ctx, nodeID)
+		return client, err
+	}
+	nodeFn := func(ctx context.Context, client interface{}, _ roachpb.NodeID) (interface{}, error) {
+		status := client.(serverpb.StatusClient)
+		return status.ContentionEvents(ctx, localReq)
+	}
+	if err := s.iterateNodes(ctx, fmt.Sprintf("contention events for node %s", req.NodeID),
+		dialFn,
+		nodeFn,
+		func(nodeID roachpb.NodeID, resp interface{}) {
+			eventsResp := resp.(*serverpb.ContentionEventsResponse)
+			response.Events = append(response.Events, eventsResp.Events...)
+		},
+		func(nodeID roachpb.NodeID, err error) {
+			log.Warningf(ctx, "failed to collect contention events from n%d: %v", nodeID, err)
+		},
+	); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// contentionEventsLocal returns the contention events recorded by this node.
+func (s *statusServer) contentionEventsLocal() *serverpb.ContentionEventsResponse {
+	events := s.contentionRegistry.Events()
+	nodeID := s.gossip.NodeID.Get()
+	resp := &serverpb.ContentionEventsResponse{
+		Events: make([]serverpb.ContentionEvent, len(events)),
+	}
+	for i, e := range events {
+		resp.Events[i] = serverpb.ContentionEvent{
+			NodeID:        nodeID,
+			Key:           e.Key,
+			BlockingTxnID: e.BlockingTxnID,
+			WaitingTxnID:  e.WaitingTxnID,
+			Time:          e.Time,
+			Duration:      e.Duration,
+			Source:        string(e.Source),
+		}
+	}
+	return resp
+}
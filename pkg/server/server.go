@@ -35,6 +35,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/gossip"
 	"github.com/cockroachdb/cockroach/pkg/internal/client"
 	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobsprotectedts"
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/kv"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
@@ -53,23 +54,30 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/execinfra"
 	"github.com/cockroachdb/cockroach/pkg/sql/execinfrapb"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire"
+	"github.com/cockroachdb/cockroach/pkg/sql/planpin"
 	"github.com/cockroachdb/cockroach/pkg/sql/querycache"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlutil"
 	"github.com/cockroachdb/cockroach/pkg/sql/stats"
+	"github.com/cockroachdb/cockroach/pkg/sql/stmtdiagnostics"
 	"github.com/cockroachdb/cockroach/pkg/sqlmigrations"
 	"github.com/cockroachdb/cockroach/pkg/storage"
 	"github.com/cockroachdb/cockroach/pkg/storage/bulk"
 	"github.com/cockroachdb/cockroach/pkg/storage/closedts/container"
 	"github.com/cockroachdb/cockroach/pkg/storage/cloud"
+	"github.com/cockroachdb/cockroach/pkg/storage/contention"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
+	"github.com/cockroachdb/cockroach/pkg/storage/protectedts"
+	"github.com/cockroachdb/cockroach/pkg/storage/protectedts/ptprovider"
+	"github.com/cockroachdb/cockroach/pkg/storage/protectedts/ptreconcile"
 	"github.com/cockroachdb/cockroach/pkg/storage/reports"
 	"github.com/cockroachdb/cockroach/pkg/storage/storagebase"
 	"github.com/cockroachdb/cockroach/pkg/ts"
 	"github.com/cockroachdb/cockroach/pkg/ui"
 	"github.com/cockroachdb/cockroach/pkg/util"
+	"github.com/cockroachdb/cockroach/pkg/util/admission"
 	"github.com/cockroachdb/cockroach/pkg/util/envutil"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/httputil"
@@ -192,15 +200,26 @@ type Server struct {
 	blobService      *blobs.Service
 	// sessionRegistry can be queried for info on running SQL sessions. It is
 	// shared between the sql.Server and the statusServer.
-	sessionRegistry     *sql.SessionRegistry
-	jobRegistry         *jobs.Registry
-	statsRefresher      *stats.Refresher
-	replicationReporter *reports.Reporter
-	engines             Engines
-	internalMemMetrics  sql.MemoryMetrics
-	adminMemMetrics     sql.MemoryMetrics
+	sessionRegistry         *sql.SessionRegistry
+	jobRegistry             *jobs.Registry
+	statsRefresher          *stats.Refresher
+	replicationReporter     *reports.Reporter
+	stmtDiagnosticsRegistry *stmtdiagnostics.Registry
+	planPinRegistry         *planpin.Registry
+	jobScheduler            *jobs.JobScheduler
+	protectedtsProvider     protectedts.Provider
+	protectedtsReconciler   *ptreconcile.Reconciler
+	engines                 Engines
+	internalMemMetrics      sql.MemoryMetrics
+	adminMemMetrics         sql.MemoryMetrics
 	// sqlMemMetrics are used to track memory usage of sql sessions.
 	sqlMemMetrics sql.MemoryMetrics
+	// contentionRegistry records the contention events observed by the
+	// node's stores. It is shared between the stores and the statusServer.
+	contentionRegistry *contention.Registry
+	// admissionController adjusts the admission queues of KV requests and
+	// DistSQL flows to the load of the node.
+	admissionController *admission.Controller
 }
 
 // NewServer creates a Server from a server.Config.
@@ -477,6 +496,36 @@ func NewServer(cfg Config, stopper *stop.Stopper) (*Server, error) {
 	// Similarly for execCfg.
 	var execCfg sql.ExecutorConfig
 
+	s.contentionRegistry = contention.NewRegistry()
+
+	s.admissionController = admission.NewController(
+		st, s.cfg.HistogramWindowInterval(), func() int64 {
+			var l0FileCount int64
+			for _, eng := range s.engines {
+				stats, err := eng.GetStats()
+				if err != nil {
+					continue
+				}
+				if stats.L0FileCount > l0FileCount {
+					l0FileCount = stats.L0FileCount
+				}
+			}
+			return l0FileCount
+		})
+	for _, m := range s.admissionController.Metrics() {
+		s.registry.AddMetricStruct(m)
+	}
+
+	protectedtsProvider, err := ptprovider.New(ptprovider.Config{
+		DB:               s.db,
+		InternalExecutor: internalExecutor,
+		Settings:         st,
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.protectedtsProvider = protectedtsProvider
+
 	// TODO(bdarnell): make StoreConfig configurable.
 	storeCfg := storage.StoreConfig{
 		DefaultZoneConfig:       &s.cfg.DefaultZoneConfig,
@@ -500,6 +549,10 @@ func NewServer(cfg Config, stopper *stop.Stopper) (*Server, error) {
 		LogRangeEvents:          s.cfg.EventLogEnabled,
 		RangeDescriptorCache:    s.distSender.RangeDescriptorCache(),
 		TimeSeriesDataStore:     s.tsDB,
+		ContentionRegistry:      s.contentionRegistry,
+		KVAdmissionQ:            s.admissionController.KVQueue(),
+
+		ProtectedTimestampTracker: s.protectedtsProvider,
 
 		// Initialize the closed timestamp subsystem. Note that it won't
 		// be ready until it is .Start()ed, but the grpc server can be
@@ -597,6 +650,7 @@ func NewServer(cfg Config, stopper *stop.Stopper) (*Server, error) {
 		NodeID:         &s.nodeIDContainer,
 		ClusterID:      &s.rpcContext.ClusterID,
 		ClusterName:    s.cfg.ClusterName,
+		AdmissionQ:     s.admissionController.FlowQueue(),
 
 		TempStorage: tempEngine,
 		DiskMonitor: s.cfg.TempStorageConfig.Mon,
@@ -620,6 +674,8 @@ func NewServer(cfg Config, stopper *stop.Stopper) (*Server, error) {
 
 		ExternalStorage:        externalStorage,
 		ExternalStorageFromURI: externalStorageFromURI,
+
+		ProtectedTimestampProvider: s.protectedtsProvider,
 	}
 	if distSQLTestingKnobs := s.cfg.TestingKnobs.DistSQL; distSQLTestingKnobs != nil {
 		distSQLCfg.TestingKnobs = *distSQLTestingKnobs.(*execinfra.TestingKnobs)
@@ -643,6 +699,7 @@ func NewServer(cfg Config, stopper *stop.Stopper) (*Server, error) {
 		s.node.stores,
 		s.stopper,
 		s.sessionRegistry,
+		s.contentionRegistry,
 	)
 	s.authentication = newAuthenticationServer(s)
 	for _, gw := range []grpcGatewayServer{s.admin, s.status, s.authentication, &s.tsServer} {
@@ -804,6 +861,29 @@ func NewServer(cfg Config, stopper *stop.Stopper) (*Server, error) {
 	s.internalExecutor = internalExecutor
 	execCfg.InternalExecutor = internalExecutor
 
+	s.stmtDiagnosticsRegistry = stmtdiagnostics.NewRegistry(internalExecutor, s.db, st)
+	execCfg.StmtDiagnosticsRecorder = s.stmtDiagnosticsRegistry
+
+	s.planPinRegistry = planpin.NewRegistry(internalExecutor, st)
+	execCfg.PlanPinRegistry = s.planPinRegistry
+
+	s.jobScheduler = jobs.NewJobScheduler(s.db, internalExecutor, s.stopper, st,
+		func(opName string, txn *client.Txn, user string) (interface{}, func()) {
+			// This is a hack to get around a Go package dependency cycle. See comment
+			// in sql/jobs/registry.go on planHookMaker.
+			return sql.NewInternalPlanner(opName, txn, user, &sql.MemoryMetrics{}, &execCfg)
+		})
+	execCfg.ProtectedTimestampProvider = s.protectedtsProvider
+
+	s.protectedtsReconciler = ptreconcile.NewReconciler(ptreconcile.Config{
+		Settings: st,
+		DB:       s.db,
+		Storage:  s.protectedtsProvider,
+		StatusFuncs: ptreconcile.StatusFuncs{
+			jobsprotectedts.MetaType: jobsprotectedts.MakeStatusFunc(internalExecutor),
+		},
+	})
+
 	s.execCfg = &execCfg
 
 	s.leaseMgr.SetInternalExecutor(execCfg.InternalExecutor)
@@ -1313,6 +1393,7 @@ func (s *Server) Start(ctx context.Context) error {
 	s.stopper.AddCloser(&s.engines)
 
 	s.node.startAssertEngineHealth(ctx, s.engines)
+	s.admissionController.Start(ctx, s.stopper)
 
 	// Write listener info files early in the startup sequence. `listenerInfo` has a comment.
 	listenerFiles := listenerInfo{
@@ -1600,6 +1681,24 @@ func (s *Server) Start(ctx context.Context) error {
 		return err
 	}
 
+	// Start the background thread for polling statement diagnostics requests.
+	s.stmtDiagnosticsRegistry.Start(ctx, s.stopper)
+
+	// Start the background thread for polling pinned plans.
+	s.planPinRegistry.Start(ctx, s.stopper)
+
+	// Start the background thread running the schedules of scheduled jobs.
+	s.jobScheduler.Start(ctx, s.stopper)
+
+	// Start the protected timestamp subsystem: the cache consulted by the GC
+	// queue and the loop which removes the records left behind by jobs.
+	if err := s.protectedtsProvider.Start(ctx, s.stopper); err != nil {
+		return err
+	}
+	if err := s.protectedtsReconciler.Start(ctx, s.stopper); err != nil {
+		return err
+	}
+
 	// Before serving SQL requests, we have to make sure the database is
 	// in an acceptable form for this version of the software.
 	// We have to do this after actually starting up the server to be able to
@@ -1673,6 +1772,13 @@ func (s *Server) Start(ctx context.Context) error {
 	s.mux.Handle(loginPath, gwMux)
 	s.mux.Handle(logoutPath, authHandler)
 	s.mux.Handle(statusVars, http.HandlerFunc(s.status.handleVars))
+	// Statement diagnostics bundles are zip files rather than protos, so they
+	// are served outside of the gRPC gateway.
+	var stmtBundleHandler http.Handler = http.HandlerFunc(s.admin.handleStatementBundle)
+	if s.cfg.RequireWebSession() {
+		stmtBundleHandler = newAuthenticationMux(s.authentication, stmtBundleHandler)
+	}
+	s.mux.Handle(stmtBundlePrefix, stmtBundleHandler)
 	log.Event(ctx, "added http endpoints")
 
 	// Attempt to upgrade cluster version.
@@ -0,0 +1,185 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package server
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+)
+
+// stmtBundlePrefix is the prefix of the HTTP endpoint serving statement
+// diagnostics bundles; the ID of the row in system.statement_diagnostics
+// follows it.
+const stmtBundlePrefix = adminPrefix + "stmtbundle/"
+
+// CreateStatementDiagnosticsReport creates a request for the next execution
+// of the given statement fingerprint to be traced.
+func (s *statusServer) CreateStatementDiagnosticsReport(
+	ctx context.Context, req *serverpb.CreateStatementDiagnosticsReportRequest,
+) (*serverpb.CreateStatementDiagnosticsReportResponse, error) {
+	if _, err := s.admin.requireAdminUser(ctx); err != nil {
+		return nil, err
+	}
+
+	ctx = propagateGatewayMetadata(ctx)
+	ctx = s.AnnotateCtx(ctx)
+
+	response := &serverpb.CreateStatementDiagnosticsReportResponse{
+		Report: &serverpb.StatementDiagnosticsReport{},
+	}
+
+	id, err := s.admin.server.stmtDiagnosticsRegistry.InsertRequest(ctx, req.StatementFingerprint)
+	if err != nil {
+		return nil, err
+	}
+
+	response.Report.ID = int64(id)
+	return response, nil
+}
+
+// StatementDiagnosticsRequests retrieves all of the statement diagnostics
+// requests in the `system.statement_diagnostics_requests` table.
+func (s *statusServer) StatementDiagnosticsRequests(
+	ctx context.Context, req *serverpb.StatementDiagnosticsReportsRequest,
+) (*serverpb.StatementDiagnosticsReportsResponse, error) {
+	if _, err := s.admin.requireAdminUser(ctx); err != nil {
+		return nil, err
+	}
+
+	ctx = propagateGatewayMetadata(ctx)
+	ctx = s.AnnotateCtx(ctx)
+
+	rows, err := s.admin.server.internalExecutor.Query(ctx, "stmt-diag-get-all", nil, /* txn */
+		`SELECT
+			id,
+			statement_fingerprint,
+			completed,
+			statement_diagnostics_id,
+			requested_at
+		FROM
+			system.statement_diagnostics_requests`)
+	if err != nil {
+		return nil, err
+	}
+
+	requests := make([]serverpb.StatementDiagnosticsReport, len(rows))
+	for i, row := range rows {
+		id := int64(*row[0].(*tree.DInt))
+		statementFingerprint := string(*row[1].(*tree.DString))
+		completed := bool(*row[2].(*tree.DBool))
+		req := serverpb.StatementDiagnosticsReport{
+			ID:                   id,
+			Completed:            completed,
+			StatementFingerprint: statementFingerprint,
+		}
+		if row[3] != tree.DNull {
+			req.StatementDiagnosticsID = int64(*row[3].(*tree.DInt))
+		}
+		if requestedAt, ok := row[4].(*tree.DTimestampTZ); ok {
+			req.RequestedAt = requestedAt.Time
+		}
+
+		requests[i] = req
+	}
+
+	response := &serverpb.StatementDiagnosticsReportsResponse{
+		Reports: requests,
+	}
+
+	return response, nil
+}
+
+// handleStatementBundle serves the diagnostics bundle stored in
+// system.statement_diagnostics under the ID given at the end of the URL, as a
+// zip file.
+func (s *adminServer) handleStatementBundle(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	id, err := strconv.Atoi(strings.TrimPrefix(req.URL.Path, stmtBundlePrefix))
+	if err != nil {
+		http.Error(w, "invalid statement diagnostics ID", http.StatusBadRequest)
+		return
+	}
+
+	// The bundle contains the statement and the data it references, so only
+	// admin users are allowed to download it.
+	userName := security.RootUser
+	if u, ok := ctx.Value(webSessionUserKey{}).(string); ok {
+		userName = u
+	}
+	isAdmin, err := s.hasAdminRole(ctx, userName)
+	if err != nil {
+		log.Errorf(ctx, "checking admin role for %s: %v", userName, err)
+		http.Error(w, errAdminAPIError.Error(), http.StatusInternalServerError)
+		return
+	}
+	if !isAdmin {
+		http.Error(w, errInsufficientPrivilege.Error(), http.StatusForbidden)
+		return
+	}
+
+	bundle, err := s.getStatementBundle(ctx, id)
+	if err != nil {
+		log.Errorf(ctx, "reading statement diagnostics bundle %d: %v", id, err)
+		http.Error(w, errAdminAPIError.Error(), http.StatusInternalServerError)
+		return
+	}
+	if bundle == nil {
+		http.Error(w, "no statement diagnostics bundle with this ID", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set(
+		"Content-Disposition",
+		fmt.Sprintf("attachment; filename=stmt-bundle-%d.zip", id),
+	)
+	_, _ = w.Write(bundle)
+}
+
+// getStatementBundle reassembles the bundle of the given
+// system.statement_diagnostics row from its chunks. A nil bundle is returned
+// if the row does not exist or has no bundle.
+func (s *adminServer) getStatementBundle(ctx context.Context, id int) ([]byte, error) {
+	row, err := s.server.internalExecutor.QueryRow(
+		ctx, "admin-stmt-bundle", nil, /* txn */
+		"SELECT bundle_chunks FROM system.statement_diagnostics WHERE id=$1 AND bundle_chunks IS NOT NULL",
+		id,
+	)
+	if err != nil || row == nil {
+		return nil, err
+	}
+	// Put together the entire bundle.
+	var bundle bytes.Buffer
+	for _, chunkID := range row[0].(*tree.DArray).Array {
+		chunkRow, err := s.server.internalExecutor.QueryRow(
+			ctx, "admin-stmt-bundle", nil, /* txn */
+			"SELECT data FROM system.statement_bundle_chunks WHERE id=$1",
+			chunkID,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if chunkRow == nil {
+			return nil, fmt.Errorf("chunk %s of statement diagnostics bundle %d is missing", chunkID, id)
+		}
+		bundle.WriteString(string(*chunkRow[0].(*tree.DBytes)))
+	}
+	return bundle.Bytes(), nil
+}
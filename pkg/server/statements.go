@@ -13,10 +13,14 @@ package server
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/security"
 	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -28,6 +32,10 @@ func (s *statusServer) Statements(
 	ctx = propagateGatewayMetadata(ctx)
 	ctx = s.AnnotateCtx(ctx)
 
+	if req.Start != 0 || req.End != 0 {
+		return s.statementsHistoric(ctx, req)
+	}
+
 	response := &serverpb.StatementsResponse{
 		Statements:            []serverpb.StatementsResponse_CollectedStatementStatistics{},
 		LastReset:             timeutil.Now(),
@@ -104,3 +112,77 @@ func (s *statusServer) StatementsLocal(ctx context.Context) (*serverpb.Statement
 
 	return resp, nil
 }
+
+// statementsHistoric returns the statement statistics persisted in
+// system.statement_statistics over the interval requested by req. The
+// statistics persisted by a node for the same statement over several
+// aggregation intervals are combined.
+func (s *statusServer) statementsHistoric(
+	ctx context.Context, req *serverpb.StatementsRequest,
+) (*serverpb.StatementsResponse, error) {
+	start := timeutil.Unix(req.Start, 0)
+	end := timeutil.Now()
+	if req.End != 0 {
+		end = timeutil.Unix(req.End, 0)
+	}
+	query := `SELECT node_id, key, statistics FROM system.statement_statistics
+ WHERE aggregated_ts >= $1 AND aggregated_ts < $2`
+	args := []interface{}{
+		tree.MakeDTimestampTZ(start, time.Microsecond),
+		tree.MakeDTimestampTZ(end, time.Microsecond),
+	}
+	if len(req.NodeID) > 0 {
+		requestedNodeID, _, err := s.parseNodeID(req.NodeID)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, err.Error())
+		}
+		query += ` AND node_id = $3`
+		args = append(args, int64(requestedNodeID))
+	}
+
+	rows, _ /* cols */, err := s.admin.server.internalExecutor.QueryWithUser(
+		ctx, "statements-historic", nil /* txn */, security.RootUser, query, args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	type extendedKey struct {
+		nodeID roachpb.NodeID
+		key    roachpb.StatementStatisticsKey
+	}
+	var keys []extendedKey
+	stats := make(map[extendedKey]*roachpb.StatementStatistics)
+	for _, row := range rows {
+		k := extendedKey{nodeID: roachpb.NodeID(tree.MustBeDInt(row[0]))}
+		if err := protoutil.Unmarshal([]byte(tree.MustBeDBytes(row[1])), &k.key); err != nil {
+			return nil, err
+		}
+		var data roachpb.StatementStatistics
+		if err := protoutil.Unmarshal([]byte(tree.MustBeDBytes(row[2])), &data); err != nil {
+			return nil, err
+		}
+		if existing, ok := stats[k]; ok {
+			existing.Add(&data)
+			continue
+		}
+		keys = append(keys, k)
+		stats[k] = &data
+	}
+
+	resp := &serverpb.StatementsResponse{
+		Statements:            make([]serverpb.StatementsResponse_CollectedStatementStatistics, len(keys)),
+		LastReset:             start,
+		InternalAppNamePrefix: sqlbase.InternalAppNamePrefix,
+	}
+	for i, k := range keys {
+		resp.Statements[i] = serverpb.StatementsResponse_CollectedStatementStatistics{
+			Key: serverpb.StatementsResponse_ExtendedStatementStatisticsKey{
+				KeyData: k.key,
+				NodeID:  k.nodeID,
+			},
+			Stats: *stats[k],
+		}
+	}
+	return resp, nil
+}
@@ -48,6 +48,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql"
 	"github.com/cockroachdb/cockroach/pkg/storage"
+	"github.com/cockroachdb/cockroach/pkg/storage/contention"
 	"github.com/cockroachdb/cockroach/pkg/storage/storagepb"
 	"github.com/cockroachdb/cockroach/pkg/util/contextutil"
 	"github.com/cockroachdb/cockroach/pkg/util/httputil"
@@ -127,19 +128,20 @@ func propagateGatewayMetadata(ctx context.Context) context.Context {
 type statusServer struct {
 	log.AmbientContext
 
-	st              *cluster.Settings
-	cfg             *base.Config
-	admin           *adminServer
-	db              *client.DB
-	gossip          *gossip.Gossip
-	metricSource    metricMarshaler
-	nodeLiveness    *storage.NodeLiveness
-	storePool       *storage.StorePool
-	rpcCtx          *rpc.Context
-	stores          *storage.Stores
-	stopper         *stop.Stopper
-	sessionRegistry *sql.SessionRegistry
-	si              systemInfoOnce
+	st                 *cluster.Settings
+	cfg                *base.Config
+	admin              *adminServer
+	db                 *client.DB
+	gossip             *gossip.Gossip
+	metricSource       metricMarshaler
+	nodeLiveness       *storage.NodeLiveness
+	storePool          *storage.StorePool
+	rpcCtx             *rpc.Context
+	stores             *storage.Stores
+	stopper            *stop.Stopper
+	sessionRegistry    *sql.SessionRegistry
+	contentionRegistry *contention.Registry
+	si                 systemInfoOnce
 }
 
 // newStatusServer allocates and returns a statusServer.
@@ -157,22 +159,24 @@ func newStatusServer(
 	stores *storage.Stores,
 	stopper *stop.Stopper,
 	sessionRegistry *sql.SessionRegistry,
+	contentionRegistry *contention.Registry,
 ) *statusServer {
 	ambient.AddLogTag("status", nil)
 	server := &statusServer{
-		AmbientContext:  ambient,
-		st:              st,
-		cfg:             cfg,
-		admin:           adminServer,
-		db:              db,
-		gossip:          gossip,
-		metricSource:    metricSource,
-		nodeLiveness:    nodeLiveness,
-		storePool:       storePool,
-		rpcCtx:          rpcCtx,
-		stores:          stores,
-		stopper:         stopper,
-		sessionRegistry: sessionRegistry,
+		AmbientContext:     ambient,
+		st:                 st,
+		cfg:                cfg,
+		admin:              adminServer,
+		db:                 db,
+		gossip:             gossip,
+		metricSource:       metricSource,
+		nodeLiveness:       nodeLiveness,
+		storePool:          storePool,
+		rpcCtx:             rpcCtx,
+		stores:             stores,
+		stopper:            stopper,
+		sessionRegistry:    sessionRegistry,
+		contentionRegistry: contentionRegistry,
 	}
 
 	return server
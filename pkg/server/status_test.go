@@ -1430,6 +1430,41 @@ func TestStatusAPIStatements(t *testing.T) {
 	}
 }
 
+func TestStatusAPIStatementsHistoric(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(context.Background())
+	ts := s.(*TestServer)
+
+	sqlDB := sqlutils.MakeSQLRunner(db)
+	sqlDB.Exec(t, `SET application_name = 'historic'`)
+	sqlDB.Exec(t, `CREATE DATABASE roachblog`)
+	sqlDB.Exec(t, `CREATE TABLE roachblog.posts (id INT8 PRIMARY KEY, body STRING)`)
+	sqlDB.Exec(t, `INSERT INTO roachblog.posts VALUES (1, 'foo')`)
+
+	// Resetting the statistics persists them.
+	ts.PGServer().SQLServer.ResetSQLStats(context.Background())
+
+	var resp serverpb.StatementsResponse
+	if err := getStatusJSONProto(s, "statements?start=1", &resp); err != nil {
+		t.Fatal(err)
+	}
+	var found bool
+	for _, respStatement := range resp.Statements {
+		if respStatement.Key.KeyData.App == "historic" &&
+			respStatement.Key.KeyData.Query == `INSERT INTO roachblog.posts VALUES (_, _)` {
+			found = true
+			if respStatement.Key.NodeID != ts.NodeID() {
+				t.Errorf("expected node %d, got %d", ts.NodeID(), respStatement.Key.NodeID)
+			}
+		}
+	}
+	if !found {
+		t.Fatalf("persisted INSERT statement not found in\n%s", pretty.Sprint(resp))
+	}
+}
+
 func TestListSessionsSecurity(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	s, _, _ := serverutils.StartServer(t, base.TestServerArgs{})
@@ -51,6 +51,14 @@ const (
 	VersionSecondaryIndexColumnFamilies
 	VersionNamespaceTableWithSchemas
 	VersionProtectedTimestamps
+	VersionStatementStatisticsTable
+	VersionStatementDiagnosticsSystemTables
+	VersionStatementPlanPins
+	VersionNonVotingReplicas
+	VersionChangefeedDatabaseTargets
+	VersionChangefeedSelect
+	VersionScheduledJobs
+	VersionBackupEncryption
 
 	// Add new versions here (step one of two).
 
@@ -350,6 +358,55 @@ var versionsSingleton = keyedVersions([]keyedVersion{
 		Key:     VersionProtectedTimestamps,
 		Version: roachpb.Version{Major: 19, Minor: 2, Unstable: 6},
 	},
+	{
+		// VersionStatementStatisticsTable introduces the system.statement_statistics
+		// table, into which each node persists its statement statistics.
+		Key:     VersionStatementStatisticsTable,
+		Version: roachpb.Version{Major: 19, Minor: 2, Unstable: 7},
+	},
+	{
+		// VersionStatementDiagnosticsSystemTables introduces the system tables
+		// used to request and store statement diagnostics bundles.
+		Key:     VersionStatementDiagnosticsSystemTables,
+		Version: roachpb.Version{Major: 19, Minor: 2, Unstable: 8},
+	},
+	{
+		// VersionStatementPlanPins introduces the system.statement_plan_pins
+		// table and the PIN PLAN / UNPIN PLAN statements.
+		Key:     VersionStatementPlanPins,
+		Version: roachpb.Version{Major: 19, Minor: 2, Unstable: 9},
+	},
+	{
+		// VersionNonVotingReplicas introduces the NON_VOTER replica type and the
+		// num_voters and voter_constraints zone config fields.
+		Key:     VersionNonVotingReplicas,
+		Version: roachpb.Version{Major: 19, Minor: 2, Unstable: 10},
+	},
+	{
+		// VersionChangefeedDatabaseTargets allows changefeeds to target a whole
+		// database with CREATE CHANGEFEED FOR DATABASE.
+		Key:     VersionChangefeedDatabaseTargets,
+		Version: roachpb.Version{Major: 19, Minor: 2, Unstable: 11},
+	},
+	{
+		// VersionChangefeedSelect allows changefeeds to project and filter rows
+		// with CREATE CHANGEFEED ... AS SELECT.
+		Key:     VersionChangefeedSelect,
+		Version: roachpb.Version{Major: 19, Minor: 2, Unstable: 12},
+	},
+	{
+		// VersionScheduledJobs introduces the system.scheduled_jobs table used
+		// by the job scheduler.
+		Key:     VersionScheduledJobs,
+		Version: roachpb.Version{Major: 19, Minor: 2, Unstable: 13},
+	},
+	{
+		// VersionBackupEncryption allows BACKUP and RESTORE to encrypt and decrypt
+		// their files, which requires all nodes to understand the encryption of
+		// Export and Import requests.
+		Key:     VersionBackupEncryption,
+		Version: roachpb.Version{Major: 19, Minor: 2, Unstable: 14},
+	},
 
 	// Add new versions here (step two of two).
 
@@ -27,11 +27,19 @@ func _() {
 	_ = x[VersionSecondaryIndexColumnFamilies-16]
 	_ = x[VersionNamespaceTableWithSchemas-17]
 	_ = x[VersionProtectedTimestamps-18]
+	_ = x[VersionStatementStatisticsTable-19]
+	_ = x[VersionStatementDiagnosticsSystemTables-20]
+	_ = x[VersionStatementPlanPins-21]
+	_ = x[VersionNonVotingReplicas-22]
+	_ = x[VersionChangefeedDatabaseTargets-23]
+	_ = x[VersionChangefeedSelect-24]
+	_ = x[VersionScheduledJobs-25]
+	_ = x[VersionBackupEncryption-26]
 }
 
-const _VersionKey_name = "Version19_1VersionStart19_2VersionQueryTxnTimestampVersionStickyBitVersionParallelCommitsVersionGenerationComparableVersionLearnerReplicasVersionTopLevelForeignKeysVersionAtomicChangeReplicasTriggerVersionAtomicChangeReplicasVersionTableDescModificationTimeFromMVCCVersionPartitionedBackupVersion19_2VersionStart20_1VersionContainsEstimatesCounterVersionChangeReplicasDemotionVersionSecondaryIndexColumnFamiliesVersionNamespaceTableWithSchemasVersionProtectedTimestamps"
+const _VersionKey_name = "Version19_1VersionStart19_2VersionQueryTxnTimestampVersionStickyBitVersionParallelCommitsVersionGenerationComparableVersionLearnerReplicasVersionTopLevelForeignKeysVersionAtomicChangeReplicasTriggerVersionAtomicChangeReplicasVersionTableDescModificationTimeFromMVCCVersionPartitionedBackupVersion19_2VersionStart20_1VersionContainsEstimatesCounterVersionChangeReplicasDemotionVersionSecondaryIndexColumnFamiliesVersionNamespaceTableWithSchemasVersionProtectedTimestampsVersionStatementStatisticsTableVersionStatementDiagnosticsSystemTablesVersionStatementPlanPinsVersionNonVotingReplicasVersionChangefeedDatabaseTargetsVersionChangefeedSelectVersionScheduledJobsVersionBackupEncryption"
 
-var _VersionKey_index = [...]uint16{0, 11, 27, 51, 67, 89, 116, 138, 164, 198, 225, 265, 289, 300, 316, 347, 376, 411, 443, 469}
+var _VersionKey_index = [...]uint16{0, 11, 27, 51, 67, 89, 116, 138, 164, 198, 225, 265, 289, 300, 316, 347, 376, 411, 443, 469, 500, 539, 563, 587, 619, 642, 662, 685}
 
 func (i VersionKey) String() string {
 	if i < 0 || i >= VersionKey(len(_VersionKey_index)-1) {
@@ -15,9 +15,11 @@ import (
 	"context"
 	"crypto/hmac"
 	"crypto/sha256"
+	"encoding/binary"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"hash/fnv"
 	"strings"
 	"time"
 
@@ -49,6 +51,9 @@ type appStats struct {
 	st    *cluster.Settings
 	stmts map[stmtKey]*stmtStats
 	txns  transactionStats
+	// txnFingerprints holds the per-transaction fingerprint statistics, keyed
+	// by the ID computed by txnFingerprintID.
+	txnFingerprints map[int64]*txnFingerprintStats
 }
 
 // stmtStats holds per-statement statistics.
@@ -56,6 +61,62 @@ type stmtStats struct {
 	syncutil.Mutex
 
 	data roachpb.StatementStatistics
+
+	// svcLatHistogram counts the executions of the statement by service
+	// latency, bucketed according to svcLatBuckets.
+	svcLatHistogram [numSvcLatBuckets]int64
+
+	// planHash identifies the shape of the most recently sampled logical
+	// plan, or is zero if no plan was sampled yet.
+	planHash int64
+}
+
+// svcLatBuckets are the upper bounds, in seconds, of the buckets of the
+// per-statement service latency histograms. Latencies above the last bound
+// are counted in an extra overflow bucket.
+var svcLatBuckets = [...]float64{
+	.001, .002, .005, .01, .02, .05, .1, .2, .5, 1, 2, 5, 10,
+}
+
+const numSvcLatBuckets = len(svcLatBuckets) + 1
+
+// svcLatBucket returns the index of the histogram bucket into which the given
+// service latency, expressed in seconds, falls.
+func svcLatBucket(svcLat float64) int {
+	for i, upper := range svcLatBuckets {
+		if svcLat <= upper {
+			return i
+		}
+	}
+	return len(svcLatBuckets)
+}
+
+// maxStmtFingerprintIDsPerTxn is the maximum number of statement fingerprints
+// making up a transaction fingerprint. Statements executed past this limit are
+// not part of the fingerprint, which keeps long-running transactions from
+// growing the recorded IDs without bound.
+const maxStmtFingerprintIDsPerTxn = 1000
+
+// txnFingerprintStats holds the statistics of a transaction fingerprint, that
+// is of the transactions executing the same sequence of statement
+// fingerprints.
+type txnFingerprintStats struct {
+	syncutil.Mutex
+
+	// stmtFingerprintIDs are the IDs of the statement fingerprints making up
+	// the transaction fingerprint, in execution order. There are at most
+	// maxStmtFingerprintIDsPerTxn of them. They are immutable.
+	stmtFingerprintIDs []int64
+
+	count          int64
+	committedCount int64
+	maxRetries     int64
+	// serviceLat is the time between the start and the end of the
+	// transaction, in seconds.
+	serviceLat roachpb.NumericStat
+	// contentionTime is the time the transaction spent waiting on other
+	// transactions, in seconds.
+	contentionTime roachpb.NumericStat
 }
 
 // transactionStats holds per-application transaction statistics.
@@ -122,7 +183,9 @@ func (s stmtKey) flags() string {
 	return b.String()
 }
 
-// recordStatement saves per-statement statistics.
+// recordStatement saves per-statement statistics. It returns the key of the
+// statement's fingerprint, or the zero stmtKey if statement statistics are
+// disabled.
 //
 // samplePlanDescription can be nil, as these are only sampled periodically per unique fingerprint.
 func (a *appStats) recordStatement(
@@ -136,17 +199,18 @@ func (a *appStats) recordStatement(
 	err error,
 	parseLat, planLat, runLat, svcLat, ovhLat float64,
 	bytesRead, rowsRead int64,
-) {
+) stmtKey {
 	if !stmtStatsEnable.Get(&a.st.SV) {
-		return
+		return stmtKey{}
 	}
 
+	key := makeStmtKey(stmt, distSQLUsed, optUsed, implicitTxn, err)
 	if t := sqlStatsCollectionLatencyThreshold.Get(&a.st.SV); t > 0 && t.Seconds() >= svcLat {
-		return
+		return key
 	}
 
 	// Get the statistics object.
-	s := a.getStatsForStmt(stmt, distSQLUsed, optUsed, implicitTxn, err, true /* createIfNonexistent */)
+	s := a.getStatsForStmtWithKey(key, true /* createIfNonexistent */)
 
 	// Collect the per-statement statistics.
 	s.Lock()
@@ -158,6 +222,7 @@ func (a *appStats) recordStatement(
 	if samplePlanDescription != nil {
 		s.data.SensitiveInfo.MostRecentPlanDescription = *samplePlanDescription
 		s.data.SensitiveInfo.MostRecentPlanTimestamp = timeutil.Now()
+		s.planHash = hashPlan(samplePlanDescription)
 	}
 	if automaticRetryCount == 0 {
 		s.data.FirstAttemptCount++
@@ -170,9 +235,11 @@ func (a *appStats) recordStatement(
 	s.data.RunLat.Record(s.data.Count, runLat)
 	s.data.ServiceLat.Record(s.data.Count, svcLat)
 	s.data.OverheadLat.Record(s.data.Count, ovhLat)
-	s.data.BytesRead = bytesRead
-	s.data.RowsRead = rowsRead
+	s.data.BytesRead.Record(s.data.Count, float64(bytesRead))
+	s.data.RowsRead.Record(s.data.Count, float64(rowsRead))
+	s.svcLatHistogram[svcLatBucket(svcLat)]++
 	s.Unlock()
+	return key
 }
 
 // getStatsForStmt retrieves the per-stmt stat object.
@@ -184,6 +251,15 @@ func (a *appStats) getStatsForStmt(
 	err error,
 	createIfNonexistent bool,
 ) *stmtStats {
+	key := makeStmtKey(stmt, distSQLUsed, optimizerUsed, implicitTxn, err)
+	return a.getStatsForStmtWithKey(key, createIfNonexistent)
+}
+
+// makeStmtKey returns the key under which the statistics of the statement are
+// collected.
+func makeStmtKey(
+	stmt *Statement, distSQLUsed bool, optimizerUsed bool, implicitTxn bool, err error,
+) stmtKey {
 	// Extend the statement key with various characteristics, so
 	// that we use separate buckets for the different situations.
 	key := stmtKey{failed: err != nil, distSQLUsed: distSQLUsed, optUsed: optimizerUsed, implicitTxn: implicitTxn}
@@ -193,8 +269,7 @@ func (a *appStats) getStatsForStmt(
 	} else {
 		key.stmt = anonymizeStmt(stmt.AST)
 	}
-
-	return a.getStatsForStmtWithKey(key, createIfNonexistent)
+	return key
 }
 
 func (a *appStats) getStatsForStmtWithKey(key stmtKey, createIfNonexistent bool) *stmtStats {
@@ -214,6 +289,48 @@ func anonymizeStmt(ast tree.Statement) string {
 	return tree.AsStringWithFlags(ast, tree.FmtHideConstants)
 }
 
+// hashPlan computes a hash of the shape of a sampled logical plan. The spans
+// scanned by the plan depend on the constants used by a particular execution
+// of the statement and are thus left out of the hash.
+func hashPlan(plan *roachpb.ExplainTreePlanNode) int64 {
+	h := fnv.New64a()
+	var walk func(n *roachpb.ExplainTreePlanNode)
+	walk = func(n *roachpb.ExplainTreePlanNode) {
+		_, _ = h.Write([]byte(n.Name))
+		for _, attr := range n.Attrs {
+			if attr.Key == "spans" {
+				continue
+			}
+			_, _ = h.Write([]byte(attr.Key))
+			_, _ = h.Write([]byte(attr.Value))
+		}
+		// Delimit the children so that different tree shapes with the same
+		// pre-order traversal hash differently.
+		_, _ = h.Write([]byte{'('})
+		for _, c := range n.Children {
+			walk(c)
+		}
+		_, _ = h.Write([]byte{')'})
+	}
+	walk(plan)
+	return int64(h.Sum64())
+}
+
+// fingerprintID computes a stable identifier for the statement fingerprint
+// described by the given key within the given application.
+func (s stmtKey) fingerprintID(appName string) int64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(appName))
+	_, _ = h.Write([]byte{0})
+	_, _ = h.Write([]byte(s.flags()))
+	if s.implicitTxn {
+		_, _ = h.Write([]byte{'i'})
+	}
+	_, _ = h.Write([]byte{0})
+	_, _ = h.Write([]byte(s.stmt))
+	return int64(h.Sum64())
+}
+
 func (s *transactionStats) getStats() (
 	txnCount int64,
 	txnTimeAvg float64,
@@ -244,11 +361,66 @@ func (s *transactionStats) recordTransaction(txnTimeSec float64, ev txnEvent, im
 	}
 }
 
-func (a *appStats) recordTransaction(txnTimeSec float64, ev txnEvent, implicit bool) {
+// recordTransaction saves per-application transaction statistics, as well as
+// the statistics of the transaction's fingerprint, which is made up of the
+// fingerprints of the statements it executed.
+func (a *appStats) recordTransaction(
+	txnTimeSec float64,
+	ev txnEvent,
+	implicit bool,
+	stmtFingerprintIDs []int64,
+	automaticRetryCount int,
+	contentionTime time.Duration,
+) {
 	if !txnStatsEnable.Get(&a.st.SV) {
 		return
 	}
 	a.txns.recordTransaction(txnTimeSec, ev, implicit)
+
+	if len(stmtFingerprintIDs) == 0 {
+		return
+	}
+	s := a.getStatsForTxnFingerprint(stmtFingerprintIDs)
+	s.Lock()
+	defer s.Unlock()
+	s.count++
+	if ev == txnCommit {
+		s.committedCount++
+	}
+	if int64(automaticRetryCount) > s.maxRetries {
+		s.maxRetries = int64(automaticRetryCount)
+	}
+	s.serviceLat.Record(s.count, txnTimeSec)
+	s.contentionTime.Record(s.count, contentionTime.Seconds())
+}
+
+// txnFingerprintID computes the ID of the transaction fingerprint made up of
+// the given statement fingerprints.
+func txnFingerprintID(stmtFingerprintIDs []int64) int64 {
+	h := fnv.New64a()
+	var buf [8]byte
+	for _, id := range stmtFingerprintIDs {
+		binary.LittleEndian.PutUint64(buf[:], uint64(id))
+		_, _ = h.Write(buf[:])
+	}
+	return int64(h.Sum64())
+}
+
+// getStatsForTxnFingerprint retrieves the statistics object of the transaction
+// fingerprint made up of the given statement fingerprints, creating it if it
+// doesn't exist yet.
+func (a *appStats) getStatsForTxnFingerprint(stmtFingerprintIDs []int64) *txnFingerprintStats {
+	id := txnFingerprintID(stmtFingerprintIDs)
+	a.Lock()
+	defer a.Unlock()
+	s, ok := a.txnFingerprints[id]
+	if !ok {
+		s = &txnFingerprintStats{
+			stmtFingerprintIDs: append([]int64(nil), stmtFingerprintIDs...),
+		}
+		a.txnFingerprints[id] = s
+	}
+	return s
 }
 
 // sqlStats carries per-application statistics for all applications.
@@ -269,16 +441,18 @@ func (s *sqlStats) getStatsForApplication(appName string) *appStats {
 		return a
 	}
 	a := &appStats{
-		st:    s.st,
-		stmts: make(map[stmtKey]*stmtStats),
+		st:              s.st,
+		stmts:           make(map[stmtKey]*stmtStats),
+		txnFingerprints: make(map[int64]*txnFingerprintStats),
 	}
 	s.apps[appName] = a
 	return a
 }
 
 // resetStats clears all the stored per-app and per-statement
-// statistics.
-func (s *sqlStats) resetStats(ctx context.Context) {
+// statistics. It returns the start of the interval over which the cleared
+// statistics were collected, along with a copy of them for persistence.
+func (s *sqlStats) resetStats(ctx context.Context) (time.Time, []persistedStmtStats) {
 	// Note: we do not clear the entire s.apps map here. We would need
 	// to do so to prevent problems with a runaway client running `SET
 	// APPLICATION_NAME=...` with a different name every time.  However,
@@ -291,6 +465,9 @@ func (s *sqlStats) resetStats(ctx context.Context) {
 	// different application_names seen so far.
 
 	s.Lock()
+	aggregatedTS := s.lastReset
+	var drained []persistedStmtStats
+	persist := stmtStatsPersistEnabled.Get(&s.st.SV)
 	// Clear the per-apps maps manually,
 	// because any SQL session currently open has cached the
 	// pointer to its appStats object and will continue to
@@ -300,20 +477,24 @@ func (s *sqlStats) resetStats(ctx context.Context) {
 		a.Lock()
 
 		// Save the existing data to logs.
-		// TODO(knz/dt): instead of dumping the stats to the log, save
-		// them in a SQL table so they can be inspected by the DBA and/or
-		// the UI.
 		if dumpStmtStatsToLogBeforeReset.Get(&a.st.SV) {
 			dumpStmtStats(ctx, appName, a.stmts)
 		}
+		// Hand the existing data over to the caller, which saves it in
+		// system.statement_statistics.
+		if persist {
+			drained = a.appendPersistedStmtStats(drained, appName)
+		}
 
 		// Clear the map, to release the memory; make the new map somewhat already
 		// large for the likely future workload.
 		a.stmts = make(map[stmtKey]*stmtStats, len(a.stmts)/2)
+		a.txnFingerprints = make(map[int64]*txnFingerprintStats, len(a.txnFingerprints)/2)
 		a.Unlock()
 	}
 	s.lastReset = timeutil.Now()
 	s.Unlock()
+	return aggregatedTS, drained
 }
 
 func (s *sqlStats) getLastReset() time.Time {
@@ -0,0 +1,140 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+)
+
+// negotiateBoundedStaleness picks the read timestamp of a bounded staleness
+// read whose plan was built at the present time, and moves the transaction to
+// it.
+//
+// The chosen timestamp is the most recent one at which the nearest replicas of
+// all the ranges touched by the plan can serve the read, that is, the minimum
+// of their closed timestamps as reported by QueryResolvedTimestamp requests. If that timestamp lies below
+// the minimum bound, or below the time at which the schema of any table read
+// by the plan last changed, the read falls back to the present time, at which
+// it is served by the leaseholders.
+func (ex *connExecutor) negotiateBoundedStaleness(ctx context.Context, p *planner) error {
+	ts, resolved, err := p.negotiateBoundedStalenessTimestamp(ctx, *p.semaCtx.AsOfTimestamp)
+	if err != nil {
+		return err
+	}
+	p.extendedEvalCtx.SetTxnTimestamp(ts.GoTime())
+	if resolved {
+		// The timestamp is closed on all the ranges read by the plan, so the
+		// reads can be served by followers even though it may be more recent than
+		// the follower read threshold.
+		ex.state.setBoundedStalenessTimestamp(ctx, ts)
+	} else {
+		ex.state.setHistoricalTimestamp(ctx, ts)
+	}
+	return nil
+}
+
+// negotiateBoundedStalenessTimestamp returns the timestamp at which the
+// current plan is to be executed as a bounded staleness read no older than
+// minTS. The returned bool is true if the timestamp is the resolved timestamp
+// of the ranges read by the plan, and false if the read falls back to the
+// present time.
+func (p *planner) negotiateBoundedStalenessTimestamp(
+	ctx context.Context, minTS hlc.Timestamp,
+) (_ hlc.Timestamp, resolved bool, _ error) {
+	spans, schemaTS, err := p.curPlan.readSpans(ctx)
+	if err != nil {
+		return hlc.Timestamp{}, false, err
+	}
+	// The plan was built using the current version of the tables' descriptors,
+	// so the read must not go below the time they were written at.
+	minTS.Forward(schemaTS)
+	now := p.execCfg.Clock.Now()
+	if len(spans) == 0 {
+		return now, false, nil
+	}
+
+	var b client.Batch
+	// An inconsistent read doesn't need the lease, so the requests are served by
+	// the nearest replica of each range.
+	b.Header.ReadConsistency = roachpb.INCONSISTENT
+	for _, span := range spans {
+		b.AddRawRequest(&roachpb.QueryResolvedTimestampRequest{
+			RequestHeader: roachpb.RequestHeaderFromSpan(span),
+		})
+	}
+	if err := p.execCfg.DB.Run(ctx, &b); err != nil {
+		return hlc.Timestamp{}, false, err
+	}
+	resolvedTS := now
+	for _, ru := range b.RawResponse().Responses {
+		resolvedTS.Backward(ru.GetQueryResolvedTimestamp().ResolvedTS)
+	}
+	if resolvedTS.Less(minTS) {
+		log.VEventf(ctx, 2, "resolved timestamp %s below minimum bound %s; "+
+			"reading from leaseholders at %s", resolvedTS, minTS, now)
+		return now, false, nil
+	}
+	log.VEventf(ctx, 2, "bounded staleness read at %s", resolvedTS)
+	return resolvedTS, true, nil
+}
+
+// readSpans returns the key spans of the tables and indexes read by the plan
+// and its subqueries, along with the most recent modification time of the
+// descriptors of these tables. Lookup and index joins are assumed to read the
+// whole index they look rows up in.
+func (p *planTop) readSpans(
+	ctx context.Context,
+) (_ roachpb.Spans, schemaTS hlc.Timestamp, _ error) {
+	var spans roachpb.Spans
+	addTable := func(desc *sqlbase.ImmutableTableDescriptor, tableSpans ...roachpb.Span) {
+		schemaTS.Forward(desc.ModificationTime)
+		spans = append(spans, tableSpans...)
+	}
+	observer := planObserver{
+		enterNode: func(ctx context.Context, _ string, plan planNode) (bool, error) {
+			switch n := plan.(type) {
+			case *scanNode:
+				if n.desc.IsVirtualTable() {
+					break
+				}
+				if len(n.spans) == 0 {
+					addTable(n.desc, n.desc.IndexSpan(n.index.ID))
+				} else {
+					addTable(n.desc, n.spans...)
+				}
+			case *indexJoinNode:
+				addTable(n.table.desc, n.table.desc.PrimaryIndexSpan())
+			case *lookupJoinNode:
+				addTable(n.table.desc, n.table.desc.IndexSpan(n.table.index.ID))
+			}
+			return true, nil
+		},
+	}
+	if err := walkPlan(ctx, p.plan, observer); err != nil {
+		return nil, hlc.Timestamp{}, err
+	}
+	for i := range p.subqueryPlans {
+		if p.subqueryPlans[i].plan == nil {
+			continue
+		}
+		if err := walkPlan(ctx, p.subqueryPlans[i].plan, observer); err != nil {
+			return nil, hlc.Timestamp{}, err
+		}
+	}
+	spans, _ = roachpb.MergeSpans(spans)
+	return spans, schemaTS, nil
+}
@@ -78,6 +78,20 @@ func TestCaseOp(t *testing.T) {
 			expected:   tuples{{nil}, {zero}, {nil}, {one}},
 			inputTypes: []types.T{*types.Int, *types.Int},
 		},
+		{
+			// Test the CASE <expr> WHEN form.
+			tuples:     tuples{{1}, {2}, {nil}, {3}},
+			renderExpr: "CASE @1 WHEN 2 THEN 1 WHEN 3 THEN 2 ELSE 0 END",
+			expected:   tuples{{0}, {1}, {0}, {2}},
+			inputTypes: []types.T{*types.Int},
+		},
+		{
+			// Test the CASE <expr> WHEN form with a non-constant operand.
+			tuples:     tuples{{1, 1}, {3, 0}, {nil, nil}, {3, 3}},
+			renderExpr: "CASE @1 + @2 WHEN 2 THEN 0 WHEN @1 THEN 1 END",
+			expected:   tuples{{0}, {1}, {nil}, {nil}},
+			inputTypes: []types.T{*types.Int, *types.Int},
+		},
 	} {
 		runTests(t, []tuples{tc.tuples}, tc.expected, orderedVerifier, func(inputs []Operator) (Operator, error) {
 			spec.Input[0].ColumnTypes = tc.inputTypes
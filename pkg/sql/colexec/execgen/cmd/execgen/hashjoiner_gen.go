@@ -51,6 +51,9 @@ func genHashJoiner(wr io.Writer) error {
 	distinctCollectNoOuter := makeFunctionRegex("_DISTINCT_COLLECT_NO_OUTER", 4)
 	s = distinctCollectNoOuter.ReplaceAllString(s, `{{template "distinctCollectNoOuter" buildDict "Global" . "UseSel" $4}}`)
 
+	distinctCollectLeftAnti := makeFunctionRegex("_DISTINCT_COLLECT_LEFT_ANTI", 4)
+	s = distinctCollectLeftAnti.ReplaceAllString(s, `{{template "distinctCollectLeftAnti" buildDict "Global" . "UseSel" $4}}`)
+
 	collectRightOuter := makeFunctionRegex("_COLLECT_RIGHT_OUTER", 5)
 	s = collectRightOuter.ReplaceAllString(s, `{{template "collectRightOuter" buildDict "Global" . "UseSel" $5}}`)
 
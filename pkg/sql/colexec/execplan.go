@@ -252,6 +252,29 @@ func createJoiner(
 	return err
 }
 
+// nullAwareAntiJoinEqCols checks whether the hash joiner described by spec is
+// a LEFT ANTI join implementing NOT IN, which is planned as a NULL-aware anti
+// join. This is the case when it has no equality columns and its ON
+// expression compares a left column to a right column of the same type with
+// NOT IN semantics. If so, it returns the indices of the two columns.
+func nullAwareAntiJoinEqCols(
+	spec *execinfrapb.ProcessorSpec,
+) (leftEqCol uint32, rightEqCol uint32, ok bool) {
+	core := spec.Core.HashJoiner
+	if core.Type != sqlbase.JoinType_LEFT_ANTI || len(core.LeftEqColumns) != 0 ||
+		len(core.RightEqColumns) != 0 || len(spec.Input) != 2 {
+		return 0, 0, false
+	}
+	leftTypes, rightTypes := spec.Input[0].ColumnTypes, spec.Input[1].ColumnTypes
+	leftEqCol, rightEqCol, ok = findNullAwareAntiJoinEqCols(
+		core.OnExpr, len(leftTypes), len(rightTypes),
+	)
+	if !ok || !leftTypes[leftEqCol].Identical(&rightTypes[rightEqCol]) {
+		return 0, 0, false
+	}
+	return leftEqCol, rightEqCol, true
+}
+
 // isSupported checks whether we have a columnar operator equivalent to a
 // processor described by spec. Note that it doesn't perform any other checks
 // (like validity of the number of inputs).
@@ -321,11 +344,11 @@ func isSupported(spec *execinfrapb.ProcessorSpec) (bool, error) {
 	case core.HashJoiner != nil:
 		if !core.HashJoiner.OnExpr.Empty() &&
 			core.HashJoiner.Type != sqlbase.JoinType_INNER {
+			if _, _, ok := nullAwareAntiJoinEqCols(spec); ok {
+				return true, nil
+			}
 			return false, errors.Newf("can't plan non-inner hash join with on expressions")
 		}
-		if core.HashJoiner.Type == sqlbase.JoinType_LEFT_ANTI {
-			return false, errors.Newf("LEFT ANTI hash join is unsupported")
-		}
 		return true, nil
 
 	case core.MergeJoiner != nil:
@@ -623,6 +646,25 @@ func NewColOperator(
 				leftTypes, rightTypes []coltypes.T,
 				leftOutCols, rightOutCols []uint32,
 			) (*execinfrapb.Expression, filterPlanningState, []uint32, []uint32, error) {
+				hashJoinerMemAccount := streamingMemAccount
+				if !useStreamingMemAccountForBuffering {
+					hashJoinerMemAccount = result.createBufferingMemAccount(ctx, flowCtx, "hash-joiner-limited")
+				}
+				if leftEqCol, rightEqCol, ok := nullAwareAntiJoinEqCols(spec); ok {
+					// The ON expression is implemented by the NULL-aware anti join
+					// itself.
+					result.Op, err = NewNullAwareAntiHashJoinerOp(
+						NewAllocator(ctx, hashJoinerMemAccount),
+						inputs[0],
+						inputs[1],
+						leftEqCol,
+						rightEqCol,
+						leftOutCols,
+						leftTypes,
+						rightTypes,
+					)
+					return nil, filterPlanningState{}, leftOutCols, nil, err
+				}
 				var (
 					onExpr         *execinfrapb.Expression
 					onExprPlanning filterPlanningState
@@ -637,11 +679,6 @@ func NewColOperator(
 						return onExpr, onExprPlanning, leftOutCols, rightOutCols, err
 					}
 				}
-
-				hashJoinerMemAccount := streamingMemAccount
-				if !useStreamingMemAccountForBuffering {
-					hashJoinerMemAccount = result.createBufferingMemAccount(ctx, flowCtx, "hash-joiner-limited")
-				}
 				result.Op, err = NewEqHashJoinerOp(
 					NewAllocator(ctx, hashJoinerMemAccount),
 					inputs[0],
@@ -1400,10 +1437,6 @@ func planProjectionOperators(
 		}
 		return op, resultIdx, ct, internalMemUsed, nil
 	case *tree.CaseExpr:
-		if t.Expr != nil {
-			return nil, resultIdx, ct, internalMemUsed, errors.New("CASE <expr> WHEN expressions unsupported")
-		}
-
 		buffer := NewBufferOp(input)
 		internalMemUsed += buffer.(InternalMemoryOperator).InternalMemoryUsage()
 		caseOps := make([]Operator, len(t.Whens))
@@ -1425,9 +1458,16 @@ func planProjectionOperators(
 			// previous WHEN arm. Finally, after each WHEN arm runs, we copy the
 			// results of the WHEN into a single output vector, assembling the final
 			// result of the case projection.
+			//
+			// In case of 'CASE <expr> WHEN <val> ...' form, each WHEN arm is the
+			// boolean projection of '<expr> = <val>'.
 			var whenInternalMemUsed, thenInternalMemUsed int
+			whenTyped := when.Cond.(tree.TypedExpr)
+			if t.Expr != nil {
+				whenTyped = tree.NewTypedComparisonExpr(tree.EQ, t.Expr.(tree.TypedExpr), whenTyped)
+			}
 			caseOps[i], resultIdx, ct, whenInternalMemUsed, err = planTypedMaybeNullProjectionOperators(
-				ctx, evalCtx, when.Cond.(tree.TypedExpr), t.ResolvedType(), ct, buffer, acc,
+				ctx, evalCtx, whenTyped, t.ResolvedType(), ct, buffer, acc,
 			)
 			if err != nil {
 				return nil, resultIdx, ct, internalMemUsed, err
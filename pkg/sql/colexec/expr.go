@@ -64,3 +64,46 @@ func (i ivarExpressionVisitor) VisitPre(expr tree.Expr) (bool, tree.Expr) {
 
 // VisitPost is a part of tree.Visitor interface.
 func (i ivarExpressionVisitor) VisitPost(expr tree.Expr) tree.Expr { return expr }
+
+// findNullAwareAntiJoinEqCols checks whether the ON expression of a join has
+// the form (@l = @r) IS NOT false, with @l referring to a left column and @r
+// to a right column. This is how the optimizer expresses NOT IN, whose NULL
+// semantics a NULL-aware anti join implements. If so, it returns the indices
+// of the two columns, the right one relative to the right input.
+func findNullAwareAntiJoinEqCols(
+	onExpr execinfrapb.Expression, numLeftCols int, numRightCols int,
+) (leftEqCol uint32, rightEqCol uint32, ok bool) {
+	var expr tree.Expr
+	if onExpr.LocalExpr != nil {
+		expr = onExpr.LocalExpr
+	} else {
+		e, err := parser.ParseExpr(onExpr.Expr)
+		if err != nil {
+			return 0, 0, false
+		}
+		expr = e
+	}
+	isNot, ok := tree.StripParens(expr).(*tree.ComparisonExpr)
+	if !ok || isNot.Operator != tree.IsDistinctFrom || isNot.Right != tree.DBoolFalse {
+		return 0, 0, false
+	}
+	eq, ok := tree.StripParens(isNot.Left).(*tree.ComparisonExpr)
+	if !ok || eq.Operator != tree.EQ {
+		return 0, 0, false
+	}
+	left, ok := tree.StripParens(eq.Left).(*tree.IndexedVar)
+	if !ok {
+		return 0, 0, false
+	}
+	right, ok := tree.StripParens(eq.Right).(*tree.IndexedVar)
+	if !ok {
+		return 0, 0, false
+	}
+	if left.Idx >= numLeftCols {
+		left, right = right, left
+	}
+	if left.Idx >= numLeftCols || right.Idx < numLeftCols || right.Idx >= numLeftCols+numRightCols {
+		return 0, 0, false
+	}
+	return uint32(left.Idx), uint32(right.Idx - numLeftCols), true
+}
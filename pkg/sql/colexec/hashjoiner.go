@@ -43,6 +43,10 @@ const (
 	// emitting unmatched rows from its build table after having consumed the
 	// probe table. This happens in the case of an outer join on the build side.
 	hjEmittingUnmatched
+
+	// hjDone represents the state the hashJoiner is in when it knows that it
+	// won't output any more rows.
+	hjDone
 )
 
 // hashJoinerSpec is the specification for a hash joiner processor. The hash
@@ -50,6 +54,12 @@ const (
 // combined left and right output columns.
 
 type hashJoinerSpec struct {
+	// joinType is the type of the join performed by the hash joiner.
+	joinType sqlbase.JoinType
+	// nullAware is set for a LEFT ANTI join with the semantics of NOT IN, see
+	// NewNullAwareAntiHashJoinerOp.
+	nullAware bool
+
 	// left and right are the specifications of the two input table sources to
 	// the hash joiner.
 	left  hashJoinerSourceSpec
@@ -168,6 +178,16 @@ type hashJoinerSourceSpec struct {
 // emitUnmatched is performed after the probing ends. This is done by gathering
 // all build table rows that have never been matched and stitching it together
 // with NULL values on the probe side.
+//
+// In the case of a LEFT ANTI join, the right side is always used as the build
+// table and only the probe rows that have a groupID of 0 (meaning they didn't
+// match any build row) are emitted. Note that a probe row with a NULL in any
+// of its equality columns never matches, so it is always emitted.
+//
+// A NULL-aware LEFT ANTI join, which implements NOT IN, emits the probe rows
+// for which comparing their key to every build key yields false. Thus, it
+// emits nothing if any build key is NULL, and, unless the build table is
+// empty, it doesn't emit the probe rows whose key is NULL.
 type hashJoinEqOp struct {
 	allocator *Allocator
 	// spec, if not nil, holds the specification for the current hash joiner
@@ -248,6 +268,7 @@ func (hj *hashJoinEqOp) Init() {
 	hj.prober = makeHashJoinProber(
 		hj.allocator,
 		hj.ht, probe, build,
+		hj.spec.joinType,
 		hj.spec.buildRightSide,
 		hj.spec.buildDistinct,
 		hj.outputBatchSize,
@@ -274,6 +295,8 @@ func (hj *hashJoinEqOp) Next(ctx context.Context) coldata.Batch {
 		case hjEmittingUnmatched:
 			hj.emitUnmatched()
 			return hj.prober.batch
+		case hjDone:
+			return coldata.ZeroBatch
 		default:
 			execerror.VectorizedInternalPanic("hash joiner in unhandled state")
 			// This code is unreachable, but the compiler cannot infer that.
@@ -285,6 +308,14 @@ func (hj *hashJoinEqOp) Next(ctx context.Context) coldata.Batch {
 func (hj *hashJoinEqOp) build(ctx context.Context) {
 	hj.builder.distinctExec(ctx)
 
+	if hj.spec.nullAware && hj.ht.size > 0 {
+		if hj.ht.keyHasNulls() {
+			hj.runningState = hjDone
+			return
+		}
+		hj.prober.dropNullProbeKeys = true
+	}
+
 	if !hj.spec.buildDistinct {
 		hj.ht.same = make([]uint64, hj.ht.size+1)
 		hj.ht.allocateVisited()
@@ -598,6 +629,23 @@ func (ht *hashTable) buildNextChains(ctx context.Context) {
 	}
 }
 
+// keyHasNulls returns whether any of the keys stored in the hashTable has a
+// NULL in one of its columns.
+func (ht *hashTable) keyHasNulls() bool {
+	for _, k := range ht.keyCols {
+		nulls := ht.vals[k].Nulls()
+		if !nulls.MaybeHasNulls() {
+			continue
+		}
+		for i := uint64(0); i < ht.size; i++ {
+			if nulls.NullAt64(i) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // allocateVisited allocates the visited array in the hashTable.
 func (ht *hashTable) allocateVisited() {
 	ht.visited = make([]bool, ht.size+1)
@@ -745,12 +793,18 @@ type hashJoinProber struct {
 	// build holds the source specification for the build table.
 	build hashJoinerSourceSpec
 
+	// joinType is the type of the join performed by the hash joiner.
+	joinType sqlbase.JoinType
+
 	// buildRightSide indicates whether the prober is probing on the left source
 	// or the right source.
 	buildRightSide bool
 	// buildDistinct indicates whether or not the build table equality column
 	// tuples are distinct. If they are distinct, performance can be optimized.
 	buildDistinct bool
+	// dropNullProbeKeys is set by a NULL-aware anti join whose build table
+	// isn't empty, in which case probe rows with a NULL key aren't emitted.
+	dropNullProbeKeys bool
 
 	// prevBatch, if not nil, indicates that the previous probe input batch has
 	// not been fully processed.
@@ -762,6 +816,7 @@ func makeHashJoinProber(
 	ht *hashTable,
 	probe hashJoinerSourceSpec,
 	build hashJoinerSourceSpec,
+	joinType sqlbase.JoinType,
 	buildRightSide bool,
 	buildDistinct bool,
 	outputBatchSize uint16,
@@ -805,6 +860,8 @@ func makeHashJoinProber(
 		spec:  probe,
 		build: build,
 
+		joinType: joinType,
+
 		probeRowUnmatched: probeRowUnmatched,
 
 		buildColOffset: buildColOffset,
@@ -1103,11 +1160,22 @@ func NewEqHashJoinerOp(
 		if len(rightOutCols) != 0 {
 			return nil, errors.Errorf("semi-join can't have right-side output columns")
 		}
+	case sqlbase.JoinType_LEFT_ANTI:
+		// Similarly to a semi-join, in an anti-join we only care whether a row on
+		// the left matches any row on the right, so we build the hash table from
+		// the right side and treat its equality columns as distinct.
+		buildRightSide = true
+		buildDistinct = true
+		if len(rightOutCols) != 0 {
+			return nil, errors.Errorf("anti-join can't have right-side output columns")
+		}
 	default:
 		return nil, errors.Errorf("hash join of type %s not supported", joinType)
 	}
 
 	spec := hashJoinerSpec{
+		joinType: joinType,
+
 		left: hashJoinerSourceSpec{
 			eqCols:      leftEqCols,
 			outCols:     leftOutCols,
@@ -1134,3 +1202,34 @@ func NewEqHashJoinerOp(
 		outputBatchSize: coldata.BatchSize(),
 	}, nil
 }
+
+// NewNullAwareAntiHashJoinerOp creates a new LEFT ANTI hash join operator with
+// the semantics of NOT IN: a left row is emitted only if comparing its
+// equality column to that of every right row yields false. Unlike a regular
+// anti join, it emits nothing if the right side has a NULL key, and it doesn't
+// emit left rows with a NULL key unless the right side is empty. Only a single
+// equality column is supported.
+func NewNullAwareAntiHashJoinerOp(
+	allocator *Allocator,
+	leftSource Operator,
+	rightSource Operator,
+	leftEqCol uint32,
+	rightEqCol uint32,
+	leftOutCols []uint32,
+	leftTypes []coltypes.T,
+	rightTypes []coltypes.T,
+) (Operator, error) {
+	op, err := NewEqHashJoinerOp(
+		allocator, leftSource, rightSource,
+		[]uint32{leftEqCol}, []uint32{rightEqCol},
+		leftOutCols, nil, /* rightOutCols */
+		leftTypes, rightTypes,
+		true /* buildRightSide */, true, /* buildDistinct */
+		sqlbase.JoinType_LEFT_ANTI,
+	)
+	if err != nil {
+		return nil, err
+	}
+	op.(*hashJoinEqOp).spec.nullAware = true
+	return op, nil
+}
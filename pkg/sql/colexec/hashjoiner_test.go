@@ -802,6 +802,175 @@ func init() {
 				{1},
 			},
 		},
+		{
+			leftTypes:  []coltypes.T{coltypes.Int64},
+			rightTypes: []coltypes.T{coltypes.Int64},
+
+			joinType: sqlbase.JoinType_LEFT_ANTI,
+
+			leftTuples: tuples{
+				{0},
+				{0},
+				{1},
+				{2},
+				{nil},
+			},
+			rightTuples: tuples{
+				{0},
+				{0},
+				{1},
+				{nil},
+			},
+
+			leftEqCols:   []uint32{0},
+			rightEqCols:  []uint32{0},
+			leftOutCols:  []uint32{0},
+			rightOutCols: []uint32{},
+
+			leftEqColsAreKey:  false,
+			rightEqColsAreKey: false,
+
+			// NULL never matches, so the left NULL row is emitted.
+			expectedTuples: tuples{
+				{2},
+				{nil},
+			},
+		},
+		{
+			leftTypes:  []coltypes.T{coltypes.Int64, coltypes.Bytes},
+			rightTypes: []coltypes.T{coltypes.Int64, coltypes.Bytes},
+
+			// Test LEFT ANTI join with multiple equality columns.
+			joinType: sqlbase.JoinType_LEFT_ANTI,
+
+			leftTuples: tuples{
+				{0, "a"},
+				{0, "b"},
+				{1, "a"},
+				{1, nil},
+			},
+			rightTuples: tuples{
+				{0, "a"},
+				{1, "b"},
+				{1, "a"},
+			},
+
+			leftEqCols:   []uint32{0, 1},
+			rightEqCols:  []uint32{0, 1},
+			leftOutCols:  []uint32{0, 1},
+			rightOutCols: []uint32{},
+
+			leftEqColsAreKey:  true,
+			rightEqColsAreKey: true,
+
+			expectedTuples: tuples{
+				{0, "b"},
+				{1, nil},
+			},
+		},
+		{
+			leftTypes:  []coltypes.T{coltypes.Int64},
+			rightTypes: []coltypes.T{coltypes.Int64},
+
+			// Test LEFT ANTI join with an empty right side.
+			joinType: sqlbase.JoinType_LEFT_ANTI,
+
+			leftTuples: tuples{
+				{0},
+				{nil},
+				{1},
+			},
+			rightTuples: tuples{},
+
+			leftEqCols:   []uint32{0},
+			rightEqCols:  []uint32{0},
+			leftOutCols:  []uint32{0},
+			rightOutCols: []uint32{},
+
+			expectedTuples: tuples{
+				{0},
+				{nil},
+				{1},
+			},
+		},
+		{
+			leftTypes:  []coltypes.T{coltypes.Int64, coltypes.Int64},
+			rightTypes: []coltypes.T{coltypes.Int64},
+
+			// Test NULL-aware LEFT ANTI join, as planned for NOT IN. Left rows
+			// with a NULL key aren't emitted when the right side isn't empty.
+			joinType: sqlbase.JoinType_LEFT_ANTI,
+
+			leftTuples: tuples{
+				{1, 1},
+				{2, 2},
+				{nil, 3},
+			},
+			rightTuples: tuples{
+				{1},
+				{3},
+			},
+
+			leftOutCols:  []uint32{0, 1},
+			rightOutCols: []uint32{},
+
+			onExpr: execinfrapb.Expression{Expr: "(@1 = @3) IS NOT false"},
+
+			expectedTuples: tuples{
+				{2, 2},
+			},
+		},
+		{
+			leftTypes:  []coltypes.T{coltypes.Int64, coltypes.Int64},
+			rightTypes: []coltypes.T{coltypes.Int64},
+
+			// Test NULL-aware LEFT ANTI join with a NULL key on the right side,
+			// in which case no row is emitted.
+			joinType: sqlbase.JoinType_LEFT_ANTI,
+
+			leftTuples: tuples{
+				{1, 1},
+				{2, 2},
+				{nil, 3},
+			},
+			rightTuples: tuples{
+				{1},
+				{nil},
+			},
+
+			leftOutCols:  []uint32{0, 1},
+			rightOutCols: []uint32{},
+
+			onExpr: execinfrapb.Expression{Expr: "(@3 = @1) IS NOT false"},
+
+			expectedTuples: tuples{},
+		},
+		{
+			leftTypes:  []coltypes.T{coltypes.Int64, coltypes.Int64},
+			rightTypes: []coltypes.T{coltypes.Int64},
+
+			// Test NULL-aware LEFT ANTI join with an empty right side, in which
+			// case all rows are emitted.
+			joinType: sqlbase.JoinType_LEFT_ANTI,
+
+			leftTuples: tuples{
+				{1, 1},
+				{2, 2},
+				{nil, 3},
+			},
+			rightTuples: tuples{},
+
+			leftOutCols:  []uint32{0, 1},
+			rightOutCols: []uint32{},
+
+			onExpr: execinfrapb.Expression{Expr: "(@1 = @3) IS NOT false"},
+
+			expectedTuples: tuples{
+				{1, 1},
+				{2, 2},
+				{nil, 3},
+			},
+		},
 		{
 			leftTypes:  []coltypes.T{coltypes.Int64, coltypes.Int64},
 			rightTypes: []coltypes.T{coltypes.Int64, coltypes.Int64},
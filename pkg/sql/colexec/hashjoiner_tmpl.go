@@ -32,6 +32,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/colexec/execerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/colexec/execgen"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 )
 
 // {{/*
@@ -344,6 +345,40 @@ func _DISTINCT_COLLECT_NO_OUTER(
 	// {{/*
 }
 
+func _DISTINCT_COLLECT_LEFT_ANTI(
+	prober *hashJoinProber, batchSize uint16, nResults uint16, _USE_SEL bool,
+) { // */}}
+	// {{define "distinctCollectLeftAnti"}}
+	// Early bounds checks.
+	_ = prober.ht.groupID[batchSize-1]
+	_ = prober.probeIdx[batchSize-1]
+	// {{if .UseSel}}
+	_ = sel[batchSize-1]
+	// {{end}}
+	for i := uint16(0); i < batchSize; i++ {
+		if prober.ht.groupID[i] == 0 {
+			// The probe row didn't match any of the build rows, so it is emitted
+			// by the anti join. Note that there are no build columns in the output,
+			// so we don't need to populate buildIdx.
+			// {{if .UseSel}}
+			probeIdx := sel[i]
+			// {{else}}
+			probeIdx := i
+			// {{end}}
+			if prober.dropNullProbeKeys && prober.ht.keys[0].Nulls().NullAt(probeIdx) {
+				// In a NULL-aware anti join, comparing a NULL probe key to the
+				// build keys yields NULL rather than false, so the row isn't
+				// emitted.
+				continue
+			}
+			prober.probeIdx[nResults] = probeIdx
+			nResults++
+		}
+	}
+	// {{end}}
+	// {{/*
+}
+
 // */}}
 
 // Use execgen package to remove unused import warning.
@@ -452,7 +487,13 @@ func (prober *hashJoinProber) distinctCollect(
 ) uint16 {
 	nResults := uint16(0)
 
-	if prober.spec.outer {
+	if prober.joinType == sqlbase.JoinType_LEFT_ANTI {
+		if sel != nil {
+			_DISTINCT_COLLECT_LEFT_ANTI(prober, batchSize, nResults, true)
+		} else {
+			_DISTINCT_COLLECT_LEFT_ANTI(prober, batchSize, nResults, false)
+		}
+	} else if prober.spec.outer {
 		nResults = batchSize
 
 		if sel != nil {
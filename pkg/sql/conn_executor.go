@@ -282,7 +282,13 @@ func NewServer(cfg *ExecutorConfig, pool *mon.BytesMonitor) *Server {
 		// dbCache will be updated on Start().
 		dbCache:  newDatabaseCacheHolder(newDatabaseCache(systemCfg)),
 		pool:     pool,
-		sqlStats: sqlStats{st: cfg.Settings, apps: make(map[string]*appStats)},
+		sqlStats: sqlStats{
+			st: cfg.Settings,
+			// The statistics collected until the first reset are aggregated since
+			// the server's start.
+			lastReset: timeutil.Now(),
+			apps:      make(map[string]*appStats),
+		},
 		reCache:  tree.NewRegexpCache(512),
 	}
 }
@@ -331,11 +337,16 @@ func (s *Server) Start(ctx context.Context, stopper *stop.Stopper) {
 		}
 	})
 	s.PeriodicallyClearSQLStats(ctx, stopper)
+	s.PeriodicallyPersistSQLStats(ctx, stopper)
 }
 
-// ResetSQLStats resets the executor's collected sql statistics.
+// ResetSQLStats resets the executor's collected sql statistics, persisting
+// them to system.statement_statistics first.
 func (s *Server) ResetSQLStats(ctx context.Context) {
-	s.sqlStats.resetStats(ctx)
+	aggregatedTS, stats := s.sqlStats.resetStats(ctx)
+	if err := s.persistSQLStats(ctx, aggregatedTS, stats); err != nil {
+		log.Warningf(ctx, "failed to persist statement statistics: %v", err)
+	}
 }
 
 // GetScrubbedStmtStats returns the statement statistics by app, with the
@@ -893,6 +904,12 @@ type connExecutor struct {
 		// committed or aborted). It is set when txn is started but can remain
 		// unset when txn is executed within another higher-level txn.
 		onTxnFinish func(txnEvent)
+
+		// stmtFingerprintIDs are the IDs of the fingerprints of the statements
+		// executed by the current transaction, in order. They make up the
+		// transaction's fingerprint, capped at maxStmtFingerprintIDsPerTxn.
+		// They are cleared when the transaction is retried.
+		stmtFingerprintIDs []int64
 	}
 
 	// sessionData contains the user-configurable connection variables.
@@ -1954,6 +1971,9 @@ func (ex *connExecutor) resetPlanner(
 
 	p.autoCommit = false
 	p.isPreparing = false
+	p.collectBundle = false
+	p.explainBundle = false
+	p.boundedStaleness = false
 	p.avoidCachedDescriptors = false
 }
 
@@ -1983,6 +2003,7 @@ func (ex *connExecutor) txnStateTransitionsApplyWrapper(
 
 	if advInfo.code == rewind {
 		ex.extraTxnState.autoRetryCounter++
+		ex.extraTxnState.stmtFingerprintIDs = ex.extraTxnState.stmtFingerprintIDs[:0]
 	}
 
 	// Handle transaction events which cause updates to txnState.
@@ -1990,6 +2011,7 @@ func (ex *connExecutor) txnStateTransitionsApplyWrapper(
 	case noEvent:
 	case txnStart:
 		ex.extraTxnState.autoRetryCounter = 0
+		ex.extraTxnState.stmtFingerprintIDs = ex.extraTxnState.stmtFingerprintIDs[:0]
 		ex.extraTxnState.onTxnFinish = ex.recordTransactionStart()
 	case txnCommit:
 		if res.Err() != nil {
@@ -20,11 +20,15 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/base"
 	"github.com/cockroachdb/cockroach/pkg/internal/client"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/server/telemetry"
+	"github.com/cockroachdb/cockroach/pkg/sql/execinfrapb"
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqltelemetry"
+	"github.com/cockroachdb/cockroach/pkg/sql/stmtdiagnostics"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
 	"github.com/cockroachdb/cockroach/pkg/util/errorutil/unimplemented"
 	"github.com/cockroachdb/cockroach/pkg/util/fsm"
@@ -34,6 +38,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/tracing"
 	"github.com/cockroachdb/errors"
+	opentracing "github.com/opentracing/opentracing-go"
 )
 
 // RestartSavepointName is the only savepoint ident that we accept.
@@ -358,6 +363,48 @@ func (ex *connExecutor) execStmtInOpenState(
 		discardRows = s.DiscardRows
 	}
 
+	// Check whether a statement diagnostics bundle needs to be collected for
+	// this execution, either because it was explicitly asked for with EXPLAIN
+	// ANALYZE (DEBUG) or because of an outstanding diagnostics request for the
+	// statement's fingerprint.
+	var explainBundle bool
+	if e, ok := stmt.AST.(*tree.Explain); ok {
+		if opts, err := e.ParseOptions(); err == nil && opts.Mode == tree.ExplainDebug {
+			if !opts.Flags.Contains(tree.ExplainFlagAnalyze) {
+				return makeErrEvent(
+					pgerror.New(pgcode.Syntax, "DEBUG flag can only be used with EXPLAIN ANALYZE"))
+			}
+			telemetry.Inc(sqltelemetry.ExplainAnalyzeDebugUseCounter)
+			explainBundle = true
+			// Run the statement being explained; its results are discarded and
+			// replaced with the information about the bundle.
+			stmt.AST = e.Statement
+			discardRows = true
+		}
+	}
+	collectBundle := explainBundle
+	var diagRequestID stmtdiagnostics.RequestID
+	if recorder := ex.server.cfg.StmtDiagnosticsRecorder; !collectBundle && recorder != nil {
+		collectBundle, diagRequestID = recorder.ShouldCollectDiagnostics(ctx, stmt.AST)
+	}
+	if collectBundle {
+		origCtx := ctx
+		var sp opentracing.Span
+		ctx, sp, retErr = tracing.StartSnowballTrace(
+			ctx, ex.server.cfg.AmbientCtx.Tracer, "traced statement")
+		if retErr != nil {
+			return nil, nil, retErr
+		}
+		defer func() {
+			trace := tracing.GetRecording(sp)
+			sp.Finish()
+			ex.collectStatementBundle(
+				origCtx, stmt.AST, trace, diagRequestID, explainBundle, res,
+				retErr == nil && !payloadHasError(retPayload),
+			)
+		}()
+	}
+
 	// For regular statements (the ones that get to this point), we don't return
 	// any event unless an an error happens.
 
@@ -365,34 +412,46 @@ func (ex *connExecutor) execStmtInOpenState(
 	stmtTS := ex.server.cfg.Clock.PhysicalTime()
 	ex.statsCollector.reset(&ex.server.sqlStats, ex.appStats, &ex.phaseTimes)
 	ex.resetPlanner(ctx, p, ex.state.mu.txn, stmtTS, stmt.NumAnnotations)
+	p.collectBundle = collectBundle
+	p.explainBundle = explainBundle
 
 	if os.ImplicitTxn.Get() {
-		asOfTs, err := p.isAsOf(stmt.AST)
+		asOf, err := p.isAsOf(stmt.AST)
 		if err != nil {
 			return makeErrEvent(err)
 		}
-		if asOfTs != nil {
-			p.semaCtx.AsOfTimestamp = asOfTs
-			p.extendedEvalCtx.SetTxnTimestamp(asOfTs.GoTime())
-			ex.state.setHistoricalTimestamp(ctx, *asOfTs)
+		if asOf != nil {
+			p.semaCtx.AsOfTimestamp = &asOf.Timestamp
+			ts := asOf.Timestamp
+			if asOf.BoundedStaleness {
+				// A bounded staleness read is planned at the present time and then
+				// executed at a negotiated timestamp. See negotiateBoundedStaleness.
+				p.boundedStaleness = true
+				ts = ex.server.cfg.Clock.Now()
+			}
+			p.extendedEvalCtx.SetTxnTimestamp(ts.GoTime())
+			ex.state.setHistoricalTimestamp(ctx, ts)
 		}
 	} else {
 		// If we're in an explicit txn, we allow AOST but only if it matches with
 		// the transaction's timestamp. This is useful for running AOST statements
 		// using the InternalExecutor inside an external transaction; one might want
 		// to do that to force p.avoidCachedDescriptors to be set below.
-		ts, err := p.isAsOf(stmt.AST)
+		asOf, err := p.isAsOf(stmt.AST)
 		if err != nil {
 			return makeErrEvent(err)
 		}
-		if ts != nil {
-			if readTs := ex.state.getReadTimestamp(); *ts != readTs {
+		if asOf != nil {
+			if asOf.BoundedStaleness {
+				return makeErrEvent(tree.ErrBoundedStalenessNotSupported)
+			}
+			if readTs := ex.state.getReadTimestamp(); asOf.Timestamp != readTs {
 				err = pgerror.Newf(pgcode.Syntax,
 					"inconsistent AS OF SYSTEM TIME timestamp; expected: %s", readTs)
 				err = errors.WithHint(err, "try SET TRANSACTION AS OF SYSTEM TIME")
 				return makeErrEvent(err)
 			}
-			p.semaCtx.AsOfTimestamp = ts
+			p.semaCtx.AsOfTimestamp = &asOf.Timestamp
 		}
 	}
 
@@ -447,6 +506,56 @@ func (ex *connExecutor) execStmtInOpenState(
 	return nil, nil, nil
 }
 
+// collectStatementBundle builds the diagnostics bundle for a statement that
+// just ran and records it in the system tables. For EXPLAIN ANALYZE (DEBUG),
+// the link to the bundle is returned to the client as the result of the
+// statement.
+func (ex *connExecutor) collectStatementBundle(
+	ctx context.Context,
+	ast tree.Statement,
+	trace tracing.Recording,
+	requestID stmtdiagnostics.RequestID,
+	explainBundle bool,
+	res RestrictedCommandResult,
+	succeeded bool,
+) {
+	recorder := ex.server.cfg.StmtDiagnosticsRecorder
+	var diagID stmtdiagnostics.CollectedInstanceID
+	var err error
+	if recorder == nil {
+		err = errors.AssertionFailedf("statement diagnostics recorder not configured")
+	} else {
+		bundle := buildStatementBundle(
+			ctx, ex.server.cfg.InternalExecutor, &ex.planner, ast, trace,
+		)
+		diagID, err = bundle.insert(
+			ctx, tree.AsStringWithFlags(ast, tree.FmtHideConstants), ast, recorder, requestID,
+		)
+	}
+	if err != nil {
+		log.Warningf(ctx, "could not record statement diagnostics: %v", err)
+	}
+	if !explainBundle || !succeeded || res.Err() != nil {
+		return
+	}
+	if err != nil {
+		res.SetError(errors.Wrap(err, "error recording statement diagnostics bundle"))
+		return
+	}
+	text := []string{
+		"Statement diagnostics bundle generated. Download from the Admin UI (Advanced " +
+			"Debug -> Statement Diagnostics History) or use the direct link below.",
+		fmt.Sprintf("Direct link: %s/_admin/v1/stmtbundle/%d",
+			ex.server.cfg.AdminURL(), diagID),
+	}
+	for _, r := range text {
+		if err := res.AddRow(ctx, tree.Datums{tree.NewDString(r)}); err != nil {
+			log.Warningf(ctx, "could not return statement diagnostics bundle link: %v", err)
+			return
+		}
+	}
+}
+
 // checkTableTwoVersionInvariant checks whether any new table schema being
 // modified written at a version V has only valid leases at version = V - 1.
 // A transaction retry error is returned whenever the invariant is violated.
@@ -665,13 +774,26 @@ func (ex *connExecutor) dispatchToExecutionEngine(
 		return nil
 	}
 
-	var cols sqlbase.ResultColumns
-	if stmt.AST.StatementType() == tree.Rows {
-		cols = planColumns(planner.curPlan.plan)
+	if planner.boundedStaleness {
+		if err := ex.negotiateBoundedStaleness(ctx, planner); err != nil {
+			res.SetError(err)
+			return nil
+		}
 	}
-	if err := ex.initStatementResult(ctx, res, stmt, cols); err != nil {
-		res.SetError(err)
-		return nil
+
+	if planner.explainBundle {
+		// The results of the statement are replaced by the information about
+		// the bundle once execution is done.
+		res.SetColumns(ctx, sqlbase.ExplainAnalyzeDebugColumns)
+	} else {
+		var cols sqlbase.ResultColumns
+		if stmt.AST.StatementType() == tree.Rows {
+			cols = planColumns(planner.curPlan.plan)
+		}
+		if err := ex.initStatementResult(ctx, res, stmt, cols); err != nil {
+			res.SetError(err)
+			return nil
+		}
 	}
 
 	ex.sessionTracing.TracePlanCheckStart(ctx)
@@ -799,6 +921,11 @@ func (ex *connExecutor) execWithDistSQLEngine(
 	planCtx.isLocal = !distribute
 	planCtx.planner = planner
 	planCtx.stmtType = recv.stmtType
+	if bundle := planner.curPlan.bundle; bundle != nil {
+		planCtx.saveDiagram = func(diagram execinfrapb.FlowDiagram) {
+			bundle.distSQLDiagrams = append(bundle.distSQLDiagrams, diagram)
+		}
+	}
 
 	var evalCtxFactory func() *extendedEvalContext
 	if len(planner.curPlan.subqueryPlans) != 0 || len(planner.curPlan.postqueryPlans) != 0 {
@@ -1296,19 +1423,27 @@ func (ex *connExecutor) recordTransactionStart() func(txnEvent) {
 	// transaction.
 	ex.phaseTimes[transactionStart] = timeutil.Now()
 	implicit := ex.implicitTxn()
-	return func(ev txnEvent) { ex.recordTransaction(ev, implicit) }
+	txn := ex.state.mu.txn
+	return func(ev txnEvent) { ex.recordTransaction(ev, implicit, txn) }
 }
 
-func (ex *connExecutor) recordTransaction(ev txnEvent, implicit bool) {
+func (ex *connExecutor) recordTransaction(ev txnEvent, implicit bool, txn *client.Txn) {
 	phaseTimes := &ex.statsCollector.phaseTimes
 	phaseTimes[transactionEnd] = timeutil.Now()
 	txnStart := phaseTimes[transactionStart]
 	txnEnd := phaseTimes[transactionEnd]
 	txnTime := txnEnd.Sub(txnStart)
 	ex.metrics.EngineMetrics.SQLTxnLatency.RecordValue(txnTime.Nanoseconds())
+	var contentionTime time.Duration
+	if txn != nil {
+		contentionTime = txn.ContentionTime()
+	}
 	ex.statsCollector.recordTransaction(
 		txnTime.Seconds(),
 		ev,
 		implicit,
+		ex.extraTxnState.stmtFingerprintIDs,
+		ex.extraTxnState.autoRetryCounter,
+		contentionTime,
 	)
 }
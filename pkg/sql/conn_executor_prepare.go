@@ -199,13 +199,19 @@ func (ex *connExecutor) populatePrepared(
 	}
 	p.extendedEvalCtx.PrepareOnly = true
 
-	protoTS, err := p.isAsOf(stmt.AST)
+	asOf, err := p.isAsOf(stmt.AST)
 	if err != nil {
 		return 0, err
 	}
-	if protoTS != nil {
-		p.semaCtx.AsOfTimestamp = protoTS
-		txn.SetFixedTimestamp(ctx, *protoTS)
+	if asOf != nil {
+		p.semaCtx.AsOfTimestamp = &asOf.Timestamp
+		ts := asOf.Timestamp
+		if asOf.BoundedStaleness {
+			// Bounded staleness reads are prepared at the present time; the read
+			// timestamp is negotiated upon execution.
+			ts = ex.server.cfg.Clock.Now()
+		}
+		txn.SetFixedTimestamp(ctx, ts)
 	}
 
 	// PREPARE has a limited subset of statements it can be run with. Postgres
@@ -390,3 +390,31 @@ func TestAppNameStatisticsInitialization(t *testing.T) {
 		t.Fatalf("query was not counted properly: %+v", counts)
 	}
 }
+
+func TestTransactionFingerprintStatistics(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	params, _ := tests.CreateTestServerParams()
+	s, db, _ := serverutils.StartServer(t, params)
+	defer s.Stopper().Stop(context.TODO())
+
+	sqlDB := sqlutils.MakeSQLRunner(db)
+	sqlDB.Exec(t, "CREATE TABLE t (x INT PRIMARY KEY)")
+	sqlDB.Exec(t, "SET application_name = 'txn_fingerprint_test'")
+	for i := 0; i < 2; i++ {
+		sqlDB.Exec(t, "BEGIN")
+		sqlDB.Exec(t, "INSERT INTO t VALUES ($1)", i)
+		sqlDB.Exec(t, "SELECT count(*) FROM t")
+		sqlDB.Exec(t, "COMMIT")
+	}
+	sqlDB.Exec(t, "SET application_name = ''")
+
+	// Both transactions executed the same statement fingerprints, so they
+	// share a transaction fingerprint.
+	sqlDB.CheckQueryResults(t, `
+SELECT count, committed_count, array_length(statement_ids, 1)
+  FROM crdb_internal.node_transaction_statistics
+ WHERE application_name = 'txn_fingerprint_test' AND array_length(statement_ids, 1) = 2`,
+		[][]string{{"2", "2", "2"}},
+	)
+}
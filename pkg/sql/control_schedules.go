@@ -0,0 +1,99 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/errors"
+)
+
+type controlSchedulesNode struct {
+	scheduleID tree.TypedExpr
+	command    tree.ScheduleCommand
+	numRows    int
+}
+
+// ControlSchedules pauses, resumes or drops a schedule.
+// Privileges: admin.
+func (p *planner) ControlSchedules(
+	ctx context.Context, n *tree.ControlSchedules,
+) (planNode, error) {
+	op := tree.ScheduleCommandToStatement[n.Command] + " SCHEDULE"
+	if err := p.RequireAdminRole(ctx, op); err != nil {
+		return nil, err
+	}
+	scheduleID, err := p.analyzeExpr(
+		ctx, n.ScheduleID, nil, tree.IndexedVarHelper{}, types.Int, true, op,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &controlSchedulesNode{scheduleID: scheduleID, command: n.Command}, nil
+}
+
+// FastPathResults implements the planNodeFastPath inteface.
+func (n *controlSchedulesNode) FastPathResults() (int, bool) {
+	return n.numRows, true
+}
+
+func (n *controlSchedulesNode) startExec(params runParams) error {
+	idDatum, err := n.scheduleID.Eval(params.EvalContext())
+	if err != nil {
+		return err
+	}
+	if idDatum == tree.DNull {
+		return pgerror.New(pgcode.InvalidParameterValue, "schedule ID cannot be NULL")
+	}
+
+	ex := params.ExecCfg().InternalExecutor
+	schedule, err := jobs.LoadScheduledJob(
+		params.ctx, ex, params.p.txn, int64(tree.MustBeDInt(idDatum)),
+	)
+	if err != nil {
+		return err
+	}
+
+	switch n.command {
+	case tree.PauseSchedule:
+		schedule.Pause()
+		err = schedule.Update(params.ctx, ex, params.p.txn)
+	case tree.ResumeSchedule:
+		if !schedule.IsPaused() {
+			break
+		}
+		if err = schedule.ScheduleNextRun(timeutil.Now()); err != nil {
+			return err
+		}
+		err = schedule.Update(params.ctx, ex, params.p.txn)
+	case tree.DropSchedule:
+		err = schedule.Delete(params.ctx, ex, params.p.txn)
+	default:
+		err = errors.AssertionFailedf("unhandled command %v", n.command)
+	}
+	if err != nil {
+		return err
+	}
+	n.numRows = 1
+	return nil
+}
+
+func (*controlSchedulesNode) Next(runParams) (bool, error) { return false, nil }
+
+func (*controlSchedulesNode) Values() tree.Datums { return nil }
+
+func (*controlSchedulesNode) Close(context.Context) {}
@@ -32,17 +32,20 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/server/status/statuspb"
 	"github.com/cockroachdb/cockroach/pkg/server/telemetry"
 	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/builtins"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
 	"github.com/cockroachdb/cockroach/pkg/storage/storagepb"
+	"github.com/cockroachdb/cockroach/pkg/util/duration"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/json"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
 	"github.com/cockroachdb/errors"
 	"gopkg.in/yaml.v2"
 )
@@ -63,41 +66,45 @@ const crdbInternalName = "crdb_internal"
 var crdbInternal = virtualSchema{
 	name: crdbInternalName,
 	tableDefs: map[sqlbase.ID]virtualSchemaDef{
-		sqlbase.CrdbInternalBackwardDependenciesTableID: crdbInternalBackwardDependenciesTable,
-		sqlbase.CrdbInternalBuildInfoTableID:            crdbInternalBuildInfoTable,
-		sqlbase.CrdbInternalBuiltinFunctionsTableID:     crdbInternalBuiltinFunctionsTable,
-		sqlbase.CrdbInternalClusterQueriesTableID:       crdbInternalClusterQueriesTable,
-		sqlbase.CrdbInternalClusterSessionsTableID:      crdbInternalClusterSessionsTable,
-		sqlbase.CrdbInternalClusterSettingsTableID:      crdbInternalClusterSettingsTable,
-		sqlbase.CrdbInternalCreateStmtsTableID:          crdbInternalCreateStmtsTable,
-		sqlbase.CrdbInternalFeatureUsageID:              crdbInternalFeatureUsage,
-		sqlbase.CrdbInternalForwardDependenciesTableID:  crdbInternalForwardDependenciesTable,
-		sqlbase.CrdbInternalGossipNodesTableID:          crdbInternalGossipNodesTable,
-		sqlbase.CrdbInternalGossipAlertsTableID:         crdbInternalGossipAlertsTable,
-		sqlbase.CrdbInternalGossipLivenessTableID:       crdbInternalGossipLivenessTable,
-		sqlbase.CrdbInternalGossipNetworkTableID:        crdbInternalGossipNetworkTable,
-		sqlbase.CrdbInternalIndexColumnsTableID:         crdbInternalIndexColumnsTable,
-		sqlbase.CrdbInternalJobsTableID:                 crdbInternalJobsTable,
-		sqlbase.CrdbInternalKVNodeStatusTableID:         crdbInternalKVNodeStatusTable,
-		sqlbase.CrdbInternalKVStoreStatusTableID:        crdbInternalKVStoreStatusTable,
-		sqlbase.CrdbInternalLeasesTableID:               crdbInternalLeasesTable,
-		sqlbase.CrdbInternalLocalQueriesTableID:         crdbInternalLocalQueriesTable,
-		sqlbase.CrdbInternalLocalSessionsTableID:        crdbInternalLocalSessionsTable,
-		sqlbase.CrdbInternalLocalMetricsTableID:         crdbInternalLocalMetricsTable,
-		sqlbase.CrdbInternalPartitionsTableID:           crdbInternalPartitionsTable,
-		sqlbase.CrdbInternalPredefinedCommentsTableID:   crdbInternalPredefinedCommentsTable,
-		sqlbase.CrdbInternalRangesNoLeasesTableID:       crdbInternalRangesNoLeasesTable,
-		sqlbase.CrdbInternalRangesViewID:                crdbInternalRangesView,
-		sqlbase.CrdbInternalRuntimeInfoTableID:          crdbInternalRuntimeInfoTable,
-		sqlbase.CrdbInternalSchemaChangesTableID:        crdbInternalSchemaChangesTable,
-		sqlbase.CrdbInternalSessionTraceTableID:         crdbInternalSessionTraceTable,
-		sqlbase.CrdbInternalSessionVariablesTableID:     crdbInternalSessionVariablesTable,
-		sqlbase.CrdbInternalStmtStatsTableID:            crdbInternalStmtStatsTable,
-		sqlbase.CrdbInternalTableColumnsTableID:         crdbInternalTableColumnsTable,
-		sqlbase.CrdbInternalTableIndexesTableID:         crdbInternalTableIndexesTable,
-		sqlbase.CrdbInternalTablesTableID:               crdbInternalTablesTable,
-		sqlbase.CrdbInternalTxnStatsTableID:             crdbInternalTxnStatsTable,
-		sqlbase.CrdbInternalZonesTableID:                crdbInternalZonesTable,
+		sqlbase.CrdbInternalBackwardDependenciesTableID:    crdbInternalBackwardDependenciesTable,
+		sqlbase.CrdbInternalBuildInfoTableID:               crdbInternalBuildInfoTable,
+		sqlbase.CrdbInternalBuiltinFunctionsTableID:        crdbInternalBuiltinFunctionsTable,
+		sqlbase.CrdbInternalClusterContentionEventsTableID: crdbInternalClusterContentionEventsTable,
+		sqlbase.CrdbInternalClusterQueriesTableID:          crdbInternalClusterQueriesTable,
+		sqlbase.CrdbInternalClusterSessionsTableID:         crdbInternalClusterSessionsTable,
+		sqlbase.CrdbInternalClusterSettingsTableID:         crdbInternalClusterSettingsTable,
+		sqlbase.CrdbInternalCreateStmtsTableID:             crdbInternalCreateStmtsTable,
+		sqlbase.CrdbInternalFeatureUsageID:                 crdbInternalFeatureUsage,
+		sqlbase.CrdbInternalForwardDependenciesTableID:     crdbInternalForwardDependenciesTable,
+		sqlbase.CrdbInternalGossipNodesTableID:             crdbInternalGossipNodesTable,
+		sqlbase.CrdbInternalGossipAlertsTableID:            crdbInternalGossipAlertsTable,
+		sqlbase.CrdbInternalGossipLivenessTableID:          crdbInternalGossipLivenessTable,
+		sqlbase.CrdbInternalGossipNetworkTableID:           crdbInternalGossipNetworkTable,
+		sqlbase.CrdbInternalIndexColumnsTableID:            crdbInternalIndexColumnsTable,
+		sqlbase.CrdbInternalJobsTableID:                    crdbInternalJobsTable,
+		sqlbase.CrdbInternalKVNodeStatusTableID:            crdbInternalKVNodeStatusTable,
+		sqlbase.CrdbInternalKVStoreStatusTableID:           crdbInternalKVStoreStatusTable,
+		sqlbase.CrdbInternalLeasesTableID:                  crdbInternalLeasesTable,
+		sqlbase.CrdbInternalLocalQueriesTableID:            crdbInternalLocalQueriesTable,
+		sqlbase.CrdbInternalLocalSessionsTableID:           crdbInternalLocalSessionsTable,
+		sqlbase.CrdbInternalLocalMetricsTableID:            crdbInternalLocalMetricsTable,
+		sqlbase.CrdbInternalPartitionsTableID:              crdbInternalPartitionsTable,
+		sqlbase.CrdbInternalPredefinedCommentsTableID:      crdbInternalPredefinedCommentsTable,
+		sqlbase.CrdbInternalProtectedSpansTableID:          crdbInternalProtectedSpansTable,
+		sqlbase.CrdbInternalRangesNoLeasesTableID:          crdbInternalRangesNoLeasesTable,
+		sqlbase.CrdbInternalRangesViewID:                   crdbInternalRangesView,
+		sqlbase.CrdbInternalRuntimeInfoTableID:             crdbInternalRuntimeInfoTable,
+		sqlbase.CrdbInternalSchemaChangesTableID:           crdbInternalSchemaChangesTable,
+		sqlbase.CrdbInternalSessionTraceTableID:            crdbInternalSessionTraceTable,
+		sqlbase.CrdbInternalSessionVariablesTableID:        crdbInternalSessionVariablesTable,
+		sqlbase.CrdbInternalStmtStatsTableID:               crdbInternalStmtStatsTable,
+		sqlbase.CrdbInternalPersistedStmtStatsTableID:      crdbInternalPersistedStmtStatsTable,
+		sqlbase.CrdbInternalTableColumnsTableID:            crdbInternalTableColumnsTable,
+		sqlbase.CrdbInternalTableIndexesTableID:            crdbInternalTableIndexesTable,
+		sqlbase.CrdbInternalTablesTableID:                  crdbInternalTablesTable,
+		sqlbase.CrdbInternalTxnStatsTableID:                crdbInternalTxnStatsTable,
+		sqlbase.CrdbInternalTxnFingerprintStatsTableID:     crdbInternalTxnFingerprintStatsTable,
+		sqlbase.CrdbInternalZonesTableID:                   crdbInternalZonesTable,
 	},
 	validWithNoDatabaseContext: true,
 }
@@ -591,8 +598,10 @@ CREATE TABLE crdb_internal.node_statement_statistics (
   service_lat_var     FLOAT NOT NULL,
   overhead_lat_avg    FLOAT NOT NULL,
   overhead_lat_var    FLOAT NOT NULL,
-  bytes_read          INT NOT NULL,
-  rows_read           INT NOT NULL,
+  bytes_read_avg      FLOAT NOT NULL,
+  bytes_read_var      FLOAT NOT NULL,
+  rows_read_avg       FLOAT NOT NULL,
+  rows_read_var       FLOAT NOT NULL,
   implicit_txn        BOOL NOT NULL
 )`,
 	populate: func(ctx context.Context, p *planner, _ *DatabaseDescriptor, addRow func(...tree.Datum) error) error {
@@ -669,8 +678,10 @@ CREATE TABLE crdb_internal.node_statement_statistics (
 					tree.NewDFloat(tree.DFloat(s.data.ServiceLat.GetVariance(s.data.Count))),
 					tree.NewDFloat(tree.DFloat(s.data.OverheadLat.Mean)),
 					tree.NewDFloat(tree.DFloat(s.data.OverheadLat.GetVariance(s.data.Count))),
-					tree.NewDInt(tree.DInt(s.data.BytesRead)),
-					tree.NewDInt(tree.DInt(s.data.RowsRead)),
+					tree.NewDFloat(tree.DFloat(s.data.BytesRead.Mean)),
+					tree.NewDFloat(tree.DFloat(s.data.BytesRead.GetVariance(s.data.Count))),
+					tree.NewDFloat(tree.DFloat(s.data.RowsRead.Mean)),
+					tree.NewDFloat(tree.DFloat(s.data.RowsRead.GetVariance(s.data.Count))),
 					tree.MakeDBool(tree.DBool(stmtKey.implicitTxn)),
 				)
 				s.Unlock()
@@ -683,6 +694,116 @@ CREATE TABLE crdb_internal.node_statement_statistics (
 	},
 }
 
+var crdbInternalPersistedStmtStatsTable = virtualSchemaTable{
+	comment: `statement statistics persisted by all nodes in system.statement_statistics (KV scan)`,
+	schema: `
+CREATE TABLE crdb_internal.statement_statistics (
+  aggregated_ts         TIMESTAMPTZ NOT NULL,
+  agg_interval          INTERVAL NOT NULL,
+  node_id               INT NOT NULL,
+  application_name      STRING NOT NULL,
+  flags                 STRING NOT NULL,
+  key                   STRING NOT NULL,
+  fingerprint_id        INT NOT NULL,
+  plan_hash             INT NOT NULL,
+  count                 INT NOT NULL,
+  first_attempt_count   INT NOT NULL,
+  max_retries           INT NOT NULL,
+  last_error            STRING,
+  rows_avg              FLOAT NOT NULL,
+  rows_var              FLOAT NOT NULL,
+  parse_lat_avg         FLOAT NOT NULL,
+  parse_lat_var         FLOAT NOT NULL,
+  plan_lat_avg          FLOAT NOT NULL,
+  plan_lat_var          FLOAT NOT NULL,
+  run_lat_avg           FLOAT NOT NULL,
+  run_lat_var           FLOAT NOT NULL,
+  service_lat_avg       FLOAT NOT NULL,
+  service_lat_var       FLOAT NOT NULL,
+  overhead_lat_avg      FLOAT NOT NULL,
+  overhead_lat_var      FLOAT NOT NULL,
+  bytes_read_avg        FLOAT NOT NULL,
+  bytes_read_var        FLOAT NOT NULL,
+  rows_read_avg         FLOAT NOT NULL,
+  rows_read_var         FLOAT NOT NULL,
+  implicit_txn          BOOL NOT NULL,
+  service_lat_histogram INT[] NOT NULL
+)`,
+	populate: func(ctx context.Context, p *planner, _ *DatabaseDescriptor, addRow func(...tree.Datum) error) error {
+		if err := p.RequireAdminRole(ctx, "access application statistics"); err != nil {
+			return err
+		}
+
+		query := `
+SELECT aggregated_ts, agg_interval, node_id, fingerprint_id, plan_hash, key, statistics,
+       service_lat_histogram
+  FROM system.statement_statistics
+ ORDER BY aggregated_ts, node_id, fingerprint_id`
+		rows, err := p.ExtendedEvalContext().ExecCfg.InternalExecutor.Query(
+			ctx, "crdb-internal-statement-statistics-table", p.txn, query)
+		if err != nil {
+			return err
+		}
+
+		for _, r := range rows {
+			var key roachpb.StatementStatisticsKey
+			if err := protoutil.Unmarshal([]byte(tree.MustBeDBytes(r[5])), &key); err != nil {
+				return err
+			}
+			var s roachpb.StatementStatistics
+			if err := protoutil.Unmarshal([]byte(tree.MustBeDBytes(r[6])), &s); err != nil {
+				return err
+			}
+			k := stmtKey{
+				stmt:        key.Query,
+				failed:      key.Failed,
+				distSQLUsed: key.DistSQL,
+				optUsed:     key.Opt,
+				implicitTxn: key.ImplicitTxn,
+			}
+			errString := tree.DNull
+			if s.SensitiveInfo.LastErr != "" {
+				errString = tree.NewDString(s.SensitiveInfo.LastErr)
+			}
+			if err := addRow(
+				r[0], // aggregated_ts
+				r[1], // agg_interval
+				r[2], // node_id
+				tree.NewDString(key.App),
+				tree.NewDString(k.flags()),
+				tree.NewDString(key.Query),
+				r[3], // fingerprint_id
+				r[4], // plan_hash
+				tree.NewDInt(tree.DInt(s.Count)),
+				tree.NewDInt(tree.DInt(s.FirstAttemptCount)),
+				tree.NewDInt(tree.DInt(s.MaxRetries)),
+				errString,
+				tree.NewDFloat(tree.DFloat(s.NumRows.Mean)),
+				tree.NewDFloat(tree.DFloat(s.NumRows.GetVariance(s.Count))),
+				tree.NewDFloat(tree.DFloat(s.ParseLat.Mean)),
+				tree.NewDFloat(tree.DFloat(s.ParseLat.GetVariance(s.Count))),
+				tree.NewDFloat(tree.DFloat(s.PlanLat.Mean)),
+				tree.NewDFloat(tree.DFloat(s.PlanLat.GetVariance(s.Count))),
+				tree.NewDFloat(tree.DFloat(s.RunLat.Mean)),
+				tree.NewDFloat(tree.DFloat(s.RunLat.GetVariance(s.Count))),
+				tree.NewDFloat(tree.DFloat(s.ServiceLat.Mean)),
+				tree.NewDFloat(tree.DFloat(s.ServiceLat.GetVariance(s.Count))),
+				tree.NewDFloat(tree.DFloat(s.OverheadLat.Mean)),
+				tree.NewDFloat(tree.DFloat(s.OverheadLat.GetVariance(s.Count))),
+				tree.NewDFloat(tree.DFloat(s.BytesRead.Mean)),
+				tree.NewDFloat(tree.DFloat(s.BytesRead.GetVariance(s.Count))),
+				tree.NewDFloat(tree.DFloat(s.RowsRead.Mean)),
+				tree.NewDFloat(tree.DFloat(s.RowsRead.GetVariance(s.Count))),
+				tree.MakeDBool(tree.DBool(key.ImplicitTxn)),
+				r[7], // service_lat_histogram
+			); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
+
 var crdbInternalTxnStatsTable = virtualSchemaTable{
 	comment: `per-application transaction statistics (in-memory, not durable; local node only). ` +
 		`This table is wiped periodically (by default, at least every two hours)`,
@@ -739,6 +860,99 @@ CREATE TABLE crdb_internal.node_txn_stats (
 	},
 }
 
+var crdbInternalTxnFingerprintStatsTable = virtualSchemaTable{
+	comment: `per-fingerprint transaction statistics (in-memory, not durable; local node only). ` +
+		`This table is wiped periodically (by default, at least every two hours)`,
+	schema: `
+CREATE TABLE crdb_internal.node_transaction_statistics (
+  node_id             INT NOT NULL,
+  application_name    STRING NOT NULL,
+  fingerprint_id      INT NOT NULL,
+  statement_ids       INT[] NOT NULL,
+  count               INT NOT NULL,
+  committed_count     INT NOT NULL,
+  max_retries         INT NOT NULL,
+  service_lat_avg     FLOAT NOT NULL,
+  service_lat_var     FLOAT NOT NULL,
+  contention_time_avg FLOAT NOT NULL,
+  contention_time_var FLOAT NOT NULL
+)`,
+	populate: func(ctx context.Context, p *planner, _ *DatabaseDescriptor, addRow func(...tree.Datum) error) error {
+		if err := p.RequireAdminRole(ctx, "access application statistics"); err != nil {
+			return err
+		}
+
+		sqlStats := p.extendedEvalCtx.sqlStatsCollector.sqlStats
+		if sqlStats == nil {
+			return errors.AssertionFailedf(
+				"cannot access sql statistics from this context")
+		}
+
+		nodeID := tree.NewDInt(tree.DInt(int64(p.execCfg.NodeID.Get())))
+
+		// Retrieve the application names and sort them to ensure the
+		// output is deterministic.
+		var appNames []string
+		sqlStats.Lock()
+		for n := range sqlStats.apps {
+			appNames = append(appNames, n)
+		}
+		sqlStats.Unlock()
+		sort.Strings(appNames)
+
+		for _, appName := range appNames {
+			appStats := sqlStats.getStatsForApplication(appName)
+
+			// Retrieve the fingerprint IDs and sort them to ensure the output
+			// is deterministic.
+			var ids []int64
+			appStats.Lock()
+			for id := range appStats.txnFingerprints {
+				ids = append(ids, id)
+			}
+			appStats.Unlock()
+			sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+			for _, id := range ids {
+				appStats.Lock()
+				s := appStats.txnFingerprints[id]
+				appStats.Unlock()
+				if s == nil {
+					// The statistics were reset concurrently.
+					continue
+				}
+
+				stmtIDs := tree.NewDArray(types.Int)
+				for _, stmtID := range s.stmtFingerprintIDs {
+					if err := stmtIDs.Append(tree.NewDInt(tree.DInt(stmtID))); err != nil {
+						return err
+					}
+				}
+
+				s.Lock()
+				err := addRow(
+					nodeID,
+					tree.NewDString(appName),
+					tree.NewDInt(tree.DInt(id)),
+					stmtIDs,
+					tree.NewDInt(tree.DInt(s.count)),
+					tree.NewDInt(tree.DInt(s.committedCount)),
+					tree.NewDInt(tree.DInt(s.maxRetries)),
+					tree.NewDFloat(tree.DFloat(s.serviceLat.Mean)),
+					tree.NewDFloat(tree.DFloat(s.serviceLat.GetVariance(s.count))),
+					tree.NewDFloat(tree.DFloat(s.contentionTime.Mean)),
+					tree.NewDFloat(tree.DFloat(s.contentionTime.GetVariance(s.count))),
+				)
+				s.Unlock()
+				if err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	},
+}
+
 // crdbInternalSessionTraceTable exposes the latest trace collected on this
 // session (via SET TRACING={ON/OFF})
 //
@@ -914,6 +1128,51 @@ var crdbInternalClusterQueriesTable = virtualSchemaTable{
 	},
 }
 
+// crdbInternalClusterContentionEventsTable exposes the contention events
+// recently recorded by the nodes in the cluster.
+var crdbInternalClusterContentionEventsTable = virtualSchemaTable{
+	comment: "recent contention events between transactions (cluster RPC; expensive!)",
+	schema: `
+CREATE TABLE crdb_internal.cluster_contention_events (
+  node_id         INT NOT NULL,
+  key             BYTES NOT NULL,
+  blocking_txn_id UUID NOT NULL,
+  waiting_txn_id  UUID,
+  time            TIMESTAMPTZ NOT NULL,
+  duration        INTERVAL NOT NULL,
+  source          STRING NOT NULL
+)`,
+	populate: func(ctx context.Context, p *planner, _ *DatabaseDescriptor, addRow func(...tree.Datum) error) error {
+		if err := p.RequireAdminRole(ctx, "read crdb_internal.cluster_contention_events"); err != nil {
+			return err
+		}
+		response, err := p.extendedEvalCtx.StatusServer.ContentionEvents(
+			ctx, &serverpb.ContentionEventsRequest{},
+		)
+		if err != nil {
+			return err
+		}
+		for _, e := range response.Events {
+			waitingTxnID := tree.DNull
+			if e.WaitingTxnID != (uuid.UUID{}) {
+				waitingTxnID = tree.NewDUuid(tree.DUuid{UUID: e.WaitingTxnID})
+			}
+			if err := addRow(
+				tree.NewDInt(tree.DInt(e.NodeID)),
+				tree.NewDBytes(tree.DBytes(e.Key)),
+				tree.NewDUuid(tree.DUuid{UUID: e.BlockingTxnID}),
+				waitingTxnID,
+				tree.MakeDTimestampTZ(e.Time, time.Microsecond),
+				&tree.DInterval{Duration: duration.MakeDuration(e.Duration.Nanoseconds(), 0, 0)},
+				tree.NewDString(e.Source),
+			); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
+
 func populateQueriesTable(
 	ctx context.Context, addRow func(...tree.Datum) error, response *serverpb.ListSessionsResponse,
 ) error {
@@ -3058,3 +3317,58 @@ CREATE TABLE crdb_internal.predefined_comments (
 		return nil
 	},
 }
+
+// crdbInternalProtectedSpansTable exposes the protected timestamp records,
+// with one row per protected span.
+var crdbInternalProtectedSpansTable = virtualSchemaTable{
+	comment: `protected timestamp records and the spans they protect (KV scan)`,
+	schema: `
+CREATE TABLE crdb_internal.protected_spans (
+  id           UUID NOT NULL,
+  ts           DECIMAL NOT NULL,
+  meta_type    STRING NOT NULL,
+  meta         BYTES,
+  verified     BOOL NOT NULL,
+  start_key    BYTES NOT NULL,
+  start_pretty STRING NOT NULL,
+  end_key      BYTES NOT NULL,
+  end_pretty   STRING NOT NULL
+)`,
+	populate: func(ctx context.Context, p *planner, _ *DatabaseDescriptor, addRow func(...tree.Datum) error) error {
+		if err := p.RequireAdminRole(ctx, "read crdb_internal.protected_spans"); err != nil {
+			return err
+		}
+		pts := p.ExecCfg().ProtectedTimestampProvider
+		if pts == nil ||
+			!cluster.Version.IsActive(ctx, p.ExecCfg().Settings, cluster.VersionProtectedTimestamps) {
+			return nil
+		}
+		state, err := pts.GetState(ctx, p.txn)
+		if err != nil {
+			return err
+		}
+		for i := range state.Records {
+			r := &state.Records[i]
+			meta := tree.DNull
+			if r.Meta != nil {
+				meta = tree.NewDBytes(tree.DBytes(r.Meta))
+			}
+			for _, sp := range r.Spans {
+				if err := addRow(
+					tree.NewDUuid(tree.DUuid{UUID: r.ID}),
+					tree.TimestampToDecimal(r.Timestamp),
+					tree.NewDString(r.MetaType),
+					meta,
+					tree.MakeDBool(tree.DBool(r.Verified)),
+					tree.NewDBytes(tree.DBytes(sp.Key)),
+					tree.NewDString(keys.PrettyPrint(nil /* valDirs */, sp.Key)),
+					tree.NewDBytes(tree.DBytes(sp.EndKey)),
+					tree.NewDString(keys.PrettyPrint(nil /* valDirs */, sp.EndKey)),
+				); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	},
+}
@@ -0,0 +1,30 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package delegate
+
+import (
+	"fmt"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+)
+
+func (d *delegator) delegateShowSchedules(n *tree.ShowSchedules) (tree.Statement, error) {
+	const selectClause = `SELECT schedule_id AS id, schedule_name AS label,
+				       IF(next_run IS NULL, 'PAUSED', 'ACTIVE') AS schedule_status,
+				       next_run, schedule_status AS state, schedule_expr AS recurrence,
+				       executor_type, last_job_id, owner, created
+				FROM system.scheduled_jobs`
+	var whereClause string
+	if n.ScheduleID != nil {
+		whereClause = fmt.Sprintf(`WHERE schedule_id = (%s)`, tree.AsString(n.ScheduleID))
+	}
+	return parse(fmt.Sprintf("%s %s ORDER BY schedule_id", selectClause, whereClause))
+}
@@ -172,6 +172,9 @@ func TestHashJoinerAgainstProcessor(t *testing.T) {
 		{
 			joinType: sqlbase.JoinType_LEFT_SEMI,
 		},
+		{
+			joinType: sqlbase.JoinType_LEFT_ANTI,
+		},
 	}
 
 	seed := rand.Int()
@@ -219,7 +222,8 @@ func TestHashJoinerAgainstProcessor(t *testing.T) {
 							}
 
 							outputTypes := append(inputTypes, inputTypes...)
-							if testSpec.joinType == sqlbase.JoinType_LEFT_SEMI {
+							if testSpec.joinType == sqlbase.JoinType_LEFT_SEMI ||
+								testSpec.joinType == sqlbase.JoinType_LEFT_ANTI {
 								outputTypes = inputTypes
 							}
 							outputColumns := make([]uint32, len(outputTypes))
@@ -63,10 +63,11 @@ var _ execinfrapb.DistSQLServer = &ServerImpl{}
 // NewServer instantiates a DistSQLServer.
 func NewServer(ctx context.Context, cfg execinfra.ServerConfig) *ServerImpl {
 	ds := &ServerImpl{
-		ServerConfig:  cfg,
-		regexpCache:   tree.NewRegexpCache(512),
-		flowRegistry:  flowinfra.NewFlowRegistry(cfg.NodeID.Get()),
-		flowScheduler: flowinfra.NewFlowScheduler(cfg.AmbientContext, cfg.Stopper, cfg.Settings, cfg.Metrics),
+		ServerConfig: cfg,
+		regexpCache:  tree.NewRegexpCache(512),
+		flowRegistry: flowinfra.NewFlowRegistry(cfg.NodeID.Get()),
+		flowScheduler: flowinfra.NewFlowScheduler(
+			cfg.AmbientContext, cfg.Stopper, cfg.Settings, cfg.Metrics, cfg.AdmissionQ),
 		memMonitor: mon.MakeMonitor(
 			"distsql",
 			mon.MemoryResource,
@@ -519,6 +519,11 @@ type PlanningCtx struct {
 	// noEvalSubqueries indicates that the plan expects any subqueries to not
 	// be replaced by evaluation. Should only be set by EXPLAIN.
 	noEvalSubqueries bool
+
+	// If saveDiagram is set, the physical plan diagram is generated and passed
+	// to it before the plan is run. Used when collecting statement diagnostics
+	// bundles.
+	saveDiagram func(execinfrapb.FlowDiagram)
 }
 
 var _ physicalplan.ExprContext = &PlanningCtx{}
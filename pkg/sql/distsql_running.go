@@ -335,6 +335,19 @@ func (dsp *DistSQLPlanner) Run(
 		}
 	}
 
+	if planCtx.saveDiagram != nil {
+		var stmtStr string
+		if planCtx.planner != nil {
+			stmtStr = planCtx.planner.stmt.String()
+		}
+		diagram, err := execinfrapb.GeneratePlanDiagram(stmtStr, flows, false /* showInputTypes */)
+		if err != nil {
+			log.Infof(ctx, "Error generating diagram: %s", err)
+		} else {
+			planCtx.saveDiagram(diagram)
+		}
+	}
+
 	log.VEvent(ctx, 1, "running DistSQL plan")
 
 	dsp.distSQLSrv.ServerConfig.Metrics.QueryStart()
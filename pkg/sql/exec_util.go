@@ -44,13 +44,16 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/planpin"
 	"github.com/cockroachdb/cockroach/pkg/sql/querycache"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlutil"
 	"github.com/cockroachdb/cockroach/pkg/sql/stats"
+	"github.com/cockroachdb/cockroach/pkg/sql/stmtdiagnostics"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/storage/protectedts"
 	"github.com/cockroachdb/cockroach/pkg/util/bitarray"
 	"github.com/cockroachdb/cockroach/pkg/util/duration"
 	"github.com/cockroachdb/cockroach/pkg/util/errorutil/unimplemented"
@@ -525,6 +528,16 @@ type ExecutorConfig struct {
 	InternalExecutor  *InternalExecutor
 	QueryCache        *querycache.C
 
+	// StmtDiagnosticsRecorder deals with recording statement diagnostics.
+	StmtDiagnosticsRecorder *stmtdiagnostics.Registry
+
+	// PlanPinRegistry holds the plans pinned with PIN PLAN.
+	PlanPinRegistry *planpin.Registry
+
+	// ProtectedTimestampProvider is used by jobs to prevent the data they need
+	// from being garbage collected.
+	ProtectedTimestampProvider protectedts.Provider
+
 	TestingKnobs              ExecutorTestingKnobs
 	PGWireTestingKnobs        *PGWireTestingKnobs
 	SchemaChangerTestingKnobs *SchemaChangerTestingKnobs
@@ -818,15 +831,29 @@ func checkResultType(typ *types.T) error {
 // EvalAsOfTimestamp evaluates and returns the timestamp from an AS OF SYSTEM
 // TIME clause.
 func (p *planner) EvalAsOfTimestamp(asOf tree.AsOfClause) (_ hlc.Timestamp, err error) {
-	ts, err := tree.EvalAsOfTimestamp(asOf, &p.semaCtx, p.EvalContext())
+	asOfTime, err := p.evalAsOf(asOf)
 	if err != nil {
 		return hlc.Timestamp{}, err
 	}
-	if now := p.execCfg.Clock.Now(); now.Less(ts) {
-		return hlc.Timestamp{}, errors.Errorf(
-			"AS OF SYSTEM TIME: cannot specify timestamp in the future (%s > %s)", ts, now)
+	if asOfTime.BoundedStaleness {
+		return hlc.Timestamp{}, tree.ErrBoundedStalenessNotSupported
+	}
+	return asOfTime.Timestamp, nil
+}
+
+// evalAsOf evaluates an AS OF SYSTEM TIME clause, which may request a bounded
+// staleness read.
+func (p *planner) evalAsOf(asOf tree.AsOfClause) (tree.AsOfSystemTime, error) {
+	asOfTime, err := tree.EvalAsOf(asOf, &p.semaCtx, p.EvalContext())
+	if err != nil {
+		return tree.AsOfSystemTime{}, err
+	}
+	if now := p.execCfg.Clock.Now(); now.Less(asOfTime.Timestamp) {
+		return tree.AsOfSystemTime{}, errors.Errorf(
+			"AS OF SYSTEM TIME: cannot specify timestamp in the future (%s > %s)",
+			asOfTime.Timestamp, now)
 	}
-	return ts, nil
+	return asOfTime, nil
 }
 
 // ParseHLC parses a string representation of an `hlc.Timestamp`.
@@ -840,11 +867,13 @@ func ParseHLC(s string) (hlc.Timestamp, error) {
 
 // isAsOf analyzes a statement to bypass the logic in newPlan(), since
 // that requires the transaction to be started already. If the returned
-// timestamp is not nil, it is the timestamp to which a transaction
+// value is not nil, its timestamp is the timestamp to which a transaction
 // should be set. The statements that will be checked are Select,
-// ShowTrace (of a Select statement), Scrub, Export, and CreateStats.
-func (p *planner) isAsOf(stmt tree.Statement) (*hlc.Timestamp, error) {
+// ShowTrace (of a Select statement), Scrub, Export, and CreateStats. Only
+// Select statements may request a bounded staleness read.
+func (p *planner) isAsOf(stmt tree.Statement) (*tree.AsOfSystemTime, error) {
 	var asOf tree.AsOfClause
+	allowBoundedStaleness := false
 	switch s := stmt.(type) {
 	case *tree.Select:
 		selStmt := s.Select
@@ -863,13 +892,18 @@ func (p *planner) isAsOf(stmt tree.Statement) (*hlc.Timestamp, error) {
 		}
 
 		asOf = sc.From.AsOf
+		allowBoundedStaleness = true
 	case *tree.Scrub:
 		if s.AsOf.Expr == nil {
 			return nil, nil
 		}
 		asOf = s.AsOf
 	case *tree.Export:
-		return p.isAsOf(s.Query)
+		asOfTime, err := p.isAsOf(s.Query)
+		if err == nil && asOfTime != nil && asOfTime.BoundedStaleness {
+			return nil, tree.ErrBoundedStalenessNotSupported
+		}
+		return asOfTime, err
 	case *tree.CreateStats:
 		if s.Options.AsOf.Expr == nil {
 			return nil, nil
@@ -878,8 +912,14 @@ func (p *planner) isAsOf(stmt tree.Statement) (*hlc.Timestamp, error) {
 	default:
 		return nil, nil
 	}
-	ts, err := p.EvalAsOfTimestamp(asOf)
-	return &ts, err
+	asOfTime, err := p.evalAsOf(asOf)
+	if err != nil {
+		return nil, err
+	}
+	if asOfTime.BoundedStaleness && !allowBoundedStaleness {
+		return nil, tree.ErrBoundedStalenessNotSupported
+	}
+	return &asOfTime, nil
 }
 
 // isSavepoint returns true if stmt is a SAVEPOINT statement.
@@ -1909,8 +1949,9 @@ func newSQLStatsCollector(
 	}
 }
 
-// recordStatement records stats for one statement. samplePlanDescription can
-// be nil, as these are only sampled periodically per unique fingerprint.
+// recordStatement records stats for one statement and returns the key of its
+// fingerprint. samplePlanDescription can be nil, as these are only sampled
+// periodically per unique fingerprint.
 func (s *sqlStatsCollector) recordStatement(
 	stmt *Statement,
 	samplePlanDescription *roachpb.ExplainTreePlanNode,
@@ -1922,15 +1963,24 @@ func (s *sqlStatsCollector) recordStatement(
 	err error,
 	parseLat, planLat, runLat, svcLat, ovhLat float64,
 	bytesRead, rowsRead int64,
-) {
-	s.appStats.recordStatement(
+) stmtKey {
+	return s.appStats.recordStatement(
 		stmt, samplePlanDescription, distSQLUsed, optUsed, implicitTxn, automaticRetryCount, numRows, err,
 		parseLat, planLat, runLat, svcLat, ovhLat, bytesRead, rowsRead)
 }
 
 // recordTransaction records stats for one transaction.
-func (s *sqlStatsCollector) recordTransaction(txnTimeSec float64, ev txnEvent, implicit bool) {
-	s.appStats.recordTransaction(txnTimeSec, ev, implicit)
+func (s *sqlStatsCollector) recordTransaction(
+	txnTimeSec float64,
+	ev txnEvent,
+	implicit bool,
+	stmtFingerprintIDs []int64,
+	automaticRetryCount int,
+	contentionTime time.Duration,
+) {
+	s.appStats.recordTransaction(
+		txnTimeSec, ev, implicit, stmtFingerprintIDs, automaticRetryCount, contentionTime,
+	)
 }
 
 func (s *sqlStatsCollector) reset(sqlStats *sqlStats, appStats *appStats, phaseTimes *phaseTimes) {
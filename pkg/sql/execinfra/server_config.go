@@ -26,7 +26,9 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlutil"
 	"github.com/cockroachdb/cockroach/pkg/storage/cloud"
 	"github.com/cockroachdb/cockroach/pkg/storage/diskmap"
+	"github.com/cockroachdb/cockroach/pkg/storage/protectedts"
 	"github.com/cockroachdb/cockroach/pkg/storage/storagebase"
+	"github.com/cockroachdb/cockroach/pkg/util/admission"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/mon"
 	"github.com/cockroachdb/cockroach/pkg/util/stop"
@@ -134,6 +136,10 @@ type ServerConfig struct {
 
 	Metrics *DistSQLMetrics
 
+	// AdmissionQ, if set, is the admission queue flows wait in before being
+	// scheduled while the node is overloaded.
+	AdmissionQ *admission.WorkQueue
+
 	// NodeID is the id of the node on which this Server is running.
 	NodeID      *base.NodeIDContainer
 	ClusterID   *base.ClusterIDContainer
@@ -159,6 +165,10 @@ type ServerConfig struct {
 
 	ExternalStorage        cloud.ExternalStorageFactory
 	ExternalStorageFromURI cloud.ExternalStorageFromURIFactory
+
+	// ProtectedTimestampProvider is used by processors which need to prevent
+	// the data they read from being garbage collected.
+	ProtectedTimestampProvider protectedts.Provider
 }
 
 // RuntimeStats is an interface through which the rowexec layer can get
@@ -167,12 +167,17 @@ func (ex *connExecutor) recordStatementSummary(
 		m.SQLServiceLatency.RecordValue(svcLatRaw.Nanoseconds())
 	}
 
-	ex.statsCollector.recordStatement(
+	key := ex.statsCollector.recordStatement(
 		stmt, planner.curPlan.savedPlanForStats,
 		flags.IsSet(planFlagDistributed), flags.IsSet(planFlagOptUsed), flags.IsSet(planFlagImplicitTxn),
 		automaticRetryCount, rowsAffected, err,
 		parseLat, planLat, runLat, svcLat, execOverhead, bytesRead, rowsRead,
 	)
+	if key.stmt != "" && len(ex.extraTxnState.stmtFingerprintIDs) < maxStmtFingerprintIDsPerTxn {
+		ex.extraTxnState.stmtFingerprintIDs = append(
+			ex.extraTxnState.stmtFingerprintIDs, key.fingerprintID(ex.sessionData.ApplicationName),
+		)
+	}
 
 	if log.V(2) {
 		// ages since significant epochs
@@ -0,0 +1,367 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	gojson "encoding/json"
+	"fmt"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/execinfrapb"
+	"github.com/cockroachdb/cockroach/pkg/sql/lex"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/cat"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/memo"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/stmtdiagnostics"
+	"github.com/cockroachdb/cockroach/pkg/util/json"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/tracing"
+	"github.com/cockroachdb/errors"
+)
+
+// bundlePlanInfo holds the information gathered during planning that goes
+// into a statement diagnostics bundle. It is collected eagerly because the
+// memo and the catalog are not usable once the statement has finished
+// executing.
+type bundlePlanInfo struct {
+	// optPlan, optPlanVerbose and optPlanTypes are the optimizer memo
+	// formatted like EXPLAIN (OPT), EXPLAIN (OPT, VERBOSE) and
+	// EXPLAIN (OPT, TYPES) would.
+	optPlan        string
+	optPlanVerbose string
+	optPlanTypes   string
+
+	// planString is the textual representation of the planNode tree.
+	planString string
+
+	// distSQLDiagrams contains the physical plan diagrams of the flows that
+	// were run for the statement.
+	distSQLDiagrams []execinfrapb.FlowDiagram
+
+	// Fully qualified names of the catalog objects referenced by the
+	// statement.
+	tables    []tree.TableName
+	sequences []tree.TableName
+	views     []tree.TableName
+}
+
+// collectBundlePlanInfo gathers the planning information of the given plan.
+func collectBundlePlanInfo(
+	ctx context.Context, plan *planTop, mem *memo.Memo, catalog *optCatalog,
+) *bundlePlanInfo {
+	info := &bundlePlanInfo{}
+
+	formatMemo := func(flags memo.ExprFmtFlags) string {
+		f := memo.MakeExprFmtCtx(flags, mem, catalog)
+		f.FormatExpr(mem.RootExpr())
+		return f.Buffer.String()
+	}
+	info.optPlan = formatMemo(memo.ExprFmtHideAll)
+	info.optPlanVerbose = formatMemo(
+		memo.ExprFmtHideQualifications | memo.ExprFmtHideScalars | memo.ExprFmtHideTypes,
+	)
+	info.optPlanTypes = formatMemo(memo.ExprFmtHideQualifications)
+	info.planString = planToString(ctx, plan.plan, plan.subqueryPlans, plan.postqueryPlans)
+
+	// Catalog objects can show up multiple times in the metadata, so
+	// deduplicate them.
+	seen := make(map[tree.TableName]bool)
+	addDS := func(list []tree.TableName, ds cat.DataSource) []tree.TableName {
+		tn, err := catalog.FullyQualifiedName(ctx, ds)
+		if err != nil {
+			log.VEventf(ctx, 1, "could not resolve name of %s: %v", ds.Name(), err)
+			return list
+		}
+		if !seen[tn] {
+			seen[tn] = true
+			list = append(list, tn)
+		}
+		return list
+	}
+	md := mem.Metadata()
+	for _, t := range md.AllTables() {
+		info.tables = addDS(info.tables, t.Table)
+	}
+	for _, s := range md.AllSequences() {
+		info.sequences = addDS(info.sequences, s)
+	}
+	for _, v := range md.AllViews() {
+		info.views = addDS(info.views, v)
+	}
+	return info
+}
+
+// diagnosticsBundle is a zip file containing the information collected for a
+// statement, along with the trace of its execution.
+type diagnosticsBundle struct {
+	zip       []byte
+	traceJSON tree.Datum
+
+	// collectionErr is set if the bundle could not be (fully) collected.
+	collectionErr error
+}
+
+// buildStatementBundle collects the information about the execution of the
+// given statement into a diagnostics bundle: a zip file containing the
+// statement (statement.txt), the optimizer plan at various levels of detail
+// (opt.txt, opt-v.txt, opt-vv.txt), the planNode tree (plan.txt), the physical
+// plan diagrams (distsql.json, distsql.url.txt), the trace of the execution
+// (trace.txt, trace.json), the SHOW CREATE output for all referenced objects
+// (schema.sql), the table statistics as INJECT STATISTICS statements
+// (stats-<table>.sql) and the version and session settings (env.sql).
+func buildStatementBundle(
+	ctx context.Context,
+	ie *InternalExecutor,
+	p *planner,
+	ast tree.Statement,
+	trace tracing.Recording,
+) diagnosticsBundle {
+	b := makeStmtBundleBuilder(ctx, ie, p, ast)
+
+	b.addStatement()
+	b.addOptPlans()
+	b.addDistSQLDiagrams()
+	b.addTrace(trace)
+	b.addEnv()
+
+	var bundle diagnosticsBundle
+	bundle.zip, bundle.collectionErr = b.finalize()
+	var err error
+	bundle.traceJSON, err = traceToJSON(trace)
+	if err != nil && bundle.collectionErr == nil {
+		bundle.collectionErr = err
+	}
+	return bundle
+}
+
+// insert records the bundle in the statement diagnostics system tables.
+func (b *diagnosticsBundle) insert(
+	ctx context.Context,
+	fingerprint string,
+	ast tree.Statement,
+	registry *stmtdiagnostics.Registry,
+	requestID stmtdiagnostics.RequestID,
+) (stmtdiagnostics.CollectedInstanceID, error) {
+	return registry.InsertStatementDiagnostics(
+		ctx, requestID, fingerprint, tree.AsString(ast), b.traceJSON, b.zip, b.collectionErr,
+	)
+}
+
+// traceToJSON converts a trace to a JSON datum suitable for the trace column
+// of system.statement_diagnostics.
+func traceToJSON(trace tracing.Recording) (tree.Datum, error) {
+	encoded, err := gojson.Marshal(trace)
+	if err != nil {
+		return nil, err
+	}
+	j, err := json.ParseJSON(string(encoded))
+	if err != nil {
+		return nil, err
+	}
+	return tree.NewDJSON(j), nil
+}
+
+// stmtBundleBuilder is a helper for building a statement bundle.
+type stmtBundleBuilder struct {
+	ctx  context.Context
+	ie   *InternalExecutor
+	p    *planner
+	ast  tree.Statement
+	plan *bundlePlanInfo
+
+	buf bytes.Buffer
+	z   *zip.Writer
+
+	// errs accumulates the errors encountered while building the bundle; these
+	// do not stop the collection of the rest of the bundle.
+	errs []error
+}
+
+func makeStmtBundleBuilder(
+	ctx context.Context, ie *InternalExecutor, p *planner, ast tree.Statement,
+) *stmtBundleBuilder {
+	b := &stmtBundleBuilder{ctx: ctx, ie: ie, p: p, ast: ast}
+	// The statement might have failed before being planned, in which case the
+	// planner still holds the plan of a previous statement.
+	if p.curPlan.AST == ast {
+		b.plan = p.curPlan.bundle
+	}
+	b.z = zip.NewWriter(&b.buf)
+	return b
+}
+
+func (b *stmtBundleBuilder) addFile(name string, contents string) {
+	w, err := b.z.Create(name)
+	if err == nil {
+		_, err = w.Write([]byte(contents))
+	}
+	if err != nil {
+		b.errs = append(b.errs, errors.Wrapf(err, "writing %s", name))
+	}
+}
+
+func (b *stmtBundleBuilder) addStatement() {
+	b.addFile("statement.txt", tree.AsString(b.ast))
+}
+
+func (b *stmtBundleBuilder) addOptPlans() {
+	if b.plan == nil {
+		// The statement was not planned, for example because planning failed.
+		return
+	}
+	b.addFile("opt.txt", b.plan.optPlan)
+	b.addFile("opt-v.txt", b.plan.optPlanVerbose)
+	b.addFile("opt-vv.txt", b.plan.optPlanTypes)
+	b.addFile("plan.txt", b.plan.planString)
+}
+
+func (b *stmtBundleBuilder) addDistSQLDiagrams() {
+	if b.plan == nil {
+		return
+	}
+	for i, d := range b.plan.distSQLDiagrams {
+		suffix := ""
+		if len(b.plan.distSQLDiagrams) > 1 {
+			suffix = fmt.Sprintf("-%d", i+1)
+		}
+		diagramJSON, url, err := d.ToURL()
+		if err != nil {
+			b.errs = append(b.errs, err)
+			continue
+		}
+		b.addFile(fmt.Sprintf("distsql%s.json", suffix), diagramJSON)
+		b.addFile(fmt.Sprintf("distsql%s.url.txt", suffix), url.String())
+	}
+}
+
+func (b *stmtBundleBuilder) addTrace(trace tracing.Recording) {
+	b.addFile("trace.txt", trace.String())
+	encoded, err := gojson.MarshalIndent(trace, "", "  ")
+	if err != nil {
+		b.errs = append(b.errs, err)
+		return
+	}
+	b.addFile("trace.json", string(encoded))
+}
+
+// addEnv adds the schema of the referenced objects, their statistics, the
+// version and the session settings.
+func (b *stmtBundleBuilder) addEnv() {
+	var env bytes.Buffer
+	if version, err := b.query("SELECT version()"); err != nil {
+		b.errs = append(b.errs, err)
+	} else {
+		fmt.Fprintf(&env, "-- Version: %s\n\n", version)
+	}
+	b.addSessionSettings(&env)
+	b.addFile("env.sql", env.String())
+
+	if b.plan == nil {
+		return
+	}
+	var schema bytes.Buffer
+	for _, tn := range b.plan.sequences {
+		b.addCreateStatement(&schema, "SEQUENCE", &tn)
+	}
+	for _, tn := range b.plan.tables {
+		b.addCreateStatement(&schema, "TABLE", &tn)
+	}
+	for _, tn := range b.plan.views {
+		b.addCreateStatement(&schema, "VIEW", &tn)
+	}
+	b.addFile("schema.sql", schema.String())
+
+	for _, tn := range b.plan.tables {
+		stats, err := b.query(fmt.Sprintf(
+			`SELECT jsonb_pretty(COALESCE(json_agg(stat), '[]'))
+			   FROM (SELECT json_array_elements(statistics) AS stat
+			           FROM [SHOW STATISTICS USING JSON FOR TABLE %s])`,
+			tn.String(),
+		))
+		if err != nil {
+			b.errs = append(b.errs, err)
+			continue
+		}
+		var buf bytes.Buffer
+		lex.EncodeSQLString(&buf, stats)
+		b.addFile(
+			fmt.Sprintf("stats-%s.sql", tn.String()),
+			fmt.Sprintf("ALTER TABLE %s INJECT STATISTICS %s;\n", tn.String(), buf.String()),
+		)
+	}
+}
+
+func (b *stmtBundleBuilder) addCreateStatement(
+	buf *bytes.Buffer, kind string, tn *tree.TableName,
+) {
+	createStatement, err := b.query(
+		fmt.Sprintf("SELECT create_statement FROM [SHOW CREATE %s %s]", kind, tn.String()),
+	)
+	if err != nil {
+		b.errs = append(b.errs, err)
+		return
+	}
+	fmt.Fprintf(buf, "%s;\n\n", createStatement)
+}
+
+// addSessionSettings writes the values of the session variables as SET
+// statements; variables that cannot be set are written as comments.
+func (b *stmtBundleBuilder) addSessionSettings(buf *bytes.Buffer) {
+	for _, name := range varNames {
+		gen := varGen[name]
+		if gen.Hidden {
+			continue
+		}
+		value := gen.Get(&b.p.extendedEvalCtx)
+		if gen.Set == nil && gen.RuntimeSet == nil {
+			fmt.Fprintf(buf, "-- %s = %s\n", name, value)
+			continue
+		}
+		fmt.Fprintf(buf, "SET %s = ", name)
+		lex.EncodeSQLString(buf, value)
+		buf.WriteString(";\n")
+	}
+}
+
+// query runs a query that returns a single string value.
+func (b *stmtBundleBuilder) query(query string) (string, error) {
+	row, err := b.ie.QueryRow(b.ctx, "stmt-bundle", nil /* txn */, query)
+	if err != nil {
+		return "", err
+	}
+	if len(row) != 1 {
+		return "", errors.AssertionFailedf(
+			"expected env query %q to return a single column, returned %d", query, len(row),
+		)
+	}
+	s, ok := row[0].(*tree.DString)
+	if !ok {
+		return "", errors.AssertionFailedf(
+			"expected env query %q to return a DString, returned %T", query, row[0],
+		)
+	}
+	return string(*s), nil
+}
+
+// finalize closes the zip file and returns its contents, along with the
+// errors encountered while building it.
+func (b *stmtBundleBuilder) finalize() ([]byte, error) {
+	if err := b.z.Close(); err != nil {
+		return nil, err
+	}
+	var err error
+	for _, e := range b.errs {
+		err = errors.CombineErrors(err, e)
+	}
+	return b.buf.Bytes(), err
+}
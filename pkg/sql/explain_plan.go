@@ -18,6 +18,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/colflow"
 	"github.com/cockroachdb/cockroach/pkg/sql/flowinfra"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/xform"
 	"github.com/cockroachdb/cockroach/pkg/sql/rowexec"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
@@ -244,7 +245,9 @@ func (p *planner) populateExplain(
 		}
 	}
 
-	if err := appendExecutionDetails(ctx, v, e.showMetadata, isDistSQL, isVec); err != nil {
+	if err := appendExecutionDetails(
+		ctx, v, e.showMetadata, isDistSQL, isVec, params.p.curPlan.pinnedPlan,
+	); err != nil {
 		return err
 	}
 
@@ -297,7 +300,10 @@ func (p *planner) populateExplain(
 }
 
 func appendExecutionDetails(
-	ctx context.Context, v *valuesNode, showMetadata, isDistSQL, isVec bool,
+	ctx context.Context,
+	v *valuesNode,
+	showMetadata, isDistSQL, isVec bool,
+	pinnedPlan *xform.PlanOutline,
 ) error {
 	var distSQLRow, vecRow tree.Datums
 	distSQLFieldName := tree.NewDString("distributed")
@@ -341,7 +347,30 @@ func appendExecutionDetails(
 	if _, err := v.rows.AddRow(ctx, vecRow); err != nil {
 		return err
 	}
-	return nil
+	if pinnedPlan == nil {
+		return nil
+	}
+	// The plan follows an outline pinned with PIN PLAN.
+	pinFieldName := tree.NewDString("pinned plan")
+	pinValue := tree.NewDString(pinnedPlan.String())
+	pinRow := tree.Datums{
+		emptyString,  // Tree
+		pinFieldName, // Field
+		pinValue,     // Description
+	}
+	if showMetadata {
+		pinRow = tree.Datums{
+			emptyString,     // Tree
+			tree.NewDInt(0), // Level
+			emptyString,     // Type
+			pinFieldName,    // Field
+			pinValue,        // Description
+			emptyString,     // Columns
+			emptyString,     // Ordering
+		}
+	}
+	_, err := v.rows.AddRow(ctx, pinRow)
+	return err
 }
 
 func (e *explainer) populateEntries(
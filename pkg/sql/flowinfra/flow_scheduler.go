@@ -18,6 +18,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql/execinfra"
+	"github.com/cockroachdb/cockroach/pkg/util/admission"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/stop"
 	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
@@ -40,6 +41,9 @@ type FlowScheduler struct {
 	stopper    *stop.Stopper
 	flowDoneCh chan Flow
 	metrics    *execinfra.DistSQLMetrics
+	// admissionQ, if set, is the admission queue flows wait in before being
+	// scheduled while the node is overloaded.
+	admissionQ *admission.WorkQueue
 
 	mu struct {
 		syncutil.Mutex
@@ -56,6 +60,9 @@ type flowWithCtx struct {
 	ctx         context.Context
 	flow        Flow
 	enqueueTime time.Time
+	// admitted is set if the flow holds an admission slot, which has to be
+	// released once it is done.
+	admitted bool
 }
 
 // NewFlowScheduler creates a new FlowScheduler.
@@ -64,12 +71,14 @@ func NewFlowScheduler(
 	stopper *stop.Stopper,
 	settings *cluster.Settings,
 	metrics *execinfra.DistSQLMetrics,
+	admissionQ *admission.WorkQueue,
 ) *FlowScheduler {
 	fs := &FlowScheduler{
 		AmbientContext: ambient,
 		stopper:        stopper,
 		flowDoneCh:     make(chan Flow, flowDoneChanSize),
 		metrics:        metrics,
+		admissionQ:     admissionQ,
 	}
 	fs.mu.queue = list.New()
 	fs.mu.maxRunningFlows = int(settingMaxRunningFlows.Get(&settings.SV))
@@ -88,37 +97,68 @@ func (fs *FlowScheduler) canRunFlow(_ Flow) bool {
 }
 
 // runFlowNow starts the given flow; does not wait for the flow to complete.
-func (fs *FlowScheduler) runFlowNow(ctx context.Context, f Flow) error {
+// If admitted is set, the admission slot held by the flow is released once it
+// is done.
+func (fs *FlowScheduler) runFlowNow(ctx context.Context, f Flow, admitted bool) error {
 	log.VEventf(
 		ctx, 1, "flow scheduler running flow %s, currently running %d", f.GetID(), fs.mu.numRunning,
 	)
 	fs.mu.numRunning++
 	fs.metrics.FlowStart()
 	if err := f.Start(ctx, func() { fs.flowDoneCh <- f }); err != nil {
+		if admitted {
+			fs.admissionQ.AdmittedWorkDone()
+		}
 		return err
 	}
 	// TODO(radu): we could replace the WaitGroup with a structure that keeps a
 	// refcount and automatically runs Cleanup() when the count reaches 0.
 	go func() {
 		f.Wait()
+		if admitted {
+			fs.admissionQ.AdmittedWorkDone()
+		}
 		f.Cleanup(ctx)
 	}()
 	return nil
 }
 
+// admit waits for the flow to be admitted by the admission queue. It returns
+// whether the flow holds an admission slot.
+func (fs *FlowScheduler) admit(ctx context.Context, f Flow) (admitted bool, _ error) {
+	if fs.admissionQ == nil {
+		return false, nil
+	}
+	info := admission.WorkInfo{Priority: admission.NormalPri, CreateTime: timeutil.Now().UnixNano()}
+	if txn := f.GetFlowCtx().Txn; txn != nil {
+		info.Priority = admission.UserPriorityToWorkPriority(txn.UserPriority())
+		if minTS := txn.Serialize().MinTimestamp; minTS.WallTime != 0 {
+			info.CreateTime = minTS.WallTime
+		}
+	}
+	return fs.admissionQ.Admit(ctx, info)
+}
+
 // ScheduleFlow is the main interface of the flow scheduler: it runs or enqueues
 // the given flow.
 //
 // If the flow can start immediately, errors encountered when starting the flow
 // are returned. If the flow is enqueued, these error will be later ignored.
 func (fs *FlowScheduler) ScheduleFlow(ctx context.Context, f Flow) error {
-	return fs.stopper.RunTaskWithErr(
+	// Admission is requested before the flow counts against the limit of
+	// running flows, so that flows waiting for admission don't prevent
+	// admitted ones from running.
+	admitted, err := fs.admit(ctx, f)
+	if err != nil {
+		return err
+	}
+	err = fs.stopper.RunTaskWithErr(
 		ctx, "flowinfra.FlowScheduler: scheduling flow", func(ctx context.Context) error {
 			fs.mu.Lock()
 			defer fs.mu.Unlock()
 
 			if fs.canRunFlow(f) {
-				return fs.runFlowNow(ctx, f)
+				return fs.runFlowNow(ctx, f, admitted)
 			}
 			log.VEventf(ctx, 1, "flow scheduler enqueuing flow %s to be run later", f.GetID())
 			fs.metrics.FlowsQueued.Inc(1)
@@ -126,10 +166,15 @@ func (fs *FlowScheduler) ScheduleFlow(ctx context.Context, f Flow) error {
 				ctx:         ctx,
 				flow:        f,
 				enqueueTime: timeutil.Now(),
+				admitted:    admitted,
 			})
 			return nil
 
 		})
+	if err == stop.ErrUnavailable && admitted {
+		fs.admissionQ.AdmittedWorkDone()
+	}
+	return err
 }
 
 // Start launches the main loop of the scheduler.
@@ -164,7 +209,7 @@ func (fs *FlowScheduler) Start() {
 						// Note: we use the flow's context instead of the worker
 						// context, to ensure that logging etc is relative to the
 						// specific flow.
-						if err := fs.runFlowNow(n.ctx, n.flow); err != nil {
+						if err := fs.runFlowNow(n.ctx, n.flow, n.admitted); err != nil {
 							log.Errorf(n.ctx, "error starting queued flow: %s", err)
 						}
 					}
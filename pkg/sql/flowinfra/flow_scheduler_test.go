@@ -0,0 +1,134 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package flowinfra
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/execinfra"
+	"github.com/cockroachdb/cockroach/pkg/sql/execinfrapb"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/admission"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/tracing"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+// admissionTestFlow is a Flow that runs until finish is called. Only the
+// methods used by the FlowScheduler are implemented.
+type admissionTestFlow struct {
+	Flow
+	id      execinfrapb.FlowID
+	flowCtx execinfra.FlowCtx
+
+	mu struct {
+		syncutil.Mutex
+		started bool
+		doneFn  func()
+	}
+	doneCh chan struct{}
+}
+
+func newAdmissionTestFlow() *admissionTestFlow {
+	return &admissionTestFlow{
+		id:     execinfrapb.FlowID{UUID: uuid.MakeV4()},
+		doneCh: make(chan struct{}),
+	}
+}
+
+func (f *admissionTestFlow) Start(_ context.Context, doneFn func()) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.mu.started = true
+	f.mu.doneFn = doneFn
+	return nil
+}
+
+func (f *admissionTestFlow) started() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.mu.started
+}
+
+func (f *admissionTestFlow) finish() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	close(f.doneCh)
+	f.mu.doneFn()
+}
+
+func (f *admissionTestFlow) Wait()                          { <-f.doneCh }
+func (f *admissionTestFlow) Cleanup(context.Context)        {}
+func (f *admissionTestFlow) GetFlowCtx() *execinfra.FlowCtx { return &f.flowCtx }
+func (f *admissionTestFlow) GetID() execinfrapb.FlowID      { return f.id }
+
+// TestFlowSchedulerAdmission verifies that scheduled flows wait for admission
+// while the node is overloaded, and that they hold their admission slot until
+// they are done.
+func TestFlowSchedulerAdmission(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ctx := context.Background()
+	stopper := stop.NewStopper()
+	defer stopper.Stop(ctx)
+
+	st := cluster.MakeTestingClusterSettings()
+	admission.SQLFlowAdmissionControlEnabled.Override(&st.SV, true)
+	q := admission.NewController(st, time.Minute, nil /* l0FileCount */).FlowQueue()
+	q.SetTotalSlotsForTesting(1)
+	metrics := execinfra.MakeDistSQLMetrics(time.Minute)
+	fs := NewFlowScheduler(
+		log.AmbientContext{Tracer: tracing.NewTracer()}, stopper, st, &metrics, q,
+	)
+	fs.Start()
+
+	f1 := newAdmissionTestFlow()
+	require.NoError(t, fs.ScheduleFlow(ctx, f1))
+	require.True(t, f1.started())
+
+	// The second flow waits for the slot of the first one.
+	f2 := newAdmissionTestFlow()
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- fs.ScheduleFlow(ctx, f2)
+	}()
+	testutils.SucceedsSoon(t, func() error {
+		if n := q.Metrics().WaitQueueLength.Value(); n != 1 {
+			return errors.Errorf("expected 1 waiting flow, found %d", n)
+		}
+		return nil
+	})
+	require.False(t, f2.started())
+
+	f1.finish()
+	require.NoError(t, <-errCh)
+	require.True(t, f2.started())
+	f2.finish()
+
+	// Once the second flow is cleaned up, its slot is available again.
+	testutils.SucceedsSoon(t, func() error {
+		enabled, admitted := q.TryAdmit(admission.WorkInfo{})
+		require.True(t, enabled)
+		if !admitted {
+			return errors.New("slot still in use")
+		}
+		q.AdmittedWorkDone()
+		return nil
+	})
+}
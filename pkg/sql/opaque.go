@@ -63,6 +63,8 @@ func buildOpaque(
 		plan, err = p.CommentOnIndex(ctx, n)
 	case *tree.CommentOnTable:
 		plan, err = p.CommentOnTable(ctx, n)
+	case *tree.ControlSchedules:
+		plan, err = p.ControlSchedules(ctx, n)
 	case *tree.CreateDatabase:
 		plan, err = p.CreateDatabase(ctx, n)
 	case *tree.CreateIndex:
@@ -91,6 +93,8 @@ func buildOpaque(
 		plan, err = p.DropUser(ctx, n)
 	case *tree.Grant:
 		plan, err = p.Grant(ctx, n)
+	case *tree.PinPlan:
+		plan, err = p.PinPlan(ctx, n)
 	case *tree.RenameColumn:
 		plan, err = p.RenameColumn(ctx, n)
 	case *tree.RenameDatabase:
@@ -131,6 +135,8 @@ func buildOpaque(
 		plan, err = p.ShowFingerprints(ctx, n)
 	case *tree.Truncate:
 		plan, err = p.Truncate(ctx, n)
+	case *tree.UnpinPlan:
+		plan, err = p.UnpinPlan(ctx, n)
 	case tree.CCLOnlyStatement:
 		plan, err = p.maybePlanHook(ctx, stmt)
 		if plan == nil && err == nil {
@@ -160,6 +166,7 @@ func init() {
 		&tree.CommentOnDatabase{},
 		&tree.CommentOnIndex{},
 		&tree.CommentOnTable{},
+		&tree.ControlSchedules{},
 		&tree.CreateDatabase{},
 		&tree.CreateIndex{},
 		&tree.CreateUser{},
@@ -174,6 +181,7 @@ func init() {
 		&tree.DropSequence{},
 		&tree.DropUser{},
 		&tree.Grant{},
+		&tree.PinPlan{},
 		&tree.RenameColumn{},
 		&tree.RenameDatabase{},
 		&tree.RenameIndex{},
@@ -194,11 +202,13 @@ func init() {
 		&tree.ShowZoneConfig{},
 		&tree.ShowFingerprints{},
 		&tree.Truncate{},
+		&tree.UnpinPlan{},
 
 		// CCL statements (without Export which has an optimizer operator).
 		&tree.Backup{},
 		&tree.ShowBackup{},
 		&tree.Restore{},
+		&tree.ScheduledBackup{},
 		&tree.CreateChangefeed{},
 		&tree.CreateRole{},
 		&tree.DropRole{},
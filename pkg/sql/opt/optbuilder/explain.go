@@ -70,6 +70,15 @@ func (b *Builder) buildExplain(explain *tree.Explain, inScope *scope) (outScope
 		telemetry.Inc(sqltelemetry.ExplainVecUseCounter)
 		cols = sqlbase.ExplainVecColumns
 
+	case tree.ExplainDebug:
+		if !opts.Flags.Contains(tree.ExplainFlagAnalyze) {
+			panic(pgerror.New(pgcode.Syntax, "DEBUG flag can only be used with EXPLAIN ANALYZE"))
+		}
+		// EXPLAIN ANALYZE (DEBUG) is intercepted by the connExecutor before
+		// planning, so we only get here when it is nested in another statement.
+		panic(pgerror.New(pgcode.FeatureNotSupported,
+			"EXPLAIN ANALYZE (DEBUG) can only be used as a top-level statement"))
+
 	default:
 		panic(pgerror.Newf(pgcode.FeatureNotSupported,
 			"EXPLAIN ANALYZE does not support RETURNING NOTHING statements"))
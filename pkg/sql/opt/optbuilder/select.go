@@ -1061,7 +1061,9 @@ func (b *Builder) buildFromWithLateral(tables tree.TableExprs, inScope *scope) (
 // validateAsOf ensures that any AS OF SYSTEM TIME timestamp is consistent with
 // that of the root statement.
 func (b *Builder) validateAsOf(asOf tree.AsOfClause) {
-	ts, err := tree.EvalAsOfTimestamp(asOf, b.semaCtx, b.evalCtx)
+	// Bounded staleness clauses evaluate to their minimum timestamp bound, which
+	// is what the root statement records as well.
+	asOfTime, err := tree.EvalAsOf(asOf, b.semaCtx, b.evalCtx)
 	if err != nil {
 		panic(err)
 	}
@@ -1071,7 +1073,7 @@ func (b *Builder) validateAsOf(asOf tree.AsOfClause) {
 			"AS OF SYSTEM TIME must be provided on a top-level statement"))
 	}
 
-	if *b.semaCtx.AsOfTimestamp != ts {
+	if *b.semaCtx.AsOfTimestamp != asOfTime.Timestamp {
 		panic(unimplementedWithIssueDetailf(35712, "",
 			"cannot specify AS OF SYSTEM TIME with different timestamps"))
 	}
@@ -80,6 +80,10 @@ type coster struct {
 	// 0.5, and the estimated cost of an expression is c, the cost returned by
 	// ComputeCost will be in the range [c - 0.5 * c, c + 0.5 * c).
 	perturbation float64
+
+	// outline, if set, is the plan outline that the chosen plan must follow.
+	// See Optimizer.SetPlanOutline.
+	outline *outlineChecker
 }
 
 var _ Coster = &coster{}
@@ -131,6 +135,7 @@ func (c *coster) Init(evalCtx *tree.EvalContext, mem *memo.Memo, perturbation fl
 	c.mem = mem
 	c.locality = evalCtx.Locality
 	c.perturbation = perturbation
+	c.outline = nil
 }
 
 // ComputeCost calculates the estimated cost of the top-level operator in a
@@ -205,6 +210,12 @@ func (c *coster) ComputeCost(candidate memo.RelExpr, required *physical.Required
 		// default behavior.
 	}
 
+	if c.outline != nil && c.outline.deviates(candidate) {
+		// Expressions that deviate from the pinned plan outline get a very high
+		// cost, so that they are only used if there is no other choice.
+		cost = hugeCost
+	}
+
 	// Add a one-time cost for any operator, meant to reflect the cost of setting
 	// up execution for the operator. This makes plans with fewer operators
 	// preferable, all else being equal.
@@ -150,6 +150,15 @@ func (o *Optimizer) DisableOptimizations() {
 	o.NotifyOnMatchedRule(func(opt.RuleName) bool { return false })
 }
 
+// SetPlanOutline instructs the optimizer to follow the given plan outline,
+// which was built with BuildPlanOutline for the same statement. Expressions
+// that deviate from the outline are only picked if no expression following it
+// is possible. The outline is only honored by the default coster, and is
+// cleared by Init.
+func (o *Optimizer) SetPlanOutline(outline *PlanOutline) {
+	o.defaultCoster.outline = &outlineChecker{outline: outline, mem: o.mem}
+}
+
 // NotifyOnMatchedRule sets a callback function which is invoked each time an
 // optimization rule (Normalize or Explore) has been matched by the optimizer.
 // If matchedRule is nil, then no notifications are sent, and all rules are
@@ -0,0 +1,366 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package xform
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/opt"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/memo"
+	"github.com/cockroachdb/cockroach/pkg/util"
+)
+
+// PlanOutline summarizes the decisions made by the optimizer for a query: the
+// index used to access each table, the order in which the tables are joined
+// and the algorithm used for each join. An outline is extracted from an
+// optimized memo with BuildPlanOutline. When it is passed to
+// Optimizer.SetPlanOutline, the optimizer avoids the expressions that deviate
+// from the outline; they are only picked if there is no alternative, for
+// example because an index was dropped since the outline was built.
+//
+// Tables are identified by their alias in the query. If the same alias is used
+// for several tables, the second occurrence is identified as "alias#2", the
+// third as "alias#3", and so on.
+type PlanOutline struct {
+	Scans []ScanOutline `json:"scans,omitempty"`
+	Joins []JoinOutline `json:"joins,omitempty"`
+}
+
+// ScanOutline records the index used to access a table, either by scanning it
+// or by looking up into it.
+type ScanOutline struct {
+	Table string `json:"table"`
+	Index string `json:"index"`
+}
+
+// JoinOutline records the tables on each side of a join, along with the
+// algorithm used to execute the join. The table lists are sorted.
+type JoinOutline struct {
+	Left      []string      `json:"left"`
+	Right     []string      `json:"right"`
+	Algorithm JoinAlgorithm `json:"algorithm"`
+}
+
+// JoinAlgorithm identifies the execution method of a join in a PlanOutline.
+type JoinAlgorithm string
+
+const (
+	// HashJoin is a hash join; the right side is the one stored in the
+	// hashtable. Cross joins are also recorded as hash joins.
+	HashJoin JoinAlgorithm = "hash"
+
+	// MergeJoin is a merge join.
+	MergeJoin JoinAlgorithm = "merge"
+
+	// LookupJoin is a lookup join; the right side is the table being looked up
+	// into.
+	LookupJoin JoinAlgorithm = "lookup"
+)
+
+// ParsePlanOutline decodes an outline encoded with PlanOutline.JSON.
+func ParsePlanOutline(encoded []byte) (*PlanOutline, error) {
+	var outline PlanOutline
+	if err := json.Unmarshal(encoded, &outline); err != nil {
+		return nil, err
+	}
+	return &outline, nil
+}
+
+// JSON encodes the outline.
+func (po *PlanOutline) JSON() ([]byte, error) {
+	return json.Marshal(po)
+}
+
+// String returns a compact description of the outline, such as
+// "a@primary, b@b_x_idx; lookup join (a) (b), hash join (a, b) (c)".
+func (po *PlanOutline) String() string {
+	var buf bytes.Buffer
+	for i := range po.Scans {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		fmt.Fprintf(&buf, "%s@%s", po.Scans[i].Table, po.Scans[i].Index)
+	}
+	for i := range po.Joins {
+		if i == 0 {
+			buf.WriteString("; ")
+		} else {
+			buf.WriteString(", ")
+		}
+		j := &po.Joins[i]
+		fmt.Fprintf(&buf, "%s join (%s) (%s)",
+			j.Algorithm, strings.Join(j.Left, ", "), strings.Join(j.Right, ", "))
+	}
+	return buf.String()
+}
+
+// BuildPlanOutline extracts the outline of the lowest cost plan from an
+// optimized memo.
+func BuildPlanOutline(mem *memo.Memo) *PlanOutline {
+	b := outlineBuilder{
+		names:   makeOutlineTableNames(mem.Metadata()),
+		outline: &PlanOutline{},
+		seen:    make(map[opt.TableID]bool),
+	}
+	b.walk(mem.RootExpr())
+	sort.Slice(b.outline.Scans, func(i, j int) bool {
+		return b.outline.Scans[i].Table < b.outline.Scans[j].Table
+	})
+	return b.outline
+}
+
+type outlineBuilder struct {
+	names   outlineTableNames
+	outline *PlanOutline
+	// seen contains the tables for which a ScanOutline was already added.
+	seen map[opt.TableID]bool
+}
+
+func (b *outlineBuilder) walk(e opt.Expr) {
+	// Walk all the children, including the scalar ones, so that the tables
+	// referenced by subqueries are also part of the outline.
+	for i, n := 0, e.ChildCount(); i < n; i++ {
+		b.walk(e.Child(i))
+	}
+
+	switch t := e.(type) {
+	case *memo.ScanExpr:
+		b.addScan(t.Table, t.Index)
+
+	case *memo.LookupJoinExpr:
+		b.addScan(t.Table, t.Index)
+		var right util.FastIntSet
+		right.Add(int(t.Table))
+		b.addJoin(outlineTables(t.Input), right, LookupJoin)
+
+	case *memo.MergeJoinExpr:
+		b.addJoin(outlineTables(t.Left), outlineTables(t.Right), MergeJoin)
+
+	case *memo.InnerJoinExpr, *memo.LeftJoinExpr, *memo.RightJoinExpr, *memo.FullJoinExpr,
+		*memo.SemiJoinExpr, *memo.AntiJoinExpr:
+		rel := t.(memo.RelExpr)
+		b.addJoin(
+			outlineTables(rel.Child(0).(memo.RelExpr)),
+			outlineTables(rel.Child(1).(memo.RelExpr)),
+			HashJoin,
+		)
+	}
+}
+
+func (b *outlineBuilder) addScan(tabID opt.TableID, index int) {
+	if b.seen[tabID] {
+		return
+	}
+	b.seen[tabID] = true
+	b.outline.Scans = append(b.outline.Scans, ScanOutline{
+		Table: b.names.byID[tabID],
+		Index: indexName(b.names.md, tabID, index),
+	})
+}
+
+func (b *outlineBuilder) addJoin(left, right util.FastIntSet, algorithm JoinAlgorithm) {
+	if left.Empty() || right.Empty() {
+		return
+	}
+	b.outline.Joins = append(b.outline.Joins, JoinOutline{
+		Left:      b.names.toNames(left),
+		Right:     b.names.toNames(right),
+		Algorithm: algorithm,
+	})
+}
+
+// outlineChecker determines whether the expressions considered by the
+// optimizer follow a PlanOutline.
+type outlineChecker struct {
+	outline *PlanOutline
+	mem     *memo.Memo
+
+	// The fields below are initialized lazily, once the metadata of the query is
+	// complete.
+	initialized bool
+	// scans maps each table to the name of the index that must be used to
+	// access it.
+	scans map[opt.TableID]string
+	joins []outlineJoin
+	// pinned contains all the tables mentioned in the outline.
+	pinned util.FastIntSet
+}
+
+type outlineJoin struct {
+	left, right, all util.FastIntSet
+	algorithm        JoinAlgorithm
+}
+
+func (oc *outlineChecker) init() {
+	oc.initialized = true
+	names := makeOutlineTableNames(oc.mem.Metadata())
+	oc.scans = make(map[opt.TableID]string, len(oc.outline.Scans))
+	for _, s := range oc.outline.Scans {
+		if tabID, ok := names.byName[s.Table]; ok {
+			oc.scans[tabID] = s.Index
+			oc.pinned.Add(int(tabID))
+		}
+	}
+	for _, j := range oc.outline.Joins {
+		left, okLeft := names.toSet(j.Left)
+		right, okRight := names.toSet(j.Right)
+		if !okLeft || !okRight {
+			// The outline doesn't match the query; ignore this join.
+			continue
+		}
+		all := left.Union(right)
+		oc.joins = append(oc.joins, outlineJoin{
+			left: left, right: right, all: all, algorithm: j.Algorithm,
+		})
+		oc.pinned.UnionWith(all)
+	}
+}
+
+// deviates returns true if the given expression does not follow the outline.
+func (oc *outlineChecker) deviates(e memo.RelExpr) bool {
+	if !oc.initialized {
+		oc.init()
+	}
+	switch t := e.(type) {
+	case *memo.ScanExpr:
+		return oc.deviatesIndex(t.Table, t.Index)
+
+	case *memo.LookupJoinExpr:
+		if oc.deviatesIndex(t.Table, t.Index) {
+			return true
+		}
+		var right util.FastIntSet
+		right.Add(int(t.Table))
+		return oc.deviatesJoin(outlineTables(t.Input), right, LookupJoin)
+
+	case *memo.MergeJoinExpr:
+		return oc.deviatesJoin(outlineTables(t.Left), outlineTables(t.Right), MergeJoin)
+
+	case *memo.InnerJoinExpr, *memo.LeftJoinExpr, *memo.RightJoinExpr, *memo.FullJoinExpr,
+		*memo.SemiJoinExpr, *memo.AntiJoinExpr:
+		return oc.deviatesJoin(
+			outlineTables(e.Child(0).(memo.RelExpr)),
+			outlineTables(e.Child(1).(memo.RelExpr)),
+			HashJoin,
+		)
+
+	case *memo.ZigzagJoinExpr:
+		// Zigzag joins are never part of an outline.
+		return oc.pinned.Contains(int(t.LeftTable)) || oc.pinned.Contains(int(t.RightTable))
+	}
+	return false
+}
+
+func (oc *outlineChecker) deviatesIndex(tabID opt.TableID, index int) bool {
+	name, ok := oc.scans[tabID]
+	return ok && name != indexName(oc.mem.Metadata(), tabID, index)
+}
+
+func (oc *outlineChecker) deviatesJoin(
+	left, right util.FastIntSet, algorithm JoinAlgorithm,
+) bool {
+	if left.Empty() || right.Empty() {
+		return false
+	}
+	all := left.Union(right)
+	if !all.SubsetOf(oc.pinned) {
+		// The outline says nothing about this join.
+		return false
+	}
+	for i := range oc.joins {
+		j := &oc.joins[i]
+		if j.all.Equals(all) {
+			return j.algorithm != algorithm || !j.left.Equals(left) || !j.right.Equals(right)
+		}
+	}
+	// The outline joins these tables in a different order.
+	return true
+}
+
+// outlineTables returns the set of tables accessed by a relational
+// expression, excluding the ones only referenced by subqueries.
+func outlineTables(e memo.RelExpr) util.FastIntSet {
+	var tables util.FastIntSet
+	switch t := e.(type) {
+	case *memo.ScanExpr:
+		tables.Add(int(t.Table))
+	case *memo.IndexJoinExpr:
+		tables.Add(int(t.Table))
+	case *memo.LookupJoinExpr:
+		tables.Add(int(t.Table))
+	case *memo.ZigzagJoinExpr:
+		tables.Add(int(t.LeftTable))
+		tables.Add(int(t.RightTable))
+	}
+	for i, n := 0, e.ChildCount(); i < n; i++ {
+		if child, ok := e.Child(i).(memo.RelExpr); ok {
+			tables.UnionWith(outlineTables(child))
+		}
+	}
+	return tables
+}
+
+// outlineTableNames maps the tables of a query to the names used to identify
+// them in a PlanOutline.
+type outlineTableNames struct {
+	md     *opt.Metadata
+	byID   map[opt.TableID]string
+	byName map[string]opt.TableID
+}
+
+func makeOutlineTableNames(md *opt.Metadata) outlineTableNames {
+	tables := md.AllTables()
+	n := outlineTableNames{
+		md:     md,
+		byID:   make(map[opt.TableID]string, len(tables)),
+		byName: make(map[string]opt.TableID, len(tables)),
+	}
+	counts := make(map[string]int, len(tables))
+	for i := range tables {
+		name := tables[i].Alias.Table()
+		counts[name]++
+		if c := counts[name]; c > 1 {
+			name = fmt.Sprintf("%s#%d", name, c)
+		}
+		n.byID[tables[i].MetaID] = name
+		n.byName[name] = tables[i].MetaID
+	}
+	return n
+}
+
+func (n *outlineTableNames) toNames(tables util.FastIntSet) []string {
+	names := make([]string, 0, tables.Len())
+	tables.ForEach(func(i int) {
+		names = append(names, n.byID[opt.TableID(i)])
+	})
+	sort.Strings(names)
+	return names
+}
+
+func (n *outlineTableNames) toSet(names []string) (_ util.FastIntSet, ok bool) {
+	var tables util.FastIntSet
+	for _, name := range names {
+		tabID, ok := n.byName[name]
+		if !ok {
+			return util.FastIntSet{}, false
+		}
+		tables.Add(int(tabID))
+	}
+	return tables, true
+}
+
+func indexName(md *opt.Metadata, tabID opt.TableID, index int) string {
+	return string(md.Table(tabID).Index(index).Name())
+}
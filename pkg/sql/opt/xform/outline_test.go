@@ -0,0 +1,87 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package xform_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/testutils"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/testutils/testcat"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/xform"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestPlanOutline(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	catalog := testcat.New()
+	for _, ddl := range []string{
+		"CREATE TABLE abc (a INT PRIMARY KEY, b INT, c STRING, INDEX abc_b (b))",
+		"CREATE TABLE xyz (x INT PRIMARY KEY, y INT, z STRING)",
+	} {
+		if _, err := catalog.ExecuteDDL(ddl); err != nil {
+			t.Fatal(err)
+		}
+	}
+	evalCtx := tree.MakeTestingEvalContext(cluster.MakeTestingClusterSettings())
+	const query = "SELECT * FROM abc JOIN xyz ON abc.b = xyz.y"
+
+	optimize := func(outline *xform.PlanOutline) *xform.PlanOutline {
+		var o xform.Optimizer
+		testutils.BuildQuery(t, &o, catalog, &evalCtx, query)
+		if outline != nil {
+			o.SetPlanOutline(outline)
+		}
+		if _, err := o.Optimize(); err != nil {
+			t.Fatal(err)
+		}
+		return xform.BuildPlanOutline(o.Memo())
+	}
+
+	// Check that the outline survives a round trip through its encoding.
+	outline := optimize(nil)
+	encoded, err := outline.JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := xform.ParsePlanOutline(encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(outline, decoded) {
+		t.Fatalf("expected %s, got %s", outline, decoded)
+	}
+
+	// Force a different index and join order, and check that the optimizer
+	// follows them.
+	forced := &xform.PlanOutline{
+		Scans: []xform.ScanOutline{
+			{Table: "abc", Index: "abc_b"},
+			{Table: "xyz", Index: "primary"},
+		},
+		Joins: []xform.JoinOutline{
+			{Left: []string{"xyz"}, Right: []string{"abc"}, Algorithm: xform.HashJoin},
+		},
+	}
+	if reflect.DeepEqual(outline, forced) {
+		t.Fatalf("expected the default plan to differ from %s", forced)
+	}
+	if res := optimize(forced); !reflect.DeepEqual(res, forced) {
+		t.Fatalf("expected %s, got %s", forced, res)
+	}
+
+	const expected = "abc@abc_b, xyz@primary; hash join (xyz) (abc)"
+	if s := forced.String(); s != expected {
+		t.Errorf("expected %q, got %q", expected, s)
+	}
+}
@@ -1169,6 +1169,9 @@ func (ef *execFactory) ConstructExplain(
 			stmtType,
 		)
 
+	case tree.ExplainDebug:
+		return nil, errors.New("EXPLAIN ANALYZE (DEBUG) can only be used as a top-level statement")
+
 	default:
 		panic(fmt.Sprintf("unsupported explain mode %v", options.Mode))
 	}
@@ -114,6 +114,8 @@ func TestContextualHelp(t *testing.T) {
 
 		{`CREATE STATISTICS ??`, `CREATE STATISTICS`},
 
+		{`CREATE SCHEDULE ??`, `CREATE SCHEDULE FOR BACKUP`},
+
 		{`CREATE TABLE blah (??`, `CREATE TABLE`},
 		{`CREATE TABLE IF NOT ??`, `CREATE TABLE`},
 		{`CREATE TABLE blah (x, y) AS ??`, `CREATE TABLE`},
@@ -142,6 +144,8 @@ func TestContextualHelp(t *testing.T) {
 		{`DROP ROLE IF ??`, `DROP ROLE`},
 		{`DROP ROLE IF EXISTS bluh ??`, `DROP ROLE`},
 
+		{`DROP SCHEDULE ??`, `DROP SCHEDULE`},
+
 		{`DROP SEQUENCE blah ??`, `DROP SEQUENCE`},
 		{`DROP SEQUENCE IF ??`, `DROP SEQUENCE`},
 		{`DROP SEQUENCE IF EXISTS blih, bloh ??`, `DROP SEQUENCE`},
@@ -166,6 +170,9 @@ func TestContextualHelp(t *testing.T) {
 		{`EXPLAIN UPDATE xx SET x = y ??`, `UPDATE`},
 		{`SELECT * FROM [EXPLAIN ??`, `EXPLAIN`},
 
+		{`PIN ??`, `PIN PLAN`},
+		{`PIN PLAN FOR SELECT 1 ??`, `SELECT`},
+
 		{`PREPARE foo ??`, `PREPARE`},
 		{`PREPARE foo (??`, `PREPARE`},
 		{`PREPARE foo AS SELECT 1 ??`, `SELECT`},
@@ -196,6 +203,9 @@ func TestContextualHelp(t *testing.T) {
 		{`UPSERT INTO blah VALUES (1) ??`, `VALUES`},
 		{`UPSERT INTO blah TABLE foo ??`, `TABLE`},
 
+		{`UNPIN ??`, `UNPIN PLAN`},
+		{`UNPIN PLAN FOR SELECT 1 ??`, `SELECT`},
+
 		{`UPDATE blah ??`, `UPDATE`},
 		{`UPDATE blah SET ??`, `UPDATE`},
 		{`UPDATE blah SET x = 3 WHERE true ??`, `UPDATE`},
@@ -206,9 +216,11 @@ func TestContextualHelp(t *testing.T) {
 		{`GRANT ALL ON foo TO ??`, `GRANT`},
 		{`GRANT ALL ON foo TO bar ??`, `GRANT`},
 
-		{`PAUSE ??`, `PAUSE JOBS`},
+		{`PAUSE ??`, `PAUSE`},
+		{`PAUSE SCHEDULE ??`, `PAUSE SCHEDULE`},
 
-		{`RESUME ??`, `RESUME JOBS`},
+		{`RESUME ??`, `RESUME`},
+		{`RESUME SCHEDULE ??`, `RESUME SCHEDULE`},
 
 		{`REVOKE ALL ??`, `REVOKE`},
 		{`REVOKE ALL ON foo FROM ??`, `REVOKE`},
@@ -283,6 +295,9 @@ func TestContextualHelp(t *testing.T) {
 
 		{`SHOW ROLES ??`, `SHOW ROLES`},
 
+		{`SHOW SCHEDULES ??`, `SHOW SCHEDULES`},
+		{`SHOW SCHEDULE ??`, `SHOW SCHEDULES`},
+
 		{`SHOW SCHEMAS FROM ??`, `SHOW SCHEMAS`},
 		{`SHOW SCHEMAS FROM blah ??`, `SHOW SCHEMAS`},
 
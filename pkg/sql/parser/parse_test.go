@@ -1327,6 +1327,10 @@ func TestParse(t *testing.T) {
 		{`EXPLAIN SHOW BACKUP 'bar'`},
 		{`SHOW BACKUP RANGES 'bar'`},
 		{`SHOW BACKUP FILES 'bar'`},
+		{`SHOW BACKUP 'bar' WITH encryption_passphrase = 'secret'`},
+		{`SHOW BACKUP SCHEMAS 'bar' WITH encryption_passphrase = 'secret'`},
+		{`SHOW BACKUPS IN 'bar'`},
+		{`SHOW BACKUPS IN $1`},
 
 		{`BACKUP TABLE foo TO 'bar' AS OF SYSTEM TIME '1' INCREMENTAL FROM 'baz'`},
 		{`BACKUP TABLE foo TO $1 INCREMENTAL FROM 'bar', $2, 'baz'`},
@@ -1339,6 +1343,24 @@ func TestParse(t *testing.T) {
 		{`BACKUP DATABASE foo TO ($1, $2)`},
 		{`BACKUP DATABASE foo TO ($1, $2) INCREMENTAL FROM 'baz'`},
 
+		{`BACKUP DATABASE foo INTO 'bar'`},
+		{`BACKUP DATABASE foo INTO 'bar' AS OF SYSTEM TIME '1' WITH revision_history`},
+		{`BACKUP TABLE foo INTO LATEST IN 'bar'`},
+		{`BACKUP DATABASE foo INTO LATEST IN ($1, $2) AS OF SYSTEM TIME '1'`},
+
+		{`CREATE SCHEDULE FOR BACKUP TABLE foo INTO 'bar' RECURRING '@hourly'`},
+		{`CREATE SCHEDULE 'baz' FOR BACKUP DATABASE foo INTO 'bar' RECURRING '@hourly' FULL BACKUP '@daily'`},
+		{`CREATE SCHEDULE $1 FOR BACKUP TABLE foo, bar INTO ($2, $3) WITH revision_history RECURRING $4 FULL BACKUP ALWAYS`},
+		{`CREATE SCHEDULE FOR BACKUP DATABASE foo INTO 'bar' WITH encryption_passphrase = 'secret' RECURRING '0 * * * *' FULL BACKUP $1`},
+
+		{`PAUSE SCHEDULE 123`},
+		{`PAUSE SCHEDULE $1`},
+		{`RESUME SCHEDULE 123`},
+		{`DROP SCHEDULE 123`},
+		{`SHOW SCHEDULES`},
+		{`SHOW SCHEDULE 123`},
+		{`EXPLAIN SHOW SCHEDULE 123`},
+
 		{`RESTORE TABLE foo FROM 'bar'`},
 		{`EXPLAIN RESTORE TABLE foo FROM 'bar'`},
 		{`RESTORE TABLE foo FROM $1`},
@@ -1357,6 +1379,9 @@ func TestParse(t *testing.T) {
 		{`RESTORE DATABASE foo FROM ($1, $2), ($3, $4)`},
 		{`RESTORE DATABASE foo FROM ($1, $2), ($3, $4) AS OF SYSTEM TIME '1'`},
 
+		{`RESTORE DATABASE foo FROM LATEST IN 'bar'`},
+		{`RESTORE TABLE foo FROM LATEST IN ($1, $2) AS OF SYSTEM TIME '1'`},
+
 		{`BACKUP TABLE foo TO 'bar' WITH key1, key2 = 'value'`},
 		{`RESTORE TABLE foo FROM 'bar' WITH key1, key2 = 'value'`},
 
@@ -1383,10 +1408,13 @@ func TestParse(t *testing.T) {
 		{`EXPLAIN CREATE CHANGEFEED FOR TABLE foo INTO 'sink'`},
 		{`CREATE CHANGEFEED FOR TABLE foo, db.bar, schema.db.foo INTO 'sink'`},
 		{`CREATE CHANGEFEED FOR TABLE foo INTO 'sink'`},
+		{`CREATE CHANGEFEED FOR DATABASE foo INTO 'sink'`},
+		{`CREATE CHANGEFEED INTO 'sink' AS SELECT a, b + 1 AS c FROM foo WHERE a > 1`},
+		{`CREATE CHANGEFEED INTO 'sink' WITH resolved AS SELECT * FROM foo`},
+		{`EXPERIMENTAL CHANGEFEED AS SELECT a FROM foo`},
 		// TODO(dan): Implement.
 		// {`CREATE CHANGEFEED FOR TABLE foo VALUES FROM (1) TO (2) INTO 'sink'`},
 		// {`CREATE CHANGEFEED FOR TABLE foo PARTITION bar, baz INTO 'sink'`},
-		// {`CREATE CHANGEFEED FOR DATABASE foo INTO 'sink'`},
 		{`CREATE CHANGEFEED FOR TABLE foo INTO 'sink' WITH bar = 'baz'`},
 
 		// Regression for #15926
@@ -1849,6 +1877,8 @@ func TestParse2(t *testing.T) {
 		{`EXPLAIN SHOW JOB a`, `EXPLAIN SHOW JOBS VALUES (a)`},
 		{`SHOW JOB WHEN COMPLETE a`, `SHOW JOBS WHEN COMPLETE VALUES (a)`},
 		{`EXPLAIN SHOW JOB WHEN COMPLETE a`, `EXPLAIN SHOW JOBS WHEN COMPLETE VALUES (a)`},
+		{`CREATE SCHEDULE foo FOR BACKUP TABLE t INTO bar RECURRING daily`,
+			`CREATE SCHEDULE 'foo' FOR BACKUP TABLE t INTO 'bar' RECURRING 'daily'`},
 		{`CANCEL QUERY a`, `CANCEL QUERIES VALUES (a)`},
 		{`CANCEL QUERY IF EXISTS a`, `CANCEL QUERIES IF EXISTS VALUES (a)`},
 		{`CANCEL SESSION a`, `CANCEL SESSIONS VALUES (a)`},
@@ -474,6 +474,9 @@ func (u *sqlSymUnion) partitionedBackup() tree.PartitionedBackup {
 func (u *sqlSymUnion) partitionedBackups() []tree.PartitionedBackup {
     return u.val.([]tree.PartitionedBackup)
 }
+func (u *sqlSymUnion) fullBackupClause() *tree.FullBackupClause {
+    return u.val.(*tree.FullBackupClause)
+}
 func newNameFromStr(s string) *tree.Name {
     return (*tree.Name)(&s)
 }
@@ -497,10 +500,10 @@ func newNameFromStr(s string) *tree.Name {
 
 // Ordinary key words in alphabetical order.
 %token <str> ABORT ACTION ADD ADMIN AGGREGATE
-%token <str> ALL ALTER ANALYSE ANALYZE AND AND_AND ANY ANNOTATE_TYPE ARRAY AS ASC
+%token <str> ALL ALTER ALWAYS ANALYSE ANALYZE AND AND_AND ANY ANNOTATE_TYPE ARRAY AS ASC
 %token <str> ASYMMETRIC AT AUTHORIZATION AUTOMATIC
 
-%token <str> BACKUP BEGIN BETWEEN BIGINT BIGSERIAL BIT
+%token <str> BACKUP BACKUPS BEGIN BETWEEN BIGINT BIGSERIAL BIT
 %token <str> BLOB BOOL BOOLEAN BOTH BY BYTEA BYTES
 
 %token <str> CACHE CANCEL CASCADE CASE CAST CHANGEFEED CHAR
@@ -540,7 +543,7 @@ func newNameFromStr(s string) *tree.Name {
 
 %token <str> KEY KEYS KV
 
-%token <str> LANGUAGE LAST LATERAL LC_CTYPE LC_COLLATE
+%token <str> LANGUAGE LAST LATEST LATERAL LC_CTYPE LC_COLLATE
 %token <str> LEADING LEASE LEAST LEFT LESS LEVEL LIKE LIMIT LIST LOCAL
 %token <str> LOCALTIME LOCALTIMESTAMP LOCKED LOOKUP LOW LSHIFT
 
@@ -552,19 +555,19 @@ func newNameFromStr(s string) *tree.Name {
 %token <str> OF OFF OFFSET OID OIDS OIDVECTOR ON ONLY OPT OPTION OPTIONS OR
 %token <str> ORDER ORDINALITY OTHERS OUT OUTER OVER OVERLAPS OVERLAY OWNED OPERATOR
 
-%token <str> PARENT PARTIAL PARTITION PARTITIONS PASSWORD PAUSE PHYSICAL PLACING
+%token <str> PARENT PARTIAL PARTITION PARTITIONS PASSWORD PAUSE PHYSICAL PIN PLACING
 %token <str> PLAN PLANS POSITION PRECEDING PRECISION PREPARE PRIMARY PRIORITY
 %token <str> PROCEDURAL PUBLIC PUBLICATION
 
 %token <str> QUERIES QUERY
 
-%token <str> RANGE RANGES READ REAL RECURSIVE REF REFERENCES
+%token <str> RANGE RANGES READ REAL RECURRING RECURSIVE REF REFERENCES
 %token <str> REGCLASS REGPROC REGPROCEDURE REGNAMESPACE REGTYPE
 %token <str> REMOVE_PATH RENAME REPEATABLE REPLACE
 %token <str> RELEASE RESET RESTORE RESTRICT RESUME RETURNING REVOKE RIGHT
 %token <str> ROLE ROLES ROLLBACK ROLLUP ROW ROWS RSHIFT RULE
 
-%token <str> SAVEPOINT SCATTER SCHEMA SCHEMAS SCRUB SEARCH SECOND SELECT SEQUENCE SEQUENCES
+%token <str> SAVEPOINT SCATTER SCHEDULE SCHEDULES SCHEMA SCHEMAS SCRUB SEARCH SECOND SELECT SEQUENCE SEQUENCES
 %token <str> SERIAL SERIAL2 SERIAL4 SERIAL8
 %token <str> SERIALIZABLE SERVER SESSION SESSIONS SESSION_USER SET SETTING SETTINGS
 %token <str> SHARE SHOW SIMILAR SIMPLE SKIP SMALLINT SMALLSERIAL SNAPSHOT SOME SPLIT SQL
@@ -577,7 +580,7 @@ func newNameFromStr(s string) *tree.Name {
 %token <str> TRUNCATE TRUSTED TYPE
 %token <str> TRACING
 
-%token <str> UNBOUNDED UNCOMMITTED UNION UNIQUE UNKNOWN UNLOGGED UNSPLIT
+%token <str> UNBOUNDED UNCOMMITTED UNION UNIQUE UNKNOWN UNLOGGED UNPIN UNSPLIT
 %token <str> UPDATE UPSERT USE USER USERS USING UUID
 
 %token <str> VALID VALIDATE VALUE VALUES VARBIT VARCHAR VARIADIC VIEW VARYING VIRTUAL
@@ -681,6 +684,9 @@ func newNameFromStr(s string) *tree.Name {
 
 %type <tree.Statement> create_stmt
 %type <tree.Statement> create_changefeed_stmt
+%type <tree.Statement> create_schedule_for_backup_stmt
+%type <tree.Expr> opt_schedule_label
+%type <*tree.FullBackupClause> opt_full_backup_clause
 %type <tree.Statement> create_ddl_stmt
 %type <tree.Statement> create_database_stmt
 %type <tree.Statement> create_index_stmt
@@ -702,6 +708,7 @@ func newNameFromStr(s string) *tree.Name {
 
 %type <tree.Statement> drop_stmt
 %type <tree.Statement> drop_ddl_stmt
+%type <tree.Statement> drop_schedule_stmt
 %type <tree.Statement> drop_database_stmt
 %type <tree.Statement> drop_index_stmt
 %type <tree.Statement> drop_role_stmt
@@ -720,10 +727,12 @@ func newNameFromStr(s string) *tree.Name {
 %type <tree.Statement> grant_stmt
 %type <tree.Statement> insert_stmt
 %type <tree.Statement> import_stmt
-%type <tree.Statement> pause_stmt
+%type <tree.Statement> pause_stmt pause_jobs_stmt pause_schedule_stmt
+%type <tree.Statement> pin_plan_stmt
+%type <tree.Statement> unpin_plan_stmt
 %type <tree.Statement> release_stmt
 %type <tree.Statement> reset_stmt reset_session_stmt reset_csetting_stmt
-%type <tree.Statement> resume_stmt
+%type <tree.Statement> resume_stmt resume_jobs_stmt resume_schedule_stmt
 %type <tree.Statement> restore_stmt
 %type <tree.PartitionedBackup> partitioned_backup
 %type <[]tree.PartitionedBackup> partitioned_backup_list
@@ -759,6 +768,7 @@ func newNameFromStr(s string) *tree.Name {
 %type <tree.Statement> show_ranges_stmt
 %type <tree.Statement> show_range_for_row_stmt
 %type <tree.Statement> show_roles_stmt
+%type <tree.Statement> show_schedules_stmt
 %type <tree.Statement> show_schemas_stmt
 %type <tree.Statement> show_sequences_stmt
 %type <tree.Statement> show_session_stmt
@@ -1102,12 +1112,14 @@ stmt:
 | deallocate_stmt   // EXTEND WITH HELP: DEALLOCATE
 | discard_stmt      // EXTEND WITH HELP: DISCARD
 | grant_stmt        // EXTEND WITH HELP: GRANT
+| pin_plan_stmt     // EXTEND WITH HELP: PIN PLAN
 | prepare_stmt      // EXTEND WITH HELP: PREPARE
 | revoke_stmt       // EXTEND WITH HELP: REVOKE
 | savepoint_stmt    // EXTEND WITH HELP: SAVEPOINT
 | release_stmt      // EXTEND WITH HELP: RELEASE
 | nonpreparable_set_stmt // help texts in sub-rule
 | transaction_stmt  // help texts in sub-rule
+| unpin_plan_stmt   // EXTEND WITH HELP: UNPIN PLAN
 | /* EMPTY */
   {
     $$.val = tree.Statement(nil)
@@ -1811,6 +1823,9 @@ opt_validate_behavior:
 //        [ AS OF SYSTEM TIME <expr> ]
 //        [ INCREMENTAL FROM <location...> ]
 //        [ WITH <option> [= <value>] [, ...] ]
+// BACKUP <targets...> INTO [ LATEST IN ] <collection...>
+//        [ AS OF SYSTEM TIME <expr> ]
+//        [ WITH <option> [= <value>] [, ...] ]
 //
 // Targets:
 //    TABLE <pattern> [, ...]
@@ -1819,9 +1834,14 @@ opt_validate_behavior:
 // Location:
 //    "[scheme]://[host]/[path to backup]?[parameters]"
 //
+// BACKUP INTO writes a full backup to a new subdirectory of the collection,
+// and BACKUP INTO LATEST IN appends an incremental backup to the latest full
+// backup of the collection.
+//
 // Options:
-//    INTO_DB
-//    SKIP_MISSING_FOREIGN_KEYS
+//    REVISION_HISTORY
+//    ENCRYPTION_PASSPHRASE = '...'
+//    ENCRYPTION_KEY_FILE = '<location>'
 //
 // %SeeAlso: RESTORE, WEBDOCS/backup.html
 backup_stmt:
@@ -1829,6 +1849,14 @@ backup_stmt:
   {
     $$.val = &tree.Backup{Targets: $2.targetList(), To: $4.partitionedBackup(), IncrementalFrom: $6.exprs(), AsOf: $5.asOfClause(), Options: $7.kvOptions()}
   }
+| BACKUP targets INTO partitioned_backup opt_as_of_clause opt_with_options
+  {
+    $$.val = &tree.Backup{Targets: $2.targetList(), To: $4.partitionedBackup(), Nested: true, AsOf: $5.asOfClause(), Options: $6.kvOptions()}
+  }
+| BACKUP targets INTO LATEST IN partitioned_backup opt_as_of_clause opt_with_options
+  {
+    $$.val = &tree.Backup{Targets: $2.targetList(), To: $6.partitionedBackup(), Nested: true, AppendToLatest: true, AsOf: $7.asOfClause(), Options: $8.kvOptions()}
+  }
 | BACKUP error // SHOW HELP: BACKUP
 
 // %Help: RESTORE - restore data from external storage
@@ -1837,6 +1865,9 @@ backup_stmt:
 // RESTORE <targets...> FROM <location...>
 //         [ AS OF SYSTEM TIME <expr> ]
 //         [ WITH <option> [= <value>] [, ...] ]
+// RESTORE <targets...> FROM LATEST IN <collection...>
+//         [ AS OF SYSTEM TIME <expr> ]
+//         [ WITH <option> [= <value>] [, ...] ]
 //
 // Targets:
 //    TABLE <pattern> [, ...]
@@ -1848,6 +1879,8 @@ backup_stmt:
 // Options:
 //    INTO_DB
 //    SKIP_MISSING_FOREIGN_KEYS
+//    ENCRYPTION_PASSPHRASE = '...'
+//    ENCRYPTION_KEY_FILE = '<location>'
 //
 // %SeeAlso: BACKUP, WEBDOCS/restore.html
 restore_stmt:
@@ -1855,6 +1888,10 @@ restore_stmt:
   {
     $$.val = &tree.Restore{Targets: $2.targetList(), From: $4.partitionedBackups(), AsOf: $5.asOfClause(), Options: $6.kvOptions()}
   }
+| RESTORE targets FROM LATEST IN partitioned_backup opt_as_of_clause opt_with_options
+  {
+    $$.val = &tree.Restore{Targets: $2.targetList(), From: []tree.PartitionedBackup{$6.partitionedBackup()}, FromLatest: true, AsOf: $7.asOfClause(), Options: $8.kvOptions()}
+  }
 | RESTORE error // SHOW HELP: RESTORE
 
 partitioned_backup:
@@ -1899,8 +1936,10 @@ import_format:
 //        [ WITH <option> [= <value>] [, ...] ]
 //
 // Formats:
+//    AVRO
 //    CSV
 //    DELIMITED
+//    JSONL
 //    MYSQLDUMP
 //    PGCOPY
 //    PGDUMP
@@ -2210,12 +2249,13 @@ comment_text:
 // %Text:
 // CREATE DATABASE, CREATE TABLE, CREATE INDEX, CREATE TABLE AS,
 // CREATE USER, CREATE VIEW, CREATE SEQUENCE, CREATE STATISTICS,
-// CREATE ROLE
+// CREATE ROLE, CREATE SCHEDULE FOR BACKUP
 create_stmt:
   create_user_stmt     // EXTEND WITH HELP: CREATE USER
 | create_role_stmt     // EXTEND WITH HELP: CREATE ROLE
 | create_ddl_stmt      // help texts in sub-rule
 | create_stats_stmt    // EXTEND WITH HELP: CREATE STATISTICS
+| create_schedule_for_backup_stmt // EXTEND WITH HELP: CREATE SCHEDULE FOR BACKUP
 | create_unsupported   {}
 | CREATE error         // SHOW HELP: CREATE
 
@@ -2288,6 +2328,77 @@ create_ddl_stmt:
 | create_view_stmt     // EXTEND WITH HELP: CREATE VIEW
 | create_sequence_stmt // EXTEND WITH HELP: CREATE SEQUENCE
 
+// %Help: CREATE SCHEDULE FOR BACKUP - backup data periodically
+// %Category: CCL
+// %Text:
+// CREATE SCHEDULE [<label>]
+// FOR BACKUP <targets...> INTO <location...>
+// [ WITH <option> [= <value>] [, ...] ]
+// RECURRING <crontab>
+// [ FULL BACKUP { <crontab> | ALWAYS } ]
+//
+// Targets:
+//    TABLE <pattern> [, ...]
+//    DATABASE <databasename> [, ...]
+//
+// Location:
+//    "[scheme]://[host]/[path to backup collection]?[parameters]"
+//
+// Crontab:
+//    A cron expression, such as '0 * * * *', or one of the shortcuts
+//    '@hourly', '@daily', '@weekly', '@monthly' and '@yearly'.
+//
+// Unless FULL BACKUP ALWAYS is specified, the RECURRING schedule appends
+// incremental backups to the latest full backup, taken by the FULL BACKUP
+// schedule, or weekly if the recurrence is more frequent than weekly.
+//
+// %SeeAlso: BACKUP, SHOW SCHEDULES, PAUSE SCHEDULE, RESUME SCHEDULE, DROP SCHEDULE
+create_schedule_for_backup_stmt:
+  CREATE SCHEDULE opt_schedule_label FOR BACKUP targets INTO partitioned_backup opt_with_options RECURRING string_or_placeholder opt_full_backup_clause
+  {
+    $$.val = &tree.ScheduledBackup{
+      ScheduleName:  $3.expr(),
+      Targets:       $6.targetList(),
+      To:            $8.partitionedBackup(),
+      BackupOptions: $9.kvOptions(),
+      Recurrence:    $11.expr(),
+      FullBackup:    $12.fullBackupClause(),
+    }
+  }
+| CREATE SCHEDULE error // SHOW HELP: CREATE SCHEDULE FOR BACKUP
+
+opt_schedule_label:
+  string_or_placeholder
+  {
+    $$.val = $1.expr()
+  }
+| /* EMPTY */
+  {
+    $$.val = nil
+  }
+
+// The recurrence of full backups can't be a non-reserved word, unlike other
+// string_or_placeholder values, so that FULL BACKUP ALWAYS isn't ambiguous.
+opt_full_backup_clause:
+  FULL BACKUP SCONST
+  {
+    $$.val = &tree.FullBackupClause{Recurrence: tree.NewStrVal($3)}
+  }
+| FULL BACKUP PLACEHOLDER
+  {
+    p := $3.placeholder()
+    sqllex.(*lexer).UpdateNumPlaceholders(p)
+    $$.val = &tree.FullBackupClause{Recurrence: p}
+  }
+| FULL BACKUP ALWAYS
+  {
+    $$.val = &tree.FullBackupClause{AlwaysFull: true}
+  }
+| /* EMPTY */
+  {
+    $$.val = (*tree.FullBackupClause)(nil)
+  }
+
 // %Help: CREATE STATISTICS - create a new table statistic
 // %Category: Misc
 // %Text:
@@ -2392,6 +2503,14 @@ create_changefeed_stmt:
       Options: $6.kvOptions(),
     }
   }
+| CREATE CHANGEFEED opt_changefeed_sink opt_with_options AS simple_select_clause
+  {
+    $$.val = &tree.CreateChangefeed{
+      SinkURI: $3.expr(),
+      Options: $4.kvOptions(),
+      Select:  $6.selectStmt().(*tree.SelectClause),
+    }
+  }
 | EXPERIMENTAL CHANGEFEED FOR changefeed_targets opt_with_options
   {
     /* SKIP DOC */
@@ -2400,6 +2519,14 @@ create_changefeed_stmt:
       Options: $5.kvOptions(),
     }
   }
+| EXPERIMENTAL CHANGEFEED opt_with_options AS simple_select_clause
+  {
+    /* SKIP DOC */
+    $$.val = &tree.CreateChangefeed{
+      Options: $3.kvOptions(),
+      Select:  $5.selectStmt().(*tree.SelectClause),
+    }
+  }
 
 changefeed_targets:
   single_table_pattern_list
@@ -2410,6 +2537,10 @@ changefeed_targets:
   {
     $$.val = tree.TargetList{Tables: $2.tablePatterns()}
   }
+| DATABASE name_list
+  {
+    $$.val = tree.TargetList{Databases: $2.nameList()}
+  }
 
 single_table_pattern_list:
   table_name
@@ -2477,10 +2608,11 @@ discard_stmt:
 // %Category: Group
 // %Text:
 // DROP DATABASE, DROP INDEX, DROP TABLE, DROP VIEW, DROP SEQUENCE,
-// DROP USER, DROP ROLE
+// DROP USER, DROP ROLE, DROP SCHEDULE
 drop_stmt:
   drop_ddl_stmt      // help texts in sub-rule
 | drop_role_stmt     // EXTEND WITH HELP: DROP ROLE
+| drop_schedule_stmt // EXTEND WITH HELP: DROP SCHEDULE
 | drop_user_stmt     // EXTEND WITH HELP: DROP USER
 | drop_unsupported   {}
 | DROP error         // SHOW HELP: DROP
@@ -2492,6 +2624,17 @@ drop_ddl_stmt:
 | drop_view_stmt     // EXTEND WITH HELP: DROP VIEW
 | drop_sequence_stmt // EXTEND WITH HELP: DROP SEQUENCE
 
+// %Help: DROP SCHEDULE - remove a schedule
+// %Category: Misc
+// %Text: DROP SCHEDULE <scheduleid>
+// %SeeAlso: SHOW SCHEDULES, PAUSE SCHEDULE, RESUME SCHEDULE
+drop_schedule_stmt:
+  DROP SCHEDULE a_expr
+  {
+    $$.val = &tree.ControlSchedules{ScheduleID: $3.expr(), Command: tree.DropSchedule}
+  }
+| DROP SCHEDULE error // SHOW HELP: DROP SCHEDULE
+
 // %Help: DROP VIEW - remove a view
 // %Category: DDL
 // %Text: DROP VIEW [IF EXISTS] <tablename> [, ...] [CASCADE | RESTRICT]
@@ -2632,6 +2775,7 @@ table_name_list:
 // EXPLAIN ([PLAN ,] <planoptions...> ) <statement>
 // EXPLAIN [ANALYZE] (DISTSQL) <statement>
 // EXPLAIN ANALYZE [(DISTSQL)] <statement>
+// EXPLAIN ANALYZE (DEBUG) <statement>
 //
 // Explainable statements:
 //     SELECT, CREATE, DROP, ALTER, INSERT, UPSERT, UPDATE, DELETE,
@@ -2683,10 +2827,10 @@ preparable_stmt:
 | explain_stmt      // EXTEND WITH HELP: EXPLAIN
 | import_stmt       // EXTEND WITH HELP: IMPORT
 | insert_stmt       // EXTEND WITH HELP: INSERT
-| pause_stmt        // EXTEND WITH HELP: PAUSE JOBS
+| pause_stmt        // help texts in sub-rule
 | reset_stmt        // help texts in sub-rule
 | restore_stmt      // EXTEND WITH HELP: RESTORE
-| resume_stmt       // EXTEND WITH HELP: RESUME JOBS
+| resume_stmt       // help texts in sub-rule
 | export_stmt       // EXTEND WITH HELP: EXPORT
 | scrub_stmt        // help texts in sub-rule
 | select_stmt       // help texts in sub-rule
@@ -2747,6 +2891,32 @@ prepare_stmt:
   }
 | PREPARE error // SHOW HELP: PREPARE
 
+// %Help: PIN PLAN - pin the current plan of a statement
+// %Category: Misc
+// %Text: PIN PLAN FOR <query>
+//
+// The plan chosen for the statement is recorded, and later executions of
+// statements with the same fingerprint use the same indexes, join order and
+// join algorithms.
+// %SeeAlso: UNPIN PLAN, EXPLAIN
+pin_plan_stmt:
+  PIN PLAN FOR preparable_stmt
+  {
+    $$.val = &tree.PinPlan{Statement: $4.stmt()}
+  }
+| PIN error // SHOW HELP: PIN PLAN
+
+// %Help: UNPIN PLAN - remove the pinned plan of a statement
+// %Category: Misc
+// %Text: UNPIN PLAN FOR <query>
+// %SeeAlso: PIN PLAN
+unpin_plan_stmt:
+  UNPIN PLAN FOR preparable_stmt
+  {
+    $$.val = &tree.UnpinPlan{Statement: $4.stmt()}
+  }
+| UNPIN error // SHOW HELP: UNPIN PLAN
+
 prep_type_clause:
   '(' type_list ')'
   {
@@ -3324,7 +3494,7 @@ zone_value:
 // SHOW BACKUP, SHOW CLUSTER SETTING, SHOW COLUMNS, SHOW CONSTRAINTS,
 // SHOW CREATE, SHOW DATABASES, SHOW HISTOGRAM, SHOW INDEXES, SHOW
 // PARTITIONS, SHOW JOBS, SHOW QUERIES, SHOW RANGE, SHOW RANGES,
-// SHOW ROLES, SHOW SCHEMAS, SHOW SEQUENCES, SHOW SESSION, SHOW SESSIONS,
+// SHOW ROLES, SHOW SCHEDULES, SHOW SCHEMAS, SHOW SEQUENCES, SHOW SESSION, SHOW SESSIONS,
 // SHOW STATISTICS, SHOW SYNTAX, SHOW TABLES, SHOW TRACE SHOW TRANSACTION, SHOW USERS
 show_stmt:
   show_backup_stmt          // EXTEND WITH HELP: SHOW BACKUP
@@ -3343,6 +3513,7 @@ show_stmt:
 | show_ranges_stmt          // EXTEND WITH HELP: SHOW RANGES
 | show_range_for_row_stmt
 | show_roles_stmt           // EXTEND WITH HELP: SHOW ROLES
+| show_schedules_stmt       // EXTEND WITH HELP: SHOW SCHEDULES
 | show_schemas_stmt         // EXTEND WITH HELP: SHOW SCHEMAS
 | show_sequences_stmt       // EXTEND WITH HELP: SHOW SEQUENCES
 | show_session_stmt         // EXTEND WITH HELP: SHOW SESSION
@@ -3423,40 +3594,54 @@ show_histogram_stmt:
 
 // %Help: SHOW BACKUP - list backup contents
 // %Category: CCL
-// %Text: SHOW BACKUP [SCHEMAS|FILES|RANGES] <location>
+// %Text:
+// SHOW BACKUP [SCHEMAS|FILES|RANGES] <location> [WITH <option> [= <value>] [, ...]]
+// SHOW BACKUPS IN <collection>
 // %SeeAlso: WEBDOCS/show-backup.html
 show_backup_stmt:
-  SHOW BACKUP string_or_placeholder
+  SHOW BACKUPS IN string_or_placeholder
+  {
+    $$.val = &tree.ShowBackup{
+      Path:         $4.expr(),
+      InCollection: true,
+    }
+  }
+| SHOW BACKUP string_or_placeholder opt_with_options
   {
     $$.val = &tree.ShowBackup{
       Details: tree.BackupDefaultDetails,
       Path:    $3.expr(),
+      Options: $4.kvOptions(),
     }
   }
-| SHOW BACKUP SCHEMAS string_or_placeholder
+| SHOW BACKUP SCHEMAS string_or_placeholder opt_with_options
   {
     $$.val = &tree.ShowBackup{
       Details: tree.BackupDefaultDetails,
       ShouldIncludeSchemas: true,
       Path:    $4.expr(),
+      Options: $5.kvOptions(),
     }
   }
-| SHOW BACKUP RANGES string_or_placeholder
+| SHOW BACKUP RANGES string_or_placeholder opt_with_options
   {
     /* SKIP DOC */
     $$.val = &tree.ShowBackup{
       Details: tree.BackupRangeDetails,
       Path:    $4.expr(),
+      Options: $5.kvOptions(),
     }
   }
-| SHOW BACKUP FILES string_or_placeholder
+| SHOW BACKUP FILES string_or_placeholder opt_with_options
   {
     /* SKIP DOC */
     $$.val = &tree.ShowBackup{
       Details: tree.BackupFileDetails,
       Path:    $4.expr(),
+      Options: $5.kvOptions(),
     }
   }
+| SHOW BACKUPS error // SHOW HELP: SHOW BACKUP
 | SHOW BACKUP error // SHOW HELP: SHOW BACKUP
 
 // %Help: SHOW CLUSTER SETTING - display cluster settings
@@ -3674,6 +3859,24 @@ show_jobs_stmt:
   }
 | SHOW JOB error // SHOW HELP: SHOW JOBS
 
+// %Help: SHOW SCHEDULES - list schedules
+// %Category: Misc
+// %Text:
+// SHOW SCHEDULES
+// SHOW SCHEDULE <scheduleid>
+// %SeeAlso: PAUSE SCHEDULE, RESUME SCHEDULE, DROP SCHEDULE
+show_schedules_stmt:
+  SHOW SCHEDULES
+  {
+    $$.val = &tree.ShowSchedules{}
+  }
+| SHOW SCHEDULES error // SHOW HELP: SHOW SCHEDULES
+| SHOW SCHEDULE a_expr
+  {
+    $$.val = &tree.ShowSchedules{ScheduleID: $3.expr()}
+  }
+| SHOW SCHEDULE error // SHOW HELP: SHOW SCHEDULES
+
 // %Help: SHOW TRACE - display an execution trace
 // %Category: Misc
 // %Text:
@@ -4166,13 +4369,21 @@ for_grantee_clause:
     $$.val = tree.NameList(nil)
   }
 
+// %Help: PAUSE
+// %Category: Group
+// %Text: PAUSE JOBS, PAUSE SCHEDULE
+pause_stmt:
+  pause_jobs_stmt     // EXTEND WITH HELP: PAUSE JOBS
+| pause_schedule_stmt // EXTEND WITH HELP: PAUSE SCHEDULE
+| PAUSE error         // SHOW HELP: PAUSE
+
 // %Help: PAUSE JOBS - pause background jobs
 // %Category: Misc
 // %Text:
 // PAUSE JOBS <selectclause>
 // PAUSE JOB <jobid>
 // %SeeAlso: SHOW JOBS, CANCEL JOBS, RESUME JOBS
-pause_stmt:
+pause_jobs_stmt:
   PAUSE JOB a_expr
   {
     $$.val = &tree.ControlJobs{
@@ -4186,7 +4397,17 @@ pause_stmt:
   {
     $$.val = &tree.ControlJobs{Jobs: $3.slct(), Command: tree.PauseJob}
   }
-| PAUSE error // SHOW HELP: PAUSE JOBS
+
+// %Help: PAUSE SCHEDULE - pause a schedule
+// %Category: Misc
+// %Text: PAUSE SCHEDULE <scheduleid>
+// %SeeAlso: SHOW SCHEDULES, RESUME SCHEDULE, DROP SCHEDULE
+pause_schedule_stmt:
+  PAUSE SCHEDULE a_expr
+  {
+    $$.val = &tree.ControlSchedules{ScheduleID: $3.expr(), Command: tree.PauseSchedule}
+  }
+| PAUSE SCHEDULE error // SHOW HELP: PAUSE SCHEDULE
 
 // %Help: CREATE TABLE - create a new table
 // %Category: DDL
@@ -5337,13 +5558,21 @@ release_stmt:
   }
 | RELEASE error // SHOW HELP: RELEASE
 
+// %Help: RESUME
+// %Category: Group
+// %Text: RESUME JOBS, RESUME SCHEDULE
+resume_stmt:
+  resume_jobs_stmt     // EXTEND WITH HELP: RESUME JOBS
+| resume_schedule_stmt // EXTEND WITH HELP: RESUME SCHEDULE
+| RESUME error         // SHOW HELP: RESUME
+
 // %Help: RESUME JOBS - resume background jobs
 // %Category: Misc
 // %Text:
 // RESUME JOBS <selectclause>
 // RESUME JOB <jobid>
 // %SeeAlso: SHOW JOBS, CANCEL JOBS, PAUSE JOBS
-resume_stmt:
+resume_jobs_stmt:
   RESUME JOB a_expr
   {
     $$.val = &tree.ControlJobs{
@@ -5357,7 +5586,17 @@ resume_stmt:
   {
     $$.val = &tree.ControlJobs{Jobs: $3.slct(), Command: tree.ResumeJob}
   }
-| RESUME error // SHOW HELP: RESUME JOBS
+
+// %Help: RESUME SCHEDULE - resume a paused schedule
+// %Category: Misc
+// %Text: RESUME SCHEDULE <scheduleid>
+// %SeeAlso: SHOW SCHEDULES, PAUSE SCHEDULE, DROP SCHEDULE
+resume_schedule_stmt:
+  RESUME SCHEDULE a_expr
+  {
+    $$.val = &tree.ControlSchedules{ScheduleID: $3.expr(), Command: tree.ResumeSchedule}
+  }
+| RESUME SCHEDULE error // SHOW HELP: RESUME SCHEDULE
 
 // %Help: SAVEPOINT - start a retryable block
 // %Category: Txn
@@ -9480,10 +9719,12 @@ unreserved_keyword:
 | ADMIN
 | AGGREGATE
 | ALTER
+| ALWAYS
 | AT
 | AUTOMATIC
 | AUTHORIZATION
 | BACKUP
+| BACKUPS
 | BEGIN
 | BIGSERIAL
 | BLOB
@@ -9579,6 +9820,7 @@ unreserved_keyword:
 | KV
 | LANGUAGE
 | LAST
+| LATEST
 | LC_COLLATE
 | LC_CTYPE
 | LEASE
@@ -9626,6 +9868,7 @@ unreserved_keyword:
 | PASSWORD
 | PAUSE
 | PHYSICAL
+| PIN
 | PLAN
 | PLANS
 | PRECEDING
@@ -9638,6 +9881,7 @@ unreserved_keyword:
 | RANGE
 | RANGES
 | READ
+| RECURRING
 | RECURSIVE
 | REF
 | REGCLASS
@@ -9665,6 +9909,8 @@ unreserved_keyword:
 | STATUS
 | SAVEPOINT
 | SCATTER
+| SCHEDULE
+| SCHEDULES
 | SCHEMA
 | SCHEMAS
 | SCRUB
@@ -9718,6 +9964,7 @@ unreserved_keyword:
 | UNCOMMITTED
 | UNKNOWN
 | UNLOGGED
+| UNPIN
 | UNSPLIT
 | UPDATE
 | UPSERT
@@ -0,0 +1,141 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/optbuilder"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/xform"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/planpin"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+)
+
+type pinPlanNode struct {
+	optColumnsSlot
+
+	stmt tree.Statement
+
+	run pinPlanRun
+}
+
+// pinPlanRun contains the run-time state of pinPlanNode during local
+// execution.
+type pinPlanRun struct {
+	values tree.Datums
+	done   bool
+}
+
+// PinPlan records the outline of the plan currently chosen for a statement,
+// so that later executions of statements with the same fingerprint follow it.
+// Privileges: admin.
+func (p *planner) PinPlan(ctx context.Context, n *tree.PinPlan) (planNode, error) {
+	if err := p.checkPlanPinStatement(ctx, "pin plans", n.Statement); err != nil {
+		return nil, err
+	}
+	return &pinPlanNode{stmt: n.Statement}, nil
+}
+
+func (n *pinPlanNode) startExec(params runParams) error {
+	// Optimize the statement from scratch, ignoring any outline already pinned
+	// for it.
+	var catalog optCatalog
+	catalog.init(params.p)
+	catalog.reset()
+	var o xform.Optimizer
+	o.Init(params.EvalContext(), &catalog)
+	bld := optbuilder.New(
+		params.ctx, &params.p.semaCtx, params.EvalContext(), &catalog, o.Factory(), n.stmt,
+	)
+	if err := bld.Build(); err != nil {
+		return err
+	}
+	if _, err := o.Optimize(); err != nil {
+		return err
+	}
+	outline := xform.BuildPlanOutline(o.Memo())
+
+	fingerprint := planpin.Fingerprint(n.stmt)
+	registry := params.ExecCfg().PlanPinRegistry
+	if err := registry.Pin(params.ctx, params.p.txn, fingerprint, outline); err != nil {
+		return err
+	}
+	n.run.values = tree.Datums{
+		tree.NewDString(fingerprint),
+		tree.NewDString(outline.String()),
+	}
+	return nil
+}
+
+func (n *pinPlanNode) Next(params runParams) (bool, error) {
+	if n.run.done {
+		return false, nil
+	}
+	n.run.done = true
+	return true, nil
+}
+
+func (n *pinPlanNode) Values() tree.Datums     { return n.run.values }
+func (n *pinPlanNode) Close(_ context.Context) {}
+
+type unpinPlanNode struct {
+	stmt tree.Statement
+}
+
+// UnpinPlan removes the outline pinned for the fingerprint of a statement.
+// Privileges: admin.
+func (p *planner) UnpinPlan(ctx context.Context, n *tree.UnpinPlan) (planNode, error) {
+	if err := p.checkPlanPinStatement(ctx, "unpin plans", n.Statement); err != nil {
+		return nil, err
+	}
+	return &unpinPlanNode{stmt: n.Statement}, nil
+}
+
+func (n *unpinPlanNode) startExec(params runParams) error {
+	fingerprint := planpin.Fingerprint(n.stmt)
+	registry := params.ExecCfg().PlanPinRegistry
+	found, err := registry.Unpin(params.ctx, params.p.txn, fingerprint)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return pgerror.Newf(pgcode.UndefinedObject,
+			"no plan is pinned for statement %q", fingerprint)
+	}
+	return nil
+}
+
+func (n *unpinPlanNode) Next(runParams) (bool, error) { return false, nil }
+func (n *unpinPlanNode) Values() tree.Datums          { return tree.Datums{} }
+func (n *unpinPlanNode) Close(context.Context)        {}
+
+// checkPlanPinStatement verifies that the current user is allowed to manage
+// pinned plans, and that the plan of the given statement can be pinned.
+func (p *planner) checkPlanPinStatement(
+	ctx context.Context, action string, stmt tree.Statement,
+) error {
+	if err := p.RequireAdminRole(ctx, action); err != nil {
+		return err
+	}
+	if p.ExecCfg().PlanPinRegistry == nil {
+		return pgerror.New(pgcode.FeatureNotSupported, "pinned plans are not supported")
+	}
+	switch stmt.(type) {
+	case *tree.ParenSelect, *tree.Select, *tree.SelectClause, *tree.UnionClause, *tree.ValuesClause,
+		*tree.Insert, *tree.Update, *tree.Delete:
+		return nil
+	default:
+		return pgerror.Newf(pgcode.FeatureNotSupported,
+			"cannot pin the plan of %s statements", stmt.StatementTag())
+	}
+}
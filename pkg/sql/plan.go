@@ -15,6 +15,7 @@ import (
 
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/xform"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
@@ -168,6 +169,7 @@ var _ planNode = &joinNode{}
 var _ planNode = &limitNode{}
 var _ planNode = &max1RowNode{}
 var _ planNode = &ordinalityNode{}
+var _ planNode = &pinPlanNode{}
 var _ planNode = &projectSetNode{}
 var _ planNode = &recursiveCTENode{}
 var _ planNode = &relocateNode{}
@@ -191,6 +193,7 @@ var _ planNode = &unsplitAllNode{}
 var _ planNode = &truncateNode{}
 var _ planNode = &unaryNode{}
 var _ planNode = &unionNode{}
+var _ planNode = &unpinPlanNode{}
 var _ planNode = &updateNode{}
 var _ planNode = &upsertNode{}
 var _ planNode = &valuesNode{}
@@ -206,6 +209,7 @@ var _ planNodeFastPath = &rowCountNode{}
 var _ planNodeFastPath = &serializeNode{}
 var _ planNodeFastPath = &setZoneConfigNode{}
 var _ planNodeFastPath = &controlJobsNode{}
+var _ planNodeFastPath = &controlSchedulesNode{}
 
 // planNodeRequireSpool serves as marker for nodes whose parent must
 // ensure that the node is fully run to completion (and the results
@@ -280,6 +284,14 @@ type planTop struct {
 	// avoidBuffering, when set, causes the execution to avoid buffering
 	// results.
 	avoidBuffering bool
+
+	// bundle is populated when a statement diagnostics bundle is being
+	// collected for this statement. See explain_bundle.go.
+	bundle *bundlePlanInfo
+
+	// pinnedPlan is the outline that the optimizer was asked to follow, if the
+	// plan of the statement is pinned. See PIN PLAN.
+	pinnedPlan *xform.PlanOutline
 }
 
 // postquery is a query tree that is executed after the main one. It can only
@@ -97,6 +97,8 @@ func getPlanColumns(plan planNode, mut bool) sqlbase.ResultColumns {
 		return n.getColumns(mut, sqlbase.ExplainDistSQLColumns)
 	case *explainVecNode:
 		return n.getColumns(mut, sqlbase.ExplainVecColumns)
+	case *pinPlanNode:
+		return n.getColumns(mut, sqlbase.PinPlanColumns)
 	case *relocateNode:
 		return n.getColumns(mut, sqlbase.AlterTableRelocateColumns)
 	case *scatterNode:
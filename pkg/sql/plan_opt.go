@@ -175,6 +175,10 @@ func (p *planner) makeOptimizerPlan(ctx context.Context) error {
 	result := plan.(*planTop)
 	result.AST = stmt.AST
 	result.flags = opc.flags
+	result.pinnedPlan = opc.pinnedPlan
+	if p.collectBundle {
+		result.bundle = collectBundlePlanInfo(ctx, result, execMemo, &opc.catalog)
+	}
 
 	cols := planColumns(result.plan)
 	if stmt.ExpectedTypes != nil {
@@ -206,6 +210,9 @@ type optPlanningCtx struct {
 	// allowMemoReuse is false.
 	useCache bool
 
+	// pinnedPlan is the outline pinned for the statement with PIN PLAN, if any.
+	pinnedPlan *xform.PlanOutline
+
 	flags planFlags
 }
 
@@ -249,6 +256,24 @@ func (opc *optPlanningCtx) reset() {
 		opc.allowMemoReuse = false
 		opc.useCache = false
 	}
+
+	opc.pinnedPlan = nil
+	if registry := p.execCfg.PlanPinRegistry; registry != nil {
+		ast := p.stmt.AST
+		if explain, ok := ast.(*tree.Explain); ok {
+			// EXPLAIN shows the plan that would be used for the statement, so it
+			// follows the outline pinned for the explained statement.
+			ast = explain.Statement
+		}
+		if outline := registry.Lookup(ast); outline != nil {
+			opc.pinnedPlan = outline
+			opc.optimizer.SetPlanOutline(outline)
+			// A reused memo could have been optimized without the outline, or
+			// before the plan was pinned.
+			opc.allowMemoReuse = false
+			opc.useCache = false
+		}
+	}
 }
 
 func (opc *optPlanningCtx) log(ctx context.Context, msg string) {
@@ -142,6 +142,21 @@ type planner struct {
 	// See EXECUTE .. DISCARD ROWS.
 	discardRows bool
 
+	// collectBundle is set if a statement diagnostics bundle is being collected
+	// for the current statement, either because of EXPLAIN ANALYZE (DEBUG) or
+	// because of a statement diagnostics request.
+	collectBundle bool
+
+	// explainBundle is set for EXPLAIN ANALYZE (DEBUG); the results of the
+	// statement are discarded and replaced with information about the bundle.
+	explainBundle bool
+
+	// boundedStaleness is set if the current statement is a bounded staleness
+	// read, in which case the plan is built at the present time and the read
+	// timestamp is negotiated before execution. The minimum timestamp bound is
+	// semaCtx.AsOfTimestamp.
+	boundedStaleness bool
+
 	// cancelChecker is used by planNodes to check for cancellation of the associated
 	// query.
 	cancelChecker *sqlbase.CancelChecker
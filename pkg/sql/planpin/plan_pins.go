@@ -0,0 +1,177 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+// Package planpin implements the bookkeeping for pinned plans: an operator
+// records the outline of a known-good plan for a statement fingerprint in
+// system.statement_plan_pins, and every node then asks the optimizer to follow
+// that outline when planning statements with the same fingerprint.
+package planpin
+
+import (
+	"context"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/xform"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlutil"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/errors"
+)
+
+var pollingInterval = settings.RegisterNonNegativeDurationSetting(
+	"sql.plan_pins.poll_interval",
+	"rate at which the planpin.Registry polls for pinned plans, set to zero to disable",
+	10*time.Second,
+)
+
+// Fingerprint returns the statement fingerprint under which the plan of the
+// given statement is pinned. It is the same fingerprint used by the statement
+// statistics and the statement diagnostics requests.
+func Fingerprint(ast tree.Statement) string {
+	return tree.AsStringWithFlags(ast, tree.FmtHideConstants)
+}
+
+// Registry maintains a view on the pinned plans (i.e. the rows of
+// system.statement_plan_pins) and provides utilities for looking up the
+// outline of a statement and for pinning and unpinning plans.
+type Registry struct {
+	mu struct {
+		syncutil.RWMutex
+		// outlines maps statement fingerprints to their pinned outline.
+		outlines map[string]*xform.PlanOutline
+	}
+	st *cluster.Settings
+	ie sqlutil.InternalExecutor
+}
+
+// NewRegistry constructs a new Registry.
+func NewRegistry(ie sqlutil.InternalExecutor, st *cluster.Settings) *Registry {
+	r := &Registry{
+		ie: ie,
+		st: st,
+	}
+	r.mu.outlines = make(map[string]*xform.PlanOutline)
+	return r
+}
+
+// Start starts the polling loop for the registry.
+func (r *Registry) Start(ctx context.Context, stopper *stop.Stopper) {
+	ctx, _ = stopper.WithCancelOnQuiesce(ctx)
+	stopper.RunWorker(ctx, r.poll)
+}
+
+func (r *Registry) poll(ctx context.Context) {
+	sqlutil.Poll(ctx, &r.st.SV, pollingInterval, "pinned plans", r.pollPins)
+}
+
+// pollPins reads system.statement_plan_pins and replaces the local view with
+// its contents.
+func (r *Registry) pollPins(ctx context.Context) error {
+	if !cluster.Version.IsActive(ctx, r.st, cluster.VersionStatementPlanPins) {
+		return nil
+	}
+	rows, err := r.ie.Query(ctx, "plan-pins-poll", nil, /* txn */
+		"SELECT fingerprint, outline::STRING FROM system.statement_plan_pins")
+	if err != nil {
+		return err
+	}
+
+	outlines := make(map[string]*xform.PlanOutline, len(rows))
+	for _, row := range rows {
+		fingerprint := string(*row[0].(*tree.DString))
+		outline, err := xform.ParsePlanOutline([]byte(*row[1].(*tree.DString)))
+		if err != nil {
+			log.Warningf(ctx, "ignoring invalid pinned plan for %q: %s", fingerprint, err)
+			continue
+		}
+		outlines[fingerprint] = outline
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.mu.outlines = outlines
+	return nil
+}
+
+// Lookup returns the outline pinned for the fingerprint of the given
+// statement, or nil if there is none.
+func (r *Registry) Lookup(ast tree.Statement) *xform.PlanOutline {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	// Don't compute the fingerprint if there are no pinned plans, which is the
+	// common case.
+	if len(r.mu.outlines) == 0 {
+		return nil
+	}
+	return r.mu.outlines[Fingerprint(ast)]
+}
+
+// Pin records the outline for the given statement fingerprint, replacing any
+// outline previously pinned for it. The change is visible to the local node
+// once the transaction commits; the other nodes notice it on their next poll.
+func (r *Registry) Pin(
+	ctx context.Context, txn *client.Txn, fingerprint string, outline *xform.PlanOutline,
+) error {
+	if !cluster.Version.IsActive(ctx, r.st, cluster.VersionStatementPlanPins) {
+		return errors.New("pinned plans are not supported until the cluster is fully upgraded")
+	}
+	encoded, err := outline.JSON()
+	if err != nil {
+		return err
+	}
+	if _, err := r.ie.Exec(ctx, "plan-pins-upsert", txn,
+		"UPSERT INTO system.statement_plan_pins (fingerprint, outline, created_at) "+
+			"VALUES ($1, $2::JSONB, $3)",
+		fingerprint, string(encoded), tree.MakeDTimestampTZ(timeutil.Now(), time.Microsecond),
+	); err != nil {
+		return err
+	}
+
+	r.afterCommit(txn, func() { r.mu.outlines[fingerprint] = outline })
+	return nil
+}
+
+// Unpin removes the outline pinned for the given statement fingerprint. It
+// returns false if there was none.
+func (r *Registry) Unpin(ctx context.Context, txn *client.Txn, fingerprint string) (bool, error) {
+	if !cluster.Version.IsActive(ctx, r.st, cluster.VersionStatementPlanPins) {
+		return false, errors.New("pinned plans are not supported until the cluster is fully upgraded")
+	}
+	n, err := r.ie.Exec(ctx, "plan-pins-delete", txn,
+		"DELETE FROM system.statement_plan_pins WHERE fingerprint = $1", fingerprint)
+	if err != nil {
+		return false, err
+	}
+
+	r.afterCommit(txn, func() { delete(r.mu.outlines, fingerprint) })
+	return n > 0, nil
+}
+
+// afterCommit applies the given change to the local view once the transaction
+// commits, so that the view never reflects a pin which is rolled back. The
+// change is applied right away if there is no transaction.
+func (r *Registry) afterCommit(txn *client.Txn, fn func()) {
+	apply := func(context.Context) {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		fn()
+	}
+	if txn == nil {
+		apply(context.Background())
+		return
+	}
+	txn.AddCommitTrigger(apply)
+}
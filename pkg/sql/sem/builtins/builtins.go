@@ -1770,6 +1770,36 @@ return without an error.`,
 		},
 	),
 
+	tree.WithMaxStalenessFunctionName: makeBuiltin(
+		tree.FunctionProperties{Impure: true},
+		tree.Overload{
+			Types:      tree.ArgTypes{{"max_staleness", types.Interval}},
+			ReturnType: tree.FixedReturnType(types.TimestampTZ),
+			Fn: func(ctx *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+				return nil, errBoundedStalenessOutsideAsOf(tree.WithMaxStalenessFunctionName)
+			},
+			Info: `When used in the AS OF SYSTEM TIME clause of a single-statement SELECT,
+performs a bounded staleness read at the freshest timestamp no more than
+max_staleness old at which the read can be served by the nearest replicas without
+blocking. If no such timestamp exists, the read is served by the leaseholders.`,
+		},
+	),
+
+	tree.WithMinTimestampFunctionName: makeBuiltin(
+		tree.FunctionProperties{Impure: true},
+		tree.Overload{
+			Types:      tree.ArgTypes{{"min_timestamp", types.TimestampTZ}},
+			ReturnType: tree.FixedReturnType(types.TimestampTZ),
+			Fn: func(ctx *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+				return nil, errBoundedStalenessOutsideAsOf(tree.WithMinTimestampFunctionName)
+			},
+			Info: `When used in the AS OF SYSTEM TIME clause of a single-statement SELECT,
+performs a bounded staleness read at the freshest timestamp no older than
+min_timestamp at which the read can be served by the nearest replicas without
+blocking. If no such timestamp exists, the read is served by the leaseholders.`,
+		},
+	),
+
 	"cluster_logical_timestamp": makeBuiltin(
 		tree.FunctionProperties{
 			Category: categorySystemInfo,
@@ -5193,6 +5223,11 @@ func checkPrivilegedUser(ctx *tree.EvalContext) error {
 // if an enterprise license is not installed.
 var EvalFollowerReadOffset func(clusterID uuid.UUID, _ *cluster.Settings) (time.Duration, error)
 
+func errBoundedStalenessOutsideAsOf(name string) error {
+	return pgerror.Newf(pgcode.FeatureNotSupported,
+		"%s can only be used in an AS OF SYSTEM TIME clause", name)
+}
+
 func recentTimestamp(ctx *tree.EvalContext) (time.Time, error) {
 	if EvalFollowerReadOffset == nil {
 		return time.Time{}, pgerror.New(pgcode.FeatureNotSupported,
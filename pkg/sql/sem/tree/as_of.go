@@ -29,13 +29,58 @@ import (
 // reads.
 const FollowerReadTimestampFunctionName = "experimental_follower_read_timestamp"
 
-var errInvalidExprForAsOf = errors.Errorf("AS OF SYSTEM TIME: only constant expressions or " +
-	FollowerReadTimestampFunctionName + " are allowed")
+// WithMaxStalenessFunctionName is the name of the function which can be used
+// with AOST clauses to perform a bounded staleness read no older than the
+// given interval before the statement timestamp.
+const WithMaxStalenessFunctionName = "with_max_staleness"
 
-// EvalAsOfTimestamp evaluates the timestamp argument to an AS OF SYSTEM TIME query.
+// WithMinTimestampFunctionName is the name of the function which can be used
+// with AOST clauses to perform a bounded staleness read at or above the given
+// timestamp.
+const WithMinTimestampFunctionName = "with_min_timestamp"
+
+var errInvalidExprForAsOf = errors.Errorf("AS OF SYSTEM TIME: only constant expressions, " +
+	FollowerReadTimestampFunctionName + ", " + WithMaxStalenessFunctionName + " or " +
+	WithMinTimestampFunctionName + " are allowed")
+
+// ErrBoundedStalenessNotSupported is returned when a bounded staleness AS OF
+// SYSTEM TIME clause is used outside of a single-statement SELECT in an
+// implicit transaction.
+var ErrBoundedStalenessNotSupported = pgerror.Newf(pgcode.FeatureNotSupported,
+	"AS OF SYSTEM TIME: %s and %s are only supported by SELECT statements in "+
+		"implicit transactions", WithMaxStalenessFunctionName, WithMinTimestampFunctionName)
+
+// AsOfSystemTime is the result of evaluating an AS OF SYSTEM TIME clause.
+type AsOfSystemTime struct {
+	// Timestamp is the timestamp of the read. For bounded staleness reads, it
+	// is the minimum timestamp bound; the actual read timestamp is negotiated
+	// later on and may be anywhere between it and the present.
+	Timestamp hlc.Timestamp
+	// BoundedStaleness is set if the clause uses with_max_staleness or
+	// with_min_timestamp.
+	BoundedStaleness bool
+}
+
+// EvalAsOfTimestamp evaluates the timestamp argument to an AS OF SYSTEM TIME
+// query. Bounded staleness clauses are rejected, see EvalAsOf.
 func EvalAsOfTimestamp(
 	asOf AsOfClause, semaCtx *SemaContext, evalCtx *EvalContext,
-) (tsss hlc.Timestamp, err error) {
+) (hlc.Timestamp, error) {
+	asOfTime, err := EvalAsOf(asOf, semaCtx, evalCtx)
+	if err != nil {
+		return hlc.Timestamp{}, err
+	}
+	if asOfTime.BoundedStaleness {
+		return hlc.Timestamp{}, ErrBoundedStalenessNotSupported
+	}
+	return asOfTime.Timestamp, nil
+}
+
+// EvalAsOf evaluates an AS OF SYSTEM TIME clause, which may request a bounded
+// staleness read.
+func EvalAsOf(
+	asOf AsOfClause, semaCtx *SemaContext, evalCtx *EvalContext,
+) (AsOfSystemTime, error) {
 	// We need to save and restore the previous value of the field in
 	// semaCtx in case we are recursively called within a subquery
 	// context.
@@ -44,7 +89,8 @@ func EvalAsOfTimestamp(
 	scalarProps.Require("AS OF SYSTEM TIME", RejectSpecial|RejectSubqueries)
 
 	// In order to support the follower reads feature we permit this expression
-	// to be a simple invocation of the `FollowerReadTimestampFunction`.
+	// to be a simple invocation of the `FollowerReadTimestampFunction`, or of
+	// one of the bounded staleness functions.
 	// Over time we could expand the set of allowed functions or expressions.
 	// All non-function expressions must be const and must TypeCheck into a
 	// string.
@@ -52,33 +98,85 @@ func EvalAsOfTimestamp(
 	if fe, ok := asOf.Expr.(*FuncExpr); ok {
 		def, err := fe.Func.Resolve(semaCtx.SearchPath)
 		if err != nil {
-			return hlc.Timestamp{}, errInvalidExprForAsOf
+			return AsOfSystemTime{}, errInvalidExprForAsOf
 		}
-		if def.Name != FollowerReadTimestampFunctionName {
-			return hlc.Timestamp{}, errInvalidExprForAsOf
+		switch def.Name {
+		case FollowerReadTimestampFunctionName:
+		case WithMaxStalenessFunctionName, WithMinTimestampFunctionName:
+			return evalBoundedStaleness(fe, def.Name, semaCtx, evalCtx)
+		default:
+			return AsOfSystemTime{}, errInvalidExprForAsOf
 		}
 		if te, err = fe.TypeCheck(semaCtx, types.TimestampTZ); err != nil {
-			return hlc.Timestamp{}, err
+			return AsOfSystemTime{}, err
 		}
 	} else {
 		var err error
 		te, err = asOf.Expr.TypeCheck(semaCtx, types.String)
 		if err != nil {
-			return hlc.Timestamp{}, err
+			return AsOfSystemTime{}, err
 		}
 		if !IsConst(evalCtx, te) {
-			return hlc.Timestamp{}, errInvalidExprForAsOf
+			return AsOfSystemTime{}, errInvalidExprForAsOf
 		}
 	}
 
 	d, err := te.Eval(evalCtx)
 	if err != nil {
-		return hlc.Timestamp{}, err
+		return AsOfSystemTime{}, err
 	}
 
 	stmtTimestamp := evalCtx.GetStmtTimestamp()
 	ts, err := DatumToHLC(evalCtx, stmtTimestamp, d)
-	return ts, errors.Wrap(err, "AS OF SYSTEM TIME")
+	return AsOfSystemTime{Timestamp: ts}, errors.Wrap(err, "AS OF SYSTEM TIME")
+}
+
+// evalBoundedStaleness evaluates the minimum timestamp bound of an invocation
+// of with_max_staleness or with_min_timestamp. The functions themselves can't
+// be evaluated; only their constant argument is.
+func evalBoundedStaleness(
+	fe *FuncExpr, name string, semaCtx *SemaContext, evalCtx *EvalContext,
+) (AsOfSystemTime, error) {
+	typed, err := fe.TypeCheck(semaCtx, types.TimestampTZ)
+	if err != nil {
+		return AsOfSystemTime{}, err
+	}
+	typedFe, ok := typed.(*FuncExpr)
+	if !ok || len(typedFe.Exprs) != 1 {
+		return AsOfSystemTime{}, errInvalidExprForAsOf
+	}
+	arg := typedFe.Exprs[0].(TypedExpr)
+	if !IsConst(evalCtx, arg) {
+		return AsOfSystemTime{}, errInvalidExprForAsOf
+	}
+	d, err := arg.Eval(evalCtx)
+	if err != nil {
+		return AsOfSystemTime{}, err
+	}
+	if d == DNull {
+		return AsOfSystemTime{}, pgerror.Newf(pgcode.InvalidParameterValue,
+			"AS OF SYSTEM TIME: argument of %s cannot be NULL", name)
+	}
+
+	stmtTimestamp := evalCtx.GetStmtTimestamp()
+	var ts hlc.Timestamp
+	switch name {
+	case WithMaxStalenessFunctionName:
+		iv, ok := d.(*DInterval)
+		if !ok {
+			return AsOfSystemTime{}, errInvalidExprForAsOf
+		}
+		if iv.Duration.Compare(duration.Duration{}) < 0 {
+			return AsOfSystemTime{}, pgerror.Newf(pgcode.InvalidParameterValue,
+				"AS OF SYSTEM TIME: interval duration for %s must be greater or equal to 0", name)
+		}
+		ts.WallTime = duration.Add(evalCtx, stmtTimestamp, iv.Duration.Mul(-1)).UnixNano()
+	case WithMinTimestampFunctionName:
+		if ts, err = DatumToHLC(evalCtx, stmtTimestamp, d); err != nil {
+			return AsOfSystemTime{}, errors.Wrap(err, "AS OF SYSTEM TIME")
+		}
+	}
+	return AsOfSystemTime{Timestamp: ts, BoundedStaleness: true}, nil
 }
 
 // DatumToHLC performs the conversion from a Datum to an HLC timestamp.
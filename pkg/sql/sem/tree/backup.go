@@ -12,8 +12,14 @@ package tree
 
 // Backup represents a BACKUP statement.
 type Backup struct {
-	Targets         TargetList
-	To              PartitionedBackup
+	Targets TargetList
+	To      PartitionedBackup
+	// Nested is set for BACKUP ... INTO, in which case To is a collection in
+	// which the backup is written to a new subdirectory.
+	Nested bool
+	// AppendToLatest is set for BACKUP ... INTO LATEST IN, which appends an
+	// incremental backup to the latest full backup of the collection.
+	AppendToLatest  bool
 	IncrementalFrom Exprs
 	AsOf            AsOfClause
 	Options         KVOptions
@@ -25,7 +31,14 @@ var _ Statement = &Backup{}
 func (node *Backup) Format(ctx *FmtCtx) {
 	ctx.WriteString("BACKUP ")
 	ctx.FormatNode(&node.Targets)
-	ctx.WriteString(" TO ")
+	if node.Nested {
+		ctx.WriteString(" INTO ")
+		if node.AppendToLatest {
+			ctx.WriteString("LATEST IN ")
+		}
+	} else {
+		ctx.WriteString(" TO ")
+	}
 	ctx.FormatNode(&node.To)
 	if node.AsOf.Expr != nil {
 		ctx.WriteString(" ")
@@ -45,8 +58,11 @@ func (node *Backup) Format(ctx *FmtCtx) {
 type Restore struct {
 	Targets TargetList
 	From    []PartitionedBackup
-	AsOf    AsOfClause
-	Options KVOptions
+	// FromLatest is set for RESTORE ... FROM LATEST IN, in which case From
+	// holds a single collection whose latest backup is restored.
+	FromLatest bool
+	AsOf       AsOfClause
+	Options    KVOptions
 }
 
 var _ Statement = &Restore{}
@@ -56,6 +72,9 @@ func (node *Restore) Format(ctx *FmtCtx) {
 	ctx.WriteString("RESTORE ")
 	ctx.FormatNode(&node.Targets)
 	ctx.WriteString(" FROM ")
+	if node.FromLatest {
+		ctx.WriteString("LATEST IN ")
+	}
 	for i := range node.From {
 		if i > 0 {
 			ctx.WriteString(", ")
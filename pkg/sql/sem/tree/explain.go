@@ -88,6 +88,10 @@ const (
 	// ExplainVec shows the physical vectorized plan for a query and whether a
 	// query would be run in "auto" vectorized mode.
 	ExplainVec
+
+	// ExplainDebug generates a statement diagnostics bundle; only valid with
+	// ANALYZE and only as a top-level statement.
+	ExplainDebug
 )
 
 var explainModeStrings = map[string]ExplainMode{
@@ -95,6 +99,7 @@ var explainModeStrings = map[string]ExplainMode{
 	"distsql": ExplainDistSQL,
 	"opt":     ExplainOpt,
 	"vec":     ExplainVec,
+	"debug":   ExplainDebug,
 }
 
 // ExplainModeName returns the human-readable name of a given ExplainMode.
@@ -0,0 +1,33 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package tree
+
+// PinPlan represents a PIN PLAN FOR statement.
+type PinPlan struct {
+	Statement Statement
+}
+
+// Format implements the NodeFormatter interface.
+func (node *PinPlan) Format(ctx *FmtCtx) {
+	ctx.WriteString("PIN PLAN FOR ")
+	ctx.FormatNode(node.Statement)
+}
+
+// UnpinPlan represents an UNPIN PLAN FOR statement.
+type UnpinPlan struct {
+	Statement Statement
+}
+
+// Format implements the NodeFormatter interface.
+func (node *UnpinPlan) Format(ctx *FmtCtx) {
+	ctx.WriteString("UNPIN PLAN FOR ")
+	ctx.FormatNode(node.Statement)
+}
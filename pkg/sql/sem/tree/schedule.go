@@ -0,0 +1,108 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package tree
+
+// FullBackupClause describes how often full backups are taken by a backup
+// schedule.
+type FullBackupClause struct {
+	AlwaysFull bool
+	Recurrence Expr
+}
+
+// ScheduledBackup represents a CREATE SCHEDULE FOR BACKUP statement.
+type ScheduledBackup struct {
+	// ScheduleName is nil if the schedule isn't named.
+	ScheduleName Expr
+	Recurrence   Expr
+	// FullBackup is nil if the statement doesn't specify how often full
+	// backups are taken.
+	FullBackup    *FullBackupClause
+	Targets       TargetList
+	To            PartitionedBackup
+	BackupOptions KVOptions
+}
+
+var _ Statement = &ScheduledBackup{}
+
+// Format implements the NodeFormatter interface.
+func (node *ScheduledBackup) Format(ctx *FmtCtx) {
+	ctx.WriteString("CREATE SCHEDULE ")
+	if node.ScheduleName != nil {
+		ctx.FormatNode(node.ScheduleName)
+		ctx.WriteString(" ")
+	}
+	ctx.WriteString("FOR BACKUP ")
+	ctx.FormatNode(&node.Targets)
+	ctx.WriteString(" INTO ")
+	ctx.FormatNode(&node.To)
+	if node.BackupOptions != nil {
+		ctx.WriteString(" WITH ")
+		ctx.FormatNode(&node.BackupOptions)
+	}
+	ctx.WriteString(" RECURRING ")
+	ctx.FormatNode(node.Recurrence)
+	if node.FullBackup != nil {
+		ctx.WriteString(" FULL BACKUP ")
+		if node.FullBackup.AlwaysFull {
+			ctx.WriteString("ALWAYS")
+		} else {
+			ctx.FormatNode(node.FullBackup.Recurrence)
+		}
+	}
+}
+
+// ControlSchedules represents a PAUSE/RESUME/DROP SCHEDULE statement.
+type ControlSchedules struct {
+	ScheduleID Expr
+	Command    ScheduleCommand
+}
+
+// ScheduleCommand determines which type of action to effect on the selected
+// schedule.
+type ScheduleCommand int
+
+// ScheduleCommand values
+const (
+	PauseSchedule ScheduleCommand = iota
+	ResumeSchedule
+	DropSchedule
+)
+
+// ScheduleCommandToStatement translates a schedule command integer to a
+// statement prefix.
+var ScheduleCommandToStatement = map[ScheduleCommand]string{
+	PauseSchedule:  "PAUSE",
+	ResumeSchedule: "RESUME",
+	DropSchedule:   "DROP",
+}
+
+// Format implements the NodeFormatter interface.
+func (n *ControlSchedules) Format(ctx *FmtCtx) {
+	ctx.WriteString(ScheduleCommandToStatement[n.Command])
+	ctx.WriteString(" SCHEDULE ")
+	ctx.FormatNode(n.ScheduleID)
+}
+
+// ShowSchedules represents a SHOW SCHEDULES or a SHOW SCHEDULE statement.
+type ShowSchedules struct {
+	// ScheduleID is nil for SHOW SCHEDULES.
+	ScheduleID Expr
+}
+
+// Format implements the NodeFormatter interface.
+func (n *ShowSchedules) Format(ctx *FmtCtx) {
+	if n.ScheduleID == nil {
+		ctx.WriteString("SHOW SCHEDULES")
+		return
+	}
+	ctx.WriteString("SHOW SCHEDULE ")
+	ctx.FormatNode(n.ScheduleID)
+}
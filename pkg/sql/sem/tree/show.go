@@ -81,15 +81,24 @@ const (
 	BackupFileDetails
 )
 
-// ShowBackup represents a SHOW BACKUP statement.
+// ShowBackup represents a SHOW BACKUP or SHOW BACKUPS IN statement.
 type ShowBackup struct {
-	Path                 Expr
+	Path Expr
+	// InCollection is set for SHOW BACKUPS IN, in which case Path is a
+	// collection whose backups are listed.
+	InCollection         bool
 	Details              BackupDetails
 	ShouldIncludeSchemas bool
+	Options              KVOptions
 }
 
 // Format implements the NodeFormatter interface.
 func (node *ShowBackup) Format(ctx *FmtCtx) {
+	if node.InCollection {
+		ctx.WriteString("SHOW BACKUPS IN ")
+		ctx.FormatNode(node.Path)
+		return
+	}
 	ctx.WriteString("SHOW BACKUP ")
 	if node.Details == BackupRangeDetails {
 		ctx.WriteString("RANGES ")
@@ -100,6 +109,10 @@ func (node *ShowBackup) Format(ctx *FmtCtx) {
 		ctx.WriteString("SCHEMAS ")
 	}
 	ctx.FormatNode(node.Path)
+	if len(node.Options) > 0 {
+		ctx.WriteString(" WITH ")
+		ctx.FormatNode(&node.Options)
+	}
 }
 
 // ShowColumns represents a SHOW COLUMNS statement.
@@ -917,6 +917,22 @@ func (stmt *ControlJobs) walkStmt(v Visitor) Statement {
 	return stmt
 }
 
+// copyNode makes a copy of this Statement without recursing in any child Statements.
+func (stmt *ControlSchedules) copyNode() *ControlSchedules {
+	stmtCopy := *stmt
+	return &stmtCopy
+}
+
+// walkStmt is part of the walkableStmt interface.
+func (stmt *ControlSchedules) walkStmt(v Visitor) Statement {
+	e, changed := WalkExpr(v, stmt.ScheduleID)
+	if changed {
+		stmt = stmt.copyNode()
+		stmt.ScheduleID = e
+	}
+	return stmt
+}
+
 // copyNode makes a copy of this Statement without recursing in any child Statements.
 func (stmt *Import) copyNode() *Import {
 	stmtCopy := *stmt
@@ -1358,6 +1374,7 @@ var _ walkableStmt = &ValuesClause{}
 var _ walkableStmt = &CancelQueries{}
 var _ walkableStmt = &CancelSessions{}
 var _ walkableStmt = &ControlJobs{}
+var _ walkableStmt = &ControlSchedules{}
 var _ walkableStmt = &BeginTransaction{}
 
 // walkStmt walks the entire parsed stmt calling WalkExpr on each
@@ -21,6 +21,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
@@ -59,6 +60,7 @@ var supportedZoneConfigOptions = map[tree.Name]struct {
 	"range_min_bytes": {types.Int, func(c *config.ZoneConfig, d tree.Datum) { c.RangeMinBytes = proto.Int64(int64(tree.MustBeDInt(d))) }},
 	"range_max_bytes": {types.Int, func(c *config.ZoneConfig, d tree.Datum) { c.RangeMaxBytes = proto.Int64(int64(tree.MustBeDInt(d))) }},
 	"num_replicas":    {types.Int, func(c *config.ZoneConfig, d tree.Datum) { c.NumReplicas = proto.Int32(int32(tree.MustBeDInt(d))) }},
+	"num_voters":      {types.Int, func(c *config.ZoneConfig, d tree.Datum) { c.NumVoters = proto.Int32(int32(tree.MustBeDInt(d))) }},
 	"gc.ttlseconds": {types.Int, func(c *config.ZoneConfig, d tree.Datum) {
 		c.GC = &config.GCPolicy{TTLSeconds: int32(tree.MustBeDInt(d))}
 	}},
@@ -71,6 +73,11 @@ var supportedZoneConfigOptions = map[tree.Name]struct {
 		c.Constraints = constraintsList.Constraints
 		c.InheritedConstraints = false
 	}},
+	"voter_constraints": {types.String, func(c *config.ZoneConfig, d tree.Datum) {
+		var constraintsList config.ConstraintsList
+		loadYAML(&constraintsList, string(tree.MustBeDString(d)))
+		c.VoterConstraints = constraintsList.Constraints
+	}},
 	"lease_preferences": {types.String, func(c *config.ZoneConfig, d tree.Datum) {
 		loadYAML(&c.LeasePreferences, string(tree.MustBeDString(d)))
 		c.InheritedLeasePreferences = false
@@ -582,6 +589,14 @@ func (n *setZoneConfigNode) startExec(params runParams) error {
 				})
 			}
 
+			if (finalZone.NumVoters != nil || len(finalZone.VoterConstraints) > 0) &&
+				!cluster.Version.IsActive(
+					params.ctx, params.ExecCfg().Settings, cluster.VersionNonVotingReplicas) {
+				return pgerror.Newf(pgcode.FeatureNotSupported,
+					"num_voters and voter_constraints require all nodes to be upgraded to %s",
+					cluster.VersionByKey(cluster.VersionNonVotingReplicas))
+			}
+
 			// Finally revalidate everything. Validate only the completeZone config.
 			if err := completeZone.Validate(); err != nil {
 				return pgerror.Newf(pgcode.CheckViolation,
@@ -717,7 +732,8 @@ func validateNoRepeatKeysInZone(zone *config.ZoneConfig) error {
 func validateZoneAttrsAndLocalities(
 	ctx context.Context, getNodes nodeGetter, zone *config.ZoneConfig,
 ) error {
-	if len(zone.Constraints) == 0 && len(zone.LeasePreferences) == 0 {
+	if len(zone.Constraints) == 0 && len(zone.VoterConstraints) == 0 &&
+		len(zone.LeasePreferences) == 0 {
 		return nil
 	}
 
@@ -747,6 +763,11 @@ func validateZoneAttrsAndLocalities(
 			addToValidate(constraint)
 		}
 	}
+	for _, constraints := range zone.VoterConstraints {
+		for _, constraint := range constraints.Constraints {
+			addToValidate(constraint)
+		}
+	}
 	for _, leasePreferences := range zone.LeasePreferences {
 		for _, constraint := range leasePreferences.Constraints {
 			addToValidate(constraint)
@@ -52,6 +52,7 @@ const (
 	CrdbInternalBackwardDependenciesTableID
 	CrdbInternalBuildInfoTableID
 	CrdbInternalBuiltinFunctionsTableID
+	CrdbInternalClusterContentionEventsTableID
 	CrdbInternalClusterQueriesTableID
 	CrdbInternalClusterSessionsTableID
 	CrdbInternalClusterSettingsTableID
@@ -72,6 +73,7 @@ const (
 	CrdbInternalLocalMetricsTableID
 	CrdbInternalPartitionsTableID
 	CrdbInternalPredefinedCommentsTableID
+	CrdbInternalProtectedSpansTableID
 	CrdbInternalRangesNoLeasesTableID
 	CrdbInternalRangesViewID
 	CrdbInternalRuntimeInfoTableID
@@ -79,10 +81,12 @@ const (
 	CrdbInternalSessionTraceTableID
 	CrdbInternalSessionVariablesTableID
 	CrdbInternalStmtStatsTableID
+	CrdbInternalPersistedStmtStatsTableID
 	CrdbInternalTableColumnsTableID
 	CrdbInternalTableIndexesTableID
 	CrdbInternalTablesTableID
 	CrdbInternalTxnStatsTableID
+	CrdbInternalTxnFingerprintStatsTableID
 	CrdbInternalZonesTableID
 	InformationSchemaID
 	InformationSchemaAdministrableRoleAuthorizationsID
@@ -126,6 +126,12 @@ var ExplainVecColumns = ResultColumns{
 	{Name: "text", Typ: types.String},
 }
 
+// ExplainAnalyzeDebugColumns are the result columns of an
+// EXPLAIN ANALYZE (DEBUG) statement.
+var ExplainAnalyzeDebugColumns = ResultColumns{
+	{Name: "text", Typ: types.String},
+}
+
 // ShowTraceColumns are the result columns of a SHOW [KV] TRACE statement.
 var ShowTraceColumns = ResultColumns{
 	{Name: "timestamp", Typ: types.TimestampTZ},
@@ -177,6 +183,12 @@ var ShowFingerprintsColumns = ResultColumns{
 	{Name: "fingerprint", Typ: types.String},
 }
 
+// PinPlanColumns are the result columns of a PIN PLAN statement.
+var PinPlanColumns = ResultColumns{
+	{Name: "fingerprint", Typ: types.String},
+	{Name: "outline", Typ: types.String},
+}
+
 // AlterTableSplitColumns are the result columns of an
 // ALTER TABLE/INDEX .. SPLIT AT statement.
 var AlterTableSplitColumns = ResultColumns{
@@ -251,6 +251,90 @@ CREATE TABLE system.protected_ts_records (
    verified  BOOL NOT NULL DEFAULT (false),
    FAMILY "primary" (id, ts, meta_type, meta, num_spans, spans, verified)
 );`
+
+	// statement_statistics stores the statement statistics that each node
+	// collects in memory, persisted whenever the node resets them.
+	StatementStatisticsTableSchema = `
+CREATE TABLE system.statement_statistics (
+   aggregated_ts         TIMESTAMPTZ NOT NULL, -- start of the aggregation interval
+   node_id               INT8 NOT NULL,
+   fingerprint_id        INT8 NOT NULL,        -- hash of the statement key
+   agg_interval          INTERVAL NOT NULL,
+   app_name              STRING NOT NULL,
+   plan_hash             INT8 NOT NULL,        -- hash of the last sampled plan
+   key                   BYTES NOT NULL,       -- marshaled roachpb.StatementStatisticsKey
+   statistics            BYTES NOT NULL,       -- marshaled roachpb.StatementStatistics
+   service_lat_histogram INT8[] NOT NULL,
+   PRIMARY KEY (aggregated_ts, node_id, fingerprint_id),
+   FAMILY "primary" (aggregated_ts, node_id, fingerprint_id, agg_interval, app_name, plan_hash, key, statistics, service_lat_histogram)
+);`
+
+	// statement_bundle_chunks stores the chunks of the zip files produced by
+	// statement diagnostics; see statement_diagnostics.bundle_chunks.
+	StatementBundleChunksTableSchema = `
+CREATE TABLE system.statement_bundle_chunks (
+   id          INT8 PRIMARY KEY DEFAULT unique_rowid(),
+   description STRING,
+   data        BYTES NOT NULL,
+   FAMILY "primary" (id, description, data)
+);`
+
+	// statement_diagnostics_requests contains the fingerprints for which the
+	// next execution should be traced and bundled.
+	StatementDiagnosticsRequestsTableSchema = `
+CREATE TABLE system.statement_diagnostics_requests (
+   id                       INT8 DEFAULT unique_rowid() PRIMARY KEY NOT NULL,
+   completed                BOOL NOT NULL DEFAULT FALSE,
+   statement_fingerprint    STRING NOT NULL,
+   statement_diagnostics_id INT8,
+   requested_at             TIMESTAMPTZ NOT NULL,
+   INDEX completed_idx (completed, id) STORING (statement_fingerprint),
+   FAMILY "primary" (id, completed, statement_fingerprint, statement_diagnostics_id, requested_at)
+);`
+
+	// statement_diagnostics holds the diagnostics collected for a statement,
+	// either on request or through EXPLAIN ANALYZE (DEBUG).
+	StatementDiagnosticsTableSchema = `
+CREATE TABLE system.statement_diagnostics (
+   id                    INT8 PRIMARY KEY DEFAULT unique_rowid(),
+   statement_fingerprint STRING NOT NULL,
+   statement             STRING NOT NULL,
+   collected_at          TIMESTAMPTZ NOT NULL,
+   trace                 JSONB,
+   bundle_chunks         INT8[],
+   error                 STRING,
+   FAMILY "primary" (id, statement_fingerprint, statement, collected_at, trace, bundle_chunks, error)
+);`
+
+	// statement_plan_pins stores the plan outlines that the optimizer must
+	// follow for the statements with a given fingerprint.
+	StatementPlanPinsTableSchema = `
+CREATE TABLE system.statement_plan_pins (
+   fingerprint STRING NOT NULL PRIMARY KEY,
+   outline     JSONB NOT NULL,
+   created_at  TIMESTAMPTZ NOT NULL,
+   FAMILY "primary" (fingerprint, outline, created_at)
+);`
+
+	// scheduled_jobs stores the schedules of the jobs run periodically by the
+	// job scheduler. A paused schedule has no next_run. last_job_id is the
+	// job created by the last run of the schedule, if any.
+	ScheduledJobsTableSchema = `
+CREATE TABLE system.scheduled_jobs (
+   schedule_id     INT8 DEFAULT unique_rowid() PRIMARY KEY NOT NULL,
+   schedule_name   STRING NOT NULL,
+   created         TIMESTAMPTZ NOT NULL,
+   owner           STRING NOT NULL,
+   next_run        TIMESTAMPTZ,
+   schedule_expr   STRING NOT NULL,
+   executor_type   STRING NOT NULL,
+   execution_args  BYTES NOT NULL,
+   schedule_status STRING,
+   last_job_id     INT8,
+   INDEX next_run_idx (next_run),
+   FAMILY "primary" (schedule_id, schedule_name, created, owner, next_run, schedule_expr,
+                     executor_type, execution_args, schedule_status, last_job_id)
+);`
 )
 
 func pk(name string) IndexDescriptor {
@@ -299,6 +383,12 @@ var SystemAllowedPrivileges = map[ID]privilege.List{
 	keys.ReportsMetaTableID:                   privilege.ReadWriteData,
 	keys.ProtectedTimestampsMetaTableID:       privilege.ReadData,
 	keys.ProtectedTimestampsRecordsTableID:    privilege.ReadData,
+	keys.StatementStatisticsTableID:           privilege.ReadData,
+	keys.StatementBundleChunksTableID:         privilege.ReadWriteData,
+	keys.StatementDiagnosticsRequestsTableID:  privilege.ReadWriteData,
+	keys.StatementDiagnosticsTableID:          privilege.ReadWriteData,
+	keys.StatementPlanPinsTableID:             privilege.ReadWriteData,
+	keys.ScheduledJobsTableID:                 privilege.ReadWriteData,
 }
 
 // Helpers used to make some of the TableDescriptor literals below more concise.
@@ -1223,6 +1313,242 @@ var (
 		FormatVersion:  InterleavedFormatVersion,
 		NextMutationID: 1,
 	}
+
+	// StatementStatisticsTable is the descriptor for the persisted statement
+	// statistics table.
+	StatementStatisticsTable = TableDescriptor{
+		Name:     "statement_statistics",
+		ID:       keys.StatementStatisticsTableID,
+		ParentID: keys.SystemDatabaseID,
+		Version:  1,
+		Columns: []ColumnDescriptor{
+			{Name: "aggregated_ts", ID: 1, Type: *types.TimestampTZ},
+			{Name: "node_id", ID: 2, Type: *types.Int},
+			{Name: "fingerprint_id", ID: 3, Type: *types.Int},
+			{Name: "agg_interval", ID: 4, Type: *types.Interval},
+			{Name: "app_name", ID: 5, Type: *types.String},
+			{Name: "plan_hash", ID: 6, Type: *types.Int},
+			{Name: "key", ID: 7, Type: *types.Bytes},
+			{Name: "statistics", ID: 8, Type: *types.Bytes},
+			{Name: "service_lat_histogram", ID: 9, Type: *types.IntArray},
+		},
+		NextColumnID: 10,
+		Families: []ColumnFamilyDescriptor{
+			{
+				Name: "primary",
+				ColumnNames: []string{
+					"aggregated_ts", "node_id", "fingerprint_id", "agg_interval", "app_name",
+					"plan_hash", "key", "statistics", "service_lat_histogram",
+				},
+				ColumnIDs: []ColumnID{1, 2, 3, 4, 5, 6, 7, 8, 9},
+			},
+		},
+		NextFamilyID: 1,
+		PrimaryIndex: IndexDescriptor{
+			Name:        "primary",
+			ID:          1,
+			Version:     1,
+			Unique:      true,
+			ColumnNames: []string{"aggregated_ts", "node_id", "fingerprint_id"},
+			ColumnIDs:   []ColumnID{1, 2, 3},
+			ColumnDirections: []IndexDescriptor_Direction{
+				IndexDescriptor_ASC,
+				IndexDescriptor_ASC,
+				IndexDescriptor_ASC,
+			},
+		},
+		NextIndexID:    2,
+		Privileges:     NewCustomSuperuserPrivilegeDescriptor(SystemAllowedPrivileges[keys.StatementStatisticsTableID]),
+		FormatVersion:  InterleavedFormatVersion,
+		NextMutationID: 1,
+	}
+
+	// StatementBundleChunksTable is the descriptor for the table storing the
+	// chunks of statement diagnostics bundles.
+	StatementBundleChunksTable = TableDescriptor{
+		Name:     "statement_bundle_chunks",
+		ID:       keys.StatementBundleChunksTableID,
+		ParentID: keys.SystemDatabaseID,
+		Version:  1,
+		Columns: []ColumnDescriptor{
+			{Name: "id", ID: 1, Type: *types.Int, DefaultExpr: &uniqueRowIDString},
+			{Name: "description", ID: 2, Type: *types.String, Nullable: true},
+			{Name: "data", ID: 3, Type: *types.Bytes},
+		},
+		NextColumnID: 4,
+		Families: []ColumnFamilyDescriptor{
+			{
+				Name:        "primary",
+				ColumnNames: []string{"id", "description", "data"},
+				ColumnIDs:   []ColumnID{1, 2, 3},
+			},
+		},
+		NextFamilyID:   1,
+		PrimaryIndex:   pk("id"),
+		NextIndexID:    2,
+		Privileges:     NewCustomSuperuserPrivilegeDescriptor(SystemAllowedPrivileges[keys.StatementBundleChunksTableID]),
+		FormatVersion:  InterleavedFormatVersion,
+		NextMutationID: 1,
+	}
+
+	// StatementDiagnosticsRequestsTable is the descriptor for the table of
+	// outstanding and completed statement diagnostics requests.
+	StatementDiagnosticsRequestsTable = TableDescriptor{
+		Name:     "statement_diagnostics_requests",
+		ID:       keys.StatementDiagnosticsRequestsTableID,
+		ParentID: keys.SystemDatabaseID,
+		Version:  1,
+		Columns: []ColumnDescriptor{
+			{Name: "id", ID: 1, Type: *types.Int, DefaultExpr: &uniqueRowIDString},
+			{Name: "completed", ID: 2, Type: *types.Bool, DefaultExpr: &falseBoolString},
+			{Name: "statement_fingerprint", ID: 3, Type: *types.String},
+			{Name: "statement_diagnostics_id", ID: 4, Type: *types.Int, Nullable: true},
+			{Name: "requested_at", ID: 5, Type: *types.TimestampTZ},
+		},
+		NextColumnID: 6,
+		Families: []ColumnFamilyDescriptor{
+			{
+				Name:        "primary",
+				ColumnNames: []string{"id", "completed", "statement_fingerprint", "statement_diagnostics_id", "requested_at"},
+				ColumnIDs:   []ColumnID{1, 2, 3, 4, 5},
+			},
+		},
+		NextFamilyID: 1,
+		PrimaryIndex: pk("id"),
+		// Index for the polling query.
+		Indexes: []IndexDescriptor{
+			{
+				Name:             "completed_idx",
+				ID:               2,
+				Unique:           false,
+				ColumnNames:      []string{"completed", "id"},
+				StoreColumnNames: []string{"statement_fingerprint"},
+				ColumnIDs:        []ColumnID{2, 1},
+				ColumnDirections: []IndexDescriptor_Direction{IndexDescriptor_ASC, IndexDescriptor_ASC},
+				StoreColumnIDs:   []ColumnID{3},
+				Version:          SecondaryIndexFamilyFormatVersion,
+			},
+		},
+		NextIndexID: 3,
+		Privileges: NewCustomSuperuserPrivilegeDescriptor(
+			SystemAllowedPrivileges[keys.StatementDiagnosticsRequestsTableID]),
+		FormatVersion:  InterleavedFormatVersion,
+		NextMutationID: 1,
+	}
+
+	// StatementDiagnosticsTable is the descriptor for the table holding the
+	// collected statement diagnostics.
+	StatementDiagnosticsTable = TableDescriptor{
+		Name:     "statement_diagnostics",
+		ID:       keys.StatementDiagnosticsTableID,
+		ParentID: keys.SystemDatabaseID,
+		Version:  1,
+		Columns: []ColumnDescriptor{
+			{Name: "id", ID: 1, Type: *types.Int, DefaultExpr: &uniqueRowIDString},
+			{Name: "statement_fingerprint", ID: 2, Type: *types.String},
+			{Name: "statement", ID: 3, Type: *types.String},
+			{Name: "collected_at", ID: 4, Type: *types.TimestampTZ},
+			{Name: "trace", ID: 5, Type: *types.Jsonb, Nullable: true},
+			{Name: "bundle_chunks", ID: 6, Type: *types.IntArray, Nullable: true},
+			{Name: "error", ID: 7, Type: *types.String, Nullable: true},
+		},
+		NextColumnID: 8,
+		Families: []ColumnFamilyDescriptor{
+			{
+				Name: "primary",
+				ColumnNames: []string{
+					"id", "statement_fingerprint", "statement",
+					"collected_at", "trace", "bundle_chunks", "error",
+				},
+				ColumnIDs: []ColumnID{1, 2, 3, 4, 5, 6, 7},
+			},
+		},
+		NextFamilyID:   1,
+		PrimaryIndex:   pk("id"),
+		NextIndexID:    2,
+		Privileges:     NewCustomSuperuserPrivilegeDescriptor(SystemAllowedPrivileges[keys.StatementDiagnosticsTableID]),
+		FormatVersion:  InterleavedFormatVersion,
+		NextMutationID: 1,
+	}
+
+	// StatementPlanPinsTable is the descriptor for the table of pinned plan
+	// outlines.
+	StatementPlanPinsTable = TableDescriptor{
+		Name:     "statement_plan_pins",
+		ID:       keys.StatementPlanPinsTableID,
+		ParentID: keys.SystemDatabaseID,
+		Version:  1,
+		Columns: []ColumnDescriptor{
+			{Name: "fingerprint", ID: 1, Type: *types.String},
+			{Name: "outline", ID: 2, Type: *types.Jsonb},
+			{Name: "created_at", ID: 3, Type: *types.TimestampTZ},
+		},
+		NextColumnID: 4,
+		Families: []ColumnFamilyDescriptor{
+			{
+				Name:        "primary",
+				ColumnNames: []string{"fingerprint", "outline", "created_at"},
+				ColumnIDs:   []ColumnID{1, 2, 3},
+			},
+		},
+		NextFamilyID:   1,
+		PrimaryIndex:   pk("fingerprint"),
+		NextIndexID:    2,
+		Privileges:     NewCustomSuperuserPrivilegeDescriptor(SystemAllowedPrivileges[keys.StatementPlanPinsTableID]),
+		FormatVersion:  InterleavedFormatVersion,
+		NextMutationID: 1,
+	}
+
+	// ScheduledJobsTable is the descriptor for the table of job schedules.
+	ScheduledJobsTable = TableDescriptor{
+		Name:     "scheduled_jobs",
+		ID:       keys.ScheduledJobsTableID,
+		ParentID: keys.SystemDatabaseID,
+		Version:  1,
+		Columns: []ColumnDescriptor{
+			{Name: "schedule_id", ID: 1, Type: *types.Int, DefaultExpr: &uniqueRowIDString},
+			{Name: "schedule_name", ID: 2, Type: *types.String},
+			{Name: "created", ID: 3, Type: *types.TimestampTZ},
+			{Name: "owner", ID: 4, Type: *types.String},
+			{Name: "next_run", ID: 5, Type: *types.TimestampTZ, Nullable: true},
+			{Name: "schedule_expr", ID: 6, Type: *types.String},
+			{Name: "executor_type", ID: 7, Type: *types.String},
+			{Name: "execution_args", ID: 8, Type: *types.Bytes},
+			{Name: "schedule_status", ID: 9, Type: *types.String, Nullable: true},
+			{Name: "last_job_id", ID: 10, Type: *types.Int, Nullable: true},
+		},
+		NextColumnID: 11,
+		Families: []ColumnFamilyDescriptor{
+			{
+				Name: "primary",
+				ColumnNames: []string{
+					"schedule_id", "schedule_name", "created", "owner", "next_run",
+					"schedule_expr", "executor_type", "execution_args", "schedule_status",
+					"last_job_id",
+				},
+				ColumnIDs: []ColumnID{1, 2, 3, 4, 5, 6, 7, 8, 9, 10},
+			},
+		},
+		NextFamilyID: 1,
+		PrimaryIndex: pk("schedule_id"),
+		// Index for the polling query of the job scheduler.
+		Indexes: []IndexDescriptor{
+			{
+				Name:             "next_run_idx",
+				ID:               2,
+				Unique:           false,
+				ColumnNames:      []string{"next_run"},
+				ColumnDirections: singleASC,
+				ColumnIDs:        []ColumnID{5},
+				ExtraColumnIDs:   []ColumnID{1},
+				Version:          SecondaryIndexFamilyFormatVersion,
+			},
+		},
+		NextIndexID:    3,
+		Privileges:     NewCustomSuperuserPrivilegeDescriptor(SystemAllowedPrivileges[keys.ScheduledJobsTableID]),
+		FormatVersion:  InterleavedFormatVersion,
+		NextMutationID: 1,
+	}
 )
 
 // Create a kv pair for the zone config for the given key and config value.
@@ -1275,6 +1601,12 @@ func addSystemDescriptorsToSchema(target *MetadataSchema) {
 	target.AddDescriptor(keys.SystemDatabaseID, &ReplicationCriticalLocalitiesTable)
 	target.AddDescriptor(keys.SystemDatabaseID, &ProtectedTimestampsMetaTable)
 	target.AddDescriptor(keys.SystemDatabaseID, &ProtectedTimestampsRecordsTable)
+	target.AddDescriptor(keys.SystemDatabaseID, &StatementStatisticsTable)
+	target.AddDescriptor(keys.SystemDatabaseID, &StatementBundleChunksTable)
+	target.AddDescriptor(keys.SystemDatabaseID, &StatementDiagnosticsRequestsTable)
+	target.AddDescriptor(keys.SystemDatabaseID, &StatementDiagnosticsTable)
+	target.AddDescriptor(keys.SystemDatabaseID, &StatementPlanPinsTable)
+	target.AddDescriptor(keys.SystemDatabaseID, &ScheduledJobsTable)
 }
 
 // addSystemDatabaseToSchema populates the supplied MetadataSchema with the
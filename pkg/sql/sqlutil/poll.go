@@ -0,0 +1,79 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sqlutil
+
+import (
+	"context"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+)
+
+// Poll calls pollFn at the rate given by the interval setting until the
+// context is canceled. It is meant to be run as a stopper worker by the
+// registries which keep a local view on a system table. Changes to the setting
+// take effect right away, and setting it to zero stops the polling until it is
+// set again. Errors returned by pollFn are logged, prefixed with what.
+//
+// Poll installs the OnChange callback of the interval setting, so each setting
+// can only be used by one polling loop.
+func Poll(
+	ctx context.Context,
+	sv *settings.Values,
+	interval *settings.DurationSetting,
+	what string,
+	pollFn func(context.Context) error,
+) {
+	var (
+		timer               timeutil.Timer
+		lastPoll            time.Time
+		deadline            time.Time
+		pollIntervalChanged = make(chan struct{}, 1)
+		maybeResetTimer     = func() {
+			if interval := interval.Get(sv); interval <= 0 {
+				// Setting the interval to a non-positive value stops the polling.
+				timer.Stop()
+			} else {
+				newDeadline := lastPoll.Add(interval)
+				if deadline.IsZero() || !deadline.Equal(newDeadline) {
+					deadline = newDeadline
+					timer.Reset(timeutil.Until(deadline))
+				}
+			}
+		}
+	)
+	interval.SetOnChange(sv, func() {
+		select {
+		case pollIntervalChanged <- struct{}{}:
+		default:
+		}
+	})
+	for {
+		maybeResetTimer()
+		select {
+		case <-pollIntervalChanged:
+			continue // go back around and maybe reset the timer
+		case <-timer.C:
+			timer.Read = true
+		case <-ctx.Done():
+			return
+		}
+		lastPoll = timeutil.Now()
+		if err := pollFn(ctx); err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			log.Warningf(ctx, "error polling for %s: %s", what, err)
+		}
+	}
+}
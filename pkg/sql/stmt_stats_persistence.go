@@ -0,0 +1,246 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/errors"
+)
+
+// stmtStatsPersistEnabled determines whether the collected statement
+// statistics are persisted to system.statement_statistics.
+var stmtStatsPersistEnabled = settings.RegisterPublicBoolSetting(
+	"sql.metrics.statement_details.persist.enabled",
+	"periodically persist collected statement statistics to system.statement_statistics",
+	true,
+)
+
+// stmtStatsPersistInterval is the interval at which the statement statistics
+// collected since the last reset are written out. Resetting the statistics
+// also writes them out.
+var stmtStatsPersistInterval = settings.RegisterPublicNonNegativeDurationSetting(
+	"sql.metrics.statement_details.persist.interval",
+	"the interval at which collected statement statistics are persisted",
+	10*time.Minute,
+)
+
+// stmtStatsPersistTTL is the amount of time after which persisted statement
+// statistics are deleted.
+var stmtStatsPersistTTL = settings.RegisterPublicNonNegativeDurationSetting(
+	"sql.metrics.statement_details.persist.ttl",
+	"the amount of time persisted statement statistics are retained; set to 0 to disable deletion",
+	14*24*time.Hour,
+)
+
+const (
+	// stmtStatsPersistBatchSize is the maximum number of rows written by a
+	// single UPSERT into system.statement_statistics.
+	stmtStatsPersistBatchSize = 100
+	// stmtStatsDeleteBatchSize is the maximum number of expired rows deleted
+	// from system.statement_statistics by a single statement.
+	stmtStatsDeleteBatchSize = 1000
+)
+
+// persistedStmtStats is a copy of the statistics collected for a statement
+// fingerprint, in the form in which they are stored in
+// system.statement_statistics.
+type persistedStmtStats struct {
+	key             roachpb.StatementStatisticsKey
+	fingerprintID   int64
+	planHash        int64
+	stats           roachpb.StatementStatistics
+	svcLatHistogram [numSvcLatBuckets]int64
+}
+
+// appendPersistedStmtStats appends a copy of the application's statement
+// statistics to dst. The caller must hold the appStats lock.
+func (a *appStats) appendPersistedStmtStats(
+	dst []persistedStmtStats, appName string,
+) []persistedStmtStats {
+	for key, s := range a.stmts {
+		p := persistedStmtStats{
+			key: roachpb.StatementStatisticsKey{
+				Query:       key.stmt,
+				App:         appName,
+				DistSQL:     key.distSQLUsed,
+				Failed:      key.failed,
+				Opt:         key.optUsed,
+				ImplicitTxn: key.implicitTxn,
+			},
+			fingerprintID: key.fingerprintID(appName),
+		}
+		s.Lock()
+		p.stats = s.data
+		p.planHash = s.planHash
+		p.svcLatHistogram = s.svcLatHistogram
+		s.Unlock()
+		dst = append(dst, p)
+	}
+	return dst
+}
+
+// getPersistedStmtStats returns a copy of all the statement statistics
+// collected since the last reset, along with the time of that reset.
+func (s *sqlStats) getPersistedStmtStats() (time.Time, []persistedStmtStats) {
+	s.Lock()
+	defer s.Unlock()
+	var ret []persistedStmtStats
+	for appName, a := range s.apps {
+		a.Lock()
+		ret = a.appendPersistedStmtStats(ret, appName)
+		a.Unlock()
+	}
+	return s.lastReset, ret
+}
+
+// PeriodicallyPersistSQLStats spawns a loop that periodically writes the
+// collected statement statistics to system.statement_statistics, and deletes
+// the persisted statistics that have outlived their TTL.
+func (s *Server) PeriodicallyPersistSQLStats(ctx context.Context, stopper *stop.Stopper) {
+	if s.cfg.InternalExecutor == nil {
+		return
+	}
+	stopper.RunWorker(ctx, func(ctx context.Context) {
+		var timer timeutil.Timer
+		defer timer.Stop()
+		for {
+			timer.Reset(stmtStatsPersistInterval.Get(&s.cfg.Settings.SV))
+			select {
+			case <-stopper.ShouldQuiesce():
+				return
+			case <-timer.C:
+				timer.Read = true
+			}
+			if !s.sqlStatsPersistenceEnabled(ctx) {
+				continue
+			}
+			aggregatedTS, stats := s.sqlStats.getPersistedStmtStats()
+			if err := s.persistSQLStats(ctx, aggregatedTS, stats); err != nil {
+				log.Warningf(ctx, "failed to persist statement statistics: %v", err)
+			}
+			if err := s.deleteExpiredSQLStats(ctx); err != nil {
+				log.Warningf(ctx, "failed to delete expired statement statistics: %v", err)
+			}
+		}
+	})
+}
+
+func (s *Server) sqlStatsPersistenceEnabled(ctx context.Context) bool {
+	return s.cfg.InternalExecutor != nil &&
+		stmtStatsPersistEnabled.Get(&s.cfg.Settings.SV) &&
+		cluster.Version.IsActive(ctx, s.cfg.Settings, cluster.VersionStatementStatisticsTable)
+}
+
+// persistSQLStats writes the given statement statistics, collected since
+// aggregatedTS, to system.statement_statistics. Statistics persisted earlier
+// for the same interval are overwritten.
+func (s *Server) persistSQLStats(
+	ctx context.Context, aggregatedTS time.Time, stats []persistedStmtStats,
+) error {
+	if len(stats) == 0 || aggregatedTS.IsZero() || !s.sqlStatsPersistenceEnabled(ctx) {
+		return nil
+	}
+	const numCols = 9
+	aggTS := tree.MakeDTimestampTZ(aggregatedTS, time.Microsecond)
+	aggInterval := timeutil.Since(aggregatedTS)
+	nodeID := int64(s.cfg.NodeID.Get())
+
+	var buf bytes.Buffer
+	for len(stats) > 0 {
+		batch := stats
+		if len(batch) > stmtStatsPersistBatchSize {
+			batch = batch[:stmtStatsPersistBatchSize]
+		}
+		stats = stats[len(batch):]
+
+		buf.Reset()
+		buf.WriteString(`UPSERT INTO system.statement_statistics (
+  aggregated_ts, node_id, fingerprint_id, agg_interval, app_name,
+  plan_hash, key, statistics, service_lat_histogram
+) VALUES `)
+		args := make([]interface{}, 0, len(batch)*numCols)
+		for i := range batch {
+			p := &batch[i]
+			key, err := protoutil.Marshal(&p.key)
+			if err != nil {
+				return err
+			}
+			data, err := protoutil.Marshal(&p.stats)
+			if err != nil {
+				return err
+			}
+			histogram := tree.NewDArray(types.Int)
+			for _, c := range p.svcLatHistogram {
+				if err := histogram.Append(tree.NewDInt(tree.DInt(c))); err != nil {
+					return err
+				}
+			}
+			if i > 0 {
+				buf.WriteString(", ")
+			}
+			buf.WriteByte('(')
+			for j := 0; j < numCols; j++ {
+				if j > 0 {
+					buf.WriteString(", ")
+				}
+				fmt.Fprintf(&buf, "$%d", len(args)+j+1)
+			}
+			buf.WriteByte(')')
+			args = append(args,
+				aggTS, nodeID, p.fingerprintID, aggInterval, p.key.App,
+				p.planHash, key, data, histogram,
+			)
+		}
+		if _, err := s.cfg.InternalExecutor.ExecWithUser(
+			ctx, "persist-stmt-stats", nil /* txn */, security.NodeUser, buf.String(), args...,
+		); err != nil {
+			return errors.Wrap(err, "failed to write system.statement_statistics")
+		}
+	}
+	return nil
+}
+
+// deleteExpiredSQLStats deletes the persisted statement statistics that were
+// aggregated before the TTL.
+func (s *Server) deleteExpiredSQLStats(ctx context.Context) error {
+	ttl := stmtStatsPersistTTL.Get(&s.cfg.Settings.SV)
+	if ttl == 0 {
+		return nil
+	}
+	cutoff := tree.MakeDTimestampTZ(timeutil.Now().Add(-ttl), time.Microsecond)
+	for {
+		n, err := s.cfg.InternalExecutor.ExecWithUser(
+			ctx, "delete-expired-stmt-stats", nil /* txn */, security.NodeUser,
+			`DELETE FROM system.statement_statistics WHERE aggregated_ts < $1 LIMIT $2`,
+			cutoff, stmtStatsDeleteBatchSize,
+		)
+		if err != nil {
+			return errors.Wrap(err, "failed to delete from system.statement_statistics")
+		}
+		if n < stmtStatsDeleteBatchSize {
+			return nil
+		}
+	}
+}
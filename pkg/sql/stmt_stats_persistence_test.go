@@ -0,0 +1,61 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/server"
+	"github.com/cockroachdb/cockroach/pkg/sql/tests"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestPersistSQLStats(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	testutils.RunTrueAndFalse(t, "priorReset", func(t *testing.T, priorReset bool) {
+		ctx := context.Background()
+		params, _ := tests.CreateTestServerParams()
+		s, db, _ := serverutils.StartServer(t, params)
+		defer s.Stopper().Stop(ctx)
+		sqlServer := s.(*server.TestServer).Server.PGServer().SQLServer
+
+		sqlDB := sqlutils.MakeSQLRunner(db)
+		if priorReset {
+			// Make sure the statistics collected from here on are aggregated over an
+			// interval with a known start.
+			sqlServer.ResetSQLStats(ctx)
+		}
+
+		sqlDB.Exec(t, `SET application_name = 'persist_test'`)
+		sqlDB.Exec(t, `CREATE TABLE t (x INT PRIMARY KEY)`)
+		for i := 0; i < 3; i++ {
+			sqlDB.Exec(t, `INSERT INTO t VALUES ($1)`, i)
+		}
+		sqlDB.Exec(t, `SET application_name = ''`)
+
+		// Without a prior reset, the statistics are aggregated since the server's
+		// start, and they must be persisted all the same.
+		sqlServer.ResetSQLStats(ctx)
+
+		sqlDB.CheckQueryResults(t, `
+SELECT key, count, array_length(service_lat_histogram, 1),
+       (SELECT sum(c) FROM unnest(service_lat_histogram) AS c)
+  FROM crdb_internal.statement_statistics
+ WHERE application_name = 'persist_test' AND key LIKE 'INSERT%'`,
+			[][]string{{"INSERT INTO t VALUES ($1)", "3", "14", "3"}},
+		)
+	})
+}
@@ -0,0 +1,293 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+// Package stmtdiagnostics implements the bookkeeping for statement
+// diagnostics requests: an operator asks for the next execution of a
+// statement fingerprint to be traced, and whichever node executes it first
+// collects a diagnostics bundle and records it in system tables.
+package stmtdiagnostics
+
+import (
+	"context"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlutil"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/errors"
+)
+
+var pollingInterval = settings.RegisterNonNegativeDurationSetting(
+	"sql.stmt_diagnostics.poll_interval",
+	"rate at which the stmtdiagnostics.Registry polls for requests, set to zero to disable",
+	10*time.Second,
+)
+
+// bundleChunkSize is the maximum size of a row in
+// system.statement_bundle_chunks; bundles are split across as many rows as
+// needed.
+const bundleChunkSize = 128 << 10 // 128 KiB
+
+// RequestID identifies a row in system.statement_diagnostics_requests.
+type RequestID int
+
+// CollectedInstanceID identifies a row in system.statement_diagnostics.
+type CollectedInstanceID int
+
+// Registry maintains a view on the statement fingerprints on which data is to
+// be collected (i.e. the outstanding rows of
+// system.statement_diagnostics_requests) and provides utilities for checking a
+// query against this list and satisfying the requests.
+type Registry struct {
+	mu struct {
+		syncutil.Mutex
+		// requests waiting for the right query to come along.
+		requestFingerprints map[RequestID]string
+		// ids of requests that this node is in the process of servicing.
+		ongoing map[RequestID]struct{}
+	}
+	st *cluster.Settings
+	ie sqlutil.InternalExecutor
+	db *client.DB
+}
+
+// NewRegistry constructs a new Registry.
+func NewRegistry(ie sqlutil.InternalExecutor, db *client.DB, st *cluster.Settings) *Registry {
+	r := &Registry{
+		ie: ie,
+		db: db,
+		st: st,
+	}
+	r.mu.requestFingerprints = make(map[RequestID]string)
+	r.mu.ongoing = make(map[RequestID]struct{})
+	return r
+}
+
+// Start starts the polling loop for the registry.
+func (r *Registry) Start(ctx context.Context, stopper *stop.Stopper) {
+	ctx, _ = stopper.WithCancelOnQuiesce(ctx)
+	stopper.RunWorker(ctx, r.poll)
+}
+
+func (r *Registry) poll(ctx context.Context) {
+	sqlutil.Poll(ctx, &r.st.SV, pollingInterval, "statement diagnostics requests", r.pollRequests)
+}
+
+// pollRequests reads the outstanding requests from
+// system.statement_diagnostics_requests and replaces the local view with them.
+func (r *Registry) pollRequests(ctx context.Context) error {
+	if !cluster.Version.IsActive(ctx, r.st, cluster.VersionStatementDiagnosticsSystemTables) {
+		return nil
+	}
+	rows, err := r.ie.Query(ctx, "stmt-diag-poll", nil, /* txn */
+		"SELECT id, statement_fingerprint FROM system.statement_diagnostics_requests "+
+			"WHERE completed = false")
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.mu.requestFingerprints = make(map[RequestID]string, len(rows))
+	for _, row := range rows {
+		id := RequestID(*row[0].(*tree.DInt))
+		if _, ok := r.mu.ongoing[id]; ok {
+			continue
+		}
+		r.mu.requestFingerprints[id] = string(*row[1].(*tree.DString))
+	}
+	return nil
+}
+
+// InsertRequest creates a request for the next execution of a statement with
+// the given fingerprint to be traced. If there already is an outstanding
+// request for the fingerprint, its ID is returned instead.
+func (r *Registry) InsertRequest(ctx context.Context, fprint string) (RequestID, error) {
+	if !cluster.Version.IsActive(ctx, r.st, cluster.VersionStatementDiagnosticsSystemTables) {
+		return 0, errors.New(
+			"statement diagnostics requests are not supported until the cluster is fully upgraded")
+	}
+	var reqID RequestID
+	err := r.db.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		row, err := r.ie.QueryRow(ctx, "stmt-diag-check-pending", txn,
+			"SELECT id FROM system.statement_diagnostics_requests "+
+				"WHERE completed = false AND statement_fingerprint = $1 LIMIT 1",
+			fprint)
+		if err != nil {
+			return err
+		}
+		if row != nil {
+			reqID = RequestID(*row[0].(*tree.DInt))
+			return nil
+		}
+		row, err = r.ie.QueryRow(ctx, "stmt-diag-insert-request", txn,
+			"INSERT INTO system.statement_diagnostics_requests (statement_fingerprint, requested_at) "+
+				"VALUES ($1, $2) RETURNING id",
+			fprint, tree.MakeDTimestampTZ(timeutil.Now(), time.Microsecond))
+		if err != nil {
+			return err
+		}
+		reqID = RequestID(*row[0].(*tree.DInt))
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	// Start watching for the fingerprint right away on this node; the other
+	// nodes will notice the request on their next poll.
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.mu.ongoing[reqID]; !ok {
+		r.mu.requestFingerprints[reqID] = fprint
+	}
+	return reqID, nil
+}
+
+// ShouldCollectDiagnostics checks whether any data should be collected for the
+// given statement. If so, the request is marked as ongoing on this node and
+// its ID is returned; the caller is then expected to call
+// InsertStatementDiagnostics once the execution is done.
+func (r *Registry) ShouldCollectDiagnostics(
+	ctx context.Context, ast tree.Statement,
+) (bool, RequestID) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Return quickly if we have no requests to trace.
+	if len(r.mu.requestFingerprints) == 0 {
+		return false, 0
+	}
+
+	fingerprint := tree.AsStringWithFlags(ast, tree.FmtHideConstants)
+	for id, f := range r.mu.requestFingerprints {
+		if f == fingerprint {
+			delete(r.mu.requestFingerprints, id)
+			r.mu.ongoing[id] = struct{}{}
+			return true, id
+		}
+	}
+	return false, 0
+}
+
+// InsertStatementDiagnostics inserts a trace and bundle into
+// system.statement_diagnostics, splitting the bundle into
+// system.statement_bundle_chunks.
+//
+// If requestID is non-zero, the corresponding request is marked as completed.
+// If the request was completed in the meantime by another node, nothing is
+// inserted and a zero ID is returned.
+//
+// collectionErr is recorded in the error column if collecting the bundle
+// failed.
+func (r *Registry) InsertStatementDiagnostics(
+	ctx context.Context,
+	requestID RequestID,
+	stmtFingerprint string,
+	stmt string,
+	traceJSON tree.Datum,
+	bundle []byte,
+	collectionErr error,
+) (CollectedInstanceID, error) {
+	if requestID != 0 {
+		defer func() {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			delete(r.mu.ongoing, requestID)
+		}()
+	}
+	if !cluster.Version.IsActive(ctx, r.st, cluster.VersionStatementDiagnosticsSystemTables) {
+		return 0, errors.New(
+			"statement diagnostics are not supported until the cluster is fully upgraded")
+	}
+
+	var diagID CollectedInstanceID
+	err := r.db.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		if requestID != 0 {
+			row, err := r.ie.QueryRow(ctx, "stmt-diag-check-completed", txn,
+				"SELECT count(1) FROM system.statement_diagnostics_requests "+
+					"WHERE id = $1 AND completed = false",
+				requestID)
+			if err != nil {
+				return err
+			}
+			if int(*row[0].(*tree.DInt)) == 0 {
+				// Someone else already satisfied the request.
+				diagID = 0
+				return nil
+			}
+		}
+
+		errorVal := tree.DNull
+		if collectionErr != nil {
+			errorVal = tree.NewDString(collectionErr.Error())
+		}
+
+		bundleChunksVal := tree.DNull
+		if len(bundle) != 0 {
+			chunks := tree.NewDArray(types.Int)
+			for len(bundle) > 0 {
+				chunk := bundle
+				if len(chunk) > bundleChunkSize {
+					chunk = chunk[:bundleChunkSize]
+				}
+				bundle = bundle[len(chunk):]
+
+				row, err := r.ie.QueryRow(ctx, "stmt-bundle-chunks-insert", txn,
+					"INSERT INTO system.statement_bundle_chunks(description, data) "+
+						"VALUES ($1, $2) RETURNING id",
+					"statement diagnostics bundle", tree.NewDBytes(tree.DBytes(chunk)))
+				if err != nil {
+					return err
+				}
+				if err := chunks.Append(row[0]); err != nil {
+					return err
+				}
+			}
+			bundleChunksVal = chunks
+		}
+
+		if traceJSON == nil {
+			traceJSON = tree.DNull
+		}
+		collectionTime := tree.MakeDTimestampTZ(timeutil.Now(), time.Microsecond)
+		row, err := r.ie.QueryRow(ctx, "stmt-diag-insert", txn,
+			"INSERT INTO system.statement_diagnostics "+
+				"(statement_fingerprint, statement, collected_at, trace, bundle_chunks, error) "+
+				"VALUES ($1, $2, $3, $4, $5, $6) RETURNING id",
+			stmtFingerprint, stmt, collectionTime, traceJSON, bundleChunksVal, errorVal)
+		if err != nil {
+			return err
+		}
+		diagID = CollectedInstanceID(*row[0].(*tree.DInt))
+
+		if requestID != 0 {
+			// Mark the request as completed.
+			_, err := r.ie.Exec(ctx, "stmt-diag-mark-completed", txn,
+				"UPDATE system.statement_diagnostics_requests "+
+					"SET completed = true, statement_diagnostics_id = $1 WHERE id = $2",
+				diagID, requestID)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return diagID, nil
+}
@@ -0,0 +1,75 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package stmtdiagnostics_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/stmtdiagnostics"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiagnosticsRequest(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ctx := context.Background()
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(ctx)
+	runner := sqlutils.MakeSQLRunner(db)
+	runner.Exec(t, "CREATE TABLE test (x int PRIMARY KEY)")
+
+	registry := s.ExecutorConfig().(sql.ExecutorConfig).StmtDiagnosticsRecorder
+	reqID, err := registry.InsertRequest(ctx, "INSERT INTO test VALUES (_)")
+	require.NoError(t, err)
+
+	// A second request for the same fingerprint reuses the pending one.
+	dupID, err := registry.InsertRequest(ctx, "INSERT INTO test VALUES (_)")
+	require.NoError(t, err)
+	require.Equal(t, reqID, dupID)
+
+	checkCompleted := func(expected bool) {
+		var completed bool
+		runner.QueryRow(t,
+			"SELECT completed FROM system.statement_diagnostics_requests WHERE ID = $1", reqID,
+		).Scan(&completed)
+		require.Equal(t, expected, completed)
+	}
+	checkCompleted(false)
+
+	// Run a query that doesn't match the fingerprint.
+	runner.Exec(t, "SELECT * FROM test")
+	checkCompleted(false)
+
+	// Run the query that matches the fingerprint, with a different constant.
+	runner.Exec(t, "INSERT INTO test VALUES (1)")
+	checkCompleted(true)
+
+	var diagID stmtdiagnostics.CollectedInstanceID
+	runner.QueryRow(t,
+		"SELECT statement_diagnostics_id FROM system.statement_diagnostics_requests WHERE ID = $1",
+		reqID,
+	).Scan(&diagID)
+
+	var stmt string
+	var numChunks int
+	runner.QueryRow(t,
+		"SELECT statement, array_length(bundle_chunks, 1) FROM system.statement_diagnostics "+
+			"WHERE ID = $1", diagID,
+	).Scan(&stmt, &numChunks)
+	require.Equal(t, "INSERT INTO test VALUES (1)", stmt)
+	require.NotZero(t, numChunks)
+}
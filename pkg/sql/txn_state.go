@@ -290,6 +290,15 @@ func (ts *txnState) setHistoricalTimestamp(ctx context.Context, historicalTimest
 	ts.isHistorical = true
 }
 
+// setBoundedStalenessTimestamp is like setHistoricalTimestamp, for the
+// transactions of bounded staleness reads. See negotiateBoundedStaleness.
+func (ts *txnState) setBoundedStalenessTimestamp(ctx context.Context, timestamp hlc.Timestamp) {
+	ts.mu.Lock()
+	ts.mu.txn.SetBoundedStalenessTimestamp(ctx, timestamp)
+	ts.mu.Unlock()
+	ts.isHistorical = true
+}
+
 // getReadTimestamp returns the transaction's current read timestamp.
 func (ts *txnState) getReadTimestamp() hlc.Timestamp {
 	ts.mu.RLock()
@@ -808,6 +808,7 @@ var planNodeNames = map[reflect.Type]string{
 	reflect.TypeOf(&commentOnIndexNode{}):       "comment on index",
 	reflect.TypeOf(&commentOnTableNode{}):       "comment on table",
 	reflect.TypeOf(&controlJobsNode{}):          "control jobs",
+	reflect.TypeOf(&controlSchedulesNode{}):     "control schedules",
 	reflect.TypeOf(&createDatabaseNode{}):       "create database",
 	reflect.TypeOf(&createIndexNode{}):          "create index",
 	reflect.TypeOf(&createSequenceNode{}):       "create sequence",
@@ -841,6 +842,7 @@ var planNodeNames = map[reflect.Type]string{
 	reflect.TypeOf(&lookupJoinNode{}):           "lookup-join",
 	reflect.TypeOf(&max1RowNode{}):              "max1row",
 	reflect.TypeOf(&ordinalityNode{}):           "ordinality",
+	reflect.TypeOf(&pinPlanNode{}):              "pin plan",
 	reflect.TypeOf(&projectSetNode{}):           "project set",
 	reflect.TypeOf(&recursiveCTENode{}):         "recursive cte node",
 	reflect.TypeOf(&relocateNode{}):             "relocate",
@@ -872,6 +874,7 @@ var planNodeNames = map[reflect.Type]string{
 	reflect.TypeOf(&truncateNode{}):             "truncate",
 	reflect.TypeOf(&unaryNode{}):                "emptyrow",
 	reflect.TypeOf(&unionNode{}):                "union",
+	reflect.TypeOf(&unpinPlanNode{}):            "unpin plan",
 	reflect.TypeOf(&updateNode{}):               "update",
 	reflect.TypeOf(&upsertNode{}):               "upsert",
 	reflect.TypeOf(&valuesNode{}):               "values",
@@ -284,6 +284,35 @@ var backwardCompatibleMigrations = []migrationDescriptor{
 		workFn:              migrateSystemNamespace,
 		includedInBootstrap: cluster.VersionByKey(cluster.VersionNamespaceTableWithSchemas),
 	},
+	{
+		// Introduced in v20.1.
+		name:                "create system.statement_statistics table",
+		workFn:              createStatementStatisticsTable,
+		includedInBootstrap: cluster.VersionByKey(cluster.VersionStatementStatisticsTable),
+		newDescriptorIDs:    staticIDs(keys.StatementStatisticsTableID),
+	},
+	{
+		// Introduced in v20.1.
+		name:                "create statement_diagnostics_requests, statement_diagnostics and statement_bundle_chunks tables",
+		workFn:              createStatementDiagnosticsTables,
+		includedInBootstrap: cluster.VersionByKey(cluster.VersionStatementDiagnosticsSystemTables),
+		newDescriptorIDs: staticIDs(keys.StatementBundleChunksTableID,
+			keys.StatementDiagnosticsRequestsTableID, keys.StatementDiagnosticsTableID),
+	},
+	{
+		// Introduced in v20.1.
+		name:                "create system.statement_plan_pins table",
+		workFn:              createStatementPlanPinsTable,
+		includedInBootstrap: cluster.VersionByKey(cluster.VersionStatementPlanPins),
+		newDescriptorIDs:    staticIDs(keys.StatementPlanPinsTableID),
+	},
+	{
+		// Introduced in v20.1.
+		name:                "create system.scheduled_jobs table",
+		workFn:              createScheduledJobsTable,
+		includedInBootstrap: cluster.VersionByKey(cluster.VersionScheduledJobs),
+		newDescriptorIDs:    staticIDs(keys.ScheduledJobsTableID),
+	},
 }
 
 func staticIDs(ids ...sqlbase.ID) func(ctx context.Context, db db) ([]sqlbase.ID, error) {
@@ -662,6 +691,34 @@ func createProtectedTimestampsRecordsTable(ctx context.Context, r runner) error
 		"failed to create system.protected_ts_records")
 }
 
+func createStatementStatisticsTable(ctx context.Context, r runner) error {
+	return errors.Wrap(createSystemTable(ctx, r, sqlbase.StatementStatisticsTable),
+		"failed to create system.statement_statistics")
+}
+
+func createStatementDiagnosticsTables(ctx context.Context, r runner) error {
+	if err := createSystemTable(ctx, r, sqlbase.StatementBundleChunksTable); err != nil {
+		return errors.Wrap(err, "failed to create system.statement_bundle_chunks")
+	}
+	if err := createSystemTable(ctx, r, sqlbase.StatementDiagnosticsRequestsTable); err != nil {
+		return errors.Wrap(err, "failed to create system.statement_diagnostics_requests")
+	}
+	if err := createSystemTable(ctx, r, sqlbase.StatementDiagnosticsTable); err != nil {
+		return errors.Wrap(err, "failed to create system.statement_diagnostics")
+	}
+	return nil
+}
+
+func createStatementPlanPinsTable(ctx context.Context, r runner) error {
+	return errors.Wrap(createSystemTable(ctx, r, sqlbase.StatementPlanPinsTable),
+		"failed to create system.statement_plan_pins")
+}
+
+func createScheduledJobsTable(ctx context.Context, r runner) error {
+	return errors.Wrap(createSystemTable(ctx, r, sqlbase.ScheduledJobsTable),
+		"failed to create system.scheduled_jobs")
+}
+
 func createNewSystemNamespaceDescriptor(ctx context.Context, r runner) error {
 	err := r.db.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
 		b := txn.NewBatch()
@@ -57,6 +57,8 @@ const (
 	removeDeadReplicaPriority               float64 = 1000
 	removeDecommissioningReplicaPriority    float64 = 200
 	removeExtraReplicaPriority              float64 = 100
+	addMissingNonVoterPriority              float64 = 60
+	removeNonVoterPriority                  float64 = 50
 )
 
 // MinLeaseTransferStatsDuration configures the minimum amount of time a
@@ -108,6 +110,8 @@ const (
 	AllocatorConsiderRebalance
 	AllocatorRangeUnavailable
 	AllocatorFinalizeAtomicReplicationChange
+	AllocatorAddNonVoter
+	AllocatorRemoveNonVoter
 )
 
 var allocatorActionNames = map[AllocatorAction]string{
@@ -122,6 +126,8 @@ var allocatorActionNames = map[AllocatorAction]string{
 	AllocatorConsiderRebalance:               "consider rebalance",
 	AllocatorRangeUnavailable:                "range unavailable",
 	AllocatorFinalizeAtomicReplicationChange: "finalize conf change",
+	AllocatorAddNonVoter:                     "add non-voter",
+	AllocatorRemoveNonVoter:                  "remove non-voter",
 }
 
 func (a AllocatorAction) String() string {
@@ -213,9 +219,11 @@ func makeAllocatorRand(source rand.Source) allocatorRand {
 // RangeUsageInfo contains usage information (sizes and traffic) needed by the
 // allocator to make rebalancing decisions for a given range.
 type RangeUsageInfo struct {
-	LogicalBytes     int64
-	QueriesPerSecond float64
-	WritesPerSecond  float64
+	LogicalBytes        int64
+	QueriesPerSecond    float64
+	WritesPerSecond     float64
+	WriteBytesPerSecond float64
+	CPUPerSecond        float64
 }
 
 func rangeUsageInfoForRepl(repl *Replica) RangeUsageInfo {
@@ -228,6 +236,12 @@ func rangeUsageInfoForRepl(repl *Replica) RangeUsageInfo {
 	if writesPerSecond, dur := repl.writeStats.avgQPS(); dur >= MinStatsDuration {
 		info.WritesPerSecond = writesPerSecond
 	}
+	if writeBytesPerSecond, dur := repl.writeBytesStats.avgQPS(); dur >= MinStatsDuration {
+		info.WriteBytesPerSecond = writeBytesPerSecond
+	}
+	if cpuPerSecond, dur := repl.evalTimeStats.avgQPS(); dur >= MinStatsDuration {
+		info.CPUPerSecond = cpuPerSecond
+	}
 	return info
 }
 
@@ -355,7 +369,62 @@ func (a *Allocator) ComputeAction(
 		return AllocatorRemoveLearner, removeLearnerReplicaPriority
 	}
 	// computeAction expects to operate only on voters.
-	return a.computeAction(ctx, zone, desc.RangeID, desc.Replicas().Voters())
+	voterReplicas := desc.Replicas().Voters()
+	action, priority := a.computeAction(ctx, zone, desc.RangeID, voterReplicas)
+	if action != AllocatorConsiderRebalance {
+		return action, priority
+	}
+	// Only once the voters are in order do we consider the non-voters, which
+	// don't affect the availability of the range.
+	return a.computeNonVoterAction(ctx, zone, desc.RangeID, voterReplicas, desc.Replicas().NonVoters())
+}
+
+// computeNonVoterAction determines whether non-voting replicas need to be
+// added or removed, as governed by the difference between num_replicas and
+// num_voters in the supplied zone configuration. Dead and decommissioning
+// non-voters are removed outright; they will be replaced in a subsequent
+// pass since, unlike voters, they don't contribute to the range's fault
+// tolerance.
+func (a *Allocator) computeNonVoterAction(
+	ctx context.Context,
+	zone *config.ZoneConfig,
+	rangeID roachpb.RangeID,
+	voterReplicas []roachpb.ReplicaDescriptor,
+	nonVoterReplicas []roachpb.ReplicaDescriptor,
+) (AllocatorAction, float64) {
+	need := 0
+	if cluster.Version.IsActive(ctx, a.storePool.st, cluster.VersionNonVotingReplicas) {
+		need = int(zone.GetNumNonVoters())
+	}
+	// Non-voters can only be placed on nodes which don't have a voter.
+	if max := a.storePool.ClusterNodeCount() - len(voterReplicas); need > max {
+		need = max
+	}
+	if need < 0 {
+		need = 0
+	}
+	have := len(nonVoterReplicas)
+
+	if have < need {
+		priority := addMissingNonVoterPriority
+		action := AllocatorAddNonVoter
+		log.VEventf(ctx, 3, "%s - missing non-voter need=%d, have=%d, priority=%.2f",
+			action, need, have, priority)
+		return action, priority
+	}
+
+	_, deadNonVoters := a.storePool.liveAndDeadReplicas(rangeID, nonVoterReplicas)
+	decommissioningNonVoters := a.storePool.decommissioningReplicas(rangeID, nonVoterReplicas)
+	if have > need || len(deadNonVoters) > 0 || len(decommissioningNonVoters) > 0 {
+		priority := removeNonVoterPriority
+		action := AllocatorRemoveNonVoter
+		log.VEventf(ctx, 3, "%s - need=%d, have=%d, dead=%d, decommissioning=%d, priority=%.2f",
+			action, need, have, len(deadNonVoters), len(decommissioningNonVoters), priority)
+		return action, priority
+	}
+
+	// Nothing needs to be done, but we may want to rebalance.
+	return AllocatorConsiderRebalance, 0
 }
 
 func (a *Allocator) computeAction(
@@ -368,7 +437,7 @@ func (a *Allocator) computeAction(
 	have := len(voterReplicas)
 	decommissioningReplicas := a.storePool.decommissioningReplicas(rangeID, voterReplicas)
 	clusterNodes := a.storePool.ClusterNodeCount()
-	need := GetNeededReplicas(*zone.NumReplicas, clusterNodes)
+	need := GetNeededReplicas(zone.GetNumVoters(), clusterNodes)
 	desiredQuorum := computeQuorum(need)
 	quorum := computeQuorum(have)
 
@@ -473,8 +542,54 @@ func (a *Allocator) AllocateTarget(
 	zone *config.ZoneConfig,
 	rangeID roachpb.RangeID,
 	existingReplicas []roachpb.ReplicaDescriptor,
+) (*roachpb.StoreDescriptor, string, error) {
+	return a.AllocateVoter(ctx, zone, rangeID, existingReplicas, nil /* existingNonVoters */)
+}
+
+// AllocateVoter is like AllocateTarget, but additionally rules out the nodes
+// of the range's existing non-voting replicas. The target satisfies the
+// zone's voter constraints, if any.
+func (a *Allocator) AllocateVoter(
+	ctx context.Context,
+	zone *config.ZoneConfig,
+	rangeID roachpb.RangeID,
+	existingVoters []roachpb.ReplicaDescriptor,
+	existingNonVoters []roachpb.ReplicaDescriptor,
+) (*roachpb.StoreDescriptor, string, error) {
+	return a.allocateTarget(
+		ctx, zone, rangeID, existingVoters, existingNonVoters, zone.VoterConstraints)
+}
+
+// AllocateNonVoter returns a suitable store for a new non-voting replica.
+// Nodes already accommodating voting or non-voting replicas are ruled out as
+// targets. Only the zone's constraints, which apply to all replicas, are taken
+// into account, and the non-voters are spread out as if they were the range's
+// only replicas.
+func (a *Allocator) AllocateNonVoter(
+	ctx context.Context,
+	zone *config.ZoneConfig,
+	rangeID roachpb.RangeID,
+	existingVoters []roachpb.ReplicaDescriptor,
+	existingNonVoters []roachpb.ReplicaDescriptor,
+) (*roachpb.StoreDescriptor, string, error) {
+	return a.allocateTarget(
+		ctx, zone, rangeID, existingNonVoters, existingVoters, nil /* extraConstraints */)
+}
+
+// allocateTarget returns a suitable store for a new replica. The existing
+// replicas are taken into account for constraints and diversity, while the
+// nodes of the excluded replicas are merely ruled out as targets. If
+// extraConstraints are given, the target must additionally satisfy them.
+func (a *Allocator) allocateTarget(
+	ctx context.Context,
+	zone *config.ZoneConfig,
+	rangeID roachpb.RangeID,
+	existingReplicas []roachpb.ReplicaDescriptor,
+	excludedReplicas []roachpb.ReplicaDescriptor,
+	extraConstraints []config.Constraints,
 ) (*roachpb.StoreDescriptor, string, error) {
 	sl, aliveStoreCount, throttled := a.storePool.getStoreList(rangeID, storeFilterThrottled)
+	sl = sl.excludeNodesOf(excludedReplicas).filter(extraConstraints)
 
 	target, details := a.allocateTargetFromList(
 		ctx, sl, zone, existingReplicas, a.scorerOptions())
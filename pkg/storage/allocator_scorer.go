@@ -78,7 +78,10 @@ var rangeRebalanceThreshold = func() *settings.FloatSetting {
 type scorerOptions struct {
 	deterministic           bool
 	rangeRebalanceThreshold float64
-	qpsRebalanceThreshold   float64 // only considered if non-zero
+	// loadDimension is the measure of load that loadRebalanceThreshold applies
+	// to.
+	loadDimension          LBRebalancingDimension
+	loadRebalanceThreshold float64 // only considered if non-zero
 }
 
 type balanceDimensions struct {
@@ -416,12 +419,14 @@ func allocateCandidates(
 		diversityScore := diversityAllocateScore(s, existingNodeLocalities)
 		balanceScore := balanceScore(sl, s.Capacity, options)
 		var convergesScore int
-		if options.qpsRebalanceThreshold > 0 {
-			if s.Capacity.QueriesPerSecond < underfullThreshold(sl.candidateQueriesPerSecond.mean, options.qpsRebalanceThreshold) {
+		if options.loadRebalanceThreshold > 0 {
+			load := options.loadDimension.storeLoad(s.Capacity)
+			meanLoad := options.loadDimension.candidateLoad(sl).mean
+			if load < underfullThreshold(meanLoad, options.loadRebalanceThreshold) {
 				convergesScore = 1
-			} else if s.Capacity.QueriesPerSecond < sl.candidateQueriesPerSecond.mean {
+			} else if load < meanLoad {
 				convergesScore = 0
-			} else if s.Capacity.QueriesPerSecond < overfullThreshold(sl.candidateQueriesPerSecond.mean, options.qpsRebalanceThreshold) {
+			} else if load < overfullThreshold(meanLoad, options.loadRebalanceThreshold) {
 				convergesScore = -1
 			} else {
 				convergesScore = -2
@@ -4823,6 +4823,154 @@ func TestAllocatorRemoveLearner(t *testing.T) {
 	require.Equal(t, AllocatorRemoveLearner, action)
 }
 
+func TestAllocatorComputeNonVoterAction(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	zone := config.ZoneConfig{
+		NumReplicas: proto.Int32(5),
+		NumVoters:   proto.Int32(3),
+	}
+	makeDesc := func(voters []roachpb.StoreID, nonVoters []roachpb.StoreID) roachpb.RangeDescriptor {
+		var desc roachpb.RangeDescriptor
+		for _, storeID := range voters {
+			desc.AddReplica(roachpb.NodeID(storeID), storeID, roachpb.VOTER_FULL)
+		}
+		for _, storeID := range nonVoters {
+			desc.AddReplica(roachpb.NodeID(storeID), storeID, roachpb.NON_VOTER)
+		}
+		return desc
+	}
+
+	testCases := []struct {
+		desc            roachpb.RangeDescriptor
+		live            []roachpb.StoreID
+		dead            []roachpb.StoreID
+		decommissioning []roachpb.StoreID
+		expectedAction  AllocatorAction
+	}{
+		// Missing non-voters are added.
+		{
+			desc:           makeDesc([]roachpb.StoreID{1, 2, 3}, nil),
+			live:           []roachpb.StoreID{1, 2, 3, 4, 5},
+			expectedAction: AllocatorAddNonVoter,
+		},
+		{
+			desc:           makeDesc([]roachpb.StoreID{1, 2, 3}, []roachpb.StoreID{4}),
+			live:           []roachpb.StoreID{1, 2, 3, 4, 5},
+			expectedAction: AllocatorAddNonVoter,
+		},
+		// A missing voter takes precedence over a missing non-voter.
+		{
+			desc:           makeDesc([]roachpb.StoreID{1, 2}, []roachpb.StoreID{4}),
+			live:           []roachpb.StoreID{1, 2, 3, 4, 5},
+			expectedAction: AllocatorAdd,
+		},
+		// Nothing to do.
+		{
+			desc:           makeDesc([]roachpb.StoreID{1, 2, 3}, []roachpb.StoreID{4, 5}),
+			live:           []roachpb.StoreID{1, 2, 3, 4, 5},
+			expectedAction: AllocatorConsiderRebalance,
+		},
+		// Excess non-voters are removed.
+		{
+			desc:           makeDesc([]roachpb.StoreID{1, 2, 3}, []roachpb.StoreID{4, 5, 6}),
+			live:           []roachpb.StoreID{1, 2, 3, 4, 5, 6},
+			expectedAction: AllocatorRemoveNonVoter,
+		},
+		// Dead and decommissioning non-voters are removed, to be replaced later.
+		{
+			desc:           makeDesc([]roachpb.StoreID{1, 2, 3}, []roachpb.StoreID{4, 5}),
+			live:           []roachpb.StoreID{1, 2, 3, 4, 6},
+			dead:           []roachpb.StoreID{5},
+			expectedAction: AllocatorRemoveNonVoter,
+		},
+		{
+			desc:            makeDesc([]roachpb.StoreID{1, 2, 3}, []roachpb.StoreID{4, 5}),
+			live:            []roachpb.StoreID{1, 2, 3, 4, 6},
+			decommissioning: []roachpb.StoreID{5},
+			expectedAction:  AllocatorRemoveNonVoter,
+		},
+	}
+
+	stopper, _, sp, a, _ := createTestAllocator(10, false /* deterministic */)
+	ctx := context.Background()
+	defer stopper.Stop(ctx)
+
+	for i, tcase := range testCases {
+		mockStorePool(sp, tcase.live, nil, tcase.dead, tcase.decommissioning, nil)
+		action, _ := a.ComputeAction(ctx, &zone, &tcase.desc)
+		if tcase.expectedAction != action {
+			t.Errorf("Test case %d expected action %s, got action %s", i, tcase.expectedAction, action)
+		}
+	}
+
+	// Without num_voters, there are no non-voters to add, and the existing ones
+	// are in excess.
+	noNonVotersZone := config.ZoneConfig{
+		NumReplicas: proto.Int32(3),
+	}
+	mockStorePool(sp, []roachpb.StoreID{1, 2, 3, 4}, nil, nil, nil, nil)
+	desc := makeDesc([]roachpb.StoreID{1, 2, 3}, []roachpb.StoreID{4})
+	action, _ := a.ComputeAction(ctx, &noNonVotersZone, &desc)
+	require.Equal(t, AllocatorRemoveNonVoter, action)
+}
+
+func TestAllocatorAllocateNonVoter(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	stopper, g, _, a, _ := createTestAllocator(5, false /* deterministic */)
+	ctx := context.Background()
+	defer stopper.Stop(ctx)
+	gossiputil.NewStoreGossiper(g).GossipStores(sameDCStores, t)
+
+	zone := config.ZoneConfig{
+		NumReplicas: proto.Int32(5),
+		NumVoters:   proto.Int32(3),
+		VoterConstraints: []config.Constraints{
+			{Constraints: []config.Constraint{{Value: "ssd", Type: config.Constraint_REQUIRED}}},
+		},
+	}
+	replicas := func(storeIDs ...roachpb.StoreID) []roachpb.ReplicaDescriptor {
+		var res []roachpb.ReplicaDescriptor
+		for _, storeID := range storeIDs {
+			res = append(res, roachpb.ReplicaDescriptor{
+				NodeID: roachpb.NodeID(storeID), StoreID: storeID, ReplicaID: roachpb.ReplicaID(storeID),
+			})
+		}
+		return res
+	}
+
+	// The nodes of both the voters and the non-voters are ruled out, and the
+	// voter constraints don't apply to non-voters.
+	result, _, err := a.AllocateNonVoter(ctx, &zone, firstRangeID, replicas(1, 2), replicas(3, 4))
+	if err != nil {
+		t.Fatalf("Unable to perform allocation: %+v", err)
+	}
+	if result.StoreID != 5 {
+		t.Errorf("expected a non-voter on store 5, got %+v", result)
+	}
+	if _, _, err := a.AllocateNonVoter(
+		ctx, &zone, firstRangeID, replicas(1, 2, 3), replicas(4, 5),
+	); err == nil {
+		t.Errorf("expected an allocation error with a replica on every node")
+	}
+
+	// Voters are only allocated on stores satisfying the voter constraints, and
+	// never on the node of a non-voter.
+	result, _, err = a.AllocateVoter(ctx, &zone, firstRangeID, replicas(1), replicas(3))
+	if err != nil {
+		t.Fatalf("Unable to perform allocation: %+v", err)
+	}
+	if result.StoreID != 2 {
+		t.Errorf("expected a voter on store 2, got %+v", result)
+	}
+	if _, _, err := a.AllocateVoter(
+		ctx, &zone, firstRangeID, replicas(1), replicas(2),
+	); err == nil {
+		t.Errorf("expected an allocation error with the only other ssd store holding a non-voter")
+	}
+}
+
 func TestAllocatorComputeActionDynamicNumReplicas(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 
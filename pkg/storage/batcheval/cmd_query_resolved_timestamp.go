@@ -0,0 +1,89 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package batcheval
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/storage/batcheval/result"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
+	"github.com/cockroachdb/errors"
+)
+
+func init() {
+	RegisterCommand(roachpb.QueryResolvedTimestamp, DefaultDeclareKeys, QueryResolvedTimestamp)
+}
+
+// QueryResolvedTimestamp returns the resolved timestamp of the key span the
+// request is issued over, that is, the newest timestamp at or below which the
+// evaluating replica can serve consistent reads of the span without being the
+// leaseholder and without blocking. This is the replica's closed timestamp,
+// lowered below the oldest intent in the span: a read which encounters an
+// intent has to push its transaction, and may block until the intent is
+// resolved.
+//
+// The request is meant to be sent with an INCONSISTENT read consistency so
+// that it is served by the nearest replica instead of the leaseholder.
+func QueryResolvedTimestamp(
+	ctx context.Context, batch engine.ReadWriter, cArgs CommandArgs, resp roachpb.Response,
+) (result.Result, error) {
+	args := cArgs.Args.(*roachpb.QueryResolvedTimestampRequest)
+	reply := resp.(*roachpb.QueryResolvedTimestampResponse)
+
+	resolvedTS := cArgs.EvalCtx.GetClosedTimestamp(ctx)
+	intentTS, err := minIntentTimestamp(batch, args.Key, args.EndKey)
+	if err != nil {
+		return result.Result{}, err
+	}
+	if !intentTS.IsEmpty() && !resolvedTS.Less(intentTS) {
+		resolvedTS = intentTS.Prev()
+	}
+	reply.ResolvedTS = resolvedTS
+	return result.Result{}, nil
+}
+
+// minIntentTimestamp returns the timestamp of the oldest intent in the given
+// key span, or an empty timestamp if there is none.
+func minIntentTimestamp(reader engine.Reader, start, end roachpb.Key) (hlc.Timestamp, error) {
+	iter := reader.NewIterator(engine.IterOptions{UpperBound: end})
+	defer iter.Close()
+
+	// Iterate through all keys using NextKey. The MVCCMetadata of an intent is
+	// always the first version of its key, so it's fine to skip over all other
+	// versions of the keys.
+	var minTS hlc.Timestamp
+	var meta enginepb.MVCCMetadata
+	for iter.SeekGE(engine.MakeMVCCMetadataKey(start)); ; iter.NextKey() {
+		if ok, err := iter.Valid(); err != nil {
+			return hlc.Timestamp{}, err
+		} else if !ok {
+			break
+		}
+		unsafeKey := iter.UnsafeKey()
+		if unsafeKey.IsValue() {
+			continue
+		}
+		if err := protoutil.Unmarshal(iter.UnsafeValue(), &meta); err != nil {
+			return hlc.Timestamp{}, errors.Wrapf(err, "unmarshaling mvcc meta: %v", unsafeKey)
+		}
+		if meta.Txn == nil {
+			continue
+		}
+		if ts := hlc.Timestamp(meta.Timestamp); minTS.IsEmpty() || ts.Less(minTS) {
+			minTS = ts
+		}
+	}
+	return minTS, nil
+}
@@ -0,0 +1,72 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package batcheval
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryResolvedTimestamp(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ctx := context.Background()
+	db := engine.NewDefaultInMem()
+	defer db.Close()
+
+	ts := func(wallTime int64) hlc.Timestamp { return hlc.Timestamp{WallTime: wallTime} }
+	value := roachpb.MakeValueFromString("val")
+	put := func(key string, ts hlc.Timestamp, txn *roachpb.Transaction) {
+		t.Helper()
+		require.NoError(t, engine.MVCCPut(ctx, db, nil, roachpb.Key(key), ts, value, txn))
+	}
+
+	// A committed value under the closed timestamp, an intent of an open
+	// transaction under the closed timestamp, and an intent above it.
+	put("a", ts(5), nil)
+	txn := roachpb.MakeTransaction("test", roachpb.Key("c"), roachpb.NormalUserPriority, ts(10), 0)
+	put("c", ts(10), &txn)
+	laterTxn := roachpb.MakeTransaction("test", roachpb.Key("e"), roachpb.NormalUserPriority, ts(30), 0)
+	put("e", ts(30), &laterTxn)
+
+	closedTS := ts(20)
+	for _, tc := range []struct {
+		name       string
+		start, end string
+		expected   hlc.Timestamp
+	}{
+		{"no intents", "a", "b", closedTS},
+		{"intent under the closed timestamp", "a", "d", ts(10).Prev()},
+		{"intent above the closed timestamp", "d", "f", closedTS},
+		{"oldest intent", "a", "f", ts(10).Prev()},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var resp roachpb.QueryResolvedTimestampResponse
+			_, err := QueryResolvedTimestamp(ctx, db, CommandArgs{
+				EvalCtx: &mockEvalCtx{closedTS: closedTS},
+				Args: &roachpb.QueryResolvedTimestampRequest{
+					RequestHeader: roachpb.RequestHeader{
+						Key: roachpb.Key(tc.start), EndKey: roachpb.Key(tc.end),
+					},
+				},
+			}, &resp)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, resp.ResolvedTS)
+			require.False(t, closedTS.Less(resp.ResolvedTS))
+		})
+	}
+}
@@ -41,6 +41,7 @@ type mockEvalCtx struct {
 	qps              float64
 	abortSpan        *abortspan.AbortSpan
 	gcThreshold      hlc.Timestamp
+	closedTS         hlc.Timestamp
 	term, firstIndex uint64
 	canCreateTxnFn   func() (bool, hlc.Timestamp, roachpb.TransactionAbortedReason)
 	lease            roachpb.Lease
@@ -109,6 +110,9 @@ func (m *mockEvalCtx) GetMVCCStats() enginepb.MVCCStats {
 func (m *mockEvalCtx) GetSplitQPS() float64 {
 	return m.qps
 }
+func (m *mockEvalCtx) GetClosedTimestamp(context.Context) hlc.Timestamp {
+	return m.closedTS
+}
 func (m *mockEvalCtx) CanCreateTxnRecord(
 	uuid.UUID, []byte, hlc.Timestamp,
 ) (bool, hlc.Timestamp, roachpb.TransactionAbortedReason) {
@@ -24,7 +24,10 @@ func DefaultDeclareKeys(
 	_ *roachpb.RangeDescriptor, header roachpb.Header, req roachpb.Request, spans *spanset.SpanSet,
 ) {
 	var access spanset.SpanAccess
-	if roachpb.IsReadOnly(req) {
+	// Locking reads declare write access so that they are serialized with
+	// conflicting writes and with other locking reads while they acquire
+	// locks.
+	if roachpb.IsReadOnly(req) && !roachpb.IsLockingRead(req) {
 		access = spanset.SpanReadOnly
 	} else {
 		access = spanset.SpanReadWrite
@@ -90,6 +90,9 @@ type EvalContext interface {
 	GetSplitQPS() float64
 
 	GetGCThreshold() hlc.Timestamp
+	// GetClosedTimestamp returns the closed timestamp of the range, below
+	// which the replica can serve follower reads.
+	GetClosedTimestamp(ctx context.Context) hlc.Timestamp
 	GetLastReplicaGCTimestamp(context.Context) (hlc.Timestamp, error)
 	GetLease() (roachpb.Lease, roachpb.Lease)
 
@@ -31,6 +31,16 @@ var consistencyCheckInterval = settings.RegisterNonNegativeDurationSetting(
 	24*time.Hour,
 )
 
+// consistencyCheckRepairEnabled controls whether the consistency checker
+// replaces the replicas which diverged from a majority of their peers instead
+// of terminating the nodes they live on.
+var consistencyCheckRepairEnabled = settings.RegisterBoolSetting(
+	"server.consistency_check.repair.enabled",
+	"if enabled, replicas found to be inconsistent with a majority of their peers are "+
+		"replaced instead of terminating the nodes they live on",
+	false,
+)
+
 var testingAggressiveConsistencyChecks = envutil.EnvOrDefaultBool("COCKROACH_CONSISTENCY_AGGRESSIVE", false)
 
 type consistencyQueue struct {
@@ -365,6 +365,102 @@ func TestCheckConsistencyInconsistent(t *testing.T) {
 	require.NotEmpty(t, b)
 }
 
+// TestCheckConsistencyRepair verifies that, with repairs enabled, a replica
+// which diverged from a majority of its peers is replaced by a new replica
+// instead of terminating the node it lives on, without leaving the range
+// under-replicated.
+func TestCheckConsistencyRepair(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	sc := storage.TestStoreConfig(nil)
+	storage.EnableConsistencyCheckRepair(&sc.Settings.SV)
+	sc.TestingKnobs.ConsistencyTestingKnobs.OnBadChecksumFatal = func(s roachpb.StoreIdent) {
+		t.Errorf("OnBadChecksumFatal called from %v", s)
+	}
+	mtc := &multiTestContext{
+		storeConfig:          &sc,
+		startWithSingleRange: true,
+	}
+	defer mtc.Stop()
+	mtc.Start(t, 4)
+	// The allocator needs to know about s4 to pick it for the replacement.
+	mtc.initGossipNetwork()
+	mtc.replicateRange(1, 1, 2)
+
+	ctx := context.Background()
+	pArgs := putArgs([]byte("a"), []byte("b"))
+	if _, err := client.SendWrapped(ctx, mtc.stores[0].TestSender(), pArgs); err != nil {
+		t.Fatal(err)
+	}
+	badRepl, ok := mtc.stores[0].LookupReplica(roachpb.RKey("a")).Desc().
+		GetReplicaDescriptor(mtc.stores[1].StoreID())
+	require.True(t, ok)
+
+	// Write some arbitrary data only to store 1, which is now in the minority.
+	var val roachpb.Value
+	val.SetInt(42)
+	if err := engine.MVCCPut(
+		ctx, mtc.stores[1].Engine(), nil, roachpb.Key("e"), mtc.stores[1].Clock().Now(), val, nil,
+	); err != nil {
+		t.Fatal(err)
+	}
+
+	checkArgs := roachpb.CheckConsistencyRequest{
+		RequestHeader: roachpb.RequestHeader{
+			Key:    []byte("a"),
+			EndKey: []byte("z"),
+		},
+		Mode: roachpb.ChecksumMode_CHECK_VIA_QUEUE,
+	}
+	resp, pErr := client.SendWrapped(ctx, mtc.stores[0].TestSender(), &checkArgs)
+	if pErr != nil {
+		t.Fatal(pErr)
+	}
+	ccResp := resp.(*roachpb.CheckConsistencyResponse)
+	require.Len(t, ccResp.Result, 1)
+	require.Equal(t, roachpb.CheckConsistencyResponse_RANGE_INCONSISTENT, ccResp.Result[0].Status)
+
+	// The inconsistent replica was replaced by a new replica on s4 in the same
+	// change, so the range never went below three voters, and the consistent
+	// replicas were left alone.
+	desc := mtc.stores[0].LookupReplica(roachpb.RKey("a")).Desc()
+	require.Len(t, desc.Replicas().Voters(), 3, "range under-replicated: %s", desc)
+	_, ok = desc.GetReplicaDescriptorByID(badRepl.ReplicaID)
+	require.False(t, ok, "inconsistent replica %s still part of %s", badRepl, desc)
+	for _, i := range []int{0, 2, 3} {
+		_, ok := desc.GetReplicaDescriptor(mtc.stores[i].StoreID())
+		require.True(t, ok, "replica on s%d not part of %s", i+1, desc)
+	}
+
+	// The replica GC queue removes the inconsistent replica along with its
+	// data.
+	testutils.SucceedsSoon(t, func() error {
+		mtc.stores[1].MustForceReplicaGCScanAndProcess()
+		if repl, err := mtc.stores[1].GetReplica(desc.RangeID); err == nil {
+			return fmt.Errorf("replica %s not GC'd yet", repl)
+		}
+		val, _, err := engine.MVCCGet(
+			ctx, mtc.stores[1].Engine(), roachpb.Key("e"), hlc.MaxTimestamp, engine.MVCCGetOptions{},
+		)
+		if err != nil {
+			return err
+		}
+		if val != nil {
+			return fmt.Errorf("data of the inconsistent replica not GC'd yet")
+		}
+		return nil
+	})
+
+	// The repaired range is consistent again.
+	resp, pErr = client.SendWrapped(ctx, mtc.stores[0].TestSender(), &checkArgs)
+	if pErr != nil {
+		t.Fatal(pErr)
+	}
+	ccResp = resp.(*roachpb.CheckConsistencyResponse)
+	require.Len(t, ccResp.Result, 1)
+	require.Equal(t, roachpb.CheckConsistencyResponse_RANGE_CONSISTENT, ccResp.Result[0].Status)
+}
+
 // TestConsistencyQueueRecomputeStats is an end-to-end test of the mechanism CockroachDB
 // employs to adjust incorrect MVCCStats ("incorrect" meaning not an inconsistency of
 // these stats between replicas, but a delta between persisted stats and those one
@@ -0,0 +1,124 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+// Package contention records the events in which a request was blocked by
+// another transaction, for the purpose of diagnosing contention between
+// transactions.
+package contention
+
+import (
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
+)
+
+// Source identifies the component in which a request blocked.
+type Source string
+
+const (
+	// SourceLatch indicates that the request waited on a latch held by a
+	// conflicting request.
+	SourceLatch Source = "latch"
+	// SourceTxnWaitQueue indicates that the request ran into an intent and
+	// waited in the txn wait queue for the transaction that wrote it to
+	// finish or be pushed.
+	SourceTxnWaitQueue Source = "txnwait"
+	// SourceLockTable indicates that the request waited in the lock table for
+	// a conflicting lock to be released.
+	SourceLockTable Source = "lock_table"
+)
+
+// DefaultMaxEvents is the number of events retained by a Registry created
+// with NewRegistry.
+const DefaultMaxEvents = 1024
+
+// Event describes a single instance of a request being blocked by another
+// transaction.
+type Event struct {
+	// Key is the key on which the request blocked. For latches, it is the
+	// start key of the contended span. For locks and the txn wait queue, it
+	// is the key of the conflicting intent.
+	Key roachpb.Key
+	// BlockingTxnID is the ID of the transaction that held the latch or lock.
+	// It is empty if the blocking request was not transactional.
+	BlockingTxnID uuid.UUID
+	// WaitingTxnID is the ID of the transaction that was blocked. It is empty
+	// if the waiting request was not transactional.
+	WaitingTxnID uuid.UUID
+	// Time is the time at which the request stopped waiting.
+	Time time.Time
+	// Duration is the amount of time the request spent waiting.
+	Duration time.Duration
+	// Source is the component in which the request blocked.
+	Source Source
+}
+
+// Registry retains the most recent contention events observed on a node. It
+// is safe for concurrent use, and a nil Registry discards all events.
+type Registry struct {
+	mu struct {
+		syncutil.Mutex
+		// events is a ring buffer, with next pointing at the slot that will
+		// be overwritten by the next event once the buffer is full.
+		events []Event
+		next   int
+		full   bool
+	}
+}
+
+// NewRegistry returns a Registry retaining up to DefaultMaxEvents events.
+func NewRegistry() *Registry {
+	return NewRegistryWithSize(DefaultMaxEvents)
+}
+
+// NewRegistryWithSize returns a Registry retaining up to maxEvents events.
+func NewRegistryWithSize(maxEvents int) *Registry {
+	if maxEvents <= 0 {
+		panic("contention registry must retain at least one event")
+	}
+	r := &Registry{}
+	r.mu.events = make([]Event, maxEvents)
+	return r
+}
+
+// Add records a contention event, evicting the oldest event if the registry
+// is full.
+func (r *Registry) Add(e Event) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.mu.events[r.mu.next] = e
+	r.mu.next++
+	if r.mu.next == len(r.mu.events) {
+		r.mu.next = 0
+		r.mu.full = true
+	}
+}
+
+// Events returns a copy of the retained events, from oldest to newest.
+func (r *Registry) Events() []Event {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var ret []Event
+	if r.mu.full {
+		ret = make([]Event, 0, len(r.mu.events))
+		ret = append(ret, r.mu.events[r.mu.next:]...)
+	} else {
+		ret = make([]Event, 0, r.mu.next)
+	}
+	return append(ret, r.mu.events[:r.mu.next]...)
+}
@@ -0,0 +1,51 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package contention
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	durations := func(events []Event) []time.Duration {
+		var ret []time.Duration
+		for _, e := range events {
+			ret = append(ret, e.Duration)
+		}
+		return ret
+	}
+
+	r := NewRegistryWithSize(3)
+	require.Empty(t, r.Events())
+
+	for i := 1; i <= 2; i++ {
+		r.Add(Event{Key: roachpb.Key("a"), Duration: time.Duration(i), Source: SourceLatch})
+	}
+	require.Equal(t, []time.Duration{1, 2}, durations(r.Events()))
+
+	// Overflow the registry; the oldest events are evicted.
+	for i := 3; i <= 5; i++ {
+		r.Add(Event{Key: roachpb.Key("a"), Duration: time.Duration(i), Source: SourceTxnWaitQueue})
+	}
+	require.Equal(t, []time.Duration{3, 4, 5}, durations(r.Events()))
+
+	// A nil registry discards events.
+	var nilRegistry *Registry
+	nilRegistry.Add(Event{})
+	require.Empty(t, nilRegistry.Events())
+}
@@ -214,17 +214,20 @@ func TestReadOnlyBasics(t *testing.T) {
 				shouldNotPanic(t, f, string(i))
 			}
 
-			// For a read-only ReadWriter, all Writer methods should panic.
-			failureTestCases := []func(){
-				func() { _ = b.ApplyBatchRepr(nil, false) },
-				func() { _ = b.Clear(a) },
-				func() { _ = b.SingleClear(a) },
-				func() { _ = b.ClearRange(a, a) },
-				func() { _ = b.Merge(a, nil) },
-				func() { _ = b.Put(a, nil) },
+			// For a read-only ReadWriter, all Writer methods should fail.
+			failureTestCases := []func() error{
+				func() error { return b.ApplyBatchRepr(nil, false) },
+				func() error { return b.Clear(a) },
+				func() error { return b.SingleClear(a) },
+				func() error { return b.ClearRange(a, a) },
+				func() error { return b.Merge(a, nil) },
+				func() error { return b.Put(a, nil) },
+				func() error { return b.LogData(nil) },
 			}
 			for i, f := range failureTestCases {
-				shouldPanic(t, f, string(i), "not implemented")
+				if err := f(); err != errReadOnlyEngine {
+					t.Errorf("%d: expected %v, got %v", i, errReadOnlyEngine, err)
+				}
 			}
 
 			if err := e.Put(mvccKey("a"), []byte("value")); err != nil {
@@ -37,6 +37,12 @@ func init() {
 	_ = DefaultStorageEngine.Set(envutil.EnvOrDefaultString("COCKROACH_STORAGE_ENGINE", "rocksdb"))
 }
 
+// errReadOnlyEngine is returned by the Writer methods of the read-only
+// ReadWriters of the engines. They are handed to code which takes a
+// ReadWriter, such as evaluateBatch for read-only batches, and must never be
+// written to.
+var errReadOnlyEngine = errors.New("cannot write to a read-only engine")
+
 // SimpleIterator is an interface for iterating over key/value pairs in an
 // engine. SimpleIterator implementations are thread safe unless otherwise
 // noted. SimpleIterator is a subset of the functionality offered by Iterator.
@@ -17,6 +17,7 @@ import (
 	"io"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"sort"
 
 	"github.com/cockroachdb/cockroach/pkg/base"
@@ -494,10 +495,23 @@ func (p *Pebble) GetCompactionStats() string {
 	return "\n" + p.db.Metrics().String()
 }
 
-// GetTickersAndHistograms implements the Engine interface.
+// GetTickersAndHistograms implements the Engine interface. Pebble doesn't
+// have the equivalent of RocksDB's tickers and histograms, so the cumulative
+// counters of its metrics are reported as tickers instead.
 func (p *Pebble) GetTickersAndHistograms() (*enginepb.TickersAndHistograms, error) {
-	// TODO(hueypark): Implement this.
-	return nil, nil
+	m := p.db.Metrics()
+	res := &enginepb.TickersAndHistograms{
+		Tickers: map[string]uint64{
+			"pebble.block-cache.hits":   uint64(m.BlockCache.Hits),
+			"pebble.block-cache.misses": uint64(m.BlockCache.Misses),
+			"pebble.filter.hits":        uint64(m.Filter.Hits),
+			"pebble.filter.misses":      uint64(m.Filter.Misses),
+			"pebble.flushes":            uint64(m.Flush.Count),
+			"pebble.compactions":        uint64(m.Compact.Count),
+		},
+		Histograms: make(map[string]enginepb.HistogramData),
+	}
+	return res, nil
 }
 
 // GetProto implements the Engine interface.
@@ -658,15 +672,18 @@ func (p *Pebble) GetEncryptionRegistries() (*EncryptionRegistries, error) {
 		}
 	}
 	if p.fileRegistry != nil {
-		rv.FileRegistry = []byte(p.fileRegistry.getRegistryCopy().String())
+		rv.FileRegistry, err = protoutil.Marshal(p.fileRegistry.getRegistryCopy())
+		if err != nil {
+			return nil, err
+		}
 	}
 	return rv, nil
 }
 
 // GetEnvStats implements the Engine interface.
 func (p *Pebble) GetEnvStats() (*EnvStats, error) {
-	// TODO(sumeer): make the stats complete. There are no bytes stats. The TotalFiles is missing
-	// files that are not in the registry (from before encryption was enabled).
+	// TODO(sumeer): make the stats complete. The TotalFiles is missing files that are not in
+	// the registry (from before encryption was enabled).
 	stats := &EnvStats{}
 	if p.statsHandler == nil {
 		return stats, nil
@@ -678,14 +695,14 @@ func (p *Pebble) GetEnvStats() (*EnvStats, error) {
 		return nil, err
 	}
 	fr := p.fileRegistry.getRegistryCopy()
-	if fr != nil {
-		stats.TotalFiles = uint64(len(fr.Files))
+	if fr == nil {
+		return stats, nil
 	}
 	activeKeyID, err := p.statsHandler.GetActiveDataKeyID()
 	if err != nil {
 		return nil, err
 	}
-	for _, entry := range fr.Files {
+	for filename, entry := range fr.Files {
 		keyID, err := p.statsHandler.GetKeyIDFromSettings(entry.EncryptionSettings)
 		if err != nil {
 			return nil, err
@@ -693,8 +710,21 @@ func (p *Pebble) GetEnvStats() (*EnvStats, error) {
 		if len(keyID) == 0 {
 			keyID = "plain"
 		}
+		// The registry may briefly contain files which were already removed.
+		// Those are skipped.
+		// Files in the DB directory are recorded with paths relative to it.
+		if !filepath.IsAbs(filename) {
+			filename = p.fs.PathJoin(p.path, filename)
+		}
+		info, err := p.fs.Stat(filename)
+		if err != nil {
+			continue
+		}
+		stats.TotalFiles++
+		stats.TotalBytes += uint64(info.Size())
 		if keyID == activeKeyID {
 			stats.ActiveKeyFiles++
+			stats.ActiveKeyBytes += uint64(info.Size())
 		}
 	}
 	return stats, nil
@@ -972,44 +1002,43 @@ func (p *pebbleReadOnly) NewIterator(opts IterOptions) Iterator {
 	return iter
 }
 
-// Writer methods are not implemented for pebbleReadOnly. Ideally, the code
-// could be refactored so that a Reader could be supplied to evaluateBatch
+// The Writer methods of pebbleReadOnly return errReadOnlyEngine.
 
 // Writer is the write interface to an engine's data.
 func (p *pebbleReadOnly) ApplyBatchRepr(repr []byte, sync bool) error {
-	panic("not implemented")
+	return errReadOnlyEngine
 }
 
 func (p *pebbleReadOnly) Clear(key MVCCKey) error {
-	panic("not implemented")
+	return errReadOnlyEngine
 }
 
 func (p *pebbleReadOnly) SingleClear(key MVCCKey) error {
-	panic("not implemented")
+	return errReadOnlyEngine
 }
 
 func (p *pebbleReadOnly) ClearRange(start, end MVCCKey) error {
-	panic("not implemented")
+	return errReadOnlyEngine
 }
 
 func (p *pebbleReadOnly) ClearIterRange(iter Iterator, start, end roachpb.Key) error {
-	panic("not implemented")
+	return errReadOnlyEngine
 }
 
 func (p *pebbleReadOnly) Merge(key MVCCKey, value []byte) error {
-	panic("not implemented")
+	return errReadOnlyEngine
 }
 
 func (p *pebbleReadOnly) Put(key MVCCKey, value []byte) error {
-	panic("not implemented")
+	return errReadOnlyEngine
 }
 
 func (p *pebbleReadOnly) LogData(data []byte) error {
-	panic("not implemented")
+	return errReadOnlyEngine
 }
 
 func (p *pebbleReadOnly) LogLogicalOp(op MVCCLogicalOpType, details MVCCLogicalOpDetails) {
-	panic("not implemented")
+	// No-op. Logical logging disabled.
 }
 
 // pebbleSnapshot represents a snapshot created using Pebble.NewSnapshot().
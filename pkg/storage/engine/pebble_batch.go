@@ -95,7 +95,7 @@ func (p *pebbleBatch) ExportToSst(
 	exportAllRevisions bool,
 	io IterOptions,
 ) ([]byte, roachpb.BulkOpSummary, error) {
-	panic("unimplemented")
+	return pebbleExportToSst(p, startKey, endKey, startTS, endTS, exportAllRevisions, io)
 }
 
 // Get implements the Batch interface.
@@ -18,6 +18,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
@@ -264,3 +265,64 @@ func TestPebbleSeparatorSuccessor(t *testing.T) {
 	}
 
 }
+
+func TestPebbleBatchExportToSst(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	eng := createTestPebbleEngine()
+	defer eng.Close()
+
+	batch := eng.NewBatch()
+	defer batch.Close()
+	for i := 0; i < 10; i++ {
+		key := MVCCKey{[]byte{byte(i)}, hlc.Timestamp{WallTime: int64(i + 1)}}
+		if err := batch.Put(key, []byte("foo")); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The batch exports its own writes, which the engine doesn't see until the
+	// batch is committed.
+	export := func(r Reader) ([]byte, roachpb.BulkOpSummary) {
+		t.Helper()
+		data, summary, err := r.ExportToSst(roachpb.KeyMin, roachpb.KeyMax,
+			hlc.Timestamp{WallTime: 5}, hlc.Timestamp{WallTime: 100},
+			false /* exportAllRevisions */, IterOptions{UpperBound: roachpb.KeyMax})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return data, summary
+	}
+	batchData, batchSummary := export(batch)
+	if _, summary := export(eng); summary.DataSize != 0 {
+		t.Fatalf("expected the engine to export nothing, got %+v", summary)
+	}
+	if err := batch.Commit(false /* sync */); err != nil {
+		t.Fatal(err)
+	}
+	engData, engSummary := export(eng)
+	if !bytes.Equal(batchData, engData) || batchSummary != engSummary {
+		t.Fatalf("expected the batch to export %+v, got %+v", engSummary, batchSummary)
+	}
+}
+
+func TestPebbleGetTickersAndHistograms(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	eng := createTestPebbleEngine()
+	defer eng.Close()
+
+	if err := eng.Put(makeMVCCKey("a"), []byte("foo")); err != nil {
+		t.Fatal(err)
+	}
+	if err := eng.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	stats, err := eng.GetTickersAndHistograms()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := stats.Tickers["pebble.flushes"]; n == 0 {
+		t.Fatalf("expected a flush, got tickers %v", stats.Tickers)
+	}
+}
@@ -1061,44 +1061,43 @@ func (r *rocksDBReadOnly) NewIterator(opts IterOptions) Iterator {
 	return iter
 }
 
-// Writer methods are not implemented for rocksDBReadOnly. Ideally, the code
-// could be refactored so that a Reader could be supplied to evaluateBatch
+// The Writer methods of rocksDBReadOnly return errReadOnlyEngine.
 
 // Writer is the write interface to an engine's data.
 func (r *rocksDBReadOnly) ApplyBatchRepr(repr []byte, sync bool) error {
-	panic("not implemented")
+	return errReadOnlyEngine
 }
 
 func (r *rocksDBReadOnly) Clear(key MVCCKey) error {
-	panic("not implemented")
+	return errReadOnlyEngine
 }
 
 func (r *rocksDBReadOnly) SingleClear(key MVCCKey) error {
-	panic("not implemented")
+	return errReadOnlyEngine
 }
 
 func (r *rocksDBReadOnly) ClearRange(start, end MVCCKey) error {
-	panic("not implemented")
+	return errReadOnlyEngine
 }
 
 func (r *rocksDBReadOnly) ClearIterRange(iter Iterator, start, end roachpb.Key) error {
-	panic("not implemented")
+	return errReadOnlyEngine
 }
 
 func (r *rocksDBReadOnly) Merge(key MVCCKey, value []byte) error {
-	panic("not implemented")
+	return errReadOnlyEngine
 }
 
 func (r *rocksDBReadOnly) Put(key MVCCKey, value []byte) error {
-	panic("not implemented")
+	return errReadOnlyEngine
 }
 
 func (r *rocksDBReadOnly) LogData(data []byte) error {
-	panic("not implemented")
+	return errReadOnlyEngine
 }
 
 func (r *rocksDBReadOnly) LogLogicalOp(op MVCCLogicalOpType, details MVCCLogicalOpDetails) {
-	panic("not implemented")
+	// No-op. Logical logging disabled.
 }
 
 // NewBatch returns a new batch wrapping this rocksdb engine.
@@ -14,6 +14,7 @@ import (
 	"io"
 
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/pebble"
 	"github.com/cockroachdb/pebble/sstable"
 	"github.com/pkg/errors"
 )
@@ -92,9 +93,47 @@ func (fw *SSTWriter) Put(key MVCCKey, value []byte) error {
 	return fw.fw.Set(fw.scratch, value)
 }
 
-// ApplyBatchRepr implements the Writer interface.
+// ApplyBatchRepr implements the Writer interface. The entries of the batch are
+// added to the sstable in the order in which they appear in the batch, so they
+// must be sorted by key.
 func (fw *SSTWriter) ApplyBatchRepr(repr []byte, sync bool) error {
-	panic("unimplemented")
+	if fw.fw == nil {
+		return errors.New("cannot call ApplyBatchRepr on a closed writer")
+	}
+	r, err := NewRocksDBBatchReader(repr)
+	if err != nil {
+		return err
+	}
+	for r.Next() {
+		if r.BatchType() == BatchTypeLogData {
+			continue
+		}
+		key, err := r.MVCCKey()
+		if err != nil {
+			return err
+		}
+		switch r.BatchType() {
+		case BatchTypeValue:
+			err = fw.Put(key, r.Value())
+		case BatchTypeDeletion:
+			err = fw.Clear(key)
+		case BatchTypeSingleDeletion:
+			err = fw.SingleClear(key)
+		case BatchTypeMerge:
+			err = fw.Merge(key, r.Value())
+		case BatchTypeRangeDeletion:
+			var endKey MVCCKey
+			if endKey, err = r.MVCCEndKey(); err == nil {
+				err = fw.ClearRange(key, endKey)
+			}
+		default:
+			err = errors.Errorf("unexpected batch entry type %d", r.BatchType())
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return r.Error()
 }
 
 // Clear implements the Writer interface.
@@ -109,7 +148,15 @@ func (fw *SSTWriter) Clear(key MVCCKey) error {
 
 // SingleClear implements the Writer interface.
 func (fw *SSTWriter) SingleClear(key MVCCKey) error {
-	panic("unimplemented")
+	if fw.fw == nil {
+		return errors.New("cannot call SingleClear on a closed writer")
+	}
+	fw.scratch = EncodeKeyToBuf(fw.scratch[:0], key)
+	fw.DataSize += int64(len(key.Key))
+	return fw.fw.Add(pebble.InternalKey{
+		UserKey: fw.scratch,
+		Trailer: uint64(pebble.InternalKeyKindSingleDelete),
+	}, nil /* value */)
 }
 
 // ClearIterRange implements the Writer interface.
@@ -175,3 +175,44 @@ func BenchmarkWriteSSTable(b *testing.B) {
 	}
 	b.StopTimer()
 }
+
+func TestSSTWriterApplyBatchRepr(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	kvs := makeIntTableKVs(10, 10, 1)
+	var b engine.RocksDBBatchBuilder
+	for i := range kvs {
+		b.Put(kvs[i].Key, kvs[i].Value)
+		b.LogData([]byte("ignored"))
+	}
+	repr := b.Finish()
+
+	f := &engine.MemFile{}
+	w := engine.MakeIngestionSSTWriter(f)
+	defer w.Close()
+	require.NoError(t, w.ApplyBatchRepr(repr, false /* sync */))
+	require.NoError(t, w.Finish())
+
+	iter, err := engine.NewMemSSTIterator(f.Data(), true /* verify */)
+	require.NoError(t, err)
+	defer iter.Close()
+	var i int
+	for iter.SeekGE(engine.MVCCKey{Key: roachpb.KeyMin}); ; iter.Next() {
+		ok, err := iter.Valid()
+		require.NoError(t, err)
+		if !ok {
+			break
+		}
+		require.Equal(t, kvs[i].Key, iter.UnsafeKey())
+		require.Equal(t, kvs[i].Value, iter.UnsafeValue())
+		i++
+	}
+	require.Equal(t, len(kvs), i)
+
+	// The entries of the batch must be sorted.
+	b.Put(kvs[1].Key, kvs[1].Value)
+	b.Put(kvs[0].Key, kvs[0].Value)
+	w = engine.MakeIngestionSSTWriter(&engine.MemFile{})
+	defer w.Close()
+	require.Error(t, w.ApplyBatchRepr(b.Finish(), false /* sync */))
+}
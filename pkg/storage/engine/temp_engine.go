@@ -136,6 +136,30 @@ func NewPebbleTempEngine(
 	if tempStorage.InMemory {
 		opts.FS = vfs.NewMem()
 		path = ""
+	} else if len(storeSpec.ExtraOptions) > 0 {
+		// Encryption at rest is enabled on the store, so the temp engine is
+		// encrypted with the same store keys. Its file and data key registries
+		// live in the temp directory, which is wiped on restart.
+		if NewEncryptedEnvFunc == nil {
+			return nil, fmt.Errorf("encryption is enabled but no function to create the encrypted env")
+		}
+		if opts.FS == nil {
+			opts.FS = vfs.Default
+		}
+		if err := opts.FS.MkdirAll(path, 0755); err != nil {
+			return nil, err
+		}
+		fileRegistry := &PebbleFileRegistry{FS: opts.FS, DBDir: path}
+		if err := fileRegistry.Load(); err != nil {
+			return nil, err
+		}
+		var err error
+		opts.FS, _, err = NewEncryptedEnvFunc(
+			opts.FS, fileRegistry, path, false /* readOnly */, storeSpec.ExtraOptions,
+		)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	p, err := pebble.Open(path, opts)
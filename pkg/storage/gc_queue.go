@@ -149,6 +149,15 @@ func (gcq *gcQueue) shouldQueue(
 	ctx context.Context, now hlc.Timestamp, repl *Replica, sysCfg *config.SystemConfig,
 ) (bool, float64) {
 	r := makeGCQueueScore(ctx, repl, now, sysCfg)
+	if !r.ShouldQueue {
+		return false, 0
+	}
+	// Don't queue replicas whose GC threshold is held back by a protected
+	// timestamp record.
+	_, zone := repl.DescAndZone()
+	if canGC, _ := repl.checkProtectedTimestampsForGC(ctx, *zone.GC); !canGC {
+		return false, 0
+	}
 	return r.ShouldQueue, r.FinalScore
 }
 
@@ -558,7 +567,14 @@ func (gcq *gcQueue) process(ctx context.Context, repl *Replica, sysCfg *config.S
 	// Lookup the descriptor and GC policy for the zone containing this key range.
 	desc, zone := repl.DescAndZone()
 
-	info, err := RunGC(ctx, desc, snap, now, *zone.GC, &replicaGCer{repl: repl},
+	// Make sure that the GC threshold stays below the protected timestamps
+	// covering the range.
+	canGC, gcTimestamp := repl.checkProtectedTimestampsForGC(ctx, *zone.GC)
+	if !canGC {
+		return nil
+	}
+
+	info, err := RunGC(ctx, desc, snap, gcTimestamp, *zone.GC, &replicaGCer{repl: repl},
 		func(ctx context.Context, intents []roachpb.Intent) error {
 			intentCount, err := repl.store.intentResolver.CleanupIntents(ctx, intents, now, roachpb.PUSH_ABORT)
 			if err == nil {
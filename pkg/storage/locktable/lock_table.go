@@ -0,0 +1,589 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package locktable
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/storage/contention"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
+	"github.com/google/btree"
+)
+
+// DefaultMaxLocks is the default maximum number of locks tracked by a Table.
+const DefaultMaxLocks = 10000
+
+// Request describes the keys that a request accesses, for the purpose of
+// finding the locks it conflicts with.
+type Request struct {
+	// Txn is the transaction of the request, or nil if the request is not
+	// transactional.
+	Txn *enginepb.TxnMeta
+	// Timestamp is the timestamp at which the request reads.
+	Timestamp hlc.Timestamp
+	// LockSpans are the spans that the request writes or reads with locking.
+	// They conflict with all the locks held by other transactions.
+	LockSpans []roachpb.Span
+	// ReadSpans are the spans that the request reads without locking. They
+	// only conflict with the replicated locks (i.e. intents) of other
+	// transactions at or below Timestamp.
+	ReadSpans []roachpb.Span
+}
+
+func (r *Request) txnID() uuid.UUID {
+	if r.Txn == nil {
+		return uuid.UUID{}
+	}
+	return r.Txn.ID
+}
+
+// Guard tracks the position of a request in the wait queues of a Table. It is
+// returned by ScanAndEnqueue, is reused across the request's retries, and must
+// be passed to Dequeue once the request is done.
+type Guard struct {
+	req Request
+	// signal is notified when the lock the request waits on is released,
+	// updated, or reserved for the request.
+	signal chan struct{}
+
+	// The following fields are protected by Table.mu.
+
+	// waitingOn is the lock in whose queue the request waits, if any, and elem
+	// is the request's element in that queue.
+	waitingOn *lockState
+	elem      *list.Element
+	// waitWrite is set if the request waits on waitingOn to write it or lock
+	// it, as opposed to waiting to read it.
+	waitWrite bool
+	// requeueFront is a lock that the request had reserved and then found
+	// held again. The request waits at the front of its queue so that it
+	// doesn't lose its turn.
+	requeueFront *lockState
+	// reserved are the locks reserved for the request after their holders
+	// released them.
+	reserved []*lockState
+	// blockingTxnID and blockingKey describe the lock the request waits on,
+	// for the purpose of recording contention events.
+	blockingTxnID uuid.UUID
+	blockingKey   roachpb.Key
+}
+
+// lockHolder describes the transaction holding a lock.
+type lockHolder struct {
+	txn enginepb.TxnMeta
+	// ts is the timestamp at which the lock is held. Non-locking reads below
+	// it don't conflict with the lock.
+	ts hlc.Timestamp
+	// replicated is set if the transaction wrote an intent on the key, and
+	// unreplicated if it acquired the lock through a locking read. Both can
+	// be set at once.
+	replicated, unreplicated bool
+}
+
+// lockState is the state of a single key in the Table.
+type lockState struct {
+	key roachpb.Key
+	// holder is the transaction holding the lock, or nil if the lock isn't
+	// held.
+	holder *lockHolder
+	// reservation is the request the lock was handed to when its holder
+	// released it while requests were waiting to write it. Other writers wait
+	// behind it until it is done, which makes waiting first-in, first-out.
+	// Only set if holder is nil.
+	reservation *Guard
+	// waiters is the queue of *Guards waiting on the lock.
+	waiters list.List
+}
+
+var _ btree.Item = (*lockState)(nil)
+
+// Less implements the btree.Item interface.
+func (l *lockState) Less(than btree.Item) bool {
+	return l.key.Compare(than.(*lockState).key) < 0
+}
+
+func (l *lockState) String() string {
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "%s:", l.key)
+	if h := l.holder; h != nil {
+		fmt.Fprintf(&buf, " held by %s @ %s", h.txn.ID.Short(), h.ts)
+		if h.replicated {
+			buf.WriteString(" (replicated)")
+		}
+		if h.unreplicated {
+			buf.WriteString(" (unreplicated)")
+		}
+	} else if l.reservation != nil {
+		fmt.Fprintf(&buf, " reserved by %s", l.reservation.req.txnID().Short())
+	}
+	if n := l.waiters.Len(); n > 0 {
+		fmt.Fprintf(&buf, ", %d waiting", n)
+	}
+	return buf.String()
+}
+
+// A Table is an in-memory table of the locks held on the keys of a range,
+// along with the queues of requests waiting for them. It sits next to the
+// range's latch manager: requests scan it while holding their latches, and
+// release their latches before waiting on a conflicting lock.
+//
+// The table tracks two kinds of locks. Replicated locks are the intents of
+// transactions, which are only added to the table once a request discovers
+// them during evaluation. Unreplicated locks are acquired by locking reads and
+// only exist in the table of the leaseholder; they are lost when the lease
+// changes hands. The table is therefore neither complete nor authoritative,
+// and evaluation remains responsible for detecting conflicting intents.
+//
+// Requests waiting on a lock queue up in first-in, first-out order. When the
+// lock's holder releases it, the waiting readers are woken up and the lock is
+// reserved for the first waiting writer, which then has the first shot at
+// acquiring it. This avoids waiting requests having to push the lock holder
+// and to race each other to the lock once it is released. Requests only push
+// the holder of a lock if it isn't released after a delay, to detect
+// deadlocks and abandoned locks.
+type Table struct {
+	maxLocks   int
+	stopper    *stop.Stopper
+	contention *contention.Registry
+
+	mu struct {
+		syncutil.Mutex
+		locks *btree.BTree
+	}
+}
+
+// Make returns an initialized Table which tracks at most maxLocks locks. If
+// registry is not nil, an event is recorded in it whenever a request waits on
+// a lock held by a transaction.
+func Make(maxLocks int, stopper *stop.Stopper, registry *contention.Registry) *Table {
+	t := &Table{
+		maxLocks:   maxLocks,
+		stopper:    stopper,
+		contention: registry,
+	}
+	t.mu.locks = btree.New(8 /* degree */)
+	return t
+}
+
+// ScanAndEnqueue scans the table for locks conflicting with the request. If
+// it finds one, the request is added to the lock's queue and wait is true: the
+// caller must release its latches and call WaitOn. Otherwise, the request can
+// proceed to evaluation. g is the Guard returned by a previous call for the
+// same request, or nil.
+func (t *Table) ScanAndEnqueue(req Request, g *Guard) (_ *Guard, wait bool) {
+	if g == nil {
+		g = &Guard{signal: make(chan struct{}, 1)}
+	}
+	g.req = req
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.removeWaiterLocked(g)
+	// Drop any stale notification. Notifications are sent under the mutex, so
+	// none can be lost here.
+	select {
+	case <-g.signal:
+	default:
+	}
+
+	for _, sp := range req.LockSpans {
+		if l := t.findConflictLocked(g, sp, true /* write */); l != nil {
+			t.enqueueLocked(g, l, true /* write */)
+			return g, true
+		}
+	}
+	for _, sp := range req.ReadSpans {
+		if l := t.findConflictLocked(g, sp, false /* write */); l != nil {
+			t.enqueueLocked(g, l, false /* write */)
+			return g, true
+		}
+	}
+	return g, false
+}
+
+// findConflictLocked returns the first lock in the span which the request
+// conflicts with, if any.
+func (t *Table) findConflictLocked(g *Guard, sp roachpb.Span, write bool) *lockState {
+	var conflict *lockState
+	if len(sp.EndKey) == 0 {
+		if l, ok := t.mu.locks.Get(&lockState{key: sp.Key}).(*lockState); ok && t.conflicts(g, l, write) {
+			conflict = l
+		}
+		return conflict
+	}
+	t.mu.locks.AscendRange(&lockState{key: sp.Key}, &lockState{key: sp.EndKey},
+		func(i btree.Item) bool {
+			l := i.(*lockState)
+			if t.conflicts(g, l, write) {
+				conflict = l
+				return false
+			}
+			return true
+		})
+	return conflict
+}
+
+// conflicts returns whether the request conflicts with the lock.
+func (t *Table) conflicts(g *Guard, l *lockState, write bool) bool {
+	txnID := g.req.txnID()
+	if h := l.holder; h != nil {
+		if txnID != (uuid.UUID{}) && h.txn.ID == txnID {
+			return false
+		}
+		if write {
+			return true
+		}
+		// Non-locking reads only conflict with intents at or below their
+		// timestamp.
+		return h.replicated && !g.req.Timestamp.Less(h.ts)
+	}
+	// The lock isn't held. Writers still have to wait for the request the
+	// lock is reserved for, if any.
+	if !write || l.reservation == nil || l.reservation == g {
+		return false
+	}
+	resTxnID := l.reservation.req.txnID()
+	return txnID == (uuid.UUID{}) || resTxnID != txnID
+}
+
+func (t *Table) enqueueLocked(g *Guard, l *lockState, write bool) {
+	if g.requeueFront == l {
+		g.elem = l.waiters.PushFront(g)
+	} else {
+		g.elem = l.waiters.PushBack(g)
+	}
+	g.requeueFront = nil
+	g.waitingOn = l
+	g.waitWrite = write
+	g.blockingKey = l.key
+	g.blockingTxnID = uuid.UUID{}
+	if l.holder != nil {
+		g.blockingTxnID = l.holder.txn.ID
+	} else if l.reservation != nil {
+		g.blockingTxnID = l.reservation.req.txnID()
+	}
+}
+
+// removeWaiterLocked removes the request from the queue it waits in, if any.
+func (t *Table) removeWaiterLocked(g *Guard) {
+	l := g.waitingOn
+	if l == nil {
+		return
+	}
+	l.waiters.Remove(g.elem)
+	g.waitingOn, g.elem = nil, nil
+	t.maybeDeleteLocked(l)
+}
+
+// maybeDeleteLocked removes the lock from the table if it is neither held,
+// reserved, nor waited on.
+func (t *Table) maybeDeleteLocked(l *lockState) {
+	if l.holder != nil || l.reservation != nil || l.waiters.Len() > 0 {
+		return
+	}
+	// The lock may have already been removed by Clear, and another lock may
+	// have been added for the same key since.
+	if cur := t.mu.locks.Get(l); cur == l {
+		t.mu.locks.Delete(l)
+	}
+}
+
+func notify(g *Guard) {
+	select {
+	case g.signal <- struct{}{}:
+	default:
+	}
+}
+
+// WaitOn blocks until the lock the request was enqueued on by ScanAndEnqueue
+// is released or updated, at which point the caller must acquire its latches
+// and call ScanAndEnqueue again.
+//
+// If the lock's holder doesn't release it within pushDelay, WaitOn removes
+// the request from the lock's queue and returns an intent describing the
+// lock. The caller must then push the lock holder, which detects deadlocks and
+// abandoned locks, before retrying. If the lock isn't held but reserved for a
+// request that is slow to complete, the request waiting on it takes the
+// reservation over instead.
+func (t *Table) WaitOn(
+	ctx context.Context, g *Guard, pushDelay time.Duration,
+) (*roachpb.Intent, error) {
+	start := timeutil.Now()
+	defer func() {
+		t.recordContention(g, timeutil.Since(start))
+	}()
+
+	var timer timeutil.Timer
+	defer timer.Stop()
+	timer.Reset(pushDelay)
+	select {
+	case <-g.signal:
+		return nil, nil
+	case <-timer.C:
+		timer.Read = true
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-t.stopper.ShouldQuiesce():
+		return nil, &roachpb.NodeUnavailableError{}
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	l := g.waitingOn
+	if l == nil {
+		// The request was woken up concurrently with the timer firing.
+		return nil, nil
+	}
+	t.removeWaiterLocked(g)
+	if l.holder == nil {
+		// The request the lock is reserved for may itself be stuck waiting on
+		// another lock held by this request's transaction. Take the
+		// reservation over rather than risking a deadlock.
+		if l.reservation != g {
+			l.reservation = g
+			g.reserved = append(g.reserved, l)
+		}
+		return nil, nil
+	}
+	return &roachpb.Intent{
+		Span:   roachpb.Span{Key: l.key},
+		Txn:    l.holder.txn,
+		Status: roachpb.PENDING,
+	}, nil
+}
+
+func (t *Table) recordContention(g *Guard, dur time.Duration) {
+	if t.contention == nil || g.blockingTxnID == (uuid.UUID{}) {
+		return
+	}
+	t.contention.Add(contention.Event{
+		Key:           g.blockingKey,
+		BlockingTxnID: g.blockingTxnID,
+		WaitingTxnID:  g.req.txnID(),
+		Time:          timeutil.Now(),
+		Duration:      dur,
+		Source:        contention.SourceLockTable,
+	})
+}
+
+// Dequeue removes the request from the table, handing the locks that were
+// reserved for it to the next waiting requests. The Guard must not be used
+// afterwards.
+func (t *Table) Dequeue(g *Guard) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.removeWaiterLocked(g)
+	for _, l := range g.reserved {
+		if l.reservation == g {
+			l.reservation = nil
+			t.grantLocked(l)
+		}
+	}
+	g.reserved = nil
+}
+
+// grantLocked hands a lock that is no longer held or reserved to the requests
+// waiting on it. Readers are all woken up, while the lock is reserved for the
+// first writer.
+func (t *Table) grantLocked(l *lockState) {
+	if l.holder != nil || l.reservation != nil {
+		return
+	}
+	t.wakeReadersLocked(l)
+	if e := l.waiters.Front(); e != nil {
+		w := l.waiters.Remove(e).(*Guard)
+		w.waitingOn, w.elem = nil, nil
+		l.reservation = w
+		w.reserved = append(w.reserved, l)
+		notify(w)
+		return
+	}
+	t.maybeDeleteLocked(l)
+}
+
+// wakeReadersLocked wakes up the non-locking readers waiting on the lock.
+func (t *Table) wakeReadersLocked(l *lockState) {
+	var next *list.Element
+	for e := l.waiters.Front(); e != nil; e = next {
+		next = e.Next()
+		if w := e.Value.(*Guard); !w.waitWrite {
+			l.waiters.Remove(e)
+			w.waitingOn, w.elem = nil, nil
+			notify(w)
+		}
+	}
+}
+
+// AddDiscoveredLocks adds the intents that the request discovered during
+// evaluation to the table, so that the request can wait on them in the next
+// call to ScanAndEnqueue. It returns false, leaving the table unchanged, if
+// the intents can't be tracked; the request must then push their transactions
+// instead.
+func (t *Table) AddDiscoveredLocks(g *Guard, intents []roachpb.Intent) bool {
+	for i := range intents {
+		if len(intents[i].EndKey) != 0 || keys.IsLocal(intents[i].Key) {
+			return false
+		}
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.mu.locks.Len()+len(intents) > t.maxLocks {
+		return false
+	}
+	for i := range intents {
+		intent := &intents[i]
+		l := t.getOrCreateLocked(intent.Key)
+		if h := l.holder; h != nil && h.txn.ID == intent.Txn.ID {
+			h.replicated = true
+			if h.txn.Epoch < intent.Txn.Epoch {
+				h.txn = intent.Txn
+			}
+			h.ts.Forward(intent.Txn.WriteTimestamp)
+		} else {
+			// The intent is the ground truth: a different holder must have
+			// released the lock without the table finding out.
+			l.holder = &lockHolder{
+				txn:        intent.Txn,
+				ts:         intent.Txn.WriteTimestamp,
+				replicated: true,
+			}
+		}
+		if l.reservation == g {
+			g.requeueFront = l
+		}
+		l.reservation = nil
+	}
+	return true
+}
+
+// AcquireLock records an unreplicated lock acquired by the transaction on the
+// key. The caller must hold write latches on the key. Locks on local keys, and
+// locks beyond the table's capacity, are not tracked.
+func (t *Table) AcquireLock(txn *enginepb.TxnMeta, key roachpb.Key) {
+	if keys.IsLocal(key) {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	l, ok := t.mu.locks.Get(&lockState{key: key}).(*lockState)
+	if !ok {
+		if t.mu.locks.Len() >= t.maxLocks {
+			return
+		}
+		l = t.getOrCreateLocked(key)
+	}
+	if h := l.holder; h != nil && h.txn.ID == txn.ID {
+		h.unreplicated = true
+	} else {
+		l.holder = &lockHolder{txn: *txn, ts: txn.WriteTimestamp, unreplicated: true}
+	}
+	l.reservation = nil
+}
+
+func (t *Table) getOrCreateLocked(key roachpb.Key) *lockState {
+	if l, ok := t.mu.locks.Get(&lockState{key: key}).(*lockState); ok {
+		return l
+	}
+	l := &lockState{key: append(roachpb.Key(nil), key...)}
+	t.mu.locks.ReplaceOrInsert(l)
+	return l
+}
+
+// UpdateLocks updates the locks held by the transaction in the span after its
+// intents in the span were resolved with the provided status. The locks are
+// released if the transaction is finalized or has restarted. Otherwise, the
+// transaction was pushed and the timestamp of its locks is forwarded, which
+// lets the readers below the new timestamp proceed.
+func (t *Table) UpdateLocks(
+	span roachpb.Span, txn *enginepb.TxnMeta, status roachpb.TransactionStatus,
+) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var held []*lockState
+	visit := func(i btree.Item) bool {
+		l := i.(*lockState)
+		if l.holder != nil && l.holder.txn.ID == txn.ID {
+			held = append(held, l)
+		}
+		return true
+	}
+	if len(span.EndKey) == 0 {
+		if l := t.mu.locks.Get(&lockState{key: span.Key}); l != nil {
+			visit(l)
+		}
+	} else {
+		t.mu.locks.AscendRange(&lockState{key: span.Key}, &lockState{key: span.EndKey}, visit)
+	}
+
+	for _, l := range held {
+		if status.IsFinalized() || l.holder.txn.Epoch < txn.Epoch {
+			l.holder = nil
+			t.grantLocked(l)
+			continue
+		}
+		l.holder.ts.Forward(txn.WriteTimestamp)
+		t.wakeReadersLocked(l)
+	}
+}
+
+// Clear removes all the locks from the table and wakes up all the requests
+// waiting on them. It is called when the table can no longer be trusted to
+// reflect the locks held on the range, for instance when the lease changes
+// hands or the range's bounds change.
+func (t *Table) Clear() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.mu.locks.Ascend(func(i btree.Item) bool {
+		l := i.(*lockState)
+		for e := l.waiters.Front(); e != nil; e = e.Next() {
+			w := e.Value.(*Guard)
+			w.waitingOn, w.elem = nil, nil
+			notify(w)
+		}
+		l.waiters.Init()
+		l.holder = nil
+		l.reservation = nil
+		return true
+	})
+	t.mu.locks.Clear(false /* addNodesToFreelist */)
+}
+
+// Len returns the number of locks in the table, including the locks that are
+// no longer held but are still reserved or waited on.
+func (t *Table) Len() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.mu.locks.Len()
+}
+
+func (t *Table) String() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var buf bytes.Buffer
+	t.mu.locks.Ascend(func(i btree.Item) bool {
+		fmt.Fprintf(&buf, "%s\n", i.(*lockState))
+		return true
+	})
+	return buf.String()
+}
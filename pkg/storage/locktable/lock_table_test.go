@@ -0,0 +1,267 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package locktable
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func makeTS(wallTime int64) hlc.Timestamp {
+	return hlc.Timestamp{WallTime: wallTime}
+}
+
+func makeTxn(wallTime int64) *enginepb.TxnMeta {
+	return &enginepb.TxnMeta{ID: uuid.MakeV4(), WriteTimestamp: makeTS(wallTime)}
+}
+
+func spanOf(key string) roachpb.Span {
+	return roachpb.Span{Key: roachpb.Key(key)}
+}
+
+func writeReq(txn *enginepb.TxnMeta, key string) Request {
+	return Request{Txn: txn, Timestamp: txn.WriteTimestamp, LockSpans: []roachpb.Span{spanOf(key)}}
+}
+
+func readReq(txn *enginepb.TxnMeta, ts hlc.Timestamp, key string) Request {
+	return Request{Txn: txn, Timestamp: ts, ReadSpans: []roachpb.Span{spanOf(key)}}
+}
+
+func intentOn(txn *enginepb.TxnMeta, key string) roachpb.Intent {
+	return roachpb.Intent{Span: spanOf(key), Txn: *txn, Status: roachpb.PENDING}
+}
+
+func signaled(g *Guard) bool {
+	return len(g.signal) > 0
+}
+
+// TestLockTableConflicts verifies which requests conflict with replicated and
+// unreplicated locks.
+func TestLockTableConflicts(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	stopper := stop.NewStopper()
+	defer stopper.Stop(context.Background())
+	lt := Make(DefaultMaxLocks, stopper, nil /* registry */)
+
+	holder := makeTxn(10)
+	other := makeTxn(20)
+	lt.AcquireLock(holder, roachpb.Key("a"))
+	g, wait := lt.ScanAndEnqueue(Request{ReadSpans: []roachpb.Span{spanOf("b")}}, nil)
+	require.False(t, wait)
+	require.True(t, lt.AddDiscoveredLocks(g, []roachpb.Intent{intentOn(holder, "b")}))
+	lt.Dequeue(g)
+	require.Equal(t, 2, lt.Len())
+
+	testCases := []struct {
+		name string
+		req  Request
+		wait bool
+	}{
+		{"holder writes", writeReq(holder, "a"), false},
+		{"writer on unreplicated lock", writeReq(other, "a"), true},
+		{"reader on unreplicated lock", readReq(other, makeTS(30), "a"), false},
+		{"writer on intent", writeReq(other, "b"), true},
+		{"reader above intent", readReq(other, makeTS(30), "b"), true},
+		{"reader below intent", readReq(other, makeTS(5), "b"), false},
+		{"non-transactional reader above intent", readReq(nil, makeTS(30), "b"), true},
+		{"ranged writer", Request{
+			Txn: other, LockSpans: []roachpb.Span{{Key: roachpb.Key("a0"), EndKey: roachpb.Key("c")}},
+		}, true},
+		{"writer without conflict", writeReq(other, "c"), false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g, wait := lt.ScanAndEnqueue(tc.req, nil)
+			defer lt.Dequeue(g)
+			require.Equal(t, tc.wait, wait)
+		})
+	}
+}
+
+// TestLockTableFIFO verifies that a released lock is reserved for the writers
+// waiting on it in the order in which they arrived, while the waiting readers
+// are all woken up at once.
+func TestLockTableFIFO(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	stopper := stop.NewStopper()
+	defer stopper.Stop(context.Background())
+	lt := Make(DefaultMaxLocks, stopper, nil /* registry */)
+
+	holder := makeTxn(10)
+	lt.AcquireLock(holder, roachpb.Key("a"))
+	require.True(t, lt.AddDiscoveredLocks(&Guard{}, []roachpb.Intent{intentOn(holder, "a")}))
+
+	w1, w2 := makeTxn(20), makeTxn(30)
+	g1, wait := lt.ScanAndEnqueue(writeReq(w1, "a"), nil)
+	require.True(t, wait)
+	g2, wait := lt.ScanAndEnqueue(writeReq(w2, "a"), nil)
+	require.True(t, wait)
+	gr, wait := lt.ScanAndEnqueue(readReq(makeTxn(40), makeTS(40), "a"), nil)
+	require.True(t, wait)
+
+	lt.UpdateLocks(spanOf("a"), holder, roachpb.COMMITTED)
+	require.True(t, signaled(g1))
+	require.False(t, signaled(g2))
+	require.True(t, signaled(gr))
+
+	// The reader proceeds, as does the first writer. The second writer waits
+	// for the first one, which holds the reservation.
+	_, wait = lt.ScanAndEnqueue(gr.req, gr)
+	require.False(t, wait)
+	lt.Dequeue(gr)
+	_, wait = lt.ScanAndEnqueue(g1.req, g1)
+	require.False(t, wait)
+	_, wait = lt.ScanAndEnqueue(g2.req, g2)
+	require.True(t, wait)
+
+	// Once the first writer is done, the lock is reserved for the second.
+	lt.Dequeue(g1)
+	require.True(t, signaled(g2))
+	intent, err := lt.WaitOn(context.Background(), g2, time.Hour)
+	require.NoError(t, err)
+	require.Nil(t, intent)
+	_, wait = lt.ScanAndEnqueue(g2.req, g2)
+	require.False(t, wait)
+	lt.Dequeue(g2)
+	require.Equal(t, 0, lt.Len())
+}
+
+// TestLockTableUpdateLocks verifies that pushing the holder of a lock lets the
+// readers below its new timestamp proceed, and that the locks of a restarted
+// transaction are released.
+func TestLockTableUpdateLocks(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	stopper := stop.NewStopper()
+	defer stopper.Stop(context.Background())
+	lt := Make(DefaultMaxLocks, stopper, nil /* registry */)
+
+	holder := makeTxn(10)
+	require.True(t, lt.AddDiscoveredLocks(&Guard{}, []roachpb.Intent{
+		intentOn(holder, "a"), intentOn(holder, "b"),
+	}))
+	gr, wait := lt.ScanAndEnqueue(readReq(nil, makeTS(20), "a"), nil)
+	require.True(t, wait)
+	defer lt.Dequeue(gr)
+	gw, wait := lt.ScanAndEnqueue(writeReq(makeTxn(20), "b"), nil)
+	require.True(t, wait)
+	defer lt.Dequeue(gw)
+
+	pushed := *holder
+	pushed.WriteTimestamp = makeTS(25)
+	lt.UpdateLocks(roachpb.Span{Key: roachpb.Key("a"), EndKey: roachpb.Key("c")}, &pushed, roachpb.PENDING)
+	require.True(t, signaled(gr))
+	require.False(t, signaled(gw))
+	_, wait = lt.ScanAndEnqueue(gr.req, gr)
+	require.False(t, wait)
+
+	restarted := pushed
+	restarted.Epoch++
+	lt.UpdateLocks(spanOf("b"), &restarted, roachpb.PENDING)
+	require.True(t, signaled(gw))
+	_, wait = lt.ScanAndEnqueue(gw.req, gw)
+	require.False(t, wait)
+}
+
+// TestLockTablePushDelay verifies that a request waiting on a lock for longer
+// than the push delay is asked to push its holder, and that it takes over the
+// reservation of a lock that isn't held.
+func TestLockTablePushDelay(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	stopper := stop.NewStopper()
+	defer stopper.Stop(context.Background())
+	lt := Make(DefaultMaxLocks, stopper, nil /* registry */)
+	ctx := context.Background()
+
+	holder := makeTxn(10)
+	lt.AcquireLock(holder, roachpb.Key("a"))
+	g1, wait := lt.ScanAndEnqueue(writeReq(makeTxn(20), "a"), nil)
+	require.True(t, wait)
+	defer lt.Dequeue(g1)
+	intent, err := lt.WaitOn(ctx, g1, time.Millisecond)
+	require.NoError(t, err)
+	require.NotNil(t, intent)
+	require.Equal(t, holder.ID, intent.Txn.ID)
+	require.Equal(t, roachpb.Key("a"), intent.Key)
+
+	// Once the lock is released and reserved for the first writer, a second
+	// writer waiting on it steals the reservation if the first one is slow.
+	_, wait = lt.ScanAndEnqueue(g1.req, g1)
+	require.True(t, wait)
+	lt.UpdateLocks(spanOf("a"), holder, roachpb.ABORTED)
+	g2, wait := lt.ScanAndEnqueue(writeReq(makeTxn(30), "a"), nil)
+	require.True(t, wait)
+	defer lt.Dequeue(g2)
+	intent, err = lt.WaitOn(ctx, g2, time.Millisecond)
+	require.NoError(t, err)
+	require.Nil(t, intent)
+	_, wait = lt.ScanAndEnqueue(g2.req, g2)
+	require.False(t, wait)
+	_, wait = lt.ScanAndEnqueue(g1.req, g1)
+	require.True(t, wait)
+}
+
+// TestLockTableClear verifies that clearing the table wakes up all the
+// waiting requests.
+func TestLockTableClear(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	stopper := stop.NewStopper()
+	defer stopper.Stop(context.Background())
+	lt := Make(DefaultMaxLocks, stopper, nil /* registry */)
+
+	holder := makeTxn(10)
+	lt.AcquireLock(holder, roachpb.Key("a"))
+	lt.AcquireLock(holder, roachpb.Key("b"))
+	var guards []*Guard
+	for _, key := range []string{"a", "b", "b"} {
+		g, wait := lt.ScanAndEnqueue(writeReq(makeTxn(20), key), nil)
+		require.True(t, wait)
+		guards = append(guards, g)
+	}
+	lt.Clear()
+	require.Equal(t, 0, lt.Len())
+	for _, g := range guards {
+		require.True(t, signaled(g))
+		_, wait := lt.ScanAndEnqueue(g.req, g)
+		require.False(t, wait)
+		lt.Dequeue(g)
+	}
+}
+
+// TestLockTableMaxLocks verifies that the table refuses to track locks beyond
+// its capacity, as well as ranged and local intents.
+func TestLockTableMaxLocks(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	stopper := stop.NewStopper()
+	defer stopper.Stop(context.Background())
+	lt := Make(2, stopper, nil /* registry */)
+
+	holder := makeTxn(10)
+	g := &Guard{}
+	require.True(t, lt.AddDiscoveredLocks(g, []roachpb.Intent{intentOn(holder, "a")}))
+	require.False(t, lt.AddDiscoveredLocks(g, []roachpb.Intent{
+		intentOn(holder, "b"), intentOn(holder, "c"),
+	}))
+	ranged := intentOn(holder, "b")
+	ranged.EndKey = roachpb.Key("c")
+	require.False(t, lt.AddDiscoveredLocks(g, []roachpb.Intent{ranged}))
+	lt.AcquireLock(holder, roachpb.Key("b"))
+	lt.AcquireLock(holder, roachpb.Key("c"))
+	require.Equal(t, 2, lt.Len())
+}
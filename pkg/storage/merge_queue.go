@@ -272,21 +272,30 @@ func (mq *mergeQueue) process(
 
 	{
 		store := lhsRepl.store
-		// AdminMerge errors if there is a learner or joint config on either
-		// side and AdminRelocateRange removes any on the range it operates on.
-		// For the sake of obviousness, just fix this all upfront.
+		// AdminMerge errors if there is a learner, a non-voter or joint config on
+		// either side and AdminRelocateRange removes any on the range it operates
+		// on. For the sake of obviousness, just fix this all upfront. The
+		// replicate queue adds the non-voters back once the ranges are merged.
 		var err error
 		lhsDesc, err = maybeLeaveAtomicChangeReplicasAndRemoveLearners(ctx, store, lhsDesc)
 		if err != nil {
 			log.VEventf(ctx, 2, `%v`, err)
 			return err
 		}
+		if lhsDesc, err = removeNonVoters(ctx, store, lhsDesc); err != nil {
+			log.VEventf(ctx, 2, `%v`, err)
+			return err
+		}
 
 		rhsDesc, err = maybeLeaveAtomicChangeReplicasAndRemoveLearners(ctx, store, rhsDesc)
 		if err != nil {
 			log.VEventf(ctx, 2, `%v`, err)
 			return err
 		}
+		if rhsDesc, err = removeNonVoters(ctx, store, rhsDesc); err != nil {
+			log.VEventf(ctx, 2, `%v`, err)
+			return err
+		}
 	}
 	lhsReplicas, rhsReplicas := lhsDesc.Replicas().All(), rhsDesc.Replicas().All()
 
@@ -0,0 +1,234 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+// Package ptcache implements protectedts.Tracker by periodically polling the
+// state of the protectedts subsystem.
+package ptcache
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/storage/protectedts"
+	"github.com/cockroachdb/cockroach/pkg/storage/protectedts/ptpb"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil/singleflight"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/errors"
+)
+
+// Cache implements protectedts.Tracker by keeping an in-memory copy of the
+// state of the protectedts subsystem. The state is refreshed periodically,
+// according to protectedts.PollInterval. Because the state changes rarely,
+// most refreshes only read the metadata row and advance the timestamp at
+// which the cached state is known to be valid.
+type Cache struct {
+	db       *client.DB
+	storage  protectedts.Storage
+	settings *cluster.Settings
+	sf       singleflight.Group
+
+	mu struct {
+		syncutil.RWMutex
+
+		started bool
+
+		// lastUpdate is the MVCC timestamp at which state was known to be
+		// current.
+		lastUpdate hlc.Timestamp
+		state      ptpb.State
+	}
+}
+
+// Config configures a Cache.
+type Config struct {
+	DB       *client.DB
+	Storage  protectedts.Storage
+	Settings *cluster.Settings
+}
+
+// New returns a new cache.
+func New(config Config) *Cache {
+	return &Cache{
+		db:       config.DB,
+		storage:  config.Storage,
+		settings: config.Settings,
+	}
+}
+
+var _ protectedts.Tracker = (*Cache)(nil)
+
+// ProtectedBy implements protectedts.Tracker.
+func (c *Cache) ProtectedBy(
+	ctx context.Context, s roachpb.Span, it func(*ptpb.Record),
+) (asOf hlc.Timestamp) {
+	state, lastUpdate := c.getStateAndLastUpdate()
+	for i := range state.Records {
+		r := &state.Records[i]
+		for _, sp := range r.Spans {
+			if sp.Overlaps(s) {
+				it(r)
+				break
+			}
+		}
+	}
+	return lastUpdate
+}
+
+// Iterate calls the passed function for each record in the cache, along with
+// the timestamp at which the cached state is known to be valid.
+func (c *Cache) Iterate(it func(*ptpb.Record)) (asOf hlc.Timestamp) {
+	state, lastUpdate := c.getStateAndLastUpdate()
+	for i := range state.Records {
+		it(&state.Records[i])
+	}
+	return lastUpdate
+}
+
+// Refresh forces the cache to update its state to a timestamp at least as
+// high as asOf.
+func (c *Cache) Refresh(ctx context.Context, asOf hlc.Timestamp) error {
+	for !asOf.Less(c.lastUpdate()) {
+		res, _ := c.sf.DoChan("refresh", func() (interface{}, error) {
+			return nil, c.doUpdate(ctx)
+		})
+		select {
+		case r := <-res:
+			if r.Err != nil {
+				return r.Err
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// Start starts the periodic fetching of the state.
+func (c *Cache) Start(ctx context.Context, stopper *stop.Stopper) error {
+	if err := c.markStarted(); err != nil {
+		return err
+	}
+	return stopper.RunAsyncTask(ctx, "periodically-refresh-protectedts-cache",
+		func(ctx context.Context) { c.periodicallyRefreshProtectedtsCache(ctx, stopper) })
+}
+
+func (c *Cache) markStarted() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.mu.started {
+		return errors.New("cannot start a Cache more than once")
+	}
+	c.mu.started = true
+	return nil
+}
+
+func (c *Cache) periodicallyRefreshProtectedtsCache(ctx context.Context, stopper *stop.Stopper) {
+	settingChanged := make(chan struct{}, 1)
+	protectedts.PollInterval.SetOnChange(&c.settings.SV, func() {
+		select {
+		case settingChanged <- struct{}{}:
+		default:
+		}
+	})
+	timer := timeutil.NewTimer()
+	defer timer.Stop()
+	timer.Reset(0) // Read immediately upon startup.
+	for {
+		select {
+		case <-timer.C:
+			timer.Read = true
+			if err := c.doUpdate(ctx); err != nil {
+				log.Warningf(ctx, "failed to refresh protected timestamps: %v", err)
+			}
+			timer.Reset(protectedts.PollInterval.Get(&c.settings.SV))
+		case <-settingChanged:
+			if timer.Read {
+				// The timer will be reset after the current refresh.
+				continue
+			}
+			timer.Reset(protectedts.PollInterval.Get(&c.settings.SV))
+		case <-stopper.ShouldQuiesce():
+			return
+		}
+	}
+}
+
+// doUpdate reads the state of the protectedts subsystem. The records are only
+// read if the version in the metadata row changed since the last update.
+func (c *Cache) doUpdate(ctx context.Context) error {
+	if !cluster.Version.IsActive(ctx, c.settings, cluster.VersionProtectedTimestamps) {
+		// No records can exist before the protectedts tables are created.
+		c.setLastUpdate(c.db.Clock().Now())
+		return nil
+	}
+	prev, _ := c.getStateAndLastUpdate()
+	var (
+		versionChanged bool
+		state          ptpb.State
+		ts             hlc.Timestamp
+	)
+	err := c.db.Txn(ctx, func(ctx context.Context, txn *client.Txn) (err error) {
+		defer func() {
+			if err == nil {
+				ts = txn.ReadTimestamp()
+			}
+		}()
+		md, err := c.storage.GetMetadata(ctx, txn)
+		if err != nil {
+			return errors.Wrap(err, "failed to fetch protectedts metadata")
+		}
+		if versionChanged = md.Version != prev.Version; !versionChanged {
+			return nil
+		}
+		if state, err = c.storage.GetState(ctx, txn); err != nil {
+			return errors.Wrap(err, "failed to fetch protectedts state")
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if versionChanged {
+		c.mu.state = state
+	}
+	if c.mu.lastUpdate.Less(ts) {
+		c.mu.lastUpdate = ts
+	}
+	return nil
+}
+
+func (c *Cache) setLastUpdate(ts hlc.Timestamp) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.mu.lastUpdate.Less(ts) {
+		c.mu.lastUpdate = ts
+	}
+}
+
+func (c *Cache) lastUpdate() hlc.Timestamp {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.mu.lastUpdate
+}
+
+func (c *Cache) getStateAndLastUpdate() (ptpb.State, hlc.Timestamp) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.mu.state, c.mu.lastUpdate
+}
@@ -0,0 +1,101 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package ptcache_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/storage/protectedts"
+	"github.com/cockroachdb/cockroach/pkg/storage/protectedts/ptcache"
+	"github.com/cockroachdb/cockroach/pkg/storage/protectedts/ptpb"
+	"github.com/cockroachdb/cockroach/pkg/storage/protectedts/ptstorage"
+	"github.com/cockroachdb/cockroach/pkg/testutils/testcluster"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCacheBasic(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	ctx := context.Background()
+	tc := testcluster.StartTestCluster(t, 1, base.TestClusterArgs{})
+	defer tc.Stopper().Stop(ctx)
+
+	s := tc.Server(0)
+	p := ptstorage.New(s.ClusterSettings(), s.InternalExecutor().(*sql.InternalExecutor))
+	c := ptcache.New(ptcache.Config{
+		DB:       s.DB(),
+		Storage:  p,
+		Settings: s.ClusterSettings(),
+	})
+	require.NoError(t, c.Start(ctx, tc.Stopper()))
+	require.Error(t, c.Start(ctx, tc.Stopper()))
+
+	// Make sure that protected timestamp gets updated.
+	ts := s.Clock().Now()
+	require.NoError(t, c.Refresh(ctx, ts))
+	sp := tableSpan(42)
+	require.False(t, c.ProtectedBy(ctx, sp, func(*ptpb.Record) {
+		t.Fatalf("expected no records")
+	}).Less(ts))
+
+	// Protect a span and make sure it shows up after a refresh.
+	r := protect(t, s.DB(), p, s.Clock().Now(), sp)
+	require.NoError(t, c.Refresh(ctx, s.Clock().Now()))
+	var found []uuid.UUID
+	c.ProtectedBy(ctx, sp, func(rec *ptpb.Record) {
+		found = append(found, rec.ID)
+	})
+	require.Equal(t, []uuid.UUID{r.ID}, found)
+
+	// Records are only reported for the spans they overlap.
+	c.ProtectedBy(ctx, tableSpan(43), func(*ptpb.Record) {
+		t.Fatalf("expected no records for an unrelated span")
+	})
+
+	// Release the record and make sure it's gone after a refresh.
+	require.NoError(t, s.DB().Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		return p.Release(ctx, txn, r.ID)
+	}))
+	require.NoError(t, c.Refresh(ctx, s.Clock().Now()))
+	c.ProtectedBy(ctx, sp, func(*ptpb.Record) {
+		t.Fatalf("expected the record to be released")
+	})
+}
+
+func protect(
+	t *testing.T, db *client.DB, s protectedts.Storage, ts hlc.Timestamp, spans ...roachpb.Span,
+) *ptpb.Record {
+	r := &ptpb.Record{
+		ID:        uuid.MakeV4(),
+		Timestamp: ts,
+		Mode:      ptpb.PROTECT_AFTER,
+		Spans:     spans,
+	}
+	require.NoError(t, db.Txn(context.Background(), func(ctx context.Context, txn *client.Txn) error {
+		return s.Protect(ctx, txn, r)
+	}))
+	return r
+}
+
+func tableSpan(tableID uint32) roachpb.Span {
+	return roachpb.Span{
+		Key:    roachpb.Key(keys.MakeTablePrefix(tableID)),
+		EndKey: roachpb.Key(keys.MakeTablePrefix(tableID)).PrefixEnd(),
+	}
+}
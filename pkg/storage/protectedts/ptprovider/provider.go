@@ -0,0 +1,74 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+// Package ptprovider encapsulates the concrete implementation of the
+// protectedts.Provider.
+package ptprovider
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlutil"
+	"github.com/cockroachdb/cockroach/pkg/storage/protectedts"
+	"github.com/cockroachdb/cockroach/pkg/storage/protectedts/ptcache"
+	"github.com/cockroachdb/cockroach/pkg/storage/protectedts/ptstorage"
+	"github.com/cockroachdb/cockroach/pkg/storage/protectedts/ptverifier"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+	"github.com/cockroachdb/errors"
+)
+
+// Config configures the Provider.
+type Config struct {
+	Settings         *cluster.Settings
+	DB               *client.DB
+	InternalExecutor sqlutil.InternalExecutorWithUser
+}
+
+type provider struct {
+	protectedts.Storage
+	protectedts.Verifier
+	*ptcache.Cache
+}
+
+// New creates a new protectedts.Provider.
+func New(cfg Config) (protectedts.Provider, error) {
+	if err := validateConfig(cfg); err != nil {
+		return nil, err
+	}
+	storage := ptstorage.New(cfg.Settings, cfg.InternalExecutor)
+	return &provider{
+		Storage:  storage,
+		Verifier: ptverifier.New(cfg.DB, storage),
+		Cache: ptcache.New(ptcache.Config{
+			DB:       cfg.DB,
+			Storage:  storage,
+			Settings: cfg.Settings,
+		}),
+	}, nil
+}
+
+func validateConfig(cfg Config) error {
+	switch {
+	case cfg.Settings == nil:
+		return errors.Errorf("invalid nil Settings")
+	case cfg.DB == nil:
+		return errors.Errorf("invalid nil DB")
+	case cfg.InternalExecutor == nil:
+		return errors.Errorf("invalid nil InternalExecutor")
+	default:
+		return nil
+	}
+}
+
+func (p *provider) Start(ctx context.Context, stopper *stop.Stopper) error {
+	return p.Cache.Start(ctx, stopper)
+}
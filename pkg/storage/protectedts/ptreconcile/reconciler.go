@@ -0,0 +1,167 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+// Package ptreconcile provides logic to reconcile protected timestamp records
+// with the state of the clients which created them.
+package ptreconcile
+
+import (
+	"context"
+	"math/rand"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/storage/protectedts"
+	"github.com/cockroachdb/cockroach/pkg/storage/protectedts/ptpb"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+)
+
+// ReconcileInterval is the interval between two reconciliations of protected
+// timestamp records.
+var ReconcileInterval = settings.RegisterNonNegativeDurationSetting(
+	"kv.protectedts.reconciliation.interval",
+	"the frequency for reconciling jobs with protected timestamp records, "+
+		"set to zero to disable",
+	5*time.Minute,
+)
+
+// StatusFunc is used to check on the status of a Record based on its Meta
+// field. It returns true if the record should be removed, for example because
+// the job which created it no longer exists.
+type StatusFunc func(
+	ctx context.Context, txn *client.Txn, meta []byte,
+) (shouldRemove bool, _ error)
+
+// StatusFuncs maps from MetaType to a StatusFunc.
+type StatusFuncs map[string]StatusFunc
+
+// Config configures a Reconciler.
+type Config struct {
+	Settings *cluster.Settings
+	DB       *client.DB
+	Storage  protectedts.Storage
+
+	// StatusFuncs determines, for each MetaType, whether a record should be
+	// removed. Records with a MetaType not in the map are left alone.
+	StatusFuncs StatusFuncs
+}
+
+// Reconciler runs a loop to reconcile the protected timestamp records with
+// the state of the clients which created them. Records whose client is gone
+// would otherwise prevent garbage collection forever.
+type Reconciler struct {
+	settings    *cluster.Settings
+	db          *client.DB
+	pts         protectedts.Storage
+	statusFuncs StatusFuncs
+}
+
+// NewReconciler constructs a Reconciler.
+func NewReconciler(cfg Config) *Reconciler {
+	return &Reconciler{
+		settings:    cfg.Settings,
+		db:          cfg.DB,
+		pts:         cfg.Storage,
+		statusFuncs: cfg.StatusFuncs,
+	}
+}
+
+// Start will start the Reconciler.
+func (r *Reconciler) Start(ctx context.Context, stopper *stop.Stopper) error {
+	return stopper.RunAsyncTask(ctx, "protectedts-reconciliation", func(ctx context.Context) {
+		r.run(ctx, stopper)
+	})
+}
+
+func (r *Reconciler) run(ctx context.Context, stopper *stop.Stopper) {
+	reconcileIntervalChanged := make(chan struct{}, 1)
+	ReconcileInterval.SetOnChange(&r.settings.SV, func() {
+		select {
+		case reconcileIntervalChanged <- struct{}{}:
+		default:
+		}
+	})
+	lastReconciled := timeutil.Now()
+	getInterval := func() time.Duration {
+		interval := ReconcileInterval.Get(&r.settings.SV)
+		const jitterFrac = .1
+		return time.Duration(float64(interval) * (1 + (2*rand.Float64()-1)*jitterFrac))
+	}
+	timer := timeutil.NewTimer()
+	defer timer.Stop()
+	resetTimer := func() {
+		if interval := ReconcileInterval.Get(&r.settings.SV); interval > 0 {
+			timer.Reset(timeutil.Until(lastReconciled.Add(getInterval())))
+		}
+	}
+	resetTimer()
+	for {
+		select {
+		case <-reconcileIntervalChanged:
+			resetTimer()
+		case <-timer.C:
+			timer.Read = true
+			r.reconcile(ctx)
+			lastReconciled = timeutil.Now()
+			resetTimer()
+		case <-stopper.ShouldQuiesce():
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// reconcile removes the records whose StatusFunc reports that they should be
+// removed.
+func (r *Reconciler) reconcile(ctx context.Context) {
+	if !cluster.Version.IsActive(ctx, r.settings, cluster.VersionProtectedTimestamps) {
+		return
+	}
+	var state ptpb.State
+	if err := r.db.Txn(ctx, func(ctx context.Context, txn *client.Txn) (err error) {
+		state, err = r.pts.GetState(ctx, txn)
+		return err
+	}); err != nil {
+		log.Warningf(ctx, "failed to read protected timestamp records: %v", err)
+		return
+	}
+	for i := range state.Records {
+		rec := &state.Records[i]
+		task, ok := r.statusFuncs[rec.MetaType]
+		if !ok {
+			continue
+		}
+		var didRemove bool
+		if err := r.db.Txn(ctx, func(ctx context.Context, txn *client.Txn) (err error) {
+			didRemove = false // reset for retries
+			shouldRemove, err := task(ctx, txn, rec.Meta)
+			if err != nil || !shouldRemove {
+				return err
+			}
+			err = r.pts.Release(ctx, txn, rec.ID)
+			if err != nil && err != protectedts.ErrNotExists {
+				return err
+			}
+			didRemove = err == nil
+			return nil
+		}); err != nil {
+			log.Warningf(ctx, "failed to reconcile protected timestamp with id %s: %v",
+				rec.ID.String(), err)
+		} else if didRemove {
+			log.Infof(ctx, "released protected timestamp %s of type %s",
+				rec.ID.String(), rec.MetaType)
+		}
+	}
+}
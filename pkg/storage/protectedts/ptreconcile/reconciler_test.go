@@ -0,0 +1,135 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package ptreconcile_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/storage/protectedts"
+	"github.com/cockroachdb/cockroach/pkg/storage/protectedts/ptpb"
+	"github.com/cockroachdb/cockroach/pkg/storage/protectedts/ptreconcile"
+	"github.com/cockroachdb/cockroach/pkg/storage/protectedts/ptstorage"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/testcluster"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
+	"github.com/cockroachdb/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReconciler(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	ctx := context.Background()
+	tc := testcluster.StartTestCluster(t, 1, base.TestClusterArgs{})
+	defer tc.Stopper().Stop(ctx)
+
+	s := tc.Server(0)
+	// Start with the reconciliation disabled. The loop of the server, which
+	// uses the same setting, leaves the records of the test alone since it
+	// doesn't know their meta type.
+	st := s.ClusterSettings()
+	ptreconcile.ReconcileInterval.Override(&st.SV, 0)
+	p := ptstorage.New(st, s.InternalExecutor().(*sql.InternalExecutor))
+
+	// The status of the clients of the records, by meta.
+	const testMetaType = "test"
+	var mu struct {
+		syncutil.Mutex
+		removed map[string]bool
+	}
+	mu.removed = make(map[string]bool)
+	setRemoved := func(meta string) {
+		mu.Lock()
+		defer mu.Unlock()
+		mu.removed[meta] = true
+	}
+	r := ptreconcile.NewReconciler(ptreconcile.Config{
+		Settings: st,
+		DB:       s.DB(),
+		Storage:  p,
+		StatusFuncs: ptreconcile.StatusFuncs{
+			testMetaType: func(
+				ctx context.Context, txn *client.Txn, meta []byte,
+			) (shouldRemove bool, _ error) {
+				mu.Lock()
+				defer mu.Unlock()
+				return mu.removed[string(meta)], nil
+			},
+		},
+	})
+	require.NoError(t, r.Start(ctx, tc.Stopper()))
+
+	protect := func(metaType, meta string) uuid.UUID {
+		rec := &ptpb.Record{
+			ID:        uuid.MakeV4(),
+			Timestamp: s.Clock().Now(),
+			Mode:      ptpb.PROTECT_AFTER,
+			MetaType:  metaType,
+			Meta:      []byte(meta),
+			Spans: []roachpb.Span{{
+				Key:    roachpb.Key(keys.MakeTablePrefix(42)),
+				EndKey: roachpb.Key(keys.MakeTablePrefix(42)).PrefixEnd(),
+			}},
+		}
+		require.NoError(t, s.DB().Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+			return p.Protect(ctx, txn, rec)
+		}))
+		return rec.ID
+	}
+	exists := func(id uuid.UUID) bool {
+		var err error
+		require.NoError(t, s.DB().Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+			_, err = p.GetRecord(ctx, txn, id)
+			if err == protectedts.ErrNotExists {
+				return nil
+			}
+			return err
+		}))
+		return err == nil
+	}
+
+	gone := protect(testMetaType, "gone")
+	live := protect(testMetaType, "live")
+	other := protect("other", "gone")
+	setRemoved("gone")
+
+	// Nothing is removed while the reconciliation is disabled.
+	require.True(t, exists(gone))
+
+	// Only the record whose client is gone is removed. Records of unknown
+	// meta types are left alone.
+	ptreconcile.ReconcileInterval.Override(&st.SV, time.Millisecond)
+	testutils.SucceedsSoon(t, func() error {
+		if exists(gone) {
+			return errors.New("record not reconciled yet")
+		}
+		return nil
+	})
+	require.True(t, exists(live))
+	require.True(t, exists(other))
+
+	setRemoved("live")
+	testutils.SucceedsSoon(t, func() error {
+		if exists(live) {
+			return errors.New("record not reconciled yet")
+		}
+		return nil
+	})
+	require.True(t, exists(other))
+}
@@ -0,0 +1,127 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+// Package ptverifier implements protectedts.Verifier.
+package ptverifier
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/storage/protectedts"
+	"github.com/cockroachdb/cockroach/pkg/storage/protectedts/ptpb"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
+	"github.com/cockroachdb/errors"
+)
+
+// verifier verifies protected timestamp records by reading at the protected
+// timestamp from every range overlapping the protected spans. A read below
+// the GC threshold of a range fails, so a successful read proves that the
+// data was not collected before the record was verified.
+//
+// The ranges are not asked to confirm that they know about the record. The
+// data cannot be collected later because a replica never runs GC past the
+// timestamp as of which its Tracker knows the records (see
+// Replica.checkProtectedTimestampsForGC), and the Tracker only moves past
+// the commit timestamp of the record once it includes the record.
+type verifier struct {
+	db *client.DB
+	s  protectedts.Storage
+}
+
+// New returns a new Verifier.
+func New(db *client.DB, s protectedts.Storage) protectedts.Verifier {
+	return &verifier{db: db, s: s}
+}
+
+// Verify implements protectedts.Verifier.
+func (v *verifier) Verify(ctx context.Context, id uuid.UUID) error {
+	var r *ptpb.Record
+	if err := v.db.Txn(ctx, func(ctx context.Context, txn *client.Txn) (err error) {
+		r, err = v.s.GetRecord(ctx, txn, id)
+		return err
+	}); err != nil {
+		return errors.Wrapf(err, "failed to fetch record %s", id)
+	}
+	if r.Verified {
+		return nil
+	}
+
+	for _, sp := range r.Spans {
+		descs, err := v.rangeDescriptors(ctx, sp)
+		if err != nil {
+			return errors.Wrapf(err, "failed to look up ranges for %s", sp)
+		}
+		for i := range descs {
+			if err := v.verifyRange(ctx, r, sp, &descs[i]); err != nil {
+				return err
+			}
+		}
+	}
+
+	return v.db.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		return v.s.MarkVerified(ctx, txn, id)
+	})
+}
+
+// verifyRange reads at the protected timestamp from the part of the span
+// owned by the given range.
+func (v *verifier) verifyRange(
+	ctx context.Context, r *ptpb.Record, sp roachpb.Span, desc *roachpb.RangeDescriptor,
+) error {
+	rs := roachpb.RSpan{Key: keys.MustAddr(sp.Key), EndKey: keys.MustAddr(sp.EndKey)}
+	rs, err := rs.Intersect(desc)
+	if err != nil {
+		// The range does not overlap the span.
+		return nil
+	}
+	rsp := rs.AsRawSpanWithNoLocals()
+	var b client.Batch
+	b.Header.Timestamp = r.Timestamp
+	b.Header.ReadConsistency = roachpb.INCONSISTENT
+	b.Header.MaxSpanRequestKeys = 1
+	b.Scan(rsp.Key, rsp.EndKey)
+	if err := v.db.Run(ctx, &b); err != nil {
+		return errors.Wrapf(err, "failed to verify protection of %s for record %s in r%d",
+			rsp, r.ID, desc.RangeID)
+	}
+	return nil
+}
+
+// rangeDescriptors returns the descriptors of the ranges overlapping the
+// given span.
+func (v *verifier) rangeDescriptors(
+	ctx context.Context, sp roachpb.Span,
+) ([]roachpb.RangeDescriptor, error) {
+	metaStart := keys.RangeMetaKey(keys.MustAddr(sp.Key).Next())
+	metaEnd := keys.RangeMetaKey(keys.MustAddr(sp.EndKey))
+	kvs, err := v.db.Scan(ctx, metaStart, metaEnd, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(kvs) == 0 || !kvs[len(kvs)-1].Key.Equal(metaEnd.AsRawKey()) {
+		// The last range is addressed by its end key, which lies past the end
+		// of the span.
+		extraKV, err := v.db.Scan(ctx, metaEnd, keys.Meta2Prefix.PrefixEnd(), 1 /* maxRows */)
+		if err != nil {
+			return nil, err
+		}
+		kvs = append(kvs, extraKV...)
+	}
+	descs := make([]roachpb.RangeDescriptor, len(kvs))
+	for i := range kvs {
+		if err := kvs[i].ValueProto(&descs[i]); err != nil {
+			return nil, err
+		}
+	}
+	return descs, nil
+}
@@ -0,0 +1,96 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package ptverifier_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/storage/protectedts"
+	"github.com/cockroachdb/cockroach/pkg/storage/protectedts/ptpb"
+	"github.com/cockroachdb/cockroach/pkg/storage/protectedts/ptstorage"
+	"github.com/cockroachdb/cockroach/pkg/storage/protectedts/ptverifier"
+	"github.com/cockroachdb/cockroach/pkg/testutils/testcluster"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifier(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	ctx := context.Background()
+	tc := testcluster.StartTestCluster(t, 1, base.TestClusterArgs{})
+	defer tc.Stopper().Stop(ctx)
+
+	s := tc.Server(0)
+	p := ptstorage.New(s.ClusterSettings(), s.InternalExecutor().(*sql.InternalExecutor))
+	v := ptverifier.New(s.DB(), p)
+	getRecord := func(id uuid.UUID) (r *ptpb.Record) {
+		require.NoError(t, s.DB().Txn(ctx, func(ctx context.Context, txn *client.Txn) (err error) {
+			r, err = p.GetRecord(ctx, txn, id)
+			return err
+		}))
+		return r
+	}
+
+	// A record whose data is still there is marked as verified.
+	sp := tableSpan(42)
+	r := protect(t, s.DB(), p, s.Clock().Now(), sp)
+	require.False(t, getRecord(r.ID).Verified)
+	require.NoError(t, v.Verify(ctx, r.ID))
+	require.True(t, getRecord(r.ID).Verified)
+	require.NoError(t, v.Verify(ctx, r.ID))
+
+	// A record protecting a timestamp below the GC threshold of the range
+	// cannot be verified.
+	old := s.Clock().Now()
+	gcr := roachpb.GCRequest{
+		RequestHeader: roachpb.RequestHeader{Key: sp.Key, EndKey: sp.EndKey},
+		Threshold:     s.Clock().Now(),
+	}
+	_, pErr := client.SendWrapped(ctx, s.DistSenderI().(*kv.DistSender), &gcr)
+	require.NoError(t, pErr.GoError())
+	r = protect(t, s.DB(), p, old, sp)
+	require.Error(t, v.Verify(ctx, r.ID))
+	require.False(t, getRecord(r.ID).Verified)
+
+	// Records which don't exist cannot be verified.
+	require.Error(t, v.Verify(ctx, uuid.MakeV4()))
+}
+
+func protect(
+	t *testing.T, db *client.DB, s protectedts.Storage, ts hlc.Timestamp, spans ...roachpb.Span,
+) *ptpb.Record {
+	r := &ptpb.Record{
+		ID:        uuid.MakeV4(),
+		Timestamp: ts,
+		Mode:      ptpb.PROTECT_AFTER,
+		Spans:     spans,
+	}
+	require.NoError(t, db.Txn(context.Background(), func(ctx context.Context, txn *client.Txn) error {
+		return s.Protect(ctx, txn, r)
+	}))
+	return r
+}
+
+func tableSpan(tableID uint32) roachpb.Span {
+	return roachpb.Span{
+		Key:    roachpb.Key(keys.MakeTablePrefix(tableID)),
+		EndKey: roachpb.Key(keys.MakeTablePrefix(tableID)).PrefixEnd(),
+	}
+}
@@ -32,6 +32,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/storage/cloud"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
+	"github.com/cockroachdb/cockroach/pkg/storage/locktable"
 	"github.com/cockroachdb/cockroach/pkg/storage/rangefeed"
 	"github.com/cockroachdb/cockroach/pkg/storage/spanlatch"
 	"github.com/cockroachdb/cockroach/pkg/storage/spanset"
@@ -186,6 +187,13 @@ type Replica struct {
 	// writeStats tracks the number of keys written by applied raft commands
 	// in order to aid in replica rebalancing decisions.
 	writeStats *replicaStats
+	// writeBytesStats tracks the number of bytes written by applied raft
+	// commands in order to aid in replica rebalancing decisions.
+	writeBytesStats *replicaStats
+	// evalTimeStats tracks the wall time, in nanoseconds, spent evaluating
+	// requests on the replica. It includes time spent waiting on I/O and stands
+	// in for the replica's CPU usage in lease and replica rebalancing decisions.
+	evalTimeStats *replicaStats
 
 	// creatingReplica is set when a replica is created as uninitialized
 	// via a raft message.
@@ -229,6 +237,9 @@ type Replica struct {
 	// keys for which keys.Addr is the identity), the locally-scoped component
 	// the rest (e.g. RangeDescriptor, transaction record, Lease, ...).
 	latchMgr spanlatch.Manager
+	// lockTable tracks the locks held on the range's keys, and the requests
+	// waiting for them. It is only populated on the leaseholder.
+	lockTable *locktable.Table
 
 	mu struct {
 		// Protects all fields in the mu struct.
@@ -1202,7 +1213,11 @@ func (r *Replica) beginCmds(
 	// protected access and to avoid interacting requests from operating at
 	// the same time. The latches will be held for the duration of request.
 	log.Event(ctx, "acquire latches")
-	lg, err := r.latchMgr.Acquire(ctx, spans)
+	var txnID uuid.UUID
+	if ba.Txn != nil {
+		txnID = ba.Txn.ID
+	}
+	lg, err := r.latchMgr.Acquire(ctx, spans, txnID)
 	if err != nil {
 		return nil, err
 	}
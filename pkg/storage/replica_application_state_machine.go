@@ -385,6 +385,7 @@ type replicaAppBatch struct {
 	entries      int
 	emptyEntries int
 	mutations    int
+	writeBytes   int
 	start        time.Time
 }
 
@@ -520,6 +521,7 @@ func (b *replicaAppBatch) stageWriteBatch(ctx context.Context, cmd *replicatedCm
 	} else {
 		b.mutations += mutations
 	}
+	b.writeBytes += len(wb.Data)
 	if err := b.batch.ApplyBatchRepr(wb.Data, false); err != nil {
 		return wrapWithNonDeterministicFailure(err, "unable to apply WriteBatch")
 	}
@@ -600,6 +602,7 @@ func (b *replicaAppBatch) runPreApplyTriggersAfterStagingWriteBatch(
 		if added := res.Delta.KeyCount; added > 0 {
 			b.r.writeStats.recordCount(float64(added), 0)
 		}
+		b.r.writeBytesStats.recordCount(float64(len(res.AddSSTable.Data)), 0)
 		res.AddSSTable = nil
 	}
 
@@ -848,6 +851,7 @@ func (b *replicaAppBatch) ApplyToStateMachine(ctx context.Context) error {
 	// Record the write activity, passing a 0 nodeID because replica.writeStats
 	// intentionally doesn't track the origin of the writes.
 	b.r.writeStats.recordCount(float64(b.mutations), 0 /* nodeID */)
+	b.r.writeBytesStats.recordCount(float64(b.writeBytes), 0 /* nodeID */)
 
 	// NB: the bootstrap store has a nil split queue.
 	// TODO(tbg): the above is probably a lie now.
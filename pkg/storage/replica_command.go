@@ -640,7 +640,7 @@ func (r *Replica) AdminMerge(
 		// it, but it's not right now.
 		//
 		// NB: the merge queue transitions out of any joint states and removes
-		// any learners it sees. It's sort of silly that we don't do that here
+		// any learners and non-voters it sees. It's sort of silly that we don't do that here
 		// instead; effectively any caller of AdminMerge that is not the merge
 		// queue won't be able to recover from these cases (though the replicate
 		// queues should fix things up quickly).
@@ -1004,6 +1004,10 @@ func (r *Replica) changeReplicasImpl(
 		return nil, err
 	}
 
+	if len(chgs.NonVoterAdditions())+len(chgs.NonVoterRemovals()) > 0 {
+		return r.changeNonVoters(ctx, desc, priority, reason, details, chgs)
+	}
+
 	settings := r.ClusterSettings()
 	if useLearners := cluster.Version.IsActive(
 		ctx, settings, cluster.VersionLearnerReplicas,
@@ -1142,6 +1146,37 @@ func maybeLeaveAtomicChangeReplicasAndRemoveLearners(
 	return desc, nil
 }
 
+// removeNonVoters removes all non-voting replicas of the range, one at a
+// time. The merge queue calls this on both sides of a merge before
+// collocating them, since AdminMerge only handles VOTER_FULL replicas; the
+// replicate queue adds the non-voters back to the merged range afterwards.
+func removeNonVoters(
+	ctx context.Context, store *Store, desc *roachpb.RangeDescriptor,
+) (*roachpb.RangeDescriptor, error) {
+	nonVoters := desc.Replicas().NonVoters()
+	if len(nonVoters) == 0 {
+		return desc, nil
+	}
+	targets := make([]roachpb.ReplicationTarget, len(nonVoters))
+	for i := range nonVoters {
+		targets[i].NodeID = nonVoters[i].NodeID
+		targets[i].StoreID = nonVoters[i].StoreID
+	}
+	log.VEventf(ctx, 2, `removing non-voter replicas %v from %v`, targets, desc)
+	origDesc := desc
+	for _, target := range targets {
+		var err error
+		desc, err = execChangeReplicasTxn(
+			ctx, store, desc, storagepb.ReasonRangeMerge, "",
+			[]internalReplicationChange{{target: target, typ: internalChangeTypeRemove}},
+		)
+		if err != nil {
+			return nil, errors.Wrapf(err, `removing non-voters from %s`, origDesc)
+		}
+	}
+	return desc, nil
+}
+
 func validateReplicationChanges(
 	desc *roachpb.RangeDescriptor, chgs roachpb.ReplicationChanges,
 ) error {
@@ -1161,7 +1196,14 @@ func validateReplicationChanges(
 	for _, rDesc := range desc.Replicas().All() {
 		chg, ok := byNodeID[rDesc.NodeID]
 		delete(byNodeID, rDesc.NodeID)
-		if !ok || chg.ChangeType != roachpb.ADD_REPLICA {
+		if !ok {
+			continue
+		}
+		if chg.ChangeType == roachpb.REMOVE_NON_VOTER && rDesc.GetType() != roachpb.NON_VOTER {
+			return errors.Errorf("unable to remove %v as a non-voter since it is a %s in %s",
+				chg.Target, rDesc.GetType(), desc)
+		}
+		if chg.ChangeType != roachpb.ADD_REPLICA && chg.ChangeType != roachpb.ADD_NON_VOTER {
 			continue
 		}
 		// We're adding a replica that's already there. This isn't allowed, even
@@ -1187,7 +1229,7 @@ func validateReplicationChanges(
 
 	// Any removals left in the map now refer to nonexisting replicas, and we refuse them.
 	for _, chg := range byNodeID {
-		if chg.ChangeType != roachpb.REMOVE_REPLICA {
+		if chg.ChangeType != roachpb.REMOVE_REPLICA && chg.ChangeType != roachpb.REMOVE_NON_VOTER {
 			continue
 		}
 		return errors.Errorf("removing %v which is not in %s", chg.Target, desc)
@@ -1223,6 +1265,71 @@ func addLearnerReplicas(
 	return desc, nil
 }
 
+// changeNonVoters carries out the addition and removal of non-voting replicas.
+// Since non-voters don't affect quorum, they are added and removed one at a
+// time using simple (non-joint) membership changes, and they are never mixed
+// with changes to the set of voters. Like learners that are about to be
+// promoted, newly added non-voters are sent a snapshot before this method
+// returns. If sending the snapshot fails, the non-voter is left in place and
+// will be caught up by the raft snapshot queue.
+func (r *Replica) changeNonVoters(
+	ctx context.Context,
+	desc *roachpb.RangeDescriptor,
+	priority SnapshotRequest_Priority,
+	reason storagepb.RangeLogEventReason,
+	details string,
+	chgs roachpb.ReplicationChanges,
+) (*roachpb.RangeDescriptor, error) {
+	if !cluster.Version.IsActive(ctx, r.ClusterSettings(), cluster.VersionNonVotingReplicas) {
+		return nil, errors.Errorf("non-voting replicas require all nodes to be upgraded to %s",
+			cluster.VersionByKey(cluster.VersionNonVotingReplicas))
+	}
+	if len(chgs.Additions())+len(chgs.Removals()) > 0 {
+		return nil, errors.Errorf("cannot mix changes to voters and non-voters: %+v", chgs)
+	}
+
+	for _, target := range chgs.NonVoterRemovals() {
+		var err error
+		desc, err = execChangeReplicasTxn(
+			ctx, r.store, desc, reason, details,
+			[]internalReplicationChange{{target: target, typ: internalChangeTypeRemove}},
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	adds := chgs.NonVoterAdditions()
+	if len(adds) == 0 {
+		return desc, nil
+	}
+	// See the comment in changeReplicasImpl for why the snapshots are locked
+	// even before the replicas are added.
+	releaseSnapshotLockFn := r.lockLearnerSnapshot(ctx, adds)
+	defer releaseSnapshotLockFn()
+	for _, target := range adds {
+		var err error
+		desc, err = execChangeReplicasTxn(
+			ctx, r.store, desc, reason, details,
+			[]internalReplicationChange{{target: target, typ: internalChangeTypeAddNonVoter}},
+		)
+		if err != nil {
+			return nil, err
+		}
+		if fn := r.store.cfg.TestingKnobs.ReplicaSkipLearnerSnapshot; fn != nil && fn() {
+			continue
+		}
+		rDesc, ok := desc.GetReplicaDescriptor(target.StoreID)
+		if !ok {
+			return nil, errors.Errorf("programming error: replica %v not found in %v", target, desc)
+		}
+		if err := r.sendSnapshot(ctx, rDesc, SnapshotRequest_LEARNER, priority); err != nil {
+			return nil, err
+		}
+	}
+	return desc, nil
+}
+
 // lockLearnerSnapshot stops the raft snapshot queue from sending snapshots to
 // the soon-to-be added learner replicas to prevent duplicate snapshots from
 // being sent. This lock is best effort because it times out and it is a node
@@ -1475,6 +1582,9 @@ const (
 	// removals throughout (i.e. they show up in `ChangeReplicasTrigger.Removed()`,
 	// but not in `.Added()`).
 	internalChangeTypeDemote
+	// internalChangeTypeAddNonVoter adds a non-voting replica. Like learners,
+	// non-voters are added without joint consensus.
+	internalChangeTypeAddNonVoter
 	// NB: can't remove multiple learners at once (need to remove at least one
 	// voter with them), see:
 	// https://github.com/cockroachdb/cockroach/pull/40268
@@ -1539,6 +1649,9 @@ func prepareChangeReplicasTrigger(
 			case internalChangeTypeAddLearner:
 				added = append(added,
 					updatedDesc.AddReplica(chg.target.NodeID, chg.target.StoreID, roachpb.LEARNER))
+			case internalChangeTypeAddNonVoter:
+				added = append(added,
+					updatedDesc.AddReplica(chg.target.NodeID, chg.target.StoreID, roachpb.NON_VOTER))
 			case internalChangeTypePromoteLearner:
 				typ := roachpb.VOTER_FULL
 				if useJoint {
@@ -1555,7 +1668,7 @@ func prepareChangeReplicasTrigger(
 					return nil, errors.Errorf("target %s not found", chg.target)
 				}
 				prevTyp := rDesc.GetType()
-				if !useJoint || prevTyp == roachpb.LEARNER {
+				if !useJoint || prevTyp == roachpb.LEARNER || prevTyp == roachpb.NON_VOTER {
 					rDesc, _ = updatedDesc.RemoveReplica(chg.target.NodeID, chg.target.StoreID)
 				} else if prevTyp != roachpb.VOTER_FULL {
 					// NB: prevTyp is already known to be VOTER_FULL because of
@@ -2275,12 +2388,23 @@ func (s *Store) AdminRelocateRange(
 func (s *Store) relocateOne(
 	ctx context.Context, desc *roachpb.RangeDescriptor, targets []roachpb.ReplicationTarget,
 ) ([]roachpb.ReplicationChange, *roachpb.ReplicationTarget, error) {
-	rangeReplicas := desc.Replicas().All()
-	if len(rangeReplicas) != len(desc.Replicas().Voters()) {
+	// Only the voters are relocated. Non-voters are left in place, and it's up
+	// to the replicate queue to move them if they end up in a bad spot.
+	rangeReplicas := desc.Replicas().Voters()
+	nonVoters := desc.Replicas().NonVoters()
+	if len(rangeReplicas)+len(nonVoters) != len(desc.Replicas().All()) {
 		// The caller removed all the learners, so there shouldn't be anything but
-		// voters.
+		// voters and non-voters.
 		return nil, nil, crdberrors.AssertionFailedf(
-			`range %s had non-voter replicas: %v`, desc, desc.Replicas())
+			`range %s had learner replicas: %v`, desc, desc.Replicas())
+	}
+	for _, t := range targets {
+		for _, rDesc := range nonVoters {
+			if rDesc.NodeID == t.NodeID {
+				return nil, nil, errors.Errorf(
+					"cannot relocate a voter to n%d which holds the non-voter %s", t.NodeID, rDesc)
+			}
+		}
 	}
 
 	sysCfg := s.cfg.Gossip.GetSystemConfig()
@@ -21,6 +21,7 @@ import (
 	"sync"
 	"sync/atomic"
 	"time"
+	"unicode/utf8"
 
 	"github.com/cockroachdb/cockroach/pkg/internal/client"
 	"github.com/cockroachdb/cockroach/pkg/keys"
@@ -32,6 +33,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
 	"github.com/cockroachdb/cockroach/pkg/storage/rditer"
 	"github.com/cockroachdb/cockroach/pkg/storage/stateloader"
+	"github.com/cockroachdb/cockroach/pkg/storage/storagepb"
 	"github.com/cockroachdb/cockroach/pkg/util/bufalloc"
 	"github.com/cockroachdb/cockroach/pkg/util/contextutil"
 	"github.com/cockroachdb/cockroach/pkg/util/envutil"
@@ -86,7 +88,10 @@ type ReplicaChecksum struct {
 // When args.Mode is CHECK_VIA_QUEUE and an inconsistency is detected and no
 // diff was requested, the consistency check will be re-run to collect a diff,
 // which is then printed before calling `log.Fatal`. This behavior should be
-// lifted to the consistency checker queue in the future.
+// lifted to the consistency checker queue in the future. If repairs are
+// enabled and a majority of the replicas including the local one agree, the
+// minority replicas are instead removed from the range; see
+// repairInconsistency.
 func (r *Replica) CheckConsistency(
 	ctx context.Context, args roachpb.CheckConsistencyRequest,
 ) (roachpb.CheckConsistencyResponse, *roachpb.Error) {
@@ -274,6 +279,16 @@ func (r *Replica) CheckConsistency(
 		return resp, nil
 	}
 
+	if consistencyCheckRepairEnabled.Get(&r.store.cfg.Settings.SV) {
+		if minority, ok := repairableMinority(r.Desc(), results, shaToIdxs); ok {
+			err := r.repairInconsistency(ctx, args, minority)
+			if err == nil {
+				return resp, nil
+			}
+			log.Errorf(ctx, "unable to repair replica inconsistency: %s", err)
+		}
+	}
+
 	// No diff was printed, so we want to re-run with diff.
 	// Note that this recursive call will be terminated in the `args.WithDiff`
 	// branch above.
@@ -301,6 +316,130 @@ func (r *Replica) CheckConsistency(
 	return resp, nil
 }
 
+// maxConsistencyRepairDetailSize bounds the size of the diff recorded in the
+// range log when repairing an inconsistency.
+const maxConsistencyRepairDetailSize = 64 << 10 // 64 KiB
+
+// truncateConsistencyRepairDetail truncates the given diff to at most
+// maxConsistencyRepairDetailSize bytes, without splitting a multi-byte
+// character.
+func truncateConsistencyRepairDetail(details string) string {
+	if len(details) <= maxConsistencyRepairDetailSize {
+		return details
+	}
+	n := maxConsistencyRepairDetailSize
+	for n > 0 && !utf8.RuneStart(details[n]) {
+		n--
+	}
+	return details[:n] + "\n... (truncated)"
+}
+
+// repairableMinority returns the replicas which disagree with the checksum
+// computed by a majority of the range's voters. It returns false if no
+// checksum is shared by a majority of the voters, or if the local replica
+// (which is always the first result) isn't part of that majority. Learners
+// which disagree with the majority are part of the minority, but they don't
+// count towards the majority since they don't vote.
+func repairableMinority(
+	desc *roachpb.RangeDescriptor, results []ConsistencyCheckResult, shaToIdxs map[string][]int,
+) ([]roachpb.ReplicaDescriptor, bool) {
+	voters := desc.Replicas().Voters()
+	isVoter := func(repl roachpb.ReplicaDescriptor) bool {
+		for _, v := range voters {
+			if v.ReplicaID == repl.ReplicaID {
+				return true
+			}
+		}
+		return false
+	}
+	majoritySHA := string(results[0].Response.Checksum)
+	var majorityVoters int
+	for _, idx := range shaToIdxs[majoritySHA] {
+		if isVoter(results[idx].Replica) {
+			majorityVoters++
+		}
+	}
+	if 2*majorityVoters <= len(voters) {
+		return nil, false
+	}
+	var minority []roachpb.ReplicaDescriptor
+	for sha, idxs := range shaToIdxs {
+		if sha == majoritySHA {
+			continue
+		}
+		for _, idx := range idxs {
+			minority = append(minority, results[idx].Replica)
+		}
+	}
+	return minority, len(minority) > 0
+}
+
+// repairInconsistency replaces the given minority replicas, which diverged from
+// a majority of their peers. Instead of terminating the minority nodes, the
+// consistency check is re-run to obtain a diff (and checkpoints), after which
+// each inconsistent voter is replaced by a new replica, allocated like those
+// of the replicate queue and up-replicated from the majority. The new replica
+// is added in the same change as the removal of the inconsistent one, so the
+// range is never under-replicated. The diff is recorded as the details of the
+// change in the range log, which the range report of the admin UI shows. The
+// replica GC queue then removes the corrupt copies.
+func (r *Replica) repairInconsistency(
+	ctx context.Context, args roachpb.CheckConsistencyRequest, minority []roachpb.ReplicaDescriptor,
+) error {
+	log.Errorf(ctx, "consistency check failed; fetching details and replacing minority %v", minority)
+	args.WithDiff = true
+	args.Checkpoint = true
+	args.Terminate = nil
+	resp, pErr := r.CheckConsistency(ctx, args)
+	if pErr != nil {
+		return pErr.GoError()
+	}
+	res := resp.Result[0]
+	if res.Status != roachpb.CheckConsistencyResponse_RANGE_INCONSISTENT {
+		return errors.Errorf("inconsistency did not reproduce: %s", res.Status)
+	}
+	details := truncateConsistencyRepairDetail(res.Detail)
+
+	for _, repl := range minority {
+		desc, zone := r.DescAndZone()
+		if _, ok := desc.GetReplicaDescriptorByID(repl.ReplicaID); !ok {
+			continue
+		}
+		chgs := roachpb.MakeReplicationChanges(roachpb.REMOVE_REPLICA, roachpb.ReplicationTarget{
+			NodeID:  repl.NodeID,
+			StoreID: repl.StoreID,
+		})
+		voters := desc.Replicas().Voters()
+		for _, v := range voters {
+			if v.ReplicaID != repl.ReplicaID {
+				continue
+			}
+			// The inconsistent replica is left among the existing replicas, so
+			// that the allocator doesn't pick its store again.
+			newStore, _, err := r.store.allocator.AllocateVoter(
+				ctx, zone, desc.RangeID, voters, desc.Replicas().NonVoters(),
+			)
+			if err != nil {
+				return errors.Wrapf(err, "allocating a replacement for inconsistent replica %s", repl)
+			}
+			// ChangeReplicas carries out the addition before the removal if it
+			// can't make both in a single atomic change.
+			chgs = append(roachpb.MakeReplicationChanges(roachpb.ADD_REPLICA, roachpb.ReplicationTarget{
+				NodeID:  newStore.Node.NodeID,
+				StoreID: newStore.StoreID,
+			}), chgs...)
+			break
+		}
+		if _, err := r.ChangeReplicas(
+			ctx, desc, SnapshotRequest_RECOVERY, storagepb.ReasonConsistencyRepair, details, chgs,
+		); err != nil {
+			return errors.Wrapf(err, "replacing inconsistent replica %s", repl)
+		}
+		log.Infof(ctx, "replaced inconsistent replica %s: %v", repl, chgs)
+	}
+	return nil
+}
+
 // A ConsistencyCheckResult contains the outcome of a CollectChecksum call.
 type ConsistencyCheckResult struct {
 	Replica  roachpb.ReplicaDescriptor
@@ -12,7 +12,9 @@ package storage
 
 import (
 	"context"
+	"strings"
 	"testing"
+	"unicode/utf8"
 
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/storage/batcheval"
@@ -56,3 +58,97 @@ func TestReplicaChecksumVersion(t *testing.T) {
 		}
 	})
 }
+
+func TestRepairableMinority(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	result := func(replicaID roachpb.ReplicaID, sha string) ConsistencyCheckResult {
+		return ConsistencyCheckResult{
+			Replica:  roachpb.ReplicaDescriptor{ReplicaID: replicaID},
+			Response: CollectChecksumResponse{Checksum: []byte(sha)},
+		}
+	}
+	desc := func(learners ...roachpb.ReplicaID) *roachpb.RangeDescriptor {
+		var d roachpb.RangeDescriptor
+		for id := roachpb.ReplicaID(1); id <= 3; id++ {
+			d.InternalReplicas = append(d.InternalReplicas, roachpb.ReplicaDescriptor{ReplicaID: id})
+		}
+		for _, id := range learners {
+			typ := roachpb.LEARNER
+			d.InternalReplicas = append(d.InternalReplicas,
+				roachpb.ReplicaDescriptor{ReplicaID: id, Type: &typ})
+		}
+		return &d
+	}
+	shaToIdxs := func(results []ConsistencyCheckResult) map[string][]int {
+		m := make(map[string][]int)
+		for i, res := range results {
+			sha := string(res.Response.Checksum)
+			m[sha] = append(m[sha], i)
+		}
+		return m
+	}
+	ids := func(repls []roachpb.ReplicaDescriptor) []roachpb.ReplicaID {
+		var ret []roachpb.ReplicaID
+		for _, repl := range repls {
+			ret = append(ret, repl.ReplicaID)
+		}
+		return ret
+	}
+
+	for _, tc := range []struct {
+		name     string
+		desc     *roachpb.RangeDescriptor
+		results  []ConsistencyCheckResult
+		ok       bool
+		minority []roachpb.ReplicaID
+	}{
+		{
+			name:     "voter minority",
+			desc:     desc(),
+			results:  []ConsistencyCheckResult{result(1, "a"), result(2, "a"), result(3, "b")},
+			ok:       true,
+			minority: []roachpb.ReplicaID{3},
+		},
+		{
+			name:    "local replica in minority",
+			desc:    desc(),
+			results: []ConsistencyCheckResult{result(1, "b"), result(2, "a"), result(3, "a")},
+		},
+		{
+			name:     "learner minority",
+			desc:     desc(4),
+			results:  []ConsistencyCheckResult{result(1, "a"), result(2, "a"), result(3, "a"), result(4, "b")},
+			ok:       true,
+			minority: []roachpb.ReplicaID{4},
+		},
+		{
+			// The learners agreeing with the local replica don't make up for the
+			// voters disagreeing with it.
+			name: "learners don't vote",
+			desc: desc(4, 5),
+			results: []ConsistencyCheckResult{
+				result(1, "a"), result(2, "b"), result(3, "b"), result(4, "a"), result(5, "a"),
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			minority, ok := repairableMinority(tc.desc, tc.results, shaToIdxs(tc.results))
+			require.Equal(t, tc.ok, ok)
+			require.Equal(t, tc.minority, ids(minority))
+		})
+	}
+}
+
+func TestTruncateConsistencyRepairDetail(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	short := strings.Repeat("a", maxConsistencyRepairDetailSize)
+	require.Equal(t, short, truncateConsistencyRepairDetail(short))
+
+	// A multi-byte character straddling the limit is dropped entirely.
+	long := strings.Repeat("a", maxConsistencyRepairDetailSize-1) + "é" + "b"
+	truncated := truncateConsistencyRepairDetail(long)
+	require.True(t, utf8.ValidString(truncated))
+	require.Equal(t, strings.Repeat("a", maxConsistencyRepairDetailSize-1)+"\n... (truncated)", truncated)
+}
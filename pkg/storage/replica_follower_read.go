@@ -37,16 +37,16 @@ var FollowerReadsEnabled = settings.RegisterPublicBoolSetting(
 func (r *Replica) canServeFollowerRead(
 	ctx context.Context, ba *roachpb.BatchRequest, pErr *roachpb.Error,
 ) *roachpb.Error {
-	// There's no known reason that a non-VOTER_FULL replica couldn't serve follower
-	// reads (or RangeFeed), but as of the time of writing, these are expected
-	// to be short-lived, so it's not worth working out the edge-cases. Revisit if
-	// we add long-lived learners or feel that incoming/outgoing voters also need
-	// to be able to serve follower reads.
+	// There's no known reason that a learner or incoming/outgoing voter couldn't
+	// serve follower reads (or RangeFeed), but as of the time of writing, these
+	// are expected to be short-lived, so it's not worth working out the
+	// edge-cases. Non-voting replicas are long-lived and exist precisely to serve
+	// follower reads, so they're permitted alongside full voters.
 	repDesc, err := r.GetReplicaDescriptor()
 	if err != nil {
 		return roachpb.NewError(err)
 	}
-	if typ := repDesc.GetType(); typ != roachpb.VOTER_FULL {
+	if typ := repDesc.GetType(); typ != roachpb.VOTER_FULL && typ != roachpb.NON_VOTER {
 		log.Eventf(ctx, "%s replicas cannot serve follower reads", typ)
 		return pErr
 	}
@@ -94,6 +94,12 @@ func (r *Replica) canServeFollowerRead(
 	return nil
 }
 
+// GetClosedTimestamp returns the closed timestamp of the range, see maxClosed.
+// It is part of the batcheval.EvalContext interface.
+func (r *Replica) GetClosedTimestamp(ctx context.Context) hlc.Timestamp {
+	return r.maxClosed(ctx)
+}
+
 // maxClosed returns the maximum closed timestamp for this range.
 // It is computed as the most recent of the known closed timestamp for the
 // current lease holder for this range as tracked by the closed timestamp
@@ -1040,3 +1040,52 @@ func TestMergeQueueSeesLearnerOrJointConfig(t *testing.T) {
 		require.False(t, desc.Replicas().InAtomicReplicationChange(), desc)
 	}
 }
+
+// TestMergeQueueSeesNonVoter verifies that the merge queue removes the
+// non-voting replicas of both sides before merging them, instead of giving up
+// on the merge.
+func TestMergeQueueSeesNonVoter(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	ctx := context.Background()
+	knobs, _ := makeReplicationTestKnobs()
+	tc := testcluster.StartTestCluster(t, 2, base.TestClusterArgs{
+		ServerArgs:      base.TestServerArgs{Knobs: knobs},
+		ReplicationMode: base.ReplicationManual,
+	})
+	defer tc.Stopper().Stop(ctx)
+	db := sqlutils.MakeSQLRunner(tc.ServerConn(0))
+	// TestCluster currently overrides this when used with ReplicationManual.
+	db.Exec(t, `SET CLUSTER SETTING kv.range_merge.queue_enabled = true`)
+
+	scratchStartKey := tc.ScratchRange(t)
+	origDesc := tc.LookupRangeOrFatal(t, scratchStartKey)
+
+	splitKey := scratchStartKey.Next()
+	_, rhsDesc := tc.SplitRangeOrFatal(t, splitKey)
+	// Unsplit the range to clear the sticky bit.
+	require.NoError(t, tc.Server(0).DB().AdminUnsplit(ctx, splitKey))
+
+	// Give the RHS a non-voter, which the LHS doesn't have.
+	_, err := tc.Server(0).DB().AdminChangeReplicas(ctx, splitKey, rhsDesc,
+		roachpb.MakeReplicationChanges(roachpb.ADD_NON_VOTER, tc.Target(1)))
+	require.NoError(t, err)
+
+	store, repl := getFirstStoreReplica(t, tc.Server(0), scratchStartKey)
+	trace, errMsg, err := store.ManuallyEnqueue(ctx, "merge", repl, true /* skipShouldQueue */)
+	require.NoError(t, err)
+	require.Equal(t, ``, errMsg)
+	formattedTrace := trace.String()
+	expectedMessages := []string{
+		`removing non-voter replicas \[n2,s2\]`,
+		`merging to produce range: /Table/Max-/Max`,
+	}
+	if err := testutils.MatchInOrder(formattedTrace, expectedMessages...); err != nil {
+		t.Fatal(err)
+	}
+
+	desc := tc.LookupRangeOrFatal(t, scratchStartKey)
+	require.Equal(t, origDesc.StartKey, desc.StartKey)
+	require.Equal(t, origDesc.EndKey, desc.EndKey)
+	require.Len(t, desc.Replicas().Voters(), 1)
+	require.Empty(t, desc.Replicas().NonVoters())
+}
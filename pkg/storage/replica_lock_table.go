@@ -0,0 +1,175 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package storage
+
+import (
+	"context"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine"
+	"github.com/cockroachdb/cockroach/pkg/storage/intentresolver"
+	"github.com/cockroachdb/cockroach/pkg/storage/locktable"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+)
+
+// lockTableEnabled controls whether requests wait in the lock table for the
+// locks they conflict with. When disabled, requests push the transactions
+// holding conflicting intents as soon as they run into them, and locking
+// reads don't acquire locks.
+//
+// The setting is disabled by default: SQL doesn't issue locking reads yet, so
+// the lock table only changes how requests wait on the intents they discover.
+// BenchmarkReplicaContention compares the throughput of contending
+// transactions with and without it, and should be checked before the default
+// is changed.
+var lockTableEnabled = settings.RegisterBoolSetting(
+	"kv.lock_table.enabled",
+	"if enabled, requests wait in a queue for the conflicting locks they discover to be released",
+	false,
+)
+
+// lockTableLivenessPushDelay is how long a request waits in the lock table
+// for a conflicting lock to be released before pushing its holder. The push
+// detects deadlocks and transactions whose coordinator died.
+var lockTableLivenessPushDelay = settings.RegisterNonNegativeDurationSetting(
+	"kv.lock_table.coordinator_liveness_push_delay",
+	"the delay before a request waiting on a lock pushes the lock holder's transaction",
+	10*time.Millisecond,
+)
+
+// lockTableRequest returns the description of the batch used to find the
+// locks it conflicts with. It returns false if the batch doesn't need to
+// consult the lock table: only requests which read or write MVCC data do.
+// Requests operating on intents and transaction records (e.g. ResolveIntent
+// or PushTxn) must not wait on the very locks they act upon.
+func lockTableRequest(ba *roachpb.BatchRequest) (locktable.Request, bool) {
+	req := locktable.Request{Timestamp: ba.Timestamp}
+	if ba.Txn != nil {
+		req.Txn = &ba.Txn.TxnMeta
+		// Intents in the uncertainty window of a transaction's reads conflict
+		// with them as well.
+		req.Timestamp.Forward(ba.Txn.MaxTimestamp)
+	}
+	for _, union := range ba.Requests {
+		args := union.GetInner()
+		switch {
+		case roachpb.IsTransactionWrite(args) || roachpb.IsLockingRead(args):
+			req.LockSpans = append(req.LockSpans, args.Header().Span())
+		case ba.ReadConsistency != roachpb.CONSISTENT:
+		default:
+			switch args.(type) {
+			case *roachpb.GetRequest, *roachpb.ScanRequest, *roachpb.ReverseScanRequest:
+				req.ReadSpans = append(req.ReadSpans, args.Header().Span())
+			}
+		}
+	}
+	return req, len(req.LockSpans) > 0 || len(req.ReadSpans) > 0
+}
+
+// waitInLockTable waits on the conflicting lock the request was enqueued on.
+// If the lock holder doesn't release it in time, it pushes the holder as if
+// the request had run into its intent during evaluation.
+func (r *Replica) waitInLockTable(
+	ctx context.Context,
+	ba *roachpb.BatchRequest,
+	g *locktable.Guard,
+	cleanup intentresolver.CleanupFunc,
+) (intentresolver.CleanupFunc, *roachpb.Error) {
+	pushDelay := lockTableLivenessPushDelay.Get(&r.store.cfg.Settings.SV)
+	intent, err := r.lockTable.WaitOn(ctx, g, pushDelay)
+	if err != nil {
+		return cleanup, roachpb.NewError(err)
+	}
+	if intent == nil {
+		return cleanup, nil
+	}
+	log.VEventf(ctx, 2, "pushing lock holder %s after waiting on %s", intent.Txn.ID.Short(), intent.Key)
+	wiErr := &roachpb.WriteIntentError{Intents: []roachpb.Intent{*intent}}
+	pErr := roachpb.NewError(wiErr)
+	pErr.SetErrorIndex(int32(lockTableRequestIndex(ba, intent.Key)))
+	return r.handleWriteIntentError(ctx, ba, pErr, wiErr, cleanup)
+}
+
+// lockTableRequestIndex returns the index of the first request in the batch
+// accessing the key.
+func lockTableRequestIndex(ba *roachpb.BatchRequest, key roachpb.Key) int {
+	for i, union := range ba.Requests {
+		if union.GetInner().Header().Span().ContainsKey(key) {
+			return i
+		}
+	}
+	return 0
+}
+
+// acquireUnreplicatedLocks records the unreplicated locks acquired by the
+// locking reads in the batch, on the keys they returned. It must be called
+// while the batch's latches are held.
+func (r *Replica) acquireUnreplicatedLocks(
+	ctx context.Context, ba *roachpb.BatchRequest, br *roachpb.BatchResponse,
+) {
+	if ba.Txn == nil || !lockTableEnabled.Get(&r.store.cfg.Settings.SV) {
+		return
+	}
+	txn := &ba.Txn.TxnMeta
+	for i, union := range ba.Requests {
+		if !roachpb.IsLockingRead(union.GetInner()) {
+			continue
+		}
+		var rows []roachpb.KeyValue
+		var batchResponses [][]byte
+		switch resp := br.Responses[i].GetInner().(type) {
+		case *roachpb.ScanResponse:
+			rows, batchResponses = resp.Rows, resp.BatchResponses
+		case *roachpb.ReverseScanResponse:
+			rows, batchResponses = resp.Rows, resp.BatchResponses
+		}
+		for j := range rows {
+			r.lockTable.AcquireLock(txn, rows[j].Key)
+		}
+		for _, repr := range batchResponses {
+			for len(repr) > 0 {
+				key, _, rest, err := engine.MVCCScanDecodeKeyValue(repr)
+				if err != nil {
+					log.Warningf(ctx, "unable to decode locked keys: %s", err)
+					break
+				}
+				r.lockTable.AcquireLock(txn, key.Key)
+				repr = rest
+			}
+		}
+	}
+}
+
+// updateLockTable updates the locks held by the transactions whose intents
+// were resolved by the batch, which must have completed successfully. This
+// releases the locks of finalized transactions and wakes up the requests
+// waiting on them.
+func (r *Replica) updateLockTable(ba *roachpb.BatchRequest, br *roachpb.BatchResponse) {
+	for _, union := range ba.Requests {
+		switch t := union.GetInner().(type) {
+		case *roachpb.ResolveIntentRequest:
+			r.lockTable.UpdateLocks(t.Span(), &t.IntentTxn, t.Status)
+		case *roachpb.ResolveIntentRangeRequest:
+			r.lockTable.UpdateLocks(t.Span(), &t.IntentTxn, t.Status)
+		case *roachpb.EndTransactionRequest:
+			// The intents of the transaction on this range were resolved
+			// synchronously if it was finalized.
+			if br.Txn == nil || !br.Txn.Status.IsFinalized() {
+				continue
+			}
+			for _, sp := range t.IntentSpans {
+				r.lockTable.UpdateLocks(sp, &br.Txn.TxnMeta, br.Txn.Status)
+			}
+		}
+	}
+}
@@ -0,0 +1,313 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package storage
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+	"github.com/pkg/errors"
+)
+
+// startLockTableTestContext starts a test context with the lock table
+// enabled. Requests waiting on a lock never push its holder, so that they
+// only proceed once the test releases the lock.
+func startLockTableTestContext(t *testing.T, stopper *stop.Stopper) *testContext {
+	tc := &testContext{manualClock: hlc.NewManualClock(123)}
+	cfg := TestStoreConfig(hlc.NewClock(tc.manualClock.UnixNano, time.Nanosecond))
+	cfg.TestingKnobs.DisableAutomaticLeaseRenewal = true
+	lockTableEnabled.Override(&cfg.Settings.SV, true)
+	lockTableLivenessPushDelay.Override(&cfg.Settings.SV, time.Hour)
+	tc.StartWithStoreConfig(t, stopper, cfg)
+	return tc
+}
+
+// waitForLockTableWaiters waits until n requests wait on the lock on key.
+func waitForLockTableWaiters(t *testing.T, tc *testContext, key roachpb.Key, n int) {
+	testutils.SucceedsSoon(t, func() error {
+		for _, l := range strings.Split(tc.repl.lockTable.String(), "\n") {
+			if strings.HasPrefix(l, key.String()+":") &&
+				strings.HasSuffix(l, fmt.Sprintf(", %d waiting", n)) {
+				return nil
+			}
+		}
+		return errors.Errorf("expected %d waiters on %s, lock table:\n%s", n, key, tc.repl.lockTable)
+	})
+}
+
+// sendPutAsync sends a transactional put in a goroutine and returns the channel
+// its error is delivered on.
+func sendPutAsync(tc *testContext, txn *roachpb.Transaction, key roachpb.Key) chan *roachpb.Error {
+	errCh := make(chan *roachpb.Error, 1)
+	put := putArgs(key, []byte("value"))
+	assignSeqNumsForReqs(txn, &put)
+	go func() {
+		_, pErr := tc.SendWrappedWith(roachpb.Header{Txn: txn}, &put)
+		errCh <- pErr
+	}()
+	return errCh
+}
+
+// TestReplicaLockTableWaitsOnDiscoveredIntent verifies that a request which
+// runs into an intent waits in the lock table, rather than pushing the intent's
+// transaction, and is woken up once the transaction commits and resolves the
+// intent.
+func TestReplicaLockTableWaitsOnDiscoveredIntent(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	stopper := stop.NewStopper()
+	defer stopper.Stop(context.TODO())
+	tc := startLockTableTestContext(t, stopper)
+
+	key := roachpb.Key("a")
+	txn1 := newTransaction("txn1", key, 1, tc.Clock())
+	put := putArgs(key, []byte("value"))
+	assignSeqNumsForReqs(txn1, &put)
+	if _, pErr := tc.SendWrappedWith(roachpb.Header{Txn: txn1}, &put); pErr != nil {
+		t.Fatal(pErr)
+	}
+
+	txn2 := newTransaction("txn2", key, 1, tc.Clock())
+	errCh := sendPutAsync(tc, txn2, key)
+	waitForLockTableWaiters(t, tc, key, 1)
+	select {
+	case pErr := <-errCh:
+		t.Fatalf("expected the put to wait on the intent, got %v", pErr)
+	default:
+	}
+
+	// Committing txn1 resolves its intent synchronously, which releases the
+	// lock and lets the waiting put proceed.
+	et, h := endTxnArgs(txn1, true /* commit */)
+	et.IntentSpans = []roachpb.Span{{Key: key}}
+	assignSeqNumsForReqs(txn1, &et)
+	if _, pErr := tc.SendWrappedWith(h, &et); pErr != nil {
+		t.Fatal(pErr)
+	}
+	if pErr := <-errCh; pErr != nil {
+		t.Fatal(pErr)
+	}
+	if n := tc.repl.lockTable.Len(); n != 0 {
+		t.Fatalf("expected an empty lock table, found %d locks:\n%s", n, tc.repl.lockTable)
+	}
+}
+
+// TestReplicaLockTableUnreplicatedLocks verifies that locking scans acquire
+// unreplicated locks on the keys they return, that the locks block writers but
+// not non-locking readers, and that resolving the holder's intents releases
+// them.
+func TestReplicaLockTableUnreplicatedLocks(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	stopper := stop.NewStopper()
+	defer stopper.Stop(context.TODO())
+	tc := startLockTableTestContext(t, stopper)
+
+	keyA, keyB, keyC := roachpb.Key("a"), roachpb.Key("b"), roachpb.Key("c")
+	for _, key := range []roachpb.Key{keyA, keyB} {
+		put := putArgs(key, []byte("value"))
+		if _, pErr := tc.SendWrapped(&put); pErr != nil {
+			t.Fatal(pErr)
+		}
+	}
+
+	txn1 := newTransaction("txn1", keyA, 1, tc.Clock())
+	scan := scanArgs(keyA, keyC)
+	scan.KeyLocking = true
+	resp, pErr := tc.SendWrappedWith(roachpb.Header{Txn: txn1}, &scan)
+	if pErr != nil {
+		t.Fatal(pErr)
+	}
+	if rows := resp.(*roachpb.ScanResponse).Rows; len(rows) != 2 {
+		t.Fatalf("expected 2 rows, got %v", rows)
+	}
+	if n := tc.repl.lockTable.Len(); n != 2 {
+		t.Fatalf("expected 2 locks, found %d:\n%s", n, tc.repl.lockTable)
+	}
+	if s := tc.repl.lockTable.String(); strings.Count(s, "(unreplicated)") != 2 {
+		t.Fatalf("expected 2 unreplicated locks:\n%s", s)
+	}
+
+	// Non-locking reads don't conflict with unreplicated locks.
+	txn2 := newTransaction("txn2", keyA, 1, tc.Clock())
+	get := getArgs(keyA)
+	if _, pErr := tc.SendWrappedWith(roachpb.Header{Txn: txn2}, &get); pErr != nil {
+		t.Fatal(pErr)
+	}
+
+	// Writes do.
+	errCh := sendPutAsync(tc, txn2, keyA)
+	waitForLockTableWaiters(t, tc, keyA, 1)
+
+	// Resolving the intents of txn1, even though it didn't write any, releases
+	// its locks.
+	txn1.Status = roachpb.ABORTED
+	resolve := roachpb.ResolveIntentRangeRequest{
+		RequestHeader: roachpb.RequestHeader{Key: keyA, EndKey: keyC},
+		IntentTxn:     txn1.TxnMeta,
+		Status:        txn1.Status,
+	}
+	if _, pErr := tc.SendWrapped(&resolve); pErr != nil {
+		t.Fatal(pErr)
+	}
+	if pErr := <-errCh; pErr != nil {
+		t.Fatal(pErr)
+	}
+	if n := tc.repl.lockTable.Len(); n != 0 {
+		t.Fatalf("expected an empty lock table, found %d locks:\n%s", n, tc.repl.lockTable)
+	}
+}
+
+// TestReplicaLockTableClearedOnLeaseChange verifies that the lock table is
+// cleared when the lease changes hands, and that the requests waiting in it
+// are woken up and redirected to the new leaseholder.
+func TestReplicaLockTableClearedOnLeaseChange(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	stopper := stop.NewStopper()
+	defer stopper.Stop(context.TODO())
+	tc := startLockTableTestContext(t, stopper)
+
+	secondReplica, err := tc.addBogusReplicaToRangeDesc(context.TODO())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key := roachpb.Key("a")
+	put := putArgs(key, []byte("value"))
+	if _, pErr := tc.SendWrapped(&put); pErr != nil {
+		t.Fatal(pErr)
+	}
+	txn1 := newTransaction("txn1", key, 1, tc.Clock())
+	scan := scanArgs(key, key.Next())
+	scan.KeyLocking = true
+	if _, pErr := tc.SendWrappedWith(roachpb.Header{Txn: txn1}, &scan); pErr != nil {
+		t.Fatal(pErr)
+	}
+
+	txn2 := newTransaction("txn2", key, 1, tc.Clock())
+	errCh := sendPutAsync(tc, txn2, key)
+	waitForLockTableWaiters(t, tc, key, 1)
+
+	tc.manualClock.Set(leaseExpiry(tc.repl))
+	now := tc.Clock().Now()
+	if err := sendLeaseRequest(tc.repl, &roachpb.Lease{
+		Start:      now,
+		Expiration: now.Add(10, 0).Clone(),
+		Replica:    secondReplica,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if pErr := <-errCh; pErr == nil {
+		t.Fatal("expected the waiting put to fail")
+	} else if _, ok := pErr.GetDetail().(*roachpb.NotLeaseHolderError); !ok {
+		t.Fatalf("expected not lease holder error, got %v", pErr)
+	}
+	if n := tc.repl.lockTable.Len(); n != 0 {
+		t.Fatalf("expected an empty lock table, found %d locks:\n%s", n, tc.repl.lockTable)
+	}
+}
+
+// TestReplicaLockTableDisabled verifies that locking reads don't acquire locks
+// when the lock table is disabled, and that locking reads are rejected unless
+// they are consistent and transactional.
+func TestReplicaLockTableDisabled(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	stopper := stop.NewStopper()
+	defer stopper.Stop(context.TODO())
+	tc := testContext{}
+	tc.Start(t, stopper)
+
+	key := roachpb.Key("a")
+	put := putArgs(key, []byte("value"))
+	if _, pErr := tc.SendWrapped(&put); pErr != nil {
+		t.Fatal(pErr)
+	}
+
+	txn := newTransaction("txn", key, 1, tc.Clock())
+	scan := scanArgs(key, key.Next())
+	scan.KeyLocking = true
+	if _, pErr := tc.SendWrappedWith(roachpb.Header{Txn: txn}, &scan); pErr != nil {
+		t.Fatal(pErr)
+	}
+	if n := tc.repl.lockTable.Len(); n != 0 {
+		t.Fatalf("expected an empty lock table, found %d locks:\n%s", n, tc.repl.lockTable)
+	}
+
+	const expErr = "Scan with key locking is only available to consistent transactional reads"
+	if _, pErr := tc.SendWrapped(&scan); !testutils.IsPError(pErr, expErr) {
+		t.Fatalf("expected %q, got %v", expErr, pErr)
+	}
+	if _, pErr := tc.SendWrappedWith(roachpb.Header{
+		ReadConsistency: roachpb.INCONSISTENT,
+	}, &scan); !testutils.IsPError(pErr, expErr) {
+		t.Fatalf("expected %q, got %v", expErr, pErr)
+	}
+}
+
+// BenchmarkReplicaContention measures the throughput of transactions which
+// increment a few hot keys, with and without the lock table. Without the lock
+// table, a transaction which runs into an intent pushes the intent's
+// transaction right away and waits in the txn wait queue. With it, the
+// transaction waits in the lock table for the intent to be resolved.
+func BenchmarkReplicaContention(b *testing.B) {
+	for _, enabled := range []bool{false, true} {
+		for _, numKeys := range []int{1, 16} {
+			b.Run(fmt.Sprintf("lock_table=%t/keys=%d", enabled, numKeys), func(b *testing.B) {
+				runReplicaContentionBenchmark(b, enabled, numKeys)
+			})
+		}
+	}
+}
+
+func runReplicaContentionBenchmark(b *testing.B, enabled bool, numKeys int) {
+	ctx := context.Background()
+	s, _, kvDB := serverutils.StartServer(b, base.TestServerArgs{})
+	defer s.Stopper().Stop(ctx)
+	lockTableEnabled.Override(&s.ClusterSettings().SV, enabled)
+
+	hotKeys := make([]roachpb.Key, numKeys)
+	for i := range hotKeys {
+		hotKeys[i] = roachpb.Key(fmt.Sprintf("hot-%03d", i))
+	}
+	var seq int64
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			hotKey := hotKeys[rand.Intn(numKeys)]
+			// The second write keeps the transaction from committing in one
+			// phase, so that its intent on the hot key is visible to the other
+			// transactions until it commits.
+			key := roachpb.Key(fmt.Sprintf("cold-%d", atomic.AddInt64(&seq, 1)))
+			if err := kvDB.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+				if _, err := txn.Inc(ctx, hotKey, 1); err != nil {
+					return err
+				}
+				return txn.Put(ctx, key, "value")
+			}); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.StopTimer()
+}
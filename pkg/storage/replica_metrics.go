@@ -117,7 +117,7 @@ func calcReplicaMetrics(
 	m.Ticking = ticking
 
 	m.RangeCounter, m.Unavailable, m.Underreplicated, m.Overreplicated =
-		calcRangeCounter(storeID, desc, livenessMap, *zone.NumReplicas, clusterNodes)
+		calcRangeCounter(storeID, desc, livenessMap, zone.GetNumVoters(), clusterNodes)
 
 	// The raft leader computes the number of raft entries that replicas are
 	// behind.
@@ -239,6 +239,22 @@ func (r *Replica) WritesPerSecond() float64 {
 	return wps
 }
 
+// WriteBytesPerSecond returns the range's average bytes written per second, as
+// measured by the size of the write batches applied by Raft.
+func (r *Replica) WriteBytesPerSecond() float64 {
+	bps, _ := r.writeBytesStats.avgQPS()
+	return bps
+}
+
+// EvalNanosPerSecond returns the average number of nanoseconds per second of
+// wall time spent evaluating requests on the replica. Go doesn't expose the CPU
+// time used by a goroutine, so the time spent evaluating batches, after
+// latches have been acquired and excluding replication, stands in for it.
+func (r *Replica) EvalNanosPerSecond() float64 {
+	nanos, _ := r.evalTimeStats.avgQPS()
+	return nanos
+}
+
 func (r *Replica) needsSplitBySizeRLocked() bool {
 	return r.exceedsMultipleOfSplitSizeRLocked(1)
 }
@@ -358,6 +358,9 @@ func (r *Replica) leasePostApply(ctx context.Context, newLease roachpb.Lease, pe
 		if r.leaseholderStats != nil {
 			r.leaseholderStats.resetRequestCounts()
 		}
+		if r.evalTimeStats != nil {
+			r.evalTimeStats.resetRequestCounts()
+		}
 	}
 
 	// Sanity check to make sure that the lease sequence is moving in the right
@@ -419,6 +422,13 @@ func (r *Replica) leasePostApply(ctx context.Context, newLease roachpb.Lease, pe
 		r.txnWaitQueue.Clear(true /* disable */)
 	}
 
+	if leaseChangingHands {
+		// The unreplicated locks in the lock table only live on the
+		// leaseholder, and the intents in it may have been resolved under
+		// another lease. Start from scratch, waking up any waiters.
+		r.lockTable.Clear()
+	}
+
 	// If we're the current raft leader, may want to transfer the leadership to
 	// the new leaseholder. Note that this condition is also checked periodically
 	// when ticking the replica.
@@ -437,6 +447,9 @@ func (r *Replica) leasePostApply(ctx context.Context, newLease roachpb.Lease, pe
 		if r.leaseholderStats != nil {
 			r.leaseholderStats.resetRequestCounts()
 		}
+		if r.evalTimeStats != nil {
+			r.evalTimeStats.resetRequestCounts()
+		}
 	}
 
 	// Potentially re-gossip if the range contains system data (e.g. system
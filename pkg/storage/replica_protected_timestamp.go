@@ -0,0 +1,62 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package storage
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/config"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine"
+	"github.com/cockroachdb/cockroach/pkg/storage/protectedts/ptpb"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+)
+
+// checkProtectedTimestampsForGC determines whether the GC threshold of the
+// replica can be advanced and, if so, the timestamp which should be used as
+// the current time when running GC. The GC threshold computed from
+// gcTimestamp is guaranteed to be below the timestamp of every protected
+// timestamp record covering the range.
+//
+// Records are only known as of the timestamp reported by the tracker. A
+// record created later protects a timestamp which, by the contract of
+// protectedts.Storage.Protect, is above that timestamp less the GC TTL, so
+// gcTimestamp is not allowed to exceed it.
+func (r *Replica) checkProtectedTimestampsForGC(
+	ctx context.Context, policy config.GCPolicy,
+) (canGC bool, gcTimestamp hlc.Timestamp) {
+	gcTimestamp = r.store.Clock().Now()
+	if tracker := r.store.cfg.ProtectedTimestampTracker; tracker != nil {
+		var earliestProtected hlc.Timestamp
+		desc := r.Desc()
+		asOf := tracker.ProtectedBy(ctx, desc.RSpan().AsRawSpanWithNoLocals(),
+			func(rec *ptpb.Record) {
+				if earliestProtected == (hlc.Timestamp{}) || rec.Timestamp.Less(earliestProtected) {
+					earliestProtected = rec.Timestamp
+				}
+			})
+		gcTimestamp.Backward(asOf)
+		if earliestProtected != (hlc.Timestamp{}) {
+			ttlNanos := int64(policy.TTLSeconds) * 1e9
+			gcTimestamp.Backward(earliestProtected.Prev().Add(ttlNanos, 0))
+			log.VEventf(ctx, 2, "earliest protected timestamp covering the range is %s",
+				earliestProtected)
+		}
+	}
+
+	newThreshold := engine.MakeGarbageCollector(gcTimestamp, policy).Threshold
+	oldThreshold := r.GetGCThreshold()
+	if !oldThreshold.Less(newThreshold) {
+		log.VEventf(ctx, 2, "cannot advance GC threshold %s to %s", oldThreshold, newThreshold)
+		return false, gcTimestamp
+	}
+	return true, gcTimestamp
+}
@@ -0,0 +1,86 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/config"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/storage/protectedts/ptpb"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeTracker is a protectedts.Tracker which reports a fixed set of records
+// as of a fixed timestamp.
+type fakeTracker struct {
+	asOf    hlc.Timestamp
+	records []ptpb.Record
+}
+
+func (f *fakeTracker) ProtectedBy(
+	ctx context.Context, s roachpb.Span, it func(*ptpb.Record),
+) (asOf hlc.Timestamp) {
+	for i := range f.records {
+		it(&f.records[i])
+	}
+	return f.asOf
+}
+
+func TestCheckProtectedTimestampsForGC(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	ctx := context.Background()
+
+	manual := hlc.NewManualClock(123)
+	tsc := TestStoreConfig(hlc.NewClock(manual.UnixNano, time.Nanosecond))
+	tracker := &fakeTracker{}
+	tsc.ProtectedTimestampTracker = tracker
+	tc := testContext{manualClock: manual}
+	stopper := stop.NewStopper()
+	defer stopper.Stop(ctx)
+	tc.StartWithStoreConfig(t, stopper, tsc)
+	manual.Set(3 * 24 * time.Hour.Nanoseconds())
+
+	const ttlSec = 3600
+	policy := config.GCPolicy{TTLSeconds: ttlSec}
+	ttl := hlc.Timestamp{WallTime: ttlSec * time.Second.Nanoseconds()}
+
+	// The tracker has not yet read the state, so nothing can be GC'd.
+	canGC, _ := tc.repl.checkProtectedTimestampsForGC(ctx, policy)
+	require.False(t, canGC)
+
+	// Without records, GC runs as of the current time.
+	now := tc.Clock().Now()
+	tracker.asOf = now
+	canGC, gcTimestamp := tc.repl.checkProtectedTimestampsForGC(ctx, policy)
+	require.True(t, canGC)
+	require.False(t, gcTimestamp.Less(now))
+
+	// A record holds the GC threshold below its timestamp.
+	protected := now.Add(-2*time.Hour.Nanoseconds(), 0)
+	tracker.records = []ptpb.Record{{Timestamp: protected}}
+	canGC, gcTimestamp = tc.repl.checkProtectedTimestampsForGC(ctx, policy)
+	require.True(t, canGC)
+	require.Equal(t, protected.Prev().Add(ttl.WallTime, 0), gcTimestamp)
+	require.True(t, gcTimestamp.Add(-ttl.WallTime, 0).Less(protected))
+
+	// Records are only known as of asOf, so GC may not run at a later time.
+	tracker.records = nil
+	tracker.asOf = protected
+	canGC, gcTimestamp = tc.repl.checkProtectedTimestampsForGC(ctx, policy)
+	require.True(t, canGC)
+	require.Equal(t, protected, gcTimestamp)
+}
@@ -24,17 +24,24 @@ const (
 type replicaWithStats struct {
 	repl *Replica
 	qps  float64
+	// cpu is the number of nanoseconds per second spent evaluating requests on
+	// the replica.
+	cpu float64
+	// writeBytes is the number of bytes per second written by commands applied
+	// to the replica.
+	writeBytes float64
 	// TODO(a-robinson): Include writes-per-second and logicalBytes of storage?
 }
 
 // replicaRankings maintains top-k orderings of the replicas in a store along
-// different dimensions of concern, such as QPS, keys written per second, and
-// disk used.
+// different dimensions of concern, such as QPS, CPU time, keys written per
+// second, and disk used.
 type replicaRankings struct {
 	mu struct {
 		syncutil.Mutex
-		qpsAccumulator *rrAccumulator
-		byQPS          []replicaWithStats
+		accumulator *rrAccumulator
+		byQPS       []replicaWithStats
+		byCPU       []replicaWithStats
 	}
 }
 
@@ -45,12 +52,13 @@ func newReplicaRankings() *replicaRankings {
 func (rr *replicaRankings) newAccumulator() *rrAccumulator {
 	res := &rrAccumulator{}
 	res.qps.val = func(r replicaWithStats) float64 { return r.qps }
+	res.cpu.val = LBRebalancingCPU.replicaLoad
 	return res
 }
 
 func (rr *replicaRankings) update(acc *rrAccumulator) {
 	rr.mu.Lock()
-	rr.mu.qpsAccumulator = acc
+	rr.mu.accumulator = acc
 	rr.mu.Unlock()
 }
 
@@ -59,12 +67,31 @@ func (rr *replicaRankings) topQPS() []replicaWithStats {
 	defer rr.mu.Unlock()
 	// If we have a new set of data, consume it. Otherwise, just return the most
 	// recently consumed data.
-	if rr.mu.qpsAccumulator.qps.Len() > 0 {
-		rr.mu.byQPS = consumeAccumulator(&rr.mu.qpsAccumulator.qps)
+	if rr.mu.accumulator.qps.Len() > 0 {
+		rr.mu.byQPS = consumeAccumulator(&rr.mu.accumulator.qps)
 	}
 	return rr.mu.byQPS
 }
 
+// topCPU is like topQPS, but orders the replicas by their load along the
+// LBRebalancingCPU dimension.
+func (rr *replicaRankings) topCPU() []replicaWithStats {
+	rr.mu.Lock()
+	defer rr.mu.Unlock()
+	if rr.mu.accumulator.cpu.Len() > 0 {
+		rr.mu.byCPU = consumeAccumulator(&rr.mu.accumulator.cpu)
+	}
+	return rr.mu.byCPU
+}
+
+// topLoad returns the hottest replicas along the provided dimension.
+func (rr *replicaRankings) topLoad(dim LBRebalancingDimension) []replicaWithStats {
+	if dim == LBRebalancingCPU {
+		return rr.topCPU()
+	}
+	return rr.topQPS()
+}
+
 // rrAccumulator is used to update the replicas tracked by replicaRankings.
 // The typical pattern should be to call replicaRankings.newAccumulator, add
 // all the replicas you care about to the accumulator using addReplica, then
@@ -75,21 +102,12 @@ func (rr *replicaRankings) topQPS() []replicaWithStats {
 // `update`d accumulator will win.
 type rrAccumulator struct {
 	qps rrPriorityQueue
+	cpu rrPriorityQueue
 }
 
 func (a *rrAccumulator) addReplica(repl replicaWithStats) {
-	// If the heap isn't full, just push the new replica and return.
-	if a.qps.Len() < numTopReplicasToTrack {
-		heap.Push(&a.qps, repl)
-		return
-	}
-
-	// Otherwise, conditionally push if the new replica is more deserving than
-	// the current tip of the heap.
-	if repl.qps > a.qps.entries[0].qps {
-		heap.Pop(&a.qps)
-		heap.Push(&a.qps, repl)
-	}
+	a.qps.add(repl)
+	a.cpu.add(repl)
 }
 
 func consumeAccumulator(pq *rrPriorityQueue) []replicaWithStats {
@@ -106,6 +124,21 @@ type rrPriorityQueue struct {
 	val     func(replicaWithStats) float64
 }
 
+func (pq *rrPriorityQueue) add(repl replicaWithStats) {
+	// If the heap isn't full, just push the new replica and return.
+	if pq.Len() < numTopReplicasToTrack {
+		heap.Push(pq, repl)
+		return
+	}
+
+	// Otherwise, conditionally push if the new replica is more deserving than
+	// the current tip of the heap.
+	if pq.val(repl) > pq.val(pq.entries[0]) {
+		heap.Pop(pq)
+		heap.Push(pq, repl)
+	}
+}
+
 func (pq rrPriorityQueue) Len() int { return len(pq.entries) }
 
 func (pq rrPriorityQueue) Less(i, j int) bool {
@@ -71,3 +71,30 @@ func TestReplicaRankings(t *testing.T) {
 		}
 	}
 }
+
+func TestReplicaRankingsByCPU(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	rr := newReplicaRankings()
+	acc := rr.newAccumulator()
+	// r1 receives the most requests, but r2 scans a lot of data and r3 writes
+	// a lot of bytes.
+	acc.addReplica(replicaWithStats{repl: &Replica{RangeID: 1}, qps: 1000, cpu: 1e6})
+	acc.addReplica(replicaWithStats{repl: &Replica{RangeID: 2}, qps: 10, cpu: 1e8})
+	acc.addReplica(replicaWithStats{repl: &Replica{RangeID: 3}, qps: 10, writeBytes: 1e5})
+	rr.update(acc)
+
+	var byQPS, byCPU []roachpb.RangeID
+	for _, r := range rr.topLoad(LBRebalancingQueries) {
+		byQPS = append(byQPS, r.repl.RangeID)
+	}
+	for _, r := range rr.topLoad(LBRebalancingCPU) {
+		byCPU = append(byCPU, r.repl.RangeID)
+	}
+	if len(byQPS) != 3 || byQPS[0] != 1 {
+		t.Errorf("expected r1 to be the hottest replica by QPS, got %v", byQPS)
+	}
+	if exp := []roachpb.RangeID{2, 3, 1}; !reflect.DeepEqual(byCPU, exp) {
+		t.Errorf("expected replicas ordered by CPU to be %v, got %v", exp, byCPU)
+	}
+}
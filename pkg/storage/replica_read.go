@@ -21,6 +21,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/storage/storagepb"
 	"github.com/cockroachdb/cockroach/pkg/util"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 )
 
 // executeReadOnlyBatch is the execution logic for client requests which do not
@@ -67,7 +68,9 @@ func (r *Replica) executeReadOnlyBatch(
 		readOnly = spanset.NewReadWriterAt(readOnly, spans, ba.Timestamp)
 	}
 	defer readOnly.Close()
+	evalStart := timeutil.Now()
 	br, result, pErr = evaluateBatch(ctx, storagebase.CmdIDKey(""), readOnly, rec, nil, ba, true /* readOnly */)
+	r.evalTimeStats.recordCount(float64(timeutil.Since(evalStart)), 0 /* nodeID */)
 
 	// A merge is (likely) about to be carried out, and this replica
 	// needs to block all traffic until the merge either commits or
@@ -96,6 +99,9 @@ func (r *Replica) executeReadOnlyBatch(
 	if pErr != nil {
 		log.VErrEvent(ctx, 3, pErr.String())
 	} else {
+		// Locking reads acquire unreplicated locks on the keys they returned
+		// before releasing their latches.
+		r.acquireUnreplicatedLocks(ctx, ba, br)
 		log.Event(ctx, "read completed")
 	}
 	return br, pErr
@@ -13,17 +13,24 @@ package storage
 import (
 	"context"
 	"reflect"
+	"time"
 
+	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/storage/batcheval"
+	"github.com/cockroachdb/cockroach/pkg/storage/contention"
 	"github.com/cockroachdb/cockroach/pkg/storage/intentresolver"
+	"github.com/cockroachdb/cockroach/pkg/storage/locktable"
 	"github.com/cockroachdb/cockroach/pkg/storage/spanlatch"
 	"github.com/cockroachdb/cockroach/pkg/storage/spanset"
 	"github.com/cockroachdb/cockroach/pkg/storage/storagepb"
 	"github.com/cockroachdb/cockroach/pkg/storage/txnwait"
+	"github.com/cockroachdb/cockroach/pkg/util/admission"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/tracing"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
 	opentracing "github.com/opentracing/opentracing-go"
 	"github.com/pkg/errors"
 )
@@ -171,6 +178,43 @@ func (r *Replica) executeBatchWithConcurrencyRetries(
 		}
 	}()
 
+	// contentionTime accumulates the time spent waiting on conflicting
+	// latches, locks, and on the transactions that wrote conflicting intents.
+	var contentionTime time.Duration
+
+	// If the batch reads or writes MVCC data, it waits in the lock table for
+	// the conflicting locks it knows about to be released. The Guard tracks
+	// its position in the lock table across retries.
+	var ltReq locktable.Request
+	var useLockTable bool
+	if lockTableEnabled.Get(&r.store.cfg.Settings.SV) {
+		ltReq, useLockTable = lockTableRequest(ba)
+	}
+	var ltg *locktable.Guard
+	// discovered is set when the batch added the intents it ran into to the
+	// lock table and hasn't waited on them yet.
+	var discovered bool
+	defer func() {
+		if ltg != nil {
+			r.lockTable.Dequeue(ltg)
+		}
+	}()
+
+	// The batch asks for admission once it is sequenced, that is, once it holds
+	// its latches and doesn't have to wait in the lock table, and only holds
+	// its admission slot while it is evaluated. This way, the slots of an
+	// overloaded node go to work which can make progress, rather than to work
+	// waiting on conflicting requests.
+	admissionQ := r.store.cfg.KVAdmissionQ
+	var admitted bool
+	releaseAdmission := func() {
+		if admitted {
+			admissionQ.AdmittedWorkDone()
+			admitted = false
+		}
+	}
+	defer releaseAdmission()
+
 	// Try to execute command; exit retry loop on success.
 	for {
 		// Exit loop if context has been canceled or timed out.
@@ -195,21 +239,83 @@ func (r *Replica) executeBatchWithConcurrencyRetries(
 		if err != nil {
 			return nil, roachpb.NewError(err)
 		}
+		if lg != nil {
+			contentionTime += lg.ContentionTime()
+		}
+
+		// Scan the lock table for conflicting locks while holding latches. If
+		// the batch needs to wait on one, it releases its latches while
+		// waiting so that the lock holder can proceed.
+		if useLockTable && lg != nil {
+			var wait bool
+			ltg, wait = r.lockTable.ScanAndEnqueue(ltReq, ltg)
+			if wait {
+				r.latchMgr.Release(lg)
+				releaseAdmission()
+				discovered = false
+				start := timeutil.Now()
+				if cleanup, pErr = r.waitInLockTable(ctx, ba, ltg, cleanup); pErr != nil {
+					return nil, pErr
+				}
+				contentionTime += timeutil.Since(start)
+				continue
+			}
+		}
+
+		// If no admission slot is free, release the latches while waiting for
+		// one. The slot is then held while the batch is sequenced again, which
+		// normally doesn't take long since it was sequenced just before.
+		if admissionQ != nil && !admitted {
+			info := kvAdmissionInfo(ba, r.store.cfg.Clock.PhysicalNow())
+			var enabled bool
+			if enabled, admitted = admissionQ.TryAdmit(info); enabled && !admitted {
+				if lg != nil {
+					r.latchMgr.Release(lg)
+				}
+				if admitted, err = admissionQ.Admit(ctx, info); err != nil {
+					return nil, roachpb.NewError(err)
+				}
+				continue
+			}
+		}
 
 		br, pErr = fn(r, ctx, ba, spans, lg)
+		if pErr != nil {
+			// Don't hold on to the slot while handling the error, which may
+			// involve waiting on other transactions.
+			releaseAdmission()
+		}
 		switch t := pErr.GetDetail().(type) {
 		case nil:
 			// Success.
+			r.updateLockTable(ba, br)
+			br.ContentionTime += contentionTime
 			return br, nil
 		case *roachpb.WriteIntentError:
+			// Wait in the lock table for the discovered intents to be
+			// resolved, if it can track them. Otherwise, or if the batch
+			// didn't wait on the intents it discovered in its previous
+			// attempt, push the transactions that wrote them right away.
+			if ltg != nil && !discovered && !r.store.cfg.TestingKnobs.DontPushOnWriteIntentError &&
+				r.lockTable.AddDiscoveredLocks(ltg, t.Intents) {
+				discovered = true
+				continue
+			}
+			discovered = false
+			start := timeutil.Now()
 			if cleanup, pErr = r.handleWriteIntentError(ctx, ba, pErr, t, cleanup); pErr != nil {
 				return nil, pErr
 			}
+			dur := timeutil.Since(start)
+			contentionTime += dur
+			r.recordIntentContention(ba, t.Intents, dur)
 			// Retry...
 		case *roachpb.TransactionPushError:
+			start := timeutil.Now()
 			if pErr = r.handleTransactionPushError(ctx, ba, pErr, t); pErr != nil {
 				return nil, pErr
 			}
+			contentionTime += timeutil.Since(start)
 			// Retry...
 		case *roachpb.IndeterminateCommitError:
 			if pErr = r.handleIndeterminateCommitError(ctx, ba, pErr, t); pErr != nil {
@@ -228,6 +334,39 @@ func (r *Replica) executeBatchWithConcurrencyRetries(
 	}
 }
 
+// recordIntentContention records a contention event for each of the given
+// intents, whose transactions the batch pushed through the txn wait queue
+// for the given duration. The events are recorded here rather than in the
+// txn wait queue, which may be on another node and only knows the key of the
+// pushed transaction's record, not the key the batch ran into.
+func (r *Replica) recordIntentContention(
+	ba *roachpb.BatchRequest, intents []roachpb.Intent, dur time.Duration,
+) {
+	registry := r.store.cfg.ContentionRegistry
+	if registry == nil {
+		return
+	}
+	var waitingTxnID uuid.UUID
+	if ba.Txn != nil {
+		waitingTxnID = ba.Txn.ID
+	}
+	now := timeutil.Now()
+	for i := range intents {
+		// A transaction doesn't contend with itself.
+		if intents[i].Txn.ID == waitingTxnID {
+			continue
+		}
+		registry.Add(contention.Event{
+			Key:           intents[i].Key,
+			BlockingTxnID: intents[i].Txn.ID,
+			WaitingTxnID:  waitingTxnID,
+			Time:          now,
+			Duration:      dur,
+			Source:        contention.SourceTxnWaitQueue,
+		})
+	}
+}
+
 func (r *Replica) handleWriteIntentError(
 	ctx context.Context,
 	ba *roachpb.BatchRequest,
@@ -498,6 +637,14 @@ func (r *Replica) checkBatchRequest(ba *roachpb.BatchRequest, isReadOnly bool) e
 	} else if !consistent {
 		return errors.Errorf("%v mode is only available to reads", ba.ReadConsistency)
 	}
+	if ba.Txn == nil || !consistent {
+		for _, union := range ba.Requests {
+			if args := union.GetInner(); roachpb.IsLockingRead(args) {
+				return errors.Errorf("%s with key locking is only available to consistent "+
+					"transactional reads", args.Method())
+			}
+		}
+	}
 
 	return nil
 }
@@ -612,3 +759,41 @@ func (r *Replica) limitTxnMaxTimestamp(
 		ba.Txn = txnClone
 	}
 }
+
+// kvAdmissionInfo returns the admission control information of a batch.
+// Batches addressing system keys, such as node liveness records and range
+// descriptors, and batches coordinating transactions or leases bypass
+// admission: the work that is waiting for admission may depend on them to
+// make progress. Other batches are ordered by user priority, and then by the
+// start time of their transaction so that older transactions finish first.
+func kvAdmissionInfo(ba *roachpb.BatchRequest, now int64) admission.WorkInfo {
+	info := admission.WorkInfo{
+		Priority:   admission.UserPriorityToWorkPriority(ba.UserPriority),
+		CreateTime: now,
+	}
+	if ba.Txn != nil && ba.Txn.MinTimestamp.WallTime != 0 {
+		info.CreateTime = ba.Txn.MinTimestamp.WallTime
+	}
+	for _, union := range ba.Requests {
+		if bypassesAdmission(union.GetInner()) {
+			info.BypassAdmission = true
+			break
+		}
+	}
+	return info
+}
+
+func bypassesAdmission(arg roachpb.Request) bool {
+	if arg.Header().Key.Compare(keys.UserTableDataMin) < 0 {
+		return true
+	}
+	switch arg.(type) {
+	case *roachpb.EndTransactionRequest, *roachpb.HeartbeatTxnRequest,
+		*roachpb.PushTxnRequest, *roachpb.QueryTxnRequest, *roachpb.RecoverTxnRequest,
+		*roachpb.ResolveIntentRequest, *roachpb.ResolveIntentRangeRequest,
+		*roachpb.QueryIntentRequest, *roachpb.RequestLeaseRequest,
+		*roachpb.TransferLeaseRequest:
+		return true
+	}
+	return false
+}
@@ -39,6 +39,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/storage/apply"
 	"github.com/cockroachdb/cockroach/pkg/storage/batcheval"
 	"github.com/cockroachdb/cockroach/pkg/storage/batcheval/result"
+	"github.com/cockroachdb/cockroach/pkg/storage/contention"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
 	"github.com/cockroachdb/cockroach/pkg/storage/intentresolver"
@@ -50,6 +51,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/storage/txnwait"
 	"github.com/cockroachdb/cockroach/pkg/testutils"
 	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/util/admission"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
@@ -2621,6 +2623,111 @@ func TestReplicaLatchingSelfOverlap(t *testing.T) {
 	})
 }
 
+// TestReplicaAdmissionAfterSequencing verifies that batches ask for admission
+// once they hold their latches, and that they don't hold on to their latches
+// while waiting for admission.
+func TestReplicaAdmissionAfterSequencing(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	tc := testContext{}
+	stopper := stop.NewStopper()
+	defer stopper.Stop(context.TODO())
+	cfg := TestStoreConfig(nil)
+	admission.KVAdmissionControlEnabled.Override(&cfg.Settings.SV, true)
+	q := admission.NewController(cfg.Settings, time.Minute, nil /* l0FileCount */).KVQueue()
+	cfg.KVAdmissionQ = q
+	tc.StartWithStoreConfig(t, stopper, cfg)
+	q.SetTotalSlotsForTesting(1)
+
+	ctx := context.Background()
+	keyA := keys.UserTableDataMin.Next()
+	keyB := keyA.Next()
+	writeLatches := func() int64 {
+		global, _ := tc.repl.latchMgr.Info()
+		return global.WriteCount
+	}
+	put := func(key roachpb.Key) chan *roachpb.Error {
+		errCh := make(chan *roachpb.Error, 1)
+		go func() {
+			args := putArgs(key, []byte("value"))
+			_, pErr := client.SendWrapped(ctx, tc.Sender(), &args)
+			errCh <- pErr
+		}()
+		return errCh
+	}
+
+	// A batch waiting on a latch doesn't hold an admission slot, so it doesn't
+	// prevent other batches from being admitted.
+	var spans spanset.SpanSet
+	spans.AddNonMVCC(spanset.SpanReadWrite, roachpb.Span{Key: keyA})
+	lg, err := tc.repl.latchMgr.Acquire(ctx, &spans, uuid.UUID{})
+	require.NoError(t, err)
+	errA := put(keyA)
+	testutils.SucceedsSoon(t, func() error {
+		if n := writeLatches(); n != 2 {
+			return errors.Errorf("expected 2 write latches, found %d", n)
+		}
+		return nil
+	})
+	require.Nil(t, <-put(keyB))
+	tc.repl.latchMgr.Release(lg)
+	require.Nil(t, <-errA)
+
+	// A sequenced batch which can't be admitted releases its latches while
+	// waiting for a slot.
+	_, admitted := q.TryAdmit(admission.WorkInfo{})
+	require.True(t, admitted)
+	errA = put(keyA)
+	testutils.SucceedsSoon(t, func() error {
+		if n := q.Metrics().WaitQueueLength.Value(); n != 1 {
+			return errors.Errorf("expected 1 waiting batch, found %d", n)
+		}
+		return nil
+	})
+	require.Equal(t, int64(0), writeLatches())
+	q.AdmittedWorkDone()
+	require.Nil(t, <-errA)
+
+	// The slots have all been released.
+	_, admitted = q.TryAdmit(admission.WorkInfo{})
+	require.True(t, admitted)
+	q.AdmittedWorkDone()
+}
+
+// TestKVAdmissionInfo verifies the admission control information of batches.
+func TestKVAdmissionInfo(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	userKey := keys.UserTableDataMin.Next()
+	txn := roachpb.MakeTransaction("test", userKey, roachpb.NormalUserPriority,
+		hlc.Timestamp{WallTime: 10}, 0)
+	testCases := []struct {
+		name     string
+		h        roachpb.Header
+		req      roachpb.Request
+		expected admission.WorkInfo
+	}{
+		{"non-transactional", roachpb.Header{UserPriority: roachpb.MinUserPriority},
+			&roachpb.GetRequest{RequestHeader: roachpb.RequestHeader{Key: userKey}},
+			admission.WorkInfo{Priority: admission.LowPri, CreateTime: 100}},
+		{"transactional", roachpb.Header{Txn: &txn},
+			&roachpb.GetRequest{RequestHeader: roachpb.RequestHeader{Key: userKey}},
+			admission.WorkInfo{Priority: admission.NormalPri, CreateTime: 10}},
+		{"system key", roachpb.Header{},
+			&roachpb.GetRequest{RequestHeader: roachpb.RequestHeader{Key: keys.NodeLivenessKey(1)}},
+			admission.WorkInfo{CreateTime: 100, BypassAdmission: true}},
+		{"intent resolution", roachpb.Header{},
+			&roachpb.ResolveIntentRequest{RequestHeader: roachpb.RequestHeader{Key: userKey}},
+			admission.WorkInfo{CreateTime: 100, BypassAdmission: true}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ba := roachpb.BatchRequest{Header: tc.h}
+			ba.Add(tc.req)
+			require.Equal(t, tc.expected, kvAdmissionInfo(&ba, 100))
+		})
+	}
+}
+
 // TestReplicaLatchingTimestampNonInterference verifies that
 // reads with earlier timestamps do not interfere with writes.
 func TestReplicaLatchingTimestampNonInterference(t *testing.T) {
@@ -12391,6 +12498,38 @@ func TestPrepareChangeReplicasTrigger(t *testing.T) {
 	}
 }
 
+// TestReplicaRecordIntentContention verifies that a batch which ran into
+// intents records a contention event on the key of each intent, skipping the
+// intents of its own transaction.
+func TestReplicaRecordIntentContention(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	tc := testContext{}
+	stopper := stop.NewStopper()
+	defer stopper.Stop(context.TODO())
+	tc.Start(t, stopper)
+
+	waiting := newTransaction("waiting", roachpb.Key("a"), 1, tc.Clock())
+	blocking := newTransaction("blocking", roachpb.Key("b"), 1, tc.Clock())
+	var ba roachpb.BatchRequest
+	ba.Txn = waiting
+	intents := []roachpb.Intent{
+		{Span: roachpb.Span{Key: roachpb.Key("b")}, Txn: blocking.TxnMeta},
+		{Span: roachpb.Span{Key: roachpb.Key("c")}, Txn: waiting.TxnMeta},
+	}
+	registry := tc.store.GetContentionRegistry()
+	before := len(registry.Events())
+	tc.repl.recordIntentContention(&ba, intents, time.Second)
+
+	events := registry.Events()[before:]
+	require.Len(t, events, 1)
+	e := events[0]
+	assert.Equal(t, roachpb.Key("b"), e.Key)
+	assert.Equal(t, blocking.ID, e.BlockingTxnID)
+	assert.Equal(t, waiting.ID, e.WaitingTxnID)
+	assert.Equal(t, time.Second, e.Duration)
+	assert.Equal(t, contention.SourceTxnWaitQueue, e.Source)
+}
+
 func enableTraceDebugUseAfterFree() (restore func()) {
 	prev := trace.DebugUseAfterFinish
 	trace.DebugUseAfterFinish = true
@@ -394,7 +394,9 @@ func (r *Replica) evaluateWriteBatchWithLocalRetries(
 			batch = spanset.NewBatch(batch, spans)
 		}
 
+		evalStart := timeutil.Now()
 		br, res, pErr = evaluateBatch(ctx, idKey, batch, rec, ms, ba, false /* readOnly */)
+		r.evalTimeStats.recordCount(float64(timeutil.Since(evalStart)), 0 /* nodeID */)
 		// If we can retry, set a higher batch timestamp and continue.
 		if wtoErr, ok := pErr.GetDetail().(*roachpb.WriteTooOldError); ok && canRetry {
 			// Allow one retry only; a non-txn batch containing overlapping
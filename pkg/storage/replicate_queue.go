@@ -411,6 +411,10 @@ func (rq *replicateQueue) processOneChange(
 		// Requeue because either we failed to transition out of a joint state
 		// (bad) or we did and there might be more to do for that range.
 		return true, err
+	case AllocatorAddNonVoter:
+		return rq.addNonVoter(ctx, repl, dryRun)
+	case AllocatorRemoveNonVoter:
+		return rq.removeNonVoter(ctx, repl, dryRun)
 	default:
 		return false, errors.Errorf("unknown allocator action %v", action)
 	}
@@ -472,11 +476,12 @@ func (rq *replicateQueue) addOrReplace(
 	// there is a reason we're removing it (i.e. dead or decommissioning). If we
 	// left the replica in the slice, the allocator would not be guaranteed to
 	// pick a replica that fills the gap removeRepl leaves once it's gone.
-	newStore, details, err := rq.allocator.AllocateTarget(
+	newStore, details, err := rq.allocator.AllocateVoter(
 		ctx,
 		zone,
 		desc.RangeID,
 		remainingLiveReplicas,
+		desc.Replicas().NonVoters(),
 	)
 	if err != nil {
 		return false, err
@@ -490,7 +495,7 @@ func (rq *replicateQueue) addOrReplace(
 	}
 
 	clusterNodes := rq.allocator.storePool.ClusterNodeCount()
-	need := GetNeededReplicas(*zone.NumReplicas, clusterNodes)
+	need := GetNeededReplicas(zone.GetNumVoters(), clusterNodes)
 
 	// Only up-replicate if there are suitable allocation targets such that,
 	// either the replication goal is met, or it is possible to get to the next
@@ -513,11 +518,12 @@ func (rq *replicateQueue) addOrReplace(
 			NodeID:  newStore.Node.NodeID,
 			StoreID: newStore.StoreID,
 		})
-		_, _, err := rq.allocator.AllocateTarget(
+		_, _, err := rq.allocator.AllocateVoter(
 			ctx,
 			zone,
 			desc.RangeID,
 			oldPlusNewReplicas,
+			desc.Replicas().NonVoters(),
 		)
 		if err != nil {
 			// It does not seem possible to go to the next odd replica state. Note
@@ -818,6 +824,110 @@ func (rq *replicateQueue) removeLearner(
 	return true, nil
 }
 
+// addNonVoter adds a non-voting replica to the range.
+func (rq *replicateQueue) addNonVoter(
+	ctx context.Context, repl *Replica, dryRun bool,
+) (requeue bool, _ error) {
+	desc, zone := repl.DescAndZone()
+	newStore, details, err := rq.allocator.AllocateNonVoter(
+		ctx, zone, desc.RangeID, desc.Replicas().Voters(), desc.Replicas().NonVoters())
+	if err != nil {
+		return false, err
+	}
+	rq.metrics.AddReplicaCount.Inc(1)
+	target := roachpb.ReplicationTarget{
+		NodeID:  newStore.Node.NodeID,
+		StoreID: newStore.StoreID,
+	}
+	log.VEventf(ctx, 1, "adding non-voter %+v: %s",
+		target, rangeRaftProgress(repl.RaftStatus(), desc.Replicas().All()))
+	if err := rq.changeReplicas(
+		ctx,
+		repl,
+		roachpb.MakeReplicationChanges(roachpb.ADD_NON_VOTER, target),
+		desc,
+		SnapshotRequest_RECOVERY,
+		storagepb.ReasonRangeUnderReplicated,
+		details,
+		dryRun,
+	); err != nil {
+		return false, err
+	}
+	// Always requeue to see if more work needs to be done.
+	return true, nil
+}
+
+// removeNonVoter removes a non-voting replica from the range, preferring dead
+// and decommissioning ones.
+func (rq *replicateQueue) removeNonVoter(
+	ctx context.Context, repl *Replica, dryRun bool,
+) (requeue bool, _ error) {
+	desc, zone := repl.DescAndZone()
+	nonVoterReplicas := desc.Replicas().NonVoters()
+	if len(nonVoterReplicas) == 0 {
+		log.VEventf(ctx, 1, "range of replica %s was identified as having excess non-voters, "+
+			"but no non-voters were found", repl)
+		return true, nil
+	}
+	var removeReplica roachpb.ReplicaDescriptor
+	var details string
+	reason := storagepb.ReasonRangeOverReplicated
+	_, deadNonVoters := rq.allocator.storePool.liveAndDeadReplicas(desc.RangeID, nonVoterReplicas)
+	decommissioningNonVoters := rq.allocator.storePool.decommissioningReplicas(
+		desc.RangeID, nonVoterReplicas)
+	switch {
+	case len(deadNonVoters) > 0:
+		removeReplica, reason = deadNonVoters[0], storagepb.ReasonStoreDead
+	case len(decommissioningNonVoters) > 0:
+		removeReplica, reason = decommissioningNonVoters[0], storagepb.ReasonStoreDecommissioning
+	default:
+		var err error
+		removeReplica, details, err = rq.allocator.RemoveTarget(
+			ctx, zone, nonVoterReplicas, nonVoterReplicas)
+		if err != nil {
+			return false, err
+		}
+	}
+	rq.metrics.RemoveReplicaCount.Inc(1)
+	log.VEventf(ctx, 1, "removing non-voter %+v: %s",
+		removeReplica, rangeRaftProgress(repl.RaftStatus(), desc.Replicas().All()))
+	target := roachpb.ReplicationTarget{
+		NodeID:  removeReplica.NodeID,
+		StoreID: removeReplica.StoreID,
+	}
+	if err := rq.changeReplicas(
+		ctx,
+		repl,
+		roachpb.MakeReplicationChanges(roachpb.REMOVE_NON_VOTER, target),
+		desc,
+		SnapshotRequest_UNKNOWN, // unused
+		reason,
+		details,
+		dryRun,
+	); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// isValidVoterTarget returns whether the given target can receive a voting
+// replica, that is, whether it satisfies the zone's voter constraints and
+// doesn't already hold a non-voting replica of the range.
+func (rq *replicateQueue) isValidVoterTarget(
+	desc *roachpb.RangeDescriptor, zone *config.ZoneConfig, target roachpb.ReplicationTarget,
+) bool {
+	for _, rDesc := range desc.Replicas().NonVoters() {
+		if rDesc.NodeID == target.NodeID {
+			return false
+		}
+	}
+	if len(zone.VoterConstraints) == 0 {
+		return true
+	}
+	store, ok := rq.allocator.storePool.getStoreDescriptor(target.StoreID)
+	return ok && constraintsCheck(store, zone.VoterConstraints)
+}
+
 func (rq *replicateQueue) considerRebalance(
 	ctx context.Context,
 	repl *Replica,
@@ -835,6 +945,8 @@ func (rq *replicateQueue) considerRebalance(
 			storeFilterThrottled)
 		if !ok {
 			log.VEventf(ctx, 1, "no suitable rebalance target")
+		} else if !rq.isValidVoterTarget(desc, zone, addTarget) {
+			log.VEventf(ctx, 1, "rebalance target %s is not suitable for a voter", addTarget)
 		} else if done, err := rq.maybeTransferLeaseAway(ctx, repl, removeTarget.StoreID, dryRun); err != nil {
 			log.VEventf(ctx, 1, "want to remove self, but failed to transfer lease away: %s", err)
 		} else if done {
@@ -315,6 +315,56 @@ func TestReplicateQueueDownReplicate(t *testing.T) {
 	}
 }
 
+// TestReplicateQueueNonVoters verifies that the replicate queue adds and
+// removes non-voting replicas according to num_replicas and num_voters.
+func TestReplicateQueueNonVoters(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	tc := testcluster.StartTestCluster(t, 5,
+		base.TestClusterArgs{
+			ReplicationMode: base.ReplicationAuto,
+			ServerArgs: base.TestServerArgs{
+				ScanMinIdleTime: 10 * time.Millisecond,
+				ScanMaxIdleTime: 10 * time.Millisecond,
+			},
+		},
+	)
+	defer tc.Stopper().Stop(context.Background())
+
+	db := sqlutils.MakeSQLRunner(tc.Conns[0])
+	db.Exec(t, `CREATE TABLE t (k INT PRIMARY KEY)`)
+	var tableID uint32
+	db.QueryRow(t, `SELECT id FROM system.namespace WHERE name = 't'`).Scan(&tableID)
+	tableKey := roachpb.Key(keys.MakeTablePrefix(tableID))
+
+	waitForReplicas := func(expVoters, expNonVoters int) {
+		testutils.SucceedsSoon(t, func() error {
+			desc, err := tc.LookupRange(tableKey)
+			if err != nil {
+				return err
+			}
+			if !desc.StartKey.Equal(tableKey) {
+				return errors.Errorf("table not split off yet: %s", desc)
+			}
+			voters, nonVoters := desc.Replicas().Voters(), desc.Replicas().NonVoters()
+			if len(voters) != expVoters || len(nonVoters) != expNonVoters {
+				return errors.Errorf("expected %d voters and %d non-voters, found %s",
+					expVoters, expNonVoters, desc.Replicas())
+			}
+			return nil
+		})
+	}
+
+	db.Exec(t, `ALTER TABLE t CONFIGURE ZONE USING num_replicas = 5, num_voters = 3`)
+	waitForReplicas(3, 2)
+
+	db.Exec(t, `ALTER TABLE t CONFIGURE ZONE USING num_replicas = 4, num_voters = 3`)
+	waitForReplicas(3, 1)
+
+	db.Exec(t, `ALTER TABLE t CONFIGURE ZONE DISCARD`)
+	waitForReplicas(3, 0)
+}
+
 // queryRangeLog queries the range log. The query must be of type:
 // `SELECT info from system.rangelog ...`.
 func queryRangeLog(
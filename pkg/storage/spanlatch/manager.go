@@ -13,10 +13,12 @@ package spanlatch
 import (
 	"context"
 	"fmt"
+	"time"
 	"unsafe"
 
 	"github.com/cockroachdb/cockroach/pkg/base"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/storage/contention"
 	"github.com/cockroachdb/cockroach/pkg/storage/spanset"
 	"github.com/cockroachdb/cockroach/pkg/storage/storagepb"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
@@ -25,6 +27,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/stop"
 	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
 )
 
 // A Manager maintains an interval tree of key and key range latches. Latch
@@ -60,8 +63,9 @@ type Manager struct {
 	idAlloc uint64
 	scopes  [spanset.NumSpanScope]scopedManager
 
-	stopper  *stop.Stopper
-	slowReqs *metric.Gauge
+	stopper    *stop.Stopper
+	slowReqs   *metric.Gauge
+	contention *contention.Registry
 }
 
 // scopedManager is a latch manager scoped to either local or global keys.
@@ -72,11 +76,16 @@ type scopedManager struct {
 }
 
 // Make returns an initialized Manager. Using this constructor is optional as
-// the type's zero value is valid to use directly.
-func Make(stopper *stop.Stopper, slowReqs *metric.Gauge) Manager {
+// the type's zero value is valid to use directly. If registry is not nil, an
+// event is recorded in it whenever a latch acquisition waits on a latch held
+// by a transaction.
+func Make(
+	stopper *stop.Stopper, slowReqs *metric.Gauge, registry *contention.Registry,
+) Manager {
 	return Manager{
-		stopper:  stopper,
-		slowReqs: slowReqs,
+		stopper:    stopper,
+		slowReqs:   slowReqs,
+		contention: registry,
 	}
 }
 
@@ -86,6 +95,7 @@ type latch struct {
 	id         uint64
 	span       roachpb.Span
 	ts         hlc.Timestamp
+	txnID      uuid.UUID
 	done       *signal
 	next, prev *latch // readSet linked-list.
 }
@@ -102,11 +112,20 @@ func (la *latch) inReadSet() bool {
 // Manager.Acquire and accepted by Manager.Release.
 type Guard struct {
 	done signal
+	// contentionTime is the total amount of time spent waiting on
+	// conflicting latches during acquisition.
+	contentionTime time.Duration
 	// latches [spanset.NumSpanScope][spanset.NumSpanAccess][]latch, but half the size.
 	latchesPtrs [spanset.NumSpanScope][spanset.NumSpanAccess]unsafe.Pointer
 	latchesLens [spanset.NumSpanScope][spanset.NumSpanAccess]int32
 }
 
+// ContentionTime returns the amount of time that the latch acquisition spent
+// waiting on conflicting latches.
+func (lg *Guard) ContentionTime() time.Duration {
+	return lg.contentionTime
+}
+
 func (lg *Guard) latches(s spanset.SpanScope, a spanset.SpanAccess) []latch {
 	len := lg.latchesLens[s][a]
 	if len == 0 {
@@ -155,7 +174,7 @@ func allocGuardAndLatches(nLatches int) (*Guard, []latch) {
 	return new(Guard), make([]latch, nLatches)
 }
 
-func newGuard(spans *spanset.SpanSet) *Guard {
+func newGuard(spans *spanset.SpanSet, txnID uuid.UUID) *Guard {
 	nLatches := spans.Len()
 	guard, latches := allocGuardAndLatches(nLatches)
 	for s := spanset.SpanScope(0); s < spanset.NumSpanScope; s++ {
@@ -172,6 +191,7 @@ func newGuard(spans *spanset.SpanSet) *Guard {
 				latch.span = ss[i].Span
 				latch.done = &guard.done
 				latch.ts = ss[i].Timestamp
+				latch.txnID = txnID
 				// latch.setID() in Manager.insert, under lock.
 			}
 			guard.setLatches(s, a, ssLatches)
@@ -191,9 +211,14 @@ func newGuard(spans *spanset.SpanSet) *Guard {
 // be released, it stops waiting and releases all latches that it has already
 // acquired.
 //
+// The txnID identifies the transaction on whose behalf the latches are
+// acquired, if any. It is used to attribute contention between transactions.
+//
 // It returns a Guard which must be provided to Release.
-func (m *Manager) Acquire(ctx context.Context, spans *spanset.SpanSet) (*Guard, error) {
-	lg, snap := m.sequence(spans)
+func (m *Manager) Acquire(
+	ctx context.Context, spans *spanset.SpanSet, txnID uuid.UUID,
+) (*Guard, error) {
+	lg, snap := m.sequence(spans, txnID)
 	defer snap.close()
 
 	err := m.wait(ctx, lg, snap)
@@ -208,8 +233,8 @@ func (m *Manager) Acquire(ctx context.Context, spans *spanset.SpanSet) (*Guard,
 // for each of the specified spans into the manager's interval trees, and
 // unlocks the manager. The role of the method is to sequence latch acquisition
 // attempts.
-func (m *Manager) sequence(spans *spanset.SpanSet) (*Guard, snapshot) {
-	lg := newGuard(spans)
+func (m *Manager) sequence(spans *spanset.SpanSet, txnID uuid.UUID) (*Guard, snapshot) {
+	lg := newGuard(spans, txnID)
 
 	m.mu.Lock()
 	snap := m.snapshotLocked(spans)
@@ -326,7 +351,7 @@ func (m *Manager) wait(ctx context.Context, lg *Guard, snap snapshot) error {
 				case spanset.SpanReadOnly:
 					// Wait for writes at equal or lower timestamps.
 					it := tr[spanset.SpanReadWrite].MakeIter()
-					if err := m.iterAndWait(ctx, timer, &it, latch, ignoreLater); err != nil {
+					if err := m.iterAndWait(ctx, timer, lg, &it, latch, ignoreLater); err != nil {
 						return err
 					}
 				case spanset.SpanReadWrite:
@@ -337,12 +362,12 @@ func (m *Manager) wait(ctx context.Context, lg *Guard, snap snapshot) error {
 					// latches first. We expect writes to take longer than reads
 					// to release their latches, so we wait on them first.
 					it := tr[spanset.SpanReadWrite].MakeIter()
-					if err := m.iterAndWait(ctx, timer, &it, latch, ignoreNothing); err != nil {
+					if err := m.iterAndWait(ctx, timer, lg, &it, latch, ignoreNothing); err != nil {
 						return err
 					}
 					// Wait for reads at equal or higher timestamps.
 					it = tr[spanset.SpanReadOnly].MakeIter()
-					if err := m.iterAndWait(ctx, timer, &it, latch, ignoreEarlier); err != nil {
+					if err := m.iterAndWait(ctx, timer, lg, &it, latch, ignoreEarlier); err != nil {
 						return err
 					}
 				default:
@@ -358,7 +383,12 @@ func (m *Manager) wait(ctx context.Context, lg *Guard, snap snapshot) error {
 // with the search latch and which should not be ignored given their timestamp
 // and the supplied ignoreFn.
 func (m *Manager) iterAndWait(
-	ctx context.Context, t *timeutil.Timer, it *iterator, wait *latch, ignore ignoreFn,
+	ctx context.Context,
+	t *timeutil.Timer,
+	lg *Guard,
+	it *iterator,
+	wait *latch,
+	ignore ignoreFn,
 ) error {
 	for it.FirstOverlap(wait); it.Valid(); it.NextOverlap() {
 		held := it.Cur()
@@ -368,9 +398,11 @@ func (m *Manager) iterAndWait(
 		if ignore(wait.ts, held.ts) {
 			continue
 		}
+		start := timeutil.Now()
 		if err := m.waitForSignal(ctx, t, wait, held); err != nil {
 			return err
 		}
+		m.recordContention(lg, wait, held, start)
 	}
 	return nil
 }
@@ -402,6 +434,32 @@ func (m *Manager) waitForSignal(ctx context.Context, t *timeutil.Timer, wait, he
 	}
 }
 
+// recordContention accounts for the time that the latch acquisition spent
+// waiting, since start, on the held latch. If the held latch belongs to a
+// transaction, a contention event is recorded as well.
+func (m *Manager) recordContention(lg *Guard, wait, held *latch, start time.Time) {
+	// The concurrent requests of a transaction, like its pipelined writes,
+	// may wait on each other's latches, but the transaction doesn't contend
+	// with itself.
+	if held.txnID != (uuid.UUID{}) && held.txnID == wait.txnID {
+		return
+	}
+	now := timeutil.Now()
+	dur := now.Sub(start)
+	lg.contentionTime += dur
+	if held.txnID == (uuid.UUID{}) {
+		return
+	}
+	m.contention.Add(contention.Event{
+		Key:           held.span.Key,
+		BlockingTxnID: held.txnID,
+		WaitingTxnID:  wait.txnID,
+		Time:          now,
+		Duration:      dur,
+		Source:        contention.SourceLatch,
+	})
+}
+
 // Release releases the latches held by the provided Guard. After being called,
 // dependent latch acquisition attempts can complete if not blocked on any other
 // owned latches.
@@ -21,10 +21,13 @@ import (
 
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/storage/contention"
 	"github.com/cockroachdb/cockroach/pkg/storage/spanset"
 	"github.com/cockroachdb/cockroach/pkg/testutils"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
 	"github.com/stretchr/testify/require"
 )
 
@@ -91,7 +94,7 @@ func testLatchBlocks(t *testing.T, lgC <-chan *Guard) {
 // MustAcquire is like Acquire, except it can't return context cancellation
 // errors.
 func (m *Manager) MustAcquire(spans *spanset.SpanSet) *Guard {
-	lg, err := m.Acquire(context.Background(), spans)
+	lg, err := m.Acquire(context.Background(), spans, uuid.UUID{})
 	if err != nil {
 		panic(err)
 	}
@@ -110,7 +113,7 @@ func (m *Manager) MustAcquireCh(spans *spanset.SpanSet) <-chan *Guard {
 // MustAcquireChCtx is like MustAcquireCh, except it accepts a context.
 func (m *Manager) MustAcquireChCtx(ctx context.Context, spans *spanset.SpanSet) <-chan *Guard {
 	ch := make(chan *Guard)
-	lg, snap := m.sequence(spans)
+	lg, snap := m.sequence(spans, uuid.UUID{})
 	go func() {
 		err := m.wait(ctx, lg, snap)
 		if err != nil {
@@ -509,6 +512,67 @@ func TestLatchManagerDependentLatches(t *testing.T) {
 	}
 }
 
+func TestLatchManagerContention(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	ctx := context.Background()
+	stopper := stop.NewStopper()
+	defer stopper.Stop(ctx)
+	registry := contention.NewRegistry()
+	m := Make(stopper, nil /* slowReqs */, registry)
+
+	txn1, txn2 := uuid.MakeV4(), uuid.MakeV4()
+	lg1, err := m.Acquire(ctx, spans("a", "", write, zeroTS), txn1)
+	require.NoError(t, err)
+	require.Zero(t, lg1.ContentionTime())
+
+	lg2C := make(chan *Guard)
+	go func() {
+		lg2, err := m.Acquire(ctx, spans("a", "", write, zeroTS), txn2)
+		require.NoError(t, err)
+		lg2C <- lg2
+	}()
+	testLatchBlocks(t, lg2C)
+	require.Empty(t, registry.Events())
+
+	m.Release(lg1)
+	lg2 := testLatchSucceeds(t, lg2C)
+	require.True(t, lg2.ContentionTime() > 0)
+
+	events := registry.Events()
+	require.Len(t, events, 1)
+	require.Equal(t, roachpb.Key("a"), events[0].Key)
+	require.Equal(t, txn1, events[0].BlockingTxnID)
+	require.Equal(t, txn2, events[0].WaitingTxnID)
+	require.Equal(t, contention.SourceLatch, events[0].Source)
+	require.Equal(t, lg2.ContentionTime(), events[0].Duration)
+	m.Release(lg2)
+
+	// Waiting on a non-transactional request does not record an event.
+	lg3 := m.MustAcquire(spans("b", "", write, zeroTS))
+	lg4C := m.MustAcquireCh(spans("b", "", write, zeroTS))
+	testLatchBlocks(t, lg4C)
+	m.Release(lg3)
+	m.Release(testLatchSucceeds(t, lg4C))
+	require.Len(t, registry.Events(), 1)
+
+	// Nor does waiting on a request of the same transaction, which doesn't
+	// count as contention either.
+	lg5, err := m.Acquire(ctx, spans("c", "", write, zeroTS), txn1)
+	require.NoError(t, err)
+	lg6C := make(chan *Guard)
+	go func() {
+		lg6, err := m.Acquire(ctx, spans("c", "", write, zeroTS), txn1)
+		require.NoError(t, err)
+		lg6C <- lg6
+	}()
+	testLatchBlocks(t, lg6C)
+	m.Release(lg5)
+	lg6 := testLatchSucceeds(t, lg6C)
+	require.Zero(t, lg6.ContentionTime())
+	m.Release(lg6)
+	require.Len(t, registry.Events(), 1)
+}
+
 func TestLatchManagerContextCancellation(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	var m Manager
@@ -577,7 +641,7 @@ func BenchmarkLatchManagerReadWriteMix(b *testing.B) {
 
 			b.ResetTimer()
 			for i := range spans {
-				lg, snap := m.sequence(&spans[i])
+				lg, snap := m.sequence(&spans[i], uuid.UUID{})
 				snap.close()
 				if len(lgBuf) == cap(lgBuf) {
 					m.Release(<-lgBuf)
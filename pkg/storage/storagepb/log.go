@@ -23,4 +23,6 @@ const (
 	ReasonRebalance            RangeLogEventReason = "rebalance"
 	ReasonAdminRequest         RangeLogEventReason = "admin request"
 	ReasonAbandonedLearner     RangeLogEventReason = "abandoned learner replica"
+	ReasonConsistencyRepair    RangeLogEventReason = "consistency repair"
+	ReasonRangeMerge           RangeLogEventReason = "range merge"
 )
@@ -42,14 +42,17 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/storage/closedts/ctpb"
 	"github.com/cockroachdb/cockroach/pkg/storage/cloud"
 	"github.com/cockroachdb/cockroach/pkg/storage/compactor"
+	"github.com/cockroachdb/cockroach/pkg/storage/contention"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
 	"github.com/cockroachdb/cockroach/pkg/storage/idalloc"
 	"github.com/cockroachdb/cockroach/pkg/storage/intentresolver"
+	"github.com/cockroachdb/cockroach/pkg/storage/protectedts"
 	"github.com/cockroachdb/cockroach/pkg/storage/raftentry"
 	"github.com/cockroachdb/cockroach/pkg/storage/tscache"
 	"github.com/cockroachdb/cockroach/pkg/storage/txnrecovery"
 	"github.com/cockroachdb/cockroach/pkg/storage/txnwait"
+	"github.com/cockroachdb/cockroach/pkg/util/admission"
 	"github.com/cockroachdb/cockroach/pkg/util/contextutil"
 	"github.com/cockroachdb/cockroach/pkg/util/envutil"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
@@ -154,6 +157,7 @@ func TestStoreConfig(clock *hlc.Clock) StoreConfig {
 		Settings:                    st,
 		AmbientCtx:                  log.AmbientContext{Tracer: st.Tracer},
 		Clock:                       clock,
+		ProtectedTimestampTracker:   protectedts.ClockTracker(clock),
 		CoalescedHeartbeatsInterval: 50 * time.Millisecond,
 		RaftHeartbeatIntervalTicks:  1,
 		ScanInterval:                10 * time.Minute,
@@ -161,6 +165,7 @@ func TestStoreConfig(clock *hlc.Clock) StoreConfig {
 		HistogramWindowInterval:     metric.TestSampleInterval,
 		EnableEpochRangeLeases:      true,
 		ClosedTimestamp:             container.NoopContainer(),
+		ContentionRegistry:          contention.NewRegistry(),
 	}
 
 	// Use shorter Raft tick settings in order to minimize start up and failover
@@ -610,9 +615,24 @@ type StoreConfig struct {
 
 	ClosedTimestamp *container.Container
 
+	// KVAdmissionQ, if set, is the admission queue that requests sent to the
+	// store wait in while the node is overloaded, once they are sequenced. It
+	// is shared by all the stores on a node.
+	KVAdmissionQ *admission.WorkQueue
+
+	// ContentionRegistry, if set, records the events in which requests on
+	// this store block on conflicting transactions. It is shared by all the
+	// stores on a node.
+	ContentionRegistry *contention.Registry
+
 	// SQLExecutor is used by the store to execute SQL statements.
 	SQLExecutor sqlutil.InternalExecutor
 
+	// ProtectedTimestampTracker is used by the GC queue to avoid collecting
+	// data which is protected by a protected timestamp record. If nil, no data
+	// is considered protected.
+	ProtectedTimestampTracker protectedts.Tracker
+
 	// TimeSeriesDataStore is an interface used by the store's time series
 	// maintenance queue to dispatch individual maintenance tasks.
 	TimeSeriesDataStore TimeSeriesDataStore
@@ -2077,6 +2097,8 @@ func (s *Store) Capacity(useCached bool) (roachpb.StoreCapacity, error) {
 	var logicalBytes int64
 	var totalQueriesPerSecond float64
 	var totalWritesPerSecond float64
+	var totalCPUPerSecond float64
+	var totalWriteBytesPerSecond float64
 	replicaCount := s.metrics.ReplicaCount.Value()
 	bytesPerReplica := make([]float64, 0, replicaCount)
 	writesPerReplica := make([]float64, 0, replicaCount)
@@ -2103,9 +2125,20 @@ func (s *Store) Capacity(useCached bool) (roachpb.StoreCapacity, error) {
 			totalWritesPerSecond += wps
 			writesPerReplica = append(writesPerReplica, wps)
 		}
+		var cpu, writeBytes float64
+		if avgCPU, dur := r.evalTimeStats.avgQPS(); dur >= MinStatsDuration {
+			cpu = avgCPU
+			totalCPUPerSecond += avgCPU
+		}
+		if bps, dur := r.writeBytesStats.avgQPS(); dur >= MinStatsDuration {
+			writeBytes = bps
+			totalWriteBytesPerSecond += bps
+		}
 		rankingsAccumulator.addReplica(replicaWithStats{
-			repl: r,
-			qps:  qps,
+			repl:       r,
+			qps:        qps,
+			cpu:        cpu,
+			writeBytes: writeBytes,
 		})
 		return true
 	})
@@ -2114,6 +2147,8 @@ func (s *Store) Capacity(useCached bool) (roachpb.StoreCapacity, error) {
 	capacity.LogicalBytes = logicalBytes
 	capacity.QueriesPerSecond = totalQueriesPerSecond
 	capacity.WritesPerSecond = totalWritesPerSecond
+	capacity.CPUPerSecond = totalCPUPerSecond
+	capacity.WriteBytesPerSecond = totalWriteBytesPerSecond
 	capacity.BytesPerReplica = roachpb.PercentilesFromData(bytesPerReplica)
 	capacity.WritesPerReplica = roachpb.PercentilesFromData(writesPerReplica)
 	s.recordNewPerSecondStats(totalQueriesPerSecond, totalWritesPerSecond)
@@ -2212,6 +2247,8 @@ func (s *Store) updateReplicationGauges(ctx context.Context) error {
 		quiescentCount                int64
 		averageQueriesPerSecond       float64
 		averageWritesPerSecond        float64
+		averageCPUPerSecond           float64
+		averageWriteBytesPerSecond    float64
 
 		rangeCount                int64
 		unavailableRangeCount     int64
@@ -2268,6 +2305,12 @@ func (s *Store) updateReplicationGauges(ctx context.Context) error {
 		if wps, dur := rep.writeStats.avgQPS(); dur >= MinStatsDuration {
 			averageWritesPerSecond += wps
 		}
+		if cpu, dur := rep.evalTimeStats.avgQPS(); dur >= MinStatsDuration {
+			averageCPUPerSecond += cpu
+		}
+		if bps, dur := rep.writeBytesStats.avgQPS(); dur >= MinStatsDuration {
+			averageWriteBytesPerSecond += bps
+		}
 		if mc := rep.maxClosed(ctx); minMaxClosedTS.IsEmpty() || mc.Less(minMaxClosedTS) {
 			minMaxClosedTS = mc
 		}
@@ -2282,6 +2325,8 @@ func (s *Store) updateReplicationGauges(ctx context.Context) error {
 	s.metrics.QuiescentCount.Update(quiescentCount)
 	s.metrics.AverageQueriesPerSecond.Update(averageQueriesPerSecond)
 	s.metrics.AverageWritesPerSecond.Update(averageWritesPerSecond)
+	s.metrics.AverageCPUNanosPerSecond.Update(averageCPUPerSecond)
+	s.metrics.AverageWriteBytesPerSecond.Update(averageWriteBytesPerSecond)
 	s.recordNewPerSecondStats(averageQueriesPerSecond, averageWritesPerSecond)
 
 	s.metrics.RangeCount.Update(rangeCount)
@@ -2523,6 +2568,12 @@ func (s *Store) GetTxnWaitMetrics() *txnwait.Metrics {
 	return s.txnWaitMetrics
 }
 
+// GetContentionRegistry returns the registry in which the contention events
+// observed on the store are recorded.
+func (s *Store) GetContentionRegistry() *contention.Registry {
+	return s.cfg.ContentionRegistry
+}
+
 func init() {
 	tracing.RegisterTagRemapping("s", "store")
 }
@@ -84,10 +84,19 @@ func (s *Store) MergeRange(
 		// logic that depends on them.
 		leftRepl.writeStats.resetRequestCounts()
 	}
+	if leftRepl.writeBytesStats != nil {
+		leftRepl.writeBytesStats.resetRequestCounts()
+	}
+	if leftRepl.evalTimeStats != nil {
+		leftRepl.evalTimeStats.resetRequestCounts()
+	}
 
 	// Clear the wait queue to redirect the queued transactions to the
 	// left-hand replica, if necessary.
 	rightRepl.txnWaitQueue.Clear(true /* disable */)
+	// Similarly, wake up the requests waiting in the RHS lock table so that
+	// they are redirected to the left-hand replica.
+	rightRepl.lockTable.Clear()
 
 	leftLease, _ := leftRepl.GetLease()
 	rightLease, _ := rightRepl.GetLease()
@@ -329,6 +329,8 @@ func (sp *StorePool) updateLocalStoreAfterRebalance(
 		detail.desc.Capacity.RangeCount++
 		detail.desc.Capacity.LogicalBytes += rangeUsageInfo.LogicalBytes
 		detail.desc.Capacity.WritesPerSecond += rangeUsageInfo.WritesPerSecond
+		detail.desc.Capacity.WriteBytesPerSecond += rangeUsageInfo.WriteBytesPerSecond
+		detail.desc.Capacity.CPUPerSecond += rangeUsageInfo.CPUPerSecond
 	case roachpb.REMOVE_REPLICA:
 		detail.desc.Capacity.RangeCount--
 		if detail.desc.Capacity.LogicalBytes <= rangeUsageInfo.LogicalBytes {
@@ -341,6 +343,16 @@ func (sp *StorePool) updateLocalStoreAfterRebalance(
 		} else {
 			detail.desc.Capacity.WritesPerSecond -= rangeUsageInfo.WritesPerSecond
 		}
+		if detail.desc.Capacity.WriteBytesPerSecond <= rangeUsageInfo.WriteBytesPerSecond {
+			detail.desc.Capacity.WriteBytesPerSecond = 0
+		} else {
+			detail.desc.Capacity.WriteBytesPerSecond -= rangeUsageInfo.WriteBytesPerSecond
+		}
+		if detail.desc.Capacity.CPUPerSecond <= rangeUsageInfo.CPUPerSecond {
+			detail.desc.Capacity.CPUPerSecond = 0
+		} else {
+			detail.desc.Capacity.CPUPerSecond -= rangeUsageInfo.CPUPerSecond
+		}
 	}
 	sp.detailsMu.storeDetails[storeID] = &detail
 }
@@ -523,6 +535,10 @@ type StoreList struct {
 	// candidateWritesPerSecond tracks writes-per-second stats for stores that are
 	// eligible to be rebalance targets.
 	candidateWritesPerSecond stat
+
+	// candidateCPU tracks the load along the LBRebalancingCPU dimension of
+	// stores that are eligible to be rebalance targets.
+	candidateCPU stat
 }
 
 // Generates a new store list based on the passed in descriptors. It will
@@ -537,6 +553,7 @@ func makeStoreList(descriptors []roachpb.StoreDescriptor) StoreList {
 		sl.candidateLogicalBytes.update(float64(desc.Capacity.LogicalBytes))
 		sl.candidateQueriesPerSecond.update(desc.Capacity.QueriesPerSecond)
 		sl.candidateWritesPerSecond.update(desc.Capacity.WritesPerSecond)
+		sl.candidateCPU.update(LBRebalancingCPU.storeLoad(desc.Capacity))
 	}
 	return sl
 }
@@ -578,6 +595,28 @@ func (sl StoreList) filter(constraints []config.Constraints) StoreList {
 	return makeStoreList(filteredDescs)
 }
 
+// excludeNodesOf returns a new StoreList which doesn't contain any of the
+// stores on the nodes of the given replicas.
+func (sl StoreList) excludeNodesOf(replicas []roachpb.ReplicaDescriptor) StoreList {
+	if len(replicas) == 0 {
+		return sl
+	}
+	var filteredDescs []roachpb.StoreDescriptor
+	for _, store := range sl.stores {
+		excluded := false
+		for _, repl := range replicas {
+			if repl.NodeID == store.Node.NodeID {
+				excluded = true
+				break
+			}
+		}
+		if !excluded {
+			filteredDescs = append(filteredDescs, store)
+		}
+	}
+	return makeStoreList(filteredDescs)
+}
+
 type storeFilter int
 
 const (
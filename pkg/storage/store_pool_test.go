@@ -465,6 +465,7 @@ func TestStorePoolUpdateLocalStore(t *testing.T) {
 				LogicalBytes:     30,
 				QueriesPerSecond: 100,
 				WritesPerSecond:  30,
+				CPUPerSecond:     1e6,
 			},
 		},
 		{
@@ -478,6 +479,7 @@ func TestStorePoolUpdateLocalStore(t *testing.T) {
 				LogicalBytes:     25,
 				QueriesPerSecond: 50,
 				WritesPerSecond:  25,
+				CPUPerSecond:     1e6,
 			},
 		},
 	}
@@ -494,9 +496,13 @@ func TestStorePoolUpdateLocalStore(t *testing.T) {
 	for _, store := range stores {
 		rs.record(store.Node.NodeID)
 	}
+	evalTimeStats := newReplicaStats(clock, nil)
+	evalTimeStats.recordCount(float64(5*time.Millisecond), 0 /* nodeID */)
 	manual.Increment(int64(MinStatsDuration + time.Second))
 	replica.leaseholderStats = rs
 	replica.writeStats = rs
+	replica.writeBytesStats = newReplicaStats(clock, nil)
+	replica.evalTimeStats = evalTimeStats
 
 	rangeUsageInfo := rangeUsageInfoForRepl(replica)
 
@@ -507,6 +513,7 @@ func TestStorePoolUpdateLocalStore(t *testing.T) {
 	}
 	QPS, _ := replica.leaseholderStats.avgQPS()
 	WPS, _ := replica.writeStats.avgQPS()
+	CPU, _ := replica.evalTimeStats.avgQPS()
 	if expectedRangeCount := int32(6); desc.Capacity.RangeCount != expectedRangeCount {
 		t.Errorf("expected RangeCount %d, but got %d", expectedRangeCount, desc.Capacity.RangeCount)
 	}
@@ -519,6 +526,9 @@ func TestStorePoolUpdateLocalStore(t *testing.T) {
 	if expectedWPS := 30 + WPS; desc.Capacity.WritesPerSecond != expectedWPS {
 		t.Errorf("expected WritesPerSecond %f, but got %f", expectedWPS, desc.Capacity.WritesPerSecond)
 	}
+	if expectedCPU := 1e6 + CPU; desc.Capacity.CPUPerSecond != expectedCPU {
+		t.Errorf("expected CPUPerSecond %f, but got %f", expectedCPU, desc.Capacity.CPUPerSecond)
+	}
 
 	sp.updateLocalStoreAfterRebalance(roachpb.StoreID(2), rangeUsageInfo, roachpb.REMOVE_REPLICA)
 	desc, ok = sp.getStoreDescriptor(roachpb.StoreID(2))
@@ -537,6 +547,9 @@ func TestStorePoolUpdateLocalStore(t *testing.T) {
 	if expectedWPS := 25 - WPS; desc.Capacity.WritesPerSecond != expectedWPS {
 		t.Errorf("expected WritesPerSecond %f, but got %f", expectedWPS, desc.Capacity.WritesPerSecond)
 	}
+	if expectedCPU := 1e6 - CPU; desc.Capacity.CPUPerSecond != expectedCPU {
+		t.Errorf("expected CPUPerSecond %f, but got %f", expectedCPU, desc.Capacity.CPUPerSecond)
+	}
 
 	sp.updateLocalStoresAfterLeaseTransfer(roachpb.StoreID(1), roachpb.StoreID(2), rangeUsageInfo.QueriesPerSecond)
 	desc, ok = sp.getStoreDescriptor(roachpb.StoreID(1))
@@ -12,6 +12,7 @@ package storage
 
 import (
 	"context"
+	"fmt"
 	"math"
 	"math/rand"
 	"sort"
@@ -40,6 +41,18 @@ const (
 	// by less than this amount even if the amount is greater than the percentage
 	// threshold. This avoids too many lease transfers in lightly loaded clusters.
 	minQPSThresholdDifference = 100
+
+	// minCPUThresholdDifference is like minQPSThresholdDifference, but for the
+	// LBRebalancingCPU dimension. It is expressed in nanoseconds of CPU time per
+	// second, i.e. a tenth of a core.
+	minCPUThresholdDifference = float64(100 * time.Millisecond)
+
+	// writeByteCPUCost is the estimated CPU time, in nanoseconds, that writing
+	// a byte costs the stores holding replicas of a range on top of the cost of
+	// evaluating the write. It accounts for replicating, applying and
+	// eventually compacting the write, and is used to fold write bytes into the
+	// LBRebalancingCPU dimension.
+	writeByteCPUCost = 100
 )
 
 var (
@@ -55,18 +68,42 @@ var (
 		Measurement: "Range Rebalances",
 		Unit:        metric.Unit_COUNT,
 	}
+	metaStoreRebalancerDimension = metric.Metadata{
+		Name:        "rebalancing.dimension",
+		Help:        "Load dimension used by the store-level rebalancer in its last run (0 = QPS, 1 = CPU)",
+		Measurement: "Dimension",
+		Unit:        metric.Unit_CONST,
+	}
+	metaStoreRebalancerLocalLoad = metric.Metadata{
+		Name:        "rebalancing.load.local",
+		Help:        "Load of the store along the rebalancing dimension, as seen by the store-level rebalancer in its last run",
+		Measurement: "Load",
+		Unit:        metric.Unit_COUNT,
+	}
+	metaStoreRebalancerMeanLoad = metric.Metadata{
+		Name:        "rebalancing.load.mean",
+		Help:        "Mean load of the candidate stores along the rebalancing dimension, as seen by the store-level rebalancer in its last run",
+		Measurement: "Load",
+		Unit:        metric.Unit_COUNT,
+	}
 )
 
 // StoreRebalancerMetrics is the set of metrics for the store-level rebalancer.
 type StoreRebalancerMetrics struct {
 	LeaseTransferCount  *metric.Counter
 	RangeRebalanceCount *metric.Counter
+	Dimension           *metric.Gauge
+	LocalLoad           *metric.GaugeFloat64
+	MeanLoad            *metric.GaugeFloat64
 }
 
 func makeStoreRebalancerMetrics() StoreRebalancerMetrics {
 	return StoreRebalancerMetrics{
 		LeaseTransferCount:  metric.NewCounter(metaStoreRebalancerLeaseTransferCount),
 		RangeRebalanceCount: metric.NewCounter(metaStoreRebalancerRangeRebalanceCount),
+		Dimension:           metric.NewGauge(metaStoreRebalancerDimension),
+		LocalLoad:           metric.NewGaugeFloat64(metaStoreRebalancerLocalLoad),
+		MeanLoad:            metric.NewGaugeFloat64(metaStoreRebalancerMeanLoad),
 	}
 }
 
@@ -99,6 +136,30 @@ var qpsRebalanceThreshold = func() *settings.FloatSetting {
 	return s
 }()
 
+// LoadBasedRebalancingDimension controls which measure of load is balanced
+// across stores by load-based rebalancing.
+var LoadBasedRebalancingDimension = settings.RegisterPublicEnumSetting(
+	"kv.allocator.load_based_rebalancing_dimension",
+	"what measure of load to balance across stores when rebalancing based on load",
+	"qps",
+	map[int64]string{
+		int64(LBRebalancingQueries): "qps",
+		int64(LBRebalancingCPU):     "cpu",
+	},
+)
+
+// cpuRebalanceThreshold is like qpsRebalanceThreshold, but for the
+// LBRebalancingCPU dimension.
+var cpuRebalanceThreshold = func() *settings.FloatSetting {
+	s := settings.RegisterNonNegativeFloatSetting(
+		"kv.allocator.cpu_rebalance_threshold",
+		"minimum fraction away from the mean a store's CPU usage can be before it is considered overfull or underfull",
+		0.25,
+	)
+	s.SetVisibility(settings.Public)
+	return s
+}()
+
 // LBRebalancingMode controls if and when we do store-level rebalancing
 // based on load.
 type LBRebalancingMode int64
@@ -108,13 +169,113 @@ const (
 	// based on load statistics.
 	LBRebalancingOff LBRebalancingMode = iota
 	// LBRebalancingLeasesOnly means that we rebalance leases based on
-	// store-level QPS imbalances.
+	// store-level load imbalances.
 	LBRebalancingLeasesOnly
 	// LBRebalancingLeasesAndReplicas means that we rebalance both leases and
-	// replicas based on store-level QPS imbalances.
+	// replicas based on store-level load imbalances.
 	LBRebalancingLeasesAndReplicas
 )
 
+// LBRebalancingDimension is a measure of load that store-level rebalancing
+// tries to balance across stores.
+type LBRebalancingDimension int64
+
+const (
+	// LBRebalancingQueries balances the number of batch requests received per
+	// second by the leaseholders on each store.
+	LBRebalancingQueries LBRebalancingDimension = iota
+	// LBRebalancingCPU balances the CPU time spent by each store. This is a
+	// composite of the time spent evaluating requests and of the bytes
+	// written, which are converted to CPU time using writeByteCPUCost. Unlike
+	// QPS, it distinguishes cheap point reads from expensive scans and large
+	// writes.
+	LBRebalancingCPU
+)
+
+func (d LBRebalancingDimension) String() string {
+	switch d {
+	case LBRebalancingQueries:
+		return "qps"
+	case LBRebalancingCPU:
+		return "cpu-ns/s"
+	default:
+		return fmt.Sprintf("LBRebalancingDimension(%d)", int64(d))
+	}
+}
+
+// storeLoad returns the load of a store along the dimension.
+func (d LBRebalancingDimension) storeLoad(sc roachpb.StoreCapacity) float64 {
+	if d == LBRebalancingCPU {
+		return sc.CPUPerSecond + sc.WriteBytesPerSecond*writeByteCPUCost
+	}
+	return sc.QueriesPerSecond
+}
+
+// leaseLoad returns the part of a replica's load along the dimension that
+// moves along with its lease.
+func (d LBRebalancingDimension) leaseLoad(r replicaWithStats) float64 {
+	if d == LBRebalancingCPU {
+		return r.cpu
+	}
+	return r.qps
+}
+
+// replicaLoad returns the load that a leaseholder replica puts on its store
+// along the dimension.
+func (d LBRebalancingDimension) replicaLoad(r replicaWithStats) float64 {
+	if d == LBRebalancingCPU {
+		return r.cpu + r.writeBytes*writeByteCPUCost
+	}
+	return r.qps
+}
+
+// candidateLoad returns the load statistics of the candidate stores in the
+// list along the dimension.
+func (d LBRebalancingDimension) candidateLoad(sl StoreList) stat {
+	if d == LBRebalancingCPU {
+		return sl.candidateCPU
+	}
+	return sl.candidateQueriesPerSecond
+}
+
+// rebalanceThreshold returns the minimum fraction away from the mean a store's
+// load along the dimension can be before it is considered overfull or
+// underfull.
+func (d LBRebalancingDimension) rebalanceThreshold(sv *settings.Values) float64 {
+	if d == LBRebalancingCPU {
+		return cpuRebalanceThreshold.Get(sv)
+	}
+	return qpsRebalanceThreshold.Get(sv)
+}
+
+// rebalanceThresholds returns the thresholds below and above which a store's
+// load along the dimension is considered underfull and overfull.
+func (d LBRebalancingDimension) rebalanceThresholds(
+	sv *settings.Values, sl StoreList,
+) (min, max float64) {
+	fraction, minDifference := d.rebalanceThreshold(sv), float64(minQPSThresholdDifference)
+	if d == LBRebalancingCPU {
+		minDifference = minCPUThresholdDifference
+	}
+	mean := d.candidateLoad(sl).mean
+	min = math.Min(mean*(1-fraction), mean-minDifference)
+	max = math.Max(mean*(1+fraction), mean+minDifference)
+	return min, max
+}
+
+// moveLease updates the capacities of two stores to account for the transfer
+// of a replica's lease from one to the other.
+func moveLease(from, to *roachpb.StoreCapacity, r replicaWithStats) {
+	from.LeaseCount--
+	from.QueriesPerSecond -= r.qps
+	from.CPUPerSecond -= r.cpu
+	if to != nil {
+		to.LeaseCount++
+		to.QueriesPerSecond += r.qps
+		to.CPUPerSecond += r.cpu
+	}
+}
+
 // StoreRebalancer is responsible for examining how the associated store's load
 // compares to the load on other stores in the cluster and transferring leases
 // or replicas away if the local store is overloaded.
@@ -202,16 +363,19 @@ func (sr *StoreRebalancer) Start(ctx context.Context, stopper *stop.Stopper) {
 	})
 }
 
+// dimension returns the measure of load that the store rebalancer balances.
+func (sr *StoreRebalancer) dimension() LBRebalancingDimension {
+	return LBRebalancingDimension(LoadBasedRebalancingDimension.Get(&sr.st.SV))
+}
+
 func (sr *StoreRebalancer) rebalanceStore(
 	ctx context.Context, mode LBRebalancingMode, storeList StoreList,
 ) {
-	qpsThresholdFraction := qpsRebalanceThreshold.Get(&sr.st.SV)
+	dim := sr.dimension()
+	meanLoad := dim.candidateLoad(storeList).mean
 
-	// First check if we should transfer leases away to better balance QPS.
-	qpsMinThreshold := math.Min(storeList.candidateQueriesPerSecond.mean*(1-qpsThresholdFraction),
-		storeList.candidateQueriesPerSecond.mean-minQPSThresholdDifference)
-	qpsMaxThreshold := math.Max(storeList.candidateQueriesPerSecond.mean*(1+qpsThresholdFraction),
-		storeList.candidateQueriesPerSecond.mean+minQPSThresholdDifference)
+	// First check if we should transfer leases away to better balance load.
+	minLoad, maxLoad := dim.rebalanceThresholds(&sr.st.SV, storeList)
 
 	var localDesc *roachpb.StoreDescriptor
 	for i := range storeList.stores {
@@ -224,9 +388,13 @@ func (sr *StoreRebalancer) rebalanceStore(
 		return
 	}
 
-	if !(localDesc.Capacity.QueriesPerSecond > qpsMaxThreshold) {
-		log.VEventf(ctx, 1, "local QPS %.2f is below max threshold %.2f (mean=%.2f); no rebalancing needed",
-			localDesc.Capacity.QueriesPerSecond, qpsMaxThreshold, storeList.candidateQueriesPerSecond.mean)
+	sr.metrics.Dimension.Update(int64(dim))
+	sr.metrics.LocalLoad.Update(dim.storeLoad(localDesc.Capacity))
+	sr.metrics.MeanLoad.Update(meanLoad)
+
+	if !(dim.storeLoad(localDesc.Capacity) > maxLoad) {
+		log.VEventf(ctx, 1, "local load %.2f %s is below max threshold %.2f (mean=%.2f); no rebalancing needed",
+			dim.storeLoad(localDesc.Capacity), dim, maxLoad, meanLoad)
 		return
 	}
 
@@ -234,20 +402,20 @@ func (sr *StoreRebalancer) rebalanceStore(
 	storeMap := storeListToMap(storeList)
 
 	log.Infof(ctx,
-		"considering load-based lease transfers for s%d with %.2f qps (mean=%.2f, upperThreshold=%.2f)",
-		localDesc.StoreID, localDesc.Capacity.QueriesPerSecond, storeList.candidateQueriesPerSecond.mean, qpsMaxThreshold)
+		"considering load-based lease transfers for s%d with %.2f %s (mean=%.2f, upperThreshold=%.2f)",
+		localDesc.StoreID, dim.storeLoad(localDesc.Capacity), dim, meanLoad, maxLoad)
 
-	hottestRanges := sr.replRankings.topQPS()
-	for localDesc.Capacity.QueriesPerSecond > qpsMaxThreshold {
+	hottestRanges := sr.replRankings.topLoad(dim)
+	for dim.storeLoad(localDesc.Capacity) > maxLoad {
 		replWithStats, target, considerForRebalance := sr.chooseLeaseToTransfer(
-			ctx, &hottestRanges, localDesc, storeList, storeMap, qpsMinThreshold, qpsMaxThreshold)
+			ctx, &hottestRanges, localDesc, storeList, storeMap, minLoad, maxLoad)
 		replicasToMaybeRebalance = append(replicasToMaybeRebalance, considerForRebalance...)
 		if replWithStats.repl == nil {
 			break
 		}
 
-		log.VEventf(ctx, 1, "transferring r%d (%.2f qps) to s%d to better balance load",
-			replWithStats.repl.RangeID, replWithStats.qps, target.StoreID)
+		log.VEventf(ctx, 1, "transferring r%d (%.2f %s) to s%d to better balance load",
+			replWithStats.repl.RangeID, dim.leaseLoad(replWithStats), dim, target.StoreID)
 		if err := contextutil.RunWithTimeout(ctx, "transfer lease", sr.rq.processTimeoutFunc(replWithStats.repl), func(ctx context.Context) error {
 			return sr.rq.transferLease(ctx, replWithStats.repl, target, replWithStats.qps)
 		}); err != nil {
@@ -259,54 +427,53 @@ func (sr *StoreRebalancer) rebalanceStore(
 		// Finally, update our local copies of the descriptors so that if
 		// additional transfers are needed we'll be making the decisions with more
 		// up-to-date info. The StorePool copies are updated by transferLease.
-		localDesc.Capacity.LeaseCount--
-		localDesc.Capacity.QueriesPerSecond -= replWithStats.qps
+		var otherCapacity *roachpb.StoreCapacity
 		if otherDesc := storeMap[target.StoreID]; otherDesc != nil {
-			otherDesc.Capacity.LeaseCount++
-			otherDesc.Capacity.QueriesPerSecond += replWithStats.qps
+			otherCapacity = &otherDesc.Capacity
 		}
+		moveLease(&localDesc.Capacity, otherCapacity, replWithStats)
 	}
 
-	if !(localDesc.Capacity.QueriesPerSecond > qpsMaxThreshold) {
+	if !(dim.storeLoad(localDesc.Capacity) > maxLoad) {
 		log.Infof(ctx,
-			"load-based lease transfers successfully brought s%d down to %.2f qps (mean=%.2f, upperThreshold=%.2f)",
-			localDesc.StoreID, localDesc.Capacity.QueriesPerSecond, storeList.candidateQueriesPerSecond.mean, qpsMaxThreshold)
+			"load-based lease transfers successfully brought s%d down to %.2f %s (mean=%.2f, upperThreshold=%.2f)",
+			localDesc.StoreID, dim.storeLoad(localDesc.Capacity), dim, meanLoad, maxLoad)
 		return
 	}
 
 	if mode != LBRebalancingLeasesAndReplicas {
 		log.Infof(ctx,
-			"ran out of leases worth transferring and qps (%.2f) is still above desired threshold (%.2f)",
-			localDesc.Capacity.QueriesPerSecond, qpsMaxThreshold)
+			"ran out of leases worth transferring and load (%.2f %s) is still above desired threshold (%.2f)",
+			dim.storeLoad(localDesc.Capacity), dim, maxLoad)
 		return
 	}
 	log.Infof(ctx,
-		"ran out of leases worth transferring and qps (%.2f) is still above desired threshold (%.2f); considering load-based replica rebalances",
-		localDesc.Capacity.QueriesPerSecond, qpsMaxThreshold)
+		"ran out of leases worth transferring and load (%.2f %s) is still above desired threshold (%.2f); considering load-based replica rebalances",
+		dim.storeLoad(localDesc.Capacity), dim, maxLoad)
 
 	// Re-combine replicasToMaybeRebalance with what remains of hottestRanges so
 	// that we'll reconsider them for replica rebalancing.
 	replicasToMaybeRebalance = append(replicasToMaybeRebalance, hottestRanges...)
 
-	for localDesc.Capacity.QueriesPerSecond > qpsMaxThreshold {
+	for dim.storeLoad(localDesc.Capacity) > maxLoad {
 		replWithStats, targets := sr.chooseReplicaToRebalance(
 			ctx,
 			&replicasToMaybeRebalance,
 			localDesc,
 			storeList,
 			storeMap,
-			qpsMinThreshold,
-			qpsMaxThreshold)
+			minLoad,
+			maxLoad)
 		if replWithStats.repl == nil {
 			log.Infof(ctx,
-				"ran out of replicas worth transferring and qps (%.2f) is still above desired threshold (%.2f); will check again soon",
-				localDesc.Capacity.QueriesPerSecond, qpsMaxThreshold)
+				"ran out of replicas worth transferring and load (%.2f %s) is still above desired threshold (%.2f); will check again soon",
+				dim.storeLoad(localDesc.Capacity), dim, maxLoad)
 			return
 		}
 
 		descBeforeRebalance := replWithStats.repl.Desc()
-		log.VEventf(ctx, 1, "rebalancing r%d (%.2f qps) from %v to %v to better balance load",
-			replWithStats.repl.RangeID, replWithStats.qps, descBeforeRebalance.Replicas(), targets)
+		log.VEventf(ctx, 1, "rebalancing r%d (%.2f %s) from %v to %v to better balance load",
+			replWithStats.repl.RangeID, dim.replicaLoad(replWithStats), dim, descBeforeRebalance.Replicas(), targets)
 		if err := contextutil.RunWithTimeout(ctx, "relocate range", sr.rq.processTimeoutFunc(replWithStats.repl), func(ctx context.Context) error {
 			return sr.rq.store.AdminRelocateRange(ctx, *descBeforeRebalance, targets)
 		}); err != nil {
@@ -326,24 +493,25 @@ func (sr *StoreRebalancer) rebalanceStore(
 		for i := range replicasBeforeRebalance {
 			if storeDesc := storeMap[replicasBeforeRebalance[i].StoreID]; storeDesc != nil {
 				storeDesc.Capacity.RangeCount--
+				storeDesc.Capacity.WriteBytesPerSecond -= replWithStats.writeBytes
 			}
 		}
-		localDesc.Capacity.LeaseCount--
-		localDesc.Capacity.QueriesPerSecond -= replWithStats.qps
+		var leaseCapacity *roachpb.StoreCapacity
 		for i := range targets {
 			if storeDesc := storeMap[targets[i].StoreID]; storeDesc != nil {
 				storeDesc.Capacity.RangeCount++
+				storeDesc.Capacity.WriteBytesPerSecond += replWithStats.writeBytes
 				if i == 0 {
-					storeDesc.Capacity.LeaseCount++
-					storeDesc.Capacity.QueriesPerSecond += replWithStats.qps
+					leaseCapacity = &storeDesc.Capacity
 				}
 			}
 		}
+		moveLease(&localDesc.Capacity, leaseCapacity, replWithStats)
 	}
 
 	log.Infof(ctx,
-		"load-based replica transfers successfully brought s%d down to %.2f qps (mean=%.2f, upperThreshold=%.2f)",
-		localDesc.StoreID, localDesc.Capacity.QueriesPerSecond, storeList.candidateQueriesPerSecond.mean, qpsMaxThreshold)
+		"load-based replica transfers successfully brought s%d down to %.2f %s (mean=%.2f, upperThreshold=%.2f)",
+		localDesc.StoreID, dim.storeLoad(localDesc.Capacity), dim, meanLoad, maxLoad)
 }
 
 // TODO(a-robinson): Should we take the number of leases on each store into
@@ -354,11 +522,12 @@ func (sr *StoreRebalancer) chooseLeaseToTransfer(
 	localDesc *roachpb.StoreDescriptor,
 	storeList StoreList,
 	storeMap map[roachpb.StoreID]*roachpb.StoreDescriptor,
-	minQPS float64,
-	maxQPS float64,
+	minLoad float64,
+	maxLoad float64,
 ) (replicaWithStats, roachpb.ReplicaDescriptor, []replicaWithStats) {
 	var considerForRebalance []replicaWithStats
 	now := sr.rq.store.Clock().Now()
+	dim := sr.dimension()
 	for {
 		if len(*hottestRanges) == 0 {
 			return replicaWithStats{}, roachpb.ReplicaDescriptor{}, considerForRebalance
@@ -371,39 +540,40 @@ func (sr *StoreRebalancer) chooseLeaseToTransfer(
 			return replicaWithStats{}, roachpb.ReplicaDescriptor{}, considerForRebalance
 		}
 
-		if shouldNotMoveAway(ctx, replWithStats, localDesc, now, minQPS) {
+		load := dim.leaseLoad(replWithStats)
+		if shouldNotMoveAway(ctx, dim, replWithStats, load, localDesc, now, minLoad) {
 			continue
 		}
 
-		// Don't bother moving leases whose QPS is below some small fraction of the
-		// store's QPS (unless the store has extra leases to spare anyway). It's
-		// just unnecessary churn with no benefit to move leases responsible for,
-		// for example, 1 qps on a store with 5000 qps.
-		const minQPSFraction = .001
-		if replWithStats.qps < localDesc.Capacity.QueriesPerSecond*minQPSFraction &&
+		// Don't bother moving leases whose load is below some small fraction of
+		// the store's load (unless the store has extra leases to spare anyway).
+		// It's just unnecessary churn with no benefit to move leases responsible
+		// for, for example, 1 qps on a store with 5000 qps.
+		const minLoadFraction = .001
+		if load < dim.storeLoad(localDesc.Capacity)*minLoadFraction &&
 			float64(localDesc.Capacity.LeaseCount) <= storeList.candidateLeases.mean {
-			log.VEventf(ctx, 5, "r%d's %.2f qps is too little to matter relative to s%d's %.2f total qps",
-				replWithStats.repl.RangeID, replWithStats.qps, localDesc.StoreID, localDesc.Capacity.QueriesPerSecond)
+			log.VEventf(ctx, 5, "r%d's %.2f %s is too little to matter relative to s%d's %.2f total",
+				replWithStats.repl.RangeID, load, dim, localDesc.StoreID, dim.storeLoad(localDesc.Capacity))
 			continue
 		}
 
 		desc, zone := replWithStats.repl.DescAndZone()
-		log.VEventf(ctx, 3, "considering lease transfer for r%d with %.2f qps",
-			desc.RangeID, replWithStats.qps)
+		log.VEventf(ctx, 3, "considering lease transfer for r%d with %.2f %s",
+			desc.RangeID, load, dim)
 
-		// Check all the other replicas in order of increasing qps. Learner replicas
-		// aren't allowed to become the leaseholder or raft leader, so only consider
-		// the `Voters` replicas.
+		// Check all the other replicas in order of increasing load. Learner
+		// replicas aren't allowed to become the leaseholder or raft leader, so
+		// only consider the `Voters` replicas.
 		candidates := desc.Replicas().DeepCopy().Voters()
 		sort.Slice(candidates, func(i, j int) bool {
-			var iQPS, jQPS float64
+			var iLoad, jLoad float64
 			if desc := storeMap[candidates[i].StoreID]; desc != nil {
-				iQPS = desc.Capacity.QueriesPerSecond
+				iLoad = dim.storeLoad(desc.Capacity)
 			}
 			if desc := storeMap[candidates[j].StoreID]; desc != nil {
-				jQPS = desc.Capacity.QueriesPerSecond
+				jLoad = dim.storeLoad(desc.Capacity)
 			}
-			return iQPS < jQPS
+			return iLoad < jLoad
 		})
 
 		var raftStatus *raft.Status
@@ -414,8 +584,10 @@ func (sr *StoreRebalancer) chooseLeaseToTransfer(
 				continue
 			}
 
-			meanQPS := storeList.candidateQueriesPerSecond.mean
-			if shouldNotMoveTo(ctx, storeMap, replWithStats, candidate.StoreID, meanQPS, minQPS, maxQPS) {
+			meanLoad := dim.candidateLoad(storeList).mean
+			if shouldNotMoveTo(
+				ctx, dim, storeMap, replWithStats, load, candidate.StoreID, meanLoad, minLoad, maxLoad,
+			) {
 				continue
 			}
 
@@ -463,10 +635,11 @@ func (sr *StoreRebalancer) chooseReplicaToRebalance(
 	localDesc *roachpb.StoreDescriptor,
 	storeList StoreList,
 	storeMap map[roachpb.StoreID]*roachpb.StoreDescriptor,
-	minQPS float64,
-	maxQPS float64,
+	minLoad float64,
+	maxLoad float64,
 ) (replicaWithStats, []roachpb.ReplicationTarget) {
 	now := sr.rq.store.Clock().Now()
+	dim := sr.dimension()
 	for {
 		if len(*hottestRanges) == 0 {
 			return replicaWithStats{}, nil
@@ -478,34 +651,39 @@ func (sr *StoreRebalancer) chooseReplicaToRebalance(
 			return replicaWithStats{}, nil
 		}
 
-		if shouldNotMoveAway(ctx, replWithStats, localDesc, now, minQPS) {
+		load := dim.replicaLoad(replWithStats)
+		if shouldNotMoveAway(ctx, dim, replWithStats, load, localDesc, now, minLoad) {
 			continue
 		}
 
-		// Don't bother moving ranges whose QPS is below some small fraction of the
-		// store's QPS (unless the store has extra ranges to spare anyway). It's
-		// just unnecessary churn with no benefit to move ranges responsible for,
-		// for example, 1 qps on a store with 5000 qps.
-		const minQPSFraction = .001
-		if replWithStats.qps < localDesc.Capacity.QueriesPerSecond*minQPSFraction &&
+		// Don't bother moving ranges whose load is below some small fraction of
+		// the store's load (unless the store has extra ranges to spare anyway).
+		// It's just unnecessary churn with no benefit to move ranges responsible
+		// for, for example, 1 qps on a store with 5000 qps.
+		const minLoadFraction = .001
+		if load < dim.storeLoad(localDesc.Capacity)*minLoadFraction &&
 			float64(localDesc.Capacity.RangeCount) <= storeList.candidateRanges.mean {
-			log.VEventf(ctx, 5, "r%d's %.2f qps is too little to matter relative to s%d's %.2f total qps",
-				replWithStats.repl.RangeID, replWithStats.qps, localDesc.StoreID, localDesc.Capacity.QueriesPerSecond)
+			log.VEventf(ctx, 5, "r%d's %.2f %s is too little to matter relative to s%d's %.2f total",
+				replWithStats.repl.RangeID, load, dim, localDesc.StoreID, dim.storeLoad(localDesc.Capacity))
 			continue
 		}
 
 		desc, zone := replWithStats.repl.DescAndZone()
-		log.VEventf(ctx, 3, "considering replica rebalance for r%d with %.2f qps",
-			desc.RangeID, replWithStats.qps)
+		log.VEventf(ctx, 3, "considering replica rebalance for r%d with %.2f %s",
+			desc.RangeID, load, dim)
 
 		clusterNodes := sr.rq.allocator.storePool.ClusterNodeCount()
-		desiredReplicas := GetNeededReplicas(*zone.NumReplicas, clusterNodes)
+		desiredReplicas := GetNeededReplicas(zone.GetNumVoters(), clusterNodes)
 		targets := make([]roachpb.ReplicationTarget, 0, desiredReplicas)
 		targetReplicas := make([]roachpb.ReplicaDescriptor, 0, desiredReplicas)
-		currentReplicas := desc.Replicas().All()
+		// Only the voters are rebalanced, and the nodes of the non-voters are
+		// ruled out as targets since a node can hold at most one replica.
+		currentReplicas := desc.Replicas().Voters()
+		voterStoreList := storeList.excludeNodesOf(desc.Replicas().NonVoters()).
+			filter(zone.VoterConstraints)
 
 		// Check the range's existing diversity score, since we want to ensure we
-		// don't hurt locality diversity just to improve QPS.
+		// don't hurt locality diversity just to improve load.
 		curDiversity := rangeDiversityScore(
 			sr.rq.allocator.storePool.getLocalities(currentReplicas))
 
@@ -514,11 +692,11 @@ func (sr *StoreRebalancer) chooseReplicaToRebalance(
 			if currentReplicas[i].StoreID == localDesc.StoreID {
 				continue
 			}
-			// Keep the replica in the range if we don't know its QPS or if its QPS
-			// is below the upper threshold. Punishing stores not in our store map
-			// could cause mass evictions if the storePool gets out of sync.
+			// Keep the replica in the range if we don't know its store's load or if
+			// its load is below the upper threshold. Punishing stores not in our
+			// store map could cause mass evictions if the storePool gets out of sync.
 			storeDesc, ok := storeMap[currentReplicas[i].StoreID]
-			if !ok || storeDesc.Capacity.QueriesPerSecond < maxQPS {
+			if !ok || dim.storeLoad(storeDesc.Capacity) < maxLoad {
 				targets = append(targets, roachpb.ReplicationTarget{
 					NodeID:  currentReplicas[i].NodeID,
 					StoreID: currentReplicas[i].StoreID,
@@ -532,14 +710,15 @@ func (sr *StoreRebalancer) chooseReplicaToRebalance(
 
 		// Then pick out which new stores to add the remaining replicas to.
 		options := sr.rq.allocator.scorerOptions()
-		options.qpsRebalanceThreshold = qpsRebalanceThreshold.Get(&sr.st.SV)
+		options.loadDimension = dim
+		options.loadRebalanceThreshold = dim.rebalanceThreshold(&sr.st.SV)
 		for len(targets) < desiredReplicas {
 			// Use the preexisting AllocateTarget logic to ensure that considerations
 			// such as zone constraints, locality diversity, and full disk come
 			// into play.
 			target, _ := sr.rq.allocator.allocateTargetFromList(
 				ctx,
-				storeList,
+				voterStoreList,
 				zone,
 				targetReplicas,
 				options,
@@ -550,8 +729,10 @@ func (sr *StoreRebalancer) chooseReplicaToRebalance(
 				break
 			}
 
-			meanQPS := storeList.candidateQueriesPerSecond.mean
-			if shouldNotMoveTo(ctx, storeMap, replWithStats, target.StoreID, meanQPS, minQPS, maxQPS) {
+			meanLoad := dim.candidateLoad(storeList).mean
+			if shouldNotMoveTo(
+				ctx, dim, storeMap, replWithStats, load, target.StoreID, meanLoad, minLoad, maxLoad,
+			) {
 				break
 			}
 
@@ -570,7 +751,7 @@ func (sr *StoreRebalancer) chooseReplicaToRebalance(
 		// TODO(a-robinson): Support more incremental improvements -- move what we
 		// can if it makes things better even if it isn't great. For example,
 		// moving one of the other existing replicas that's on a store with less
-		// qps than the max threshold but above the mean would help in certain
+		// load than the max threshold but above the mean would help in certain
 		// locality configurations.
 		if len(targets) < desiredReplicas {
 			log.VEventf(ctx, 3, "couldn't find enough rebalance targets for r%d (%d/%d)",
@@ -585,10 +766,10 @@ func (sr *StoreRebalancer) chooseReplicaToRebalance(
 			continue
 		}
 
-		// Pick the replica with the least QPS to be leaseholder;
+		// Pick the replica with the least load to be leaseholder;
 		// RelocateRange transfers the lease to the first provided target.
 		newLeaseIdx := 0
-		newLeaseQPS := math.MaxFloat64
+		newLeaseLoad := math.MaxFloat64
 		var raftStatus *raft.Status
 		for i := 0; i < len(targets); i++ {
 			// Ensure we don't transfer the lease to an existing replica that is behind
@@ -603,9 +784,9 @@ func (sr *StoreRebalancer) chooseReplicaToRebalance(
 			}
 
 			storeDesc, ok := storeMap[targets[i].StoreID]
-			if ok && storeDesc.Capacity.QueriesPerSecond < newLeaseQPS {
+			if ok && dim.storeLoad(storeDesc.Capacity) < newLeaseLoad {
 				newLeaseIdx = i
-				newLeaseQPS = storeDesc.Capacity.QueriesPerSecond
+				newLeaseLoad = dim.storeLoad(storeDesc.Capacity)
 			}
 		}
 		targets[0], targets[newLeaseIdx] = targets[newLeaseIdx], targets[0]
@@ -613,33 +794,41 @@ func (sr *StoreRebalancer) chooseReplicaToRebalance(
 	}
 }
 
+// shouldNotMoveAway returns whether moving the provided load, belonging to
+// the given replica, away from the local store is undesirable.
 func shouldNotMoveAway(
 	ctx context.Context,
+	dim LBRebalancingDimension,
 	replWithStats replicaWithStats,
+	load float64,
 	localDesc *roachpb.StoreDescriptor,
 	now hlc.Timestamp,
-	minQPS float64,
+	minLoad float64,
 ) bool {
 	if !replWithStats.repl.OwnsValidLease(now) {
 		log.VEventf(ctx, 3, "store doesn't own the lease for r%d", replWithStats.repl.RangeID)
 		return true
 	}
-	if localDesc.Capacity.QueriesPerSecond-replWithStats.qps < minQPS {
-		log.VEventf(ctx, 3, "moving r%d's %.2f qps would bring s%d below the min threshold (%.2f)",
-			replWithStats.repl.RangeID, replWithStats.qps, localDesc.StoreID, minQPS)
+	if dim.storeLoad(localDesc.Capacity)-load < minLoad {
+		log.VEventf(ctx, 3, "moving r%d's %.2f %s would bring s%d below the min threshold (%.2f)",
+			replWithStats.repl.RangeID, load, dim, localDesc.StoreID, minLoad)
 		return true
 	}
 	return false
 }
 
+// shouldNotMoveTo returns whether moving the provided load, belonging to the
+// given replica, to the candidate store is undesirable.
 func shouldNotMoveTo(
 	ctx context.Context,
+	dim LBRebalancingDimension,
 	storeMap map[roachpb.StoreID]*roachpb.StoreDescriptor,
 	replWithStats replicaWithStats,
+	load float64,
 	candidateStore roachpb.StoreID,
-	meanQPS float64,
-	minQPS float64,
-	maxQPS float64,
+	meanLoad float64,
+	minLoad float64,
+	maxLoad float64,
 ) bool {
 	storeDesc, ok := storeMap[candidateStore]
 	if !ok {
@@ -647,18 +836,19 @@ func shouldNotMoveTo(
 		return true
 	}
 
-	newCandidateQPS := storeDesc.Capacity.QueriesPerSecond + replWithStats.qps
-	if storeDesc.Capacity.QueriesPerSecond < minQPS {
-		if newCandidateQPS > maxQPS {
+	candidateLoad := dim.storeLoad(storeDesc.Capacity)
+	newCandidateLoad := candidateLoad + load
+	if candidateLoad < minLoad {
+		if newCandidateLoad > maxLoad {
 			log.VEventf(ctx, 3,
-				"r%d's %.2f qps would push s%d over the max threshold (%.2f) with %.2f qps afterwards",
-				replWithStats.repl.RangeID, replWithStats.qps, candidateStore, maxQPS, newCandidateQPS)
+				"r%d's %.2f %s would push s%d over the max threshold (%.2f) with %.2f afterwards",
+				replWithStats.repl.RangeID, load, dim, candidateStore, maxLoad, newCandidateLoad)
 			return true
 		}
-	} else if newCandidateQPS > meanQPS {
+	} else if newCandidateLoad > meanLoad {
 		log.VEventf(ctx, 3,
-			"r%d's %.2f qps would push s%d over the mean (%.2f) with %.2f qps afterwards",
-			replWithStats.repl.RangeID, replWithStats.qps, candidateStore, meanQPS, newCandidateQPS)
+			"r%d's %.2f %s would push s%d over the mean (%.2f) with %.2f afterwards",
+			replWithStats.repl.RangeID, load, dim, candidateStore, meanLoad, newCandidateLoad)
 		return true
 	}
 
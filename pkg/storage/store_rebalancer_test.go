@@ -374,3 +374,46 @@ func TestNoLeaseTransferToBehindReplicas(t *testing.T) {
 			targets, sr.getRaftStatusFn(repl), expectTargets)
 	}
 }
+
+func TestLBRebalancingDimensionLoad(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	sc := roachpb.StoreCapacity{
+		QueriesPerSecond:    1000,
+		CPUPerSecond:        2e8,
+		WriteBytesPerSecond: 1e6,
+	}
+	repl := replicaWithStats{qps: 100, cpu: 1e7, writeBytes: 1e4}
+
+	testCases := []struct {
+		dim                                LBRebalancingDimension
+		expStore, expLease, expReplicaLoad float64
+	}{
+		{LBRebalancingQueries, 1000, 100, 100},
+		{LBRebalancingCPU, 2e8 + 1e6*writeByteCPUCost, 1e7, 1e7 + 1e4*writeByteCPUCost},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.dim.String(), func(t *testing.T) {
+			if load := tc.dim.storeLoad(sc); load != tc.expStore {
+				t.Errorf("expected store load %.2f, got %.2f", tc.expStore, load)
+			}
+			if load := tc.dim.leaseLoad(repl); load != tc.expLease {
+				t.Errorf("expected lease load %.2f, got %.2f", tc.expLease, load)
+			}
+			if load := tc.dim.replicaLoad(repl); load != tc.expReplicaLoad {
+				t.Errorf("expected replica load %.2f, got %.2f", tc.expReplicaLoad, load)
+			}
+		})
+	}
+
+	// Moving a lease moves its QPS and CPU time, but not the bytes written to
+	// the range, which every replica has to apply.
+	from, to := sc, roachpb.StoreCapacity{}
+	moveLease(&from, &to, repl)
+	if from.QueriesPerSecond != 900 || from.CPUPerSecond != 1.9e8 || from.WriteBytesPerSecond != 1e6 {
+		t.Errorf("unexpected capacity after moving lease away: %+v", from)
+	}
+	if to.QueriesPerSecond != 100 || to.CPUPerSecond != 1e7 || to.WriteBytesPerSecond != 0 {
+		t.Errorf("unexpected capacity after receiving lease: %+v", to)
+	}
+}
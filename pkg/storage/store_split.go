@@ -255,6 +255,10 @@ func (s *Store) SplitRange(
 	// to ensure that no pre-split commands are inserted into the
 	// txnWaitQueue after we clear it.
 	leftRepl.txnWaitQueue.Clear(false /* disable */)
+	// The LHS lock table may hold locks on keys that now belong to the RHS.
+	// Clear it, which wakes up the requests waiting on those locks so that
+	// they are redirected to the RHS.
+	leftRepl.lockTable.Clear()
 
 	// The rangefeed processor will no longer be provided logical ops for
 	// its entire range, so it needs to be shut down and all registrations
@@ -273,9 +277,13 @@ func (s *Store) SplitRange(
 	if rightReplOrNil == nil {
 		throwawayRightWriteStats := new(replicaStats)
 		leftRepl.writeStats.splitRequestCounts(throwawayRightWriteStats)
+		leftRepl.writeBytesStats.splitRequestCounts(new(replicaStats))
+		leftRepl.evalTimeStats.splitRequestCounts(new(replicaStats))
 	} else {
 		rightRepl := rightReplOrNil
 		leftRepl.writeStats.splitRequestCounts(rightRepl.writeStats)
+		leftRepl.writeBytesStats.splitRequestCounts(rightRepl.writeBytesStats)
+		leftRepl.evalTimeStats.splitRequestCounts(rightRepl.evalTimeStats)
 		if err := s.addReplicaInternalLocked(rightRepl); err != nil {
 			return errors.Errorf("unable to add replica %v: %s", rightRepl, err)
 		}
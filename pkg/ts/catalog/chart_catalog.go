@@ -427,6 +427,25 @@ var charts = []sectionDescription{
 				Title:   "QPS",
 				Metrics: []string{"rebalancing.queriespersecond"},
 			},
+			{
+				Title:   "CPU Time",
+				Metrics: []string{"rebalancing.cpunanospersecond"},
+			},
+			{
+				Title:   "Write Bytes",
+				Metrics: []string{"rebalancing.writebytespersecond"},
+			},
+			{
+				Title:   "Load Dimension",
+				Metrics: []string{"rebalancing.dimension"},
+			},
+			{
+				Title: "Load",
+				Metrics: []string{
+					"rebalancing.load.local",
+					"rebalancing.load.mean",
+				},
+			},
 		},
 	},
 	{
@@ -459,6 +478,83 @@ var charts = []sectionDescription{
 			},
 		},
 	},
+	{
+		Organization: [][]string{{KVTransactionLayer, "Admission Control", "Overview"}},
+		Charts: []chartDescription{
+			{
+				Title:   "Overloaded",
+				Metrics: []string{"admission.overloaded"},
+			},
+			{
+				Title:   "L0 Files",
+				Metrics: []string{"admission.l0_file_count"},
+			},
+			{
+				Title:   "Scheduling Latency",
+				Metrics: []string{"admission.scheduling_latency"},
+			},
+			{
+				Title:   "GC Pause Fraction",
+				Metrics: []string{"admission.gc_pause_fraction"},
+			},
+		},
+	},
+	{
+		Organization: [][]string{{KVTransactionLayer, "Admission Control", "KV"}},
+		Charts: []chartDescription{
+			{
+				Title: "Requests",
+				Metrics: []string{
+					"admission.kv.requested",
+					"admission.kv.admitted",
+					"admission.kv.errored",
+				},
+			},
+			{
+				Title:   "Wait Queue Length",
+				Metrics: []string{"admission.kv.wait_queue_length"},
+			},
+			{
+				Title:   "Wait Durations",
+				Metrics: []string{"admission.kv.wait_durations"},
+			},
+			{
+				Title: "Slots",
+				Metrics: []string{
+					"admission.kv.used_slots",
+					"admission.kv.total_slots",
+				},
+			},
+		},
+	},
+	{
+		Organization: [][]string{{SQLLayer, "DistSQL", "Admission Control"}},
+		Charts: []chartDescription{
+			{
+				Title: "Requests",
+				Metrics: []string{
+					"admission.sql_flows.requested",
+					"admission.sql_flows.admitted",
+					"admission.sql_flows.errored",
+				},
+			},
+			{
+				Title:   "Wait Queue Length",
+				Metrics: []string{"admission.sql_flows.wait_queue_length"},
+			},
+			{
+				Title:   "Wait Durations",
+				Metrics: []string{"admission.sql_flows.wait_durations"},
+			},
+			{
+				Title: "Slots",
+				Metrics: []string{
+					"admission.sql_flows.used_slots",
+					"admission.sql_flows.total_slots",
+				},
+			},
+		},
+	},
 	{
 		Organization: [][]string{{KVTransactionLayer, "Clocks"}}, Charts: []chartDescription{
 			{
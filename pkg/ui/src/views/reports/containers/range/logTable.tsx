@@ -40,6 +40,11 @@ function printLogEventType(
   }
 }
 
+// consistencyRepairReason is the reason of the range log events of the
+// replacement of a replica which diverged from its peers, whose details are the
+// diff between the replicas.
+const consistencyRepairReason = "consistency repair";
+
 export default class LogTable extends React.Component<LogTableProps, {}> {
   // If there is no otherRangeID, it comes back as the number 0.
   renderRangeID(otherRangeID: Long | number) {
@@ -71,9 +76,22 @@ export default class LogTable extends React.Component<LogTableProps, {}> {
     );
   }
 
+  renderConsistencyRepairDiff(diff: string) {
+    if (_.isEmpty(diff)) {
+      return null;
+    }
+    return (
+      <li>
+        Inconsistency Diff:
+        <pre className="log-entries-list__diff">{diff}</pre>
+      </li>
+    );
+  }
+
   renderLogInfo(
     info: protos.cockroach.server.serverpb.RangeLogResponse.IPrettyInfo,
   ) {
+    const isRepair = info.reason === consistencyRepairReason;
     return (
       <ul className="log-entries-list">
         {this.renderLogInfoDescriptor("Updated Range Descriptor", info.updated_desc)}
@@ -81,7 +99,9 @@ export default class LogTable extends React.Component<LogTableProps, {}> {
         {this.renderLogInfoDescriptor("Added Replica", info.added_replica)}
         {this.renderLogInfoDescriptor("Removed Replica", info.removed_replica)}
         {this.renderLogInfoDescriptor("Reason", info.reason)}
-        {this.renderLogInfoDescriptor("Details", info.details)}
+        {isRepair
+          ? this.renderConsistencyRepairDiff(info.details)
+          : this.renderLogInfoDescriptor("Details", info.details)}
       </ul>
     );
   }
@@ -113,7 +133,10 @@ export default class LogTable extends React.Component<LogTableProps, {}> {
                 {Print.Timestamp(event.event.timestamp)}
               </td>
               <td className="log-table__cell">s{event.event.store_id}</td>
-              <td className="log-table__cell">{printLogEventType(event.event.event_type)}</td>
+              <td className="log-table__cell">
+                {printLogEventType(event.event.event_type)}
+                {event.pretty_info.reason === consistencyRepairReason ? " (Consistency Repair)" : null}
+              </td>
               <td className="log-table__cell">{this.renderRangeID(event.event.range_id)}</td>
               <td className="log-table__cell">{this.renderRangeID(event.event.other_range_id)}</td>
               <td className="log-table__cell">{this.renderLogInfo(event.pretty_info)}</td>
@@ -0,0 +1,341 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+// Package admission implements admission control, which protects a node from
+// accepting more work than it can handle. Work entering the KV layer and
+// DistSQL flows wait in WorkQueues, which admit them in order of priority and
+// age. The Controller watches the node's load and limits the number of units
+// of work that can run concurrently while the node is overloaded.
+package admission
+
+import (
+	"context"
+	"runtime"
+	"runtime/debug"
+	"sort"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/metric"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/pkg/errors"
+)
+
+// KVAdmissionControlEnabled controls whether KV requests are subject to
+// admission control.
+var KVAdmissionControlEnabled = settings.RegisterPublicBoolSetting(
+	"admission.kv.enabled",
+	"when true, work performed by the KV layer is subject to admission control",
+	false,
+)
+
+// SQLFlowAdmissionControlEnabled controls whether DistSQL flows are subject
+// to admission control.
+var SQLFlowAdmissionControlEnabled = settings.RegisterPublicBoolSetting(
+	"admission.sql_flows.enabled",
+	"when true, DistSQL flows scheduled on a node are subject to admission control",
+	false,
+)
+
+// l0FileCountOverloadThreshold is the number of files in level 0 of a store
+// above which the node is considered overloaded. Files pile up in L0 when
+// compactions can't keep up with writes, which makes reads increasingly
+// expensive.
+var l0FileCountOverloadThreshold = settings.RegisterPositiveIntSetting(
+	"admission.l0_file_count_overload_threshold",
+	"when the number of files in level 0 of any store exceeds this value, the node is considered overloaded",
+	20,
+)
+
+// schedulingLatencyOverloadThreshold is the scheduling latency above which the
+// node is considered overloaded. See sampleSchedulingLatency.
+var schedulingLatencyOverloadThreshold = settings.RegisterNonNegativeDurationSetting(
+	"admission.scheduling_latency_overload_threshold",
+	"when runnable goroutines wait longer than this to be scheduled, the node is considered overloaded",
+	5*time.Millisecond,
+)
+
+// gcPauseFractionOverloadThreshold is the fraction of time spent in garbage
+// collection pauses above which the node is considered overloaded.
+var gcPauseFractionOverloadThreshold = settings.RegisterValidatedFloatSetting(
+	"admission.gc_pause_fraction_overload_threshold",
+	"when the fraction of time the process is paused for garbage collection exceeds this value, "+
+		"the node is considered overloaded",
+	0.05,
+	func(v float64) error {
+		if v <= 0 || v > 1 {
+			return errors.Errorf("%v is not in (0, 1]", v)
+		}
+		return nil
+	},
+)
+
+const (
+	// adjustInterval is how often the Controller samples the node's load and
+	// adjusts the number of slots of its queues.
+	adjustInterval = 250 * time.Millisecond
+	// minSlotsPerCPU bounds how far the number of slots of a queue can be
+	// lowered. It has to allow enough concurrency to keep the CPUs busy while
+	// work waits on disk and network I/O.
+	minSlotsPerCPU = 8
+	// maxSlotsPerCPU is the number of slots above which a queue whose node is
+	// no longer overloaded stops limiting concurrency altogether.
+	maxSlotsPerCPU = 256
+	// schedulingLatencySamples is the number of measurements the scheduling
+	// latency is the median of.
+	schedulingLatencySamples = 5
+)
+
+// Controller samples the load of the node and adjusts the number of slots of
+// the KV and DistSQL flow admission queues accordingly.
+//
+// While the node is overloaded, the number of slots of each queue is lowered
+// multiplicatively, starting from the number of slots in use. Once the
+// overload clears, it is raised additively, and the queues stop limiting
+// concurrency when it exceeds maxSlotsPerCPU slots per CPU.
+//
+// None of the load signals grows with the amount of work waiting for
+// admission: waiting work is blocked, so it neither runs on the CPUs nor
+// allocates.
+type Controller struct {
+	st *cluster.Settings
+	// l0FileCount returns the highest number of L0 files among the node's
+	// stores.
+	l0FileCount func() int64
+	// schedulingLatency measures how long runnable goroutines wait to be
+	// scheduled.
+	schedulingLatency func() time.Duration
+	// gcPauseTotal returns the total duration of the garbage collection
+	// pauses since the process started.
+	gcPauseTotal func() time.Duration
+	// now returns the current time.
+	now    func() time.Time
+	numCPU int
+
+	// lastGCPauseTotal and lastSample are the values of gcPauseTotal and now
+	// as of the previous call to adjust.
+	lastGCPauseTotal time.Duration
+	lastSample       time.Time
+
+	kvQueue   *WorkQueue
+	flowQueue *WorkQueue
+	metrics   ControllerMetrics
+}
+
+// NewController creates a Controller. The provided function returns the
+// highest number of L0 files among the node's stores.
+func NewController(
+	st *cluster.Settings, histogramWindow time.Duration, l0FileCount func() int64,
+) *Controller {
+	return &Controller{
+		st:                st,
+		l0FileCount:       l0FileCount,
+		schedulingLatency: sampleSchedulingLatency,
+		gcPauseTotal:      readGCPauseTotal,
+		now:               timeutil.Now,
+		numCPU:            runtime.GOMAXPROCS(0),
+		kvQueue:           makeWorkQueue("kv", KVAdmissionControlEnabled, st, histogramWindow),
+		flowQueue: makeWorkQueue(
+			"sql_flows", SQLFlowAdmissionControlEnabled, st, histogramWindow),
+		metrics: makeControllerMetrics(),
+	}
+}
+
+// sampleSchedulingLatency returns the median of a few measurements of the time
+// it takes a goroutine which yields the processor to be scheduled again.
+//
+// Go doesn't expose the number of runnable goroutines, but a yielding
+// goroutine is put on the global run queue, which the scheduler only looks
+// at once a processor runs out of local work or every so often. The latency
+// thus grows with the number of runnable goroutines per processor, unlike the
+// total number of goroutines, most of which are blocked. In particular, the
+// goroutines of work waiting for admission don't count.
+func sampleSchedulingLatency() time.Duration {
+	var samples [schedulingLatencySamples]time.Duration
+	for i := range samples {
+		start := timeutil.Now()
+		runtime.Gosched()
+		samples[i] = timeutil.Since(start)
+	}
+	sort.Slice(samples[:], func(i, j int) bool { return samples[i] < samples[j] })
+	return samples[len(samples)/2]
+}
+
+// readGCPauseTotal returns the total duration of the garbage collection pauses
+// since the process started. Unlike runtime.ReadMemStats, debug.ReadGCStats
+// doesn't stop the world.
+func readGCPauseTotal() time.Duration {
+	var stats debug.GCStats
+	debug.ReadGCStats(&stats)
+	return stats.PauseTotal
+}
+
+// KVQueue returns the admission queue of work entering the KV layer.
+func (c *Controller) KVQueue() *WorkQueue {
+	return c.kvQueue
+}
+
+// FlowQueue returns the admission queue of DistSQL flows.
+func (c *Controller) FlowQueue() *WorkQueue {
+	return c.flowQueue
+}
+
+// Metrics returns the metrics of the Controller and of its queues.
+func (c *Controller) Metrics() []metric.Struct {
+	return []metric.Struct{c.metrics, c.kvQueue.metrics, c.flowQueue.metrics}
+}
+
+// Start runs a worker that periodically adjusts the number of slots of the
+// Controller's queues.
+func (c *Controller) Start(ctx context.Context, stopper *stop.Stopper) {
+	stopper.RunWorker(ctx, func(ctx context.Context) {
+		ticker := time.NewTicker(adjustInterval)
+		defer ticker.Stop()
+		wasOverloaded := false
+		for {
+			select {
+			case <-ticker.C:
+				overloaded := c.adjust()
+				if overloaded != wasOverloaded {
+					log.Infof(ctx, "admission control: overloaded=%t, %s, %s",
+						overloaded, c.kvQueue, c.flowQueue)
+					wasOverloaded = overloaded
+				}
+			case <-stopper.ShouldQuiesce():
+				return
+			}
+		}
+	})
+}
+
+// adjust samples the load of the node and adjusts the number of slots of the
+// queues. It returns whether the node is overloaded.
+func (c *Controller) adjust() (overloaded bool) {
+	var l0Files int64
+	if c.l0FileCount != nil {
+		l0Files = c.l0FileCount()
+	}
+	schedulingLatency := c.schedulingLatency()
+
+	// The GC pause fraction is computed over the time elapsed since the
+	// previous sample; there is nothing to compare to on the first one.
+	var gcPauseFraction float64
+	now, gcPauseTotal := c.now(), c.gcPauseTotal()
+	if !c.lastSample.IsZero() {
+		if elapsed := now.Sub(c.lastSample); elapsed > 0 {
+			gcPauseFraction = float64(gcPauseTotal-c.lastGCPauseTotal) / float64(elapsed)
+		}
+	}
+	c.lastSample, c.lastGCPauseTotal = now, gcPauseTotal
+
+	c.metrics.L0FileCount.Update(l0Files)
+	c.metrics.SchedulingLatency.Update(schedulingLatency.Nanoseconds())
+	c.metrics.GCPauseFraction.Update(gcPauseFraction)
+
+	overloaded = l0Files > l0FileCountOverloadThreshold.Get(&c.st.SV) ||
+		schedulingLatency > schedulingLatencyOverloadThreshold.Get(&c.st.SV) ||
+		gcPauseFraction > gcPauseFractionOverloadThreshold.Get(&c.st.SV)
+	if overloaded {
+		c.metrics.Overloaded.Update(1)
+	} else {
+		c.metrics.Overloaded.Update(0)
+	}
+	for _, q := range []*WorkQueue{c.kvQueue, c.flowQueue} {
+		used, total := q.slots()
+		newTotal := c.nextTotalSlots(overloaded, used, total)
+		if newTotal != total {
+			q.setTotalSlots(newTotal)
+		}
+		q.metrics.UsedSlots.Update(int64(used))
+		if newTotal == unlimitedSlots {
+			q.metrics.TotalSlots.Update(0)
+		} else {
+			q.metrics.TotalSlots.Update(int64(newTotal))
+		}
+	}
+	return overloaded
+}
+
+// nextTotalSlots computes the new number of slots of a queue.
+func (c *Controller) nextTotalSlots(overloaded bool, used, total int) int {
+	minSlots, maxSlots := minSlotsPerCPU*c.numCPU, maxSlotsPerCPU*c.numCPU
+	if overloaded {
+		if total > used {
+			// Start limiting concurrency from what is currently running.
+			total = used
+		}
+		total -= total / 4
+		if total < minSlots {
+			total = minSlots
+		}
+		return total
+	}
+	if total == unlimitedSlots {
+		return total
+	}
+	total += c.numCPU
+	if total > maxSlots {
+		return unlimitedSlots
+	}
+	return total
+}
+
+// ControllerMetrics are the metrics of a Controller describing the load
+// signals it acts upon.
+type ControllerMetrics struct {
+	Overloaded        *metric.Gauge
+	L0FileCount       *metric.Gauge
+	SchedulingLatency *metric.Gauge
+	GCPauseFraction   *metric.GaugeFloat64
+}
+
+// MetricStruct implements the metric.Struct interface.
+func (ControllerMetrics) MetricStruct() {}
+
+var _ metric.Struct = ControllerMetrics{}
+
+var (
+	metaAdmissionOverloaded = metric.Metadata{
+		Name:        "admission.overloaded",
+		Help:        "Whether admission control considers the node overloaded (1) or not (0)",
+		Measurement: "Overloaded",
+		Unit:        metric.Unit_CONST,
+	}
+	metaAdmissionL0FileCount = metric.Metadata{
+		Name:        "admission.l0_file_count",
+		Help:        "Highest number of files in level 0 among the node's stores, as seen by admission control",
+		Measurement: "Files",
+		Unit:        metric.Unit_COUNT,
+	}
+	metaAdmissionSchedulingLatency = metric.Metadata{
+		Name:        "admission.scheduling_latency",
+		Help:        "Time runnable goroutines wait to be scheduled, as seen by admission control",
+		Measurement: "Latency",
+		Unit:        metric.Unit_NANOSECONDS,
+	}
+	metaAdmissionGCPauseFraction = metric.Metadata{
+		Name:        "admission.gc_pause_fraction",
+		Help:        "Fraction of time the process is paused for garbage collection, as seen by admission control",
+		Measurement: "GC Pause",
+		Unit:        metric.Unit_PERCENT,
+	}
+)
+
+func makeControllerMetrics() ControllerMetrics {
+	return ControllerMetrics{
+		Overloaded:        metric.NewGauge(metaAdmissionOverloaded),
+		L0FileCount:       metric.NewGauge(metaAdmissionL0FileCount),
+		SchedulingLatency: metric.NewGauge(metaAdmissionSchedulingLatency),
+		GCPauseFraction:   metric.NewGaugeFloat64(metaAdmissionGCPauseFraction),
+	}
+}
@@ -0,0 +1,343 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package admission
+
+import (
+	"container/heap"
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/metric"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/pkg/errors"
+)
+
+// WorkPriority is the priority of work waiting for admission. Work with a
+// higher priority is admitted before work with a lower one.
+type WorkPriority int8
+
+const (
+	// LowPri is the priority of work issued with a low user priority.
+	LowPri WorkPriority = math.MinInt8
+	// NormalPri is the priority of most work.
+	NormalPri WorkPriority = 0
+	// HighPri is the priority of work issued with a high user priority.
+	HighPri WorkPriority = math.MaxInt8
+)
+
+// UserPriorityToWorkPriority maps the user priority of a transaction to the
+// priority of its work.
+func UserPriorityToWorkPriority(up roachpb.UserPriority) WorkPriority {
+	switch {
+	case up == roachpb.UnspecifiedUserPriority:
+		return NormalPri
+	case up < roachpb.NormalUserPriority:
+		return LowPri
+	case up > roachpb.NormalUserPriority:
+		return HighPri
+	}
+	return NormalPri
+}
+
+// WorkInfo describes a unit of work asking for admission.
+type WorkInfo struct {
+	// Priority is the priority of the work.
+	Priority WorkPriority
+	// CreateTime orders work of equal priority, older work being admitted
+	// first. For transactional work, it is the transaction's start time, so
+	// that the oldest transactions get to finish first under overload.
+	CreateTime int64
+	// BypassAdmission is set for work that must never wait, typically because
+	// other work depends on it to make progress (e.g. node liveness heartbeats
+	// or intent resolution). Such work is still counted against the queue's
+	// slots.
+	BypassAdmission bool
+}
+
+// WorkQueue is an admission queue for one kind of work. Each admitted unit of
+// work holds a slot until it calls AdmittedWorkDone. While the node is not
+// overloaded, the number of slots is unlimited and work is admitted
+// immediately. Once the Controller detects overload, the number of slots is
+// lowered below the number of slots in use, and work queues up in order of
+// priority and then age until slots are released.
+type WorkQueue struct {
+	name    string
+	enabled *settings.BoolSetting
+	st      *cluster.Settings
+	metrics WorkQueueMetrics
+
+	mu struct {
+		syncutil.Mutex
+		usedSlots int
+		// totalSlots is the number of slots available. A value of unlimitedSlots
+		// disables queueing.
+		totalSlots int
+		waiting    waitingWorkHeap
+	}
+}
+
+// unlimitedSlots is the value of WorkQueue.mu.totalSlots while the node isn't
+// overloaded.
+const unlimitedSlots = math.MaxInt32
+
+func makeWorkQueue(
+	name string, enabled *settings.BoolSetting, st *cluster.Settings, histogramWindow time.Duration,
+) *WorkQueue {
+	q := &WorkQueue{
+		name:    name,
+		enabled: enabled,
+		st:      st,
+		metrics: makeWorkQueueMetrics(name, histogramWindow),
+	}
+	q.mu.totalSlots = unlimitedSlots
+	return q
+}
+
+// Metrics returns the metrics of the queue.
+func (q *WorkQueue) Metrics() *WorkQueueMetrics {
+	return &q.metrics
+}
+
+// Admit blocks until the work is admitted or the context is canceled. If the
+// returned enabled value is true, the caller must call AdmittedWorkDone once
+// the work is done. If it is false, admission control is disabled for this
+// kind of work and AdmittedWorkDone must not be called.
+func (q *WorkQueue) Admit(ctx context.Context, info WorkInfo) (enabled bool, err error) {
+	if !q.enabled.Get(&q.st.SV) {
+		return false, nil
+	}
+	q.metrics.Requested.Inc(1)
+
+	q.mu.Lock()
+	if info.BypassAdmission || (q.mu.usedSlots < q.mu.totalSlots && q.mu.waiting.Len() == 0) {
+		q.mu.usedSlots++
+		q.mu.Unlock()
+		q.metrics.Admitted.Inc(1)
+		return true, nil
+	}
+	w := &waitingWork{
+		info:        info,
+		enqueueTime: timeutil.Now(),
+		grantCh:     make(chan struct{}, 1),
+	}
+	heap.Push(&q.mu.waiting, w)
+	q.metrics.WaitQueueLength.Inc(1)
+	q.mu.Unlock()
+
+	select {
+	case <-w.grantCh:
+		wait := timeutil.Since(w.enqueueTime)
+		q.metrics.WaitDurations.RecordValue(wait.Nanoseconds())
+		q.metrics.Admitted.Inc(1)
+		log.VEventf(ctx, 2, "%s admission: admitted after waiting %s", q.name, wait)
+		return true, nil
+	case <-ctx.Done():
+		q.mu.Lock()
+		if w.heapIndex >= 0 {
+			heap.Remove(&q.mu.waiting, w.heapIndex)
+			q.mu.Unlock()
+			q.metrics.WaitQueueLength.Dec(1)
+			q.metrics.Errored.Inc(1)
+			return false, errors.Wrapf(ctx.Err(), "%s admission: canceled while waiting", q.name)
+		}
+		q.mu.Unlock()
+		// The work was granted a slot concurrently with the cancellation. Hand
+		// it back so that it isn't leaked.
+		q.AdmittedWorkDone()
+		q.metrics.Errored.Inc(1)
+		return false, errors.Wrapf(ctx.Err(), "%s admission: canceled while waiting", q.name)
+	}
+}
+
+// TryAdmit is like Admit, but doesn't wait. If the returned enabled value is
+// true but admitted is false, the work couldn't be admitted right away; the
+// caller is expected to release the resources it holds before calling Admit.
+// If admitted is true, the caller must call AdmittedWorkDone once the work is
+// done.
+func (q *WorkQueue) TryAdmit(info WorkInfo) (enabled bool, admitted bool) {
+	if !q.enabled.Get(&q.st.SV) {
+		return false, false
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if info.BypassAdmission || (q.mu.usedSlots < q.mu.totalSlots && q.mu.waiting.Len() == 0) {
+		q.mu.usedSlots++
+		q.metrics.Requested.Inc(1)
+		q.metrics.Admitted.Inc(1)
+		return true, true
+	}
+	return true, false
+}
+
+// AdmittedWorkDone releases the slot held by admitted work.
+func (q *WorkQueue) AdmittedWorkDone() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.mu.usedSlots--
+	q.grantLocked()
+}
+
+// setTotalSlots sets the number of slots of the queue, admitting waiting work
+// if slots became available.
+func (q *WorkQueue) setTotalSlots(n int) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.mu.totalSlots = n
+	q.grantLocked()
+}
+
+// SetTotalSlotsForTesting sets the number of slots of the queue, as the
+// Controller would while the node is overloaded.
+func (q *WorkQueue) SetTotalSlotsForTesting(n int) {
+	q.setTotalSlots(n)
+}
+
+// slots returns the number of used and total slots of the queue.
+func (q *WorkQueue) slots() (used, total int) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.mu.usedSlots, q.mu.totalSlots
+}
+
+// grantLocked admits waiting work as long as there are free slots.
+func (q *WorkQueue) grantLocked() {
+	for q.mu.waiting.Len() > 0 && q.mu.usedSlots < q.mu.totalSlots {
+		w := heap.Pop(&q.mu.waiting).(*waitingWork)
+		q.mu.usedSlots++
+		q.metrics.WaitQueueLength.Dec(1)
+		w.grantCh <- struct{}{}
+	}
+}
+
+func (q *WorkQueue) String() string {
+	used, total := q.slots()
+	if total == unlimitedSlots {
+		return fmt.Sprintf("%s: used slots %d, unlimited", q.name, used)
+	}
+	return fmt.Sprintf("%s: used slots %d of %d", q.name, used, total)
+}
+
+// waitingWork is work waiting for admission in a WorkQueue.
+type waitingWork struct {
+	info        WorkInfo
+	enqueueTime time.Time
+	grantCh     chan struct{}
+	// heapIndex is the index of the work in the waitingWorkHeap, or -1 once it
+	// has been removed from it.
+	heapIndex int
+}
+
+// waitingWorkHeap orders waiting work by decreasing priority, and then by
+// increasing creation time.
+type waitingWorkHeap []*waitingWork
+
+var _ heap.Interface = (*waitingWorkHeap)(nil)
+
+func (h waitingWorkHeap) Len() int { return len(h) }
+
+func (h waitingWorkHeap) Less(i, j int) bool {
+	if h[i].info.Priority != h[j].info.Priority {
+		return h[i].info.Priority > h[j].info.Priority
+	}
+	return h[i].info.CreateTime < h[j].info.CreateTime
+}
+
+func (h waitingWorkHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].heapIndex = i
+	h[j].heapIndex = j
+}
+
+func (h *waitingWorkHeap) Push(x interface{}) {
+	w := x.(*waitingWork)
+	w.heapIndex = len(*h)
+	*h = append(*h, w)
+}
+
+func (h *waitingWorkHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	w := old[n-1]
+	old[n-1] = nil
+	w.heapIndex = -1
+	*h = old[:n-1]
+	return w
+}
+
+// WorkQueueMetrics are the metrics of a WorkQueue.
+type WorkQueueMetrics struct {
+	Requested       *metric.Counter
+	Admitted        *metric.Counter
+	Errored         *metric.Counter
+	WaitQueueLength *metric.Gauge
+	WaitDurations   *metric.Histogram
+	UsedSlots       *metric.Gauge
+	TotalSlots      *metric.Gauge
+}
+
+// MetricStruct implements the metric.Struct interface.
+func (WorkQueueMetrics) MetricStruct() {}
+
+var _ metric.Struct = WorkQueueMetrics{}
+
+func makeWorkQueueMetrics(name string, histogramWindow time.Duration) WorkQueueMetrics {
+	return WorkQueueMetrics{
+		Requested: metric.NewCounter(metric.Metadata{
+			Name:        fmt.Sprintf("admission.%s.requested", name),
+			Help:        fmt.Sprintf("Number of %s requests asking for admission", name),
+			Measurement: "Requests",
+			Unit:        metric.Unit_COUNT,
+		}),
+		Admitted: metric.NewCounter(metric.Metadata{
+			Name:        fmt.Sprintf("admission.%s.admitted", name),
+			Help:        fmt.Sprintf("Number of %s requests admitted", name),
+			Measurement: "Requests",
+			Unit:        metric.Unit_COUNT,
+		}),
+		Errored: metric.NewCounter(metric.Metadata{
+			Name:        fmt.Sprintf("admission.%s.errored", name),
+			Help:        fmt.Sprintf("Number of %s requests that gave up while waiting for admission", name),
+			Measurement: "Requests",
+			Unit:        metric.Unit_COUNT,
+		}),
+		WaitQueueLength: metric.NewGauge(metric.Metadata{
+			Name:        fmt.Sprintf("admission.%s.wait_queue_length", name),
+			Help:        fmt.Sprintf("Number of %s requests waiting for admission", name),
+			Measurement: "Requests",
+			Unit:        metric.Unit_COUNT,
+		}),
+		WaitDurations: metric.NewLatency(metric.Metadata{
+			Name:        fmt.Sprintf("admission.%s.wait_durations", name),
+			Help:        fmt.Sprintf("Queueing delay of %s requests that waited for admission", name),
+			Measurement: "Wait time",
+			Unit:        metric.Unit_NANOSECONDS,
+		}, histogramWindow),
+		UsedSlots: metric.NewGauge(metric.Metadata{
+			Name:        fmt.Sprintf("admission.%s.used_slots", name),
+			Help:        fmt.Sprintf("Number of admission slots held by %s requests", name),
+			Measurement: "Slots",
+			Unit:        metric.Unit_COUNT,
+		}),
+		TotalSlots: metric.NewGauge(metric.Metadata{
+			Name:        fmt.Sprintf("admission.%s.total_slots", name),
+			Help:        fmt.Sprintf("Number of admission slots available to %s requests, or 0 if unlimited", name),
+			Measurement: "Slots",
+			Unit:        metric.Unit_COUNT,
+		}),
+	}
+}
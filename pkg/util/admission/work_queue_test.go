@@ -0,0 +1,310 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package admission
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+// makeTestWorkQueue returns a WorkQueue with admission control enabled.
+func makeTestWorkQueue(st *cluster.Settings) *WorkQueue {
+	KVAdmissionControlEnabled.Override(&st.SV, true)
+	return makeWorkQueue("test", KVAdmissionControlEnabled, st, time.Minute)
+}
+
+func waitForQueueLength(t *testing.T, q *WorkQueue, n int) {
+	testutils.SucceedsSoon(t, func() error {
+		q.mu.Lock()
+		defer q.mu.Unlock()
+		if l := q.mu.waiting.Len(); l != n {
+			return errors.Errorf("expected %d waiting, found %d", n, l)
+		}
+		return nil
+	})
+}
+
+// TestWorkQueueOrdering verifies that waiting work is admitted by decreasing
+// priority, and then by increasing creation time.
+func TestWorkQueueOrdering(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ctx := context.Background()
+	q := makeTestWorkQueue(cluster.MakeTestingClusterSettings())
+	q.setTotalSlots(1)
+
+	// Occupy the only slot.
+	enabled, err := q.Admit(ctx, WorkInfo{})
+	require.NoError(t, err)
+	require.True(t, enabled)
+
+	infos := []WorkInfo{
+		{Priority: NormalPri, CreateTime: 3},
+		{Priority: LowPri, CreateTime: 1},
+		{Priority: NormalPri, CreateTime: 2},
+		{Priority: HighPri, CreateTime: 4},
+	}
+	admittedCh := make(chan int64, len(infos))
+	for i, info := range infos {
+		go func(info WorkInfo) {
+			if _, err := q.Admit(ctx, info); err != nil {
+				t.Error(err)
+			}
+			admittedCh <- info.CreateTime
+		}(info)
+		waitForQueueLength(t, q, i+1)
+	}
+
+	var order []int64
+	for range infos {
+		q.AdmittedWorkDone()
+		order = append(order, <-admittedCh)
+	}
+	require.Equal(t, []int64{4, 2, 3, 1}, order)
+	q.AdmittedWorkDone()
+
+	used, total := q.slots()
+	require.Equal(t, 0, used)
+	require.Equal(t, 1, total)
+	require.Equal(t, int64(5), q.metrics.Admitted.Count())
+	require.Equal(t, int64(0), q.metrics.WaitQueueLength.Value())
+}
+
+// TestWorkQueueBypassAndUnlimited verifies that work is admitted immediately
+// while the queue has unlimited slots, and that work bypassing admission
+// doesn't wait even when no slot is available.
+func TestWorkQueueBypassAndUnlimited(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ctx := context.Background()
+	q := makeTestWorkQueue(cluster.MakeTestingClusterSettings())
+	for i := 0; i < 100; i++ {
+		enabled, err := q.Admit(ctx, WorkInfo{})
+		require.NoError(t, err)
+		require.True(t, enabled)
+	}
+
+	q.setTotalSlots(10)
+	enabled, err := q.Admit(ctx, WorkInfo{BypassAdmission: true})
+	require.NoError(t, err)
+	require.True(t, enabled)
+	used, _ := q.slots()
+	require.Equal(t, 101, used)
+
+	for i := 0; i < 101; i++ {
+		q.AdmittedWorkDone()
+	}
+}
+
+// TestWorkQueueCancellation verifies that work whose context is canceled
+// while waiting leaves the queue without holding a slot.
+func TestWorkQueueCancellation(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	q := makeTestWorkQueue(cluster.MakeTestingClusterSettings())
+	q.setTotalSlots(1)
+	_, err := q.Admit(context.Background(), WorkInfo{})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	go func() {
+		_, err := q.Admit(ctx, WorkInfo{})
+		errCh <- err
+	}()
+	waitForQueueLength(t, q, 1)
+	cancel()
+	require.Equal(t, context.Canceled, errors.Cause(<-errCh))
+
+	q.AdmittedWorkDone()
+	used, _ := q.slots()
+	require.Equal(t, 0, used)
+	require.Equal(t, int64(1), q.metrics.Errored.Count())
+	require.Equal(t, int64(0), q.metrics.WaitQueueLength.Value())
+}
+
+// TestWorkQueueDisabled verifies that no slot is taken when admission control
+// is disabled.
+func TestWorkQueueDisabled(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	st := cluster.MakeTestingClusterSettings()
+	q := makeTestWorkQueue(st)
+	KVAdmissionControlEnabled.Override(&st.SV, false)
+	q.setTotalSlots(0)
+	enabled, err := q.Admit(context.Background(), WorkInfo{})
+	require.NoError(t, err)
+	require.False(t, enabled)
+	used, _ := q.slots()
+	require.Equal(t, 0, used)
+
+	enabled, admitted := q.TryAdmit(WorkInfo{})
+	require.False(t, enabled)
+	require.False(t, admitted)
+}
+
+// TestWorkQueueTryAdmit verifies that TryAdmit takes a slot only when one is
+// available without waiting, and never jumps ahead of waiting work.
+func TestWorkQueueTryAdmit(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ctx := context.Background()
+	q := makeTestWorkQueue(cluster.MakeTestingClusterSettings())
+	q.setTotalSlots(1)
+
+	enabled, admitted := q.TryAdmit(WorkInfo{})
+	require.True(t, enabled)
+	require.True(t, admitted)
+
+	// No slot left.
+	enabled, admitted = q.TryAdmit(WorkInfo{Priority: HighPri})
+	require.True(t, enabled)
+	require.False(t, admitted)
+	used, _ := q.slots()
+	require.Equal(t, 1, used)
+
+	// Bypassing work is admitted regardless.
+	enabled, admitted = q.TryAdmit(WorkInfo{BypassAdmission: true})
+	require.True(t, enabled)
+	require.True(t, admitted)
+	q.AdmittedWorkDone()
+
+	// Slots freed while work is waiting go to the waiting work, not to
+	// TryAdmit.
+	admittedCh := make(chan struct{})
+	go func() {
+		if _, err := q.Admit(ctx, WorkInfo{}); err != nil {
+			t.Error(err)
+		}
+		close(admittedCh)
+	}()
+	waitForQueueLength(t, q, 1)
+	q.setTotalSlots(2)
+	<-admittedCh
+	_, admitted = q.TryAdmit(WorkInfo{Priority: HighPri})
+	require.False(t, admitted)
+
+	q.AdmittedWorkDone()
+	q.AdmittedWorkDone()
+	used, _ = q.slots()
+	require.Equal(t, 0, used)
+	require.Equal(t, int64(3), q.metrics.Requested.Count())
+	require.Equal(t, int64(3), q.metrics.Admitted.Count())
+}
+
+func TestControllerNextTotalSlots(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	c := &Controller{numCPU: 2}
+	testCases := []struct {
+		overloaded  bool
+		used, total int
+		expected    int
+	}{
+		// Overload starts limiting from the slots in use.
+		{true, 100, unlimitedSlots, 75},
+		{true, 100, 80, 60},
+		// The number of slots never drops below minSlotsPerCPU per CPU.
+		{true, 10, 20, 16},
+		// Without overload, the number of slots grows by one per CPU...
+		{false, 10, 60, 62},
+		// ... until it exceeds maxSlotsPerCPU per CPU.
+		{false, 10, 511, unlimitedSlots},
+		{false, 10, unlimitedSlots, unlimitedSlots},
+	}
+	for _, tc := range testCases {
+		require.Equal(t, tc.expected, c.nextTotalSlots(tc.overloaded, tc.used, tc.total),
+			"overloaded=%t used=%d total=%d", tc.overloaded, tc.used, tc.total)
+	}
+}
+
+// TestControllerAdjust verifies that each load signal on its own makes the
+// Controller limit the number of slots, and that the limit is lifted once the
+// overload clears.
+func TestControllerAdjust(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	testCases := []struct {
+		name     string
+		overload func(l0Files *int64, schedulingLatency, gcPause *time.Duration)
+	}{
+		{"l0 files", func(l0Files *int64, _, _ *time.Duration) { *l0Files = 100 }},
+		{"scheduling latency", func(_ *int64, schedulingLatency, _ *time.Duration) {
+			*schedulingLatency = time.Second
+		}},
+		// Each call to adjust advances the clock by one second, so a tenth of
+		// a second of GC pauses per call is a fraction of 0.1.
+		{"gc pauses", func(_ *int64, _, gcPause *time.Duration) { *gcPause = 100 * time.Millisecond }},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			st := cluster.MakeTestingClusterSettings()
+			KVAdmissionControlEnabled.Override(&st.SV, true)
+			var l0Files int64
+			var schedulingLatency, gcPause, gcPauseTotal time.Duration
+			now := timeutil.Unix(0, 0)
+			c := NewController(st, time.Minute, func() int64 { return l0Files })
+			c.numCPU = 1
+			c.schedulingLatency = func() time.Duration { return schedulingLatency }
+			c.gcPauseTotal = func() time.Duration {
+				gcPauseTotal += gcPause
+				return gcPauseTotal
+			}
+			c.now = func() time.Time {
+				now = now.Add(time.Second)
+				return now
+			}
+
+			require.False(t, c.adjust())
+			require.False(t, c.adjust())
+			_, total := c.kvQueue.slots()
+			require.Equal(t, unlimitedSlots, total)
+
+			// Occupy slots so that the limit starts above the minimum.
+			const used = 100
+			for i := 0; i < used; i++ {
+				_, admitted := c.kvQueue.TryAdmit(WorkInfo{})
+				require.True(t, admitted)
+			}
+
+			tc.overload(&l0Files, &schedulingLatency, &gcPause)
+			require.True(t, c.adjust())
+			_, total = c.kvQueue.slots()
+			require.Equal(t, 75, total)
+			require.True(t, c.adjust())
+			_, total = c.kvQueue.slots()
+			require.Equal(t, 57, total)
+			require.Equal(t, int64(1), c.metrics.Overloaded.Value())
+
+			l0Files, schedulingLatency, gcPause = 0, 0, 0
+			require.False(t, c.adjust())
+			_, total = c.kvQueue.slots()
+			require.Equal(t, 58, total)
+			for i := 0; i < maxSlotsPerCPU; i++ {
+				c.adjust()
+			}
+			_, total = c.kvQueue.slots()
+			require.Equal(t, unlimitedSlots, total)
+			require.Equal(t, int64(0), c.metrics.Overloaded.Value())
+			for i := 0; i < used; i++ {
+				c.kvQueue.AdmittedWorkDone()
+			}
+		})
+	}
+}
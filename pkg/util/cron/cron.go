@@ -0,0 +1,214 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+// Package cron parses cron expressions and computes the times they fire at.
+//
+// An expression is either one of the macros @yearly (or @annually),
+// @monthly, @weekly, @daily (or @midnight) and @hourly, or is made of five
+// space separated fields:
+//
+//   minute (0-59) hour (0-23) day-of-month (1-31) month (1-12) day-of-week (0-6)
+//
+// Each field is a comma separated list of values, ranges (1-5) and steps
+// (*/15, 0-30/10); months and days of the week may also be given by their
+// three letter English names. As in the standard cron, when both the day of
+// the month and the day of the week are restricted, a time matches when
+// either of them does.
+package cron
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/errors"
+)
+
+// Expr is a parsed cron expression.
+type Expr struct {
+	minute, hour, dom, month, dow uint64
+	// domStar and dowStar are set when the day of the month, respectively the
+	// day of the week, is unrestricted.
+	domStar, dowStar bool
+}
+
+type fieldBounds struct {
+	name     string
+	min, max int
+	names    map[string]int
+}
+
+var (
+	minuteBounds = fieldBounds{name: "minute", min: 0, max: 59}
+	hourBounds   = fieldBounds{name: "hour", min: 0, max: 23}
+	domBounds    = fieldBounds{name: "day of month", min: 1, max: 31}
+	monthBounds  = fieldBounds{name: "month", min: 1, max: 12, names: map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	// Sunday may be given both as 0 and as 7.
+	dowBounds = fieldBounds{name: "day of week", min: 0, max: 7, names: map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+var macros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// Parse parses the given cron expression.
+func Parse(expr string) (*Expr, error) {
+	s := strings.TrimSpace(expr)
+	if strings.HasPrefix(s, "@") {
+		m, ok := macros[strings.ToLower(s)]
+		if !ok {
+			return nil, errors.Errorf("invalid cron expression %q: unknown macro", expr)
+		}
+		s = m
+	}
+	fields := strings.Fields(s)
+	if len(fields) != 5 {
+		return nil, errors.Errorf(
+			"invalid cron expression %q: expected 5 fields, found %d", expr, len(fields))
+	}
+
+	var e Expr
+	var err error
+	for i, f := range []struct {
+		field  string
+		bounds fieldBounds
+		bits   *uint64
+	}{
+		{fields[0], minuteBounds, &e.minute},
+		{fields[1], hourBounds, &e.hour},
+		{fields[2], domBounds, &e.dom},
+		{fields[3], monthBounds, &e.month},
+		{fields[4], dowBounds, &e.dow},
+	} {
+		if *f.bits, err = parseField(f.field, f.bounds); err != nil {
+			return nil, errors.Wrapf(err, "invalid cron expression %q", expr)
+		}
+		switch i {
+		case 2:
+			e.domStar = f.field == "*"
+		case 4:
+			e.dowStar = f.field == "*"
+		}
+	}
+	// Fold Sunday given as 7 into 0.
+	if e.dow&(1<<7) != 0 {
+		e.dow = (e.dow | 1) &^ (1 << 7)
+	}
+	return &e, nil
+}
+
+// parseField returns the set of values matched by a single field, as a bit
+// set.
+func parseField(field string, b fieldBounds) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rangePart, step := part, 1
+		if i := strings.IndexByte(part, '/'); i >= 0 {
+			var err error
+			rangePart = part[:i]
+			if step, err = strconv.Atoi(part[i+1:]); err != nil || step <= 0 {
+				return 0, errors.Errorf("invalid step in %s field %q", b.name, part)
+			}
+		}
+
+		lo, hi := b.min, b.max
+		if rangePart != "*" {
+			var err error
+			bounds := strings.SplitN(rangePart, "-", 2)
+			if lo, err = parseValue(bounds[0], b); err != nil {
+				return 0, err
+			}
+			hi = lo
+			if len(bounds) == 2 {
+				if hi, err = parseValue(bounds[1], b); err != nil {
+					return 0, err
+				}
+			} else if step != 1 {
+				// As in the standard cron, "N/step" stands for "N-max/step".
+				hi = b.max
+			}
+			if lo > hi {
+				return 0, errors.Errorf("invalid range in %s field %q", b.name, part)
+			}
+		}
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+func parseValue(s string, b fieldBounds) (int, error) {
+	if v, ok := b.names[strings.ToLower(s)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, errors.Errorf("invalid %s %q", b.name, s)
+	}
+	if v < b.min || v > b.max {
+		return 0, errors.Errorf("%s %d out of range [%d, %d]", b.name, v, b.min, b.max)
+	}
+	return v, nil
+}
+
+// maxSearchYears bounds the search for the next firing time of an expression
+// which may never fire, such as "0 0 30 2 *".
+const maxSearchYears = 5
+
+// Next returns the first time strictly after the given one at which the
+// expression fires, in the location of the given time. It returns the zero
+// time if the expression doesn't fire in the next few years.
+func (e *Expr) Next(t time.Time) time.Time {
+	loc := t.Location()
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(maxSearchYears, 0, 0)
+
+	for t.Before(limit) {
+		if e.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if !e.matchDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if e.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+			continue
+		}
+		if e.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+func (e *Expr) matchDay(t time.Time) bool {
+	domMatch := e.dom&(1<<uint(t.Day())) != 0
+	dowMatch := e.dow&(1<<uint(t.Weekday())) != 0
+	if !e.domStar && !e.dowStar {
+		return domMatch || dowMatch
+	}
+	return domMatch && dowMatch
+}
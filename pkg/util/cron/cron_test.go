@@ -0,0 +1,87 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package cron
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+)
+
+func TestNext(t *testing.T) {
+	// 2020-03-18 is a Wednesday.
+	from := time.Date(2020, 3, 18, 10, 30, 15, 0, time.UTC)
+	testCases := []struct {
+		expr     string
+		expected time.Time
+	}{
+		{"@hourly", time.Date(2020, 3, 18, 11, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2020, 3, 19, 0, 0, 0, 0, time.UTC)},
+		{"@midnight", time.Date(2020, 3, 19, 0, 0, 0, 0, time.UTC)},
+		{"@weekly", time.Date(2020, 3, 22, 0, 0, 0, 0, time.UTC)},
+		{"@monthly", time.Date(2020, 4, 1, 0, 0, 0, 0, time.UTC)},
+		{"@yearly", time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"@ANNUALLY", time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"* * * * *", time.Date(2020, 3, 18, 10, 31, 0, 0, time.UTC)},
+		{"30 10 * * *", time.Date(2020, 3, 19, 10, 30, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2020, 3, 18, 10, 45, 0, 0, time.UTC)},
+		{"5/20 * * * *", time.Date(2020, 3, 18, 10, 45, 0, 0, time.UTC)},
+		{"0 9-17/4 * * *", time.Date(2020, 3, 18, 13, 0, 0, 0, time.UTC)},
+		{"0 1,22 * * *", time.Date(2020, 3, 18, 22, 0, 0, 0, time.UTC)},
+		{"0 0 * * mon-fri", time.Date(2020, 3, 19, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2020, 3, 22, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * SAT", time.Date(2020, 3, 21, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 feb *", time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"0 0 31 * *", time.Date(2020, 3, 31, 0, 0, 0, 0, time.UTC)},
+		// With both the day of the month and the day of the week restricted,
+		// either one matching is enough.
+		{"0 0 1 * fri", time.Date(2020, 3, 20, 0, 0, 0, 0, time.UTC)},
+		{"0 0 30 2 *", time.Time{}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.expr, func(t *testing.T) {
+			e, err := Parse(tc.expr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if next := e.Next(from); !next.Equal(tc.expected) {
+				t.Fatalf("expected %s, got %s", tc.expected, next)
+			}
+		})
+	}
+}
+
+func TestParseError(t *testing.T) {
+	testCases := []struct {
+		expr string
+		err  string
+	}{
+		{"", "expected 5 fields, found 0"},
+		{"* * * *", "expected 5 fields, found 4"},
+		{"@sometimes", "unknown macro"},
+		{"60 * * * *", "minute 60 out of range"},
+		{"* 24 * * *", "hour 24 out of range"},
+		{"* * 0 * *", "day of month 0 out of range"},
+		{"* * * 13 *", "month 13 out of range"},
+		{"* * * foo *", `invalid month "foo"`},
+		{"*/0 * * * *", "invalid step"},
+		{"5-1 * * * *", "invalid range"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.expr, func(t *testing.T) {
+			_, err := Parse(tc.expr)
+			if !testutils.IsError(err, tc.err) {
+				t.Fatalf("expected error %q, got %v", tc.err, err)
+			}
+		})
+	}
+}